@@ -0,0 +1,30 @@
+// Command hashpassword prints a bcrypt hash for AUTH_PROVIDER=local user entries.
+//
+//	go run ./cmd/hashpassword            # prompts on stdin
+//	echo -n 'secret' | go run ./cmd/hashpassword
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/auth"
+)
+
+func main() {
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		fmt.Fprintf(os.Stderr, "failed to read password: %v\n", err)
+		os.Exit(1)
+	}
+
+	hash, err := auth.HashPassword(strings.TrimRight(password, "\r\n"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to hash password: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(hash)
+}
@@ -0,0 +1,365 @@
+// Package app wires the draft service together: it picks the store, pub/sub
+// and ClickHouse client for a config.Config, sets up authentication, the
+// pages, the JSON API and the health checks, and runs the HTTP and gRPC
+// servers along with the cuddle points sync.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/auth"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/clickhouse"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/config"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	grpcserver "github.com/Billy-Davies-2/jellycat-draft-ui/internal/grpc"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/handlers"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/middleware"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/mocks"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/pubsub"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/render"
+	pb "github.com/Billy-Davies-2/jellycat-draft-ui/proto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+)
+
+// shutdownTimeout bounds how long Serve waits for requests in flight.
+const shutdownTimeout = 10 * time.Second
+
+// App is the wired service.
+type App struct {
+	Config config.Config
+
+	Store dal.DraftDAL
+	// Upstream is the NATS connection events are shared through; Events
+	// bridges it to local subscribers and is what handlers publish to.
+	Upstream pubsub.Upstream
+	Events   *pubsub.PubSub
+
+	// ClickHouse is nil when ClickHouse is off or unreachable; in the latter
+	// case ClickHouseErr says why.
+	ClickHouse    clickhouse.CuddlePointsClient
+	ClickHouseErr error
+
+	Auth       auth.AuthProvider
+	TokenAuth  *auth.TokenAuth // nil when the store can't keep tokens
+	Roles      *auth.Roles
+	AuthEvents dal.AuthEventStore // nil when the store can't keep them
+
+	// Reservations holds pending picks for both the HTTP API and gRPC.
+	Reservations *dal.PickReservations
+
+	// Mux serves static files, the auth routes, /metrics, the pages, the
+	// JSON API and the health checks.
+	Mux *http.ServeMux
+
+	room     *roomState
+	renderer *render.Renderer
+	// syncer copies cuddle points from ClickHouse; nil when the sync is off
+	syncer *clickhouse.Syncer
+	// interactions records draft interactions for the cuddle points formula;
+	// nil when the ClickHouse client can't take them
+	interactions *clickhouse.InteractionWriter
+
+	backchannel auth.BackchannelLogoutProvider
+	closers     []func()
+}
+
+// NewApp wires the service for cfg. It returns an error, rather than exiting,
+// for any dependency it can't set up.
+func NewApp(cfg config.Config) (*App, error) {
+	a := &App{Config: cfg, Reservations: dal.NewPickReservations()}
+
+	store, err := openStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	a.Store = store
+
+	upstream, closeUpstream, err := openPubSub(cfg)
+	if err != nil {
+		return nil, err
+	}
+	a.Upstream = upstream
+	a.closers = append(a.closers, closeUpstream)
+	a.Events = Bridge(upstream)
+	a.Events.StampVersions(store.Version)
+
+	a.ClickHouse, a.ClickHouseErr, err = connectClickHouse(cfg, func() (clickhouse.CuddlePointsClient, error) {
+		client, err := clickhouse.NewClient(cfg.ClickHouse.Addr, cfg.ClickHouse.Database, cfg.ClickHouse.User, cfg.ClickHouse.Password, clickhouse.FormulaFromEnv())
+		if err != nil {
+			logger.Error("Failed to initialize ClickHouse", "error", err, "address", cfg.ClickHouse.Addr)
+			return nil, err
+		}
+		logger.Info("Connected to ClickHouse", "address", cfg.ClickHouse.Addr, "database", cfg.ClickHouse.Database)
+		return client, nil
+	})
+	if err != nil {
+		a.Close()
+		return nil, fmt.Errorf("failed to initialize ClickHouse: %w", err)
+	}
+
+	if err := a.setUpAuth(); err != nil {
+		a.Close()
+		return nil, err
+	}
+
+	a.room = newRoomState(cfg.RoomCode)
+	logger.Info("Draft room code ready", "code", a.room.Code())
+
+	// Load templates, reparsing them per request in development
+	a.renderer, err = render.New("templates", cfg.Development())
+	if err != nil {
+		a.Close()
+		return nil, fmt.Errorf("failed to parse templates: %w", err)
+	}
+	logger.Info("Templates loaded successfully", "pages", a.renderer.Pages(), "reload", cfg.Development())
+
+	// Sync cuddle points from ClickHouse, or its mock in development
+	switch {
+	case a.ClickHouse == nil:
+		logger.Info("Skipping cuddle points sync (ClickHouse not configured)")
+	case clickhouse.SyncDisabled():
+		logger.Info("Skipping cuddle points sync (CUDDLE_SYNC_DISABLED=true)")
+	default:
+		a.syncer = a.newCuddleSyncer(a.ClickHouse)
+	}
+	if sink, ok := a.ClickHouse.(clickhouse.InteractionSink); ok {
+		a.interactions = clickhouse.NewInteractionWriter(sink)
+	}
+
+	a.Mux = a.routes()
+	return a, nil
+}
+
+// openStore opens the DraftDAL cfg.DBDriver names.
+func openStore(cfg config.Config) (dal.DraftDAL, error) {
+	switch cfg.DBDriver {
+	case "memory":
+		logger.Info("Using in-memory data store")
+		return dal.NewMemoryDAL(), nil
+	case "sqlite":
+		store, err := dal.NewSQLiteDAL(cfg.SQLiteFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize SQLite: %w", err)
+		}
+		logger.Info("Connected to SQLite database", "file", cfg.SQLiteFile)
+		return store, nil
+	case "postgres":
+		if cfg.DatabaseURL == "" {
+			return nil, errors.New("DATABASE_URL environment variable is required for postgres driver")
+		}
+		store, err := dal.NewPostgresDAL(cfg.DatabaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Postgres: %w", err)
+		}
+		logger.Info("Connected to Postgres database")
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown DB_DRIVER: %s (valid: memory, sqlite, postgres)", cfg.DBDriver)
+	}
+}
+
+// openPubSub starts embedded NATS in development and connects to a NATS
+// server otherwise.
+func openPubSub(cfg config.Config) (pubsub.Upstream, func(), error) {
+	if cfg.Development() {
+		logger.Info("Starting embedded NATS server for local development")
+		embeddedNats, err := pubsub.NewEmbeddedNATSPubSub(pubsub.EmbeddedNATSOptions{
+			Port:       0, // Random available port
+			Subject:    cfg.NATSSubject,
+			StreamName: "DRAFT_EVENTS",
+			StoreDir:   "", // In-memory storage
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize embedded NATS: %w", err)
+		}
+		logger.Info("Embedded NATS server ready", "url", embeddedNats.GetServerURL())
+		return embeddedNats, embeddedNats.Close, nil
+	}
+
+	logger.Info("Using real NATS JetStream for production")
+	realNats, err := pubsub.NewNATSPubSub(cfg.NATSURL, cfg.NATSSubject)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize NATS: %w", err)
+	}
+	logger.Info("Connected to NATS", "url", cfg.NATSURL)
+	return realNats, realNats.Close, nil
+}
+
+// Bridge gives handlers a local PubSub over upstream: publishes go to the
+// upstream, which broadcasts them to every instance, and upstream events
+// reach local subscribers.
+func Bridge(upstream pubsub.Upstream) *pubsub.PubSub {
+	return pubsub.NewWithUpstream(upstream)
+}
+
+// connectClickHouse picks the cuddle points client the server runs with:
+// connect's client when ClickHouse is enabled, the mock in development
+// (unless UseMock is off), or none. If connect fails the draft carries on
+// with the points it has, without ClickHouse or the sync, and the failure is
+// returned as unavailable for /api/health to report. Require makes the
+// failure fatal instead.
+func connectClickHouse(cfg config.Config, connect func() (clickhouse.CuddlePointsClient, error)) (client clickhouse.CuddlePointsClient, unavailable error, err error) {
+	switch {
+	case cfg.ClickHouse.Enabled:
+		client, err := connect()
+		if err == nil {
+			return client, nil, nil
+		}
+		if cfg.ClickHouse.Require {
+			return nil, nil, err
+		}
+		logger.Error("Starting without ClickHouse; cuddle points won't sync until it is reachable and the server restarts", "error", err)
+		return nil, err, nil
+	case cfg.Development() && cfg.ClickHouse.UseMock:
+		// Exercise the sync path locally with made-up cuddle points
+		return mocks.NewMockClickHouseClient(), nil, nil
+	default:
+		logger.Info("Skipping ClickHouse analytics integration", "enabled", false)
+		return nil, nil, nil
+	}
+}
+
+// setUpAuth builds the session store, the auth provider (with login limits
+// and, when the store keeps them, personal access tokens) and role checks.
+func (a *App) setUpAuth() error {
+	sessions, err := auth.NewSessionStore(a.Config.SessionStore, a.Store, a.Config.RedisURL, a.Config.SessionSecrets...)
+	if err != nil {
+		return fmt.Errorf("failed to initialize session store: %w", err)
+	}
+	if a.Config.SessionStore != "" {
+		logger.Info("Using session store", "store", a.Config.SessionStore)
+	}
+
+	a.AuthEvents, _ = a.Store.(dal.AuthEventStore)
+	audit := auth.NewAuditLog(a.AuthEvents)
+
+	provider, err := newAuthProvider(a.Config.Environment, sessions, audit)
+	if err != nil {
+		return fmt.Errorf("failed to initialize authentication: %w", err)
+	}
+	a.Auth = auth.WithLoginLimiter(provider, auth.NewLoginLimiter(auth.LoginLimiterConfig{}, audit))
+	if tokenStore, ok := a.Store.(dal.TokenStore); ok {
+		a.TokenAuth = auth.NewTokenAuth(tokenStore)
+		a.Auth = auth.WithAccessTokens(a.Auth, a.TokenAuth)
+	}
+	a.Roles = auth.NewRoles(a.Store)
+
+	a.backchannel, _ = provider.(auth.BackchannelLogoutProvider)
+	return nil
+}
+
+// baseRoutes sets up the routes every deployment serves regardless of pages:
+// static files, authentication and Prometheus metrics.
+func (a *App) baseRoutes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
+
+	mux.HandleFunc("/auth/login", a.Auth.LoginHandler)
+	mux.HandleFunc("/auth/callback", a.Auth.CallbackHandler)
+	mux.HandleFunc("/auth/logout", a.Auth.LogoutHandler)
+	mux.HandleFunc("/auth/session", a.Auth.SessionHandler)
+	mux.HandleFunc("/auth/session/refresh", a.Auth.RefreshSessionHandler)
+	if a.backchannel != nil {
+		// Called by the identity provider, not the browser
+		mux.HandleFunc("/auth/backchannel-logout", a.backchannel.BackchannelLogoutHandler)
+	}
+
+	mux.Handle("/metrics", promhttp.Handler())
+	return mux
+}
+
+// Start runs the cuddle points sync and records draft interactions into
+// ClickHouse (or the mock) in the background until ctx is done or Close.
+func (a *App) Start(ctx context.Context) {
+	if a.syncer != nil {
+		a.syncer.Start(ctx)
+		a.closers = append(a.closers, a.syncer.Stop)
+		logger.Info("Cuddle points sync started", "interval", a.syncer.Interval, "start_delay", a.syncer.StartDelay)
+	}
+	if a.interactions != nil {
+		a.interactions.Start(ctx)
+		events := a.Events.SubscribeOrigin()
+		go a.recordInteractions(events, a.interactions)
+		a.closers = append(a.closers, a.interactions.Stop, func() { a.Events.Unsubscribe(events) })
+	}
+}
+
+// StartGRPC listens on Config.GRPCPort and serves the draft service in the
+// background until Close.
+func (a *App) StartGRPC() error {
+	addr := net.JoinHostPort("0.0.0.0", strconv.Itoa(a.Config.GRPCPort))
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for gRPC: %w", err)
+	}
+
+	var serverOptions []grpc.ServerOption
+	if a.TokenAuth != nil {
+		unaryAuth, streamAuth := grpcserver.AuthInterceptors(a.TokenAuth, a.Roles)
+		serverOptions = append(serverOptions, grpc.UnaryInterceptor(unaryAuth), grpc.StreamInterceptor(streamAuth))
+	}
+
+	grpcServer := grpc.NewServer(serverOptions...)
+	draftService := grpcserver.NewServer(a.Store, a.Events)
+	draftService.UsePickReservations(a.Reservations)
+	if a.ClickHouse != nil {
+		draftService.UsePlayerMetrics(a.ClickHouse)
+	}
+	pb.RegisterDraftServiceServer(grpcServer, draftService)
+	a.closers = append(a.closers, grpcServer.Stop)
+
+	go func() {
+		logger.Info("gRPC server starting", "address", addr)
+		if err := grpcServer.Serve(lis); err != nil {
+			logger.Error("Failed to serve gRPC", "error", err)
+		}
+	}()
+	return nil
+}
+
+// Serve serves handler on Config.Port until ctx is done, then gives requests
+// in flight shutdownTimeout to finish. It logs how many requests and SSE
+// clients were active when shutdown began and how long they took to drain.
+func (a *App) Serve(ctx context.Context, handler http.Handler) error {
+	addr := net.JoinHostPort("0.0.0.0", strconv.Itoa(a.Config.Port))
+	server := &http.Server{Addr: addr, Handler: middleware.RequestLogger(middleware.CountInFlight(handler))}
+	go func() {
+		<-ctx.Done()
+		logger.Info("Shutting down", "active_requests", middleware.InFlight(), "active_sse_clients", handlers.ActiveSSEClients())
+		start := time.Now()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error("HTTP server shutdown failed", "error", err, "drain_duration", time.Since(start),
+				"active_requests", middleware.InFlight(), "active_sse_clients", handlers.ActiveSSEClients())
+			return
+		}
+		logger.Info("HTTP server drained", "drain_duration", time.Since(start))
+	}()
+
+	logger.Info("Server starting", "address", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Close stops the gRPC server and the background work Start began, and
+// disconnects from NATS and ClickHouse.
+func (a *App) Close() {
+	for i := len(a.closers) - 1; i >= 0; i-- {
+		a.closers[i]()
+	}
+	a.closers = nil
+	if a.ClickHouse != nil {
+		a.ClickHouse.Close()
+	}
+}
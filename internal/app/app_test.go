@@ -0,0 +1,157 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/clickhouse"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/config"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/mocks"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/pubsub"
+)
+
+// TestMain sets up the logger once: goroutines an App starts keep logging
+// until it closes, so reinitializing the logger per test would race them.
+func TestMain(m *testing.M) {
+	logger.Init()
+	os.Exit(m.Run())
+}
+
+// testConfig is a development config that needs nothing outside the process.
+// It runs the test from the repository root, where NewApp finds the templates.
+func testConfig(t *testing.T) config.Config {
+	t.Helper()
+	t.Chdir("../..")
+	return config.Config{
+		Environment: "development",
+		DBDriver:    "memory",
+		NATSSubject: "draft.events",
+	}
+}
+
+func newTestApp(t *testing.T, cfg config.Config) *App {
+	t.Helper()
+	a, err := NewApp(cfg)
+	if err != nil {
+		t.Fatalf("NewApp() failed: %v", err)
+	}
+	t.Cleanup(a.Close)
+	return a
+}
+
+// newBareApp is an App around store with no servers or background work, for
+// calling its handlers directly.
+func newBareApp(store dal.DraftDAL) *App {
+	return &App{Store: store, Events: pubsub.New(), Reservations: dal.NewPickReservations(), room: newRoomState("A123")}
+}
+
+func TestNewAppSelectsStoreByDriver(t *testing.T) {
+	cfg := testConfig(t)
+	store := newTestApp(t, cfg).Store
+	if _, ok := store.(*dal.MemoryDAL); !ok {
+		t.Fatalf("memory driver store = %T, want *dal.MemoryDAL", store)
+	}
+
+	cfg.DBDriver = "sqlite"
+	cfg.SQLiteFile = filepath.Join(t.TempDir(), "app.sqlite")
+	store = newTestApp(t, cfg).Store
+	if _, ok := store.(*dal.SQLiteDAL); !ok {
+		t.Fatalf("sqlite driver store = %T, want *dal.SQLiteDAL", store)
+	}
+
+	for _, driver := range []string{"postgres", "mongo"} {
+		cfg.DBDriver = driver
+		cfg.DatabaseURL = ""
+		if a, err := NewApp(cfg); err == nil {
+			a.Close()
+			t.Errorf("NewApp() with driver %q and no DATABASE_URL succeeded, want an error", driver)
+		}
+	}
+}
+
+func TestNewAppRegistersBaseRoutes(t *testing.T) {
+	a := newTestApp(t, testConfig(t))
+
+	for path, want := range map[string]int{
+		"/metrics":      http.StatusOK,
+		"/auth/session": http.StatusUnauthorized,
+		"/nowhere":      http.StatusNotFound,
+	} {
+		recorder := httptest.NewRecorder()
+		a.Mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != want {
+			t.Errorf("GET %s = %d, want %d", path, recorder.Code, want)
+		}
+	}
+	if a.Roles == nil || a.TokenAuth == nil || a.AuthEvents == nil {
+		t.Errorf("auth wiring = roles %v, tokens %v, audit %v; want all set for the memory store", a.Roles, a.TokenAuth, a.AuthEvents)
+	}
+}
+
+func TestConnectClickHouseDegradesUnlessRequired(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Environment = "production"
+	cfg.ClickHouse.Enabled = true
+	refused := errors.New("connection refused")
+	failing := func() (clickhouse.CuddlePointsClient, error) { return nil, refused }
+
+	client, unavailable, err := connectClickHouse(cfg, failing)
+	if client != nil || unavailable != refused || err != nil {
+		t.Fatalf("connectClickHouse() = %v, %v, %v; want no client and the failure reported as unavailable", client, unavailable, err)
+	}
+
+	cfg.ClickHouse.Require = true
+	if _, _, err := connectClickHouse(cfg, failing); err != refused {
+		t.Fatalf("connectClickHouse() error = %v, want the failure when ClickHouse is required", err)
+	}
+
+	mock := mocks.NewMockClickHouseClient()
+	client, unavailable, err = connectClickHouse(cfg, func() (clickhouse.CuddlePointsClient, error) { return mock, nil })
+	if client != mock || unavailable != nil || err != nil {
+		t.Fatalf("connectClickHouse() = %v, %v, %v; want the connected client", client, unavailable, err)
+	}
+
+	cfg.ClickHouse.Enabled = false
+	if client, _, _ := connectClickHouse(cfg, failing); client != nil {
+		t.Fatalf("connectClickHouse() = %v, want no client when ClickHouse is off in production", client)
+	}
+	cfg.Environment = "development"
+	cfg.ClickHouse.UseMock = true
+	if client, _, _ := connectClickHouse(cfg, failing); client == nil {
+		t.Fatal("connectClickHouse() = nil, want the mock in development")
+	}
+}
+
+func TestBridgeSharesEventsBetweenInstances(t *testing.T) {
+	upstream, _ := pubsub.NewMockNATSPubSub("", "draft.events")
+	first, second := Bridge(upstream), Bridge(upstream)
+	received := second.Subscribe()
+	origin := first.SubscribeOrigin()
+
+	// The bridges subscribe to the upstream in the background, so publish
+	// until the second instance hears one.
+	deadline := time.After(2 * time.Second)
+	for delivered := false; !delivered; {
+		first.Publish(pubsub.Event{Type: "draft:pick"})
+		select {
+		case event := <-received:
+			if event.Type != "draft:pick" {
+				t.Fatalf("second instance got %q, want draft:pick", event.Type)
+			}
+			delivered = true
+		case <-time.After(20 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("event published on one instance never reached the other")
+		}
+	}
+	if event := <-origin; event.Type != "draft:pick" {
+		t.Fatalf("origin subscriber got %q, want the publisher's own draft:pick", event.Type)
+	}
+}
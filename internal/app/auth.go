@@ -0,0 +1,182 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/auth"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+)
+
+// newAuthProvider selects the authentication provider from AUTH_PROVIDER.
+// When unset, development uses mock auth and every other environment uses Authentik.
+// Every provider except mock keeps its sessions in sessions and records to audit.
+func newAuthProvider(environment string, sessions auth.SessionStore, audit *auth.AuditLog) (auth.AuthProvider, error) {
+	provider := strings.ToLower(strings.TrimSpace(os.Getenv("AUTH_PROVIDER")))
+	if provider == "" {
+		if environment == "" || environment == "development" {
+			provider = "mock"
+		} else {
+			provider = "authentik"
+		}
+	}
+
+	switch provider {
+	case "mock":
+		var users []auth.MockUser
+		var err error
+		if usersFile := os.Getenv("MOCK_USERS_FILE"); usersFile != "" {
+			users, err = auth.LoadMockUsers(usersFile)
+		} else {
+			users, err = auth.ParseMockUsers(os.Getenv("MOCK_USERS"))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		provider, err := auth.NewMockAuth(users...)
+		if err != nil {
+			return nil, fmt.Errorf("%w (check MOCK_USERS_FILE or MOCK_USERS)", err)
+		}
+		logger.Info("Using mock authentication for local development (no Authentik server required)", "users", len(users))
+		return provider, nil
+	case "authentik":
+		authentikBaseURL := os.Getenv("AUTHENTIK_BASE_URL")
+		authentikClientID := os.Getenv("AUTHENTIK_CLIENT_ID")
+		authentikClientSecret := os.Getenv("AUTHENTIK_CLIENT_SECRET")
+		authentikRedirectURL := os.Getenv("AUTHENTIK_REDIRECT_URL")
+
+		if authentikBaseURL == "" || authentikClientID == "" || authentikClientSecret == "" {
+			return nil, fmt.Errorf("AUTHENTIK_BASE_URL, AUTHENTIK_CLIENT_ID, and AUTHENTIK_CLIENT_SECRET environment variables are required for production")
+		}
+
+		if authentikRedirectURL == "" {
+			authentikRedirectURL = "http://localhost:3000/auth/callback"
+		}
+
+		provider := auth.NewAuthentikAuth(&auth.AuthentikConfig{
+			BaseURL:      authentikBaseURL,
+			ClientID:     authentikClientID,
+			ClientSecret: authentikClientSecret,
+			RedirectURL:  authentikRedirectURL,
+			Scopes:       []string{"openid", "profile", "email"},
+			Sessions:     sessions,
+			Audit:        audit,
+		})
+		logger.Info("Connected to Authentik", "url", authentikBaseURL)
+		return provider, nil
+	case "oidc":
+		issuerURL := os.Getenv("OIDC_ISSUER_URL")
+		clientID := os.Getenv("OIDC_CLIENT_ID")
+		clientSecret := os.Getenv("OIDC_CLIENT_SECRET")
+		redirectURL := os.Getenv("OIDC_REDIRECT_URL")
+
+		if issuerURL == "" || clientID == "" || clientSecret == "" {
+			return nil, fmt.Errorf("OIDC_ISSUER_URL, OIDC_CLIENT_ID, and OIDC_CLIENT_SECRET environment variables are required for the oidc provider")
+		}
+
+		if redirectURL == "" {
+			redirectURL = "http://localhost:3000/auth/callback"
+		}
+
+		var scopes []string
+		if rawScopes := os.Getenv("OIDC_SCOPES"); rawScopes != "" {
+			scopes = strings.Split(rawScopes, ",")
+		}
+
+		provider, err := auth.NewOIDCAuth(&auth.OIDCConfig{
+			IssuerURL:     issuerURL,
+			ClientID:      clientID,
+			ClientSecret:  clientSecret,
+			RedirectURL:   redirectURL,
+			Scopes:        scopes,
+			UsernameClaim: os.Getenv("OIDC_USERNAME_CLAIM"),
+			GroupsClaim:   os.Getenv("OIDC_GROUPS_CLAIM"),
+			Sessions:      sessions,
+			Audit:         audit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Connected to OIDC provider", "issuer", issuerURL)
+		return provider, nil
+	case "github":
+		clientID := os.Getenv("GITHUB_CLIENT_ID")
+		clientSecret := os.Getenv("GITHUB_CLIENT_SECRET")
+		redirectURL := os.Getenv("GITHUB_REDIRECT_URL")
+
+		if clientID == "" || clientSecret == "" {
+			return nil, fmt.Errorf("GITHUB_CLIENT_ID and GITHUB_CLIENT_SECRET environment variables are required for the github provider")
+		}
+
+		if redirectURL == "" {
+			redirectURL = "http://localhost:3000/auth/callback"
+		}
+
+		provider := auth.NewGitHubAuth(&auth.GitHubConfig{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			AdminOrg:     os.Getenv("GITHUB_ADMIN_ORG"),
+			AdminTeam:    os.Getenv("GITHUB_ADMIN_TEAM"),
+			Sessions:     sessions,
+			Audit:        audit,
+		})
+		logger.Info("Using GitHub authentication", "adminOrg", os.Getenv("GITHUB_ADMIN_ORG"), "adminTeam", os.Getenv("GITHUB_ADMIN_TEAM"))
+		return provider, nil
+	case "google":
+		clientID := os.Getenv("GOOGLE_CLIENT_ID")
+		clientSecret := os.Getenv("GOOGLE_CLIENT_SECRET")
+		redirectURL := os.Getenv("GOOGLE_REDIRECT_URL")
+
+		if clientID == "" || clientSecret == "" {
+			return nil, fmt.Errorf("GOOGLE_CLIENT_ID and GOOGLE_CLIENT_SECRET environment variables are required for the google provider")
+		}
+
+		if redirectURL == "" {
+			redirectURL = "http://localhost:3000/auth/callback"
+		}
+
+		allowedDomain := os.Getenv("GOOGLE_ALLOWED_DOMAIN")
+		provider, err := auth.NewGoogleAuth(&auth.GoogleConfig{
+			ClientID:      clientID,
+			ClientSecret:  clientSecret,
+			RedirectURL:   redirectURL,
+			AllowedDomain: allowedDomain,
+			AdminEmails:   strings.Split(os.Getenv("GOOGLE_ADMIN_EMAILS"), ","),
+			Sessions:      sessions,
+			Audit:         audit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Using Google authentication", "allowedDomain", allowedDomain)
+		return provider, nil
+	case "local":
+		var users []auth.LocalUser
+		var err error
+		if usersFile := os.Getenv("LOCAL_AUTH_USERS_FILE"); usersFile != "" {
+			users, err = auth.LoadLocalUsers(usersFile)
+		} else {
+			users, err = auth.ParseLocalUsers(os.Getenv("LOCAL_AUTH_USERS"))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		provider, err := auth.NewLocalAuth(&auth.LocalConfig{
+			Users:           users,
+			InsecureCookies: os.Getenv("LOCAL_AUTH_INSECURE_COOKIES") == "true",
+			Sessions:        sessions,
+			Audit:           audit,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w (set LOCAL_AUTH_USERS_FILE or LOCAL_AUTH_USERS)", err)
+		}
+		logger.Info("Using local user-list authentication", "users", len(users))
+		return provider, nil
+	default:
+		return nil, fmt.Errorf("unknown AUTH_PROVIDER: %s (valid: mock, authentik, oidc, github, google, local)", provider)
+	}
+}
@@ -0,0 +1,206 @@
+package app
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/auth"
+)
+
+// healthCheckTimeout bounds each dependency check so a hung backend fails
+// the probe instead of hanging it.
+var healthCheckTimeout = 2 * time.Second
+
+var errHealthCheckTimeout = errors.New("timeout")
+
+// checkDependency runs check under a healthCheckTimeout deadline. Checks
+// must honor ctx so an overrunning one returns instead of lingering.
+func checkDependency(parent context.Context, check func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(parent, healthCheckTimeout)
+	defer cancel()
+
+	err := check(ctx)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return errHealthCheckTimeout
+	}
+	return err
+}
+
+func (a *App) healthHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	status := "ok"
+	httpStatus := http.StatusOK
+	checks := make(map[string]interface{})
+
+	// Check database connectivity
+	if store := a.Store; store != nil {
+		err := checkDependency(ctx, store.Ping)
+		if err != nil {
+			status = "degraded"
+			httpStatus = http.StatusServiceUnavailable
+			checks["database"] = map[string]interface{}{
+				"status": "unhealthy",
+				"error":  err.Error(),
+			}
+		} else {
+			checks["database"] = map[string]interface{}{
+				"status": "healthy",
+			}
+		}
+	} else {
+		checks["database"] = map[string]interface{}{
+			"status": "not_configured",
+		}
+	}
+
+	// Check ClickHouse connectivity (only in production) with a ping; the
+	// cuddle points queries are too heavy to run on every probe.
+	if a.Config.Environment == "production" && a.ClickHouse != nil {
+		var latency time.Duration
+		err := checkDependency(ctx, func(ctx context.Context) error {
+			var err error
+			latency, err = a.ClickHouse.Health(ctx)
+			return err
+		})
+		clickhouseCheck := map[string]interface{}{
+			"status": "healthy",
+		}
+		if err != nil {
+			status = "degraded"
+			httpStatus = http.StatusServiceUnavailable
+			clickhouseCheck["status"] = "unhealthy"
+			clickhouseCheck["error"] = err.Error()
+		} else {
+			clickhouseCheck["latencyMs"] = float64(latency.Microseconds()) / 1000
+		}
+		if a.syncer != nil {
+			clickhouseCheck["lastSuccessfulSync"] = nil
+			if lastSuccess, _ := a.syncer.Status(); !lastSuccess.IsZero() {
+				clickhouseCheck["lastSuccessfulSync"] = lastSuccess.Unix()
+			}
+		}
+		checks["clickhouse"] = clickhouseCheck
+	} else if a.ClickHouseErr != nil {
+		// Running on the draft's own points since startup
+		status = "degraded"
+		httpStatus = http.StatusServiceUnavailable
+		checks["clickhouse"] = map[string]interface{}{
+			"status": "unavailable",
+			"error":  a.ClickHouseErr.Error(),
+		}
+	} else if a.Config.Environment == "production" {
+		checks["clickhouse"] = map[string]interface{}{
+			"status": "not_configured",
+		}
+	}
+
+	// Report the cuddle points sync wherever it runs
+	if a.syncer != nil {
+		lastSuccess, failures := a.syncer.Status()
+		syncCheck := map[string]interface{}{
+			"status":              "healthy",
+			"consecutiveFailures": failures,
+			"lastSuccess":         nil,
+		}
+		if !lastSuccess.IsZero() {
+			syncCheck["lastSuccess"] = lastSuccess.Unix()
+			syncCheck["lastResult"] = a.syncer.LastResult()
+		}
+		if a.syncer.Unhealthy() {
+			status = "degraded"
+			httpStatus = http.StatusServiceUnavailable
+			syncCheck["status"] = "unhealthy"
+		}
+		checks["cuddleSync"] = syncCheck
+	}
+
+	// Check NATS connectivity (only in production) - We can verify by trying to publish a test event
+	if a.Config.Environment == "production" && a.Upstream != nil {
+		// Just verify NATS is available - actual connection health is handled internally by NATS
+		checks["nats"] = map[string]interface{}{
+			"status": "healthy",
+		}
+	}
+
+	response := map[string]interface{}{
+		"status": status,
+	}
+	if a.healthDetailsAllowed(r) {
+		response["timestamp"] = time.Now().Unix()
+		response["checks"] = checks
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	json.NewEncoder(w).Encode(response)
+}
+
+// healthDetailsAllowed reports whether r may see dependency checks in health
+// responses; other callers only get the overall status. With neither
+// HEALTH_TOKEN nor HEALTH_INTERNAL_NETWORKS set, every caller may. Otherwise
+// the caller needs the token in X-Health-Token or an address inside one of
+// the comma-separated CIDRs.
+func (a *App) healthDetailsAllowed(r *http.Request) bool {
+	token := a.Config.HealthToken
+	if token == "" && len(a.Config.HealthNetworks) == 0 {
+		return true
+	}
+
+	if token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Health-Token")), []byte(token)) == 1 {
+		return true
+	}
+	ip := net.ParseIP(auth.ClientIP(r))
+	if ip == nil {
+		return false
+	}
+	for _, network := range a.Config.HealthNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// livenessHandler handles Kubernetes liveness probes
+// Returns 200 if the application is running (doesn't check dependencies)
+func (a *App) livenessHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "alive",
+		"timestamp": time.Now().Unix(),
+	})
+}
+
+// readinessHandler handles Kubernetes readiness probes
+// Returns 200 if the application is ready to serve traffic (checks critical dependencies)
+func (a *App) readinessHandler(w http.ResponseWriter, r *http.Request) {
+	// Check database connectivity - this is critical for readiness
+	if store := a.Store; store != nil {
+		if err := checkDependency(r.Context(), store.Ping); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			response := map[string]interface{}{
+				"status": "not_ready",
+			}
+			if a.healthDetailsAllowed(r) {
+				response["reason"] = "database_unavailable"
+				response["timestamp"] = time.Now().Unix()
+			}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "ready",
+		"timestamp": time.Now().Unix(),
+	})
+}
@@ -0,0 +1,174 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/config"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/mocks"
+)
+
+// slowDAL simulates a hung database.
+type slowDAL struct {
+	dal.DraftDAL
+	delay time.Duration
+}
+
+func (s slowDAL) Ping(ctx context.Context) error {
+	select {
+	case <-time.After(s.delay):
+		return s.DraftDAL.Ping(ctx)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestHealthHandlerTimesOutHungDatabase(t *testing.T) {
+	originalTimeout := healthCheckTimeout
+	defer func() { healthCheckTimeout = originalTimeout }()
+
+	a := newBareApp(slowDAL{DraftDAL: dal.NewMemoryDAL(), delay: time.Second})
+	healthCheckTimeout = 20 * time.Millisecond
+
+	recorder := httptest.NewRecorder()
+	started := time.Now()
+	a.healthHandler(recorder, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+	if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+		t.Fatalf("health check took %v, want it bounded by the timeout", elapsed)
+	}
+
+	var response struct {
+		Status string                       `json:"status"`
+		Checks map[string]map[string]string `json:"checks"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if recorder.Code != http.StatusServiceUnavailable || response.Status != "degraded" {
+		t.Fatalf("health = %d %q, want 503 degraded", recorder.Code, response.Status)
+	}
+	if database := response.Checks["database"]; database["status"] != "unhealthy" || database["error"] != "timeout" {
+		t.Fatalf("database check = %v, want unhealthy timeout", database)
+	}
+
+	recorder = httptest.NewRecorder()
+	a.readinessHandler(recorder, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("readiness status = %d, want %d", recorder.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestHealthDetailsRequireTokenOrInternalNetwork(t *testing.T) {
+	_, internal, _ := net.ParseCIDR("10.0.0.0/8")
+	a := newBareApp(dal.NewMemoryDAL())
+	a.Config = config.Config{HealthToken: "probe-secret", HealthNetworks: []*net.IPNet{internal}}
+
+	health := func(remoteAddr, token string) map[string]interface{} {
+		request := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+		request.RemoteAddr = remoteAddr
+		if token != "" {
+			request.Header.Set("X-Health-Token", token)
+		}
+		recorder := httptest.NewRecorder()
+		a.healthHandler(recorder, request)
+		var response map[string]interface{}
+		if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		return response
+	}
+
+	for name, response := range map[string]map[string]interface{}{
+		"token":            health("203.0.113.7:5000", "probe-secret"),
+		"internal network": health("10.1.2.3:5000", ""),
+	} {
+		if response["status"] != "ok" || response["checks"] == nil {
+			t.Fatalf("%s: response = %v, want the detailed checks", name, response)
+		}
+	}
+	for name, response := range map[string]map[string]interface{}{
+		"no token":    health("203.0.113.7:5000", ""),
+		"wrong token": health("203.0.113.7:5000", "guess"),
+	} {
+		if len(response) != 1 || response["status"] != "ok" {
+			t.Fatalf("%s: response = %v, want only the status", name, response)
+		}
+	}
+}
+
+// unqueryableClickHouse fails any cuddle points query, and its ping when
+// down is set.
+type unqueryableClickHouse struct {
+	*mocks.MockClickHouseClient
+	down bool
+}
+
+func (unqueryableClickHouse) GetAllCuddlePoints(ctx context.Context) (map[string]int, error) {
+	return nil, errors.New("health probe queried ClickHouse")
+}
+
+func (c unqueryableClickHouse) Health(ctx context.Context) (time.Duration, error) {
+	if c.down {
+		return 0, errors.New("connection refused")
+	}
+	return 3 * time.Millisecond, nil
+}
+
+func TestHealthPingsClickHouseInsteadOfQuerying(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	a := newBareApp(dal.NewMemoryDAL())
+	a.Config = config.Config{Environment: "production"}
+	a.ClickHouse = unqueryableClickHouse{MockClickHouseClient: mocks.NewMockClickHouseClient()}
+	a.syncer = a.newCuddleSyncer(mocks.NewMockClickHouseClient())
+	a.syncer.RunOnce(context.Background())
+
+	clickhouseCheck := func() (int, map[string]interface{}) {
+		recorder := httptest.NewRecorder()
+		a.healthHandler(recorder, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+		var health struct {
+			Checks map[string]map[string]interface{} `json:"checks"`
+		}
+		if err := json.NewDecoder(recorder.Body).Decode(&health); err != nil {
+			t.Fatalf("decode health: %v", err)
+		}
+		return recorder.Code, health.Checks["clickhouse"]
+	}
+
+	code, check := clickhouseCheck()
+	if code != http.StatusOK || check["status"] != "healthy" || check["latencyMs"] != float64(3) || check["lastSuccessfulSync"] == nil {
+		t.Fatalf("clickhouse check = %d %v, want healthy with the ping latency and last successful sync", code, check)
+	}
+
+	a.ClickHouse = unqueryableClickHouse{MockClickHouseClient: mocks.NewMockClickHouseClient(), down: true}
+	code, check = clickhouseCheck()
+	if code != http.StatusServiceUnavailable || check["status"] != "unhealthy" || check["error"] != "connection refused" {
+		t.Fatalf("clickhouse check = %d %v, want unhealthy with the ping error", code, check)
+	}
+}
+
+func TestHealthReportsClickHouseUnavailableSinceStartup(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	a := newBareApp(dal.NewMemoryDAL())
+	a.Config = config.Config{Environment: "production"}
+	a.ClickHouseErr = errors.New("connection refused")
+
+	recorder := httptest.NewRecorder()
+	a.healthHandler(recorder, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+	var health struct {
+		Status string                            `json:"status"`
+		Checks map[string]map[string]interface{} `json:"checks"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&health); err != nil {
+		t.Fatalf("decode health: %v", err)
+	}
+	if health.Status != "degraded" || health.Checks["clickhouse"]["status"] != "unavailable" || health.Checks["clickhouse"]["error"] != "connection refused" {
+		t.Fatalf("health = %d %+v, want degraded with ClickHouse unavailable", recorder.Code, health)
+	}
+}
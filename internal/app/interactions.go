@@ -0,0 +1,84 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/clickhouse"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/pubsub"
+)
+
+// recordInteractions queues the interactions behind each event until events
+// is closed. Pass an origin subscription so each event is recorded once
+// across replicas.
+func (a *App) recordInteractions(events <-chan pubsub.Event, writer *clickhouse.InteractionWriter) {
+	for event := range events {
+		if interactions := a.interactionsFor(event, time.Now()); len(interactions) > 0 {
+			writer.Record(interactions...)
+		}
+	}
+}
+
+// interactionsFor maps a draft event to jellycat_interactions rows: a pick
+// credits the picking team's owner, a user chat message credits each Jellycat
+// it names, and an edit credits the player edited.
+func (a *App) interactionsFor(event pubsub.Event, now time.Time) []clickhouse.Interaction {
+	switch event.Type {
+	case "draft:pick", "chat:add", "players:update":
+	default:
+		return nil
+	}
+
+	state, err := a.Store.GetState()
+	if err != nil {
+		logger.Warn("Failed to load draft state for interactions", "error", err, "type", event.Type)
+		return nil
+	}
+	payloadString := func(key string) string {
+		if value, ok := event.Payload[key]; ok {
+			return fmt.Sprint(value)
+		}
+		return ""
+	}
+
+	switch event.Type {
+	case "draft:pick":
+		teamID := payloadString("teamId")
+		userID := "team:" + teamID
+		for _, team := range state.Teams {
+			if team.ID == teamID && team.OwnerUserID != "" {
+				userID = team.OwnerUserID
+			}
+		}
+		return []clickhouse.Interaction{{JellycatID: payloadString("playerId"), UserID: userID, Action: "pick", Timestamp: now}}
+
+	case "chat:add":
+		message := findChatMessage(state.Chat, payloadString("id"))
+		if message == nil || message.Type != "user" {
+			return nil
+		}
+		text := strings.ToLower(message.Text)
+		var interactions []clickhouse.Interaction
+		for _, player := range state.Players {
+			if player.Name != "" && strings.Contains(text, strings.ToLower(player.Name)) {
+				interactions = append(interactions, clickhouse.Interaction{JellycatID: player.ID, UserID: "chat", Action: "mention", Timestamp: now})
+			}
+		}
+		return interactions
+
+	default: // players:update
+		return []clickhouse.Interaction{{JellycatID: payloadString("id"), UserID: "commissioner", Action: "update", Timestamp: now}}
+	}
+}
+
+func findChatMessage(chat []models.ChatMessage, id string) *models.ChatMessage {
+	for i := range chat {
+		if chat[i].ID == id {
+			return &chat[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,416 @@
+package app
+
+import (
+	"hash/fnv"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/auth"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+type featuredProspect struct {
+	Name       string
+	Image      string
+	Category   string
+	Label      string
+	FrameIndex int
+}
+
+var featuredProspectLabels = []string{"No. 1 Board Buzz", "Sleeper Pick", "Fan Favorite"}
+
+func (a *App) homeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	http.Redirect(w, r, "/start", http.StatusSeeOther)
+}
+
+func (a *App) startHandler(w http.ResponseWriter, r *http.Request) {
+	state, err := a.Store.GetState()
+	if err != nil {
+		http.Error(w, "Failed to load state", http.StatusInternalServerError)
+		return
+	}
+
+	user := auth.GetUser(r)
+	data := map[string]interface{}{
+		"Teams":             state.Teams,
+		"Settings":          state.Settings,
+		"ModeOptions":       models.DraftModeOptions(),
+		"FeaturedProspects": buildFeaturedProspects(state.Players, a.homeProspectSeed(state)),
+		"User":              user,
+		"IsAdmin":           auth.IsAdmin(user),
+	}
+	for key, value := range a.roomTemplateData(r) {
+		data[key] = value
+	}
+
+	a.renderPage(w, r, "start", data)
+}
+
+// renderPage renders page with data, or a 500 that keeps template details
+// out of the response.
+func (a *App) renderPage(w http.ResponseWriter, r *http.Request, page string, data map[string]interface{}) {
+	// Every page shows the draft's title, when one is set
+	if meta, err := a.Store.GetMeta(); err != nil {
+		logger.FromContext(r.Context()).Warn("Failed to load draft meta", "error", err)
+	} else {
+		data["DraftTitle"] = meta.Title
+		data["DraftDescription"] = meta.Description
+	}
+	if err := a.renderer.Render(w, page, data); err != nil {
+		logger.FromContext(r.Context()).Error("Failed to render page", "page", page, "error", err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+	}
+}
+
+func (a *App) homeProspectSeed(state *models.DraftState) string {
+	if state == nil {
+		return a.room.Code()
+	}
+
+	return strings.Join([]string{
+		a.room.Code(),
+		string(state.Settings.Mode),
+		state.CurrentTeamID,
+		state.CurrentTeamName,
+	}, "|")
+}
+
+func buildFeaturedProspects(players []models.Player, seed string) []featuredProspect {
+	candidatesByCategory := map[string][]models.Player{}
+	categories := []string{}
+
+	for _, player := range players {
+		if player.Drafted {
+			continue
+		}
+
+		category := playerCategory(player)
+		if _, exists := candidatesByCategory[category]; !exists {
+			categories = append(categories, category)
+		}
+		candidatesByCategory[category] = append(candidatesByCategory[category], player)
+	}
+
+	sort.SliceStable(categories, func(leftIndex, rightIndex int) bool {
+		leftCategory := categories[leftIndex]
+		rightCategory := categories[rightIndex]
+		return homeProspectHash(seed, leftCategory) < homeProspectHash(seed, rightCategory)
+	})
+
+	selected := make([]models.Player, 0, len(featuredProspectLabels))
+	selectedIDs := map[string]bool{}
+	for _, category := range categories {
+		if len(selected) == len(featuredProspectLabels) {
+			break
+		}
+
+		playersInCategory := append([]models.Player(nil), candidatesByCategory[category]...)
+		sort.SliceStable(playersInCategory, func(leftIndex, rightIndex int) bool {
+			leftPlayer := playersInCategory[leftIndex]
+			rightPlayer := playersInCategory[rightIndex]
+			return homeProspectHash(seed, category, leftPlayer.ID, leftPlayer.Name) < homeProspectHash(seed, category, rightPlayer.ID, rightPlayer.Name)
+		})
+
+		selected = append(selected, playersInCategory[0])
+		selectedIDs[playersInCategory[0].ID] = true
+	}
+
+	if len(selected) < len(featuredProspectLabels) {
+		remaining := make([]models.Player, 0, len(players))
+		for _, player := range players {
+			if player.Drafted || selectedIDs[player.ID] {
+				continue
+			}
+			remaining = append(remaining, player)
+		}
+		sort.SliceStable(remaining, func(leftIndex, rightIndex int) bool {
+			leftPlayer := remaining[leftIndex]
+			rightPlayer := remaining[rightIndex]
+			return homeProspectHash(seed, leftPlayer.ID, leftPlayer.Name) < homeProspectHash(seed, rightPlayer.ID, rightPlayer.Name)
+		})
+
+		for _, player := range remaining {
+			if len(selected) == len(featuredProspectLabels) {
+				break
+			}
+			selected = append(selected, player)
+		}
+	}
+
+	prospects := make([]featuredProspect, 0, len(selected))
+	for index, player := range selected {
+		image := strings.TrimSpace(player.Image)
+		if image == "" {
+			image = "/images/placeholder.png"
+		}
+
+		prospects = append(prospects, featuredProspect{
+			Name:       player.Name,
+			Image:      image,
+			Category:   playerCategory(player),
+			Label:      featuredProspectLabels[index%len(featuredProspectLabels)],
+			FrameIndex: index % len(featuredProspectLabels),
+		})
+	}
+
+	return prospects
+}
+
+func playerCategory(player models.Player) string {
+	category := strings.TrimSpace(player.Team)
+	if category != "" {
+		return category
+	}
+
+	category = strings.TrimSpace(player.Position)
+	if category != "" {
+		return category
+	}
+
+	return "Prospect"
+}
+
+func homeProspectHash(parts ...string) uint32 {
+	hasher := fnv.New32a()
+	for _, part := range parts {
+		_, _ = hasher.Write([]byte(part))
+		_, _ = hasher.Write([]byte("|"))
+	}
+	return hasher.Sum32()
+}
+
+// userTeam returns the team user acts as, resolved by auth.ResolveTeam when
+// they own several. Teams that predate OwnerUserID are migrated the first
+// time their owner signs in: each unclaimed team whose owner string matches
+// the user's username or name is bound to the user's ID, after which only
+// the ID is consulted.
+func (a *App) userTeam(state *models.DraftState, user *auth.User) *models.Team {
+	if user == nil || user.ID == "" {
+		return nil
+	}
+
+	for i := range state.Teams {
+		team := &state.Teams[i]
+		if team.OwnerUserID != "" || team.Owner == "" {
+			continue
+		}
+		if team.Owner != user.Username && team.Owner != user.Name {
+			continue
+		}
+		claimed, err := a.Store.ClaimTeam(team.ID, user.ID, team.Owner)
+		if err != nil {
+			logger.Warn("Failed to migrate team owner", "team_id", team.ID, "user_id", user.ID, "error", err)
+			continue
+		}
+		logger.Info("Migrated team owner to user ID", "team_id", team.ID, "user_id", user.ID)
+		team.OwnerUserID = claimed.OwnerUserID
+	}
+
+	return auth.ResolveTeam(auth.OwnedTeams(user, state.Teams), state.CurrentTeamID)
+}
+
+func (a *App) draftHandler(w http.ResponseWriter, r *http.Request) {
+	state, err := a.Store.GetState()
+	if err != nil {
+		http.Error(w, "Failed to load state", http.StatusInternalServerError)
+		return
+	}
+
+	user := auth.GetUser(r)
+
+	// Find if user owns the team with current pick
+	var userTeamID string
+	var isUserTurn bool
+	if team := a.userTeam(state, user); team != nil {
+		userTeamID = team.ID
+		isUserTurn = team.ID == state.CurrentTeamID
+	}
+
+	data := map[string]interface{}{
+		"Players":             state.Players,
+		"Teams":               state.Teams,
+		"Chat":                state.Chat,
+		"Settings":            state.Settings,
+		"ModeOptions":         models.DraftModeOptions(),
+		"IsBingoMode":         state.Settings.Mode == models.DraftModeBingo,
+		"IsWheelMode":         state.Settings.Mode == models.DraftModeWheel,
+		"DraftOrder":          state.DraftOrder,
+		"BingoBoard":          state.BingoBoard,
+		"CurrentBingoPrompt":  state.CurrentBingoPrompt,
+		"WheelSlots":          state.WheelSlots,
+		"SuggestedPick":       state.SuggestedPick,
+		"AnalyticsConfigured": a.ClickHouse != nil,
+		"ChatEnabled":         dal.ChatEnabled(),
+		"User":                user,
+		"IsAdmin":             auth.IsAdmin(user),
+		"CurrentPick":         state.CurrentPick,
+		"CurrentRound":        state.CurrentRound,
+		"PickInRound":         state.PickInRound,
+		"CurrentTeamID":       state.CurrentTeamID,
+		"StateVersion":        state.Version,
+		"CurrentTeamName":     state.CurrentTeamName,
+		"UserTeamID":          userTeamID,
+		"IsUserTurn":          isUserTurn,
+	}
+	for key, value := range a.roomTemplateData(r) {
+		data[key] = value
+	}
+
+	a.renderPage(w, r, "draft", data)
+}
+
+func (a *App) pickHandler(w http.ResponseWriter, r *http.Request) {
+	state, err := a.Store.GetState()
+	if err != nil {
+		http.Error(w, "Failed to load state", http.StatusInternalServerError)
+		return
+	}
+
+	user := auth.GetUser(r)
+
+	var userTeamID string
+	var isUserTurn bool
+	if team := a.userTeam(state, user); team != nil {
+		userTeamID = team.ID
+		isUserTurn = team.ID == state.CurrentTeamID
+	}
+
+	data := map[string]interface{}{
+		"Players":             state.Players,
+		"Teams":               state.Teams,
+		"Settings":            state.Settings,
+		"IsBingoMode":         state.Settings.Mode == models.DraftModeBingo,
+		"IsWheelMode":         state.Settings.Mode == models.DraftModeWheel,
+		"CurrentBingoPrompt":  state.CurrentBingoPrompt,
+		"DraftOrder":          state.DraftOrder,
+		"WheelSlots":          state.WheelSlots,
+		"SuggestedPick":       state.SuggestedPick,
+		"AnalyticsConfigured": a.ClickHouse != nil,
+		"ChatEnabled":         dal.ChatEnabled(),
+		"User":                user,
+		"IsAdmin":             auth.IsAdmin(user),
+		"CurrentPick":         state.CurrentPick,
+		"CurrentRound":        state.CurrentRound,
+		"PickInRound":         state.PickInRound,
+		"CurrentTeamID":       state.CurrentTeamID,
+		"CurrentTeamName":     state.CurrentTeamName,
+		"UserTeamID":          userTeamID,
+		"IsUserTurn":          isUserTurn,
+		"InitialRoomCode":     normalizeRoomCode(r.URL.Query().Get("code")),
+	}
+
+	a.renderPage(w, r, "pick", data)
+}
+
+func (a *App) adminHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user from context
+	user := auth.GetUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Check if user is an admin
+	if !auth.IsAdmin(user) {
+		http.Error(w, "Forbidden: Admin access required", http.StatusForbidden)
+		return
+	}
+
+	state, err := a.Store.GetState()
+	if err != nil {
+		http.Error(w, "Failed to load state", http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Players":             state.Players,
+		"Teams":               state.Teams,
+		"Settings":            state.Settings,
+		"ModeOptions":         models.DraftModeOptions(),
+		"AnalyticsConfigured": a.ClickHouse != nil,
+		"ChatEnabled":         dal.ChatEnabled(),
+		"User":                user,
+		"IsAdmin":             true,
+	}
+
+	a.renderPage(w, r, "admin", data)
+}
+
+func (a *App) resultsHandler(w http.ResponseWriter, r *http.Request) {
+	state, err := a.Store.GetState()
+	if err != nil {
+		http.Error(w, "Failed to load state", http.StatusInternalServerError)
+		return
+	}
+
+	user := auth.GetUser(r)
+
+	// Calculate total points for each team and sort by points
+	type TeamWithPoints struct {
+		models.Team
+		TotalPoints int
+	}
+
+	teamsWithPoints := make([]TeamWithPoints, 0, len(state.Teams))
+	for _, team := range state.Teams {
+		totalPoints := 0
+		for _, player := range team.Players {
+			totalPoints += player.Points
+		}
+		teamsWithPoints = append(teamsWithPoints, TeamWithPoints{
+			Team:        team,
+			TotalPoints: totalPoints,
+		})
+	}
+
+	// Sort by total points descending
+	for i := 0; i < len(teamsWithPoints)-1; i++ {
+		for j := i + 1; j < len(teamsWithPoints); j++ {
+			if teamsWithPoints[j].TotalPoints > teamsWithPoints[i].TotalPoints {
+				teamsWithPoints[i], teamsWithPoints[j] = teamsWithPoints[j], teamsWithPoints[i]
+			}
+		}
+	}
+
+	// Get winner (team with most points)
+	var winner *TeamWithPoints
+	if len(teamsWithPoints) > 0 && teamsWithPoints[0].TotalPoints > 0 {
+		winner = &teamsWithPoints[0]
+	}
+
+	data := map[string]interface{}{
+		"Teams":   teamsWithPoints,
+		"Winner":  winner,
+		"User":    user,
+		"IsAdmin": auth.IsAdmin(user),
+	}
+
+	a.renderPage(w, r, "results", data)
+}
+
+// serveImageHandler serves images from the database or falls back to static files
+func (a *App) serveImageHandler(w http.ResponseWriter, r *http.Request) {
+	// Try to get image from database if the DAL supports image storage.
+	if imageStore, ok := a.Store.(dal.ImageStore); ok {
+		imageData, contentType, err := imageStore.GetImageByPath(r.URL.Path)
+		if err == nil && len(imageData) > 0 {
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("Cache-Control", "public, max-age=31536000") // Cache for 1 year
+			w.Write(imageData)
+			return
+		}
+	}
+
+	// Fallback to serving from static files
+	http.ServeFile(w, r, "static"+r.URL.Path)
+}
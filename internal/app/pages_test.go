@@ -0,0 +1,233 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/auth"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/render"
+)
+
+func TestBuildFeaturedProspectsSpreadsCategories(t *testing.T) {
+	players := []models.Player{
+		{ID: "space-1", Name: "Space One", Team: "Space", Position: "CC", Image: "/images/space-1.png"},
+		{ID: "space-2", Name: "Space Two", Team: "Space", Position: "SS", Image: "/images/space-2.png"},
+		{ID: "ocean-1", Name: "Ocean One", Team: "Ocean", Position: "HH", Image: "/images/ocean-1.png"},
+		{ID: "garden-1", Name: "Garden One", Team: "Garden", Position: "DD", Image: "/images/garden-1.png"},
+	}
+
+	prospects := buildFeaturedProspects(players, "ROOM|standard")
+	if len(prospects) != 3 {
+		t.Fatalf("featured prospects length = %d, want 3", len(prospects))
+	}
+
+	categories := map[string]bool{}
+	for index, prospect := range prospects {
+		categories[prospect.Category] = true
+		if prospect.Image == "" || prospect.Name == "" || prospect.Label == "" {
+			t.Fatalf("featured prospect missing display fields: %+v", prospect)
+		}
+		if prospect.FrameIndex != index {
+			t.Fatalf("featured prospect frame index = %d, want %d", prospect.FrameIndex, index)
+		}
+	}
+
+	if len(categories) != 3 {
+		t.Fatalf("featured prospects should prefer distinct categories, got %v", categories)
+	}
+}
+
+func TestBuildFeaturedProspectsIgnoresDraftedPlayers(t *testing.T) {
+	players := []models.Player{
+		{ID: "drafted", Name: "Drafted", Team: "Space", Drafted: true, Image: "/images/drafted.png"},
+		{ID: "available", Name: "Available", Team: "Ocean", Image: "/images/available.png"},
+	}
+
+	prospects := buildFeaturedProspects(players, "ROOM|standard")
+	if len(prospects) != 1 {
+		t.Fatalf("featured prospects length = %d, want 1", len(prospects))
+	}
+	if prospects[0].Name != "Available" {
+		t.Fatalf("featured prospect = %q, want available player", prospects[0].Name)
+	}
+}
+
+// parseTestTemplates parses the repository's templates directory.
+func parseTestTemplates(t *testing.T) *render.Renderer {
+	t.Helper()
+	pages, err := render.New("../../templates", false)
+	if err != nil {
+		t.Fatalf("parse templates: %v", err)
+	}
+	return pages
+}
+
+// renderTestPage renders page from the templates directory with data.
+func renderTestPage(t *testing.T, page string, data map[string]interface{}) string {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	if err := parseTestTemplates(t).Render(recorder, page, data); err != nil {
+		t.Fatalf("render %s: %v", page, err)
+	}
+	return recorder.Body.String()
+}
+
+// TestPageHandlersRender renders every page through its handler with the
+// seeded draft, a reacted chat message and a finished pick, so template and
+// helper regressions fail here instead of as runtime 500s.
+func TestPageHandlersRender(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "development")
+
+	store := dal.NewMemoryDAL()
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	if err := store.DraftPlayer(state.Players[0].ID, state.CurrentTeamID); err != nil {
+		t.Fatalf("DraftPlayer() failed: %v", err)
+	}
+	message, err := store.AddChatMessage("Great pick!", "user")
+	if err != nil {
+		t.Fatalf("AddChatMessage() failed: %v", err)
+	}
+	if _, err := store.AddReaction(message.ID, "👍", "user-1"); err != nil {
+		t.Fatalf("AddReaction() failed: %v", err)
+	}
+	if _, err := store.SetMeta(models.DraftMeta{Title: "Springfield Plush League"}); err != nil {
+		t.Fatalf("SetMeta() failed: %v", err)
+	}
+	a := newBareApp(store)
+	a.renderer = parseTestTemplates(t)
+
+	pages := map[string]struct {
+		handler http.HandlerFunc
+		want    []string
+	}{
+		"start":   {a.startHandler, []string{"ROOM A123", "Pair Phone"}},
+		"draft":   {a.draftHandler, []string{"Great pick!", time.UnixMilli(message.TS).UTC().Format("15:04 UTC")}},
+		"pick":    {a.pickHandler, []string{"Draft Slots"}},
+		"admin":   {a.adminHandler, []string{"Manage Teams", "1 Player Drafted", "Tier "}},
+		"results": {a.resultsHandler, []string{state.Players[0].Name}},
+	}
+	if got, want := len(a.renderer.Pages()), len(pages); got != want {
+		t.Fatalf("templates define %d pages %v, test covers %d", got, a.renderer.Pages(), want)
+	}
+	admin := &auth.User{ID: "user-admin", Username: "admin", Name: "Admin", Groups: []string{"admins"}}
+	for _, page := range a.renderer.Pages() {
+		t.Run(page, func(t *testing.T) {
+			tc, ok := pages[page]
+			if !ok {
+				t.Fatalf("no handler test for page %q", page)
+			}
+			request := httptest.NewRequest(http.MethodGet, "/"+page, nil)
+			request = request.WithContext(auth.WithUser(request.Context(), admin))
+			recorder := httptest.NewRecorder()
+			tc.handler(recorder, request)
+
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", recorder.Code, recorder.Body.String())
+			}
+			for _, expected := range append(tc.want, "<title>Springfield Plush League · Jellycat Fantasy Draft</title>") {
+				if !strings.Contains(recorder.Body.String(), expected) {
+					t.Errorf("page missing %q", expected)
+				}
+			}
+		})
+	}
+}
+
+func TestAdminTemplateRendersTeamManagement(t *testing.T) {
+	data := map[string]interface{}{
+		"Players": []models.Player{},
+		"Teams": []models.Team{
+			{ID: "team-1", Name: "Test Team", Owner: "", Mascot: "T", Color: "bg-blue-100 border-blue-300", Players: []models.Player{}},
+		},
+		"Settings":            models.DefaultDraftSettings(),
+		"ModeOptions":         models.DraftModeOptions(),
+		"AnalyticsConfigured": false,
+		"User":                &auth.User{Name: "Admin"},
+		"IsAdmin":             true,
+	}
+
+	rendered := renderTestPage(t, "admin", data)
+	for _, expected := range []string{"Manage Teams", "Add Team", "Move Up", "Unassigned", "editTeamColor", "0 Players Drafted"} {
+		if !strings.Contains(rendered, expected) {
+			t.Fatalf("admin template missing %q", expected)
+		}
+	}
+}
+
+func TestDraftAndPickTemplatesRenderRoomUX(t *testing.T) {
+	data := map[string]interface{}{
+		"Players": []models.Player{
+			{
+				ID:           "player-1",
+				Name:         "Keli Pelican",
+				Position:     "CH",
+				Team:         "Ocean Motion",
+				CuddlePoints: 73,
+				Tier:         models.TierC,
+				Image:        "/images/keli.png",
+				Analytics: models.PlayerAnalytics{
+					PickScore:  81,
+					ValueScore: 72,
+					CrowdHeat:  80,
+					NeedFit:    70,
+					TrendLabel: "+2%",
+					Label:      "Steady pick",
+					Reason:     "Balanced profile.",
+					Sparkline:  []int{20, 40, 60},
+				},
+			},
+		},
+		"Teams": []models.Team{
+			{ID: "team-1", Name: "Draft Slot 1", Owner: "Taylor", Mascot: "T", Color: "bg-blue-100 border-blue-300", Players: []models.Player{}},
+		},
+		"Chat":                []models.ChatMessage{},
+		"Settings":            models.DefaultDraftSettings(),
+		"ModeOptions":         models.DraftModeOptions(),
+		"IsBingoMode":         false,
+		"IsWheelMode":         false,
+		"DraftOrder":          []models.DraftOrderEntry{{Pick: 1, Round: 1, TeamID: "team-1", TeamName: "Draft Slot 1", Mascot: "T", Active: true}},
+		"BingoBoard":          []models.BingoSquare{},
+		"CurrentBingoPrompt":  "",
+		"WheelSlots":          []models.WheelSlot{},
+		"SuggestedPick":       nil,
+		"AnalyticsConfigured": false,
+		"User":                &auth.User{Name: "Taylor"},
+		"IsAdmin":             false,
+		"CurrentPick":         1,
+		"CurrentRound":        1,
+		"PickInRound":         1,
+		"CurrentTeamID":       "team-1",
+		"CurrentTeamName":     "Draft Slot 1",
+		"UserTeamID":          "team-1",
+		"IsUserTurn":          true,
+		"InitialRoomCode":     "A123",
+		"RoomCode":            "A123",
+		"JoinPath":            "/join?code=A123",
+		"JoinURL":             "https://example.test/join?code=A123",
+		"JoinQRPath":          "/api/room/qr?code=A123",
+	}
+
+	expectedByPage := map[string][]string{
+		"draft": {"Draft Lobby", "Waiting Room", "Taylor", "Draft Slot 1"},
+		"pick":  {"Optional team nickname", "Draft Slots", "Taylor", "Draft Slot 1"},
+	}
+
+	for _, page := range []string{"draft", "pick"} {
+		t.Run(page, func(t *testing.T) {
+			output := renderTestPage(t, page, data)
+			for _, expected := range expectedByPage[page] {
+				if !strings.Contains(output, expected) {
+					t.Fatalf("template missing %q", expected)
+				}
+			}
+		})
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package app
 
 import (
 	"crypto/rand"
@@ -7,9 +7,11 @@ import (
 	"math/big"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/auth"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/handlers"
 	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
 	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/pubsub"
 	qrcode "github.com/skip2/go-qrcode"
@@ -17,8 +19,7 @@ import (
 
 const roomCodeLength = 4
 
-var draftRoom *roomState
-
+// roomState is the draft room players join with its code.
 type roomState struct {
 	code string
 }
@@ -108,7 +109,7 @@ func isValidRoomCode(code string) bool {
 	return hasLetter && hasDigit
 }
 
-func roomInfoHandler(w http.ResponseWriter, r *http.Request) {
+func (a *App) roomInfoHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -116,25 +117,25 @@ func roomInfoHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]string{
-		"code":     draftRoom.Code(),
-		"joinPath": joinPath(),
-		"joinUrl":  joinURL(r),
-		"qrPath":   joinQRPath(),
+		"code":     a.room.Code(),
+		"joinPath": a.joinPath(),
+		"joinUrl":  a.joinURL(r),
+		"qrPath":   a.joinQRPath(),
 	})
 }
 
-func roomQRHandler(w http.ResponseWriter, r *http.Request) {
+func (a *App) roomQRHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	if code := normalizeRoomCode(r.URL.Query().Get("code")); code != "" && !draftRoom.Matches(code) {
+	if code := normalizeRoomCode(r.URL.Query().Get("code")); code != "" && !a.room.Matches(code) {
 		http.Error(w, "Invalid room code", http.StatusUnauthorized)
 		return
 	}
 
-	png, err := qrcode.Encode(joinURL(r), qrcode.Medium, 512)
+	png, err := qrcode.Encode(a.joinURL(r), qrcode.Medium, 512)
 	if err != nil {
 		http.Error(w, "Failed to generate QR code", http.StatusInternalServerError)
 		return
@@ -145,7 +146,7 @@ func roomQRHandler(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(png)
 }
 
-func roomJoinHandler(w http.ResponseWriter, r *http.Request) {
+func (a *App) roomJoinHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -157,7 +158,7 @@ func roomJoinHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !draftRoom.Matches(request.Code) {
+	if !a.room.Matches(request.Code) {
 		http.Error(w, "Invalid room code", http.StatusUnauthorized)
 		return
 	}
@@ -173,20 +174,20 @@ func roomJoinHandler(w http.ResponseWriter, r *http.Request) {
 
 	var team *models.Team
 	if request.TeamID != "" {
-		team, err = findTeamByID(request.TeamID)
+		team, err = a.findTeamByID(request.TeamID)
 		if err == nil && strings.TrimSpace(team.Owner) == "" {
-			team, err = dataStore.UpdateTeam(team.ID, team.Name, request.Username, team.Mascot, team.Color)
+			team, err = a.Store.UpdateTeam(team.ID, team.Name, request.Username, team.Mascot, team.Color)
 			if err == nil {
-				publishRoomTeamUpdateEvent(team)
+				a.publishRoomTeamUpdateEvent(team)
 			}
 		}
 	} else {
 		if request.TeamName == "" {
 			request.TeamName = request.Username
 		}
-		team, err = dataStore.AddTeam(request.TeamName, request.Username, "", "")
+		team, err = a.Store.AddTeam(request.TeamName, request.Username, "", "")
 		if err == nil {
-			publishRoomJoinEvents(team)
+			a.publishRoomJoinEvents(team)
 		}
 	}
 
@@ -196,14 +197,14 @@ func roomJoinHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(roomJoinResponse{OK: true, Code: draftRoom.Code(), Team: *team})
+	_ = json.NewEncoder(w).Encode(roomJoinResponse{OK: true, Code: a.room.Code(), Team: *team})
 }
 
 func decodeRoomJoinRequest(r *http.Request) (roomJoinRequest, error) {
 	var request roomJoinRequest
 	contentType := r.Header.Get("Content-Type")
 	if strings.Contains(contentType, "application/json") {
-		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		if err := handlers.DecodeJSON(r, &request); err != nil {
 			return request, err
 		}
 		return request, nil
@@ -229,8 +230,8 @@ func cleanRoomValue(value string, maxLength int) string {
 	return value
 }
 
-func findTeamByID(teamID string) (*models.Team, error) {
-	state, err := dataStore.GetState()
+func (a *App) findTeamByID(teamID string) (*models.Team, error) {
+	state, err := a.Store.GetState()
 	if err != nil {
 		return nil, err
 	}
@@ -240,59 +241,79 @@ func findTeamByID(teamID string) (*models.Team, error) {
 			return &teamCopy, nil
 		}
 	}
-	return nil, fmt.Errorf("team not found")
+	return nil, dal.ErrTeamNotFound
 }
 
-func publishRoomJoinEvents(team *models.Team) {
-	if ps == nil || team == nil {
+func (a *App) publishRoomJoinEvents(team *models.Team) {
+	if team == nil {
 		return
 	}
-	ps.Publish(pubsub.Event{
+	a.Events.Publish(pubsub.Event{
 		Type: "teams:add",
 		Payload: map[string]interface{}{
 			"id": team.ID,
 		},
+		Room: a.room.Code(),
 	})
-	ps.Publish(pubsub.Event{
+	if !dal.ChatEnabled() {
+		return
+	}
+	a.Events.Publish(pubsub.Event{
 		Type: "chat:add",
 		Payload: map[string]interface{}{
 			"type": "system",
 		},
+		Room: a.room.Code(),
 	})
 }
 
-func publishRoomTeamUpdateEvent(team *models.Team) {
-	if ps == nil || team == nil {
+func (a *App) publishRoomTeamUpdateEvent(team *models.Team) {
+	if team == nil {
 		return
 	}
-	ps.Publish(pubsub.Event{
+	a.Events.Publish(pubsub.Event{
 		Type: "teams:update",
 		Payload: map[string]interface{}{
 			"id": team.ID,
 		},
+		Room: a.room.Code(),
 	})
 }
 
-func roomTemplateData(r *http.Request) map[string]string {
+// userRooms lists the rooms r's user gets room events for: the draft room,
+// for a commissioner or a stream that gives the room code, in the room query
+// parameter or the X-Jellycat-Room-Code header picks use.
+func (a *App) userRooms(r *http.Request) []string {
+	code := r.URL.Query().Get("room")
+	if code == "" {
+		code = r.Header.Get("X-Jellycat-Room-Code")
+	}
+	if !a.room.Matches(code) && !auth.IsAdmin(auth.GetUser(r)) {
+		return nil
+	}
+	return []string{a.room.Code()}
+}
+
+func (a *App) roomTemplateData(r *http.Request) map[string]string {
 	return map[string]string{
-		"RoomCode":   draftRoom.Code(),
-		"JoinPath":   joinPath(),
-		"JoinURL":    joinURL(r),
-		"JoinQRPath": joinQRPath(),
+		"RoomCode":   a.room.Code(),
+		"JoinPath":   a.joinPath(),
+		"JoinURL":    a.joinURL(r),
+		"JoinQRPath": a.joinQRPath(),
 	}
 }
 
-func joinPath() string {
-	return "/join?code=" + url.QueryEscape(draftRoom.Code())
+func (a *App) joinPath() string {
+	return "/join?code=" + url.QueryEscape(a.room.Code())
 }
 
-func joinQRPath() string {
-	return "/api/room/qr?code=" + url.QueryEscape(draftRoom.Code())
+func (a *App) joinQRPath() string {
+	return "/api/room/qr?code=" + url.QueryEscape(a.room.Code())
 }
 
-func joinURL(r *http.Request) string {
-	if publicURL := strings.TrimRight(os.Getenv("PUBLIC_URL"), "/"); publicURL != "" {
-		return publicURL + joinPath()
+func (a *App) joinURL(r *http.Request) string {
+	if a.Config.PublicURL != "" {
+		return a.Config.PublicURL + a.joinPath()
 	}
 
 	scheme := r.Header.Get("X-Forwarded-Proto")
@@ -304,5 +325,5 @@ func joinURL(r *http.Request) string {
 		}
 	}
 
-	return scheme + "://" + r.Host + joinPath()
+	return scheme + "://" + r.Host + a.joinPath()
 }
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+)
+
+func TestRoomJoinDefaultsTeamNameToUsername(t *testing.T) {
+	a := newBareApp(dal.NewMemoryDAL())
+
+	body := strings.NewReader(`{"code":"A123","username":"Taylor"}`)
+	request := httptest.NewRequest(http.MethodPost, "/api/room/join", body)
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+
+	a.roomJoinHandler(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body.String())
+	}
+
+	var response roomJoinResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if response.Team.Name != "Taylor" {
+		t.Fatalf("team name = %q, want %q", response.Team.Name, "Taylor")
+	}
+	if response.Team.Owner != "Taylor" {
+		t.Fatalf("team owner = %q, want %q", response.Team.Owner, "Taylor")
+	}
+}
+
+func TestRoomJoinClaimsUnassignedTeam(t *testing.T) {
+	store := dal.NewMemoryDAL()
+	team, err := store.AddTeam("Draft Slot 1", "", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	a := newBareApp(store)
+
+	body := strings.NewReader(`{"code":"A123","username":"Taylor","teamId":"` + team.ID + `"}`)
+	request := httptest.NewRequest(http.MethodPost, "/api/room/join", body)
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+
+	a.roomJoinHandler(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body.String())
+	}
+
+	var response roomJoinResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if response.Team.Name != "Draft Slot 1" {
+		t.Fatalf("team name = %q, want %q", response.Team.Name, "Draft Slot 1")
+	}
+	if response.Team.Owner != "Taylor" {
+		t.Fatalf("team owner = %q, want %q", response.Team.Owner, "Taylor")
+	}
+}
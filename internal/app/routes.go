@@ -0,0 +1,231 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/auth"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/clickhouse"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/handlers"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/middleware"
+)
+
+// routes adds the pages, the JSON API and the health checks to the base
+// routes.
+func (a *App) routes() *http.ServeMux {
+	mux := a.baseRoutes()
+
+	// Image serving from database (fallback to static files if not in DB)
+	mux.HandleFunc("/images/", a.serveImageHandler)
+
+	// Route groups: pages attach the session if there is one, signedIn
+	// redirects anonymous visitors to log in.
+	pages := middleware.New(middleware.Func(a.Auth.OptionalMiddleware))
+	signedIn := middleware.New(middleware.Func(a.Auth.Middleware))
+
+	// Impersonation: only a real commissioner may start it; the impersonated session ends it.
+	impersonation := handlers.NewImpersonationHandlers(a.Auth, a.Store)
+	mux.Handle("/auth/impersonate", pages.Append(a.Roles.Middleware(auth.RoleCommissioner)).ThenFunc(impersonation.Impersonate))
+	mux.Handle("/auth/unimpersonate", pages.Append(a.Roles.Middleware(auth.RoleSpectator)).ThenFunc(impersonation.Unimpersonate))
+
+	// Page routes
+	mux.HandleFunc("/", a.homeHandler)
+	mux.Handle("/start", pages.ThenFunc(a.startHandler))
+	mux.Handle("/draft", pages.ThenFunc(a.draftHandler))
+	mux.Handle("/join", pages.ThenFunc(a.pickHandler))
+	mux.Handle("/pick", pages.ThenFunc(a.pickHandler))
+	mux.Handle("/results", pages.ThenFunc(a.resultsHandler))
+	mux.Handle("/admin", signedIn.ThenFunc(a.adminHandler))
+
+	// API routes
+	api := handlers.NewAPIHandlers(a.Store, a.Events)
+	api.UsePickReservations(a.Reservations)
+	api.UseRooms(a.userRooms)
+	if a.ClickHouse != nil {
+		api.UsePlayerMetrics(a.ClickHouse)
+	}
+	a.registerAPIRoutes(mux, api)
+
+	// Health check endpoints
+	mux.HandleFunc("/api/health", a.healthHandler)
+	mux.HandleFunc("/healthz", a.livenessHandler) // Kubernetes liveness probe
+	mux.HandleFunc("/readyz", a.readinessHandler) // Kubernetes readiness probe
+	return mux
+}
+
+// registerAPIRoutes wires the JSON API. Role requirements per route:
+//   - reads and /api/me: anyone, including anonymous spectators
+//   - SSE, chat, team claiming and mock drafts: any authenticated user
+//   - picks: the owner of the team being picked for, or a commissioner
+//     (unclaimed teams still accept room-code picks); tokens need the draft
+//     scope for picks, reservations, nominations and bids
+//   - everything else: commissioner
+//
+// Each group is a middleware.Chain, so request-wide middlewares can be added
+// to its base in one place.
+func (a *App) registerAPIRoutes(mux *http.ServeMux, api *handlers.APIHandlers) {
+	public := middleware.New()
+	anyone := middleware.New(middleware.Func(a.Auth.OptionalMiddleware))
+	authenticated := anyone.Append(a.Roles.Middleware(auth.RoleSpectator))
+	commissioner := anyone.Append(a.Roles.Middleware(auth.RoleCommissioner))
+	chat := authenticated.Append(requireScope(auth.ScopeChat))
+	picks := anyone.Append(requireScope(auth.ScopeDraft), a.requireRoomCode)
+
+	// Draft API
+	mux.Handle("/api/draft/state", public.ThenFunc(api.GetDraftState))
+	mux.Handle("/api/draft/diff", public.ThenFunc(api.GetDraftDiff))
+	mux.Handle("/api/draft/suggest", public.ThenFunc(api.SuggestPick))
+	mux.Handle("/api/draft/pick", picks.ThenFunc(api.DraftPick))
+	mux.Handle("/api/draft/reserve", picks.ThenFunc(api.ReservePick))
+	mux.Handle("/api/draft/reset", commissioner.ThenFunc(api.ResetDraft))
+	mux.Handle("/api/draft/undo", commissioner.ThenFunc(api.UndraftPlayer))
+	mux.Handle("/api/draft/undo-last", commissioner.ThenFunc(api.UndoLastPick))
+	mux.Handle("/api/draft/swap", commissioner.ThenFunc(api.SwapPicks))
+	mux.Handle("/api/draft/settings", commissioner.ThenFunc(api.UpdateDraftSettings))
+	mux.Handle("/api/draft/randomize-order", commissioner.ThenFunc(api.RandomizeTeamOrder))
+	mux.Handle("/api/draft/window", readOr(public.ThenFunc(api.GetDraftWindow), commissioner.ThenFunc(api.SetDraftWindow)))
+	mux.Handle("/api/draft/meta", readOr(public.ThenFunc(api.GetDraftMeta), commissioner.ThenFunc(api.SetDraftMeta)))
+	mux.Handle("/api/draft/export", public.ThenFunc(api.ExportDraft))
+	mux.Handle("/api/room", public.ThenFunc(a.roomInfoHandler))
+	mux.Handle("/api/room/qr", public.ThenFunc(a.roomQRHandler))
+	mux.Handle("/api/room/join", public.ThenFunc(a.roomJoinHandler))
+	mux.Handle("/api/me", anyone.ThenFunc(api.Me))
+	mux.Handle("/api/stats", public.ThenFunc(api.GetStats))
+
+	// Practice drafts, private to the caller's session
+	mock := handlers.NewMockDraftHandlers(a.Store)
+	mux.Handle("/api/draft/mock/start", authenticated.ThenFunc(mock.StartMockDraft))
+	mux.Handle("/api/draft/mock/state", authenticated.ThenFunc(mock.GetMockDraftState))
+	mux.Handle("/api/draft/mock/pick", authenticated.ThenFunc(mock.MockDraftPick))
+	mux.Handle("/api/draft/mock/reset", authenticated.ThenFunc(mock.ResetMockDraft))
+
+	// Auction draft API (DRAFT_MODE=auction)
+	mux.Handle("/api/auction/nominate", picks.ThenFunc(api.NominatePlayer))
+	mux.Handle("/api/auction/bid", picks.ThenFunc(api.PlaceBid))
+	mux.Handle("/api/auction/award", commissioner.ThenFunc(api.AwardPlayer))
+
+	// Teams API
+	mux.Handle("/api/teams", public.ThenFunc(api.ListTeams))
+	mux.Handle("/api/teams/players", public.ThenFunc(api.GetTeamPlayers))
+	mux.Handle("/api/teams/add", commissioner.ThenFunc(api.AddTeam))
+	mux.Handle("/api/teams/bulk", commissioner.ThenFunc(api.AddTeams))
+	mux.Handle("/api/teams/update", commissioner.ThenFunc(api.UpdateTeam))
+	mux.Handle("/api/teams/delete", commissioner.ThenFunc(api.DeleteTeam))
+	mux.Handle("/api/teams/reorder", commissioner.ThenFunc(api.ReorderTeams))
+	mux.Handle("/api/teams/claim", authenticated.ThenFunc(api.ClaimTeam))
+	var engagement clickhouse.EngagementSource
+	if source, ok := a.ClickHouse.(clickhouse.EngagementSource); ok {
+		engagement = source
+	}
+	mux.Handle("/api/teams/engagement", public.ThenFunc(handlers.NewEngagementHandlers(a.Store, engagement).GetTeamEngagement))
+
+	// Players API
+	mux.Handle("/api/players/add", commissioner.ThenFunc(api.AddPlayer))
+	mux.Handle("/api/players/update", commissioner.ThenFunc(api.UpdatePlayer))
+	mux.Handle("/api/players/delete", commissioner.ThenFunc(api.DeletePlayer))
+	mux.Handle("/api/players/points", commissioner.ThenFunc(api.SetPlayerPoints))
+	mux.Handle("/api/players/profile", public.ThenFunc(api.GetPlayerProfile))
+	if history, ok := a.ClickHouse.(clickhouse.CuddleHistorySource); ok {
+		mux.Handle("/api/players/cuddle-history", public.ThenFunc(handlers.NewCuddleHistoryHandlers(a.Store, history).GetCuddleHistory))
+	}
+
+	// Image upload API
+	mux.Handle("/api/images/upload", commissioner.ThenFunc(api.UploadImage))
+	mux.Handle("/api/images/list", public.ThenFunc(api.ListImages))
+
+	// Chat API
+	mux.Handle("/api/chat/list", public.ThenFunc(api.ListChat))
+	mux.Handle("/api/chat/since", public.ThenFunc(api.GetChatSince))
+	mux.Handle("/api/chat/top-reactions", public.ThenFunc(api.GetTopReactions))
+	mux.Handle("/api/chat/send", chat.ThenFunc(api.SendChatMessage))
+	mux.Handle("/api/chat/react", chat.ThenFunc(api.AddReaction))
+	mux.Handle("/api/chat/reactions", public.ThenFunc(api.GetReactionUsers))
+	mux.Handle("/api/chat/clear", commissioner.ThenFunc(api.ClearChat))
+	mux.Handle("/api/chat/restore", commissioner.ThenFunc(api.RestoreChat))
+
+	// Personal access tokens API
+	if a.TokenAuth != nil {
+		mux.Handle("/api/tokens", anyone.ThenFunc(handlers.NewTokenHandlers(a.TokenAuth).Tokens))
+	}
+
+	// Run the cuddle points sync on demand
+	mux.Handle("/api/admin/sync", commissioner.ThenFunc(a.adminSyncHandler))
+	mux.Handle("/api/admin/sync-status", commissioner.ThenFunc(a.adminSyncStatusHandler))
+	mux.Handle("/api/admin/cuddle-formula", commissioner.ThenFunc(a.adminCuddleFormulaHandler))
+
+	// Authentication audit log
+	if a.AuthEvents != nil {
+		mux.Handle("/api/admin/auth-events", commissioner.ThenFunc(handlers.NewAuthEventHandlers(a.AuthEvents).ListAuthEvents))
+	}
+
+	// SSE for realtime updates, for signed-in users only so anonymous
+	// clients can't watch the draft; the session is attached for expiry warnings
+	mux.Handle("/api/events", authenticated.ThenFunc(api.EventsSSE))
+}
+
+// readOr serves GET and HEAD with read and every other method with write,
+// for routes whose reads are public but whose writes need a role.
+func readOr(read, write http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			read.ServeHTTP(w, r)
+			return
+		}
+		write.ServeHTTP(w, r)
+	})
+}
+
+// requireScope rejects token-authenticated requests that lack scope.
+// Anonymous requests and browser sessions pass through unchanged.
+func requireScope(scope string) middleware.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if user := auth.GetUser(r); user != nil && !user.HasScope(scope) {
+				http.Error(w, "Forbidden: token lacks "+scope+" scope", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (a *App) requireRoomCode(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		code := r.Header.Get("X-Jellycat-Room-Code")
+		if code == "" && strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, "Failed to read request", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			var payload struct {
+				Code     string `json:"code"`
+				RoomCode string `json:"roomCode"`
+			}
+			if err := json.Unmarshal(body, &payload); err == nil {
+				code = payload.Code
+				if code == "" {
+					code = payload.RoomCode
+				}
+			}
+		}
+
+		if !a.room.Matches(code) {
+			http.Error(w, "Invalid room code", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,412 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/auth"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/pubsub"
+)
+
+// headerAuthProvider authenticates test requests by the X-Test-Role header.
+type headerAuthProvider struct {
+	users map[string]*auth.User
+}
+
+func (p *headerAuthProvider) LoginHandler(w http.ResponseWriter, r *http.Request)    {}
+func (p *headerAuthProvider) CallbackHandler(w http.ResponseWriter, r *http.Request) {}
+func (p *headerAuthProvider) LogoutHandler(w http.ResponseWriter, r *http.Request)   {}
+func (p *headerAuthProvider) SessionHandler(w http.ResponseWriter, r *http.Request)  {}
+
+func (p *headerAuthProvider) RefreshSessionHandler(w http.ResponseWriter, r *http.Request) {}
+
+func (p *headerAuthProvider) Impersonate(w http.ResponseWriter, r *http.Request, target *auth.User) error {
+	return nil
+}
+
+func (p *headerAuthProvider) Unimpersonate(w http.ResponseWriter, r *http.Request) error {
+	return auth.ErrNotImpersonating
+}
+
+func (p *headerAuthProvider) Middleware(next http.HandlerFunc) http.HandlerFunc {
+	return p.OptionalMiddleware(next)
+}
+
+func (p *headerAuthProvider) OptionalMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if user := p.users[r.Header.Get("X-Test-Role")]; user != nil {
+			r = r.WithContext(context.WithValue(r.Context(), "user", user)) //nolint:staticcheck
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+func requestWithUser(request *http.Request, user *auth.User) *http.Request {
+	return request.WithContext(context.WithValue(request.Context(), "user", user)) //nolint:staticcheck
+}
+
+// TestNewAppRegistersAPIRoutes checks NewApp's mux serves the API, pages and
+// health checks, with the API's role guards in front of it.
+func TestNewAppRegistersAPIRoutes(t *testing.T) {
+	a := newTestApp(t, testConfig(t))
+
+	for _, route := range []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodGet, "/api/draft/state", http.StatusOK},
+		{http.MethodGet, "/api/teams", http.StatusOK},
+		{http.MethodGet, "/api/room", http.StatusOK},
+		{http.MethodGet, "/api/health", http.StatusOK},
+		{http.MethodGet, "/readyz", http.StatusOK},
+		{http.MethodGet, "/start", http.StatusOK},
+		{http.MethodPost, "/api/draft/pick", http.StatusUnauthorized},
+		{http.MethodPost, "/api/chat/send", http.StatusUnauthorized},
+		{http.MethodPost, "/api/teams/claim", http.StatusUnauthorized},
+		{http.MethodPost, "/api/draft/reset", http.StatusUnauthorized},
+		{http.MethodPost, "/api/players/add", http.StatusUnauthorized},
+		{http.MethodPost, "/api/admin/sync", http.StatusUnauthorized},
+		{http.MethodGet, "/api/events", http.StatusUnauthorized},
+	} {
+		recorder := httptest.NewRecorder()
+		a.Mux.ServeHTTP(recorder, httptest.NewRequest(route.method, route.path, nil))
+		if recorder.Code != route.want {
+			t.Errorf("anonymous %s %s = %d, want %d", route.method, route.path, recorder.Code, route.want)
+		}
+	}
+}
+
+type routeFixture struct {
+	app         *App
+	ownedTeamID string
+	openTeamID  string
+	playerID    string
+}
+
+func newRouteFixture(t *testing.T) routeFixture {
+	t.Helper()
+
+	store := dal.NewMemoryDAL()
+	owned, err := store.AddTeam("Owned", "owner", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	if _, err := store.ClaimTeam(owned.ID, "user-owner", "owner"); err != nil {
+		t.Fatalf("ClaimTeam() failed: %v", err)
+	}
+	open, err := store.AddTeam("Open", "", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	player, err := store.AddPlayer(&models.Player{Name: "Route Pick", Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+
+	a := newBareApp(store)
+	a.Auth = &headerAuthProvider{users: map[string]*auth.User{
+		"spectator":    {ID: "user-spectator", Username: "spectator"},
+		"owner":        {ID: "user-owner", Username: "owner"},
+		"commissioner": {ID: "user-commish", Username: "commish", Groups: []string{"admins"}},
+	}}
+	a.Roles = auth.NewRoles(store)
+	a.AuthEvents = store
+	a.Mux = a.routes()
+	return routeFixture{app: a, ownedTeamID: owned.ID, openTeamID: open.ID, playerID: player.ID}
+}
+
+func TestAPIRoutesEnforceRoleMatrix(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("AUTH_ADMIN_CLAIM", "")
+	t.Setenv("AUTH_ADMIN_VALUE", "")
+
+	// minimum is the least privileged role allowed; "" means anonymous callers are allowed too.
+	routes := []struct {
+		method  string
+		path    string
+		body    func(routeFixture) string
+		minimum auth.Role
+	}{
+		{http.MethodGet, "/api/draft/state", nil, ""},
+		{http.MethodGet, "/api/teams", nil, ""},
+		{http.MethodGet, "/api/chat/list", nil, ""},
+		{http.MethodGet, "/api/me", nil, ""},
+		{http.MethodGet, "/api/stats", nil, ""},
+		{http.MethodGet, "/api/images/list", nil, ""},
+		{http.MethodGet, "/api/draft/window", nil, ""},
+		{http.MethodGet, "/api/draft/meta", nil, ""},
+		{http.MethodGet, "/api/draft/suggest?teamId=missing", nil, ""},
+		{http.MethodPost, "/api/chat/send", func(routeFixture) string { return `{"text":"hello"}` }, auth.RoleSpectator},
+		{http.MethodPost, "/api/chat/react", func(routeFixture) string { return `{"messageId":"missing","emote":"👍"}` }, auth.RoleSpectator},
+		{http.MethodPost, "/api/teams/claim", func(f routeFixture) string { return `{"teamId":"` + f.openTeamID + `"}` }, auth.RoleSpectator},
+		{http.MethodPost, "/api/draft/pick", func(f routeFixture) string {
+			return `{"playerId":"` + f.playerID + `","teamId":"` + f.ownedTeamID + `"}`
+		}, auth.RoleOwner},
+		{http.MethodPost, "/api/draft/reserve", func(f routeFixture) string {
+			return `{"playerId":"` + f.playerID + `","teamId":"` + f.ownedTeamID + `"}`
+		}, auth.RoleOwner},
+		{http.MethodPost, "/api/draft/reset", nil, auth.RoleCommissioner},
+		{http.MethodPost, "/api/chat/clear", nil, auth.RoleCommissioner},
+		{http.MethodPost, "/api/draft/undo", func(f routeFixture) string { return `{"playerId":"` + f.playerID + `"}` }, auth.RoleCommissioner},
+		{http.MethodPost, "/api/draft/undo-last", nil, auth.RoleCommissioner},
+		{http.MethodPost, "/api/draft/swap", nil, auth.RoleCommissioner},
+		{http.MethodPost, "/api/draft/settings", func(routeFixture) string { return `{"mode":"snake"}` }, auth.RoleCommissioner},
+		{http.MethodPost, "/api/draft/window", func(routeFixture) string { return `{"closesAt":"2099-01-01T00:00:00Z"}` }, auth.RoleCommissioner},
+		{http.MethodPost, "/api/draft/meta", func(routeFixture) string { return `{"title":"League"}` }, auth.RoleCommissioner},
+		{http.MethodPost, "/api/teams/add", func(routeFixture) string { return `{"name":"New"}` }, auth.RoleCommissioner},
+		{http.MethodPost, "/api/teams/bulk", func(routeFixture) string { return `[{"name":"Bulk"}]` }, auth.RoleCommissioner},
+		{http.MethodPost, "/api/teams/update", func(f routeFixture) string { return `{"id":"` + f.openTeamID + `","name":"Renamed"}` }, auth.RoleCommissioner},
+		{http.MethodPost, "/api/teams/delete", func(f routeFixture) string { return `{"id":"` + f.openTeamID + `"}` }, auth.RoleCommissioner},
+		{http.MethodPost, "/api/draft/randomize-order", nil, auth.RoleCommissioner},
+		{http.MethodPost, "/api/teams/reorder", func(f routeFixture) string { return `{"order":["` + f.openTeamID + `"]}` }, auth.RoleCommissioner},
+		{http.MethodPost, "/api/players/add", func(routeFixture) string {
+			return `{"name":"Added","position":"CC","team":"Test","points":1,"tier":"B"}`
+		}, auth.RoleCommissioner},
+		{http.MethodPost, "/api/players/update", func(f routeFixture) string { return `{"id":"` + f.playerID + `","name":"Updated"}` }, auth.RoleCommissioner},
+		{http.MethodPost, "/api/players/delete", func(f routeFixture) string { return `{"id":"` + f.playerID + `"}` }, auth.RoleCommissioner},
+		{http.MethodPost, "/api/players/points", func(f routeFixture) string { return `{"id":"` + f.playerID + `","points":5}` }, auth.RoleCommissioner},
+		{http.MethodPost, "/api/images/upload", nil, auth.RoleCommissioner},
+		{http.MethodGet, "/api/admin/auth-events", nil, auth.RoleCommissioner},
+		{http.MethodGet, "/api/admin/sync-status", nil, auth.RoleCommissioner},
+		{http.MethodGet, "/api/admin/cuddle-formula", nil, auth.RoleCommissioner},
+	}
+	callers := []auth.Role{"", auth.RoleSpectator, auth.RoleOwner, auth.RoleCommissioner}
+
+	for _, route := range routes {
+		for _, caller := range callers {
+			name := string(caller)
+			if name == "" {
+				name = "anonymous"
+			}
+			t.Run(route.path+"/"+name, func(t *testing.T) {
+				fixture := newRouteFixture(t)
+
+				body := ""
+				if route.body != nil {
+					body = route.body(fixture)
+				}
+				request := httptest.NewRequest(route.method, route.path, strings.NewReader(body))
+				request.Header.Set("Content-Type", "application/json")
+				request.Header.Set("X-Jellycat-Room-Code", "A123")
+				request.Header.Set("X-Test-Role", string(caller))
+				recorder := httptest.NewRecorder()
+				fixture.app.Mux.ServeHTTP(recorder, request)
+
+				allowed := route.minimum == "" || (caller != "" && caller.Includes(route.minimum))
+				rejected := recorder.Code == http.StatusUnauthorized || recorder.Code == http.StatusForbidden
+				switch {
+				case allowed && rejected:
+					t.Fatalf("status = %d, want access allowed: %s", recorder.Code, recorder.Body.String())
+				case !allowed && caller == "" && recorder.Code != http.StatusUnauthorized:
+					t.Fatalf("status = %d, want %d", recorder.Code, http.StatusUnauthorized)
+				case !allowed && caller != "" && recorder.Code != http.StatusForbidden:
+					t.Fatalf("status = %d, want %d", recorder.Code, http.StatusForbidden)
+				}
+			})
+		}
+	}
+}
+
+func TestDraftRoutesRequireDraftScope(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("AUTH_ADMIN_CLAIM", "")
+	t.Setenv("AUTH_ADMIN_VALUE", "")
+
+	for scope, want := range map[string]int{
+		auth.ScopeRead:  http.StatusForbidden,
+		auth.ScopeChat:  http.StatusForbidden,
+		auth.ScopeDraft: http.StatusOK,
+	} {
+		t.Run(scope, func(t *testing.T) {
+			fixture := newRouteFixture(t)
+			a := fixture.app
+			a.TokenAuth = auth.NewTokenAuth(a.Store.(dal.TokenStore))
+			a.Auth = auth.WithAccessTokens(a.Auth, a.TokenAuth)
+			a.Mux = a.routes()
+			_, secret, err := a.TokenAuth.Create(&auth.User{ID: "user-owner", Username: "owner"}, "bot", []string{scope})
+			if err != nil {
+				t.Fatalf("Create() failed: %v", err)
+			}
+
+			body := `{"playerId":"` + fixture.playerID + `","teamId":"` + fixture.ownedTeamID + `"}`
+			request := httptest.NewRequest(http.MethodPost, "/api/draft/pick", strings.NewReader(body))
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set("X-Jellycat-Room-Code", "A123")
+			request.Header.Set("Authorization", "Bearer "+secret)
+			recorder := httptest.NewRecorder()
+			a.Mux.ServeHTTP(recorder, request)
+
+			if recorder.Code != want {
+				t.Fatalf("pick with a %s token = %d, want %d: %s", scope, recorder.Code, want, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestEventsRequireLogin(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+
+	server := httptest.NewServer(newRouteFixture(t).app.Mux)
+	defer server.Close()
+
+	subscribe := func(role string) *http.Response {
+		t.Helper()
+		request, _ := http.NewRequest(http.MethodGet, server.URL+"/api/events", nil)
+		request.Header.Set("X-Test-Role", role)
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("GET /api/events as %q: %v", role, err)
+		}
+		return response
+	}
+
+	anonymous := subscribe("")
+	anonymous.Body.Close()
+	if anonymous.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("anonymous subscribe status = %d, want %d", anonymous.StatusCode, http.StatusUnauthorized)
+	}
+
+	spectator := subscribe("spectator")
+	defer spectator.Body.Close()
+	if spectator.StatusCode != http.StatusOK {
+		t.Fatalf("spectator subscribe status = %d, want %d", spectator.StatusCode, http.StatusOK)
+	}
+	line, err := bufio.NewReader(spectator.Body).ReadString('\n')
+	if err != nil || !strings.Contains(line, `"type":"connected"`) {
+		t.Fatalf("first event = %q (%v), want the connected event", line, err)
+	}
+}
+
+func TestCommissionerRoleRequiresLogin(t *testing.T) {
+	called := false
+	handler := auth.NewRoles(dal.NewMemoryDAL()).Require(auth.RoleCommissioner, func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/players/update", nil))
+
+	if recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusUnauthorized)
+	}
+	if called {
+		t.Fatal("handler should not be called without a logged-in user")
+	}
+}
+
+func TestCommissionerRoleRequiresAdmin(t *testing.T) {
+	t.Setenv("AUTH_ADMIN_CLAIM", "email")
+	t.Setenv("AUTH_ADMIN_VALUE", "billy.davies.10@icloud.com")
+
+	called := false
+	handler := auth.NewRoles(dal.NewMemoryDAL()).Require(auth.RoleCommissioner, func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	request := requestWithUser(httptest.NewRequest(http.MethodPost, "/api/players/update", nil), &auth.User{Email: "not-admin@example.com"})
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusForbidden)
+	}
+	if called {
+		t.Fatal("handler should not be called for a non-admin user")
+	}
+}
+
+func TestCommissionerRoleAllowsAdmin(t *testing.T) {
+	t.Setenv("AUTH_ADMIN_CLAIM", "email")
+	t.Setenv("AUTH_ADMIN_VALUE", "billy.davies.10@icloud.com")
+
+	called := false
+	handler := auth.NewRoles(dal.NewMemoryDAL()).Require(auth.RoleCommissioner, func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	request := requestWithUser(httptest.NewRequest(http.MethodPost, "/api/players/update", nil), &auth.User{Email: "billy.davies.10@icloud.com"})
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusAccepted)
+	}
+	if !called {
+		t.Fatal("handler should be called for the configured admin user")
+	}
+}
+
+// TestEventsOnlyReachRoomMembers streams /api/events for a spectator who gave
+// the room code and one who didn't, and checks only the first hears a team
+// join the room.
+func TestEventsOnlyReachRoomMembers(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+
+	fixture := newRouteFixture(t)
+	server := httptest.NewServer(fixture.app.Mux)
+	// Cleanups run last first, so the streams close before the server
+	t.Cleanup(server.Close)
+
+	subscribe := func(query string) *bufio.Reader {
+		t.Helper()
+		request, _ := http.NewRequest(http.MethodGet, server.URL+"/api/events"+query, nil)
+		request.Header.Set("X-Test-Role", "spectator")
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("GET /api/events%s: %v", query, err)
+		}
+		t.Cleanup(func() { response.Body.Close() })
+		stream := bufio.NewReader(response.Body)
+		// The stream is subscribed once it says it is connected
+		if line, err := stream.ReadString('\n'); err != nil || !strings.Contains(line, `"type":"connected"`) {
+			t.Fatalf("first event = %q (%v), want the connected event", line, err)
+		}
+		return stream
+	}
+	// nextEvent returns the type of the next event on stream.
+	nextEvent := func(stream *bufio.Reader) string {
+		t.Helper()
+		for {
+			line, err := stream.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read event: %v", err)
+			}
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				var event pubsub.Event
+				if err := json.Unmarshal([]byte(data), &event); err != nil {
+					t.Fatalf("decode event %q: %v", data, err)
+				}
+				return event.Type
+			}
+		}
+	}
+	member := subscribe("?room=A123")
+	outsider := subscribe("?room=ZZ99")
+
+	join := httptest.NewRequest(http.MethodPost, "/api/room/join", strings.NewReader(`{"code":"A123","username":"Taylor"}`))
+	join.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	fixture.app.Mux.ServeHTTP(recorder, join)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("join status = %d: %s", recorder.Code, recorder.Body.String())
+	}
+	// Every stream gets events for no room, so the outsider's next event
+	// after the join shows whether the join's reached it.
+	fixture.app.Events.Publish(pubsub.Event{Type: "draft:settings"})
+
+	if got := nextEvent(member); got != "teams:add" {
+		t.Fatalf("room member's next event = %q, want teams:add", got)
+	}
+	if got := nextEvent(outsider); got != "draft:settings" {
+		t.Fatalf("outsider's next event = %q, want draft:settings with the room's events withheld", got)
+	}
+}
@@ -0,0 +1,123 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/clickhouse"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/pubsub"
+)
+
+// adminSyncHandler runs a cuddle points sync now and returns its summary.
+func (a *App) adminSyncHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if a.syncer == nil {
+		http.Error(w, "Cuddle points sync is not running", http.StatusServiceUnavailable)
+		return
+	}
+
+	result, err := a.syncer.RunManually(r.Context())
+	if errors.Is(err, clickhouse.ErrSyncInProgress) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Cuddle points sync failed: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// adminSyncStatusHandler reports the cuddle points sync's recent runs,
+// newest first.
+func (a *App) adminSyncStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if a.syncer == nil {
+		http.Error(w, "Cuddle points sync is not running", http.StatusServiceUnavailable)
+		return
+	}
+
+	lastSuccess, failures := a.syncer.Status()
+	response := map[string]interface{}{
+		"lastSuccessfulSync":  nil,
+		"consecutiveFailures": failures,
+		"overlaps":            a.syncer.Overlaps(),
+		"runs":                a.syncer.Runs(),
+	}
+	if !lastSuccess.IsZero() {
+		response["lastSuccessfulSync"] = lastSuccess.Unix()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// adminCuddleFormulaHandler reports the formula behind the current cuddle points.
+func (a *App) adminCuddleFormulaHandler(w http.ResponseWriter, r *http.Request) {
+	source, ok := a.ClickHouse.(clickhouse.FormulaSource)
+	if !ok {
+		http.Error(w, "ClickHouse is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(source.Formula())
+}
+
+// newCuddleSyncer syncs client's cuddle points into the data store,
+// publishes players:cuddleSync when a sync moves any and ops:syncFailed when
+// the sync keeps failing.
+func (a *App) newCuddleSyncer(client clickhouse.CuddlePointsClient) *clickhouse.Syncer {
+	syncer := clickhouse.NewSyncer(client, func(playerID string, points int) error {
+		_, err := a.Store.SetPlayerPoints(playerID, points)
+		if errors.Is(err, dal.ErrPlayerNotFound) {
+			return fmt.Errorf("%w: %s", clickhouse.ErrUnknownPlayer, playerID)
+		}
+		return err
+	})
+	syncer.Current = func() (map[string]int, error) {
+		state, err := a.Store.GetState()
+		if err != nil {
+			return nil, err
+		}
+		points := make(map[string]int, len(state.Players))
+		for _, player := range state.Players {
+			points[player.ID] = player.Points
+		}
+		return points, nil
+	}
+	syncer.OnChanged = func(changes []clickhouse.PointsChange) {
+		a.Events.Publish(pubsub.Event{Type: "players:cuddleSync", Payload: cuddleSyncPayload(changes)})
+	}
+	syncer.OnAlert = func(failures int, lastSuccess time.Time, err error) {
+		payload := map[string]interface{}{
+			"consecutiveFailures": failures,
+			"error":               err.Error(),
+		}
+		if !lastSuccess.IsZero() {
+			payload["lastSuccess"] = lastSuccess.UTC().Format(time.RFC3339)
+		}
+		a.Events.Publish(pubsub.Event{Type: "ops:syncFailed", Payload: payload})
+	}
+	return syncer
+}
+
+// cuddleSyncTopMovers is how many of a sync's biggest changes
+// players:cuddleSync carries.
+const cuddleSyncTopMovers = 5
+
+// cuddleSyncPayload summarizes a sync's changes, which come biggest first.
+func cuddleSyncPayload(changes []clickhouse.PointsChange) map[string]interface{} {
+	return map[string]interface{}{
+		"changed":   len(changes),
+		"topMovers": changes[:min(len(changes), cuddleSyncTopMovers)],
+	}
+}
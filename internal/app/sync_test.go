@@ -0,0 +1,279 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/clickhouse"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/mocks"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/pubsub"
+)
+
+func TestCuddleSyncerUsesMockClickHouse(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "development")
+
+	sqliteStore, err := dal.NewSQLiteDAL(t.TempDir() + "/draft.sqlite")
+	if err != nil {
+		t.Fatalf("NewSQLiteDAL() failed: %v", err)
+	}
+	for name, store := range map[string]dal.DraftDAL{"memory": dal.NewMemoryDAL(), "sqlite": sqliteStore} {
+		t.Run(name, func(t *testing.T) {
+			a := newBareApp(store)
+			if _, err := a.Store.SetPlayerPoints("1", 0); err != nil {
+				t.Fatalf("SetPlayerPoints() failed: %v", err)
+			}
+			syncer := a.newCuddleSyncer(mocks.NewMockClickHouseClient())
+
+			// A sync whose deadline has already passed gives up without writing.
+			cancelled, cancel := context.WithCancel(context.Background())
+			cancel()
+			if _, err := syncer.RunOnce(cancelled); err == nil {
+				t.Fatal("RunOnce() with a cancelled context succeeded")
+			}
+			if state, _ := a.Store.GetState(); playerPoints(state, "1") != 0 {
+				t.Fatalf("cancelled sync wrote points: %d", playerPoints(state, "1"))
+			}
+
+			// Player 2 was retired from the draft but ClickHouse still scores it.
+			if err := a.Store.DeletePlayer("2"); err != nil {
+				t.Fatalf("DeletePlayer() failed: %v", err)
+			}
+			result, err := syncer.RunOnce(context.Background())
+			if err != nil {
+				t.Fatalf("RunOnce() failed: %v", err)
+			}
+			if result.Updated != 17 || result.Skipped != 1 || len(result.Errors) != 0 {
+				t.Fatalf("RunOnce() = %+v, want 17 updated and the retired player skipped", result)
+			}
+			state, err := a.Store.GetState()
+			if err != nil {
+				t.Fatalf("GetState() failed: %v", err)
+			}
+			// The mock's formula rounds Bashful Bunny's 324 base points to 321, and
+			// jitter moves that by up to 10%: 321 - 32 to 321 + 31.
+			if points := playerPoints(state, "1"); points < 289 || points > 352 {
+				t.Fatalf("Bashful Bunny points = %d, want the mock's 321 ± 10%%", points)
+			}
+		})
+	}
+}
+
+func TestCuddleSyncPublishesOneSummaryEvent(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "development")
+	a := newBareApp(dal.NewMemoryDAL())
+	subscription := a.Events.Subscribe()
+
+	if _, err := a.Store.SetPlayerPoints("1", 0); err != nil {
+		t.Fatalf("SetPlayerPoints() failed: %v", err)
+	}
+	if _, err := a.newCuddleSyncer(mocks.NewMockClickHouseClient()).RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce() failed: %v", err)
+	}
+
+	var summaries []pubsub.Event
+	for len(subscription) > 0 {
+		if event := <-subscription; event.Type == "players:cuddleSync" {
+			summaries = append(summaries, event)
+		} else if event.Type == "players:updatePoints" {
+			t.Fatalf("sync published a per-player event: %+v", event)
+		}
+	}
+	if len(summaries) != 1 {
+		t.Fatalf("published %d players:cuddleSync events, want 1", len(summaries))
+	}
+	movers, _ := summaries[0].Payload["topMovers"].([]clickhouse.PointsChange)
+	if changed, _ := summaries[0].Payload["changed"].(int); changed == 0 || len(movers) == 0 || len(movers) > cuddleSyncTopMovers {
+		t.Fatalf("payload = %+v, want the changed count and up to %d top movers", summaries[0].Payload, cuddleSyncTopMovers)
+	}
+	// Bashful Bunny went from 0 to about 324, the biggest move of the run.
+	if movers[0].PlayerID != "1" {
+		t.Fatalf("top mover = %+v, want Bashful Bunny", movers[0])
+	}
+}
+
+func TestDraftEventsRecordInteractionsInMockClickHouse(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "development")
+	a := newBareApp(dal.NewMemoryDAL())
+
+	team, err := a.Store.AddTeam("Pickers", "", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	if _, err := a.Store.ClaimTeam(team.ID, "user-picker", "Picker"); err != nil {
+		t.Fatalf("ClaimTeam() failed: %v", err)
+	}
+	message, err := a.Store.AddChatMessage("Bashful Bunny is a steal", "user")
+	if err != nil {
+		t.Fatalf("AddChatMessage() failed: %v", err)
+	}
+
+	client := mocks.NewMockClickHouseClient()
+	writer := clickhouse.NewInteractionWriter(client)
+	events := pubsub.New()
+	origin := events.SubscribeOrigin()
+	done := make(chan struct{})
+	go func() {
+		a.recordInteractions(origin, writer)
+		close(done)
+	}()
+
+	events.Publish(pubsub.Event{Type: "draft:pick", Payload: map[string]interface{}{"playerId": "1", "teamId": team.ID}})
+	events.Publish(pubsub.Event{Type: "chat:add", Payload: map[string]interface{}{"id": message.ID}})
+	events.Publish(pubsub.Event{Type: "draft:reset"})
+	events.Unsubscribe(origin)
+	<-done
+	if err := writer.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+
+	recorded := client.Interactions()
+	if len(recorded) != 2 {
+		t.Fatalf("interactions = %+v, want a pick and a mention", recorded)
+	}
+	if pick := recorded[0]; pick.JellycatID != "1" || pick.UserID != "user-picker" || pick.Action != "pick" {
+		t.Fatalf("pick interaction = %+v, want player 1 credited to the team owner", pick)
+	}
+	if mention := recorded[1]; mention.JellycatID != "1" || mention.Action != "mention" {
+		t.Fatalf("chat interaction = %+v, want a mention of player 1", mention)
+	}
+
+	// Two distinct users add 20 points on top of the mock's 289 to 352 (see
+	// TestCuddleSyncerUsesMockClickHouse).
+	points, err := client.GetAllCuddlePoints(context.Background())
+	if err != nil {
+		t.Fatalf("GetAllCuddlePoints() failed: %v", err)
+	}
+	if points["1"] < 309 || points["1"] > 372 {
+		t.Fatalf("Bashful Bunny points = %d, want the interactions added", points["1"])
+	}
+}
+
+func playerPoints(state *models.DraftState, id string) int {
+	for _, player := range state.Players {
+		if player.ID == id {
+			return player.Points
+		}
+	}
+	return -1
+}
+
+// failingClickHouse fails every sync.
+type failingClickHouse struct{ *mocks.MockClickHouseClient }
+
+func (failingClickHouse) SyncCuddlePoints(ctx context.Context, updateFunc func(playerID string, points int) error) (clickhouse.SyncResult, error) {
+	return clickhouse.SyncResult{}, errors.New("clickhouse unavailable")
+}
+
+func TestCuddleSyncerAlertsAndReportsHealth(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "development")
+	t.Setenv("CUDDLE_SYNC_ALERT_AFTER", "2")
+	a := newBareApp(dal.NewMemoryDAL())
+	subscription := a.Events.Subscribe()
+
+	a.syncer = a.newCuddleSyncer(failingClickHouse{mocks.NewMockClickHouseClient()})
+	a.syncer.Attempts = 1
+	a.syncer.RunOnce(context.Background())
+	select {
+	case event := <-subscription:
+		t.Fatalf("alerted after one failed run: %+v", event)
+	default:
+	}
+	a.syncer.RunOnce(context.Background())
+	select {
+	case event := <-subscription:
+		if event.Type != "ops:syncFailed" || event.Payload["consecutiveFailures"] != 2 {
+			t.Fatalf("event = %+v, want ops:syncFailed after 2 failures", event)
+		}
+	default:
+		t.Fatal("no ops:syncFailed event after 2 failed runs")
+	}
+
+	recorder := httptest.NewRecorder()
+	a.healthHandler(recorder, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+	var health struct {
+		Checks map[string]map[string]interface{} `json:"checks"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&health); err != nil {
+		t.Fatalf("decode health: %v", err)
+	}
+	if check := health.Checks["cuddleSync"]; check["status"] != "unhealthy" || check["consecutiveFailures"] != float64(2) {
+		t.Fatalf("cuddleSync check = %v, want unhealthy with the failure streak", check)
+	}
+}
+
+func TestAdminSyncReturnsSummaryAndHealthShowsIt(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "development")
+	a := newBareApp(dal.NewMemoryDAL())
+	if err := a.Store.DeletePlayer("5"); err != nil {
+		t.Fatalf("DeletePlayer() failed: %v", err)
+	}
+	a.syncer = a.newCuddleSyncer(mocks.NewMockClickHouseClient())
+
+	recorder := httptest.NewRecorder()
+	a.adminSyncHandler(recorder, httptest.NewRequest(http.MethodPost, "/api/admin/sync", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var result clickhouse.SyncResult
+	if err := json.NewDecoder(recorder.Body).Decode(&result); err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+	if result.Updated != 17 || result.Skipped != 1 {
+		t.Fatalf("result = %+v, want 17 updated and 1 skipped", result)
+	}
+
+	recorder = httptest.NewRecorder()
+	a.healthHandler(recorder, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+	var health struct {
+		Checks map[string]map[string]interface{} `json:"checks"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&health); err != nil {
+		t.Fatalf("decode health: %v", err)
+	}
+	lastResult, _ := health.Checks["cuddleSync"]["lastResult"].(map[string]interface{})
+	if lastResult["skipped"] != float64(1) || lastResult["updated"] != float64(17) {
+		t.Fatalf("cuddleSync lastResult = %v, want the admin sync's summary", health.Checks["cuddleSync"])
+	}
+
+	recorder = httptest.NewRecorder()
+	a.adminSyncStatusHandler(recorder, httptest.NewRequest(http.MethodGet, "/api/admin/sync-status", nil))
+	var status struct {
+		LastSuccessfulSync *int64               `json:"lastSuccessfulSync"`
+		Runs               []clickhouse.SyncRun `json:"runs"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&status); err != nil {
+		t.Fatalf("decode sync status: %v", err)
+	}
+	if status.LastSuccessfulSync == nil || len(status.Runs) != 1 || status.Runs[0].Trigger != clickhouse.TriggerManual || status.Runs[0].Updated != 17 {
+		t.Fatalf("sync status = %+v, want the admin sync as the only run", status)
+	}
+}
+
+func TestAdminCuddleFormulaReportsTheClientsFormula(t *testing.T) {
+	t.Setenv("CUDDLE_WINDOW_DAYS", "7")
+	t.Setenv("CUDDLE_WEIGHT_USERS", "2.5")
+	a := newBareApp(dal.NewMemoryDAL())
+	recorder := httptest.NewRecorder()
+	a.adminCuddleFormulaHandler(recorder, httptest.NewRequest(http.MethodGet, "/api/admin/cuddle-formula", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status without ClickHouse = %d, want 503", recorder.Code)
+	}
+
+	a.ClickHouse = mocks.NewMockClickHouseClient()
+	recorder = httptest.NewRecorder()
+	a.adminCuddleFormulaHandler(recorder, httptest.NewRequest(http.MethodGet, "/api/admin/cuddle-formula", nil))
+	var formula clickhouse.Formula
+	if err := json.NewDecoder(recorder.Body).Decode(&formula); err != nil {
+		t.Fatalf("decode formula: %v", err)
+	}
+	want := clickhouse.Formula{WindowDays: 7, UniqueUserWeight: 2.5, InteractionWeight: 0.1, MinuteWeight: 1}
+	if formula != want {
+		t.Fatalf("formula = %+v, want %+v", formula, want)
+	}
+}
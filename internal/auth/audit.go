@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// Auth event types written to the audit log
+const (
+	EventLoginSuccess       = "login_success"
+	EventLoginFailure       = "login_failure"
+	EventLoginThrottled     = "login_throttled"
+	EventLogout             = "logout"
+	EventSessionExpired     = "session_expired"
+	EventSessionRefreshed   = "session_refreshed"
+	EventSessionInvalid     = "session_invalid"
+	EventBackchannelLogout  = "backchannel_logout"
+	EventImpersonationStart = "impersonation_start"
+	EventImpersonationEnd   = "impersonation_end"
+)
+
+// maxUserAgentLength keeps hostile user agents from bloating the audit log.
+const maxUserAgentLength = 256
+
+// AuditLog records authentication events. A nil AuditLog, or one without a
+// store, discards events so providers can record unconditionally.
+type AuditLog struct {
+	store dal.AuthEventStore
+}
+
+// NewAuditLog creates an audit log backed by store.
+func NewAuditLog(store dal.AuthEventStore) *AuditLog {
+	return &AuditLog{store: store}
+}
+
+// Store returns the underlying event store, or nil when events are discarded.
+func (a *AuditLog) Store() dal.AuthEventStore {
+	if a == nil {
+		return nil
+	}
+	return a.store
+}
+
+// Record writes an event for the request. user may be nil for failed logins,
+// in which case username is the name that was attempted.
+func (a *AuditLog) Record(r *http.Request, eventType string, user *User, username, detail string) {
+	if a == nil || a.store == nil {
+		return
+	}
+
+	event := &models.AuthEvent{
+		Type:      eventType,
+		Username:  username,
+		IP:        ClientIP(r),
+		UserAgent: r.UserAgent(),
+		Detail:    detail,
+	}
+	if len(event.UserAgent) > maxUserAgentLength {
+		event.UserAgent = event.UserAgent[:maxUserAgentLength]
+	}
+	if user != nil {
+		event.UserID = user.ID
+		event.Username = user.Username
+		if user.Impersonator != nil {
+			event.ImpersonatorID = user.Impersonator.ID
+		}
+	}
+
+	if err := a.store.RecordAuthEvent(event); err != nil {
+		logger.Error("Failed to record auth event", "error", err, "type", eventType)
+	}
+}
+
+// ClientIP returns the caller's address. X-Forwarded-For is only trusted when
+// AUTH_TRUST_PROXY=true, since clients can otherwise forge it to dodge rate limits.
+func ClientIP(r *http.Request) string {
+	if os.Getenv("AUTH_TRUST_PROXY") == "true" {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -2,17 +2,14 @@ package auth
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/base64"
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"strings"
-	"sync"
 	"time"
 
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
 	"golang.org/x/oauth2"
 )
 
@@ -23,6 +20,12 @@ type AuthentikConfig struct {
 	ClientSecret string
 	RedirectURL  string
 	Scopes       []string
+	// Slug is the Authentik application slug used for the JWKS and end-session URLs (default "jellycat-draft").
+	Slug string
+	// Sessions stores login sessions (default in-memory).
+	Sessions SessionStore
+	// Audit records sign-ins, sign-outs and expired sessions (optional).
+	Audit *AuditLog
 }
 
 // User represents an authenticated user
@@ -32,14 +35,19 @@ type User struct {
 	Name     string
 	Username string
 	Groups   []string
+	// Scopes limits what a personal access token may do; nil for interactive sessions.
+	Scopes []string
+	// Impersonator is the admin acting as this user; nil unless impersonating.
+	Impersonator *User `json:",omitempty"`
+	// commissioner is set on users restored from a cookie session, which keeps
+	// the admin check's result instead of the claims it reads.
+	commissioner bool
 }
 
-// AuthentikAuth manages authentication with Authentik
+// AuthentikAuth is an OIDCAuth preset for Authentik's /application/o/... URL layout.
 type AuthentikAuth struct {
-	config       *AuthentikConfig
-	oauth2Config *oauth2.Config
-	sessions     map[string]*Session
-	sessionMu    sync.RWMutex
+	*OIDCAuth
+	config *AuthentikConfig
 }
 
 // Session represents a user session
@@ -49,193 +57,62 @@ type Session struct {
 	Token     *oauth2.Token
 	CreatedAt time.Time
 	ExpiresAt time.Time
+	// IdP holds the sub and sid claims of the login's OIDC ID token so
+	// back-channel logout can find the session.
+	IdP dal.IdPSession
 }
 
 // NewAuthentikAuth creates a new Authentik authentication handler
 func NewAuthentikAuth(config *AuthentikConfig) *AuthentikAuth {
-	if len(config.Scopes) == 0 {
-		config.Scopes = []string{"openid", "profile", "email"}
+	if config.Slug == "" {
+		config.Slug = "jellycat-draft"
 	}
 
-	oauth2Config := &oauth2.Config{
+	baseURL := strings.TrimRight(config.BaseURL, "/")
+	oidc := newOIDCAuthWithEndpoints(&OIDCConfig{
+		IssuerURL:    fmt.Sprintf("%s/application/o/%s/", baseURL, config.Slug),
 		ClientID:     config.ClientID,
 		ClientSecret: config.ClientSecret,
 		RedirectURL:  config.RedirectURL,
 		Scopes:       config.Scopes,
-		Endpoint: oauth2.Endpoint{
-			AuthURL:  fmt.Sprintf("%s/application/o/authorize/", config.BaseURL),
-			TokenURL: fmt.Sprintf("%s/application/o/token/", config.BaseURL),
-		},
-	}
-
-	return &AuthentikAuth{
-		config:       config,
-		oauth2Config: oauth2Config,
-		sessions:     make(map[string]*Session),
-	}
-}
-
-// LoginHandler initiates the OAuth2 login flow
-func (a *AuthentikAuth) LoginHandler(w http.ResponseWriter, r *http.Request) {
-	// Generate state for CSRF protection
-	state := generateState()
-
-	// Store state in cookie
-	http.SetCookie(w, &http.Cookie{
-		Name:     "oauth_state",
-		Value:    state,
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   true,
-		SameSite: http.SameSiteLaxMode,
-		MaxAge:   300, // 5 minutes
-	})
-
-	// Redirect to Authentik
-	authURL := a.oauth2Config.AuthCodeURL(state)
-	http.Redirect(w, r, authURL, http.StatusTemporaryRedirect)
-}
-
-// CallbackHandler handles the OAuth2 callback from Authentik
-func (a *AuthentikAuth) CallbackHandler(w http.ResponseWriter, r *http.Request) {
-	// Verify state
-	stateCookie, err := r.Cookie("oauth_state")
-	if err != nil {
-		http.Error(w, "Missing state cookie", http.StatusBadRequest)
-		return
-	}
-
-	state := r.URL.Query().Get("state")
-	if state != stateCookie.Value {
-		http.Error(w, "Invalid state parameter", http.StatusBadRequest)
-		return
-	}
-
-	// Exchange code for token
-	code := r.URL.Query().Get("code")
-	token, err := a.oauth2Config.Exchange(context.Background(), code)
-	if err != nil {
-		http.Error(w, "Failed to exchange token: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	// Get user info
-	user, err := a.getUserInfo(token)
-	if err != nil {
-		http.Error(w, "Failed to get user info: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	// Create session
-	sessionID := generateSessionID()
-	session := &Session{
-		ID:        sessionID,
-		User:      user,
-		Token:     token,
-		CreatedAt: time.Now(),
-		ExpiresAt: token.Expiry,
-	}
-
-	a.sessionMu.Lock()
-	a.sessions[sessionID] = session
-	a.sessionMu.Unlock()
-
-	// Set session cookie
-	http.SetCookie(w, &http.Cookie{
-		Name:     "session_id",
-		Value:    sessionID,
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   true,
-		SameSite: http.SameSiteLaxMode,
-		Expires:  token.Expiry,
-	})
-
-	// Clear state cookie
-	http.SetCookie(w, &http.Cookie{
-		Name:   "oauth_state",
-		Value:  "",
-		Path:   "/",
-		MaxAge: -1,
+		Sessions:     config.Sessions,
+		Audit:        config.Audit,
+	}, OIDCEndpoints{
+		AuthorizationEndpoint: fmt.Sprintf("%s/application/o/authorize/", baseURL),
+		TokenEndpoint:         fmt.Sprintf("%s/application/o/token/", baseURL),
+		UserInfoEndpoint:      fmt.Sprintf("%s/application/o/userinfo/", baseURL),
+		JWKSURI:               fmt.Sprintf("%s/application/o/%s/jwks/", baseURL, config.Slug),
+		EndSessionEndpoint:    fmt.Sprintf("%s/application/o/%s/end-session/", baseURL, config.Slug),
 	})
 
-	// Redirect to app
-	http.Redirect(w, r, "/", http.StatusSeeOther)
-}
-
-// LogoutHandler handles user logout
-func (a *AuthentikAuth) LogoutHandler(w http.ResponseWriter, r *http.Request) {
-	// Get session cookie
-	cookie, err := r.Cookie("session_id")
-	if err == nil {
-		// Delete session
-		a.sessionMu.Lock()
-		delete(a.sessions, cookie.Value)
-		a.sessionMu.Unlock()
-	}
-
-	// Clear session cookie
-	http.SetCookie(w, &http.Cookie{
-		Name:   "session_id",
-		Value:  "",
-		Path:   "/",
-		MaxAge: -1,
-	})
-
-	// Redirect to Authentik logout
-	logoutURL := fmt.Sprintf("%s/application/o/jellycat-draft/end-session/", a.config.BaseURL)
-	http.Redirect(w, r, logoutURL, http.StatusSeeOther)
-}
-
-// Middleware protects routes requiring authentication
-func (a *AuthentikAuth) Middleware(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		user := a.userFromRequest(r)
-		if user == nil {
-			http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
-			return
-		}
-
-		ctx := context.WithValue(r.Context(), "user", user)
-		next.ServeHTTP(w, r.WithContext(ctx))
+	return &AuthentikAuth{
+		OIDCAuth: oidc,
+		config:   config,
 	}
 }
 
-// OptionalMiddleware attaches a user when a valid session exists, but allows anonymous reads.
-func (a *AuthentikAuth) OptionalMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		if user := a.userFromRequest(r); user != nil {
-			ctx := context.WithValue(r.Context(), "user", user)
-			next.ServeHTTP(w, r.WithContext(ctx))
-			return
-		}
-		next.ServeHTTP(w, r)
-	}
+// GetUser retrieves the authenticated user from the request context
+func GetUser(r *http.Request) *User {
+	return UserFromContext(r.Context())
 }
 
-func (a *AuthentikAuth) userFromRequest(r *http.Request) *User {
-	cookie, err := r.Cookie("session_id")
-	if err != nil {
-		return nil
-	}
-
-	a.sessionMu.RLock()
-	session, exists := a.sessions[cookie.Value]
-	a.sessionMu.RUnlock()
-
-	if !exists || session == nil || time.Now().After(session.ExpiresAt) {
+// UserFromContext returns the user the HTTP middlewares or the gRPC auth
+// interceptor attached to ctx, or nil for anonymous callers.
+func UserFromContext(ctx context.Context) *User {
+	user, ok := ctx.Value("user").(*User)
+	if !ok {
 		return nil
 	}
-	return session.User
+	return user
 }
 
-// GetUser retrieves the authenticated user from the request context
-func GetUser(r *http.Request) *User {
-	user, ok := r.Context().Value("user").(*User)
-	if !ok {
-		return nil
+// WithUser returns ctx carrying user for UserFromContext. The request logger
+// from logger.FromContext gains a user attribute.
+func WithUser(ctx context.Context, user *User) context.Context {
+	if user != nil {
+		ctx = logger.WithContext(ctx, logger.FromContext(ctx).With("user", user.Username))
 	}
-	return user
+	return context.WithValue(ctx, "user", user) //nolint:staticcheck
 }
 
 // IsAdmin checks if the user has admin privileges
@@ -243,6 +120,16 @@ func IsAdmin(user *User) bool {
 	if user == nil {
 		return false
 	}
+	// Impersonated sessions see what the target sees and never get admin powers.
+	if user.Impersonator != nil {
+		return false
+	}
+	if !user.HasScope(ScopeAdmin) {
+		return false
+	}
+	if user.commissioner {
+		return true
+	}
 
 	claim := strings.ToLower(strings.TrimSpace(os.Getenv("AUTH_ADMIN_CLAIM")))
 	if claim == "" {
@@ -301,176 +188,6 @@ func containsAdminValue(allowedValues []string, actualValue string) bool {
 	return false
 }
 
-// getUserInfo fetches user information from Authentik
-func (a *AuthentikAuth) getUserInfo(token *oauth2.Token) (*User, error) {
-	userInfoURL := fmt.Sprintf("%s/application/o/userinfo/", a.config.BaseURL)
-
-	req, err := http.NewRequest("GET", userInfoURL, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get user info: %s - %s", resp.Status, string(body))
-	}
-
-	var userInfo struct {
-		Sub               string   `json:"sub"`
-		Email             string   `json:"email"`
-		Name              string   `json:"name"`
-		PreferredUsername string   `json:"preferred_username"`
-		Groups            []string `json:"groups"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&userInfo); err != nil {
-		return nil, err
-	}
-
-	return &User{
-		ID:       userInfo.Sub,
-		Email:    userInfo.Email,
-		Name:     userInfo.Name,
-		Username: userInfo.PreferredUsername,
-		Groups:   userInfo.Groups,
-	}, nil
-}
-
-// generateState generates a random state string for CSRF protection
-func generateState() string {
-	b := make([]byte, 32)
-	rand.Read(b)
-	return base64.URLEncoding.EncodeToString(b)
-}
-
-// generateSessionID generates a random session ID
-func generateSessionID() string {
-	b := make([]byte, 32)
-	rand.Read(b)
-	return base64.URLEncoding.EncodeToString(b)
-}
-
-// MockAuth provides a mock authentication for local development
-type MockAuth struct {
-	sessions  map[string]*Session
-	sessionMu sync.RWMutex
-}
-
-// NewMockAuth creates a new mock authentication handler
-func NewMockAuth() *MockAuth {
-	return &MockAuth{
-		sessions: make(map[string]*Session),
-	}
-}
-
-// LoginHandler for mock auth - auto-creates a session
-func (m *MockAuth) LoginHandler(w http.ResponseWriter, r *http.Request) {
-	// Auto-authenticate as test user
-	sessionID := generateSessionID()
-	session := &Session{
-		ID: sessionID,
-		User: &User{
-			ID:       "dev-user-123",
-			Email:    "billy@jellycat.local",
-			Name:     "Billy",
-			Username: "Billy",
-			Groups:   []string{"users", "admins"},
-		},
-		CreatedAt: time.Now(),
-		ExpiresAt: time.Now().Add(24 * time.Hour),
-	}
-
-	m.sessionMu.Lock()
-	m.sessions[sessionID] = session
-	m.sessionMu.Unlock()
-
-	// Set session cookie
-	http.SetCookie(w, &http.Cookie{
-		Name:     "session_id",
-		Value:    sessionID,
-		Path:     "/",
-		HttpOnly: true,
-		Expires:  session.ExpiresAt,
-	})
-
-	http.Redirect(w, r, "/", http.StatusSeeOther)
-}
-
-// CallbackHandler is not needed for mock auth
-func (m *MockAuth) CallbackHandler(w http.ResponseWriter, r *http.Request) {
-	http.Redirect(w, r, "/", http.StatusSeeOther)
-}
-
-// LogoutHandler for mock auth
-func (m *MockAuth) LogoutHandler(w http.ResponseWriter, r *http.Request) {
-	cookie, err := r.Cookie("session_id")
-	if err == nil {
-		m.sessionMu.Lock()
-		delete(m.sessions, cookie.Value)
-		m.sessionMu.Unlock()
-	}
-
-	http.SetCookie(w, &http.Cookie{
-		Name:   "session_id",
-		Value:  "",
-		Path:   "/",
-		MaxAge: -1,
-	})
-
-	http.Redirect(w, r, "/start", http.StatusSeeOther)
-}
-
-// Middleware for mock auth protects routes and sends anonymous users through explicit login.
-func (m *MockAuth) Middleware(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		user := m.userFromRequest(r)
-		if user == nil {
-			http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
-			return
-		}
-
-		ctx := context.WithValue(r.Context(), "user", user)
-		next.ServeHTTP(w, r.WithContext(ctx))
-	}
-}
-
-// OptionalMiddleware attaches the mock user only when a dev session already exists.
-func (m *MockAuth) OptionalMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		if user := m.userFromRequest(r); user != nil {
-			ctx := context.WithValue(r.Context(), "user", user)
-			next.ServeHTTP(w, r.WithContext(ctx))
-			return
-		}
-		next.ServeHTTP(w, r)
-	}
-}
-
-func (m *MockAuth) userFromRequest(r *http.Request) *User {
-	cookie, err := r.Cookie("session_id")
-	if err != nil {
-		return nil
-	}
-
-	m.sessionMu.RLock()
-	session, exists := m.sessions[cookie.Value]
-	m.sessionMu.RUnlock()
-
-	if !exists || session == nil || time.Now().After(session.ExpiresAt) {
-		return nil
-	}
-	return session.User
-}
-
 // AuthProvider is a common interface for authentication providers
 type AuthProvider interface {
 	LoginHandler(w http.ResponseWriter, r *http.Request)
@@ -478,4 +195,12 @@ type AuthProvider interface {
 	LogoutHandler(w http.ResponseWriter, r *http.Request)
 	Middleware(next http.HandlerFunc) http.HandlerFunc
 	OptionalMiddleware(next http.HandlerFunc) http.HandlerFunc
+	// Impersonate makes target the effective user of the request's session.
+	Impersonate(w http.ResponseWriter, r *http.Request, target *User) error
+	// Unimpersonate returns the request's session to the real admin.
+	Unimpersonate(w http.ResponseWriter, r *http.Request) error
+	// SessionHandler reports when the caller's session expires.
+	SessionHandler(w http.ResponseWriter, r *http.Request)
+	// RefreshSessionHandler extends the caller's session.
+	RefreshSessionHandler(w http.ResponseWriter, r *http.Request)
 }
@@ -1,6 +1,14 @@
 package auth
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+)
 
 func TestIsAdminDefaultsToAdminsGroup(t *testing.T) {
 	t.Setenv("AUTH_ADMIN_CLAIM", "")
@@ -25,3 +33,18 @@ func TestIsAdminCanUseEmailClaim(t *testing.T) {
 		t.Fatal("expected other users to be denied")
 	}
 }
+
+func TestWithUserTagsTheRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := logger.WithContext(context.Background(), slog.New(slog.NewJSONHandler(&buf, nil)).With("request_id", "req-1"))
+
+	ctx = WithUser(ctx, &User{ID: "u1", Username: "billy"})
+	logger.FromContext(ctx).Info("picked")
+
+	if got := buf.String(); !strings.Contains(got, `"request_id":"req-1"`) || !strings.Contains(got, `"user":"billy"`) {
+		t.Fatalf("log line %q should carry both request_id and user", got)
+	}
+	if UserFromContext(ctx).Username != "billy" {
+		t.Fatal("WithUser should still store the user")
+	}
+}
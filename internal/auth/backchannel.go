@@ -0,0 +1,328 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"golang.org/x/oauth2"
+)
+
+// backchannelLogoutEvent is the events claim member that marks a logout token.
+const backchannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+// logoutTokenLeeway absorbs clock skew between us and the identity provider.
+const logoutTokenLeeway = time.Minute
+
+// jwksRefreshInterval limits how often an unknown key ID refetches the JWKS.
+const jwksRefreshInterval = time.Minute
+
+// BackchannelLogoutProvider is implemented by providers that accept OIDC
+// back-channel logout requests from their identity provider.
+type BackchannelLogoutProvider interface {
+	BackchannelLogoutHandler(w http.ResponseWriter, r *http.Request)
+}
+
+// errInvalidLogoutToken wraps every reason a logout token is rejected.
+var errInvalidLogoutToken = errors.New("invalid logout token")
+
+func invalidLogoutToken(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", errInvalidLogoutToken, fmt.Sprintf(format, args...))
+}
+
+// idpSessionFromToken reads sub and sid from the ID token returned with
+// token. The token came straight from the token endpoint over TLS, so its
+// signature need not be checked (OIDC Core 3.1.3.7).
+func idpSessionFromToken(token *oauth2.Token) dal.IdPSession {
+	if token == nil {
+		return dal.IdPSession{}
+	}
+	raw, _ := token.Extra("id_token").(string)
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return dal.IdPSession{}
+	}
+	var claims struct {
+		Subject   string `json:"sub"`
+		SessionID string `json:"sid"`
+	}
+	if payload, err := base64.RawURLEncoding.DecodeString(parts[1]); err == nil {
+		json.Unmarshal(payload, &claims)
+	}
+	return dal.IdPSession{Subject: claims.Subject, SessionID: claims.SessionID}
+}
+
+// BackchannelLogoutHandler implements OIDC Back-Channel Logout
+// (POST /auth/backchannel-logout): the identity provider posts a signed
+// logout_token and every session it names is deleted.
+func (o *OIDCAuth) BackchannelLogoutHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	idp, err := o.verifyLogoutToken(r.Context(), r.PostFormValue("logout_token"), time.Now())
+	if err != nil {
+		logger.Warn("Rejected back-channel logout", "error", err)
+		writeBackchannelError(w, err.Error())
+		return
+	}
+
+	deleted, err := o.store.DeleteIdP(r.Context(), idp)
+	if err != nil {
+		logger.Error("Failed to delete sessions for back-channel logout", "error", err)
+		writeBackchannelError(w, "logout failed")
+		return
+	}
+
+	o.audit.Record(r, EventBackchannelLogout, &User{ID: idp.Subject}, "", fmt.Sprintf("sid=%s sessions=%d", idp.SessionID, deleted))
+	w.WriteHeader(http.StatusOK)
+}
+
+// writeBackchannelError sends the 400 the spec requires for any failed logout.
+func writeBackchannelError(w http.ResponseWriter, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]string{"error": "invalid_request", "error_description": description})
+}
+
+// logoutTokenClaims are the claims checked on a logout token.
+type logoutTokenClaims struct {
+	Issuer    string                     `json:"iss"`
+	Subject   string                     `json:"sub"`
+	Audience  audienceClaim              `json:"aud"`
+	IssuedAt  int64                      `json:"iat"`
+	Expiry    int64                      `json:"exp"`
+	SessionID string                     `json:"sid"`
+	Events    map[string]json.RawMessage `json:"events"`
+	Nonce     json.RawMessage            `json:"nonce"`
+}
+
+// audienceClaim accepts aud as a single string or an array.
+type audienceClaim []string
+
+func (a *audienceClaim) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audienceClaim{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(a))
+}
+
+// verifyLogoutToken validates raw per OIDC Back-Channel Logout 1.0 section
+// 2.6 and returns the sessions it logs out.
+func (o *OIDCAuth) verifyLogoutToken(ctx context.Context, raw string, now time.Time) (dal.IdPSession, error) {
+	if raw == "" {
+		return dal.IdPSession{}, invalidLogoutToken("logout_token is required")
+	}
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return dal.IdPSession{}, invalidLogoutToken("not a signed JWT")
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return dal.IdPSession{}, invalidLogoutToken("bad header: %v", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return dal.IdPSession{}, invalidLogoutToken("bad signature encoding")
+	}
+	key, err := o.jwks.key(ctx, o.httpClient, o.endpoints.JWKSURI, header.KeyID)
+	if err != nil {
+		return dal.IdPSession{}, err
+	}
+	if err := verifyJWTSignature(header.Algorithm, key, parts[0]+"."+parts[1], signature); err != nil {
+		return dal.IdPSession{}, err
+	}
+
+	var claims logoutTokenClaims
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return dal.IdPSession{}, invalidLogoutToken("bad claims: %v", err)
+	}
+
+	issuer := o.endpoints.Issuer
+	if issuer == "" {
+		issuer = o.config.IssuerURL
+	}
+	if claims.Issuer != issuer {
+		return dal.IdPSession{}, invalidLogoutToken("unexpected issuer %q", claims.Issuer)
+	}
+	audienceOK := false
+	for _, audience := range claims.Audience {
+		audienceOK = audienceOK || audience == o.config.ClientID
+	}
+	if !audienceOK {
+		return dal.IdPSession{}, invalidLogoutToken("token is not for this client")
+	}
+	if claims.IssuedAt == 0 || time.Unix(claims.IssuedAt, 0).After(now.Add(logoutTokenLeeway)) {
+		return dal.IdPSession{}, invalidLogoutToken("missing or future iat")
+	}
+	if claims.Expiry != 0 && now.After(time.Unix(claims.Expiry, 0).Add(logoutTokenLeeway)) {
+		return dal.IdPSession{}, invalidLogoutToken("token has expired")
+	}
+	if event, ok := claims.Events[backchannelLogoutEvent]; !ok || !strings.HasPrefix(strings.TrimSpace(string(event)), "{") {
+		return dal.IdPSession{}, invalidLogoutToken("missing back-channel logout event")
+	}
+	if claims.Nonce != nil {
+		return dal.IdPSession{}, invalidLogoutToken("logout tokens must not carry a nonce")
+	}
+	if claims.Subject == "" && claims.SessionID == "" {
+		return dal.IdPSession{}, invalidLogoutToken("token names neither sub nor sid")
+	}
+
+	return dal.IdPSession{Subject: claims.Subject, SessionID: claims.SessionID}, nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// verifyJWTSignature checks an RS256/384/512 or ES256 signature over signed.
+func verifyJWTSignature(algorithm string, key crypto.PublicKey, signed string, signature []byte) error {
+	hashes := map[string]crypto.Hash{"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512, "ES256": crypto.SHA256}
+	hash, ok := hashes[algorithm]
+	if !ok {
+		return invalidLogoutToken("unsupported alg %q", algorithm)
+	}
+	hasher := hash.New()
+	hasher.Write([]byte(signed))
+	digest := hasher.Sum(nil)
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if strings.HasPrefix(algorithm, "RS") && rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil {
+			return nil
+		}
+	case *ecdsa.PublicKey:
+		if algorithm == "ES256" && len(signature) == 64 {
+			r := new(big.Int).SetBytes(signature[:32])
+			s := new(big.Int).SetBytes(signature[32:])
+			if ecdsa.Verify(key, digest, r, s) {
+				return nil
+			}
+		}
+	}
+	return invalidLogoutToken("signature does not verify")
+}
+
+// jwksCache holds the identity provider's signing keys by key ID.
+type jwksCache struct {
+	mu      sync.Mutex
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+}
+
+// key returns the key for kid, refetching the JWKS when kid is unknown. A
+// token without a kid is accepted only when the JWKS has a single key.
+func (c *jwksCache) key(ctx context.Context, client *http.Client, jwksURI, kid string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lookup := func() crypto.PublicKey {
+		if kid == "" && len(c.keys) == 1 {
+			for _, key := range c.keys {
+				return key
+			}
+		}
+		return c.keys[kid]
+	}
+	if key := lookup(); key != nil {
+		return key, nil
+	}
+	if time.Since(c.fetched) < jwksRefreshInterval {
+		return nil, invalidLogoutToken("unknown signing key %q", kid)
+	}
+
+	keys, err := fetchJWKS(ctx, client, jwksURI)
+	if err != nil {
+		return nil, err
+	}
+	c.keys, c.fetched = keys, time.Now()
+	if key := lookup(); key != nil {
+		return key, nil
+	}
+	return nil, invalidLogoutToken("unknown signing key %q", kid)
+}
+
+func fetchJWKS(ctx context.Context, client *http.Client, jwksURI string) (map[string]crypto.PublicKey, error) {
+	if jwksURI == "" {
+		return nil, fmt.Errorf("provider does not publish a jwks_uri")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []struct {
+			KeyType string `json:"kty"`
+			KeyID   string `json:"kid"`
+			Use     string `json:"use"`
+			N       string `json:"n"`
+			E       string `json:"e"`
+			Curve   string `json:"crv"`
+			X       string `json:"x"`
+			Y       string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		switch {
+		case jwk.KeyType == "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+			e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+			if errN != nil || errE != nil {
+				continue
+			}
+			keys[jwk.KeyID] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case jwk.KeyType == "EC" && jwk.Curve == "P-256":
+			x, errX := base64.RawURLEncoding.DecodeString(jwk.X)
+			y, errY := base64.RawURLEncoding.DecodeString(jwk.Y)
+			if errX != nil || errY != nil {
+				continue
+			}
+			keys[jwk.KeyID] = &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"golang.org/x/oauth2"
+)
+
+// backchannelFixture is an OIDC provider that signs logout tokens with key.
+type backchannelFixture struct {
+	provider *OIDCAuth
+	key      *rsa.PrivateKey
+	issuer   string
+}
+
+func newBackchannelFixture(t *testing.T) *backchannelFixture {
+	t.Helper()
+	logger.Init()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() failed: %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key-1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	t.Cleanup(server.Close)
+
+	provider := newOIDCAuthWithEndpoints(&OIDCConfig{IssuerURL: server.URL, ClientID: "jellycat"}, OIDCEndpoints{Issuer: server.URL, JWKSURI: server.URL + "/jwks"})
+	return &backchannelFixture{provider: provider, key: key, issuer: server.URL}
+}
+
+// token signs claims, filling in a valid logout token's required claims.
+func (f *backchannelFixture) token(t *testing.T, overrides map[string]interface{}) string {
+	t.Helper()
+
+	claims := map[string]interface{}{
+		"iss":    f.issuer,
+		"aud":    "jellycat",
+		"iat":    time.Now().Unix(),
+		"exp":    time.Now().Add(2 * time.Minute).Unix(),
+		"jti":    "logout-1",
+		"sub":    "sub-1",
+		"sid":    "sid-laptop",
+		"events": map[string]interface{}{backchannelLogoutEvent: map[string]interface{}{}},
+	}
+	for name, value := range overrides {
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "key-1", "typ": "logout+jwt"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, f.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("SignPKCS1v15() failed: %v", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func (f *backchannelFixture) post(token string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/auth/backchannel-logout", strings.NewReader(url.Values{"logout_token": {token}}.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	f.provider.BackchannelLogoutHandler(recorder, request)
+	return recorder
+}
+
+func (f *backchannelFixture) saveSession(t *testing.T, id string, idp dal.IdPSession) {
+	t.Helper()
+	session := &Session{ID: id, User: &User{ID: idp.Subject}, ExpiresAt: time.Now().Add(time.Hour), IdP: idp}
+	if err := f.provider.store.Save(context.Background(), session); err != nil {
+		t.Fatalf("Save(%s) failed: %v", id, err)
+	}
+}
+
+func (f *backchannelFixture) exists(id string) bool {
+	_, err := f.provider.store.Get(context.Background(), id)
+	return !errors.Is(err, ErrSessionNotFound)
+}
+
+func TestBackchannelLogoutDeletesMatchingSession(t *testing.T) {
+	f := newBackchannelFixture(t)
+	f.saveSession(t, "laptop", dal.IdPSession{Subject: "sub-1", SessionID: "sid-laptop"})
+	f.saveSession(t, "phone", dal.IdPSession{Subject: "sub-1", SessionID: "sid-phone"})
+
+	recorder := f.post(f.token(t, nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body)
+	}
+	if recorder.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("Cache-Control = %q, want no-store", recorder.Header().Get("Cache-Control"))
+	}
+	if f.exists("laptop") || !f.exists("phone") {
+		t.Fatalf("laptop exists = %v, phone exists = %v; want only the sid's session gone", f.exists("laptop"), f.exists("phone"))
+	}
+
+	// Without a sid every session of the subject goes.
+	if code := f.post(f.token(t, map[string]interface{}{"sid": nil})).Code; code != http.StatusOK {
+		t.Fatalf("sub-only status = %d, want %d", code, http.StatusOK)
+	}
+	if f.exists("phone") {
+		t.Fatal("sub-only logout left the phone session")
+	}
+}
+
+func TestBackchannelLogoutRejectsInvalidTokens(t *testing.T) {
+	f := newBackchannelFixture(t)
+	f.saveSession(t, "laptop", dal.IdPSession{Subject: "sub-1", SessionID: "sid-laptop"})
+
+	tampered := f.token(t, nil)
+	tampered = tampered[:len(tampered)-4] + "AAAA"
+
+	for name, token := range map[string]string{
+		"expired":        f.token(t, map[string]interface{}{"iat": time.Now().Add(-time.Hour).Unix(), "exp": time.Now().Add(-30 * time.Minute).Unix()}),
+		"wrong audience": f.token(t, map[string]interface{}{"aud": []string{"someone-else"}}),
+		"wrong issuer":   f.token(t, map[string]interface{}{"iss": "https://evil.example"}),
+		"missing event":  f.token(t, map[string]interface{}{"events": map[string]interface{}{}}),
+		"nonce":          f.token(t, map[string]interface{}{"nonce": "n-1"}),
+		"no sub or sid":  f.token(t, map[string]interface{}{"sub": nil, "sid": nil}),
+		"bad signature":  tampered,
+		"missing":        "",
+	} {
+		recorder := f.post(token)
+		if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "invalid_request") {
+			t.Fatalf("%s: status = %d, body = %s; want 400 invalid_request", name, recorder.Code, recorder.Body)
+		}
+	}
+	if !f.exists("laptop") {
+		t.Fatal("a rejected logout token deleted the session")
+	}
+}
+
+func TestCreateSessionRecordsIdPSession(t *testing.T) {
+	manager := newSessionManager(nil, nil, false)
+	idToken := "e30." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"sub-1","sid":"sid-laptop"}`)) + ".sig"
+	token := (&oauth2.Token{AccessToken: "access"}).WithExtra(map[string]interface{}{"id_token": idToken})
+
+	session := manager.createSession(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/auth/callback", nil), &User{ID: "sub-1"}, token)
+	if session == nil || session.IdP != (dal.IdPSession{Subject: "sub-1", SessionID: "sid-laptop"}) {
+		t.Fatalf("session = %+v, want the ID token's sub and sid recorded", session)
+	}
+}
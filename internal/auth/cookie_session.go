@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+)
+
+// maxSessionCookieSize keeps sealed sessions under the 4096-byte limit
+// browsers put on a cookie, leaving room for its name and attributes.
+const maxSessionCookieSize = 3800
+
+var (
+	// ErrInvalidSession is returned by the cookie session store for a cookie
+	// that fails authentication, i.e. was tampered with or sealed under an
+	// unknown SESSION_SECRET.
+	ErrInvalidSession = errors.New("invalid session cookie")
+	// ErrSessionTooLarge is returned when a sealed session would not fit in a cookie.
+	ErrSessionTooLarge = errors.New("session too large for a cookie")
+)
+
+// cookieSessionStore keeps the whole session in the session cookie, encrypted
+// and authenticated with AES-GCM, so the server stores nothing. Save replaces
+// session.ID with the sealed value that becomes the cookie.
+//
+// Only what the middlewares need survives the round trip: the user's identity,
+// whether they are a commissioner (instead of their groups, which can be
+// long), any impersonator and the expiry. OAuth tokens are dropped, so cookie
+// sessions extend like local ones. Delete is a no-op; logout works by expiring
+// the cookie, and back-channel logout cannot revoke cookie sessions.
+type cookieSessionStore struct {
+	// keys[0] seals; every key opens, so old secrets keep working during rotation.
+	keys []cipher.AEAD
+}
+
+// NewCookieSessionStore seals sessions under the first secret and accepts
+// cookies sealed under any of them.
+func NewCookieSessionStore(secrets ...string) (SessionStore, error) {
+	store := &cookieSessionStore{}
+	for _, secret := range secrets {
+		secret = strings.TrimSpace(secret)
+		if secret == "" {
+			continue
+		}
+		key := sha256.Sum256([]byte(secret))
+		block, err := aes.NewCipher(key[:])
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		store.keys = append(store.keys, aead)
+	}
+	if len(store.keys) == 0 {
+		return nil, fmt.Errorf("SESSION_SECRET is required when SESSION_STORE=cookie")
+	}
+	return store, nil
+}
+
+// sealedSession is the cookie payload; short keys keep it small.
+type sealedSession struct {
+	User      *sealedUser `json:"u"`
+	CreatedAt int64       `json:"c"`
+	ExpiresAt int64       `json:"e"`
+}
+
+type sealedUser struct {
+	ID           string      `json:"i"`
+	Email        string      `json:"m,omitempty"`
+	Name         string      `json:"n,omitempty"`
+	Username     string      `json:"un,omitempty"`
+	Commissioner bool        `json:"a,omitempty"`
+	Impersonator *sealedUser `json:"im,omitempty"`
+}
+
+func sealUser(user *User) *sealedUser {
+	if user == nil {
+		return nil
+	}
+	return &sealedUser{
+		ID:           user.ID,
+		Email:        user.Email,
+		Name:         user.Name,
+		Username:     user.Username,
+		Commissioner: IsAdmin(user),
+		Impersonator: sealUser(user.Impersonator),
+	}
+}
+
+func (u *sealedUser) open() *User {
+	if u == nil {
+		return nil
+	}
+	return &User{
+		ID:           u.ID,
+		Email:        u.Email,
+		Name:         u.Name,
+		Username:     u.Username,
+		Impersonator: u.Impersonator.open(),
+		commissioner: u.Commissioner,
+	}
+}
+
+func (c *cookieSessionStore) Save(ctx context.Context, session *Session) error {
+	payload, err := json.Marshal(sealedSession{
+		User:      sealUser(session.User),
+		CreatedAt: session.CreatedAt.Unix(),
+		ExpiresAt: session.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	aead := c.keys[0]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(payload)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	sealed := base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, payload, nil))
+	if len(sealed) > maxSessionCookieSize {
+		return fmt.Errorf("%w: %d bytes", ErrSessionTooLarge, len(sealed))
+	}
+
+	session.ID = sealed
+	return nil
+}
+
+func (c *cookieSessionStore) Get(ctx context.Context, id string) (*Session, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil {
+		return nil, ErrInvalidSession
+	}
+
+	var payload []byte
+	for _, aead := range c.keys {
+		if len(sealed) < aead.NonceSize() {
+			break
+		}
+		nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+		if payload, err = aead.Open(nil, nonce, ciphertext, nil); err == nil {
+			break
+		}
+	}
+	if payload == nil {
+		return nil, ErrInvalidSession
+	}
+
+	var decoded sealedSession
+	if err := json.Unmarshal(payload, &decoded); err != nil || decoded.User == nil {
+		return nil, ErrInvalidSession
+	}
+	session := &Session{
+		ID:        id,
+		User:      decoded.User.open(),
+		CreatedAt: time.Unix(decoded.CreatedAt, 0),
+		ExpiresAt: time.Unix(decoded.ExpiresAt, 0),
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func (c *cookieSessionStore) Delete(ctx context.Context, id string) error {
+	return nil
+}
+
+func (c *cookieSessionStore) DeleteIdP(ctx context.Context, idp dal.IdPSession) (int, error) {
+	return 0, nil
+}
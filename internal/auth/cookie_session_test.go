@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+)
+
+func newCookieMockAuth(t *testing.T, secrets ...string) *MockAuth {
+	t.Helper()
+	store, err := NewSessionStore("cookie", dal.NewMemoryDAL(), "", secrets...)
+	if err != nil {
+		t.Fatalf("NewSessionStore(cookie) failed: %v", err)
+	}
+	return &MockAuth{sessionManager: newSessionManager(store, nil, false)}
+}
+
+func TestCookieSessionRoundTrip(t *testing.T) {
+	provider := newCookieMockAuth(t, "secret-1")
+	login := httptest.NewRecorder()
+	provider.LoginHandler(login, httptest.NewRequest(http.MethodGet, "/auth/login", nil))
+	cookies := login.Result().Cookies()
+	if len(cookies) != 1 || len(cookies[0].Value) > maxSessionCookieSize || strings.Contains(cookies[0].Value, "Billy") {
+		t.Fatalf("cookies = %+v, want one sealed session cookie", cookies)
+	}
+
+	var seen *User
+	provider.Middleware(func(w http.ResponseWriter, r *http.Request) {
+		seen = GetUser(r)
+	})(httptest.NewRecorder(), sessionRequest(http.MethodGet, "/draft", cookies))
+	if seen == nil || seen.ID != defaultMockUser.ID || seen.Name != "Billy" || len(seen.Groups) != 0 || !IsAdmin(seen) {
+		t.Fatalf("user = %+v, want the mock admin restored without its groups", seen)
+	}
+
+	logout := httptest.NewRecorder()
+	provider.LogoutHandler(logout, sessionRequest(http.MethodGet, "/auth/logout", cookies))
+	if cleared := logout.Result().Cookies(); len(cleared) != 1 || cleared[0].MaxAge >= 0 {
+		t.Fatalf("logout cookies = %+v, want the session cookie expired", cleared)
+	}
+}
+
+func TestCookieSessionRejectsTamperedCookies(t *testing.T) {
+	provider := newCookieMockAuth(t, "secret-1")
+	login := httptest.NewRecorder()
+	provider.LoginHandler(login, httptest.NewRequest(http.MethodGet, "/auth/login", nil))
+	sealed := login.Result().Cookies()[0].Value
+
+	flipped := []byte(sealed)
+	flipped[len(flipped)/2] ^= 'A' ^ 'B'
+	for name, value := range map[string]string{
+		"flipped byte": string(flipped),
+		"truncated":    sealed[:10],
+		"not base64":   "!!!",
+	} {
+		reached := false
+		recorder := httptest.NewRecorder()
+		provider.OptionalMiddleware(func(w http.ResponseWriter, r *http.Request) {
+			reached = true
+		})(recorder, sessionRequest(http.MethodGet, "/api/draft/state", []*http.Cookie{{Name: "session_id", Value: value}}))
+		if recorder.Code != http.StatusUnauthorized || reached {
+			t.Fatalf("%s: status = %d, reached = %v; want 401", name, recorder.Code, reached)
+		}
+	}
+}
+
+func TestCookieSessionKeyRotation(t *testing.T) {
+	old, _ := NewCookieSessionStore("old-secret")
+	session := &Session{User: &User{ID: "user-1"}, ExpiresAt: time.Now().Add(time.Hour)}
+	if err := old.Save(context.Background(), session); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	rotated, _ := NewCookieSessionStore("new-secret", "old-secret")
+	if got, err := rotated.Get(context.Background(), session.ID); err != nil || got.User.ID != "user-1" {
+		t.Fatalf("rotated Get() = %+v, %v; want the old key's session", got, err)
+	}
+	retired, _ := NewCookieSessionStore("new-secret")
+	if _, err := retired.Get(context.Background(), session.ID); !errors.Is(err, ErrInvalidSession) {
+		t.Fatalf("retired Get() error = %v, want %v", err, ErrInvalidSession)
+	}
+}
+
+func TestCookieSessionExpiryAndSize(t *testing.T) {
+	store, _ := NewCookieSessionStore("secret-1")
+
+	expired := &Session{User: &User{ID: "user-1"}, ExpiresAt: time.Now().Add(-time.Minute)}
+	if err := store.Save(context.Background(), expired); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	if _, err := store.Get(context.Background(), expired.ID); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expired Get() error = %v, want %v", err, ErrSessionNotFound)
+	}
+
+	// Groups never reach the cookie, however many there are.
+	groups := make([]string, 500)
+	for i := range groups {
+		groups[i] = "some-rather-long-group-name"
+	}
+	if err := store.Save(context.Background(), &Session{User: &User{ID: "user-1", Groups: groups}, ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("Save() with many groups failed: %v", err)
+	}
+	huge := &Session{User: &User{ID: "user-1", Name: strings.Repeat("x", maxSessionCookieSize)}, ExpiresAt: time.Now().Add(time.Hour)}
+	if err := store.Save(context.Background(), huge); !errors.Is(err, ErrSessionTooLarge) {
+		t.Fatalf("oversized Save() error = %v, want %v", err, ErrSessionTooLarge)
+	}
+
+	if _, err := NewSessionStore("cookie", dal.NewMemoryDAL(), ""); err == nil {
+		t.Fatal("NewSessionStore(cookie) without SESSION_SECRET succeeded")
+	}
+}
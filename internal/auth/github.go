@@ -0,0 +1,233 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// GitHubConfig holds the configuration for GitHub OAuth login
+type GitHubConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+
+	// AdminOrg grants admin to active members of this GitHub organization.
+	AdminOrg string
+	// AdminTeam narrows AdminOrg to a team slug within the organization.
+	AdminTeam string
+
+	// BaseURL and APIURL default to github.com; override for GitHub Enterprise or tests.
+	BaseURL string
+	APIURL  string
+
+	HTTPClient *http.Client
+	// Sessions stores login sessions (default in-memory).
+	Sessions SessionStore
+	// Audit records sign-ins, sign-outs and expired sessions (optional).
+	Audit *AuditLog
+}
+
+// GitHubAuth manages "Sign in with GitHub" using a GitHub OAuth app
+type GitHubAuth struct {
+	*sessionManager
+	config       *GitHubConfig
+	oauth2Config *oauth2.Config
+	httpClient   *http.Client
+}
+
+type githubUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+type githubMembership struct {
+	State string `json:"state"`
+}
+
+// NewGitHubAuth creates a new GitHub authentication handler
+func NewGitHubAuth(config *GitHubConfig) *GitHubAuth {
+	if config.BaseURL == "" {
+		config.BaseURL = "https://github.com"
+	}
+	if config.APIURL == "" {
+		config.APIURL = "https://api.github.com"
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+	config.APIURL = strings.TrimRight(config.APIURL, "/")
+
+	scopes := []string{"read:user", "user:email"}
+	if config.AdminOrg != "" {
+		scopes = append(scopes, "read:org")
+	}
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	oauth2Config := &oauth2.Config{
+		ClientID:     config.ClientID,
+		ClientSecret: config.ClientSecret,
+		RedirectURL:  config.RedirectURL,
+		Scopes:       scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  config.BaseURL + "/login/oauth/authorize",
+			TokenURL: config.BaseURL + "/login/oauth/access_token",
+		},
+	}
+	sessions := newSessionManager(config.Sessions, config.Audit, true)
+	// GitHub App tokens expire and carry a refresh token; OAuth app tokens do neither.
+	sessions.refresh = oauthRefresher(oauth2Config, httpClient)
+
+	return &GitHubAuth{
+		sessionManager: sessions,
+		config:         config,
+		oauth2Config:   oauth2Config,
+		httpClient:     httpClient,
+	}
+}
+
+// LoginHandler initiates the OAuth2 login flow
+func (g *GitHubAuth) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	state := setStateCookie(w, r)
+	http.Redirect(w, r, g.oauth2Config.AuthCodeURL(state), http.StatusTemporaryRedirect)
+}
+
+// CallbackHandler handles the OAuth2 callback from GitHub
+func (g *GitHubAuth) CallbackHandler(w http.ResponseWriter, r *http.Request) {
+	next, ok := verifyStateCookie(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), oauth2.HTTPClient, g.httpClient)
+	token, err := g.oauth2Config.Exchange(ctx, r.URL.Query().Get("code"))
+	if err != nil {
+		http.Error(w, "Failed to exchange token: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	user, err := g.getUserInfo(r.Context(), token)
+	if err != nil {
+		http.Error(w, "Failed to get user info: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if g.createSession(w, r, user, token) == nil {
+		return
+	}
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+// LogoutHandler handles user logout. GitHub has no end-session endpoint, so only the local session is cleared.
+func (g *GitHubAuth) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	g.destroySession(w, r)
+	http.Redirect(w, r, "/start", http.StatusSeeOther)
+}
+
+// getUserInfo loads the GitHub profile and maps admin membership onto the "admins" group
+func (g *GitHubAuth) getUserInfo(ctx context.Context, token *oauth2.Token) (*User, error) {
+	var profile githubUser
+	if _, err := g.getJSON(ctx, token, "/user", &profile); err != nil {
+		return nil, err
+	}
+
+	if profile.Email == "" {
+		var emails []githubEmail
+		if _, err := g.getJSON(ctx, token, "/user/emails", &emails); err == nil {
+			for _, email := range emails {
+				if email.Primary && email.Verified {
+					profile.Email = email.Email
+					break
+				}
+			}
+		}
+	}
+
+	name := profile.Name
+	if name == "" {
+		name = profile.Login
+	}
+
+	user := &User{
+		ID:       strconv.FormatInt(profile.ID, 10),
+		Email:    profile.Email,
+		Name:     name,
+		Username: profile.Login,
+		Groups:   []string{"users"},
+	}
+
+	isAdmin, err := g.isAdminMember(ctx, token, profile.Login)
+	if err != nil {
+		return nil, err
+	}
+	if isAdmin {
+		user.Groups = append(user.Groups, "admins")
+	}
+
+	return user, nil
+}
+
+// isAdminMember reports whether login is an active member of the configured org or team
+func (g *GitHubAuth) isAdminMember(ctx context.Context, token *oauth2.Token, login string) (bool, error) {
+	if g.config.AdminOrg == "" {
+		return false, nil
+	}
+
+	path := "/user/memberships/orgs/" + url.PathEscape(g.config.AdminOrg)
+	if g.config.AdminTeam != "" {
+		path = fmt.Sprintf("/orgs/%s/teams/%s/memberships/%s",
+			url.PathEscape(g.config.AdminOrg), url.PathEscape(g.config.AdminTeam), url.PathEscape(login))
+	}
+
+	var membership githubMembership
+	status, err := g.getJSON(ctx, token, path, &membership)
+	if status == http.StatusNotFound || status == http.StatusForbidden {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return membership.State == "active", nil
+}
+
+// getJSON performs an authenticated GET against the GitHub API and decodes the response
+func (g *GitHubAuth) getJSON(ctx context.Context, token *oauth2.Token, path string, target interface{}) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.config.APIURL+path, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp.StatusCode, fmt.Errorf("GitHub API %s: %s - %s", path, resp.Status, string(body))
+	}
+
+	return resp.StatusCode, json.NewDecoder(resp.Body).Decode(target)
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func newFakeGitHubServer(t *testing.T, email string, orgState string) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "gh-token", "token_type": "bearer"})
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer gh-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 42, "login": "octocat", "name": "", "email": email})
+	})
+	mux.HandleFunc("/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"email": "secondary@example.com", "primary": false, "verified": true},
+			{"email": "octocat@example.com", "primary": true, "verified": true},
+		})
+	})
+	mux.HandleFunc("/user/memberships/orgs/jellycats", func(w http.ResponseWriter, r *http.Request) {
+		if orgState == "" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"state": orgState})
+	})
+	mux.HandleFunc("/orgs/jellycats/teams/commissioners/memberships/octocat", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"state": "active"})
+	})
+
+	return server
+}
+
+func githubLogin(t *testing.T, provider *GitHubAuth) *User {
+	t.Helper()
+
+	state, stateCookie := beginLogin(t, "/", nil)
+	request := httptest.NewRequest(http.MethodGet, "/auth/callback?code=abc&state="+url.QueryEscape(state), nil)
+	request.AddCookie(stateCookie)
+	recorder := httptest.NewRecorder()
+
+	provider.CallbackHandler(recorder, request)
+
+	if recorder.Code != http.StatusSeeOther {
+		t.Fatalf("callback status = %d, want %d: %s", recorder.Code, http.StatusSeeOther, recorder.Body.String())
+	}
+
+	follow := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.Name == "session_id" {
+			follow.AddCookie(cookie)
+		}
+	}
+
+	user, _ := provider.userFromRequest(follow)
+	if user == nil {
+		t.Fatal("expected a session after the GitHub callback")
+	}
+	return user
+}
+
+func TestGitHubAuthMapsProfileAndPrimaryEmail(t *testing.T) {
+	t.Setenv("AUTH_ADMIN_CLAIM", "")
+	t.Setenv("AUTH_ADMIN_VALUE", "")
+
+	server := newFakeGitHubServer(t, "", "")
+	provider := NewGitHubAuth(&GitHubConfig{ClientID: "id", ClientSecret: "secret", BaseURL: server.URL, APIURL: server.URL})
+
+	user := githubLogin(t, provider)
+
+	if user.ID != "42" || user.Username != "octocat" || user.Name != "octocat" {
+		t.Fatalf("user = %+v, want mapped GitHub profile", user)
+	}
+	if user.Email != "octocat@example.com" {
+		t.Fatalf("email = %q, want primary verified email", user.Email)
+	}
+	if IsAdmin(user) {
+		t.Fatal("expected user without an admin org to be denied")
+	}
+}
+
+func TestGitHubAuthGrantsAdminForActiveOrgMember(t *testing.T) {
+	t.Setenv("AUTH_ADMIN_CLAIM", "")
+	t.Setenv("AUTH_ADMIN_VALUE", "")
+
+	server := newFakeGitHubServer(t, "octocat@example.com", "active")
+	provider := NewGitHubAuth(&GitHubConfig{AdminOrg: "jellycats", BaseURL: server.URL, APIURL: server.URL})
+
+	if !IsAdmin(githubLogin(t, provider)) {
+		t.Fatal("expected active org member to be an admin")
+	}
+}
+
+func TestGitHubAuthDeniesPendingOrgMember(t *testing.T) {
+	t.Setenv("AUTH_ADMIN_CLAIM", "")
+	t.Setenv("AUTH_ADMIN_VALUE", "")
+
+	server := newFakeGitHubServer(t, "octocat@example.com", "pending")
+	provider := NewGitHubAuth(&GitHubConfig{AdminOrg: "jellycats", BaseURL: server.URL, APIURL: server.URL})
+
+	if IsAdmin(githubLogin(t, provider)) {
+		t.Fatal("expected pending org member to be denied")
+	}
+}
+
+func TestGitHubAuthChecksTeamMembership(t *testing.T) {
+	t.Setenv("AUTH_ADMIN_CLAIM", "")
+	t.Setenv("AUTH_ADMIN_VALUE", "")
+
+	server := newFakeGitHubServer(t, "octocat@example.com", "")
+	provider := NewGitHubAuth(&GitHubConfig{AdminOrg: "jellycats", AdminTeam: "commissioners", BaseURL: server.URL, APIURL: server.URL})
+
+	if !IsAdmin(githubLogin(t, provider)) {
+		t.Fatal("expected active team member to be an admin")
+	}
+}
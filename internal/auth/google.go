@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// GoogleConfig holds the configuration for Google sign-in
+type GoogleConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+
+	// AllowedDomain restricts logins to a Google Workspace domain via the hd claim.
+	AllowedDomain string
+	// AdminEmails are granted the "admins" group (matched case-insensitively).
+	AdminEmails []string
+
+	// IssuerURL defaults to https://accounts.google.com; override for tests.
+	IssuerURL  string
+	HTTPClient *http.Client
+	// Sessions stores login sessions (default in-memory).
+	Sessions SessionStore
+	// Audit records sign-ins, sign-outs and expired sessions (optional).
+	Audit *AuditLog
+}
+
+// GoogleAuth is an OIDCAuth preset for Google accounts
+type GoogleAuth struct {
+	*OIDCAuth
+	config *GoogleConfig
+}
+
+// NewGoogleAuth discovers Google's endpoints and creates a new Google authentication handler
+func NewGoogleAuth(config *GoogleConfig) (*GoogleAuth, error) {
+	if config.IssuerURL == "" {
+		config.IssuerURL = "https://accounts.google.com"
+	}
+
+	oidc, err := NewOIDCAuth(&OIDCConfig{
+		IssuerURL:     config.IssuerURL,
+		ClientID:      config.ClientID,
+		ClientSecret:  config.ClientSecret,
+		RedirectURL:   config.RedirectURL,
+		Scopes:        []string{"openid", "profile", "email"},
+		UsernameClaim: "email",
+		HTTPClient:    config.HTTPClient,
+		Sessions:      config.Sessions,
+		Audit:         config.Audit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	google := &GoogleAuth{OIDCAuth: oidc, config: config}
+	if config.AllowedDomain != "" {
+		// hd is only a hint for the account chooser; the claim is enforced in authorize.
+		oidc.authCodeOptions = append(oidc.authCodeOptions, oauth2.SetAuthURLParam("hd", config.AllowedDomain))
+	}
+	oidc.authorizeClaims = google.authorize
+
+	return google, nil
+}
+
+// authorize enforces the hosted domain and maps admin emails onto the "admins" group
+func (g *GoogleAuth) authorize(claims map[string]interface{}, user *User) error {
+	if g.config.AllowedDomain != "" {
+		hostedDomain := claimString(claims, "hd")
+		if !strings.EqualFold(hostedDomain, g.config.AllowedDomain) {
+			return fmt.Errorf("%w: account is not in the %s domain", errAccessDenied, g.config.AllowedDomain)
+		}
+	}
+
+	user.Groups = append(user.Groups, "users")
+	if claimString(claims, "email_verified") == "true" && containsAdminValue(g.config.AdminEmails, user.Email) {
+		user.Groups = append(user.Groups, "admins")
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func googleClaims(email, hostedDomain string) map[string]interface{} {
+	return map[string]interface{}{
+		"sub":            "google-1",
+		"email":          email,
+		"email_verified": true,
+		"name":           "Taylor",
+		"hd":             hostedDomain,
+	}
+}
+
+func TestGoogleAuthRejectsOtherHostedDomains(t *testing.T) {
+	server := newFakeOIDCServer(t, googleClaims("taylor@gmail.com", ""))
+	provider, err := NewGoogleAuth(&GoogleConfig{IssuerURL: server.URL, AllowedDomain: "jellycat.example"})
+	if err != nil {
+		t.Fatalf("NewGoogleAuth() failed: %v", err)
+	}
+
+	_, err = provider.getUserInfo(context.Background(), &oauth2.Token{AccessToken: "test-token"})
+	if !errors.Is(err, errAccessDenied) {
+		t.Fatalf("getUserInfo() error = %v, want access denied", err)
+	}
+}
+
+func TestGoogleAuthMapsAdminEmails(t *testing.T) {
+	t.Setenv("AUTH_ADMIN_CLAIM", "")
+	t.Setenv("AUTH_ADMIN_VALUE", "")
+
+	server := newFakeOIDCServer(t, googleClaims("Commish@jellycat.example", "jellycat.example"))
+	provider, err := NewGoogleAuth(&GoogleConfig{
+		IssuerURL:     server.URL,
+		AllowedDomain: "jellycat.example",
+		AdminEmails:   []string{"commish@jellycat.example"},
+	})
+	if err != nil {
+		t.Fatalf("NewGoogleAuth() failed: %v", err)
+	}
+
+	user, err := provider.getUserInfo(context.Background(), &oauth2.Token{AccessToken: "test-token"})
+	if err != nil {
+		t.Fatalf("getUserInfo() failed: %v", err)
+	}
+	if user.Username != "Commish@jellycat.example" {
+		t.Fatalf("username = %q, want email", user.Username)
+	}
+	if !IsAdmin(user) {
+		t.Fatal("expected configured admin email to be an admin")
+	}
+}
+
+func TestGoogleAuthLoginSendsHostedDomainHint(t *testing.T) {
+	server := newFakeOIDCServer(t, nil)
+	provider, err := NewGoogleAuth(&GoogleConfig{IssuerURL: server.URL, AllowedDomain: "jellycat.example"})
+	if err != nil {
+		t.Fatalf("NewGoogleAuth() failed: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	provider.LoginHandler(recorder, httptest.NewRequest(http.MethodGet, "/auth/login", nil))
+
+	location, err := url.Parse(recorder.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("parse redirect: %v", err)
+	}
+	if got := location.Query().Get("hd"); got != "jellycat.example" {
+		t.Fatalf("hd = %q, want %q", got, "jellycat.example")
+	}
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+)
+
+func TestImpersonationSwapsAndRestoresSessionUser(t *testing.T) {
+	t.Setenv("AUTH_ADMIN_CLAIM", "")
+	t.Setenv("AUTH_ADMIN_VALUE", "")
+
+	events := dal.NewMemoryDAL()
+	provider := &MockAuth{sessionManager: newSessionManager(nil, NewAuditLog(events), false)}
+
+	login := httptest.NewRecorder()
+	provider.LoginHandler(login, httptest.NewRequest(http.MethodGet, "/auth/login", nil))
+	request := httptest.NewRequest(http.MethodPost, "/auth/impersonate", nil)
+	for _, cookie := range login.Result().Cookies() {
+		request.AddCookie(cookie)
+	}
+
+	target := &User{ID: "user-owner", Username: "owner", Name: "owner"}
+	if err := provider.Impersonate(httptest.NewRecorder(), request, target); err != nil {
+		t.Fatalf("Impersonate() failed: %v", err)
+	}
+	user, _ := provider.userFromRequest(request)
+	if user == nil || user.ID != "user-owner" || user.Impersonator == nil || user.Impersonator.ID != "dev-user-123" {
+		t.Fatalf("impersonated user = %+v, want owner with admin recorded", user)
+	}
+	if IsAdmin(user) {
+		t.Fatal("impersonated session has admin privileges")
+	}
+	if err := provider.Impersonate(httptest.NewRecorder(), request, target); !errors.Is(err, ErrAlreadyImpersonating) {
+		t.Fatalf("nested Impersonate() error = %v, want %v", err, ErrAlreadyImpersonating)
+	}
+
+	if err := provider.Unimpersonate(httptest.NewRecorder(), request); err != nil {
+		t.Fatalf("Unimpersonate() failed: %v", err)
+	}
+	user, _ = provider.userFromRequest(request)
+	if user == nil || user.ID != "dev-user-123" || user.Impersonator != nil || !IsAdmin(user) {
+		t.Fatalf("restored user = %+v, want the admin back", user)
+	}
+	if err := provider.Unimpersonate(httptest.NewRecorder(), request); !errors.Is(err, ErrNotImpersonating) {
+		t.Fatalf("second Unimpersonate() error = %v, want %v", err, ErrNotImpersonating)
+	}
+
+	audited, _ := events.ListAuthEvents(dal.AuthEventFilter{User: "dev-user-123"})
+	if len(audited) != 3 || audited[0].Type != EventImpersonationEnd || audited[1].Type != EventImpersonationStart ||
+		audited[1].UserID != "user-owner" || audited[1].ImpersonatorID != "dev-user-123" {
+		t.Fatalf("audit events = %+v, want login then start and end naming the admin", audited)
+	}
+}
+
+func TestImpersonationRequiresSession(t *testing.T) {
+	provider, _ := NewMockAuth()
+	request := httptest.NewRequest(http.MethodPost, "/auth/impersonate", nil)
+	if err := provider.Impersonate(httptest.NewRecorder(), request, &User{ID: "someone"}); !errors.Is(err, ErrNoSession) {
+		t.Fatalf("Impersonate() without a session error = %v, want %v", err, ErrNoSession)
+	}
+}
@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// LocalUser is a statically configured account for LocalAuth
+type LocalUser struct {
+	Username     string   `json:"username"`
+	PasswordHash string   `json:"passwordHash"`
+	Name         string   `json:"name,omitempty"`
+	Email        string   `json:"email,omitempty"`
+	Groups       []string `json:"groups,omitempty"`
+}
+
+// LocalConfig holds the configuration for the static user-list provider
+type LocalConfig struct {
+	Users []LocalUser
+
+	// MaxFailures is the number of bad passwords before an account is locked (default 5).
+	MaxFailures int
+	// LockoutDuration is how long a locked account stays locked (default 15 minutes).
+	LockoutDuration time.Duration
+	// InsecureCookies drops the Secure cookie flag for plain-HTTP LAN deployments.
+	InsecureCookies bool
+	// Sessions stores login sessions (default in-memory).
+	Sessions SessionStore
+	// Audit records sign-ins, sign-outs and expired sessions (optional).
+	Audit *AuditLog
+}
+
+// LocalAuth authenticates against a fixed list of users with bcrypt password hashes
+type LocalAuth struct {
+	*sessionManager
+	config *LocalConfig
+	users  map[string]LocalUser
+
+	failureMu sync.Mutex
+	failures  map[string]*loginFailures
+}
+
+type loginFailures struct {
+	count       int
+	lockedUntil time.Time
+}
+
+// dummyPasswordHash keeps unknown-username logins as slow as real ones.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("jellycat-dummy-password"), bcrypt.DefaultCost)
+	return hash
+})
+
+var localLoginTemplate = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>Sign in - Jellycat Draft</title>
+	<link rel="stylesheet" href="/static/css/styles.css">
+</head>
+<body class="min-h-screen flex items-center justify-center bg-pink-50">
+	<form method="post" action="/auth/login" class="bg-white rounded-xl shadow p-6 w-80 space-y-4">
+		<h1 class="text-xl font-bold">Sign in</h1>
+		{{if .Error}}<p class="text-sm text-red-600">{{.Error}}</p>{{end}}
+		<label class="block text-sm">Username
+			<input name="username" value="{{.Username}}" autocomplete="username" required class="mt-1 w-full border rounded px-2 py-1">
+		</label>
+		<label class="block text-sm">Password
+			<input name="password" type="password" autocomplete="current-password" required class="mt-1 w-full border rounded px-2 py-1">
+		</label>
+		<button type="submit" class="w-full bg-pink-500 text-white rounded py-2">Sign in</button>
+	</form>
+</body>
+</html>
+`))
+
+// NewLocalAuth creates a new static user-list authentication handler
+func NewLocalAuth(config *LocalConfig) (*LocalAuth, error) {
+	if len(config.Users) == 0 {
+		return nil, fmt.Errorf("local auth requires at least one user")
+	}
+	if config.MaxFailures <= 0 {
+		config.MaxFailures = 5
+	}
+	if config.LockoutDuration <= 0 {
+		config.LockoutDuration = 15 * time.Minute
+	}
+
+	users := make(map[string]LocalUser, len(config.Users))
+	for _, user := range config.Users {
+		key := strings.ToLower(strings.TrimSpace(user.Username))
+		if key == "" || user.PasswordHash == "" {
+			return nil, fmt.Errorf("local auth user entries require a username and password hash")
+		}
+		if _, exists := users[key]; exists {
+			return nil, fmt.Errorf("duplicate local auth user: %s", user.Username)
+		}
+		users[key] = user
+	}
+
+	return &LocalAuth{
+		sessionManager: newSessionManager(config.Sessions, config.Audit, !config.InsecureCookies),
+		config:         config,
+		users:          users,
+		failures:       make(map[string]*loginFailures),
+	}, nil
+}
+
+// LoadLocalUsers reads users from a JSON file containing an array of LocalUser entries
+func LoadLocalUsers(path string) ([]LocalUser, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local users file: %w", err)
+	}
+
+	var users []LocalUser
+	if err := json.Unmarshal(data, &users); err != nil {
+		return nil, fmt.Errorf("failed to parse local users file: %w", err)
+	}
+	return users, nil
+}
+
+// ParseLocalUsers parses "username:bcrypt-hash[:group1|group2]" entries separated by commas.
+func ParseLocalUsers(value string) ([]LocalUser, error) {
+	var users []LocalUser
+	for _, entry := range splitAdminValues(value) {
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("invalid local user entry %q (want username:hash[:groups])", entry)
+		}
+
+		user := LocalUser{Username: parts[0], PasswordHash: parts[1]}
+		if len(parts) == 3 && parts[2] != "" {
+			user.Groups = strings.Split(parts[2], "|")
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// HashPassword returns a bcrypt hash suitable for a LocalUser entry
+func HashPassword(password string) (string, error) {
+	if password == "" {
+		return "", fmt.Errorf("password must not be empty")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// LoginHandler renders the login form on GET and validates credentials on POST
+func (l *LocalAuth) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		l.renderLogin(w, http.StatusOK, "", "")
+		return
+	}
+
+	username := strings.TrimSpace(r.FormValue("username"))
+	password := r.FormValue("password")
+
+	user, err := l.authenticate(username, password, time.Now())
+	if err != nil {
+		l.renderLogin(w, http.StatusUnauthorized, username, err.Error())
+		return
+	}
+
+	if l.createSession(w, r, user, nil) == nil {
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// CallbackHandler is not needed for local auth
+func (l *LocalAuth) CallbackHandler(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// LogoutHandler handles user logout
+func (l *LocalAuth) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	l.destroySession(w, r)
+	http.Redirect(w, r, "/start", http.StatusSeeOther)
+}
+
+// authenticate checks the password and applies the failure lockout
+func (l *LocalAuth) authenticate(username, password string, now time.Time) (*User, error) {
+	key := strings.ToLower(username)
+
+	l.failureMu.Lock()
+	if failure := l.failures[key]; failure != nil && now.Before(failure.lockedUntil) {
+		l.failureMu.Unlock()
+		return nil, fmt.Errorf("too many failed attempts, try again later")
+	}
+	l.failureMu.Unlock()
+
+	account, exists := l.users[key]
+	hash := dummyPasswordHash()
+	if exists {
+		hash = []byte(account.PasswordHash)
+	}
+
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil || !exists {
+		// Only real accounts are tracked so random usernames can't grow the map.
+		if exists {
+			l.recordFailure(key, now)
+		}
+		return nil, fmt.Errorf("invalid username or password")
+	}
+
+	l.failureMu.Lock()
+	delete(l.failures, key)
+	l.failureMu.Unlock()
+
+	name := account.Name
+	if name == "" {
+		name = account.Username
+	}
+	return &User{
+		ID:       "local:" + key,
+		Email:    account.Email,
+		Name:     name,
+		Username: account.Username,
+		Groups:   append([]string(nil), account.Groups...),
+	}, nil
+}
+
+func (l *LocalAuth) recordFailure(key string, now time.Time) {
+	l.failureMu.Lock()
+	defer l.failureMu.Unlock()
+
+	failure := l.failures[key]
+	if failure == nil || (!failure.lockedUntil.IsZero() && !now.Before(failure.lockedUntil)) {
+		failure = &loginFailures{}
+		l.failures[key] = failure
+	}
+
+	failure.count++
+	if failure.count >= l.config.MaxFailures {
+		failure.lockedUntil = now.Add(l.config.LockoutDuration)
+	}
+}
+
+func (l *LocalAuth) renderLogin(w http.ResponseWriter, status int, username, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	localLoginTemplate.Execute(w, map[string]string{"Username": username, "Error": message})
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestLocalAuth(t *testing.T) *LocalAuth {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("cuddles"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+
+	provider, err := NewLocalAuth(&LocalConfig{
+		Users:       []LocalUser{{Username: "Taylor", PasswordHash: string(hash), Groups: []string{"admins"}}},
+		MaxFailures: 3,
+	})
+	if err != nil {
+		t.Fatalf("NewLocalAuth() failed: %v", err)
+	}
+	return provider
+}
+
+func postLocalLogin(provider *LocalAuth, username, password string) *httptest.ResponseRecorder {
+	form := url.Values{"username": {username}, "password": {password}}
+	request := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(form.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	provider.LoginHandler(recorder, request)
+	return recorder
+}
+
+func TestLocalAuthLoginCreatesSession(t *testing.T) {
+	provider := newTestLocalAuth(t)
+
+	recorder := postLocalLogin(provider, "taylor", "cuddles")
+	if recorder.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusSeeOther)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range recorder.Result().Cookies() {
+		request.AddCookie(cookie)
+	}
+	user, _ := provider.userFromRequest(request)
+	if user == nil || user.Username != "Taylor" || !containsAdminValue(user.Groups, "admins") {
+		t.Fatalf("session user = %+v, want Taylor in admins", user)
+	}
+}
+
+func TestLocalAuthRejectsBadPassword(t *testing.T) {
+	provider := newTestLocalAuth(t)
+
+	if recorder := postLocalLogin(provider, "Taylor", "wrong"); recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusUnauthorized)
+	}
+	if recorder := postLocalLogin(provider, "nobody", "cuddles"); recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("unknown user status = %d, want %d", recorder.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestLocalAuthLocksOutAfterRepeatedFailures(t *testing.T) {
+	provider := newTestLocalAuth(t)
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if _, err := provider.authenticate("Taylor", "wrong", now); err == nil {
+			t.Fatal("expected bad password to fail")
+		}
+	}
+
+	if _, err := provider.authenticate("Taylor", "cuddles", now.Add(time.Minute)); err == nil {
+		t.Fatal("expected locked account to reject the correct password")
+	}
+	if _, err := provider.authenticate("Taylor", "cuddles", now.Add(16*time.Minute)); err != nil {
+		t.Fatalf("expected lockout to expire, got %v", err)
+	}
+}
+
+func TestParseLocalUsers(t *testing.T) {
+	users, err := ParseLocalUsers("alice:$2a$10$abc:admins|users, bob:$2a$10$def")
+	if err != nil {
+		t.Fatalf("ParseLocalUsers() failed: %v", err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("users length = %d, want 2", len(users))
+	}
+	if users[0].PasswordHash != "$2a$10$abc" || len(users[0].Groups) != 2 {
+		t.Fatalf("users[0] = %+v, want hash and two groups", users[0])
+	}
+	if users[1].Username != "bob" || users[1].Groups != nil {
+		t.Fatalf("users[1] = %+v, want bob with no groups", users[1])
+	}
+}
@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// MockUser is a development account offered on the mock login page
+type MockUser struct {
+	// ID defaults to "mock:" plus the lowercased username.
+	ID       string   `json:"id,omitempty"`
+	Username string   `json:"username"`
+	Name     string   `json:"name,omitempty"`
+	Email    string   `json:"email,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+}
+
+// defaultMockUser is signed in automatically when no mock users are configured.
+var defaultMockUser = MockUser{
+	ID:       "dev-user-123",
+	Email:    "billy@jellycat.local",
+	Name:     "Billy",
+	Username: "Billy",
+	Groups:   []string{"users", "admins"},
+}
+
+var mockLoginTemplate = template.Must(template.New("mock-login").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>Choose a user - Jellycat Draft</title>
+	<link rel="stylesheet" href="/static/css/styles.css">
+</head>
+<body class="min-h-screen flex items-center justify-center bg-pink-50">
+	<form method="post" action="/auth/login" class="bg-white rounded-xl shadow p-6 w-80 space-y-3">
+		<h1 class="text-xl font-bold">Sign in as</h1>
+		<p class="text-xs text-gray-500">Development mode: pick any mock user.</p>
+		{{if .Error}}<p class="text-sm text-red-600">{{.Error}}</p>{{end}}
+		{{range .Users}}
+		<button type="submit" name="username" value="{{.Username}}" class="w-full text-left border rounded px-3 py-2 hover:bg-pink-100">
+			<span class="font-medium">{{if .Name}}{{.Name}}{{else}}{{.Username}}{{end}}</span>
+			{{if .Groups}}<span class="block text-xs text-gray-500">{{range $i, $g := .Groups}}{{if $i}}, {{end}}{{$g}}{{end}}</span>{{end}}
+		</button>
+		{{end}}
+	</form>
+</body>
+</html>
+`))
+
+// MockAuth provides a mock authentication for local development. Without
+// configured users every login signs in as a single admin; with users, the
+// login page lets you pick which one to be.
+type MockAuth struct {
+	*sessionManager
+	users []MockUser
+}
+
+// NewMockAuth creates a new mock authentication handler. Passing no users
+// keeps the zero-config single admin.
+func NewMockAuth(users ...MockUser) (*MockAuth, error) {
+	seen := make(map[string]bool, len(users))
+	for i := range users {
+		user := &users[i]
+		user.Username = strings.TrimSpace(user.Username)
+		key := strings.ToLower(user.Username)
+		if key == "" {
+			return nil, fmt.Errorf("mock user entries require a username")
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate mock user: %s", user.Username)
+		}
+		seen[key] = true
+		if user.ID == "" {
+			user.ID = "mock:" + key
+		}
+	}
+
+	return &MockAuth{
+		sessionManager: newSessionManager(nil, nil, false),
+		users:          users,
+	}, nil
+}
+
+// LoadMockUsers reads users from a JSON file containing an array of MockUser entries
+func LoadMockUsers(path string) ([]MockUser, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mock users file: %w", err)
+	}
+	return ParseMockUsers(string(data))
+}
+
+// ParseMockUsers accepts either a JSON array of MockUser entries or
+// "username[:group1|group2]" entries separated by commas.
+func ParseMockUsers(value string) ([]MockUser, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "[") {
+		var users []MockUser
+		if err := json.Unmarshal([]byte(value), &users); err != nil {
+			return nil, fmt.Errorf("failed to parse mock users: %w", err)
+		}
+		return users, nil
+	}
+
+	var users []MockUser
+	for _, entry := range splitAdminValues(value) {
+		username, groups, _ := strings.Cut(entry, ":")
+		user := MockUser{Username: username}
+		if groups != "" {
+			user.Groups = strings.Split(groups, "|")
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// LoginHandler signs in as the default admin when no users are configured,
+// otherwise renders the user picker on GET and signs in as the chosen user on POST.
+func (m *MockAuth) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	account := defaultMockUser
+	if len(m.users) > 0 {
+		if r.Method != http.MethodPost {
+			m.renderLogin(w, http.StatusOK, "")
+			return
+		}
+
+		chosen, ok := m.lookup(r.FormValue("username"))
+		if !ok {
+			m.renderLogin(w, http.StatusBadRequest, "Unknown mock user")
+			return
+		}
+		account = chosen
+	}
+
+	name := account.Name
+	if name == "" {
+		name = account.Username
+	}
+	session := m.createSession(w, r, &User{
+		ID:       account.ID,
+		Email:    account.Email,
+		Name:     name,
+		Username: account.Username,
+		Groups:   append([]string(nil), account.Groups...),
+	}, nil)
+	if session == nil {
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// CallbackHandler is not needed for mock auth
+func (m *MockAuth) CallbackHandler(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// LogoutHandler for mock auth
+func (m *MockAuth) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	m.destroySession(w, r)
+	http.Redirect(w, r, "/start", http.StatusSeeOther)
+}
+
+func (m *MockAuth) lookup(username string) (MockUser, bool) {
+	username = strings.TrimSpace(username)
+	for _, user := range m.users {
+		if strings.EqualFold(user.Username, username) {
+			return user, true
+		}
+	}
+	return MockUser{}, false
+}
+
+func (m *MockAuth) renderLogin(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	mockLoginTemplate.Execute(w, map[string]interface{}{"Users": m.users, "Error": message})
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func mockSessionUser(t *testing.T, provider *MockAuth, recorder *httptest.ResponseRecorder) *User {
+	t.Helper()
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range recorder.Result().Cookies() {
+		request.AddCookie(cookie)
+	}
+	user, _ := provider.userFromRequest(request)
+	return user
+}
+
+func TestMockAuthDefaultsToSingleAdmin(t *testing.T) {
+	provider, err := NewMockAuth()
+	if err != nil {
+		t.Fatalf("NewMockAuth() failed: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	provider.LoginHandler(recorder, httptest.NewRequest(http.MethodGet, "/auth/login", nil))
+	if recorder.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusSeeOther)
+	}
+	if user := mockSessionUser(t, provider, recorder); user == nil || user.ID != "dev-user-123" {
+		t.Fatalf("session user = %+v, want default admin", user)
+	}
+}
+
+func TestMockAuthUserPicker(t *testing.T) {
+	users, err := ParseMockUsers("Owner:users, Commish:users|admins")
+	if err != nil {
+		t.Fatalf("ParseMockUsers() failed: %v", err)
+	}
+	provider, err := NewMockAuth(users...)
+	if err != nil {
+		t.Fatalf("NewMockAuth() failed: %v", err)
+	}
+
+	page := httptest.NewRecorder()
+	provider.LoginHandler(page, httptest.NewRequest(http.MethodGet, "/auth/login", nil))
+	if page.Code != http.StatusOK || !strings.Contains(page.Body.String(), `value="Owner"`) || !strings.Contains(page.Body.String(), "users, admins") {
+		t.Fatalf("picker = %d %q, want both users listed", page.Code, page.Body.String())
+	}
+
+	choose := func(username string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(url.Values{"username": {username}}.Encode()))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		recorder := httptest.NewRecorder()
+		provider.LoginHandler(recorder, request)
+		return recorder
+	}
+
+	login := choose("owner")
+	user := mockSessionUser(t, provider, login)
+	if login.Code != http.StatusSeeOther || user == nil || user.ID != "mock:owner" || user.Name != "Owner" || containsAdminValue(user.Groups, "admins") {
+		t.Fatalf("login = %d user %+v, want non-admin Owner", login.Code, user)
+	}
+	if code := choose("stranger").Code; code != http.StatusBadRequest {
+		t.Fatalf("unknown user status = %d, want %d", code, http.StatusBadRequest)
+	}
+}
+
+func TestParseMockUsersJSON(t *testing.T) {
+	users, err := ParseMockUsers(`[{"username":"alice","name":"Alice","groups":["admins"]},{"username":"bob","id":"u-bob"}]`)
+	if err != nil {
+		t.Fatalf("ParseMockUsers() failed: %v", err)
+	}
+	if len(users) != 2 || users[0].Name != "Alice" || users[1].ID != "u-bob" {
+		t.Fatalf("users = %+v, want alice and bob", users)
+	}
+
+	if _, err := NewMockAuth(MockUser{Username: "alice"}, MockUser{Username: "Alice"}); err == nil {
+		t.Fatal("NewMockAuth() accepted duplicate usernames")
+	}
+}
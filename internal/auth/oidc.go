@@ -0,0 +1,317 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// OIDCConfig holds the configuration for a generic OpenID Connect provider.
+// Only the issuer is required to locate the remaining endpoints.
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+
+	// UsernameClaim is a dotted claim path used for User.Username (default "preferred_username").
+	UsernameClaim string
+	// GroupsClaim is a dotted claim path used for User.Groups (default "groups").
+	// Keycloak exposes realm roles at "realm_access.roles".
+	GroupsClaim string
+
+	// HTTPClient is used for discovery and userinfo requests (default 10s timeout client).
+	HTTPClient *http.Client
+	// Sessions stores login sessions (default in-memory).
+	Sessions SessionStore
+	// Audit records sign-ins, sign-outs and expired sessions (optional).
+	Audit *AuditLog
+}
+
+// OIDCEndpoints are the provider endpoints published in the discovery document.
+type OIDCEndpoints struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+}
+
+// OIDCAuth manages authentication against any OpenID Connect provider
+type OIDCAuth struct {
+	*sessionManager
+	config       *OIDCConfig
+	endpoints    OIDCEndpoints
+	oauth2Config *oauth2.Config
+	httpClient   *http.Client
+	// jwks caches the provider's signing keys for back-channel logout tokens.
+	jwks jwksCache
+
+	// authCodeOptions are extra parameters added to the authorize redirect (e.g. Google's hd hint).
+	authCodeOptions []oauth2.AuthCodeOption
+	// authorizeClaims lets presets reject or enrich a user after the claims are mapped.
+	// Returning an error wrapping errAccessDenied rejects the login with 403.
+	authorizeClaims func(claims map[string]interface{}, user *User) error
+}
+
+// errAccessDenied marks a login that authenticated but is not allowed into the app
+var errAccessDenied = errors.New("access denied")
+
+// DiscoverOIDCEndpoints fetches /.well-known/openid-configuration for the issuer.
+func DiscoverOIDCEndpoints(ctx context.Context, client *http.Client, issuerURL string) (*OIDCEndpoints, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	discoveryURL := strings.TrimRight(issuerURL, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch OIDC discovery document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("failed to fetch OIDC discovery document: %s - %s", resp.Status, string(body))
+	}
+
+	var endpoints OIDCEndpoints
+	if err := json.NewDecoder(resp.Body).Decode(&endpoints); err != nil {
+		return nil, fmt.Errorf("failed to decode OIDC discovery document: %w", err)
+	}
+
+	if endpoints.AuthorizationEndpoint == "" || endpoints.TokenEndpoint == "" {
+		return nil, fmt.Errorf("OIDC discovery document is missing authorization or token endpoint")
+	}
+
+	return &endpoints, nil
+}
+
+// NewOIDCAuth discovers the provider endpoints and creates a new OIDC authentication handler
+func NewOIDCAuth(config *OIDCConfig) (*OIDCAuth, error) {
+	if config.IssuerURL == "" {
+		return nil, fmt.Errorf("OIDC issuer URL is required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	endpoints, err := DiscoverOIDCEndpoints(ctx, config.HTTPClient, config.IssuerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return newOIDCAuthWithEndpoints(config, *endpoints), nil
+}
+
+// newOIDCAuthWithEndpoints builds a provider from already-known endpoints.
+// Presets such as Authentik use this to skip discovery.
+func newOIDCAuthWithEndpoints(config *OIDCConfig, endpoints OIDCEndpoints) *OIDCAuth {
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "profile", "email"}
+	}
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = "preferred_username"
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	oauth2Config := &oauth2.Config{
+		ClientID:     config.ClientID,
+		ClientSecret: config.ClientSecret,
+		RedirectURL:  config.RedirectURL,
+		Scopes:       config.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  endpoints.AuthorizationEndpoint,
+			TokenURL: endpoints.TokenEndpoint,
+		},
+	}
+	sessions := newSessionManager(config.Sessions, config.Audit, true)
+	sessions.refresh = oauthRefresher(oauth2Config, httpClient)
+
+	return &OIDCAuth{
+		config:         config,
+		endpoints:      endpoints,
+		oauth2Config:   oauth2Config,
+		httpClient:     httpClient,
+		sessionManager: sessions,
+	}
+}
+
+// Endpoints returns the endpoints the provider is using.
+func (o *OIDCAuth) Endpoints() OIDCEndpoints {
+	return o.endpoints
+}
+
+// LoginHandler initiates the OAuth2 login flow
+func (o *OIDCAuth) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	state := setStateCookie(w, r)
+
+	// Redirect to the identity provider
+	authURL := o.oauth2Config.AuthCodeURL(state, o.authCodeOptions...)
+	http.Redirect(w, r, authURL, http.StatusTemporaryRedirect)
+}
+
+// CallbackHandler handles the OAuth2 callback from the identity provider
+func (o *OIDCAuth) CallbackHandler(w http.ResponseWriter, r *http.Request) {
+	next, ok := verifyStateCookie(w, r)
+	if !ok {
+		return
+	}
+
+	// Exchange code for token
+	code := r.URL.Query().Get("code")
+	token, err := o.oauth2Config.Exchange(context.Background(), code)
+	if err != nil {
+		http.Error(w, "Failed to exchange token: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Get user info
+	user, err := o.getUserInfo(r.Context(), token)
+	if errors.Is(err, errAccessDenied) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to get user info: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if o.createSession(w, r, user, token) == nil {
+		return
+	}
+
+	// Redirect to the page that started the login
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+// LogoutHandler handles user logout
+func (o *OIDCAuth) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	o.destroySession(w, r)
+
+	// Redirect to the provider's end-session endpoint when it publishes one
+	if o.endpoints.EndSessionEndpoint != "" {
+		http.Redirect(w, r, o.endpoints.EndSessionEndpoint, http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/start", http.StatusSeeOther)
+}
+
+// getUserInfo fetches the userinfo claims and maps them onto a User
+func (o *OIDCAuth) getUserInfo(ctx context.Context, token *oauth2.Token) (*User, error) {
+	if o.endpoints.UserInfoEndpoint == "" {
+		return nil, fmt.Errorf("provider does not publish a userinfo endpoint")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.endpoints.UserInfoEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get user info: %s - %s", resp.Status, string(body))
+	}
+
+	var claims map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&claims); err != nil {
+		return nil, err
+	}
+
+	user := o.userFromClaims(claims)
+	if o.authorizeClaims != nil {
+		if err := o.authorizeClaims(claims, user); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// userFromClaims maps raw claims onto a User using the configured claim paths.
+func (o *OIDCAuth) userFromClaims(claims map[string]interface{}) *User {
+	return &User{
+		ID:       claimString(claims, "sub"),
+		Email:    claimString(claims, "email"),
+		Name:     claimString(claims, "name"),
+		Username: claimString(claims, o.config.UsernameClaim),
+		Groups:   claimStrings(claims, o.config.GroupsClaim),
+	}
+}
+
+// lookupClaim resolves a dotted path such as "realm_access.roles" in a claim set.
+func lookupClaim(claims map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = claims
+	for _, part := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = object[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func claimString(claims map[string]interface{}, path string) string {
+	value, ok := lookupClaim(claims, path)
+	if !ok || value == nil {
+		return ""
+	}
+	if text, ok := value.(string); ok {
+		return text
+	}
+	return fmt.Sprint(value)
+}
+
+func claimStrings(claims map[string]interface{}, path string) []string {
+	value, ok := lookupClaim(claims, path)
+	if !ok || value == nil {
+		return nil
+	}
+
+	switch typed := value.(type) {
+	case []interface{}:
+		values := make([]string, 0, len(typed))
+		for _, item := range typed {
+			if text, ok := item.(string); ok && text != "" {
+				values = append(values, text)
+			}
+		}
+		return values
+	case string:
+		return splitAdminValues(typed)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func newFakeOIDCServer(t *testing.T, claims map[string]interface{}) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 server.URL,
+			"authorization_endpoint": server.URL + "/authorize",
+			"token_endpoint":         server.URL + "/token",
+			"userinfo_endpoint":      server.URL + "/userinfo",
+			"jwks_uri":               server.URL + "/jwks",
+			"end_session_endpoint":   server.URL + "/logout",
+		})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(claims)
+	})
+
+	return server
+}
+
+func TestNewOIDCAuthUsesDiscoveryDocument(t *testing.T) {
+	server := newFakeOIDCServer(t, nil)
+
+	provider, err := NewOIDCAuth(&OIDCConfig{IssuerURL: server.URL + "/", ClientID: "client", ClientSecret: "secret"})
+	if err != nil {
+		t.Fatalf("NewOIDCAuth() failed: %v", err)
+	}
+
+	endpoints := provider.Endpoints()
+	if endpoints.AuthorizationEndpoint != server.URL+"/authorize" {
+		t.Fatalf("authorization endpoint = %q, want %q", endpoints.AuthorizationEndpoint, server.URL+"/authorize")
+	}
+	if endpoints.TokenEndpoint != server.URL+"/token" {
+		t.Fatalf("token endpoint = %q, want %q", endpoints.TokenEndpoint, server.URL+"/token")
+	}
+	if endpoints.EndSessionEndpoint != server.URL+"/logout" {
+		t.Fatalf("end session endpoint = %q, want %q", endpoints.EndSessionEndpoint, server.URL+"/logout")
+	}
+}
+
+func TestDiscoverOIDCEndpointsRejectsMissingDocument(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	if _, err := DiscoverOIDCEndpoints(context.Background(), nil, server.URL); err == nil {
+		t.Fatal("expected error for missing discovery document")
+	}
+}
+
+func TestOIDCUserInfoMapsKeycloakRoles(t *testing.T) {
+	server := newFakeOIDCServer(t, map[string]interface{}{
+		"sub":                "user-1",
+		"email":              "taylor@example.com",
+		"name":               "Taylor",
+		"preferred_username": "taylor",
+		"realm_access": map[string]interface{}{
+			"roles": []string{"users", "admins"},
+		},
+	})
+
+	provider, err := NewOIDCAuth(&OIDCConfig{IssuerURL: server.URL, GroupsClaim: "realm_access.roles"})
+	if err != nil {
+		t.Fatalf("NewOIDCAuth() failed: %v", err)
+	}
+
+	user, err := provider.getUserInfo(context.Background(), &oauth2.Token{AccessToken: "test-token", TokenType: "Bearer"})
+	if err != nil {
+		t.Fatalf("getUserInfo() failed: %v", err)
+	}
+
+	if user.ID != "user-1" || user.Username != "taylor" || user.Email != "taylor@example.com" {
+		t.Fatalf("user = %+v, want mapped identity claims", user)
+	}
+	if len(user.Groups) != 2 || user.Groups[1] != "admins" {
+		t.Fatalf("groups = %v, want [users admins]", user.Groups)
+	}
+}
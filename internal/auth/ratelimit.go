@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LoginLimiterConfig tunes login throttling. Zero values use the defaults.
+type LoginLimiterConfig struct {
+	// FreeFailures is how many failed logins an IP or username gets before backoff starts (default 5).
+	FreeFailures int
+	// BaseDelay is the first backoff; each further failure doubles it (default 1s).
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff (default 15 minutes).
+	MaxDelay time.Duration
+}
+
+// LoginLimiter throttles failed logins per client IP and per username with
+// exponential backoff. A successful login clears both counters.
+type LoginLimiter struct {
+	config LoginLimiterConfig
+	audit  *AuditLog
+	now    func() time.Time
+
+	mu       sync.Mutex
+	attempts map[string]*loginAttempts
+}
+
+type loginAttempts struct {
+	failures     int
+	blockedUntil time.Time
+	lastFailure  time.Time
+}
+
+// maxTrackedLoginKeys triggers a sweep of idle entries so spraying usernames can't grow the map unbounded.
+const maxTrackedLoginKeys = 10000
+
+// NewLoginLimiter creates a limiter that records throttled and failed logins to audit.
+func NewLoginLimiter(config LoginLimiterConfig, audit *AuditLog) *LoginLimiter {
+	if config.FreeFailures <= 0 {
+		config.FreeFailures = 5
+	}
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = time.Second
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = 15 * time.Minute
+	}
+	return &LoginLimiter{
+		config:   config,
+		audit:    audit,
+		now:      time.Now,
+		attempts: make(map[string]*loginAttempts),
+	}
+}
+
+// retryAfter returns how long the caller must wait before any of keys may try again.
+func (l *LoginLimiter) retryAfter(keys []string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	var wait time.Duration
+	for _, key := range keys {
+		if attempt := l.attempts[key]; attempt != nil && attempt.blockedUntil.After(now) {
+			wait = max(wait, attempt.blockedUntil.Sub(now))
+		}
+	}
+	return wait
+}
+
+func (l *LoginLimiter) recordFailure(keys []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if len(l.attempts) >= maxTrackedLoginKeys {
+		for key, attempt := range l.attempts {
+			if now.Sub(attempt.lastFailure) > l.config.MaxDelay && !attempt.blockedUntil.After(now) {
+				delete(l.attempts, key)
+			}
+		}
+	}
+
+	for _, key := range keys {
+		attempt := l.attempts[key]
+		if attempt == nil || now.Sub(attempt.lastFailure) > l.config.MaxDelay {
+			// A quiet period as long as the longest backoff starts the count over.
+			attempt = &loginAttempts{}
+			l.attempts[key] = attempt
+		}
+		attempt.failures++
+		attempt.lastFailure = now
+
+		if excess := attempt.failures - l.config.FreeFailures; excess > 0 {
+			delay := l.config.MaxDelay
+			if excess < 32 {
+				delay = min(l.config.BaseDelay<<(excess-1), l.config.MaxDelay)
+			}
+			attempt.blockedUntil = now.Add(delay)
+		}
+	}
+}
+
+func (l *LoginLimiter) recordSuccess(keys []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		delete(l.attempts, key)
+	}
+}
+
+// WithLoginLimiter throttles provider's credential and callback endpoints:
+// POST /auth/login (per IP and per submitted username) and /auth/callback
+// (per IP). Throttled requests get 429 with a Retry-After header.
+func WithLoginLimiter(provider AuthProvider, limiter *LoginLimiter) AuthProvider {
+	return &limitedProvider{AuthProvider: provider, limiter: limiter}
+}
+
+type limitedProvider struct {
+	AuthProvider
+	limiter *LoginLimiter
+}
+
+func (p *limitedProvider) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	// GET only renders a form or redirects to the identity provider.
+	if r.Method != http.MethodPost {
+		p.AuthProvider.LoginHandler(w, r)
+		return
+	}
+	username := strings.ToLower(strings.TrimSpace(r.FormValue("username")))
+	p.limit(w, r, username, p.AuthProvider.LoginHandler)
+}
+
+func (p *limitedProvider) CallbackHandler(w http.ResponseWriter, r *http.Request) {
+	p.limit(w, r, "", p.AuthProvider.CallbackHandler)
+}
+
+func (p *limitedProvider) limit(w http.ResponseWriter, r *http.Request, username string, next http.HandlerFunc) {
+	keys := []string{"ip:" + ClientIP(r)}
+	if username != "" {
+		keys = append(keys, "user:"+username)
+	}
+
+	if wait := p.limiter.retryAfter(keys); wait > 0 {
+		seconds := int((wait + time.Second - 1) / time.Second)
+		p.limiter.audit.Record(r, EventLoginThrottled, nil, username, fmt.Sprintf("retry after %ds", seconds))
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		http.Error(w, "Too many failed login attempts, try again later", http.StatusTooManyRequests)
+		return
+	}
+
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	next(recorder, r)
+
+	if recorder.status >= http.StatusBadRequest {
+		p.limiter.recordFailure(keys)
+		p.limiter.audit.Record(r, EventLoginFailure, nil, username, fmt.Sprintf("%s returned %d", r.URL.Path, recorder.status))
+		return
+	}
+	p.limiter.recordSuccess(keys)
+}
+
+// statusRecorder captures the status code written by the wrapped handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
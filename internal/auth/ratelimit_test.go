@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newLimitedLocalAuth(t *testing.T) (AuthProvider, *LoginLimiter, dal.AuthEventStore, *time.Time) {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("cuddles"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+	events := dal.NewMemoryDAL()
+	audit := NewAuditLog(events)
+	local, err := NewLocalAuth(&LocalConfig{
+		Users: []LocalUser{{Username: "Taylor", PasswordHash: string(hash)}},
+		Audit: audit,
+	})
+	if err != nil {
+		t.Fatalf("NewLocalAuth() failed: %v", err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	limiter := NewLoginLimiter(LoginLimiterConfig{FreeFailures: 2, BaseDelay: time.Second, MaxDelay: time.Minute}, audit)
+	limiter.now = func() time.Time { return now }
+	return WithLoginLimiter(local, limiter), limiter, events, &now
+}
+
+func postLogin(provider AuthProvider, remoteAddr, username, password string) *httptest.ResponseRecorder {
+	form := url.Values{"username": {username}, "password": {password}}
+	request := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(form.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.RemoteAddr = remoteAddr
+	recorder := httptest.NewRecorder()
+	provider.LoginHandler(recorder, request)
+	return recorder
+}
+
+func authEventTypes(t *testing.T, store dal.AuthEventStore) []string {
+	t.Helper()
+
+	events, err := store.ListAuthEvents(dal.AuthEventFilter{})
+	if err != nil {
+		t.Fatalf("ListAuthEvents() failed: %v", err)
+	}
+	types := make([]string, len(events))
+	for i, event := range events {
+		types[len(events)-1-i] = event.Type
+	}
+	return types
+}
+
+func TestLoginLimiterBacksOffExponentially(t *testing.T) {
+	provider, _, _, now := newLimitedLocalAuth(t)
+
+	for i := 0; i < 3; i++ {
+		if code := postLogin(provider, "10.0.0.1:5000", "nobody", "wrong").Code; code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d status = %d, want %d", i+1, code, http.StatusUnauthorized)
+		}
+	}
+
+	throttled := postLogin(provider, "10.0.0.1:5000", "nobody", "wrong")
+	if throttled.Code != http.StatusTooManyRequests || throttled.Header().Get("Retry-After") != "1" {
+		t.Fatalf("throttled = %d Retry-After %q, want 429 after 1s", throttled.Code, throttled.Header().Get("Retry-After"))
+	}
+
+	*now = now.Add(time.Second)
+	postLogin(provider, "10.0.0.1:5000", "nobody", "wrong")
+	if retry := postLogin(provider, "10.0.0.1:5000", "nobody", "wrong").Header().Get("Retry-After"); retry != "2" {
+		t.Fatalf("second backoff Retry-After = %q, want 2", retry)
+	}
+}
+
+func TestLoginLimiterTracksUsernameAcrossIPs(t *testing.T) {
+	provider, _, _, _ := newLimitedLocalAuth(t)
+
+	for i, ip := range []string{"10.0.0.1:1", "10.0.0.2:1", "10.0.0.3:1"} {
+		if code := postLogin(provider, ip, "Taylor", "wrong").Code; code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d status = %d, want %d", i+1, code, http.StatusUnauthorized)
+		}
+	}
+	if code := postLogin(provider, "10.0.0.4:1", "taylor", "cuddles").Code; code != http.StatusTooManyRequests {
+		t.Fatalf("status from a fresh IP = %d, want %d", code, http.StatusTooManyRequests)
+	}
+	if code := postLogin(provider, "10.0.0.4:1", "someone-else", "wrong").Code; code != http.StatusUnauthorized {
+		t.Fatalf("other username status = %d, want %d", code, http.StatusUnauthorized)
+	}
+}
+
+func TestLoginLimiterResetsOnSuccess(t *testing.T) {
+	provider, limiter, _, _ := newLimitedLocalAuth(t)
+
+	postLogin(provider, "10.0.0.1:1", "Taylor", "wrong")
+	postLogin(provider, "10.0.0.1:1", "Taylor", "wrong")
+	if code := postLogin(provider, "10.0.0.1:1", "Taylor", "cuddles").Code; code != http.StatusSeeOther {
+		t.Fatalf("login status = %d, want %d", code, http.StatusSeeOther)
+	}
+	if len(limiter.attempts) != 0 {
+		t.Fatalf("attempts after success = %v, want none", limiter.attempts)
+	}
+}
+
+func TestLoginEventsAreAudited(t *testing.T) {
+	provider, _, events, _ := newLimitedLocalAuth(t)
+
+	postLogin(provider, "10.0.0.1:1", "Taylor", "wrong")
+	login := postLogin(provider, "10.0.0.1:1", "Taylor", "cuddles")
+
+	logout := httptest.NewRequest(http.MethodGet, "/auth/logout", nil)
+	for _, cookie := range login.Result().Cookies() {
+		logout.AddCookie(cookie)
+	}
+	provider.LogoutHandler(httptest.NewRecorder(), logout)
+
+	// The cookie now points at a deleted session.
+	stale := httptest.NewRecorder()
+	provider.OptionalMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		if GetUser(r) != nil {
+			t.Fatal("stale cookie authenticated")
+		}
+	})(stale, logout)
+	if cookies := stale.Result().Cookies(); len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+		t.Fatalf("stale cookie response cookies = %v, want session cookie cleared", cookies)
+	}
+
+	want := []string{EventLoginFailure, EventLoginSuccess, EventLogout, EventSessionExpired}
+	got := authEventTypes(t, events)
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+
+	failures, _ := events.ListAuthEvents(dal.AuthEventFilter{Type: EventLoginFailure})
+	if failures[0].Username != "taylor" || failures[0].IP != "10.0.0.1" {
+		t.Fatalf("failure event = %+v, want attempted username and IP", failures[0])
+	}
+	successes, _ := events.ListAuthEvents(dal.AuthEventFilter{Type: EventLoginSuccess})
+	if successes[0].UserID != "local:taylor" {
+		t.Fatalf("success event = %+v, want user ID", successes[0])
+	}
+}
+
+func TestClientIPOnlyTrustsForwardedForBehindProxy(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.RemoteAddr = "10.0.0.9:1234"
+	request.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.9")
+
+	t.Setenv("AUTH_TRUST_PROXY", "")
+	if ip := ClientIP(request); ip != "10.0.0.9" {
+		t.Fatalf("ClientIP() = %q, want remote address", ip)
+	}
+	t.Setenv("AUTH_TRUST_PROXY", "true")
+	if ip := ClientIP(request); ip != "203.0.113.7" {
+		t.Fatalf("ClientIP() behind proxy = %q, want forwarded client", ip)
+	}
+}
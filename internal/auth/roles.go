@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// Role is the caller's standing in the draft, derived from groups/claims and team ownership.
+type Role string
+
+const (
+	// RoleSpectator is everyone without a claimed team, including anonymous callers.
+	RoleSpectator Role = "spectator"
+	// RoleOwner has claimed a team.
+	RoleOwner Role = "owner"
+	// RoleCommissioner is a member of the admin group and may run the draft.
+	RoleCommissioner Role = "commissioner"
+)
+
+var roleRank = map[Role]int{
+	RoleSpectator:    0,
+	RoleOwner:        1,
+	RoleCommissioner: 2,
+}
+
+// Includes reports whether r grants at least the privileges of required.
+func (r Role) Includes(required Role) bool {
+	return roleRank[r] >= roleRank[required]
+}
+
+// RoleForTeams derives user's role from the current teams.
+func RoleForTeams(user *User, teams []models.Team) Role {
+	if user == nil {
+		return RoleSpectator
+	}
+	if IsAdmin(user) {
+		return RoleCommissioner
+	}
+	for _, team := range teams {
+		if team.OwnerUserID != "" && team.OwnerUserID == user.ID {
+			return RoleOwner
+		}
+	}
+	return RoleSpectator
+}
+
+// CanPickFor reports whether user may pick for team: commissioners always,
+// owners only for the team they claimed.
+func CanPickFor(user *User, team models.Team) bool {
+	if user == nil {
+		return false
+	}
+	if IsAdmin(user) {
+		return true
+	}
+	return team.OwnerUserID != "" && team.OwnerUserID == user.ID
+}
+
+// OwnedTeams returns the teams user has claimed, in draft order. Unless
+// UNIQUE_OWNERS is on, one user may own several teams.
+func OwnedTeams(user *User, teams []models.Team) []models.Team {
+	if user == nil || user.ID == "" {
+		return nil
+	}
+	var owned []models.Team
+	for _, team := range teams {
+		if team.OwnerUserID == user.ID {
+			owned = append(owned, team)
+		}
+	}
+	return owned
+}
+
+// ResolveTeam picks the team a user acts as from the teams they own: the one
+// on the clock if any, otherwise the first in draft order. It returns nil
+// when owned is empty.
+func ResolveTeam(owned []models.Team, currentTeamID string) *models.Team {
+	if len(owned) == 0 {
+		return nil
+	}
+	for i := range owned {
+		if owned[i].ID == currentTeamID {
+			return &owned[i]
+		}
+	}
+	return &owned[0]
+}
+
+// ErrSystemMessageForbidden is returned by ChatMessageType when someone other
+// than a commissioner asks to post a system message.
+var ErrSystemMessageForbidden = errors.New("only a commissioner may post system messages")
+
+// ChatMessageType resolves the type of a chat message sent by user, "user"
+// when requested is empty. Only commissioners may post "system" messages;
+// a nil user (an unauthenticated transport) is left to the caller's gate.
+func ChatMessageType(user *User, requested string) (string, error) {
+	if requested == "" {
+		return "user", nil
+	}
+	if requested == "system" && user != nil && !IsAdmin(user) {
+		return "", ErrSystemMessageForbidden
+	}
+	return requested, nil
+}
+
+// ReactionUserID is who a reaction is recorded for: the signed-in user, so
+// nobody can react on someone else's behalf, or requested for anonymous callers.
+func ReactionUserID(user *User, requested string) string {
+	if user != nil {
+		return user.ID
+	}
+	return requested
+}
+
+// Roles resolves roles against the draft store.
+type Roles struct {
+	store dal.DraftDAL
+}
+
+// NewRoles creates a role resolver backed by store.
+func NewRoles(store dal.DraftDAL) *Roles {
+	return &Roles{store: store}
+}
+
+// RoleFor returns user's role. Only the owner check needs to load teams.
+func (r *Roles) RoleFor(user *User) (Role, error) {
+	if user == nil || IsAdmin(user) {
+		return RoleForTeams(user, nil), nil
+	}
+
+	state, err := r.store.GetState()
+	if err != nil {
+		return RoleSpectator, err
+	}
+	return RoleForTeams(user, state.Teams), nil
+}
+
+// Require rejects requests from anonymous callers (401) and from users whose
+// role does not include role (403). Use it behind OptionalMiddleware.
+func (r *Roles) Require(role Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		user := GetUser(req)
+		if user == nil {
+			http.Error(w, "Unauthorized: login required", http.StatusUnauthorized)
+			return
+		}
+
+		// Commissioner and spectator checks never need the team list.
+		got := RoleForTeams(user, nil)
+		if role == RoleOwner && !got.Includes(role) {
+			var err error
+			if got, err = r.RoleFor(user); err != nil {
+				http.Error(w, "Failed to resolve role", http.StatusInternalServerError)
+				return
+			}
+		}
+		if !got.Includes(role) {
+			http.Error(w, "Forbidden: "+string(role)+" role required", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	}
+}
+
+// Middleware is Require in the func(http.Handler) http.Handler convention.
+func (r *Roles) Middleware(role Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return r.Require(role, next.ServeHTTP)
+	}
+}
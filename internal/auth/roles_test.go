@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+func TestRoleForTeams(t *testing.T) {
+	t.Setenv("AUTH_ADMIN_CLAIM", "")
+	t.Setenv("AUTH_ADMIN_VALUE", "")
+
+	teams := []models.Team{{ID: "t1", Owner: "sam", OwnerUserID: "user-sam"}, {ID: "t2", Owner: "legacy"}}
+	tests := []struct {
+		name string
+		user *User
+		want Role
+	}{
+		{"anonymous", nil, RoleSpectator},
+		{"no team", &User{ID: "user-kim", Username: "kim"}, RoleSpectator},
+		{"legacy owner string only", &User{ID: "user-legacy", Username: "legacy"}, RoleSpectator},
+		{"claimed team", &User{ID: "user-sam", Username: "sam"}, RoleOwner},
+		{"admin group", &User{ID: "user-ash", Groups: []string{"admins"}}, RoleCommissioner},
+		{"admin without admin scope", &User{ID: "user-ash", Groups: []string{"admins"}, Scopes: []string{ScopeRead}}, RoleSpectator},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RoleForTeams(tt.user, teams); got != tt.want {
+				t.Fatalf("RoleForTeams() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveTeamWithSeveralOwnedTeams(t *testing.T) {
+	user := &User{ID: "user-sam"}
+	teams := []models.Team{
+		{ID: "t1", OwnerUserID: "user-kim"},
+		{ID: "t2", OwnerUserID: "user-sam"},
+		{ID: "t3"},
+		{ID: "t4", OwnerUserID: "user-sam"},
+	}
+
+	owned := OwnedTeams(user, teams)
+	if len(owned) != 2 || owned[0].ID != "t2" || owned[1].ID != "t4" {
+		t.Fatalf("OwnedTeams() = %+v, want t2 and t4 in draft order", owned)
+	}
+	for current, want := range map[string]string{"t4": "t4", "t2": "t2", "t1": "t2", "": "t2"} {
+		if got := ResolveTeam(owned, current); got == nil || got.ID != want {
+			t.Fatalf("ResolveTeam(current %q) = %+v, want %s", current, got, want)
+		}
+	}
+	if got := ResolveTeam(OwnedTeams(&User{ID: "user-ash"}, teams), "t1"); got != nil {
+		t.Fatalf("ResolveTeam() for a user without teams = %+v, want nil", got)
+	}
+}
+
+func TestCanPickFor(t *testing.T) {
+	t.Setenv("AUTH_ADMIN_CLAIM", "")
+	t.Setenv("AUTH_ADMIN_VALUE", "")
+
+	claimed := models.Team{ID: "t1", OwnerUserID: "user-sam"}
+	unclaimed := models.Team{ID: "t2"}
+
+	if !CanPickFor(&User{ID: "user-sam"}, claimed) {
+		t.Fatal("owner should pick for their team")
+	}
+	if CanPickFor(&User{ID: "user-kim"}, claimed) {
+		t.Fatal("other users should not pick for a claimed team")
+	}
+	if CanPickFor(&User{ID: "user-sam"}, unclaimed) {
+		t.Fatal("owners should not pick for an unclaimed team")
+	}
+	if !CanPickFor(&User{ID: "user-ash", Groups: []string{"admins"}}, unclaimed) {
+		t.Fatal("commissioners should pick for any team")
+	}
+	if CanPickFor(nil, claimed) {
+		t.Fatal("anonymous callers should not pick for a claimed team")
+	}
+}
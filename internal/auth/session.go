@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/ids"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"golang.org/x/oauth2"
+)
+
+// defaultSessionTTL applies when the provider's token carries no expiry (e.g. GitHub OAuth apps).
+const defaultSessionTTL = 24 * time.Hour
+
+// errSessionExpired marks a session cookie whose session has expired or been removed.
+var errSessionExpired = errors.New("session expired")
+
+// sessionManager manages the session cookie over a SessionStore and is shared by every provider.
+type sessionManager struct {
+	store SessionStore
+	audit *AuditLog
+	// secure marks cookies Secure; mock auth runs over plain HTTP in development.
+	secure bool
+	// refresh renews OAuth tokens; nil when the provider cannot refresh.
+	refresh tokenRefresher
+}
+
+// newSessionManager uses store, or an in-memory store when store is nil.
+// audit may be nil.
+func newSessionManager(store SessionStore, audit *AuditLog, secure bool) *sessionManager {
+	if store == nil {
+		store = NewMemorySessionStore()
+	}
+	return &sessionManager{
+		store:  store,
+		audit:  audit,
+		secure: secure,
+	}
+}
+
+// createSession stores a session for user and sets the session cookie. On
+// failure it writes a 503 response itself and returns nil.
+func (s *sessionManager) createSession(w http.ResponseWriter, r *http.Request, user *User, token *oauth2.Token) *Session {
+	expiresAt := time.Now().Add(defaultSessionTTL)
+	if token != nil && !token.Expiry.IsZero() {
+		expiresAt = token.Expiry
+	}
+
+	session := &Session{
+		ID:        ids.Token(),
+		User:      user,
+		Token:     token,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+		IdP:       idpSessionFromToken(token),
+	}
+
+	if err := s.store.Save(r.Context(), session); err != nil {
+		logger.Error("Failed to save session", "error", err)
+		http.Error(w, "Session store unavailable, please try again", http.StatusServiceUnavailable)
+		return nil
+	}
+
+	s.setSessionCookie(w, session)
+	s.audit.Record(r, EventLoginSuccess, user, "", "")
+	return session
+}
+
+func (s *sessionManager) setSessionCookie(w http.ResponseWriter, session *Session) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "session_id",
+		Value:    session.ID,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   s.secure,
+		SameSite: http.SameSiteLaxMode,
+		Expires:  session.ExpiresAt,
+	})
+}
+
+// destroySession deletes the request's session and clears the cookie.
+func (s *sessionManager) destroySession(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie("session_id"); err == nil {
+		if session, err := s.store.Get(r.Context(), cookie.Value); err == nil {
+			s.audit.Record(r, EventLogout, session.User, "", "")
+		}
+		if err := s.store.Delete(r.Context(), cookie.Value); err != nil {
+			logger.Warn("Failed to delete session", "error", err)
+		}
+	}
+
+	clearSessionCookie(w)
+}
+
+func clearSessionCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:   "session_id",
+		Value:  "",
+		Path:   "/",
+		MaxAge: -1,
+	})
+}
+
+// userFromRequest returns the session's user, or nil without a session cookie.
+// It returns errSessionExpired for a stale cookie and ErrInvalidSession for a
+// forged one; any other error means the store could not be reached.
+func (s *sessionManager) userFromRequest(r *http.Request) (*User, error) {
+	cookie, err := r.Cookie("session_id")
+	if err != nil {
+		return nil, nil
+	}
+
+	session, err := s.store.Get(r.Context(), cookie.Value)
+	if errors.Is(err, ErrSessionNotFound) {
+		return nil, errSessionExpired
+	}
+	if errors.Is(err, ErrInvalidSession) {
+		return nil, err
+	}
+	if err != nil {
+		logger.Error("Failed to load session", "error", err)
+		return nil, err
+	}
+	if session == nil || time.Now().After(session.ExpiresAt) {
+		return nil, errSessionExpired
+	}
+	return session.User, nil
+}
+
+// authenticate resolves the request's user for the middlewares. A stale
+// cookie is audited and cleared so it is only reported once. A forged cookie,
+// or a store that cannot be reached, fails closed: the request gets 401
+// rather than being treated as anonymous, and ok is false.
+func (s *sessionManager) authenticate(w http.ResponseWriter, r *http.Request) (user *User, ok bool) {
+	user, err := s.userFromRequest(r)
+	if errors.Is(err, errSessionExpired) {
+		s.audit.Record(r, EventSessionExpired, nil, "", "")
+		clearSessionCookie(w)
+		return nil, true
+	}
+	if errors.Is(err, ErrInvalidSession) {
+		s.audit.Record(r, EventSessionInvalid, nil, "", "")
+		clearSessionCookie(w)
+		http.Error(w, "Unauthorized: invalid session", http.StatusUnauthorized)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, "Unauthorized: session store unavailable", http.StatusUnauthorized)
+		return nil, false
+	}
+	return user, true
+}
+
+// Middleware protects routes requiring authentication
+func (s *sessionManager) Middleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := s.authenticate(w, r)
+		if !ok {
+			return
+		}
+		if user == nil {
+			http.Redirect(w, r, "/auth/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+			return
+		}
+
+		next.ServeHTTP(w, s.withSession(r, user))
+	}
+}
+
+// OptionalMiddleware attaches a user when a valid session exists, but allows anonymous reads.
+func (s *sessionManager) OptionalMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := s.authenticate(w, r)
+		if !ok {
+			return
+		}
+		if user != nil {
+			next.ServeHTTP(w, s.withSession(r, user))
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"golang.org/x/oauth2"
+)
+
+// SessionInfo describes the caller's login session.
+type SessionInfo struct {
+	ExpiresAt time.Time `json:"expiresAt"`
+	// ExpiresIn is the whole seconds left, never negative.
+	ExpiresIn int64 `json:"expiresIn"`
+	// Refreshable reports whether POST /auth/session/refresh can extend the session.
+	Refreshable bool `json:"refreshable"`
+}
+
+// tokenRefresher exchanges an OAuth refresh token for a new token.
+type tokenRefresher func(ctx context.Context, token *oauth2.Token) (*oauth2.Token, error)
+
+// oauthRefresher refreshes through config, always hitting the token endpoint.
+func oauthRefresher(config *oauth2.Config, httpClient *http.Client) tokenRefresher {
+	return func(ctx context.Context, token *oauth2.Token) (*oauth2.Token, error) {
+		// TokenSource hands back a still-valid token unchanged, so mark it expired.
+		stale := *token
+		stale.Expiry = time.Now().Add(-time.Minute)
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+		return config.TokenSource(ctx, &stale).Token()
+	}
+}
+
+// sessionKey stores a sessionRef in authenticated requests' contexts.
+type sessionKey struct{}
+
+// sessionRef lets handlers re-read the request's session, so they see refreshes.
+type sessionRef struct {
+	manager *sessionManager
+	id      string
+}
+
+func (s *sessionManager) withSession(r *http.Request, user *User) *http.Request {
+	ctx := WithUser(r.Context(), user)
+	if cookie, err := r.Cookie("session_id"); err == nil {
+		ctx = context.WithValue(ctx, sessionKey{}, sessionRef{manager: s, id: cookie.Value})
+	}
+	return r.WithContext(ctx)
+}
+
+// CurrentSession returns the live state of the session that authenticated r.
+// ok is false for anonymous and access-token requests and once the session is gone.
+func CurrentSession(r *http.Request) (info *SessionInfo, ok bool) {
+	ref, found := r.Context().Value(sessionKey{}).(sessionRef)
+	if !found {
+		return nil, false
+	}
+	session, err := ref.manager.store.Get(r.Context(), ref.id)
+	if err != nil || time.Now().After(session.ExpiresAt) {
+		return nil, false
+	}
+	return ref.manager.describe(session, time.Now()), true
+}
+
+// refreshable reports whether session can be extended: sessions without an
+// expiring token slide forward, OAuth sessions need a refresh token.
+func (s *sessionManager) refreshable(session *Session) bool {
+	if session.Token == nil || session.Token.Expiry.IsZero() {
+		return true
+	}
+	return session.Token.RefreshToken != "" && s.refresh != nil
+}
+
+// liveSession is sessionFromRequest, treating an expired session as missing.
+func (s *sessionManager) liveSession(r *http.Request) (*Session, error) {
+	session, err := s.sessionFromRequest(r)
+	if err == nil && time.Now().After(session.ExpiresAt) {
+		return nil, ErrNoSession
+	}
+	return session, err
+}
+
+func (s *sessionManager) describe(session *Session, now time.Time) *SessionInfo {
+	return &SessionInfo{
+		ExpiresAt:   session.ExpiresAt,
+		ExpiresIn:   max(int64(session.ExpiresAt.Sub(now)/time.Second), 0),
+		Refreshable: s.refreshable(session),
+	}
+}
+
+// SessionHandler reports the caller's session expiry (GET /auth/session).
+func (s *sessionManager) SessionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session, err := s.liveSession(r)
+	w.Header().Set("Content-Type", "application/json")
+	if errors.Is(err, ErrNoSession) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]bool{"authenticated": false})
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to load session", "error", err)
+		http.Error(w, "Session store unavailable, please try again", http.StatusServiceUnavailable)
+		return
+	}
+
+	json.NewEncoder(w).Encode(struct {
+		Authenticated bool `json:"authenticated"`
+		*SessionInfo
+	}{true, s.describe(session, time.Now())})
+}
+
+// RefreshSessionHandler extends the caller's session (POST /auth/session/refresh),
+// refreshing the OAuth token when the session has one.
+func (s *sessionManager) RefreshSessionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session, err := s.liveSession(r)
+	if errors.Is(err, ErrNoSession) {
+		http.Error(w, "Unauthorized: login required", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to load session", "error", err)
+		http.Error(w, "Session store unavailable, please try again", http.StatusServiceUnavailable)
+		return
+	}
+	if !s.refreshable(session) {
+		http.Error(w, "Session cannot be refreshed, please sign in again", http.StatusConflict)
+		return
+	}
+
+	updated := *session
+	if session.Token != nil && !session.Token.Expiry.IsZero() {
+		token, err := s.refresh(r.Context(), session.Token)
+		if err != nil {
+			logger.Warn("Failed to refresh token", "error", err)
+			http.Error(w, "Session cannot be refreshed, please sign in again", http.StatusUnauthorized)
+			return
+		}
+		updated.Token = token
+		updated.ExpiresAt = token.Expiry
+	} else {
+		updated.ExpiresAt = time.Now().Add(defaultSessionTTL)
+	}
+
+	if err := s.store.Save(r.Context(), &updated); err != nil {
+		logger.Error("Failed to save session", "error", err)
+		http.Error(w, "Session store unavailable, please try again", http.StatusServiceUnavailable)
+		return
+	}
+	s.setSessionCookie(w, &updated)
+	s.audit.Record(r, EventSessionRefreshed, updated.User, "", "")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.describe(&updated, time.Now()))
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"golang.org/x/oauth2"
+)
+
+func sessionRequest(method, path string, cookies []*http.Cookie) *http.Request {
+	request := httptest.NewRequest(method, path, nil)
+	for _, cookie := range cookies {
+		request.AddCookie(cookie)
+	}
+	return request
+}
+
+func TestSessionHandlerReportsExpiry(t *testing.T) {
+	provider, _ := NewMockAuth()
+
+	anonymous := httptest.NewRecorder()
+	provider.SessionHandler(anonymous, httptest.NewRequest(http.MethodGet, "/auth/session", nil))
+	if anonymous.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous status = %d, want %d", anonymous.Code, http.StatusUnauthorized)
+	}
+
+	login := httptest.NewRecorder()
+	provider.LoginHandler(login, httptest.NewRequest(http.MethodGet, "/auth/login", nil))
+	recorder := httptest.NewRecorder()
+	provider.SessionHandler(recorder, sessionRequest(http.MethodGet, "/auth/session", login.Result().Cookies()))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	var body struct {
+		Authenticated bool `json:"authenticated"`
+		SessionInfo
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if !body.Authenticated || !body.Refreshable || body.ExpiresIn < int64((defaultSessionTTL-time.Minute)/time.Second) {
+		t.Fatalf("session = %+v, want a refreshable session of about %s", body, defaultSessionTTL)
+	}
+}
+
+func TestRefreshSessionExtendsLocalSession(t *testing.T) {
+	events := dal.NewMemoryDAL()
+	provider := &MockAuth{sessionManager: newSessionManager(nil, NewAuditLog(events), false)}
+	login := httptest.NewRecorder()
+	provider.LoginHandler(login, httptest.NewRequest(http.MethodGet, "/auth/login", nil))
+	cookies := login.Result().Cookies()
+
+	// Bring the session close to expiry.
+	session, _ := provider.store.Get(context.Background(), cookies[0].Value)
+	expiring := *session
+	expiring.ExpiresAt = time.Now().Add(time.Minute)
+	provider.store.Save(context.Background(), &expiring)
+
+	var seen *SessionInfo
+	provider.Middleware(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = CurrentSession(r)
+	})(httptest.NewRecorder(), sessionRequest(http.MethodGet, "/draft", cookies))
+	if seen == nil || seen.ExpiresIn > 60 {
+		t.Fatalf("CurrentSession() = %+v, want about a minute left", seen)
+	}
+
+	recorder := httptest.NewRecorder()
+	provider.RefreshSessionHandler(recorder, sessionRequest(http.MethodPost, "/auth/session/refresh", cookies))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body)
+	}
+	refreshed, _ := provider.store.Get(context.Background(), cookies[0].Value)
+	if time.Until(refreshed.ExpiresAt) < defaultSessionTTL-time.Minute {
+		t.Fatalf("refreshed ExpiresAt = %s, want about %s away", refreshed.ExpiresAt, defaultSessionTTL)
+	}
+	if cookie := recorder.Result().Cookies(); len(cookie) != 1 || !cookie[0].Expires.Equal(refreshed.ExpiresAt.Truncate(time.Second)) {
+		t.Fatalf("cookies = %+v, want the session cookie with the new expiry", cookie)
+	}
+
+	audited, _ := events.ListAuthEvents(dal.AuthEventFilter{Type: EventSessionRefreshed})
+	if len(audited) != 1 {
+		t.Fatalf("refresh audit events = %d, want 1", len(audited))
+	}
+}
+
+func TestRefreshSessionUsesOAuthRefreshToken(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "refresh-1" {
+			http.Error(w, "bad grant", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"access-2","refresh_token":"refresh-2","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer tokenServer.Close()
+
+	manager := newSessionManager(nil, nil, false)
+	manager.refresh = oauthRefresher(&oauth2.Config{Endpoint: oauth2.Endpoint{TokenURL: tokenServer.URL}}, tokenServer.Client())
+
+	save := func(id string, token *oauth2.Token) []*http.Cookie {
+		manager.store.Save(context.Background(), &Session{ID: id, User: &User{ID: "user-1"}, Token: token, ExpiresAt: token.Expiry})
+		return []*http.Cookie{{Name: "session_id", Value: id}}
+	}
+	expiry := time.Now().Add(2 * time.Minute)
+
+	recorder := httptest.NewRecorder()
+	manager.RefreshSessionHandler(recorder, sessionRequest(http.MethodPost, "/auth/session/refresh",
+		save("with-refresh", &oauth2.Token{AccessToken: "access-1", RefreshToken: "refresh-1", Expiry: expiry})))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body)
+	}
+	session, _ := manager.store.Get(context.Background(), "with-refresh")
+	if session.Token.AccessToken != "access-2" || time.Until(session.ExpiresAt) < 50*time.Minute {
+		t.Fatalf("session = %+v, want the refreshed token's expiry", session)
+	}
+
+	recorder = httptest.NewRecorder()
+	manager.RefreshSessionHandler(recorder, sessionRequest(http.MethodPost, "/auth/session/refresh",
+		save("without-refresh", &oauth2.Token{AccessToken: "access-1", Expiry: expiry})))
+	if recorder.Code != http.StatusConflict {
+		t.Fatalf("status without refresh token = %d, want %d", recorder.Code, http.StatusConflict)
+	}
+}
@@ -0,0 +1,312 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrSessionNotFound is returned by a SessionStore for unknown or expired sessions.
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionStore keeps login sessions. Any other error from Get means the store
+// is unavailable; callers treat it as unauthenticated rather than trusting the cookie.
+type SessionStore interface {
+	Save(ctx context.Context, session *Session) error
+	Get(ctx context.Context, id string) (*Session, error)
+	Delete(ctx context.Context, id string) error
+	// DeleteIdP deletes the sessions matching every non-empty field of idp
+	// (see dal.IdPSession) and returns how many went.
+	DeleteIdP(ctx context.Context, idp dal.IdPSession) (int, error)
+}
+
+// memorySessionStore keeps sessions in process memory. Sessions are lost on
+// restart and are not shared between replicas.
+type memorySessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]*Session
+}
+
+// NewMemorySessionStore creates an in-process session store.
+func NewMemorySessionStore() SessionStore {
+	return &memorySessionStore{sessions: make(map[string]*Session)}
+}
+
+func (m *memorySessionStore) Save(ctx context.Context, session *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for id, existing := range m.sessions {
+		if now.After(existing.ExpiresAt) {
+			delete(m.sessions, id)
+		}
+	}
+	m.sessions[session.ID] = session
+	return nil
+}
+
+func (m *memorySessionStore) Get(ctx context.Context, id string) (*Session, error) {
+	m.mu.RLock()
+	session, ok := m.sessions[id]
+	m.mu.RUnlock()
+
+	if !ok || time.Now().After(session.ExpiresAt) {
+		return nil, ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func (m *memorySessionStore) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	delete(m.sessions, id)
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *memorySessionStore) DeleteIdP(ctx context.Context, idp dal.IdPSession) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := 0
+	for id, session := range m.sessions {
+		if idp.Matches(session.IdP) {
+			delete(m.sessions, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// dbSessionStore persists sessions through the draft database.
+type dbSessionStore struct {
+	store dal.SessionStore
+}
+
+// NewDBSessionStore creates a session store backed by the draft database.
+func NewDBSessionStore(store dal.SessionStore) SessionStore {
+	return &dbSessionStore{store: store}
+}
+
+func (d *dbSessionStore) Save(ctx context.Context, session *Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return d.store.SaveSession(session.ID, data, session.ExpiresAt.UnixMilli(), session.IdP)
+}
+
+func (d *dbSessionStore) Get(ctx context.Context, id string) (*Session, error) {
+	data, err := d.store.GetSession(id)
+	if errors.Is(err, dal.ErrSessionNotFound) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeSession(data)
+}
+
+func (d *dbSessionStore) Delete(ctx context.Context, id string) error {
+	return d.store.DeleteSession(id)
+}
+
+func (d *dbSessionStore) DeleteIdP(ctx context.Context, idp dal.IdPSession) (int, error) {
+	return d.store.DeleteIdPSessions(idp)
+}
+
+// redisSessionKeyPrefix namespaces session keys in a shared Redis.
+const redisSessionKeyPrefix = "jellycat:session:"
+
+// redisTimeout bounds each Redis round trip so a stalled server fails requests
+// quickly instead of hanging them.
+const redisTimeout = 2 * time.Second
+
+// redisIdPKeyPrefix prefixes sets of session IDs indexed by IdP subject
+// ("sub:<sub>") and session ("sid:<sid>") for back-channel logout.
+const redisIdPKeyPrefix = "jellycat:session-idp:"
+
+// redisSessionStore keeps sessions in Redis with a TTL matching their expiry.
+type redisSessionStore struct {
+	client *redis.Client
+}
+
+// NewRedisSessionStore connects to the Redis server at redisURL
+// (redis://[user:password@]host:port/db; pool options such as pool_size may
+// be set as query parameters) and verifies it is reachable.
+func NewRedisSessionStore(redisURL string) (SessionStore, error) {
+	options, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	if options.DialTimeout == 0 {
+		options.DialTimeout = redisTimeout
+	}
+	if options.ReadTimeout == 0 {
+		options.ReadTimeout = redisTimeout
+	}
+	if options.WriteTimeout == 0 {
+		options.WriteTimeout = redisTimeout
+	}
+
+	client := redis.NewClient(options)
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis at %s: %w", options.Addr, err)
+	}
+
+	return &redisSessionStore{client: client}, nil
+}
+
+func (s *redisSessionStore) Save(ctx context.Context, session *Session) error {
+	ttl := time.Until(session.ExpiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, redisTimeout)
+	defer cancel()
+	if err := s.client.Set(ctx, redisSessionKeyPrefix+session.ID, data, ttl).Err(); err != nil {
+		return err
+	}
+	return s.index(ctx, session, ttl)
+}
+
+// index adds the session to its IdP index sets, keeping each set alive as
+// long as its longest-lived session. Deleted sessions linger in the sets
+// until they expire; DeleteIdP skips them.
+func (s *redisSessionStore) index(ctx context.Context, session *Session, ttl time.Duration) error {
+	for _, key := range redisIdPKeys(session.IdP) {
+		pipe := s.client.TxPipeline()
+		pipe.SAdd(ctx, key, session.ID)
+		current := pipe.TTL(ctx, key)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+		if current.Val() < ttl {
+			if err := s.client.Expire(ctx, key, ttl).Err(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *redisSessionStore) DeleteIdP(ctx context.Context, idp dal.IdPSession) (int, error) {
+	keys := redisIdPKeys(idp)
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, redisTimeout)
+	defer cancel()
+
+	// The sid set is the narrower one when both are given
+	key := keys[len(keys)-1]
+	ids, err := s.client.SMembers(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, id := range ids {
+		session, err := s.Get(ctx, id)
+		if errors.Is(err, ErrSessionNotFound) {
+			s.client.SRem(ctx, key, id)
+			continue
+		}
+		if err != nil {
+			return deleted, err
+		}
+		if !idp.Matches(session.IdP) {
+			continue
+		}
+		if err := s.Delete(ctx, id); err != nil {
+			return deleted, err
+		}
+		s.client.SRem(ctx, key, id)
+		deleted++
+	}
+	return deleted, nil
+}
+
+// redisIdPKeys returns the index sets for idp: the subject's, then the sid's.
+func redisIdPKeys(idp dal.IdPSession) []string {
+	var keys []string
+	if idp.Subject != "" {
+		keys = append(keys, redisIdPKeyPrefix+"sub:"+idp.Subject)
+	}
+	if idp.SessionID != "" {
+		keys = append(keys, redisIdPKeyPrefix+"sid:"+idp.SessionID)
+	}
+	return keys
+}
+
+func (s *redisSessionStore) Get(ctx context.Context, id string) (*Session, error) {
+	ctx, cancel := context.WithTimeout(ctx, redisTimeout)
+	defer cancel()
+
+	data, err := s.client.Get(ctx, redisSessionKeyPrefix+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeSession(data)
+}
+
+func (s *redisSessionStore) Delete(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, redisTimeout)
+	defer cancel()
+	return s.client.Del(ctx, redisSessionKeyPrefix+id).Err()
+}
+
+func decodeSession(data []byte) (*Session, error) {
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("corrupt session: %w", err)
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+// NewSessionStore builds the store selected by kind ("memory", "db", "redis" or
+// "cookie"). db uses the draft database, which must implement dal.SessionStore;
+// cookie seals sessions with secrets, the SESSION_SECRET keys newest first.
+func NewSessionStore(kind string, store dal.DraftDAL, redisURL string, secrets ...string) (SessionStore, error) {
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "", "memory":
+		return NewMemorySessionStore(), nil
+	case "db":
+		sessions, ok := store.(dal.SessionStore)
+		if !ok {
+			return nil, fmt.Errorf("SESSION_STORE=db requires a database that can store sessions")
+		}
+		return NewDBSessionStore(sessions), nil
+	case "redis":
+		if redisURL == "" {
+			return nil, fmt.Errorf("REDIS_URL is required when SESSION_STORE=redis")
+		}
+		return NewRedisSessionStore(redisURL)
+	case "cookie":
+		return NewCookieSessionStore(secrets...)
+	default:
+		return nil, fmt.Errorf("unknown SESSION_STORE: %s (valid: memory, db, redis, cookie)", kind)
+	}
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"github.com/alicebob/miniredis/v2"
+)
+
+func newTestRedisSessionStore(t *testing.T) (SessionStore, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	store, err := NewRedisSessionStore("redis://" + server.Addr())
+	if err != nil {
+		t.Fatalf("NewRedisSessionStore() failed: %v", err)
+	}
+	return store, server
+}
+
+func assertSessionStoreRoundTrip(t *testing.T, store SessionStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	session := &Session{
+		ID:        "sess_1",
+		User:      &User{ID: "u1", Username: "taylor", Groups: []string{"admins"}},
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	if err := store.Save(ctx, session); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	found, err := store.Get(ctx, "sess_1")
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if found.User.ID != "u1" || found.User.Groups[0] != "admins" || found.User.Scopes != nil {
+		t.Fatalf("session user = %+v, want stored interactive user", found.User)
+	}
+
+	if err := store.Delete(ctx, "sess_1"); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	if _, err := store.Get(ctx, "sess_1"); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("Get() after delete error = %v, want %v", err, ErrSessionNotFound)
+	}
+}
+
+func TestMemorySessionStoreRoundTrip(t *testing.T) {
+	assertSessionStoreRoundTrip(t, NewMemorySessionStore())
+}
+
+func TestDBSessionStoreRoundTrip(t *testing.T) {
+	assertSessionStoreRoundTrip(t, NewDBSessionStore(dal.NewMemoryDAL()))
+}
+
+func TestRedisSessionStoreRoundTrip(t *testing.T) {
+	store, _ := newTestRedisSessionStore(t)
+	assertSessionStoreRoundTrip(t, store)
+}
+
+func TestRedisSessionStoreExpiresWithSession(t *testing.T) {
+	store, server := newTestRedisSessionStore(t)
+
+	session := &Session{ID: "sess_ttl", User: &User{ID: "u1"}, ExpiresAt: time.Now().Add(time.Minute)}
+	if err := store.Save(context.Background(), session); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	if ttl := server.TTL(redisSessionKeyPrefix + "sess_ttl"); ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("TTL = %v, want the session's remaining lifetime", ttl)
+	}
+
+	server.FastForward(2 * time.Minute)
+	if _, err := store.Get(context.Background(), "sess_ttl"); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("Get() after expiry error = %v, want %v", err, ErrSessionNotFound)
+	}
+}
+
+func TestSessionMiddlewareFailsClosedWhenRedisIsUnavailable(t *testing.T) {
+	logger.Init()
+	store, server := newTestRedisSessionStore(t)
+	manager := newSessionManager(store, nil, true)
+
+	login := httptest.NewRecorder()
+	if manager.createSession(login, httptest.NewRequest(http.MethodGet, "/", nil), &User{ID: "u1"}, nil) == nil {
+		t.Fatalf("createSession() failed with Redis up: %s", login.Body.String())
+	}
+	server.Close()
+
+	protected := func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler ran without a verified session")
+	}
+	for name, middleware := range map[string]func(http.HandlerFunc) http.HandlerFunc{
+		"Middleware":         manager.Middleware,
+		"OptionalMiddleware": manager.OptionalMiddleware,
+	} {
+		request := httptest.NewRequest(http.MethodGet, "/api/me", nil)
+		for _, cookie := range login.Result().Cookies() {
+			request.AddCookie(cookie)
+		}
+		recorder := httptest.NewRecorder()
+		middleware(protected)(recorder, request)
+		if recorder.Code != http.StatusUnauthorized {
+			t.Fatalf("%s status = %d, want %d", name, recorder.Code, http.StatusUnauthorized)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	if manager.createSession(recorder, httptest.NewRequest(http.MethodGet, "/", nil), &User{ID: "u2"}, nil) != nil {
+		t.Fatal("createSession() succeeded with Redis down")
+	}
+	if recorder.Code != http.StatusServiceUnavailable || len(recorder.Result().Cookies()) != 0 {
+		t.Fatalf("createSession() = %d with cookies %v, want 503 without a cookie", recorder.Code, recorder.Result().Cookies())
+	}
+}
+
+func TestNewSessionStoreSelection(t *testing.T) {
+	if _, err := NewSessionStore("db", dal.NewMemoryDAL(), ""); err != nil {
+		t.Fatalf("NewSessionStore(db) failed: %v", err)
+	}
+	if _, err := NewSessionStore("redis", dal.NewMemoryDAL(), ""); err == nil {
+		t.Fatal("NewSessionStore(redis) without REDIS_URL succeeded")
+	}
+	if _, err := NewSessionStore("files", dal.NewMemoryDAL(), ""); err == nil {
+		t.Fatal("NewSessionStore(files) succeeded")
+	}
+}
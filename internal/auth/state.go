@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/ids"
+)
+
+const (
+	stateCookieName = "oauth_state"
+	// stateTTL bounds how long a login may sit at the identity provider.
+	stateTTL = 5 * time.Minute
+	// maxPendingStates caps the nonces kept in the cookie; the oldest login is dropped first.
+	maxPendingStates = 5
+)
+
+var (
+	errInvalidState = errors.New("invalid state parameter")
+	errStateExpired = errors.New("login state expired")
+)
+
+// stateKey signs OAuth state. AUTH_STATE_SECRET lets several replicas accept
+// each other's callbacks; otherwise a per-process key is used, which only
+// invalidates logins in flight across a restart.
+var stateKey = sync.OnceValue(func() []byte {
+	if secret := os.Getenv("AUTH_STATE_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic("auth: failed to generate state key: " + err.Error())
+	}
+	return key
+})
+
+// oauthState is the signed payload carried through the identity provider.
+type oauthState struct {
+	Nonce     string `json:"n"`
+	ExpiresAt int64  `json:"e"`
+	Next      string `json:"r,omitempty"`
+}
+
+// setStateCookie starts a login: it returns a signed state carrying a fresh
+// nonce, an expiry and the ?next= redirect target, and adds the nonce to the
+// oauth_state cookie alongside any other tabs' pending logins.
+func setStateCookie(w http.ResponseWriter, r *http.Request) string {
+	state := oauthState{
+		Nonce:     ids.Token(),
+		ExpiresAt: time.Now().Add(stateTTL).Unix(),
+		Next:      safeRedirect(r.URL.Query().Get("next")),
+	}
+
+	nonces := append(pendingNonces(r), state.Nonce)
+	if len(nonces) > maxPendingStates {
+		nonces = nonces[len(nonces)-maxPendingStates:]
+	}
+	writeStateCookie(w, nonces)
+
+	return signState(state)
+}
+
+// verifyStateCookie checks the callback state's signature, expiry and nonce,
+// consumes the nonce and returns the post-login redirect target. It writes the
+// error response itself and returns false on failure.
+func verifyStateCookie(w http.ResponseWriter, r *http.Request) (string, bool) {
+	nonces := pendingNonces(r)
+	if len(nonces) == 0 {
+		http.Error(w, "Missing state cookie", http.StatusBadRequest)
+		return "", false
+	}
+
+	state, err := parseState(r.URL.Query().Get("state"))
+	if errors.Is(err, errStateExpired) {
+		http.Error(w, "Login expired, please try again", http.StatusBadRequest)
+		return "", false
+	}
+	if err != nil {
+		http.Error(w, "Invalid state parameter", http.StatusBadRequest)
+		return "", false
+	}
+
+	match := -1
+	for i, nonce := range nonces {
+		if subtle.ConstantTimeCompare([]byte(nonce), []byte(state.Nonce)) == 1 {
+			match = i
+		}
+	}
+	if match < 0 {
+		http.Error(w, "Invalid state parameter", http.StatusBadRequest)
+		return "", false
+	}
+
+	writeStateCookie(w, append(nonces[:match], nonces[match+1:]...))
+
+	if state.Next == "" {
+		return "/", true
+	}
+	return state.Next, true
+}
+
+func signState(state oauthState) string {
+	payload, _ := json.Marshal(state)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(stateMAC(encoded))
+}
+
+func parseState(value string) (oauthState, error) {
+	var state oauthState
+
+	encoded, signature, ok := strings.Cut(value, ".")
+	if !ok {
+		return state, errInvalidState
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, stateMAC(encoded)) {
+		return state, errInvalidState
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || json.Unmarshal(payload, &state) != nil {
+		return state, errInvalidState
+	}
+	if time.Now().Unix() > state.ExpiresAt {
+		return state, errStateExpired
+	}
+	return state, nil
+}
+
+func stateMAC(encoded string) []byte {
+	mac := hmac.New(sha256.New, stateKey())
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+func pendingNonces(r *http.Request) []string {
+	cookie, err := r.Cookie(stateCookieName)
+	if err != nil || cookie.Value == "" {
+		return nil
+	}
+	return strings.Split(cookie.Value, ".")
+}
+
+func writeStateCookie(w http.ResponseWriter, nonces []string) {
+	cookie := &http.Cookie{
+		Name:     stateCookieName,
+		Value:    strings.Join(nonces, "."),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(stateTTL / time.Second),
+	}
+	if len(nonces) == 0 {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(w, cookie)
+}
+
+// safeRedirect only allows same-origin absolute paths, so the login flow can't
+// be used as an open redirect.
+func safeRedirect(target string) string {
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") || strings.HasPrefix(target, "/\\") {
+		return ""
+	}
+	return target
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// beginLogin runs setStateCookie as a login handler would, carrying over the
+// browser's existing state cookie, and returns the signed state and new cookie.
+func beginLogin(t *testing.T, target string, existing *http.Cookie) (string, *http.Cookie) {
+	t.Helper()
+
+	request := httptest.NewRequest(http.MethodGet, "/auth/login?next="+url.QueryEscape(target), nil)
+	if existing != nil {
+		request.AddCookie(existing)
+	}
+	recorder := httptest.NewRecorder()
+	state := setStateCookie(recorder, request)
+
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.Name == stateCookieName {
+			return state, cookie
+		}
+	}
+	t.Fatal("login did not set the state cookie")
+	return "", nil
+}
+
+func finishLogin(state string, cookie *http.Cookie) (*httptest.ResponseRecorder, string, bool) {
+	request := httptest.NewRequest(http.MethodGet, "/auth/callback?code=abc&state="+url.QueryEscape(state), nil)
+	if cookie != nil {
+		request.AddCookie(cookie)
+	}
+	recorder := httptest.NewRecorder()
+	next, ok := verifyStateCookie(recorder, request)
+	return recorder, next, ok
+}
+
+func TestOAuthStateSupportsConcurrentLogins(t *testing.T) {
+	firstState, cookie := beginLogin(t, "/draft", nil)
+	secondState, cookie := beginLogin(t, "/admin", cookie)
+
+	// The second tab's callback lands first; the first tab must still succeed.
+	recorder, next, ok := finishLogin(secondState, cookie)
+	if !ok || next != "/admin" {
+		t.Fatalf("second callback = %q, %v; want /admin: %s", next, ok, recorder.Body.String())
+	}
+	remaining := recorder.Result().Cookies()[0]
+
+	if _, next, ok := finishLogin(firstState, remaining); !ok || next != "/draft" {
+		t.Fatalf("first callback = %q, %v; want /draft", next, ok)
+	}
+	if _, _, ok := finishLogin(secondState, remaining); ok {
+		t.Fatal("a consumed state should not verify twice")
+	}
+}
+
+func TestOAuthStateRejectsTamperingAndExpiry(t *testing.T) {
+	state, cookie := beginLogin(t, "/draft", nil)
+
+	if _, _, ok := finishLogin(state+"x", cookie); ok {
+		t.Fatal("tampered state should be rejected")
+	}
+	if _, _, ok := finishLogin(state, nil); ok {
+		t.Fatal("state without its cookie should be rejected")
+	}
+
+	expired := signState(oauthState{Nonce: cookie.Value, ExpiresAt: time.Now().Add(-time.Second).Unix()})
+	recorder, _, ok := finishLogin(expired, cookie)
+	if ok || recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expired state = %v (status %d), want rejection", ok, recorder.Code)
+	}
+}
+
+func TestOAuthStateIgnoresOffsiteRedirects(t *testing.T) {
+	for _, target := range []string{"https://evil.example", "//evil.example", "/\\evil.example"} {
+		state, cookie := beginLogin(t, target, nil)
+		if _, next, ok := finishLogin(state, cookie); !ok || next != "/" {
+			t.Fatalf("redirect for %q = %q, %v; want /", target, next, ok)
+		}
+	}
+}
+
+func TestOAuthStateKeepsRecentLoginsOnly(t *testing.T) {
+	var cookie *http.Cookie
+	var states []string
+	for i := 0; i < maxPendingStates+1; i++ {
+		var state string
+		state, cookie = beginLogin(t, "/", cookie)
+		states = append(states, state)
+	}
+
+	if _, _, ok := finishLogin(states[0], cookie); ok {
+		t.Fatal("the oldest login should have been evicted")
+	}
+	if _, _, ok := finishLogin(states[len(states)-1], cookie); !ok {
+		t.Fatal("the newest login should verify")
+	}
+}
@@ -0,0 +1,223 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/ids"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// Personal access token scopes
+const (
+	ScopeRead  = "read"
+	ScopeChat  = "chat"
+	ScopeDraft = "draft"
+	ScopeAdmin = "admin"
+)
+
+// accessTokenPrefix makes leaked tokens easy to recognise in logs and secret scanners.
+const accessTokenPrefix = "jcd_"
+
+// touchInterval limits last-used writes to one per token per minute.
+const touchInterval = time.Minute
+
+// ErrInvalidToken is returned for unknown or malformed bearer tokens.
+var ErrInvalidToken = errors.New("invalid access token")
+
+// HasScope reports whether the user may act with scope. Interactive sessions have every scope.
+func (u *User) HasScope(scope string) bool {
+	if u == nil {
+		return false
+	}
+	if u.Scopes == nil {
+		return true
+	}
+	for _, granted := range u.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeScopes validates requested scopes and removes duplicates.
+func NormalizeScopes(scopes []string) ([]string, error) {
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		switch scope {
+		case ScopeRead, ScopeChat, ScopeDraft, ScopeAdmin:
+		default:
+			return nil, fmt.Errorf("unknown scope: %q (valid: read, chat, draft, admin)", scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+	return normalized, nil
+}
+
+// HashAccessToken returns the stored form of a token secret.
+func HashAccessToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// TokenAuth issues and resolves personal access tokens.
+type TokenAuth struct {
+	store dal.TokenStore
+	now   func() time.Time
+}
+
+// NewTokenAuth creates a token authenticator backed by store.
+func NewTokenAuth(store dal.TokenStore) *TokenAuth {
+	return &TokenAuth{store: store, now: time.Now}
+}
+
+// Store returns the underlying token store.
+func (t *TokenAuth) Store() dal.TokenStore {
+	return t.store
+}
+
+// Create issues a token for owner and returns the stored record with the one-time plaintext secret.
+// Requesting the admin scope requires the owner to be an admin.
+func (t *TokenAuth) Create(owner *User, name string, scopes []string) (*models.AccessToken, string, error) {
+	scopes, err := NormalizeScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, scope := range scopes {
+		if scope == ScopeAdmin && !IsAdmin(owner) {
+			return nil, "", fmt.Errorf("admin scope requires an admin user")
+		}
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "Personal access token"
+	}
+
+	secret := accessTokenPrefix + ids.Token()
+	token := &models.AccessToken{
+		ID:            ids.New("tok"),
+		Name:          name,
+		OwnerID:       owner.ID,
+		OwnerUsername: owner.Username,
+		OwnerName:     owner.Name,
+		OwnerEmail:    owner.Email,
+		OwnerGroups:   append([]string(nil), owner.Groups...),
+		Scopes:        scopes,
+		TokenHash:     HashAccessToken(secret),
+		CreatedAt:     t.now().UnixMilli(),
+	}
+
+	if err := t.store.CreateAccessToken(token); err != nil {
+		return nil, "", err
+	}
+	return token, secret, nil
+}
+
+// Authenticate resolves a bearer secret to its owning user, limited to the token's scopes.
+func (t *TokenAuth) Authenticate(secret string) (*User, error) {
+	if !strings.HasPrefix(secret, accessTokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+
+	token, err := t.store.GetAccessTokenByHash(HashAccessToken(secret))
+	if errors.Is(err, dal.ErrTokenNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := t.now()
+	if now.Sub(time.UnixMilli(token.LastUsedAt)) >= touchInterval {
+		if err := t.store.TouchAccessToken(token.ID, now.UnixMilli()); err != nil {
+			logger.Warn("Failed to record access token use", "error", err, "token_id", token.ID)
+		}
+	}
+
+	return &User{
+		ID:       token.OwnerID,
+		Email:    token.OwnerEmail,
+		Name:     token.OwnerName,
+		Username: token.OwnerUsername,
+		Groups:   append([]string(nil), token.OwnerGroups...),
+		Scopes:   append([]string{}, token.Scopes...),
+	}, nil
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer ..." header value.
+func BearerToken(header string) string {
+	scheme, value, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(value)
+}
+
+// WithAccessTokens wraps provider so requests carrying a bearer token authenticate with it.
+// Browser sessions continue to work unchanged.
+func WithAccessTokens(provider AuthProvider, tokens *TokenAuth) AuthProvider {
+	return &tokenProvider{AuthProvider: provider, tokens: tokens}
+}
+
+type tokenProvider struct {
+	AuthProvider
+	tokens *TokenAuth
+}
+
+func (p *tokenProvider) Middleware(next http.HandlerFunc) http.HandlerFunc {
+	sessionHandler := p.AuthProvider.Middleware(next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !p.serveWithToken(w, r, next) {
+			sessionHandler(w, r)
+		}
+	}
+}
+
+func (p *tokenProvider) OptionalMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	sessionHandler := p.AuthProvider.OptionalMiddleware(next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !p.serveWithToken(w, r, next) {
+			sessionHandler(w, r)
+		}
+	}
+}
+
+// serveWithToken handles the request when it carries a bearer token and reports whether it did.
+func (p *tokenProvider) serveWithToken(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) bool {
+	secret := BearerToken(r.Header.Get("Authorization"))
+	if secret == "" {
+		return false
+	}
+
+	user, err := p.tokens.Authenticate(secret)
+	if err != nil {
+		http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+		return true
+	}
+
+	if (r.Method == http.MethodGet || r.Method == http.MethodHead) && !user.HasScope(ScopeRead) {
+		http.Error(w, "Forbidden: token lacks read scope", http.StatusForbidden)
+		return true
+	}
+
+	ctx := WithUser(r.Context(), user)
+	next.ServeHTTP(w, r.WithContext(ctx))
+	return true
+}
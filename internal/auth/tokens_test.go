@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+)
+
+func newTestTokenAuth(t *testing.T) (*TokenAuth, *dal.MemoryDAL) {
+	t.Helper()
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("AUTH_ADMIN_CLAIM", "")
+	t.Setenv("AUTH_ADMIN_VALUE", "")
+
+	store := dal.NewMemoryDAL()
+	return NewTokenAuth(store), store
+}
+
+func TestAccessTokenResolvesOwnerWithScopes(t *testing.T) {
+	tokens, store := newTestTokenAuth(t)
+	owner := &User{ID: "u1", Username: "commish", Groups: []string{"admins"}}
+
+	record, secret, err := tokens.Create(owner, "cron", []string{"read", "read"})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if record.TokenHash == secret || record.TokenHash != HashAccessToken(secret) {
+		t.Fatal("expected only the hash of the secret to be stored")
+	}
+
+	user, err := tokens.Authenticate(secret)
+	if err != nil {
+		t.Fatalf("Authenticate() failed: %v", err)
+	}
+	if user.Username != "commish" || !user.HasScope(ScopeRead) || user.HasScope(ScopeChat) {
+		t.Fatalf("user = %+v, want commish with read scope only", user)
+	}
+	if IsAdmin(user) {
+		t.Fatal("read-only token should not grant admin even for an admin owner")
+	}
+
+	listed, _ := store.ListAccessTokens("u1")
+	if len(listed) != 1 || listed[0].LastUsedAt == 0 {
+		t.Fatalf("tokens = %+v, want last-used recorded", listed)
+	}
+}
+
+func TestAccessTokenAdminScopeRequiresAdminOwner(t *testing.T) {
+	tokens, _ := newTestTokenAuth(t)
+
+	if _, _, err := tokens.Create(&User{ID: "u2", Groups: []string{"users"}}, "sneaky", []string{"admin"}); err == nil {
+		t.Fatal("expected admin scope to be rejected for a non-admin")
+	}
+
+	_, secret, err := tokens.Create(&User{ID: "u1", Groups: []string{"admins"}}, "ops", []string{"admin"})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	user, err := tokens.Authenticate(secret)
+	if err != nil {
+		t.Fatalf("Authenticate() failed: %v", err)
+	}
+	if !IsAdmin(user) {
+		t.Fatal("expected admin-scoped token for an admin to be an admin")
+	}
+}
+
+func TestAccessTokenLastUsedIsThrottled(t *testing.T) {
+	tokens, store := newTestTokenAuth(t)
+	now := time.UnixMilli(1700000000000)
+	tokens.now = func() time.Time { return now }
+
+	_, secret, _ := tokens.Create(&User{ID: "u1"}, "cron", []string{"read"})
+	tokens.Authenticate(secret)
+
+	now = now.Add(10 * time.Second)
+	tokens.Authenticate(secret)
+
+	listed, _ := store.ListAccessTokens("u1")
+	if listed[0].LastUsedAt != 1700000000000 {
+		t.Fatalf("LastUsedAt = %d, want first use only", listed[0].LastUsedAt)
+	}
+}
+
+func TestWithAccessTokensMiddleware(t *testing.T) {
+	tokens, _ := newTestTokenAuth(t)
+	mock, _ := NewMockAuth()
+	provider := WithAccessTokens(mock, tokens)
+	_, chatOnly, _ := tokens.Create(&User{ID: "u1"}, "bot", []string{"chat"})
+
+	var seen *User
+	handler := provider.OptionalMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		seen = GetUser(r)
+	})
+
+	cases := []struct {
+		name   string
+		method string
+		header string
+		want   int
+	}{
+		{"invalid token", http.MethodPost, "Bearer jcd_nope", http.StatusUnauthorized},
+		{"missing read scope", http.MethodGet, "Bearer " + chatOnly, http.StatusForbidden},
+		{"chat post", http.MethodPost, "Bearer " + chatOnly, http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			seen = nil
+			request := httptest.NewRequest(tc.method, "/api/chat/send", nil)
+			request.Header.Set("Authorization", tc.header)
+			recorder := httptest.NewRecorder()
+			handler(recorder, request)
+
+			if recorder.Code != tc.want {
+				t.Fatalf("status = %d, want %d", recorder.Code, tc.want)
+			}
+			if tc.want == http.StatusOK && (seen == nil || seen.ID != "u1") {
+				t.Fatalf("user = %+v, want token owner", seen)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package clickhouse
+
+import "sort"
+
+// PointsChange is how far a sync moved one player's points.
+type PointsChange struct {
+	PlayerID string `json:"id"`
+	Delta    int    `json:"delta"`
+}
+
+// changeTracker wraps a sync's update function and notes the players whose
+// points end up different from before.
+type changeTracker struct {
+	before map[string]int
+	after  map[string]int
+}
+
+func newChangeTracker(before map[string]int) *changeTracker {
+	return &changeTracker{before: before, after: make(map[string]int)}
+}
+
+func (t *changeTracker) wrap(update func(playerID string, points int) error) func(playerID string, points int) error {
+	return func(playerID string, points int) error {
+		if err := update(playerID, points); err != nil {
+			return err
+		}
+		t.after[playerID] = points
+		return nil
+	}
+}
+
+// changes lists the players whose points moved, biggest moves first.
+func (t *changeTracker) changes() []PointsChange {
+	var changes []PointsChange
+	for id, points := range t.after {
+		if delta := points - t.before[id]; delta != 0 {
+			changes = append(changes, PointsChange{PlayerID: id, Delta: delta})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := abs(changes[i].Delta), abs(changes[j].Delta)
+		if a != b {
+			return a > b
+		}
+		return changes[i].PlayerID < changes[j].PlayerID
+	})
+	return changes
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -2,58 +2,141 @@ package clickhouse
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
 )
 
+// PointsSource is what the server reads from ClickHouse. Client and
+// mocks.MockClickHouseClient implement it, so tests can swap in a fake by
+// embedding the mock and overriding a method.
+type PointsSource interface {
+	GetCuddlePoints(ctx context.Context, jellycatID string) (int, error)
+	GetAllCuddlePoints(ctx context.Context) (map[string]int, error)
+	MetricsSource
+	Health(ctx context.Context) (time.Duration, error)
+	Close() error
+}
+
+// CuddlePointsClient is a PointsSource the Syncer can copy cuddle points
+// from.
+type CuddlePointsClient interface {
+	PointsSource
+	SyncCuddlePoints(ctx context.Context, updateFunc func(playerID string, points int) error) (SyncResult, error)
+}
+
+// ErrUnknownPlayer is what a sync's update function returns, possibly
+// wrapped, for a Jellycat the draft doesn't have. ClickHouse keeps retired
+// Jellycats, so these are counted as skipped rather than failed.
+var ErrUnknownPlayer = errors.New("unknown player")
+
+// SyncResult summarizes a sync run that reached ClickHouse. Errors lists the
+// players whose update failed; the rest of the run carried on without them.
+type SyncResult struct {
+	Updated int      `json:"updated"`
+	Skipped int      `json:"skipped"`
+	Errors  []string `json:"errors"`
+}
+
+// ApplyCuddlePoints calls updateFunc for each player in points, in ID order,
+// carrying on past failures. Players it returns ErrUnknownPlayer or
+// ErrManualPoints for are counted as skipped.
+func ApplyCuddlePoints(points map[string]int, updateFunc func(playerID string, points int) error) SyncResult {
+	ids := make([]string, 0, len(points))
+	for id := range points {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	result := SyncResult{Errors: []string{}}
+	for _, id := range ids {
+		err := updateFunc(id, points[id])
+		switch {
+		case err == nil:
+			result.Updated++
+		case errors.Is(err, ErrUnknownPlayer), errors.Is(err, ErrManualPoints):
+			result.Skipped++
+		default:
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", id, err))
+		}
+	}
+	return result
+}
+
+var _ CuddlePointsClient = (*Client)(nil)
+
+const defaultQueryTimeout = 10 * time.Second
+
+// QueryTimeout bounds each ClickHouse query, on top of any deadline the
+// caller's context already has. It is read from CLICKHOUSE_QUERY_TIMEOUT (a
+// Go duration such as "5s").
+func QueryTimeout() time.Duration {
+	timeout, err := time.ParseDuration(strings.TrimSpace(os.Getenv("CLICKHOUSE_QUERY_TIMEOUT")))
+	if err != nil || timeout <= 0 {
+		return defaultQueryTimeout
+	}
+	return timeout
+}
+
 // Client provides ClickHouse integration for cuddle points
 type Client struct {
-	conn driver.Conn
+	conn     driver.Conn
+	database string
+	formula  Formula
 }
 
-// NewClient creates a new ClickHouse client
-func NewClient(addr, database, username, password string) (*Client, error) {
-	conn, err := clickhouse.Open(&clickhouse.Options{
-		Addr: []string{addr},
-		Auth: clickhouse.Auth{
-			Database: database,
-			Username: username,
-			Password: password,
-		},
-	})
-
+// NewClient connects to ClickHouse and creates the jellycat_interactions
+// table in database if it doesn't exist yet. Cuddle points are scored with
+// formula. TLS, compression and pool settings come from the environment; see
+// connOptions.
+func NewClient(addr, database, username, password string, formula Formula) (*Client, error) {
+	options, err := connOptions(addr, database, username, password)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := clickhouse.Open(options)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to ClickHouse: %w", err)
 	}
 
-	if err := conn.Ping(context.Background()); err != nil {
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout())
+	defer cancel()
+	if err := conn.Ping(ctx); err != nil {
 		conn.Close()
-		return nil, fmt.Errorf("failed to ping ClickHouse: %w", err)
+		return nil, fmt.Errorf("failed to ping ClickHouse: %w", classifyConnError(err))
 	}
 
-	return &Client{conn: conn}, nil
+	client := &Client{conn: conn, database: database, formula: formula}
+	if err := client.EnsureSchema(context.Background()); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return client, nil
 }
 
-// GetCuddlePoints retrieves cuddle points for a Jellycat from ClickHouse
-// This queries aggregated metrics to calculate cuddle points
-func (c *Client) GetCuddlePoints(jellycatID string) (int, error) {
+// GetCuddlePoints scores a Jellycat's recent interactions with the client's
+// formula.
+func (c *Client) GetCuddlePoints(ctx context.Context, jellycatID string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout())
+	defer cancel()
+
 	var points int
 
 	query := `
-		SELECT 
-			toInt32(
-				countDistinct(user_id) * 10 +  -- Unique users who interacted
-				count() / 10 +                   -- Total interactions
-				sum(duration) / 60               -- Time spent (seconds to minutes)
-			) as cuddle_points
+		SELECT ` + scoreExpr(3) + ` AS cuddle_points
 		FROM jellycat_interactions
 		WHERE jellycat_id = $1
-		AND timestamp >= now() - INTERVAL 30 DAY
+		AND timestamp >= now() - toIntervalDay($2)
 	`
 
-	row := c.conn.QueryRow(context.Background(), query, jellycatID)
+	args := append([]any{jellycatID, c.formula.WindowDays}, c.formula.weights()...)
+	row := c.conn.QueryRow(ctx, query, args...)
 	if err := row.Scan(&points); err != nil {
 		return 0, err
 	}
@@ -62,23 +145,23 @@ func (c *Client) GetCuddlePoints(jellycatID string) (int, error) {
 }
 
 // GetAllCuddlePoints retrieves cuddle points for all Jellycats
-func (c *Client) GetAllCuddlePoints() (map[string]int, error) {
+func (c *Client) GetAllCuddlePoints(ctx context.Context) (map[string]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout())
+	defer cancel()
+
 	points := make(map[string]int)
 
 	query := `
-		SELECT 
+		SELECT
 			jellycat_id,
-			toInt32(
-				countDistinct(user_id) * 10 +
-				count() / 10 +
-				sum(duration) / 60
-			) as cuddle_points
+			` + scoreExpr(2) + ` AS cuddle_points
 		FROM jellycat_interactions
-		WHERE timestamp >= now() - INTERVAL 30 DAY
+		WHERE timestamp >= now() - toIntervalDay($1)
 		GROUP BY jellycat_id
 	`
 
-	rows, err := c.conn.Query(context.Background(), query)
+	args := append([]any{c.formula.WindowDays}, c.formula.weights()...)
+	rows, err := c.conn.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -98,19 +181,28 @@ func (c *Client) GetAllCuddlePoints() (map[string]int, error) {
 
 // SyncCuddlePoints updates player cuddle points from ClickHouse
 // This should be called periodically to keep points up-to-date
-func (c *Client) SyncCuddlePoints(updateFunc func(playerID string, points int) error) error {
-	allPoints, err := c.GetAllCuddlePoints()
+func (c *Client) SyncCuddlePoints(ctx context.Context, updateFunc func(playerID string, points int) error) (SyncResult, error) {
+	allPoints, err := c.GetAllCuddlePoints(ctx)
 	if err != nil {
-		return err
+		return SyncResult{}, err
 	}
+	return ApplyCuddlePoints(allPoints, updateFunc), nil
+}
 
-	for playerID, points := range allPoints {
-		if err := updateFunc(playerID, points); err != nil {
-			return fmt.Errorf("failed to update points for %s: %w", playerID, err)
-		}
+// Health pings ClickHouse and reports the round trip. Unlike the cuddle
+// points queries it reads no tables, so it is cheap enough for every health
+// probe.
+func (c *Client) Health(ctx context.Context) (time.Duration, error) {
+	started := time.Now()
+	if err := c.conn.Ping(ctx); err != nil {
+		return 0, classifyConnError(err)
 	}
+	return time.Since(started), nil
+}
 
-	return nil
+// Formula returns the formula the client scores cuddle points with.
+func (c *Client) Formula() Formula {
+	return c.formula
 }
 
 // Close closes the ClickHouse connection
@@ -0,0 +1,91 @@
+package clickhouse
+
+import (
+	"context"
+	"sort"
+)
+
+// TeamEngagement totals the interactions with a team's roster. CuddlePoints
+// sums each Jellycat's formula score.
+type TeamEngagement struct {
+	Interactions int `json:"interactions"`
+	UniqueUsers  int `json:"uniqueUsers"`
+	CuddlePoints int `json:"cuddlePoints"`
+}
+
+// EngagementSource totals interactions per team over the last days days,
+// given each team's Jellycat IDs. Client and mocks.MockClickHouseClient
+// implement it.
+type EngagementSource interface {
+	GetTeamEngagement(ctx context.Context, rosters map[string][]string, days int) (map[string]TeamEngagement, error)
+}
+
+var _ EngagementSource = (*Client)(nil)
+
+// GetTeamEngagement runs one grouped query for every roster: interactions
+// are mapped to their team in ClickHouse, so a user who cuddled several of a
+// team's Jellycats counts once. Teams without interactions are left out.
+func (c *Client) GetTeamEngagement(ctx context.Context, rosters map[string][]string, days int) (map[string]TeamEngagement, error) {
+	jellycatIDs, teamIDs := rosterColumns(rosters)
+	engagement := make(map[string]TeamEngagement)
+	if len(jellycatIDs) == 0 {
+		return engagement, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout())
+	defer cancel()
+
+	query := `
+		SELECT
+			team_id,
+			sum(interactions),
+			length(groupUniqArrayArray(users)),
+			sum(cuddle_points)
+		FROM (
+			SELECT
+				transform(jellycat_id, $1, $2, '') AS team_id,
+				count() AS interactions,
+				groupUniqArray(user_id) AS users,
+				` + scoreExpr(4) + ` AS cuddle_points
+			FROM jellycat_interactions
+			WHERE jellycat_id IN $1
+			AND timestamp >= now() - toIntervalDay($3)
+			GROUP BY jellycat_id
+		)
+		GROUP BY team_id
+	`
+	args := append([]any{jellycatIDs, teamIDs, clampHistoryDays(days)}, c.formula.weights()...)
+	rows, err := c.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var teamID string
+		var interactions, users uint64
+		var points int64
+		if err := rows.Scan(&teamID, &interactions, &users, &points); err != nil {
+			return nil, err
+		}
+		engagement[teamID] = TeamEngagement{Interactions: int(interactions), UniqueUsers: int(users), CuddlePoints: int(points)}
+	}
+	return engagement, rows.Err()
+}
+
+// rosterColumns flattens rosters into parallel Jellycat and team ID lists,
+// in a stable order.
+func rosterColumns(rosters map[string][]string) (jellycatIDs, teamIDs []string) {
+	teams := make([]string, 0, len(rosters))
+	for teamID := range rosters {
+		teams = append(teams, teamID)
+	}
+	sort.Strings(teams)
+	for _, teamID := range teams {
+		for _, jellycatID := range rosters[teamID] {
+			jellycatIDs = append(jellycatIDs, jellycatID)
+			teamIDs = append(teamIDs, teamID)
+		}
+	}
+	return jellycatIDs, teamIDs
+}
@@ -0,0 +1,76 @@
+package clickhouse
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formula scores a Jellycat's interactions over the last WindowDays days:
+//
+//	uniqueUsers*UniqueUserWeight + interactions*InteractionWeight + minutes*MinuteWeight
+//
+// truncated to a whole number.
+type Formula struct {
+	WindowDays        int     `json:"windowDays"`
+	UniqueUserWeight  float64 `json:"uniqueUserWeight"`
+	InteractionWeight float64 `json:"interactionWeight"`
+	MinuteWeight      float64 `json:"minuteWeight"`
+}
+
+// DefaultFormula is 10 points per unique user, one per ten interactions and
+// one per minute of play, over 30 days.
+func DefaultFormula() Formula {
+	return Formula{WindowDays: 30, UniqueUserWeight: 10, InteractionWeight: 0.1, MinuteWeight: 1}
+}
+
+// FormulaFromEnv overrides DefaultFormula with CUDDLE_WINDOW_DAYS,
+// CUDDLE_WEIGHT_USERS, CUDDLE_WEIGHT_INTERACTIONS and CUDDLE_WEIGHT_MINUTES.
+// Unset or invalid values, including negative weights, keep the defaults.
+func FormulaFromEnv() Formula {
+	formula := DefaultFormula()
+	formula.WindowDays = envInt("CUDDLE_WINDOW_DAYS", formula.WindowDays)
+	formula.UniqueUserWeight = envWeight("CUDDLE_WEIGHT_USERS", formula.UniqueUserWeight)
+	formula.InteractionWeight = envWeight("CUDDLE_WEIGHT_INTERACTIONS", formula.InteractionWeight)
+	formula.MinuteWeight = envWeight("CUDDLE_WEIGHT_MINUTES", formula.MinuteWeight)
+	return formula
+}
+
+func envWeight(name string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(strings.TrimSpace(os.Getenv(name)), 64)
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
+
+// Score applies the formula to totals already limited to its window, the
+// same way scoreExpr does in ClickHouse.
+func (f Formula) Score(uniqueUsers, interactions int, played time.Duration) int {
+	return int(float64(uniqueUsers)*f.UniqueUserWeight +
+		float64(interactions)*f.InteractionWeight +
+		played.Seconds()/60*f.MinuteWeight)
+}
+
+// scoreExpr is the formula as a ClickHouse expression over
+// jellycat_interactions, taking the three weights as query parameters
+// starting at $first.
+func scoreExpr(first int) string {
+	return `toInt32(
+				countDistinct(user_id) * $` + strconv.Itoa(first) + ` +
+				count() * $` + strconv.Itoa(first+1) + ` +
+				sum(duration) / 60 * $` + strconv.Itoa(first+2) + `
+			)`
+}
+
+// weights returns the query parameters scoreExpr expects.
+func (f Formula) weights() []any {
+	return []any{f.UniqueUserWeight, f.InteractionWeight, f.MinuteWeight}
+}
+
+// FormulaSource reports the formula behind a client's cuddle points. Client
+// and mocks.MockClickHouseClient implement it.
+type FormulaSource interface {
+	Formula() Formula
+}
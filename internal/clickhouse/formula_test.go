@@ -0,0 +1,25 @@
+package clickhouse
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormulaFromEnvFallsBackOnInvalidValues(t *testing.T) {
+	t.Setenv("CUDDLE_WINDOW_DAYS", "14")
+	t.Setenv("CUDDLE_WEIGHT_USERS", "-1")
+	t.Setenv("CUDDLE_WEIGHT_INTERACTIONS", "0.5")
+	t.Setenv("CUDDLE_WEIGHT_MINUTES", "lots")
+
+	want := Formula{WindowDays: 14, UniqueUserWeight: 10, InteractionWeight: 0.5, MinuteWeight: 1}
+	if got := FormulaFromEnv(); got != want {
+		t.Fatalf("FormulaFromEnv() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFormulaScoreMatchesTheDefaultQuery(t *testing.T) {
+	// 3 users, 25 interactions and 90 seconds: 30 + 2.5 + 1.5, truncated.
+	if got := DefaultFormula().Score(3, 25, 90*time.Second); got != 34 {
+		t.Fatalf("Score() = %d, want 34", got)
+	}
+}
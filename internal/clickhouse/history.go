@@ -0,0 +1,84 @@
+package clickhouse
+
+import (
+	"context"
+	"time"
+)
+
+// MaxHistoryDays caps how far back a cuddle points history reaches.
+const MaxHistoryDays = 90
+
+// DailyCuddlePoints is one day of a Jellycat's cuddle points history.
+type DailyCuddlePoints struct {
+	Date   time.Time `json:"date"`
+	Points int       `json:"points"`
+}
+
+// CuddleHistorySource returns a Jellycat's cuddle points per day. Client and
+// mocks.MockClickHouseClient implement it.
+type CuddleHistorySource interface {
+	GetCuddlePointsHistory(ctx context.Context, jellycatID string, days int) ([]DailyCuddlePoints, error)
+}
+
+var _ CuddleHistorySource = (*Client)(nil)
+
+// GetCuddlePointsHistory scores each of the last days days (today included)
+// with the client's formula weights, oldest first. Days without interactions
+// score 0.
+func (c *Client) GetCuddlePointsHistory(ctx context.Context, jellycatID string, days int) ([]DailyCuddlePoints, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout())
+	defer cancel()
+
+	days = clampHistoryDays(days)
+	query := `
+		SELECT
+			toDate(timestamp) AS day,
+			` + scoreExpr(3) + ` AS cuddle_points
+		FROM jellycat_interactions
+		WHERE jellycat_id = $1
+		AND timestamp >= toStartOfDay(now()) - toIntervalDay($2)
+		GROUP BY day
+	`
+
+	args := append([]any{jellycatID, days - 1}, c.formula.weights()...)
+	rows, err := c.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byDay := make(map[string]int)
+	for rows.Next() {
+		var day time.Time
+		var points int32
+		if err := rows.Scan(&day, &points); err != nil {
+			return nil, err
+		}
+		byDay[day.Format(time.DateOnly)] = int(points)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return HistorySeries(time.Now(), days, func(day time.Time) int {
+		return byDay[day.Format(time.DateOnly)]
+	}), nil
+}
+
+// HistorySeries builds a days-long series ending on the day of now, scoring
+// each day with points.
+func HistorySeries(now time.Time, days int, points func(day time.Time) int) []DailyCuddlePoints {
+	days = clampHistoryDays(days)
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	series := make([]DailyCuddlePoints, days)
+	for i := range series {
+		day := today.AddDate(0, 0, i-days+1)
+		series[i] = DailyCuddlePoints{Date: day, Points: points(day)}
+	}
+	return series
+}
+
+func clampHistoryDays(days int) int {
+	return max(1, min(days, MaxHistoryDays))
+}
@@ -0,0 +1,197 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Interaction is one row of jellycat_interactions, the table cuddle points
+// are computed from.
+type Interaction struct {
+	JellycatID string
+	UserID     string
+	Action     string
+	Duration   time.Duration
+	Timestamp  time.Time
+}
+
+// InteractionSink stores interactions. Client and
+// mocks.MockClickHouseClient implement it.
+type InteractionSink interface {
+	InsertInteractions(ctx context.Context, interactions []Interaction) error
+}
+
+var _ InteractionSink = (*Client)(nil)
+
+// InsertInteractions writes interactions in a single batch insert.
+func (c *Client) InsertInteractions(ctx context.Context, interactions []Interaction) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout())
+	defer cancel()
+
+	batch, err := c.conn.PrepareBatch(ctx, "INSERT INTO jellycat_interactions (jellycat_id, user_id, action, duration, timestamp)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare interactions batch: %w", err)
+	}
+	for _, i := range interactions {
+		if err := batch.Append(i.JellycatID, i.UserID, i.Action, uint32(i.Duration.Seconds()), i.Timestamp); err != nil {
+			batch.Abort()
+			return fmt.Errorf("failed to append interaction: %w", err)
+		}
+	}
+	return batch.Send()
+}
+
+const (
+	defaultInteractionQueue = 10000
+	defaultInteractionBatch = 500
+	defaultInteractionFlush = 5 * time.Second
+)
+
+var interactionsDroppedCounter = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "jellycat_interactions_dropped_total",
+	Help: "Interactions dropped because the write queue was full, usually during a ClickHouse outage.",
+})
+
+func init() {
+	prometheus.MustRegister(interactionsDroppedCounter)
+}
+
+// InteractionWriter queues interactions and inserts them in batches. Record
+// never blocks, so a slow or unavailable ClickHouse can't hold up the draft:
+// failed batches are retried on the next flush, and once QueueSize rows are
+// waiting the oldest are dropped.
+type InteractionWriter struct {
+	Sink          InteractionSink
+	QueueSize     int
+	BatchSize     int
+	FlushInterval time.Duration
+
+	mu      sync.Mutex
+	pending []Interaction
+	dropped int
+	flush   chan struct{}
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewInteractionWriter creates a writer for sink configured from the
+// environment: CLICKHOUSE_INSERT_QUEUE and CLICKHOUSE_INSERT_BATCH (rows) and
+// CLICKHOUSE_INSERT_INTERVAL (a Go duration). Invalid values fall back to the
+// defaults.
+func NewInteractionWriter(sink InteractionSink) *InteractionWriter {
+	interval := envDuration("CLICKHOUSE_INSERT_INTERVAL", defaultInteractionFlush)
+	if interval == 0 {
+		interval = defaultInteractionFlush
+	}
+	return &InteractionWriter{
+		Sink:          sink,
+		QueueSize:     envInt("CLICKHOUSE_INSERT_QUEUE", defaultInteractionQueue),
+		BatchSize:     envInt("CLICKHOUSE_INSERT_BATCH", defaultInteractionBatch),
+		FlushInterval: interval,
+		flush:         make(chan struct{}, 1),
+	}
+}
+
+// Record queues interactions for the next batch.
+func (w *InteractionWriter) Record(interactions ...Interaction) {
+	w.mu.Lock()
+	w.pending = append(w.pending, interactions...)
+	if over := len(w.pending) - w.QueueSize; over > 0 {
+		w.pending = w.pending[over:]
+		w.dropped += over
+		interactionsDroppedCounter.Add(float64(over))
+		logger.Warn("Interaction queue full, dropping oldest", "dropped", over)
+	}
+	full := len(w.pending) >= w.BatchSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Pending returns how many interactions are waiting to be written.
+func (w *InteractionWriter) Pending() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.pending)
+}
+
+// Start flushes every FlushInterval, or sooner when a batch fills, until ctx
+// is done or Stop is called.
+func (w *InteractionWriter) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	w.mu.Lock()
+	w.cancel = cancel
+	w.done = make(chan struct{})
+	done := w.done
+	w.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(w.FlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-w.flush:
+			}
+			w.Flush(ctx)
+		}
+	}()
+}
+
+// Stop ends the loop started by Start and makes a last attempt to write
+// whatever is queued.
+func (w *InteractionWriter) Stop() {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+
+	ctx, cancelFlush := context.WithTimeout(context.Background(), QueryTimeout())
+	defer cancelFlush()
+	w.Flush(ctx)
+}
+
+// Flush writes queued interactions in batches of BatchSize, stopping at the
+// first failure so the rest stay queued for the next attempt.
+func (w *InteractionWriter) Flush(ctx context.Context) error {
+	for {
+		w.mu.Lock()
+		n := min(len(w.pending), w.BatchSize)
+		batch := append([]Interaction(nil), w.pending[:n]...)
+		droppedBefore := w.dropped
+		w.mu.Unlock()
+		if n == 0 {
+			return nil
+		}
+
+		if err := w.Sink.InsertInteractions(ctx, batch); err != nil {
+			logger.Warn("Failed to write interactions, will retry", "error", err, "batch", n)
+			return err
+		}
+
+		// Record may have dropped some of the batch from the front of the
+		// queue meanwhile; remove only what is left of it.
+		w.mu.Lock()
+		if remaining := n - (w.dropped - droppedBefore); remaining > 0 {
+			w.pending = w.pending[remaining:]
+		}
+		w.mu.Unlock()
+	}
+}
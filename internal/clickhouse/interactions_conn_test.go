@@ -0,0 +1,69 @@
+package clickhouse
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+// batchConn is a driver.Conn that captures the rows of each batch sent.
+type batchConn struct {
+	driver.Conn
+	queries []string
+	sent    [][][]any
+}
+
+func (c *batchConn) PrepareBatch(ctx context.Context, query string, opts ...driver.PrepareBatchOption) (driver.Batch, error) {
+	c.queries = append(c.queries, query)
+	return &capturedBatch{conn: c}, nil
+}
+
+type capturedBatch struct {
+	driver.Batch
+	conn *batchConn
+	rows [][]any
+}
+
+func (b *capturedBatch) Append(v ...any) error {
+	b.rows = append(b.rows, v)
+	return nil
+}
+
+func (b *capturedBatch) Send() error {
+	b.conn.sent = append(b.conn.sent, b.rows)
+	return nil
+}
+
+func TestInteractionWriterSendsBatchesThroughTheConn(t *testing.T) {
+	logger.Init()
+	t.Setenv("CLICKHOUSE_INSERT_BATCH", "2")
+	conn := &batchConn{}
+	writer := NewInteractionWriter(&Client{conn: conn})
+	if writer.BatchSize != 2 || writer.FlushInterval != defaultInteractionFlush {
+		t.Fatalf("writer = batch %d every %v, want batch 2 every %v", writer.BatchSize, writer.FlushInterval, defaultInteractionFlush)
+	}
+
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, id := range []string{"1", "2", "3"} {
+		writer.Record(Interaction{JellycatID: id, UserID: "user-1", Action: "pick", Duration: 90 * time.Second, Timestamp: at})
+	}
+	writer.Start(context.Background())
+	writer.Stop()
+
+	if len(conn.sent) != 2 || len(conn.sent[0]) != 2 || len(conn.sent[1]) != 1 {
+		t.Fatalf("sent %v, want batches of 2 and 1", conn.sent)
+	}
+	if conn.queries[0] != "INSERT INTO jellycat_interactions (jellycat_id, user_id, action, duration, timestamp)" {
+		t.Fatalf("query = %q", conn.queries[0])
+	}
+	row := conn.sent[0][0]
+	if row[0] != "1" || row[1] != "user-1" || row[2] != "pick" || row[3] != uint32(90) || row[4] != at {
+		t.Fatalf("first row = %v, want the interaction's columns with duration in seconds", row)
+	}
+	if writer.Pending() != 0 {
+		t.Fatalf("Pending() = %d after Stop, want everything flushed", writer.Pending())
+	}
+}
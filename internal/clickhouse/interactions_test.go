@@ -0,0 +1,67 @@
+package clickhouse_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/clickhouse"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+)
+
+// downSink fails while down is set and records the batches it accepts.
+type downSink struct {
+	down    bool
+	batches [][]clickhouse.Interaction
+}
+
+func (s *downSink) InsertInteractions(ctx context.Context, interactions []clickhouse.Interaction) error {
+	if s.down {
+		return errors.New("clickhouse unavailable")
+	}
+	s.batches = append(s.batches, interactions)
+	return nil
+}
+
+func interactionsFor(ids ...string) []clickhouse.Interaction {
+	out := make([]clickhouse.Interaction, len(ids))
+	for i, id := range ids {
+		out[i] = clickhouse.Interaction{JellycatID: id, UserID: "user-1", Action: "pick", Timestamp: time.Now()}
+	}
+	return out
+}
+
+func TestInteractionWriterBatchesAndRetries(t *testing.T) {
+	logger.Init()
+	sink := &downSink{down: true}
+	writer := clickhouse.NewInteractionWriter(sink)
+	writer.BatchSize = 2
+	writer.QueueSize = 4
+
+	// Recording while ClickHouse is down neither blocks nor loses rows...
+	writer.Record(interactionsFor("1", "2", "3")...)
+	if err := writer.Flush(context.Background()); err == nil {
+		t.Fatal("Flush() succeeded with the sink down")
+	}
+	if writer.Pending() != 3 {
+		t.Fatalf("Pending() = %d, want the 3 rows kept for retry", writer.Pending())
+	}
+
+	// ...until the queue is full, when the oldest give way.
+	writer.Record(interactionsFor("4", "5")...)
+	if writer.Pending() != 4 {
+		t.Fatalf("Pending() = %d, want the queue capped at 4", writer.Pending())
+	}
+
+	sink.down = false
+	if err := writer.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+	if writer.Pending() != 0 || len(sink.batches) != 2 || len(sink.batches[0]) != 2 {
+		t.Fatalf("batches = %v, pending = %d; want two batches of up to 2", sink.batches, writer.Pending())
+	}
+	if first := sink.batches[0][0].JellycatID; first != "2" {
+		t.Fatalf("first written row = %s, want 2 after 1 was dropped", first)
+	}
+}
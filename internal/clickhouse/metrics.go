@@ -0,0 +1,197 @@
+package clickhouse
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// PlayerMetrics rates a Jellycat's activity over the formula's window.
+// Popularity, Consistency and Efficiency run from 0 to 100; TrendDelta from
+// -1 to 1.
+type PlayerMetrics struct {
+	// Popularity is the percentile of the Jellycat's unique users.
+	Popularity int `json:"popularity"`
+	// Consistency is high when interactions are spread evenly across days.
+	Consistency int `json:"consistency"`
+	// Efficiency is the percentile of cuddle points earned per interaction.
+	Efficiency int `json:"efficiency"`
+	// TrendDelta compares the recent half of the window with the older half.
+	TrendDelta float64 `json:"trendDelta"`
+}
+
+// ActivityTotals is one Jellycat's activity over the formula's window.
+type ActivityTotals struct {
+	UniqueUsers  int
+	Interactions int
+	Points       int
+}
+
+// MetricsSource computes a Jellycat's profile metrics. Every PointsSource
+// is one.
+type MetricsSource interface {
+	GetPlayerMetrics(ctx context.Context, jellycatID string) (PlayerMetrics, error)
+}
+
+// GetPlayerMetrics ranks jellycatID's activity against every Jellycat
+// scored in the formula's window.
+func (c *Client) GetPlayerMetrics(ctx context.Context, jellycatID string) (PlayerMetrics, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout())
+	defer cancel()
+
+	query := `
+		SELECT
+			jellycat_id,
+			countDistinct(user_id) AS users,
+			count() AS interactions,
+			` + scoreExpr(2) + ` AS cuddle_points
+		FROM jellycat_interactions
+		WHERE timestamp >= now() - toIntervalDay($1)
+		GROUP BY jellycat_id
+	`
+	args := append([]any{c.formula.WindowDays}, c.formula.weights()...)
+	rows, err := c.conn.Query(ctx, query, args...)
+	if err != nil {
+		return PlayerMetrics{}, err
+	}
+	totals := make(map[string]ActivityTotals)
+	for rows.Next() {
+		var id string
+		var users, interactions uint64
+		var points int32
+		if err := rows.Scan(&id, &users, &interactions, &points); err != nil {
+			rows.Close()
+			return PlayerMetrics{}, err
+		}
+		totals[id] = ActivityTotals{UniqueUsers: int(users), Interactions: int(interactions), Points: int(points)}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return PlayerMetrics{}, err
+	}
+
+	dailyQuery := `
+		SELECT toDate(timestamp) AS day, count() AS interactions
+		FROM jellycat_interactions
+		WHERE jellycat_id = $1
+		AND timestamp >= toStartOfDay(now()) - toIntervalDay($2)
+		GROUP BY day
+	`
+	days := clampHistoryDays(c.formula.WindowDays)
+	rows, err = c.conn.Query(ctx, dailyQuery, jellycatID, days-1)
+	if err != nil {
+		return PlayerMetrics{}, err
+	}
+	defer rows.Close()
+	byDay := make(map[string]int)
+	for rows.Next() {
+		var day time.Time
+		var interactions uint64
+		if err := rows.Scan(&day, &interactions); err != nil {
+			return PlayerMetrics{}, err
+		}
+		byDay[day.Format(time.DateOnly)] = int(interactions)
+	}
+	if err := rows.Err(); err != nil {
+		return PlayerMetrics{}, err
+	}
+
+	series := HistorySeries(time.Now(), days, func(day time.Time) int {
+		return byDay[day.Format(time.DateOnly)]
+	})
+	daily := make([]int, len(series))
+	for i, day := range series {
+		daily[i] = day.Points
+	}
+	return ComputePlayerMetrics(jellycatID, totals, daily), nil
+}
+
+// ComputePlayerMetrics rates jellycatID against totals, every Jellycat's
+// activity, with daily holding its interactions per day, oldest first.
+// A Jellycat with no activity scores zero throughout.
+func ComputePlayerMetrics(jellycatID string, totals map[string]ActivityTotals, daily []int) PlayerMetrics {
+	own := totals[jellycatID]
+	metrics := PlayerMetrics{
+		Consistency: consistency(daily),
+		TrendDelta:  trendDelta(daily),
+	}
+
+	if own.UniqueUsers > 0 {
+		metrics.Popularity = percentile(totals, own, func(t ActivityTotals) (float64, bool) {
+			return float64(t.UniqueUsers), true
+		})
+	}
+	if own.Interactions > 0 {
+		metrics.Efficiency = percentile(totals, own, func(t ActivityTotals) (float64, bool) {
+			if t.Interactions == 0 {
+				return 0, false
+			}
+			return float64(t.Points) / float64(t.Interactions), true
+		})
+	}
+	return metrics
+}
+
+// percentile is the share of Jellycats, among those value counts, whose value
+// is at most own's.
+func percentile(totals map[string]ActivityTotals, own ActivityTotals, value func(ActivityTotals) (float64, bool)) int {
+	ownValue, _ := value(own)
+	ranked, atOrBelow := 0, 0
+	for _, t := range totals {
+		v, ok := value(t)
+		if !ok {
+			continue
+		}
+		ranked++
+		if v <= ownValue {
+			atOrBelow++
+		}
+	}
+	if ranked == 0 {
+		return 0
+	}
+	return atOrBelow * 100 / ranked
+}
+
+// consistency maps the coefficient of variation of daily to 0–100: 100 for
+// the same count every day, 50 when the spread equals the mean.
+func consistency(daily []int) int {
+	if len(daily) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, n := range daily {
+		sum += float64(n)
+	}
+	mean := sum / float64(len(daily))
+	if mean == 0 {
+		return 0
+	}
+	var variance float64
+	for _, n := range daily {
+		variance += (float64(n) - mean) * (float64(n) - mean)
+	}
+	cv := math.Sqrt(variance/float64(len(daily))) / mean
+	return int(math.Round(100 / (1 + cv)))
+}
+
+// trendDelta is the change from the older half of daily to the newer half,
+// clamped to [-1, 1].
+func trendDelta(daily []int) float64 {
+	half := len(daily) / 2
+	var older, newer int
+	for i, n := range daily {
+		if i < len(daily)-half {
+			older += n
+		} else {
+			newer += n
+		}
+	}
+	switch {
+	case older == 0 && newer == 0:
+		return 0
+	case older == 0:
+		return 1
+	}
+	return math.Max(-1, math.Min(1, float64(newer-older)/float64(older)))
+}
@@ -0,0 +1,36 @@
+package clickhouse
+
+import "testing"
+
+func TestComputePlayerMetrics(t *testing.T) {
+	totals := map[string]ActivityTotals{
+		"quiet":   {UniqueUsers: 1, Interactions: 10, Points: 20},
+		"middle":  {UniqueUsers: 5, Interactions: 50, Points: 60},
+		"popular": {UniqueUsers: 20, Interactions: 400, Points: 240},
+		"idle":    {},
+	}
+
+	metrics := ComputePlayerMetrics("middle", totals, []int{5, 5, 5, 5})
+	if metrics.Popularity != 75 {
+		t.Errorf("Popularity = %d, want 75 (3 of 4 at or below)", metrics.Popularity)
+	}
+	// Points per interaction: quiet 2, middle 1.2, popular 0.6; idle has none.
+	if metrics.Efficiency != 66 {
+		t.Errorf("Efficiency = %d, want 66 (2 of 3 at or below)", metrics.Efficiency)
+	}
+	if metrics.Consistency != 100 || metrics.TrendDelta != 0 {
+		t.Errorf("Consistency, TrendDelta = %d, %v; want 100, 0 for an even series", metrics.Consistency, metrics.TrendDelta)
+	}
+
+	metrics = ComputePlayerMetrics("popular", totals, []int{0, 0, 2, 6})
+	if metrics.TrendDelta != 1 {
+		t.Errorf("TrendDelta = %v, want 1 for activity that only just started", metrics.TrendDelta)
+	}
+	if metrics.Consistency >= 50 {
+		t.Errorf("Consistency = %d, want under 50 for a spiky series", metrics.Consistency)
+	}
+
+	if metrics := ComputePlayerMetrics("unknown", totals, []int{0, 0}); metrics != (PlayerMetrics{}) {
+		t.Errorf("metrics for an unknown Jellycat = %+v, want zero", metrics)
+	}
+}
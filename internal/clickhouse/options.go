@@ -0,0 +1,113 @@
+package clickhouse
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+)
+
+// ClickHouse exception codes for rejected credentials.
+const (
+	codeUnknownUser          = 192
+	codeWrongPassword        = 193
+	codeAuthenticationFailed = 516
+)
+
+// Errors NewClient wraps so startup failures say which layer rejected the
+// connection.
+var (
+	ErrTLSHandshake = errors.New("ClickHouse TLS handshake failed")
+	ErrAuthFailed   = errors.New("ClickHouse rejected the credentials")
+)
+
+// connOptions builds the driver options for addr from the environment:
+//   - CLICKHOUSE_TLS=true connects over TLS, verifying against
+//     CLICKHOUSE_TLS_CA_FILE (PEM) if set, else the system roots.
+//     CLICKHOUSE_TLS_INSECURE_SKIP_VERIFY=true skips verification for lab setups.
+//   - CLICKHOUSE_COMPRESSION=lz4 compresses blocks; anything else sends them raw.
+//   - CLICKHOUSE_DIAL_TIMEOUT (a Go duration), CLICKHOUSE_MAX_OPEN_CONNS and
+//     CLICKHOUSE_MAX_IDLE_CONNS tune the pool; unset or invalid values keep the
+//     driver defaults.
+//   - CLICKHOUSE_MAX_EXECUTION_TIME (seconds) sets max_execution_time.
+//
+// Only an unreadable or invalid CA file is an error.
+func connOptions(addr, database, username, password string) (*clickhouse.Options, error) {
+	options := &clickhouse.Options{
+		Addr: []string{addr},
+		Auth: clickhouse.Auth{
+			Database: database,
+			Username: username,
+			Password: password,
+		},
+		DialTimeout:  envDuration("CLICKHOUSE_DIAL_TIMEOUT", 0),
+		MaxOpenConns: envInt("CLICKHOUSE_MAX_OPEN_CONNS", 0),
+		MaxIdleConns: envInt("CLICKHOUSE_MAX_IDLE_CONNS", 0),
+	}
+
+	if strings.EqualFold(strings.TrimSpace(os.Getenv("CLICKHOUSE_COMPRESSION")), "lz4") {
+		options.Compression = &clickhouse.Compression{Method: clickhouse.CompressionLZ4}
+	}
+	if seconds := envInt("CLICKHOUSE_MAX_EXECUTION_TIME", 0); seconds > 0 {
+		options.Settings = clickhouse.Settings{"max_execution_time": seconds}
+	}
+
+	if os.Getenv("CLICKHOUSE_TLS") == "true" {
+		tlsConfig, err := tlsConfigFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		options.TLS = tlsConfig
+	}
+	return options, nil
+}
+
+func tlsConfigFromEnv() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: os.Getenv("CLICKHOUSE_TLS_INSECURE_SKIP_VERIFY") == "true",
+	}
+	caFile := strings.TrimSpace(os.Getenv("CLICKHOUSE_TLS_CA_FILE"))
+	if caFile == "" {
+		return config, nil
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CLICKHOUSE_TLS_CA_FILE: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("CLICKHOUSE_TLS_CA_FILE %s contains no PEM certificates", caFile)
+	}
+	config.RootCAs = pool
+	return config, nil
+}
+
+// classifyConnError wraps err with ErrTLSHandshake or ErrAuthFailed when it
+// is one of those, so the startup log points at certificates or credentials
+// rather than the network.
+func classifyConnError(err error) error {
+	var (
+		exception    *clickhouse.Exception
+		recordHeader tls.RecordHeaderError
+		alert        tls.AlertError
+		verification *tls.CertificateVerificationError
+		unknownAuth  x509.UnknownAuthorityError
+		hostname     x509.HostnameError
+		invalidCert  x509.CertificateInvalidError
+	)
+	switch {
+	case errors.As(err, &exception) && (exception.Code == codeAuthenticationFailed ||
+		exception.Code == codeUnknownUser || exception.Code == codeWrongPassword):
+		return fmt.Errorf("%w: %w", ErrAuthFailed, err)
+	case errors.As(err, &recordHeader), errors.As(err, &alert), errors.As(err, &verification),
+		errors.As(err, &unknownAuth), errors.As(err, &hostname), errors.As(err, &invalidCert):
+		return fmt.Errorf("%w (check CLICKHOUSE_TLS and the server certificate): %w", ErrTLSHandshake, err)
+	default:
+		return err
+	}
+}
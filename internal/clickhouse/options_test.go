@@ -0,0 +1,68 @@
+package clickhouse
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+)
+
+func TestConnOptionsFromEnv(t *testing.T) {
+	t.Setenv("CLICKHOUSE_TLS", "true")
+	t.Setenv("CLICKHOUSE_TLS_INSECURE_SKIP_VERIFY", "true")
+	t.Setenv("CLICKHOUSE_COMPRESSION", "LZ4")
+	t.Setenv("CLICKHOUSE_DIAL_TIMEOUT", "3s")
+	t.Setenv("CLICKHOUSE_MAX_OPEN_CONNS", "12")
+	t.Setenv("CLICKHOUSE_MAX_IDLE_CONNS", "nope")
+	t.Setenv("CLICKHOUSE_MAX_EXECUTION_TIME", "30")
+
+	options, err := connOptions("ch:9440", "analytics", "reader", "secret")
+	if err != nil {
+		t.Fatalf("connOptions() failed: %v", err)
+	}
+	if options.TLS == nil || !options.TLS.InsecureSkipVerify {
+		t.Errorf("TLS = %+v, want insecure TLS", options.TLS)
+	}
+	if options.Compression == nil || options.Compression.Method != clickhouse.CompressionLZ4 {
+		t.Errorf("Compression = %+v, want lz4", options.Compression)
+	}
+	if options.DialTimeout != 3*time.Second || options.MaxOpenConns != 12 || options.MaxIdleConns != 0 {
+		t.Errorf("pool = %v/%d/%d, want 3s/12/0", options.DialTimeout, options.MaxOpenConns, options.MaxIdleConns)
+	}
+	if options.Settings["max_execution_time"] != 30 {
+		t.Errorf("Settings = %v, want max_execution_time 30", options.Settings)
+	}
+}
+
+func TestConnOptionsRejectsBadCAFile(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CLICKHOUSE_TLS", "true")
+	t.Setenv("CLICKHOUSE_TLS_CA_FILE", caFile)
+
+	if _, err := connOptions("ch:9440", "default", "default", ""); err == nil {
+		t.Fatal("connOptions() succeeded with an invalid CA file")
+	}
+}
+
+func TestClassifyConnError(t *testing.T) {
+	auth := fmt.Errorf("handshake: %w", &clickhouse.Exception{Code: codeAuthenticationFailed, Message: "bad password"})
+	if err := classifyConnError(auth); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("classifyConnError(auth) = %v, want ErrAuthFailed", err)
+	}
+	untrusted := fmt.Errorf("dial: %w", x509.UnknownAuthorityError{})
+	if err := classifyConnError(untrusted); !errors.Is(err, ErrTLSHandshake) {
+		t.Errorf("classifyConnError(untrusted) = %v, want ErrTLSHandshake", err)
+	}
+	other := errors.New("connection refused")
+	if err := classifyConnError(other); err != other {
+		t.Errorf("classifyConnError(other) = %v, want it unchanged", err)
+	}
+}
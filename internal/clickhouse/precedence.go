@@ -0,0 +1,56 @@
+package clickhouse
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// PointsPrecedence decides what a sync does to points set by hand, set by
+// POINTS_SOURCE.
+type PointsPrecedence string
+
+const (
+	// PrecedenceClickHouse overwrites points with ClickHouse's values.
+	PrecedenceClickHouse PointsPrecedence = "clickhouse"
+	// PrecedenceManual keeps the points the draft has; syncs change nothing.
+	PrecedenceManual PointsPrecedence = "manual"
+	// PrecedenceBlend stores the average of the draft's points and ClickHouse's.
+	PrecedenceBlend PointsPrecedence = "blend"
+)
+
+// ErrManualPoints is what a sync's update returns for a player whose points
+// POINTS_SOURCE keeps; like ErrUnknownPlayer, it counts as skipped.
+var ErrManualPoints = errors.New("points are kept by POINTS_SOURCE")
+
+// PointsPrecedenceFromEnv reads POINTS_SOURCE, defaulting to
+// PrecedenceClickHouse when it is unset or unknown.
+func PointsPrecedenceFromEnv() PointsPrecedence {
+	switch precedence := PointsPrecedence(strings.ToLower(strings.TrimSpace(os.Getenv("POINTS_SOURCE")))); precedence {
+	case PrecedenceManual, PrecedenceBlend:
+		return precedence
+	}
+	return PrecedenceClickHouse
+}
+
+// withPrecedence wraps a sync's update so it follows precedence, given each
+// player's points before the run. Blending needs those points, so a player
+// missing from before is skipped rather than overwritten.
+func withPrecedence(precedence PointsPrecedence, before map[string]int, update func(playerID string, points int) error) func(playerID string, points int) error {
+	switch precedence {
+	case PrecedenceManual:
+		return func(playerID string, points int) error {
+			return fmt.Errorf("%w: %s", ErrManualPoints, playerID)
+		}
+	case PrecedenceBlend:
+		return func(playerID string, points int) error {
+			current, ok := before[playerID]
+			if !ok {
+				return fmt.Errorf("%w: %s has no current points to blend", ErrManualPoints, playerID)
+			}
+			return update(playerID, (current+points)/2)
+		}
+	}
+	return update
+}
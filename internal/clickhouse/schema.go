@@ -0,0 +1,66 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const defaultInteractionsTTLDays = 90
+
+// InteractionsTTLDays is how long jellycat_interactions rows are kept, read
+// from CLICKHOUSE_INTERACTIONS_TTL_DAYS. 0 keeps rows forever. The cuddle
+// points query only looks back 30 days, so shorter values skew points.
+func InteractionsTTLDays() int {
+	value := strings.TrimSpace(os.Getenv("CLICKHOUSE_INTERACTIONS_TTL_DAYS"))
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		return defaultInteractionsTTLDays
+	}
+	return days
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// interactionsTableDDL creates jellycat_interactions in database unless it
+// already exists: daily partitions, ordered for the per-Jellycat lookups the
+// cuddle points queries make.
+func interactionsTableDDL(database string, ttlDays int) (string, error) {
+	if !identifierPattern.MatchString(database) {
+		return "", fmt.Errorf("invalid ClickHouse database name %q", database)
+	}
+
+	ddl := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s.jellycat_interactions (
+	jellycat_id String,
+	user_id String,
+	action LowCardinality(String),
+	duration UInt32,
+	timestamp DateTime
+) ENGINE = MergeTree
+PARTITION BY toDate(timestamp)
+ORDER BY (jellycat_id, timestamp)`, database)
+	if ttlDays > 0 {
+		ddl += fmt.Sprintf("\nTTL timestamp + INTERVAL %d DAY", ttlDays)
+	}
+	return ddl, nil
+}
+
+// EnsureSchema creates the tables the client reads and writes if they are
+// missing. Existing tables are left as they are, so it is safe on every start;
+// a changed TTL only applies to tables created afterwards.
+func (c *Client) EnsureSchema(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout())
+	defer cancel()
+
+	ddl, err := interactionsTableDDL(c.database, InteractionsTTLDays())
+	if err != nil {
+		return err
+	}
+	if err := c.conn.Exec(ctx, ddl); err != nil {
+		return fmt.Errorf("failed to create jellycat_interactions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package clickhouse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInteractionsTableDDL(t *testing.T) {
+	ddl, err := interactionsTableDDL("analytics", 90)
+	if err != nil {
+		t.Fatalf("interactionsTableDDL() failed: %v", err)
+	}
+	for _, want := range []string{
+		"CREATE TABLE IF NOT EXISTS analytics.jellycat_interactions",
+		"ENGINE = MergeTree",
+		"PARTITION BY toDate(timestamp)",
+		"ORDER BY (jellycat_id, timestamp)",
+		"TTL timestamp + INTERVAL 90 DAY",
+	} {
+		if !strings.Contains(ddl, want) {
+			t.Errorf("DDL missing %q:\n%s", want, ddl)
+		}
+	}
+
+	if ddl, _ := interactionsTableDDL("analytics", 0); strings.Contains(ddl, "TTL") {
+		t.Errorf("DDL with TTL disabled still has a TTL:\n%s", ddl)
+	}
+	if _, err := interactionsTableDDL("analytics; DROP TABLE x", 90); err == nil {
+		t.Error("interactionsTableDDL() accepted an invalid database name")
+	}
+}
+
+func TestInteractionsTTLDays(t *testing.T) {
+	for value, want := range map[string]int{"": 90, "30": 30, "0": 0, "-1": 90, "soon": 90} {
+		t.Setenv("CLICKHOUSE_INTERACTIONS_TTL_DAYS", value)
+		if got := InteractionsTTLDays(); got != want {
+			t.Errorf("CLICKHOUSE_INTERACTIONS_TTL_DAYS=%q: got %d, want %d", value, got, want)
+		}
+	}
+}
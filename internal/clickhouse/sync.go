@@ -0,0 +1,351 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	defaultSyncInterval   = 5 * time.Minute
+	defaultSyncJitter     = 0.1
+	defaultSyncAttempts   = 4
+	defaultSyncBackoff    = 2 * time.Second
+	defaultSyncAlertAfter = 3
+	defaultSyncHistory    = 20
+)
+
+// How a sync run was started.
+const (
+	TriggerScheduled = "scheduled"
+	TriggerManual    = "manual"
+)
+
+// SyncRun records one sync run. Error is set when the run never reached
+// ClickHouse; per-player failures are in Errors.
+type SyncRun struct {
+	StartedAt  time.Time `json:"startedAt"`
+	DurationMs int64     `json:"durationMs"`
+	Trigger    string    `json:"trigger"`
+	SyncResult
+	Error string `json:"error,omitempty"`
+}
+
+// ErrSyncInProgress is returned by a run started while another is still
+// going; the second run is skipped rather than queued.
+var ErrSyncInProgress = errors.New("a cuddle points sync is already running")
+
+var (
+	syncFailuresGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "jellycat_cuddle_sync_consecutive_failures",
+		Help: "Cuddle points sync runs that have failed in a row.",
+	})
+	syncLastSuccessGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "jellycat_cuddle_sync_last_success_timestamp_seconds",
+		Help: "Unix time of the last successful cuddle points sync.",
+	})
+	syncOverlapsCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "jellycat_cuddle_sync_overlaps_total",
+		Help: "Cuddle points sync runs skipped because another was still running.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(syncFailuresGauge, syncLastSuccessGauge, syncOverlapsCounter)
+}
+
+// Clock is the time source a Syncer waits on; tests substitute a fake.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Syncer copies cuddle points from a CuddlePointsClient into the draft on an
+// interval. Each run retries failed queries with exponential backoff, and
+// OnAlert is called once a run brings the failure streak to AlertAfter.
+type Syncer struct {
+	Client CuddlePointsClient
+	Update func(playerID string, points int) error
+
+	Interval   time.Duration
+	Jitter     float64 // each wait is Interval ± this fraction
+	StartDelay time.Duration
+	Attempts   int
+	Backoff    time.Duration
+	AlertAfter int
+	OnAlert    func(failures int, lastSuccess time.Time, err error)
+	Clock      Clock
+	// History is how many runs Runs keeps.
+	History int
+	// Current, when set, reads every player's points before a run, and
+	// OnChanged is then called after a successful run that moved any.
+	Current   func() (map[string]int, error)
+	OnChanged func(changes []PointsChange)
+	// Precedence decides whether a run overwrites, keeps or blends the
+	// draft's points; blending uses the points Current reads.
+	Precedence PointsPrecedence
+
+	running  atomic.Bool
+	overlaps atomic.Int64
+
+	mu                  sync.Mutex
+	lastSuccess         time.Time
+	lastResult          SyncResult
+	consecutiveFailures int
+	runs                []SyncRun // oldest first
+	cancel              context.CancelFunc
+	done                chan struct{}
+}
+
+// NewSyncer creates a Syncer configured from the environment:
+// CUDDLE_SYNC_INTERVAL and CUDDLE_SYNC_STARTUP_DELAY (Go durations) and
+// CUDDLE_SYNC_ALERT_AFTER, CUDDLE_SYNC_HISTORY and POINTS_SOURCE. Invalid
+// values fall back to the defaults.
+func NewSyncer(client CuddlePointsClient, update func(playerID string, points int) error) *Syncer {
+	interval := envDuration("CUDDLE_SYNC_INTERVAL", defaultSyncInterval)
+	if interval == 0 {
+		interval = defaultSyncInterval
+	}
+	return &Syncer{
+		Client:     client,
+		Update:     update,
+		Interval:   interval,
+		Jitter:     defaultSyncJitter,
+		StartDelay: envDuration("CUDDLE_SYNC_STARTUP_DELAY", 0),
+		Attempts:   defaultSyncAttempts,
+		Backoff:    defaultSyncBackoff,
+		AlertAfter: envInt("CUDDLE_SYNC_ALERT_AFTER", defaultSyncAlertAfter),
+		Clock:      realClock{},
+		History:    envInt("CUDDLE_SYNC_HISTORY", defaultSyncHistory),
+		Precedence: PointsPrecedenceFromEnv(),
+	}
+}
+
+// SyncDisabled reports whether CUDDLE_SYNC_DISABLED=true turns the sync off.
+func SyncDisabled() bool {
+	return os.Getenv("CUDDLE_SYNC_DISABLED") == "true"
+}
+
+// Start runs the sync after StartDelay and then every jittered Interval until
+// ctx is done or Stop is called.
+func (s *Syncer) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	done := s.done
+	s.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		wait := s.StartDelay
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Info("Stopping cuddle points sync")
+				return
+			case <-s.Clock.After(wait):
+			}
+			s.run(ctx, TriggerScheduled)
+			wait = s.nextWait()
+		}
+	}()
+}
+
+// Stop ends the loop started by Start and waits for a run in progress.
+func (s *Syncer) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// nextWait is Interval spread by ±Jitter so replicas drift apart.
+func (s *Syncer) nextWait() time.Duration {
+	if s.Jitter <= 0 {
+		return s.Interval
+	}
+	spread := (rand.Float64()*2 - 1) * s.Jitter
+	return s.Interval + time.Duration(float64(s.Interval)*spread)
+}
+
+// RunOnce syncs now, retrying transient failures. A run is given until the
+// next interval, so a hung ClickHouse node can't pile up overlapping runs.
+// Players that fail to update don't fail the run; they are listed in the
+// result. While another run, scheduled or manual, is in progress it returns
+// ErrSyncInProgress without syncing.
+func (s *Syncer) RunOnce(ctx context.Context) (SyncResult, error) {
+	return s.run(ctx, TriggerScheduled)
+}
+
+// RunManually is RunOnce for syncs an admin asked for; Runs marks them
+// manual.
+func (s *Syncer) RunManually(ctx context.Context) (SyncResult, error) {
+	return s.run(ctx, TriggerManual)
+}
+
+func (s *Syncer) run(ctx context.Context, trigger string) (SyncResult, error) {
+	if !s.running.CompareAndSwap(false, true) {
+		overlaps := s.overlaps.Add(1)
+		syncOverlapsCounter.Inc()
+		logger.Warn("Skipping cuddle points sync; the previous run is still going", "trigger", trigger, "overlaps", overlaps)
+		return SyncResult{}, ErrSyncInProgress
+	}
+	defer s.running.Store(false)
+
+	logger.Info("Syncing cuddle points from ClickHouse", "trigger", trigger)
+	started := s.Clock.Now()
+	ctx, cancel := context.WithTimeout(ctx, s.Interval)
+	defer cancel()
+
+	update := s.Update
+	var tracker *changeTracker
+	var before map[string]int
+	if s.Current != nil {
+		var err error
+		if before, err = s.Current(); err != nil {
+			logger.Warn("Could not read current points; this sync won't report changes", "error", err)
+		} else {
+			tracker = newChangeTracker(before)
+			update = tracker.wrap(update)
+		}
+	}
+	update = withPrecedence(s.Precedence, before, update)
+
+	var result SyncResult
+	var err error
+	backoff := s.Backoff
+	for attempt := 1; ; attempt++ {
+		result, err = s.Client.SyncCuddlePoints(ctx, update)
+		if err == nil || attempt >= s.Attempts || ctx.Err() != nil {
+			break
+		}
+
+		logger.Warn("Cuddle points sync failed, retrying", "error", err, "attempt", attempt, "backoff", backoff)
+		select {
+		case <-ctx.Done():
+		case <-s.Clock.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	failures, lastSuccess := s.record(SyncRun{StartedAt: started, Trigger: trigger, SyncResult: result}, err)
+	if err == nil {
+		logger.Info("Cuddle points synced", "updated", result.Updated, "skipped", result.Skipped, "errors", len(result.Errors))
+		if len(result.Errors) > 0 {
+			logger.Warn("Some cuddle points failed to update", "errors", result.Errors)
+		}
+		if tracker != nil && s.OnChanged != nil {
+			if changes := tracker.changes(); len(changes) > 0 {
+				s.OnChanged(changes)
+			}
+		}
+		return result, nil
+	}
+
+	logger.Error("Failed to sync cuddle points", "error", err, "consecutive_failures", failures)
+	if failures == s.AlertAfter && s.OnAlert != nil {
+		s.OnAlert(failures, lastSuccess, err)
+	}
+	return result, err
+}
+
+func (s *Syncer) record(run SyncRun, err error) (failures int, lastSuccess time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.Clock.Now()
+	if err == nil {
+		s.lastSuccess = now
+		s.lastResult = run.SyncResult
+		s.consecutiveFailures = 0
+		syncLastSuccessGauge.Set(float64(s.lastSuccess.Unix()))
+	} else {
+		run.Error = err.Error()
+		s.consecutiveFailures++
+	}
+	syncFailuresGauge.Set(float64(s.consecutiveFailures))
+
+	run.DurationMs = now.Sub(run.StartedAt).Milliseconds()
+	if run.Errors == nil {
+		run.Errors = []string{}
+	}
+	s.runs = append(s.runs, run)
+	if keep := max(s.History, 1); len(s.runs) > keep {
+		s.runs = append([]SyncRun(nil), s.runs[len(s.runs)-keep:]...)
+	}
+	return s.consecutiveFailures, s.lastSuccess
+}
+
+// Runs returns the most recent runs, newest first.
+func (s *Syncer) Runs() []SyncRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs := make([]SyncRun, len(s.runs))
+	for i, run := range s.runs {
+		runs[len(runs)-1-i] = run
+	}
+	return runs
+}
+
+// Overlaps returns how many runs have been skipped because another was still
+// going. A steady climb means the interval is shorter than a sync takes.
+func (s *Syncer) Overlaps() int64 {
+	return s.overlaps.Load()
+}
+
+// LastResult returns the summary of the last run that reached ClickHouse.
+func (s *Syncer) LastResult() SyncResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastResult
+}
+
+// Status returns when the last run succeeded (zero if none has) and how many
+// runs have failed since.
+func (s *Syncer) Status() (lastSuccess time.Time, consecutiveFailures int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastSuccess, s.consecutiveFailures
+}
+
+// Unhealthy reports whether the failure streak has reached AlertAfter.
+func (s *Syncer) Unhealthy() bool {
+	_, failures := s.Status()
+	return failures >= s.AlertAfter
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(strings.TrimSpace(os.Getenv(name)))
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
+
+func envInt(name string, fallback int) int {
+	value, err := strconv.Atoi(strings.TrimSpace(os.Getenv(name)))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
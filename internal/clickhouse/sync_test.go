@@ -0,0 +1,336 @@
+package clickhouse_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/clickhouse"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/mocks"
+)
+
+// fakeClock reports each wait on waits and returns once the test sends on fire.
+type fakeClock struct {
+	waits chan time.Duration
+	fire  chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{waits: make(chan time.Duration), fire: make(chan time.Time)}
+}
+
+func (c *fakeClock) Now() time.Time { return time.Unix(1700000000, 0) }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits <- d
+	return c.fire
+}
+
+func (c *fakeClock) nextWait(t *testing.T) time.Duration {
+	t.Helper()
+	select {
+	case d := <-c.waits:
+		return d
+	case <-time.After(time.Second):
+		t.Fatal("syncer never waited")
+		return 0
+	}
+}
+
+// countingUpdates records how many times each player was updated.
+type countingUpdates struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (u *countingUpdates) update(playerID string, points int) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.calls[playerID]++
+	return nil
+}
+
+func (u *countingUpdates) count(playerID string) int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.calls[playerID]
+}
+
+func TestSyncerWaitsStartupDelayThenJitteredInterval(t *testing.T) {
+	logger.Init()
+	t.Setenv("CUDDLE_SYNC_INTERVAL", "1m")
+	t.Setenv("CUDDLE_SYNC_STARTUP_DELAY", "10s")
+	updates := &countingUpdates{calls: map[string]int{}}
+	clock := newFakeClock()
+	syncer := clickhouse.NewSyncer(mocks.NewMockClickHouseClient(), updates.update)
+	syncer.Clock = clock
+
+	syncer.Start(context.Background())
+	if wait := clock.nextWait(t); wait != 10*time.Second {
+		t.Fatalf("first wait = %v, want the 10s startup delay", wait)
+	}
+	if updates.count("1") != 0 {
+		t.Fatal("synced before the startup delay passed")
+	}
+
+	for run := 1; run <= 2; run++ {
+		clock.fire <- time.Time{}
+		wait := clock.nextWait(t)
+		if wait < 54*time.Second || wait > 66*time.Second {
+			t.Fatalf("wait after run %d = %v, want 1m ± 10%%", run, wait)
+		}
+		if updates.count("1") != run {
+			t.Fatalf("player 1 updated %d times after run %d", updates.count("1"), run)
+		}
+	}
+
+	syncer.Stop()
+	if lastSuccess, failures := syncer.Status(); lastSuccess.IsZero() || failures != 0 {
+		t.Fatalf("Status() = %v, %d; want a success and no failures", lastSuccess, failures)
+	}
+}
+
+// flakyClient fails its first failures syncs.
+type flakyClient struct {
+	*mocks.MockClickHouseClient
+	failures int
+	calls    int
+}
+
+func (f *flakyClient) SyncCuddlePoints(ctx context.Context, updateFunc func(playerID string, points int) error) (clickhouse.SyncResult, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return clickhouse.SyncResult{}, errors.New("clickhouse unavailable")
+	}
+	return f.MockClickHouseClient.SyncCuddlePoints(ctx, updateFunc)
+}
+
+func TestSyncerRetriesWithBackoffAndAlerts(t *testing.T) {
+	logger.Init()
+	t.Setenv("CUDDLE_SYNC_ALERT_AFTER", "1")
+	clock := newFakeClock()
+	client := &flakyClient{MockClickHouseClient: mocks.NewMockClickHouseClient(), failures: 2}
+	var alerts []int
+	syncer := clickhouse.NewSyncer(client, func(string, int) error { return nil })
+	syncer.Clock = clock
+	syncer.OnAlert = func(failures int, lastSuccess time.Time, err error) { alerts = append(alerts, failures) }
+
+	done := make(chan error)
+	go func() {
+		_, err := syncer.RunOnce(context.Background())
+		done <- err
+	}()
+	for _, want := range []time.Duration{syncer.Backoff, 2 * syncer.Backoff} {
+		if wait := clock.nextWait(t); wait != want {
+			t.Fatalf("backoff = %v, want %v", wait, want)
+		}
+		clock.fire <- time.Time{}
+	}
+	if err := <-done; err != nil || client.calls != 3 {
+		t.Fatalf("RunOnce() = %v after %d calls, want success on the third", err, client.calls)
+	}
+
+	// A run that exhausts its attempts fails and, at the threshold, alerts.
+	client.calls, client.failures = 0, 100
+	syncer.Attempts = 1
+	if _, err := syncer.RunOnce(context.Background()); err == nil {
+		t.Fatal("RunOnce() succeeded with ClickHouse down")
+	}
+	if _, failures := syncer.Status(); failures != 1 || len(alerts) != 1 || !syncer.Unhealthy() {
+		t.Fatalf("failures = %d, alerts = %v; want one failure and one alert", failures, alerts)
+	}
+}
+
+func TestSyncerKeepsTheMostRecentRuns(t *testing.T) {
+	logger.Init()
+	client := &flakyClient{MockClickHouseClient: mocks.NewMockClickHouseClient()}
+	syncer := clickhouse.NewSyncer(client, func(string, int) error { return nil })
+	syncer.Attempts = 1
+	syncer.History = 2
+
+	syncer.RunOnce(context.Background())
+	client.calls, client.failures = 0, 1
+	syncer.RunManually(context.Background())
+	client.calls, client.failures = 0, 0
+	syncer.RunManually(context.Background())
+
+	runs := syncer.Runs()
+	if len(runs) != 2 {
+		t.Fatalf("kept %d runs, want 2", len(runs))
+	}
+	if latest := runs[0]; latest.Trigger != clickhouse.TriggerManual || latest.Error != "" || latest.Updated == 0 {
+		t.Fatalf("latest run = %+v, want a successful manual run", latest)
+	}
+	if failed := runs[1]; failed.Error != "clickhouse unavailable" || failed.Errors == nil {
+		t.Fatalf("previous run = %+v, want the failed run", failed)
+	}
+}
+
+// slowClient holds each sync until release is closed.
+type slowClient struct {
+	*mocks.MockClickHouseClient
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c *slowClient) SyncCuddlePoints(ctx context.Context, updateFunc func(playerID string, points int) error) (clickhouse.SyncResult, error) {
+	c.started <- struct{}{}
+	<-c.release
+	return c.MockClickHouseClient.SyncCuddlePoints(ctx, updateFunc)
+}
+
+func TestSyncerSkipsRunsThatWouldOverlap(t *testing.T) {
+	logger.Init()
+	client := &slowClient{MockClickHouseClient: mocks.NewMockClickHouseClient(), started: make(chan struct{}), release: make(chan struct{})}
+	syncer := clickhouse.NewSyncer(client, func(string, int) error { return nil })
+
+	done := make(chan error)
+	go func() {
+		_, err := syncer.RunOnce(context.Background())
+		done <- err
+	}()
+	<-client.started
+
+	if _, err := syncer.RunManually(context.Background()); !errors.Is(err, clickhouse.ErrSyncInProgress) {
+		t.Fatalf("RunManually() during a run = %v, want ErrSyncInProgress", err)
+	}
+	if overlaps := syncer.Overlaps(); overlaps != 1 {
+		t.Fatalf("Overlaps() = %d, want 1", overlaps)
+	}
+
+	close(client.release)
+	if err := <-done; err != nil {
+		t.Fatalf("RunOnce() failed: %v", err)
+	}
+	if runs := syncer.Runs(); len(runs) != 1 || runs[0].Trigger != clickhouse.TriggerScheduled {
+		t.Fatalf("runs = %+v, want only the scheduled run", runs)
+	}
+
+	// Once the run finishes, the next one goes ahead.
+	go func() { <-client.started }()
+	if _, err := syncer.RunManually(context.Background()); err != nil {
+		t.Fatalf("RunManually() after the run = %v", err)
+	}
+}
+
+// fixedClient syncs the same points every run.
+type fixedClient struct {
+	*mocks.MockClickHouseClient
+	points map[string]int
+}
+
+func (c fixedClient) SyncCuddlePoints(ctx context.Context, updateFunc func(playerID string, points int) error) (clickhouse.SyncResult, error) {
+	return clickhouse.ApplyCuddlePoints(c.points, updateFunc), nil
+}
+
+func TestSyncerReportsOnlyRunsThatChangePoints(t *testing.T) {
+	logger.Init()
+	stored := map[string]int{"1": 10, "2": 5, "3": 40}
+	client := fixedClient{MockClickHouseClient: mocks.NewMockClickHouseClient(), points: map[string]int{"1": 10, "2": 20, "3": 30}}
+	syncer := clickhouse.NewSyncer(client, func(playerID string, points int) error {
+		stored[playerID] = points
+		return nil
+	})
+	syncer.Current = func() (map[string]int, error) {
+		current := make(map[string]int, len(stored))
+		for id, points := range stored {
+			current[id] = points
+		}
+		return current, nil
+	}
+	var reports [][]clickhouse.PointsChange
+	syncer.OnChanged = func(changes []clickhouse.PointsChange) { reports = append(reports, changes) }
+
+	if _, err := syncer.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce() failed: %v", err)
+	}
+	want := []clickhouse.PointsChange{{PlayerID: "2", Delta: 15}, {PlayerID: "3", Delta: -10}}
+	if len(reports) != 1 || fmt.Sprint(reports[0]) != fmt.Sprint(want) {
+		t.Fatalf("reported %v, want one report of %v", reports, want)
+	}
+
+	// Nothing moves the second time, so nothing is reported.
+	if _, err := syncer.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce() failed: %v", err)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("reported %v after an unchanged run, want no new report", reports)
+	}
+}
+
+func TestSyncerFollowsPointsSource(t *testing.T) {
+	logger.Init()
+	for _, tc := range []struct {
+		source  string
+		want    int
+		skipped int
+	}{
+		{"", 80, 0},
+		{"clickhouse", 80, 0},
+		{"manual", 40, 1},
+		{"blend", 60, 0},
+	} {
+		t.Run("POINTS_SOURCE="+tc.source, func(t *testing.T) {
+			t.Setenv("POINTS_SOURCE", tc.source)
+			// Player 1's 40 was set by hand; ClickHouse says 80
+			stored := map[string]int{"1": 40}
+			client := fixedClient{MockClickHouseClient: mocks.NewMockClickHouseClient(), points: map[string]int{"1": 80}}
+			syncer := clickhouse.NewSyncer(client, func(playerID string, points int) error {
+				stored[playerID] = points
+				return nil
+			})
+			syncer.Current = func() (map[string]int, error) { return map[string]int{"1": stored["1"]}, nil }
+
+			result, err := syncer.RunOnce(context.Background())
+			if err != nil {
+				t.Fatalf("RunOnce() failed: %v", err)
+			}
+			if stored["1"] != tc.want || result.Skipped != tc.skipped {
+				t.Fatalf("points = %d with %d skipped, want %d with %d skipped", stored["1"], result.Skipped, tc.want, tc.skipped)
+			}
+		})
+	}
+}
+
+func TestSyncerSkipsBlendingWithoutCurrentPoints(t *testing.T) {
+	logger.Init()
+	t.Setenv("POINTS_SOURCE", "blend")
+	updates := &countingUpdates{calls: map[string]int{}}
+	client := fixedClient{MockClickHouseClient: mocks.NewMockClickHouseClient(), points: map[string]int{"1": 80}}
+	syncer := clickhouse.NewSyncer(client, updates.update)
+	syncer.Current = func() (map[string]int, error) { return nil, errors.New("database is locked") }
+
+	result, err := syncer.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce() failed: %v", err)
+	}
+	if updates.count("1") != 0 || result.Skipped != 1 {
+		t.Fatalf("player 1 updated %d times with %d skipped, want it skipped untouched", updates.count("1"), result.Skipped)
+	}
+}
+
+func TestApplyCuddlePointsCarriesOnPastFailures(t *testing.T) {
+	points := map[string]int{"1": 10, "2": 20, "retired": 30, "3": 40}
+	var updated []string
+	result := clickhouse.ApplyCuddlePoints(points, func(playerID string, points int) error {
+		switch playerID {
+		case "retired":
+			return fmt.Errorf("%w: %s", clickhouse.ErrUnknownPlayer, playerID)
+		case "2":
+			return errors.New("database is locked")
+		}
+		updated = append(updated, playerID)
+		return nil
+	})
+
+	if result.Updated != 2 || result.Skipped != 1 || len(result.Errors) != 1 || result.Errors[0] != "2: database is locked" {
+		t.Fatalf("ApplyCuddlePoints() = %+v, want 2 updated, 1 skipped and player 2's error", result)
+	}
+	if len(updated) != 2 || updated[0] != "1" || updated[1] != "3" {
+		t.Fatalf("updated %v, want players 1 and 3 despite player 2 failing first", updated)
+	}
+}
@@ -0,0 +1,263 @@
+// Package config reads the service's settings from the environment once, at
+// startup. Load applies the defaults and reports every invalid or missing
+// setting together, so a bad deployment fails before anything connects.
+//
+// Settings can also come from a YAML or JSON file named by CONFIG_FILE, keyed
+// by the environment variable names; environment variables override it.
+//
+// Tuning knobs that only one package uses (CUDDLE_SYNC_INTERVAL,
+// MAX_ROSTER_SIZE and the like) are still read by that package.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Config holds the settings the service is wired from.
+type Config struct {
+	// Environment is "development" (or empty) for local runs; anything else
+	// is treated as production.
+	Environment string
+
+	DBDriver    string // memory, sqlite or postgres
+	SQLiteFile  string
+	DatabaseURL string
+
+	NATSURL     string
+	NATSSubject string
+
+	ClickHouse ClickHouseConfig
+
+	SessionStore   string // memory, db, redis or cookie
+	RedisURL       string
+	SessionSecrets []string // newest first
+
+	Port     int
+	GRPCPort int
+
+	// RoomCode is the draft room's join code; empty picks a random one.
+	RoomCode string
+	// PublicURL is the address join links use instead of the request's host.
+	PublicURL string
+
+	// HealthToken and HealthNetworks limit who sees dependency details in
+	// /api/health. With neither set everyone does.
+	HealthToken    string
+	HealthNetworks []*net.IPNet
+
+	// src is where Load found each setting, for Validate's errors.
+	src source
+}
+
+// ClickHouseConfig says whether and where to reach ClickHouse.
+type ClickHouseConfig struct {
+	Enabled  bool
+	Require  bool // fail startup rather than run without ClickHouse
+	UseMock  bool // use the in-memory mock in development
+	Addr     string
+	Database string
+	User     string
+	Password string
+}
+
+// Development reports whether cfg is for a local run.
+func (cfg Config) Development() bool {
+	return cfg.Environment == "" || cfg.Environment == "development"
+}
+
+// Load reads the Config from the environment, over CONFIG_FILE when set.
+// Storage defaults to Postgres in production and memory otherwise, pub/sub to
+// a local NATS server. File keys Load doesn't use are exported to the
+// environment for the packages that read them.
+func Load() (Config, error) {
+	src, err := loadFile(strings.TrimSpace(os.Getenv("CONFIG_FILE")))
+	if err != nil {
+		return Config{}, err
+	}
+
+	var errs []error
+	boolean := func(name string, fallback bool) bool {
+		value, err := src.boolean(name, fallback)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return value
+	}
+	port := func(name string, fallback int) int {
+		value, err := src.port(name, fallback)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return value
+	}
+
+	cfg := Config{
+		Environment: strings.TrimSpace(src.get("ENVIRONMENT")),
+		DBDriver:    strings.TrimSpace(src.get("DB_DRIVER")),
+		SQLiteFile:  src.or("SQLITE_FILE", "dev.sqlite"),
+		DatabaseURL: src.get("DATABASE_URL"),
+		NATSURL:     src.or("NATS_URL", "nats://localhost:4222"),
+		NATSSubject: src.or("NATS_SUBJECT", "draft.events"),
+		ClickHouse: ClickHouseConfig{
+			Enabled:  boolean("CLICKHOUSE_ENABLED", false),
+			Require:  boolean("REQUIRE_CLICKHOUSE", false),
+			UseMock:  boolean("USE_MOCK_CLICKHOUSE", true),
+			Addr:     src.or("CLICKHOUSE_ADDR", "localhost:9000"),
+			Database: src.or("CLICKHOUSE_DB", "default"),
+			User:     src.or("CLICKHOUSE_USER", "default"),
+			Password: src.get("CLICKHOUSE_PASSWORD"),
+		},
+		SessionStore:   strings.ToLower(strings.TrimSpace(src.get("SESSION_STORE"))),
+		RedisURL:       src.get("REDIS_URL"),
+		SessionSecrets: splitList(src.get("SESSION_SECRET")),
+		Port:           port("PORT", 3000),
+		GRPCPort:       port("GRPC_PORT", 50051),
+		RoomCode:       strings.TrimSpace(src.get("ROOM_CODE")),
+		PublicURL:      strings.TrimRight(strings.TrimSpace(src.get("PUBLIC_URL")), "/"),
+		HealthToken:    src.get("HEALTH_TOKEN"),
+		src:            src,
+	}
+
+	for _, cidr := range splitList(src.get("HEALTH_INTERNAL_NETWORKS")) {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %q is not a CIDR", src.origin("HEALTH_INTERNAL_NETWORKS"), cidr))
+			continue
+		}
+		cfg.HealthNetworks = append(cfg.HealthNetworks, network)
+	}
+
+	if cfg.DBDriver == "" {
+		if cfg.Environment == "production" {
+			cfg.DBDriver = "postgres"
+		} else {
+			cfg.DBDriver = "memory"
+		}
+	}
+	if cfg.DatabaseURL == "" {
+		cfg.DatabaseURL = src.postgresURL()
+	}
+
+	errs = append(errs, cfg.Validate(), src.exportRest())
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// Validate checks the settings that depend on each other, such as a database
+// URL for Postgres. Load calls it; tests that build a Config directly can too.
+func (cfg Config) Validate() error {
+	var errs []error
+	switch cfg.DBDriver {
+	case "memory", "sqlite":
+	case "postgres":
+		if cfg.DatabaseURL == "" {
+			errs = append(errs, errors.New("DATABASE_URL (or POSTGRES_HOST, POSTGRES_USER and POSTGRES_DB) is required for the postgres driver"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%s: unknown driver %q (valid: memory, sqlite, postgres)", cfg.src.origin("DB_DRIVER"), cfg.DBDriver))
+	}
+
+	switch cfg.SessionStore {
+	case "", "memory", "db":
+	case "redis":
+		if cfg.RedisURL == "" {
+			errs = append(errs, errors.New("REDIS_URL is required when SESSION_STORE=redis"))
+		}
+	case "cookie":
+		if len(cfg.SessionSecrets) == 0 {
+			errs = append(errs, errors.New("SESSION_SECRET is required when SESSION_STORE=cookie"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%s: unknown store %q (valid: memory, db, redis, cookie)", cfg.src.origin("SESSION_STORE"), cfg.SessionStore))
+	}
+
+	return errors.Join(errs...)
+}
+
+// or returns name's trimmed value, or fallback when unset.
+func (s source) or(name, fallback string) string {
+	if value := strings.TrimSpace(s.get(name)); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// boolean parses name with strconv.ParseBool, returning fallback when unset.
+func (s source) boolean(name string, fallback bool) (bool, error) {
+	raw := strings.TrimSpace(s.get(name))
+	if raw == "" {
+		return fallback, nil
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return fallback, fmt.Errorf("%s: %q is not true or false", s.origin(name), raw)
+	}
+	return value, nil
+}
+
+// port parses name as a TCP port, returning fallback when unset. 0 asks the
+// OS for a free port.
+func (s source) port(name string, fallback int) (int, error) {
+	raw := strings.TrimSpace(s.get(name))
+	if raw == "" {
+		return fallback, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 || value > 65535 {
+		return fallback, fmt.Errorf("%s: %q is not a port number", s.origin(name), raw)
+	}
+	return value, nil
+}
+
+// splitList splits a comma-separated value, dropping blank entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// postgresURL builds a connection string from the POSTGRES_* settings, or
+// returns "" when they are incomplete.
+func (s source) postgresURL() string {
+	host := s.get("POSTGRES_HOST")
+	user := s.get("POSTGRES_USER")
+	password := s.get("POSTGRES_PASSWORD")
+	database := s.get("POSTGRES_DB")
+	if host == "" || user == "" || database == "" {
+		return ""
+	}
+
+	port := s.get("POSTGRES_PORT")
+	if port == "" {
+		port = "5432"
+	}
+
+	sslMode := s.get("POSTGRES_SSLMODE")
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	postgresURL := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(user, password),
+		Host:   net.JoinHostPort(host, port),
+		Path:   database,
+	}
+	query := postgresURL.Query()
+	query.Set("sslmode", sslMode)
+	postgresURL.RawQuery = query.Encode()
+
+	return postgresURL.String()
+}
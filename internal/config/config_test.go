@@ -0,0 +1,242 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// clearEnv blanks every variable Load reads so the host environment cannot
+// leak into a test.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{
+		"ENVIRONMENT", "DB_DRIVER", "SQLITE_FILE", "DATABASE_URL",
+		"POSTGRES_HOST", "POSTGRES_PORT", "POSTGRES_USER", "POSTGRES_PASSWORD", "POSTGRES_DB", "POSTGRES_SSLMODE",
+		"NATS_URL", "NATS_SUBJECT",
+		"CLICKHOUSE_ENABLED", "REQUIRE_CLICKHOUSE", "USE_MOCK_CLICKHOUSE",
+		"CLICKHOUSE_ADDR", "CLICKHOUSE_DB", "CLICKHOUSE_USER", "CLICKHOUSE_PASSWORD",
+		"SESSION_STORE", "REDIS_URL", "SESSION_SECRET",
+		"PORT", "GRPC_PORT", "ROOM_CODE", "PUBLIC_URL",
+		"HEALTH_TOKEN", "HEALTH_INTERNAL_NETWORKS", "CONFIG_FILE",
+	} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	clearEnv(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if !cfg.Development() {
+		t.Fatalf("Development() = false, want true with ENVIRONMENT unset")
+	}
+	if cfg.DBDriver != "memory" || cfg.SQLiteFile != "dev.sqlite" {
+		t.Fatalf("storage = %q/%q, want memory/dev.sqlite", cfg.DBDriver, cfg.SQLiteFile)
+	}
+	if cfg.NATSURL != "nats://localhost:4222" || cfg.NATSSubject != "draft.events" {
+		t.Fatalf("nats = %q/%q, want the local defaults", cfg.NATSURL, cfg.NATSSubject)
+	}
+	if cfg.Port != 3000 || cfg.GRPCPort != 50051 {
+		t.Fatalf("ports = %d/%d, want 3000/50051", cfg.Port, cfg.GRPCPort)
+	}
+	want := ClickHouseConfig{UseMock: true, Addr: "localhost:9000", Database: "default", User: "default"}
+	if cfg.ClickHouse != want {
+		t.Fatalf("ClickHouse = %+v, want %+v", cfg.ClickHouse, want)
+	}
+	if cfg.HealthNetworks != nil || cfg.SessionSecrets != nil {
+		t.Fatalf("lists = %v/%v, want nil", cfg.HealthNetworks, cfg.SessionSecrets)
+	}
+}
+
+func TestLoadProductionRequiresDatabase(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("ENVIRONMENT", "production")
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "DATABASE_URL") {
+		t.Fatalf("Load() error = %v, want a missing DATABASE_URL error", err)
+	}
+
+	t.Setenv("POSTGRES_HOST", "db")
+	t.Setenv("POSTGRES_USER", "draft")
+	t.Setenv("POSTGRES_PASSWORD", "p@ss")
+	t.Setenv("POSTGRES_DB", "jellycat")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.DBDriver != "postgres" || cfg.DatabaseURL != "postgres://draft:p%40ss@db:5432/jellycat?sslmode=disable" {
+		t.Fatalf("storage = %q %q, want postgres from the POSTGRES_* variables", cfg.DBDriver, cfg.DatabaseURL)
+	}
+}
+
+func TestLoadRequiresSessionStoreSettings(t *testing.T) {
+	for store, want := range map[string]string{
+		"redis":  "REDIS_URL",
+		"cookie": "SESSION_SECRET",
+		"disk":   "unknown store",
+	} {
+		t.Run(store, func(t *testing.T) {
+			clearEnv(t)
+			t.Setenv("SESSION_STORE", store)
+			if _, err := Load(); err == nil || !strings.Contains(err.Error(), want) {
+				t.Fatalf("Load() error = %v, want it to mention %q", err, want)
+			}
+		})
+	}
+}
+
+func TestLoadParsesTypes(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("CLICKHOUSE_ENABLED", "1")
+	t.Setenv("USE_MOCK_CLICKHOUSE", "false")
+	t.Setenv("PORT", "8080")
+	t.Setenv("GRPC_PORT", "0")
+	t.Setenv("SESSION_STORE", "Cookie")
+	t.Setenv("SESSION_SECRET", "new, old,")
+	t.Setenv("HEALTH_INTERNAL_NETWORKS", "10.0.0.0/8, 192.168.0.0/16")
+	t.Setenv("PUBLIC_URL", "https://draft.example.com/")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if !cfg.ClickHouse.Enabled || cfg.ClickHouse.UseMock {
+		t.Fatalf("ClickHouse = %+v, want enabled without the mock", cfg.ClickHouse)
+	}
+	if cfg.Port != 8080 || cfg.GRPCPort != 0 {
+		t.Fatalf("ports = %d/%d, want 8080/0", cfg.Port, cfg.GRPCPort)
+	}
+	if cfg.SessionStore != "cookie" || len(cfg.SessionSecrets) != 2 || cfg.SessionSecrets[1] != "old" {
+		t.Fatalf("sessions = %q %q, want cookie with two secrets", cfg.SessionStore, cfg.SessionSecrets)
+	}
+	if len(cfg.HealthNetworks) != 2 || cfg.HealthNetworks[1].String() != "192.168.0.0/16" {
+		t.Fatalf("HealthNetworks = %v, want both networks", cfg.HealthNetworks)
+	}
+	if cfg.PublicURL != "https://draft.example.com" {
+		t.Fatalf("PublicURL = %q, want the trailing slash trimmed", cfg.PublicURL)
+	}
+}
+
+func TestLoadReportsEveryInvalidValue(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("PORT", "http")
+	t.Setenv("GRPC_PORT", "70000")
+	t.Setenv("REQUIRE_CLICKHOUSE", "sometimes")
+	t.Setenv("HEALTH_INTERNAL_NETWORKS", "10.0.0.0")
+	t.Setenv("DB_DRIVER", "mysql")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("Load() succeeded, want errors")
+	}
+	for _, want := range []string{"PORT", "GRPC_PORT", "REQUIRE_CLICKHOUSE", "HEALTH_INTERNAL_NETWORKS", "DB_DRIVER"} {
+		if !strings.Contains(err.Error(), want+":") {
+			t.Errorf("Load() error = %v, want it to mention %s", err, want)
+		}
+	}
+}
+
+// useConfigFile writes body to a file called name and points CONFIG_FILE at it.
+func useConfigFile(t *testing.T, name, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	return path
+}
+
+func TestLoadLayersEnvironmentOverConfigFile(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("MAX_ROSTER_SIZE", "")
+	useConfigFile(t, "dev-config.yaml", `
+port: 8080
+grpc_port: 9090
+clickhouse:
+  enabled: true
+  addr: clickhouse:9000
+session_store: cookie
+session_secret: [new, old]
+max_roster_size: 9
+`)
+	t.Setenv("PORT", "3100")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Port != 3100 || cfg.GRPCPort != 9090 {
+		t.Fatalf("ports = %d/%d, want PORT from the environment and GRPC_PORT from the file", cfg.Port, cfg.GRPCPort)
+	}
+	if !cfg.ClickHouse.Enabled || cfg.ClickHouse.Addr != "clickhouse:9000" || cfg.ClickHouse.Database != "default" {
+		t.Fatalf("ClickHouse = %+v, want nested file keys over the defaults", cfg.ClickHouse)
+	}
+	if cfg.SessionStore != "cookie" || len(cfg.SessionSecrets) != 2 || cfg.SessionSecrets[1] != "old" {
+		t.Fatalf("sessions = %q %q, want the file's list of secrets", cfg.SessionStore, cfg.SessionSecrets)
+	}
+	if got := os.Getenv("MAX_ROSTER_SIZE"); got != "9" {
+		t.Fatalf("MAX_ROSTER_SIZE = %q, want the file value exported for the package that reads it", got)
+	}
+}
+
+func TestLoadErrorsNameTheFileKey(t *testing.T) {
+	clearEnv(t)
+	path := useConfigFile(t, "config.json", `{"port": "http", "clickhouse": {"enabled": "sometimes"}, "db_driver": "mysql"}`)
+	t.Setenv("GRPC_PORT", "70000")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("Load() succeeded, want errors")
+	}
+	for _, want := range []string{path + ": port:", path + ": clickhouse.enabled:", path + ": db_driver:", "GRPC_PORT:"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Load() error = %v, want it to mention %q", err, want)
+		}
+	}
+
+	useConfigFile(t, "config.toml", "port = 1")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "CONFIG_FILE") {
+		t.Fatalf("Load() error = %v, want an unsupported CONFIG_FILE error", err)
+	}
+}
+
+func TestWriteYAMLRedactsSecretsAndLoadsBack(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("DATABASE_URL", "postgres://draft:s3cret@db:5432/jellycat")
+	t.Setenv("CLICKHOUSE_PASSWORD", "hunter2")
+	t.Setenv("SESSION_SECRET", "new,old")
+	t.Setenv("HEALTH_TOKEN", "probe-token")
+	t.Setenv("HEALTH_INTERNAL_NETWORKS", "10.0.0.0/8")
+	t.Setenv("PORT", "8080")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	var printed bytes.Buffer
+	if err := cfg.WriteYAML(&printed); err != nil {
+		t.Fatalf("WriteYAML() failed: %v", err)
+	}
+	for _, secret := range []string{"s3cret", "hunter2", "new", "probe-token"} {
+		if strings.Contains(printed.String(), secret) {
+			t.Fatalf("printed config leaks %q:\n%s", secret, printed.String())
+		}
+	}
+
+	clearEnv(t)
+	useConfigFile(t, "printed.yaml", printed.String())
+	reloaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() of the printed config failed: %v\n%s", err, printed.String())
+	}
+	if reloaded.Port != 8080 || reloaded.DatabaseURL != "postgres://draft:REDACTED@db:5432/jellycat" || reloaded.HealthNetworks[0].String() != "10.0.0.0/8" {
+		t.Fatalf("reloaded = %+v, want the printed settings", reloaded)
+	}
+}
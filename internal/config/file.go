@@ -0,0 +1,158 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// source looks settings up by their environment variable name. A non-empty
+// environment variable wins over the CONFIG_FILE value, which wins over the
+// caller's default.
+type source struct {
+	path string
+	// file holds CONFIG_FILE values by environment variable name; keys
+	// holds the key each was written as, for error messages.
+	file map[string]string
+	keys map[string]string
+	// read records the names Load asked for.
+	read map[string]bool
+}
+
+// get returns name's value, or "" when neither the environment nor the file
+// sets it.
+func (s source) get(name string) string {
+	s.read[name] = true
+	if value := os.Getenv(name); strings.TrimSpace(value) != "" {
+		return value
+	}
+	return s.file[name]
+}
+
+// origin names where name's value came from, such as "PORT" or
+// "config.yaml: port", for error messages.
+func (s source) origin(name string) string {
+	if strings.TrimSpace(os.Getenv(name)) == "" {
+		if key, ok := s.keys[name]; ok {
+			return s.path + ": " + key
+		}
+	}
+	return name
+}
+
+// loadFile reads a YAML or JSON config file. Keys are environment variable
+// names in any case (db_driver for DB_DRIVER); nested maps join their keys
+// with underscores, so clickhouse: {addr: ...} sets CLICKHOUSE_ADDR. Lists
+// become comma-separated values.
+func loadFile(path string) (source, error) {
+	s := source{path: path, file: map[string]string{}, keys: map[string]string{}, read: map[string]bool{}}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s, fmt.Errorf("CONFIG_FILE: %w", err)
+	}
+	var values map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".json":
+		err = json.Unmarshal(data, &values)
+	default:
+		return s, fmt.Errorf("CONFIG_FILE: %s is not a .yaml, .yml or .json file", path)
+	}
+	if err != nil {
+		return s, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := s.add("", "", values); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// add flattens values under the name and key prefixes.
+func (s source) add(namePrefix, keyPrefix string, values map[string]interface{}) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name := namePrefix + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+		fullKey := keyPrefix + key
+		switch value := values[key].(type) {
+		case map[string]interface{}:
+			if err := s.add(name+"_", fullKey+".", value); err != nil {
+				return err
+			}
+			continue
+		case []interface{}:
+			items := make([]string, len(value))
+			for i, item := range value {
+				text, err := scalar(item)
+				if err != nil {
+					return fmt.Errorf("%s: %s: %w", s.path, fullKey, err)
+				}
+				items[i] = text
+			}
+			s.file[name] = strings.Join(items, ",")
+		default:
+			text, err := scalar(value)
+			if err != nil {
+				return fmt.Errorf("%s: %s: %w", s.path, fullKey, err)
+			}
+			s.file[name] = text
+		}
+		if previous, ok := s.keys[name]; ok {
+			return fmt.Errorf("%s: %s and %s both set %s", s.path, previous, fullKey, name)
+		}
+		s.keys[name] = fullKey
+	}
+	return nil
+}
+
+func scalar(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case int:
+		return strconv.Itoa(value), nil
+	case int64:
+		return strconv.FormatInt(value, 10), nil
+	case uint64:
+		return strconv.FormatUint(value, 10), nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v", value)
+	}
+}
+
+// exportRest sets the environment variables for file values Load doesn't
+// read itself, such as MAX_ROSTER_SIZE, so the packages that read them see
+// the file too. Variables already set are left alone.
+func (s source) exportRest() error {
+	for name, value := range s.file {
+		if s.read[name] || strings.TrimSpace(os.Getenv(name)) != "" {
+			continue
+		}
+		if err := os.Setenv(name, value); err != nil {
+			return fmt.Errorf("%s: %s: %w", s.path, s.keys[name], err)
+		}
+	}
+	return nil
+}
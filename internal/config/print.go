@@ -0,0 +1,85 @@
+package config
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// redacted replaces secrets in WriteYAML output.
+const redacted = "REDACTED"
+
+// WriteYAML writes cfg as a CONFIG_FILE would set it, with passwords,
+// tokens and secrets redacted, for --print-config.
+func (cfg Config) WriteYAML(w io.Writer) error {
+	networks := make([]string, len(cfg.HealthNetworks))
+	for i, network := range cfg.HealthNetworks {
+		networks[i] = network.String()
+	}
+	settings := []struct {
+		key   string
+		value interface{}
+	}{
+		{"environment", cfg.Environment},
+		{"db_driver", cfg.DBDriver},
+		{"sqlite_file", cfg.SQLiteFile},
+		{"database_url", redactURL(cfg.DatabaseURL)},
+		{"nats_url", redactURL(cfg.NATSURL)},
+		{"nats_subject", cfg.NATSSubject},
+		{"clickhouse_enabled", cfg.ClickHouse.Enabled},
+		{"require_clickhouse", cfg.ClickHouse.Require},
+		{"use_mock_clickhouse", cfg.ClickHouse.UseMock},
+		{"clickhouse_addr", cfg.ClickHouse.Addr},
+		{"clickhouse_db", cfg.ClickHouse.Database},
+		{"clickhouse_user", cfg.ClickHouse.User},
+		{"clickhouse_password", redact(cfg.ClickHouse.Password)},
+		{"session_store", cfg.SessionStore},
+		{"redis_url", redactURL(cfg.RedisURL)},
+		{"session_secret", redact(strings.Join(cfg.SessionSecrets, ","))},
+		{"port", cfg.Port},
+		{"grpc_port", cfg.GRPCPort},
+		{"room_code", cfg.RoomCode},
+		{"public_url", cfg.PublicURL},
+		{"health_token", redact(cfg.HealthToken)},
+		{"health_internal_networks", strings.Join(networks, ",")},
+	}
+
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	for _, setting := range settings {
+		value := &yaml.Node{}
+		if err := value.Encode(setting.value); err != nil {
+			return err
+		}
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: setting.key}, value)
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redacted
+}
+
+// redactURL hides a URL's password, or the whole value if it doesn't parse.
+func redactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return redacted
+	}
+	if _, ok := parsed.User.Password(); ok {
+		parsed.User = url.UserPassword(parsed.User.Username(), redacted)
+	}
+	return parsed.String()
+}
@@ -0,0 +1,433 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/draft"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// auctionKey is the draft_settings row holding the auction as JSON. Reset
+// clears draft_settings, so a reset starts a fresh auction.
+const auctionKey = "auction"
+
+// checkAuctionOpen rejects auction moves unless DRAFT_MODE=auction and the
+// draft window is open.
+func checkAuctionOpen(window models.DraftWindow) error {
+	if !draft.AuctionEnabled() {
+		return ErrAuctionDisabled
+	}
+	return checkDraftWindow(window, time.Now())
+}
+
+// loadAuction reads the auction from draft_settings, starting a new one with
+// the AUCTION_BUDGET when none is stored. The query has no placeholders, so
+// it works on every SQL backend.
+func loadAuction(q sqlQueryer) (*draft.Auction, error) {
+	rows, err := q.Query(`SELECT value FROM draft_settings WHERE key = '` + auctionKey + `'`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var value string
+	if rows.Next() {
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if value == "" {
+		return draft.NewAuction(draft.AuctionBudget()), nil
+	}
+
+	auction := &draft.Auction{}
+	if err := json.Unmarshal([]byte(value), auction); err != nil {
+		return nil, fmt.Errorf("failed to decode auction state: %w", err)
+	}
+	if auction.Sales == nil {
+		auction.Sales = make(map[string]draft.Sale)
+	}
+	return auction, nil
+}
+
+// refundAuction returns the price paid for playerID to its team when the pick
+// is undone. Drafts that never ran an auction are left alone.
+func refundAuction(tx *sql.Tx, playerID string, save func(tx *sql.Tx, auction *draft.Auction) error) error {
+	auction, err := loadAuction(tx)
+	if err != nil {
+		return err
+	}
+	if _, ok := auction.Sales[playerID]; !ok {
+		return nil
+	}
+	auction.Refund(playerID)
+	return save(tx, auction)
+}
+
+// Memory
+
+// currentAuction returns the running auction, or a fresh one that isn't kept
+// until a nomination changes it.
+func (m *MemoryDAL) currentAuction() *draft.Auction {
+	if m.auction != nil {
+		return m.auction
+	}
+	return draft.NewAuction(draft.AuctionBudget())
+}
+
+func (m *MemoryDAL) NominatePlayer(playerID, teamID string, openingBid int) (*models.AuctionNomination, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := checkAuctionOpen(m.window); err != nil {
+		return nil, err
+	}
+	player, team := m.findPlayerUnsafe(playerID), m.findTeamUnsafe(teamID)
+	if player == nil {
+		return nil, ErrPlayerNotFound
+	}
+	if team == nil {
+		return nil, ErrTeamNotFound
+	}
+	if player.Drafted {
+		return nil, ErrPlayerAlreadyDrafted
+	}
+	if err := checkRosterSize(team.Name, len(team.Players)); err != nil {
+		return nil, err
+	}
+
+	auction := m.currentAuction()
+	if err := auction.Nominate(playerID, teamID, openingBid, time.Now()); err != nil {
+		return nil, auctionError(err)
+	}
+	m.auction = auction
+	m.version++
+
+	nomination := *auction.Nomination
+	return &nomination, nil
+}
+
+func (m *MemoryDAL) PlaceBid(playerID, teamID string, amount int) (*models.AuctionNomination, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := checkAuctionOpen(m.window); err != nil {
+		return nil, err
+	}
+	team := m.findTeamUnsafe(teamID)
+	if team == nil {
+		return nil, ErrTeamNotFound
+	}
+	if err := checkRosterSize(team.Name, len(team.Players)); err != nil {
+		return nil, err
+	}
+
+	auction := m.currentAuction()
+	if err := auction.Bid(playerID, teamID, amount); err != nil {
+		return nil, auctionError(err)
+	}
+	m.version++
+
+	nomination := *auction.Nomination
+	return &nomination, nil
+}
+
+func (m *MemoryDAL) AwardPlayer() (*models.AuctionNomination, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := checkAuctionOpen(m.window); err != nil {
+		return nil, err
+	}
+	auction := m.currentAuction()
+	if auction.Nomination == nil {
+		return nil, auctionError(draft.ErrNoNomination)
+	}
+	if err := m.draftPlayerUnsafe(auction.Nomination.PlayerID, auction.Nomination.HighBidder, false); err != nil {
+		return nil, err
+	}
+
+	won, err := auction.Award()
+	if err != nil {
+		return nil, auctionError(err)
+	}
+	return &won, nil
+}
+
+func (m *MemoryDAL) findPlayerUnsafe(id string) *models.Player {
+	for i := range m.players {
+		if m.players[i].ID == id {
+			return &m.players[i]
+		}
+	}
+	return nil
+}
+
+func (m *MemoryDAL) findTeamUnsafe(id string) *models.Team {
+	for i := range m.teams {
+		if m.teams[i].ID == id {
+			return &m.teams[i]
+		}
+	}
+	return nil
+}
+
+// SQLite
+
+func sqliteSaveAuction(tx *sql.Tx, auction *draft.Auction) error {
+	value, err := json.Marshal(auction)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO draft_settings (key, value)
+		VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value
+	`, auctionKey, string(value))
+	return err
+}
+
+func (s *SQLiteDAL) NominatePlayer(playerID, teamID string, openingBid int) (*models.AuctionNomination, error) {
+	return s.updateAuction(func(tx *sql.Tx, auction *draft.Auction) error {
+		var drafted int
+		err := tx.QueryRow(`SELECT drafted FROM players WHERE id = ?`, playerID).Scan(&drafted)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPlayerNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if drafted == 1 {
+			return ErrPlayerAlreadyDrafted
+		}
+		if err := sqliteCheckBidder(tx, teamID); err != nil {
+			return err
+		}
+		return auctionError(auction.Nominate(playerID, teamID, openingBid, time.Now()))
+	})
+}
+
+func (s *SQLiteDAL) PlaceBid(playerID, teamID string, amount int) (*models.AuctionNomination, error) {
+	return s.updateAuction(func(tx *sql.Tx, auction *draft.Auction) error {
+		if err := sqliteCheckBidder(tx, teamID); err != nil {
+			return err
+		}
+		return auctionError(auction.Bid(playerID, teamID, amount))
+	})
+}
+
+func (s *SQLiteDAL) AwardPlayer() (*models.AuctionNomination, error) {
+	var won models.AuctionNomination
+	_, err := s.updateAuction(func(tx *sql.Tx, auction *draft.Auction) error {
+		if auction.Nomination == nil {
+			return auctionError(draft.ErrNoNomination)
+		}
+		if err := s.draftPlayerTx(tx, auction.Nomination.PlayerID, auction.Nomination.HighBidder, false); err != nil {
+			return err
+		}
+		var err error
+		won, err = auction.Award()
+		return auctionError(err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &won, nil
+}
+
+// updateAuction applies change to the stored auction in one transaction and
+// returns the nomination it leaves open, if any.
+func (s *SQLiteDAL) updateAuction(change func(tx *sql.Tx, auction *draft.Auction) error) (*models.AuctionNomination, error) {
+	s.pickMu.Lock()
+	defer s.pickMu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	window, err := loadDraftWindow(tx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAuctionOpen(window); err != nil {
+		return nil, err
+	}
+	auction, err := loadAuction(tx)
+	if err != nil {
+		return nil, err
+	}
+	if err := change(tx, auction); err != nil {
+		return nil, err
+	}
+	if err := sqliteSaveAuction(tx, auction); err != nil {
+		return nil, err
+	}
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if auction.Nomination == nil {
+		return nil, nil
+	}
+	nomination := *auction.Nomination
+	return &nomination, nil
+}
+
+// sqliteCheckBidder checks teamID exists and has room for another player.
+func sqliteCheckBidder(tx *sql.Tx, teamID string) error {
+	var teamName string
+	err := tx.QueryRow(`SELECT name FROM teams WHERE id = ?`, teamID).Scan(&teamName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTeamNotFound
+	}
+	if err != nil {
+		return err
+	}
+	var rosterSize int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM team_players WHERE team_id = ?`, teamID).Scan(&rosterSize); err != nil {
+		return err
+	}
+	return checkRosterSize(teamName, rosterSize)
+}
+
+// Postgres
+
+func postgresSaveAuction(ctx context.Context) func(tx *sql.Tx, auction *draft.Auction) error {
+	return func(tx *sql.Tx, auction *draft.Auction) error {
+		value, err := json.Marshal(auction)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO draft_settings (key, value, updated_at)
+			VALUES ($1, $2, CURRENT_TIMESTAMP)
+			ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_at = CURRENT_TIMESTAMP
+		`, auctionKey, string(value))
+		return err
+	}
+}
+
+func (p *PostgresDAL) NominatePlayer(playerID, teamID string, openingBid int) (*models.AuctionNomination, error) {
+	return p.updateAuction(func(ctx context.Context, tx *sql.Tx, auction *draft.Auction) error {
+		var drafted bool
+		err := tx.QueryRowContext(ctx, `SELECT drafted FROM players WHERE id = $1`, playerID).Scan(&drafted)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPlayerNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if drafted {
+			return ErrPlayerAlreadyDrafted
+		}
+		if err := postgresCheckBidder(ctx, tx, teamID); err != nil {
+			return err
+		}
+		return auctionError(auction.Nominate(playerID, teamID, openingBid, time.Now()))
+	})
+}
+
+func (p *PostgresDAL) PlaceBid(playerID, teamID string, amount int) (*models.AuctionNomination, error) {
+	return p.updateAuction(func(ctx context.Context, tx *sql.Tx, auction *draft.Auction) error {
+		if err := postgresCheckBidder(ctx, tx, teamID); err != nil {
+			return err
+		}
+		return auctionError(auction.Bid(playerID, teamID, amount))
+	})
+}
+
+func (p *PostgresDAL) AwardPlayer() (*models.AuctionNomination, error) {
+	var won models.AuctionNomination
+	_, err := p.updateAuction(func(ctx context.Context, tx *sql.Tx, auction *draft.Auction) error {
+		if auction.Nomination == nil {
+			return auctionError(draft.ErrNoNomination)
+		}
+		if err := p.draftPlayerTx(ctx, tx, auction.Nomination.PlayerID, auction.Nomination.HighBidder, false); err != nil {
+			return err
+		}
+		var err error
+		won, err = auction.Award()
+		return auctionError(err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &won, nil
+}
+
+// updateAuction applies change to the stored auction in one transaction and
+// returns the nomination it leaves open, if any. The pick counter lock
+// serializes it with picks, undos and other bids.
+func (p *PostgresDAL) updateAuction(change func(ctx context.Context, tx *sql.Tx, auction *draft.Auction) error) (*models.AuctionNomination, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockPickCounter(ctx, tx); err != nil {
+		return nil, err
+	}
+	window, err := loadDraftWindow(tx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAuctionOpen(window); err != nil {
+		return nil, err
+	}
+	auction, err := loadAuction(tx)
+	if err != nil {
+		return nil, err
+	}
+	if err := change(ctx, tx, auction); err != nil {
+		return nil, err
+	}
+	if err := postgresSaveAuction(ctx)(tx, auction); err != nil {
+		return nil, err
+	}
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if auction.Nomination == nil {
+		return nil, nil
+	}
+	nomination := *auction.Nomination
+	return &nomination, nil
+}
+
+// postgresCheckBidder checks teamID exists and has room for another player.
+func postgresCheckBidder(ctx context.Context, tx *sql.Tx, teamID string) error {
+	var teamName string
+	err := tx.QueryRowContext(ctx, `SELECT name FROM teams WHERE id = $1`, teamID).Scan(&teamName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTeamNotFound
+	}
+	if err != nil {
+		return err
+	}
+	var rosterSize int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM team_players WHERE team_id = $1`, teamID).Scan(&rosterSize); err != nil {
+		return err
+	}
+	return checkRosterSize(teamName, rosterSize)
+}
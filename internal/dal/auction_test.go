@@ -0,0 +1,137 @@
+package dal
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/draft"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// assertAuctionCycle runs a nominate→bid→award cycle between two teams and
+// checks budgets are enforced, charged and refunded on undo.
+func assertAuctionCycle(t *testing.T, store DraftDAL) {
+	t.Helper()
+	t.Setenv("DRAFT_MODE", "auction")
+	t.Setenv("AUCTION_BUDGET", "50")
+
+	var teams []*models.Team
+	for _, name := range []string{"Bidders A", "Bidders B"} {
+		team, err := store.AddTeam(name, "", "", "")
+		if err != nil {
+			t.Fatalf("AddTeam() failed: %v", err)
+		}
+		teams = append(teams, team)
+	}
+	player, err := store.AddPlayer(&models.Player{Name: "Auction Bun", Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+	a, b := teams[0].ID, teams[1].ID
+
+	if err := store.DraftPlayer(player.ID, a); !errors.Is(err, ErrAuctionPick) {
+		t.Fatalf("DraftPlayer() in an auction = %v, want %v", err, ErrAuctionPick)
+	}
+	if _, err := store.PlaceBid(player.ID, b, 5); !errors.Is(err, draft.ErrNoNomination) || !errors.Is(err, ErrValidation) {
+		t.Fatalf("PlaceBid() before a nomination = %v, want %v", err, draft.ErrNoNomination)
+	}
+	if _, err := store.NominatePlayer(player.ID, a, 51); !errors.Is(err, draft.ErrOverBudget) {
+		t.Fatalf("NominatePlayer() over budget = %v, want %v", err, draft.ErrOverBudget)
+	}
+
+	nomination, err := store.NominatePlayer(player.ID, a, 5)
+	if err != nil {
+		t.Fatalf("NominatePlayer() failed: %v", err)
+	}
+	if nomination.HighBidder != a || nomination.HighBid != 5 {
+		t.Fatalf("nomination = %+v, want team A opening at 5", nomination)
+	}
+	if _, err := store.NominatePlayer(player.ID, b, 1); !errors.Is(err, draft.ErrNominationOpen) {
+		t.Fatalf("second NominatePlayer() = %v, want %v", err, draft.ErrNominationOpen)
+	}
+	if _, err := store.PlaceBid(player.ID, b, 5); !errors.Is(err, draft.ErrBidTooLow) {
+		t.Fatalf("PlaceBid() matching the high bid = %v, want %v", err, draft.ErrBidTooLow)
+	}
+	if _, err := store.PlaceBid(player.ID, b, 60); !errors.Is(err, draft.ErrOverBudget) {
+		t.Fatalf("PlaceBid() over budget = %v, want %v", err, draft.ErrOverBudget)
+	}
+	if nomination, err = store.PlaceBid(player.ID, b, 12); err != nil || nomination.HighBidder != b {
+		t.Fatalf("PlaceBid() = %+v, %v; want team B leading", nomination, err)
+	}
+
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	if state.Auction == nil || state.Auction.HighBid != 12 {
+		t.Fatalf("state.Auction = %+v, want the open nomination at 12", state.Auction)
+	}
+
+	won, err := store.AwardPlayer()
+	if err != nil {
+		t.Fatalf("AwardPlayer() failed: %v", err)
+	}
+	if won.PlayerID != player.ID || won.HighBidder != b || won.HighBid != 12 {
+		t.Fatalf("AwardPlayer() = %+v, want the player sold to B for 12", won)
+	}
+	if _, err := store.AwardPlayer(); !errors.Is(err, draft.ErrNoNomination) {
+		t.Fatalf("AwardPlayer() with nothing nominated = %v, want %v", err, draft.ErrNoNomination)
+	}
+
+	budgets := func() map[string]int {
+		t.Helper()
+		state, err := store.GetState()
+		if err != nil {
+			t.Fatalf("GetState() failed: %v", err)
+		}
+		if state.Auction != nil {
+			t.Fatalf("state.Auction = %+v after the award, want none", state.Auction)
+		}
+		budgets := map[string]int{}
+		for _, team := range state.Teams {
+			if team.Budget != nil {
+				budgets[team.ID] = *team.Budget
+			}
+			budgets[team.ID+" roster"] = len(team.Players)
+		}
+		return budgets
+	}
+	if got := budgets(); got[a] != 50 || got[b] != 38 || got[b+" roster"] != 1 {
+		t.Fatalf("budgets after the award = %v, want A 50 and B 38 with the player on B", got)
+	}
+
+	if _, err := store.UndoLastPick(); err != nil {
+		t.Fatalf("UndoLastPick() failed: %v", err)
+	}
+	if got := budgets(); got[b] != 50 || got[b+" roster"] != 0 {
+		t.Fatalf("budgets after undo = %v, want B's 12 refunded", got)
+	}
+}
+
+func TestMemoryAuctionCycle(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertAuctionCycle(t, NewMemoryDAL())
+}
+
+func TestSQLiteAuctionCycle(t *testing.T) {
+	assertAuctionCycle(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresAuctionCycle(t *testing.T) {
+	assertAuctionCycle(t, newTestPostgresDAL(t))
+}
+
+func TestAuctionMethodsRequireAuctionMode(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	store := NewMemoryDAL()
+	if _, err := store.NominatePlayer("p", "t", 1); !errors.Is(err, ErrAuctionDisabled) {
+		t.Fatalf("NominatePlayer() without DRAFT_MODE=auction = %v, want %v", err, ErrAuctionDisabled)
+	}
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	if state.Auction != nil {
+		t.Fatalf("state.Auction = %+v outside an auction draft", state.Auction)
+	}
+}
@@ -0,0 +1,152 @@
+package dal
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/ids"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+const authEventColumns = `id, type, user_id, username, ip, user_agent, detail, impersonator_id, created_at`
+
+// maxMemoryAuthEvents bounds the in-memory audit log; the oldest events are dropped first.
+const maxMemoryAuthEvents = 10000
+
+func (f AuthEventFilter) matches(event models.AuthEvent) bool {
+	return (f.Type == "" || event.Type == f.Type) &&
+		(f.User == "" || event.UserID == f.User || event.Username == f.User || event.ImpersonatorID == f.User) &&
+		(f.IP == "" || event.IP == f.IP) &&
+		(f.Since == 0 || event.CreatedAt >= f.Since) &&
+		(f.Until == 0 || event.CreatedAt <= f.Until)
+}
+
+// whereClause renders the filter as SQL, numbering placeholders with placeholder.
+func (f AuthEventFilter) whereClause(placeholder func(n int) string) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, values ...interface{}) {
+		for _, value := range values {
+			condition = strings.Replace(condition, "?", placeholder(len(args)+1), 1)
+			args = append(args, value)
+		}
+		conditions = append(conditions, condition)
+	}
+
+	if f.Type != "" {
+		add("type = ?", f.Type)
+	}
+	if f.User != "" {
+		add("(user_id = ? OR username = ? OR impersonator_id = ?)", f.User, f.User, f.User)
+	}
+	if f.IP != "" {
+		add("ip = ?", f.IP)
+	}
+	if f.Since != 0 {
+		add("created_at >= ?", f.Since)
+	}
+	if f.Until != 0 {
+		add("created_at <= ?", f.Until)
+	}
+
+	query := ""
+	if len(conditions) > 0 {
+		query = " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+	if f.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", f.Limit)
+	}
+	return query, args
+}
+
+func prepareAuthEvent(event *models.AuthEvent) {
+	if event.ID == "" {
+		event.ID = ids.NewSortable("auth")
+	}
+	if event.CreatedAt == 0 {
+		event.CreatedAt = nowMillis()
+	}
+}
+
+func scanAuthEvents(rows *sql.Rows) ([]models.AuthEvent, error) {
+	defer rows.Close()
+
+	events := []models.AuthEvent{}
+	for rows.Next() {
+		var event models.AuthEvent
+		if err := rows.Scan(&event.ID, &event.Type, &event.UserID, &event.Username, &event.IP,
+			&event.UserAgent, &event.Detail, &event.ImpersonatorID, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// Memory
+
+func (m *MemoryDAL) RecordAuthEvent(event *models.AuthEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	prepareAuthEvent(event)
+	m.authEvents = append(m.authEvents, *event)
+	if len(m.authEvents) > maxMemoryAuthEvents {
+		m.authEvents = append([]models.AuthEvent(nil), m.authEvents[len(m.authEvents)-maxMemoryAuthEvents:]...)
+	}
+	return nil
+}
+
+func (m *MemoryDAL) ListAuthEvents(filter AuthEventFilter) ([]models.AuthEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	events := []models.AuthEvent{}
+	for i := len(m.authEvents) - 1; i >= 0; i-- {
+		if filter.Limit > 0 && len(events) == filter.Limit {
+			break
+		}
+		if filter.matches(m.authEvents[i]) {
+			events = append(events, m.authEvents[i])
+		}
+	}
+	return events, nil
+}
+
+// SQLite
+
+func (s *SQLiteDAL) RecordAuthEvent(event *models.AuthEvent) error {
+	prepareAuthEvent(event)
+	_, err := s.db.Exec(`INSERT INTO auth_events (`+authEventColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		event.ID, event.Type, event.UserID, event.Username, event.IP, event.UserAgent, event.Detail, event.ImpersonatorID, event.CreatedAt)
+	return err
+}
+
+func (s *SQLiteDAL) ListAuthEvents(filter AuthEventFilter) ([]models.AuthEvent, error) {
+	where, args := filter.whereClause(func(int) string { return "?" })
+	rows, err := s.db.Query(`SELECT `+authEventColumns+` FROM auth_events`+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanAuthEvents(rows)
+}
+
+// Postgres
+
+func (p *PostgresDAL) RecordAuthEvent(event *models.AuthEvent) error {
+	prepareAuthEvent(event)
+	_, err := p.db.Exec(`INSERT INTO auth_events (`+authEventColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		event.ID, event.Type, event.UserID, event.Username, event.IP, event.UserAgent, event.Detail, event.ImpersonatorID, event.CreatedAt)
+	return err
+}
+
+func (p *PostgresDAL) ListAuthEvents(filter AuthEventFilter) ([]models.AuthEvent, error) {
+	where, args := filter.whereClause(func(n int) string { return fmt.Sprintf("$%d", n) })
+	rows, err := p.db.Query(`SELECT `+authEventColumns+` FROM auth_events`+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanAuthEvents(rows)
+}
@@ -0,0 +1,73 @@
+package dal
+
+import (
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+func assertAuthEventStoreFilters(t *testing.T, store AuthEventStore) {
+	t.Helper()
+
+	events := []models.AuthEvent{
+		{Type: "login_failure", Username: "taylor", IP: "10.0.0.1", CreatedAt: 1000},
+		{Type: "login_success", UserID: "local:taylor", Username: "taylor", IP: "10.0.0.1", CreatedAt: 2000},
+		{Type: "login_failure", Username: "sam", IP: "10.0.0.2", CreatedAt: 3000},
+		{Type: "logout", UserID: "local:taylor", Username: "taylor", IP: "10.0.0.3", CreatedAt: 4000},
+		{Type: "impersonation_start", UserID: "local:sam", Username: "sam", IP: "10.0.0.4", ImpersonatorID: "local:admin", CreatedAt: 5000},
+	}
+	for i := range events {
+		if err := store.RecordAuthEvent(&events[i]); err != nil {
+			t.Fatalf("RecordAuthEvent() failed: %v", err)
+		}
+		if events[i].ID == "" {
+			t.Fatal("RecordAuthEvent() did not assign an ID")
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter AuthEventFilter
+		want   []int64
+	}{
+		{"all newest first", AuthEventFilter{}, []int64{5000, 4000, 3000, 2000, 1000}},
+		{"type", AuthEventFilter{Type: "login_failure"}, []int64{3000, 1000}},
+		{"user by id", AuthEventFilter{User: "local:taylor"}, []int64{4000, 2000}},
+		{"user by username", AuthEventFilter{User: "taylor"}, []int64{4000, 2000, 1000}},
+		{"user by impersonator", AuthEventFilter{User: "local:admin"}, []int64{5000}},
+		{"ip", AuthEventFilter{IP: "10.0.0.1"}, []int64{2000, 1000}},
+		{"window", AuthEventFilter{Since: 2000, Until: 3000}, []int64{3000, 2000}},
+		{"limit", AuthEventFilter{Limit: 2}, []int64{5000, 4000}},
+	}
+	for _, test := range tests {
+		got, err := store.ListAuthEvents(test.filter)
+		if err != nil {
+			t.Fatalf("ListAuthEvents(%s) failed: %v", test.name, err)
+		}
+		if len(got) != len(test.want) {
+			t.Fatalf("ListAuthEvents(%s) returned %d events, want %d", test.name, len(got), len(test.want))
+		}
+		for i, event := range got {
+			if event.CreatedAt == 5000 && event.ImpersonatorID != "local:admin" {
+				t.Fatalf("ListAuthEvents(%s) lost the impersonator: %+v", test.name, event)
+			}
+			if event.CreatedAt != test.want[i] {
+				t.Fatalf("ListAuthEvents(%s)[%d].CreatedAt = %d, want %d", test.name, i, event.CreatedAt, test.want[i])
+			}
+		}
+	}
+}
+
+func TestMemoryAuthEventStore(t *testing.T) {
+	assertAuthEventStoreFilters(t, NewMemoryDAL())
+}
+
+func TestSQLiteAuthEventStore(t *testing.T) {
+	assertAuthEventStoreFilters(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresAuthEventStore(t *testing.T) {
+	store := newTestPostgresDAL(t)
+	store.db.Exec(`DELETE FROM auth_events`)
+	assertAuthEventStoreFilters(t, store)
+}
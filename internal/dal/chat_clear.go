@@ -0,0 +1,231 @@
+package dal
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+const defaultChatClearGrace = 5 * time.Minute
+
+// ChatClearGrace is how long cleared chat can be restored, read from
+// CHAT_CLEAR_GRACE (a Go duration such as "10m"). Cleared messages older than
+// that are deleted for good by the next clear or restore.
+func ChatClearGrace() time.Duration {
+	grace, err := time.ParseDuration(strings.TrimSpace(os.Getenv("CHAT_CLEAR_GRACE")))
+	if err != nil || grace <= 0 {
+		return defaultChatClearGrace
+	}
+	return grace
+}
+
+// clearedMessage is a chat message the memory backend has soft-deleted.
+type clearedMessage struct {
+	msg       models.ChatMessage
+	deletedAt time.Time
+}
+
+func (m *MemoryDAL) ClearChat() (int, error) {
+	if !ChatEnabled() {
+		return 0, ErrChatDisabled
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.purgeClearedChatUnsafe(now)
+	for _, msg := range m.chat {
+		m.clearedChat = append(m.clearedChat, clearedMessage{msg: msg, deletedAt: now})
+	}
+	cleared := len(m.chat)
+	m.chat = []models.ChatMessage{}
+	if cleared > 0 {
+		m.version++
+	}
+	return cleared, nil
+}
+
+func (m *MemoryDAL) RestoreChat() (int, error) {
+	if !ChatEnabled() {
+		return 0, ErrChatDisabled
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.purgeClearedChatUnsafe(time.Now())
+	if len(m.clearedChat) == 0 {
+		return 0, ErrNothingToRestore
+	}
+	for _, cleared := range m.clearedChat {
+		m.chat = append(m.chat, cleared.msg)
+	}
+	sort.SliceStable(m.chat, func(i, j int) bool {
+		if m.chat[i].TS != m.chat[j].TS {
+			return m.chat[i].TS < m.chat[j].TS
+		}
+		return m.chat[i].ID < m.chat[j].ID
+	})
+	restored := len(m.clearedChat)
+	m.clearedChat = nil
+	m.version++
+	return restored, nil
+}
+
+// purgeClearedChatUnsafe drops cleared messages past the grace period.
+func (m *MemoryDAL) purgeClearedChatUnsafe(now time.Time) {
+	cutoff := now.Add(-ChatClearGrace())
+	kept := m.clearedChat[:0]
+	for _, cleared := range m.clearedChat {
+		if cleared.deletedAt.After(cutoff) {
+			kept = append(kept, cleared)
+		} else {
+			delete(m.reactionUsers, cleared.msg.ID)
+		}
+	}
+	m.clearedChat = kept
+}
+
+func (s *SQLiteDAL) ClearChat() (int, error) {
+	if !ChatEnabled() {
+		return 0, ErrChatDisabled
+	}
+	now := time.Now()
+	if err := s.purgeClearedChat(now); err != nil {
+		return 0, err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE chat SET deleted_at = ? WHERE deleted_at IS NULL`, now.UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	cleared, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if cleared > 0 {
+		if err := bumpVersion(tx); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int(cleared), nil
+}
+
+func (s *SQLiteDAL) RestoreChat() (int, error) {
+	if !ChatEnabled() {
+		return 0, ErrChatDisabled
+	}
+	if err := s.purgeClearedChat(time.Now()); err != nil {
+		return 0, err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE chat SET deleted_at = NULL WHERE deleted_at IS NOT NULL`)
+	if err != nil {
+		return 0, err
+	}
+	restored, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if restored == 0 {
+		return 0, ErrNothingToRestore
+	}
+	if err := bumpVersion(tx); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int(restored), nil
+}
+
+// purgeClearedChat deletes messages cleared before the grace period, with
+// their reactions.
+func (s *SQLiteDAL) purgeClearedChat(now time.Time) error {
+	if _, err := s.db.Exec(`DELETE FROM chat WHERE deleted_at IS NOT NULL AND deleted_at <= ?`, now.Add(-ChatClearGrace()).UnixMilli()); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(`DELETE FROM chat_reactions WHERE message_id NOT IN (SELECT id FROM chat)`)
+	return err
+}
+
+func (p *PostgresDAL) ClearChat() (int, error) {
+	if !ChatEnabled() {
+		return 0, ErrChatDisabled
+	}
+	now := time.Now()
+	if _, err := p.db.Exec(`DELETE FROM chat WHERE deleted_at IS NOT NULL AND deleted_at <= $1`, now.Add(-ChatClearGrace()).UnixMilli()); err != nil {
+		return 0, err
+	}
+	tx, err := p.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE chat SET deleted_at = $1 WHERE deleted_at IS NULL`, now.UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	cleared, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if cleared > 0 {
+		if err := bumpVersion(tx); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int(cleared), nil
+}
+
+func (p *PostgresDAL) RestoreChat() (int, error) {
+	if !ChatEnabled() {
+		return 0, ErrChatDisabled
+	}
+	if _, err := p.db.Exec(`DELETE FROM chat WHERE deleted_at IS NOT NULL AND deleted_at <= $1`, time.Now().Add(-ChatClearGrace()).UnixMilli()); err != nil {
+		return 0, err
+	}
+	tx, err := p.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE chat SET deleted_at = NULL WHERE deleted_at IS NOT NULL`)
+	if err != nil {
+		return 0, err
+	}
+	restored, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if restored == 0 {
+		return 0, ErrNothingToRestore
+	}
+	if err := bumpVersion(tx); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int(restored), nil
+}
@@ -0,0 +1,73 @@
+package dal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func assertChatClearAndRestore(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	first, err := store.AddChatMessage("first", "chat")
+	if err != nil {
+		t.Fatalf("AddChatMessage(first) failed: %v", err)
+	}
+	if _, err := store.AddChatMessage("second", "chat"); err != nil {
+		t.Fatalf("AddChatMessage(second) failed: %v", err)
+	}
+
+	cleared, err := store.ClearChat()
+	if err != nil || cleared != 2 {
+		t.Fatalf("ClearChat() = %d, %v; want 2", cleared, err)
+	}
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	if len(state.Chat) != 0 {
+		t.Fatalf("chat after clear = %v, want none", state.Chat)
+	}
+
+	// Messages sent after the clear stay put when the cleared ones return.
+	if _, err := store.AddChatMessage("third", "chat"); err != nil {
+		t.Fatalf("AddChatMessage(third) failed: %v", err)
+	}
+	restored, err := store.RestoreChat()
+	if err != nil || restored != 2 {
+		t.Fatalf("RestoreChat() = %d, %v; want 2", restored, err)
+	}
+	state, err = store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	if len(state.Chat) != 3 || state.Chat[0].ID != first.ID || state.Chat[2].Text != "third" {
+		t.Fatalf("chat after restore = %v, want first, second, third", state.Chat)
+	}
+	if _, err := store.RestoreChat(); !errors.Is(err, ErrNothingToRestore) {
+		t.Fatalf("second RestoreChat() error = %v, want ErrNothingToRestore", err)
+	}
+
+	// Past the grace period a clear can't be undone.
+	t.Setenv("CHAT_CLEAR_GRACE", "1ms")
+	if _, err := store.ClearChat(); err != nil {
+		t.Fatalf("ClearChat() failed: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if _, err := store.RestoreChat(); !errors.Is(err, ErrNothingToRestore) {
+		t.Fatalf("RestoreChat() after the grace period error = %v, want ErrNothingToRestore", err)
+	}
+}
+
+func TestMemoryChatClearAndRestore(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertChatClearAndRestore(t, NewMemoryDAL())
+}
+
+func TestSQLiteChatClearAndRestore(t *testing.T) {
+	assertChatClearAndRestore(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresChatClearAndRestore(t *testing.T) {
+	assertChatClearAndRestore(t, newTestPostgresDAL(t))
+}
@@ -0,0 +1,88 @@
+package dal
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+func assertChatDisabled(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	// Messages from before chat was turned off stay hidden.
+	if _, err := store.AddChatMessage("earlier", "user"); err != nil {
+		t.Fatalf("AddChatMessage() failed: %v", err)
+	}
+	t.Setenv("ENABLE_CHAT", "false")
+
+	if _, err := store.AddChatMessage("hello", "user"); !errors.Is(err, ErrChatDisabled) {
+		t.Fatalf("AddChatMessage() error = %v, want ErrChatDisabled", err)
+	}
+	if _, err := store.AddReaction("msg-1", "heart", "user-1"); !errors.Is(err, ErrChatDisabled) {
+		t.Fatalf("AddReaction() error = %v, want ErrChatDisabled", err)
+	}
+	if _, err := store.GetChatSince(0); !errors.Is(err, ErrChatDisabled) {
+		t.Fatalf("GetChatSince() error = %v, want ErrChatDisabled", err)
+	}
+	if _, err := store.ClearChat(); !errors.Is(err, ErrChatDisabled) {
+		t.Fatalf("ClearChat() error = %v, want ErrChatDisabled", err)
+	}
+	if !errors.Is(ErrChatDisabled, ErrNotFound) {
+		t.Fatal("ErrChatDisabled should match ErrNotFound")
+	}
+
+	team, err := store.AddTeam("Quiet Foxes", "Sarah", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	player, err := store.AddPlayer(&models.Player{Name: "Quiet Pick", Position: "CC", Team: "Test", Points: 10, Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+	if _, err := store.SetDraftMode(models.DraftModeStandard); err != nil {
+		t.Fatalf("SetDraftMode() failed: %v", err)
+	}
+	if err := store.DraftPlayer(player.ID, team.ID); err != nil {
+		t.Fatalf("DraftPlayer() failed: %v", err)
+	}
+	if _, err := store.UndoLastPick(); err != nil {
+		t.Fatalf("UndoLastPick() failed: %v", err)
+	}
+	if err := store.DraftPlayer(player.ID, team.ID); err != nil {
+		t.Fatalf("DraftPlayer() after undo failed: %v", err)
+	}
+
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	if len(state.Chat) != 0 {
+		t.Fatalf("GetState() chat = %+v, want none", state.Chat)
+	}
+	if len(state.Teams) != 1 || len(state.Teams[0].Players) != 1 {
+		t.Fatalf("GetState() teams = %+v, want the pick on the roster", state.Teams)
+	}
+
+	t.Setenv("ENABLE_CHAT", "true")
+	state, err = store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	if len(state.Chat) != 1 || state.Chat[0].Text != "earlier" {
+		t.Fatalf("GetState() chat after re-enabling = %+v, want only the earlier message", state.Chat)
+	}
+}
+
+func TestMemoryChatDisabled(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertChatDisabled(t, NewMemoryDAL())
+}
+
+func TestSQLiteChatDisabled(t *testing.T) {
+	assertChatDisabled(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresChatDisabled(t *testing.T) {
+	assertChatDisabled(t, newTestPostgresDAL(t))
+}
@@ -0,0 +1,159 @@
+package dal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/i18n"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/ids"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// System messages are stored as an i18n key and params in the chat table's
+// message column and rendered in LANG whenever they are read, so they can be
+// translated or reworded after the fact. The text column still gets the
+// English rendering; rows without a key are legacy text shown as stored.
+
+// chatMessageData is the JSON the message column holds.
+type chatMessageData struct {
+	Key    string            `json:"key"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// newSystemChatMessage builds a system message for key and params.
+func newSystemChatMessage(key string, params map[string]string) *models.ChatMessage {
+	msg := &models.ChatMessage{
+		ID:     ids.NewSortable("msg"),
+		TS:     time.Now().UnixMilli(),
+		Type:   "system",
+		Text:   i18n.RenderIn(i18n.DefaultLang, key, params),
+		Sender: SystemSender(),
+		Emotes: make(map[string]int),
+		Key:    key,
+		Params: params,
+	}
+	stampChatMessage(msg, 0)
+	return msg
+}
+
+// newChatMessage builds a raw text message of msgType, as chat posts are.
+func newChatMessage(text, msgType string) (*models.ChatMessage, error) {
+	text, err := sanitizeChatText(text)
+	if err != nil {
+		return nil, err
+	}
+	msg := &models.ChatMessage{
+		ID:     ids.NewSortable("msg"),
+		TS:     time.Now().UnixMilli(),
+		Type:   msgType,
+		Text:   text,
+		Sender: chatSender(msgType),
+		Emotes: make(map[string]int),
+	}
+	stampChatMessage(msg, 0)
+	return msg, nil
+}
+
+func pickChatMessage(mascot, teamName string, player models.Player) *models.ChatMessage {
+	return newSystemChatMessage(i18n.KeyPick, map[string]string{
+		"mascot":     mascot,
+		"team":       teamName,
+		"player":     player.Name,
+		"playerTeam": player.Team,
+		"position":   player.Position,
+	})
+}
+
+func teamJoinedChatMessage(team *models.Team) *models.ChatMessage {
+	if team.Owner == "" {
+		return newSystemChatMessage(i18n.KeyTeamJoinedNoOwner, map[string]string{"mascot": team.Mascot, "team": team.Name})
+	}
+	return newSystemChatMessage(i18n.KeyTeamJoined, map[string]string{"mascot": team.Mascot, "team": team.Name, "owner": team.Owner})
+}
+
+// orderRandomizedChatMessage lists teams in their new order, numbered from 1.
+func orderRandomizedChatMessage(teams []models.Team) *models.ChatMessage {
+	names := make([]string, len(teams))
+	for i, team := range teams {
+		names[i] = fmt.Sprintf("%d. %s %s", i+1, team.Mascot, team.Name)
+	}
+	return newSystemChatMessage(i18n.KeyOrderRandomized, map[string]string{"order": strings.Join(names, ", ")})
+}
+
+func draftModeSetChatMessage(settings models.DraftSettings) *models.ChatMessage {
+	return newSystemChatMessage(i18n.KeyDraftModeSet, map[string]string{"mode": settings.Name})
+}
+
+func pickUndoneChatMessage(player models.Player, teamName string) *models.ChatMessage {
+	return newSystemChatMessage(i18n.KeyPickUndone, map[string]string{"player": player.Name, "team": teamName})
+}
+
+// picksSwappedChatMessage describes swapping picks a and b, each of which
+// still names the team that held it before the swap.
+func picksSwappedChatMessage(a, b draftedPick) *models.ChatMessage {
+	return newSystemChatMessage(i18n.KeyPicksSwapped, map[string]string{
+		"pickA":   strconv.Itoa(a.number),
+		"pickB":   strconv.Itoa(b.number),
+		"playerA": a.player.Name,
+		"playerB": b.player.Name,
+		"teamA":   a.team.Name,
+		"teamB":   b.team.Name,
+	})
+}
+
+// welcomeChatKeys are the messages a fresh draft's chat opens with.
+var welcomeChatKeys = []string{i18n.KeyWelcome, i18n.KeyTipRoomCode, i18n.KeyFirstPickTease}
+
+// chatMessageColumn is the message column value for msg: NULL for a message
+// stored as raw text.
+func chatMessageColumn(msg *models.ChatMessage) interface{} {
+	if msg.Key == "" {
+		return nil
+	}
+	data, _ := json.Marshal(chatMessageData{Key: msg.Key, Params: msg.Params})
+	return string(data)
+}
+
+// applyChatMessageColumn sets msg's key and params from a message column
+// value and renders its text.
+func applyChatMessageColumn(msg *models.ChatMessage, column []byte) {
+	var data chatMessageData
+	if len(column) > 0 && json.Unmarshal(column, &data) == nil {
+		msg.Key, msg.Params = data.Key, data.Params
+	}
+	localizeChatMessage(msg)
+}
+
+// localizeChatMessage renders a keyed message's text in LANG.
+func localizeChatMessage(msg *models.ChatMessage) {
+	if msg.Key != "" {
+		msg.Text = i18n.Render(msg.Key, msg.Params)
+	}
+}
+
+// localizeChat renders messages' texts in LANG in place, returning them.
+func localizeChat(messages []models.ChatMessage) []models.ChatMessage {
+	for i := range messages {
+		localizeChatMessage(&messages[i])
+	}
+	return messages
+}
+
+// insertChatTx stores msg inside tx, so it commits or rolls back with the
+// change it describes. placeholders is the backend's list of seven value
+// markers. It does nothing when chat is disabled.
+func insertChatTx(tx *sql.Tx, msg *models.ChatMessage, placeholders string) error {
+	if !ChatEnabled() {
+		return nil
+	}
+	emotesJSON, _ := json.Marshal(msg.Emotes)
+	_, err := tx.Exec(`
+		INSERT INTO chat (id, ts, type, text, sender, emotes, message)
+		VALUES (`+placeholders+`)
+	`, msg.ID, msg.TS, msg.Type, msg.Text, msg.Sender, string(emotesJSON), chatMessageColumn(msg))
+	return err
+}
@@ -0,0 +1,194 @@
+package dal
+
+import (
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/i18n"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// assertLocalizedSystemChat checks that picks and new teams are stored as a
+// message key and rendered in whichever LANG is set when they're read.
+func assertLocalizedSystemChat(t *testing.T, store DraftDAL) {
+	t.Helper()
+	t.Setenv("LANG", "en_US.UTF-8")
+
+	team, err := store.AddTeam("Foxes", "", "🦊", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	player, err := store.AddPlayer(&models.Player{Name: "Bashful Bunny", Position: "CC", Team: "Test", Points: 10, Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+	if err := store.DraftPlayer(player.ID, team.ID); err != nil {
+		t.Fatalf("DraftPlayer() failed: %v", err)
+	}
+
+	texts := func(messages []models.ChatMessage) map[string]string {
+		byKey := map[string]string{}
+		for _, msg := range messages {
+			if msg.Key != "" {
+				byKey[msg.Key] = msg.Text
+			}
+		}
+		return byKey
+	}
+	want := map[string]map[string]string{
+		"en_US.UTF-8": {
+			i18n.KeyTeamJoinedNoOwner: "New team joined the draft: 🦊 Foxes (Owner: Unassigned)",
+			i18n.KeyPick:              "🦊 Foxes drafted Bashful Bunny (Test • CC)",
+		},
+		"fr_FR.UTF-8": {
+			i18n.KeyTeamJoinedNoOwner: "Nouvelle équipe dans la draft : 🦊 Foxes (sans propriétaire)",
+			i18n.KeyPick:              "🦊 Foxes a choisi Bashful Bunny (Test • CC)",
+		},
+	}
+	for _, lang := range []string{"en_US.UTF-8", "fr_FR.UTF-8"} {
+		t.Setenv("LANG", lang)
+
+		state, err := store.GetState()
+		if err != nil {
+			t.Fatalf("GetState() failed: %v", err)
+		}
+		since, err := store.GetChatSince(0)
+		if err != nil {
+			t.Fatalf("GetChatSince() failed: %v", err)
+		}
+		for source, got := range map[string]map[string]string{"GetState": texts(state.Chat), "GetChatSince": texts(since)} {
+			for key, text := range want[lang] {
+				if got[key] != text {
+					t.Errorf("LANG=%s %s %s text = %q, want %q", lang, source, key, got[key], text)
+				}
+			}
+		}
+	}
+
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	for _, msg := range state.Chat {
+		if msg.Key == i18n.KeyPick && (msg.Params["player"] != "Bashful Bunny" || msg.Params["team"] != "Foxes") {
+			t.Fatalf("pick message params = %v, want the team and player", msg.Params)
+		}
+	}
+}
+
+func TestMemoryLocalizedSystemChat(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertLocalizedSystemChat(t, NewMemoryDAL())
+}
+
+func TestSQLiteLocalizedSystemChat(t *testing.T) {
+	assertLocalizedSystemChat(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresLocalizedSystemChat(t *testing.T) {
+	assertLocalizedSystemChat(t, newTestPostgresDAL(t))
+}
+
+// assertLocalizedChangeMessages checks that mode changes, swaps and undos
+// are stored as message keys too.
+func assertLocalizedChangeMessages(t *testing.T, store DraftDAL) {
+	t.Helper()
+	t.Setenv("LANG", "en_US.UTF-8")
+
+	foxes, err := store.AddTeam("Foxes", "", "🦊", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	owls, err := store.AddTeam("Owls", "", "🦉", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	for _, pick := range []struct{ name, teamID string }{{"Bashful Bunny", foxes.ID}, {"Amuseable Avocado", owls.ID}} {
+		player, err := store.AddPlayer(&models.Player{Name: pick.name, Position: "CC", Team: "Test", Points: 10, Tier: models.TierB})
+		if err != nil {
+			t.Fatalf("AddPlayer() failed: %v", err)
+		}
+		if err := store.DraftPlayer(player.ID, pick.teamID); err != nil {
+			t.Fatalf("DraftPlayer(%s) failed: %v", pick.name, err)
+		}
+	}
+	if err := store.SwapPicks(1, 2); err != nil {
+		t.Fatalf("SwapPicks() failed: %v", err)
+	}
+	if _, err := store.UndoLastPick(); err != nil {
+		t.Fatalf("UndoLastPick() failed: %v", err)
+	}
+	if _, err := store.SetDraftMode(models.DraftModeReverseSnake); err != nil {
+		t.Fatalf("SetDraftMode() failed: %v", err)
+	}
+
+	want := map[string]map[string]string{
+		"en_US.UTF-8": {
+			i18n.KeyPicksSwapped: "🔀 Picks 1 and 2 swapped: Bashful Bunny now goes to Owls at pick 2, Amuseable Avocado to Foxes at pick 1",
+			i18n.KeyPickUndone:   "↩️ Pick undone: Bashful Bunny returned to the pool from Owls",
+			i18n.KeyDraftModeSet: "Draft mode set to Reverse Snake",
+		},
+		"fr_FR.UTF-8": {
+			i18n.KeyPicksSwapped: "🔀 Choix 1 et 2 échangés : Bashful Bunny passe à Owls au choix 2, Amuseable Avocado à Foxes au choix 1",
+			i18n.KeyPickUndone:   "↩️ Choix annulé : Bashful Bunny retourne dans le vivier, retiré de Owls",
+			i18n.KeyDraftModeSet: "Mode de draft réglé sur Reverse Snake",
+		},
+	}
+	for _, lang := range []string{"en_US.UTF-8", "fr_FR.UTF-8"} {
+		t.Setenv("LANG", lang)
+
+		state, err := store.GetState()
+		if err != nil {
+			t.Fatalf("GetState() failed: %v", err)
+		}
+		got := map[string]string{}
+		for _, msg := range state.Chat {
+			if msg.Key != "" {
+				got[msg.Key] = msg.Text
+			}
+		}
+		for key, text := range want[lang] {
+			if got[key] != text {
+				t.Errorf("LANG=%s %s text = %q, want %q", lang, key, got[key], text)
+			}
+		}
+	}
+}
+
+func TestMemoryLocalizedChangeMessages(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertLocalizedChangeMessages(t, NewMemoryDAL())
+}
+
+func TestSQLiteLocalizedChangeMessages(t *testing.T) {
+	assertLocalizedChangeMessages(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresLocalizedChangeMessages(t *testing.T) {
+	assertLocalizedChangeMessages(t, newTestPostgresDAL(t))
+}
+
+func TestSQLiteLegacyChatKeepsItsText(t *testing.T) {
+	store := newTestSQLiteDAL(t)
+	t.Setenv("LANG", "fr_FR.UTF-8")
+
+	if _, err := store.db.Exec(`
+		INSERT INTO chat (id, ts, type, text, sender, emotes)
+		VALUES ('msg_legacy', 1, 'system', 'Old Foxes drafted Old Bunny', 'Draft Bot', '{}')
+	`); err != nil {
+		t.Fatalf("insert legacy row failed: %v", err)
+	}
+
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	for _, msg := range state.Chat {
+		if msg.ID == "msg_legacy" {
+			if msg.Text != "Old Foxes drafted Old Bunny" || msg.Key != "" {
+				t.Fatalf("legacy message = %q (key %q), want its stored text", msg.Text, msg.Key)
+			}
+			return
+		}
+	}
+	t.Fatal("legacy message missing from state")
+}
@@ -0,0 +1,46 @@
+package dal
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLiteChatOrderIsStableWithinMillisecond(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+
+	store, err := NewSQLiteDAL(filepath.Join(t.TempDir(), "draft.sqlite"))
+	if err != nil {
+		t.Fatalf("NewSQLiteDAL() failed: %v", err)
+	}
+
+	first, err := store.AddChatMessage("first", "chat")
+	if err != nil {
+		t.Fatalf("AddChatMessage(first) failed: %v", err)
+	}
+	second, err := store.AddChatMessage("second", "chat")
+	if err != nil {
+		t.Fatalf("AddChatMessage(second) failed: %v", err)
+	}
+
+	// Force both messages into the same millisecond so only the ID can break the tie.
+	if _, err := store.db.Exec(`UPDATE chat SET ts = ?`, first.TS); err != nil {
+		t.Fatalf("align timestamps: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		state, err := store.GetState()
+		if err != nil {
+			t.Fatalf("GetState() failed: %v", err)
+		}
+
+		var order []string
+		for _, msg := range state.Chat {
+			if msg.ID == first.ID || msg.ID == second.ID {
+				order = append(order, msg.ID)
+			}
+		}
+		if len(order) != 2 || order[0] != first.ID || order[1] != second.ID {
+			t.Fatalf("chat order = %v, want [%s %s]", order, first.ID, second.ID)
+		}
+	}
+}
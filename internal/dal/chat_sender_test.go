@@ -0,0 +1,67 @@
+package dal
+
+import (
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+func assertSystemSender(t *testing.T, store DraftDAL) {
+	t.Helper()
+	t.Setenv("SYSTEM_SENDER", "Jelly Bot")
+
+	team, err := store.AddTeam("Foxes", "Sarah", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	player, err := store.AddPlayer(&models.Player{Name: "Bashful Bunny", Position: "CC", Team: "Test", Points: 10, Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+	if err := store.DraftPlayer(player.ID, team.ID); err != nil {
+		t.Fatalf("DraftPlayer() failed: %v", err)
+	}
+	if _, err := store.AddChatMessage("Nice pick", "user"); err != nil {
+		t.Fatalf("AddChatMessage() failed: %v", err)
+	}
+
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	var pick, user *models.ChatMessage
+	for i, msg := range state.Chat {
+		switch {
+		case msg.Type == "system" && msg.Text == "🦊 Foxes drafted Bashful Bunny (Test • CC)":
+			pick = &state.Chat[i]
+		case msg.Type == "user":
+			user = &state.Chat[i]
+		}
+	}
+	if pick == nil || pick.Sender != "Jelly Bot" {
+		t.Fatalf("pick message = %+v, want one sent by Jelly Bot in %+v", pick, state.Chat)
+	}
+	if user == nil || user.Sender != "" {
+		t.Fatalf("user message = %+v, want no sender", user)
+	}
+}
+
+func TestMemorySystemSender(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertSystemSender(t, NewMemoryDAL())
+}
+
+func TestSQLiteSystemSender(t *testing.T) {
+	assertSystemSender(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresSystemSender(t *testing.T) {
+	assertSystemSender(t, newTestPostgresDAL(t))
+}
+
+func TestSystemSenderDefault(t *testing.T) {
+	t.Setenv("SYSTEM_SENDER", " ")
+	if got := SystemSender(); got != "Draft Bot" {
+		t.Fatalf("SystemSender() = %q, want %q", got, "Draft Bot")
+	}
+}
@@ -0,0 +1,76 @@
+package dal
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+func (m *MemoryDAL) GetChatSince(since int64) ([]models.ChatMessage, error) {
+	if !ChatEnabled() {
+		return nil, ErrChatDisabled
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	messages := []models.ChatMessage{}
+	for _, msg := range m.chat {
+		if msg.TS > since {
+			msg = cloneChatMessage(msg)
+			localizeChatMessage(&msg)
+			messages = append(messages, msg)
+		}
+	}
+	return messages, nil
+}
+
+func (s *SQLiteDAL) GetChatSince(since int64) ([]models.ChatMessage, error) {
+	if !ChatEnabled() {
+		return nil, ErrChatDisabled
+	}
+	rows, err := s.db.Query(`
+		SELECT id, ts, type, text, sender, emotes, updated_at, message
+		FROM chat WHERE deleted_at IS NULL AND ts > ? ORDER BY ts ASC, id ASC
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	return scanChatRows(rows)
+}
+
+func (p *PostgresDAL) GetChatSince(since int64) ([]models.ChatMessage, error) {
+	if !ChatEnabled() {
+		return nil, ErrChatDisabled
+	}
+	rows, err := p.db.Query(`
+		SELECT id, ts, type, text, sender, emotes, updated_at, message
+		FROM chat WHERE deleted_at IS NULL AND ts > $1 ORDER BY ts ASC, id ASC
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	return scanChatRows(rows)
+}
+
+// scanChatRows reads id, ts, type, text, sender, emotes, updated_at, message
+// rows and closes them.
+func scanChatRows(rows *sql.Rows) ([]models.ChatMessage, error) {
+	defer rows.Close()
+
+	messages := []models.ChatMessage{}
+	for rows.Next() {
+		var msg models.ChatMessage
+		var emotesJSON, message []byte
+		var updatedAt int64
+		if err := rows.Scan(&msg.ID, &msg.TS, &msg.Type, &msg.Text, &msg.Sender, &emotesJSON, &updatedAt, &message); err != nil {
+			return nil, err
+		}
+		msg.Emotes = make(map[string]int)
+		json.Unmarshal(emotesJSON, &msg.Emotes)
+		stampChatMessage(&msg, updatedAt)
+		applyChatMessageColumn(&msg, message)
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
@@ -0,0 +1,46 @@
+package dal
+
+import "testing"
+
+func assertChatSince(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	first, err := store.AddChatMessage("first", "chat")
+	if err != nil {
+		t.Fatalf("AddChatMessage(first) failed: %v", err)
+	}
+	second, err := store.AddChatMessage("second", "chat")
+	if err != nil {
+		t.Fatalf("AddChatMessage(second) failed: %v", err)
+	}
+
+	messages, err := store.GetChatSince(first.TS - 1)
+	if err != nil {
+		t.Fatalf("GetChatSince() failed: %v", err)
+	}
+	if len(messages) < 2 || messages[0].ID != first.ID || messages[len(messages)-1].ID != second.ID {
+		t.Fatalf("GetChatSince(before first) = %v, want first and second in order", messages)
+	}
+
+	// Strictly after: a message at exactly since is left out.
+	messages, err = store.GetChatSince(second.TS)
+	if err != nil {
+		t.Fatalf("GetChatSince() failed: %v", err)
+	}
+	if messages == nil || len(messages) != 0 {
+		t.Fatalf("GetChatSince(second.TS) = %#v, want an empty list", messages)
+	}
+}
+
+func TestMemoryGetChatSince(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertChatSince(t, NewMemoryDAL())
+}
+
+func TestSQLiteGetChatSince(t *testing.T) {
+	assertChatSince(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresGetChatSince(t *testing.T) {
+	assertChatSince(t, newTestPostgresDAL(t))
+}
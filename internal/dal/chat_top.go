@@ -0,0 +1,81 @@
+package dal
+
+import (
+	"sort"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// reactionTotal is the number of reactions on msg across every emote.
+func reactionTotal(msg models.ChatMessage) int {
+	total := 0
+	for _, count := range msg.Emotes {
+		total += count
+	}
+	return total
+}
+
+func (m *MemoryDAL) TopReactedMessages(limit int) ([]models.ChatMessage, error) {
+	if !ChatEnabled() {
+		return nil, ErrChatDisabled
+	}
+	if limit <= 0 {
+		return nil, fieldErrorf("limit", "limit must be positive")
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	messages := []models.ChatMessage{}
+	for _, msg := range m.chat {
+		if reactionTotal(msg) > 0 {
+			localizeChatMessage(&msg)
+			messages = append(messages, msg)
+		}
+	}
+	// m.chat is oldest first, so a stable sort keeps ties in that order.
+	sort.SliceStable(messages, func(i, j int) bool {
+		return reactionTotal(messages[i]) > reactionTotal(messages[j])
+	})
+	if len(messages) > limit {
+		messages = messages[:limit]
+	}
+	return messages, nil
+}
+
+func (s *SQLiteDAL) TopReactedMessages(limit int) ([]models.ChatMessage, error) {
+	if !ChatEnabled() {
+		return nil, ErrChatDisabled
+	}
+	if limit <= 0 {
+		return nil, fieldErrorf("limit", "limit must be positive")
+	}
+	rows, err := s.db.Query(`
+		SELECT id, ts, type, text, sender, emotes, updated_at, message FROM (
+			SELECT c.*, (SELECT COALESCE(SUM(value), 0) FROM json_each(c.emotes)) AS total
+			FROM chat c WHERE deleted_at IS NULL
+		) WHERE total > 0 ORDER BY total DESC, ts ASC, id ASC LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanChatRows(rows)
+}
+
+func (p *PostgresDAL) TopReactedMessages(limit int) ([]models.ChatMessage, error) {
+	if !ChatEnabled() {
+		return nil, ErrChatDisabled
+	}
+	if limit <= 0 {
+		return nil, fieldErrorf("limit", "limit must be positive")
+	}
+	rows, err := p.db.Query(`
+		SELECT id, ts, type, text, sender, emotes, updated_at, message FROM (
+			SELECT c.*, (SELECT COALESCE(SUM(value::int), 0) FROM jsonb_each_text(c.emotes)) AS total
+			FROM chat c WHERE deleted_at IS NULL
+		) ranked WHERE total > 0 ORDER BY total DESC, ts ASC, id ASC LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanChatRows(rows)
+}
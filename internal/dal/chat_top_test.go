@@ -0,0 +1,74 @@
+package dal
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func assertTopReactedMessages(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	// Each reaction comes from a different user; repeats by one user count once.
+	react := func(id string, emotes ...string) {
+		t.Helper()
+		for i, emote := range emotes {
+			if _, err := store.AddReaction(id, emote, fmt.Sprintf("user-%d", i)); err != nil {
+				t.Fatalf("AddReaction(%s, %s) failed: %v", id, emote, err)
+			}
+		}
+	}
+	var ids []string
+	for _, text := range []string{"quiet", "one", "three", "two early", "two late"} {
+		msg, err := store.AddChatMessage(text, "user")
+		if err != nil {
+			t.Fatalf("AddChatMessage(%s) failed: %v", text, err)
+		}
+		ids = append(ids, msg.ID)
+	}
+	react(ids[1], "👍")
+	react(ids[2], "🔥", "🔥", "🎉")
+	react(ids[3], "😂", "👍")
+	react(ids[4], "❤️", "❤️")
+
+	top, err := store.TopReactedMessages(10)
+	if err != nil {
+		t.Fatalf("TopReactedMessages() failed: %v", err)
+	}
+	want := []string{"three", "two early", "two late", "one"}
+	if len(top) != len(want) {
+		t.Fatalf("TopReactedMessages(10) = %v, want %v", top, want)
+	}
+	for i, msg := range top {
+		if msg.Text != want[i] {
+			t.Fatalf("TopReactedMessages(10)[%d] = %q, want %q", i, msg.Text, want[i])
+		}
+	}
+	if top[0].Emotes["🔥"] != 2 || top[0].Emotes["🎉"] != 1 {
+		t.Fatalf("top message emotes = %v, want its reactions", top[0].Emotes)
+	}
+
+	top, err = store.TopReactedMessages(2)
+	if err != nil {
+		t.Fatalf("TopReactedMessages(2) failed: %v", err)
+	}
+	if len(top) != 2 || top[0].Text != "three" || top[1].Text != "two early" {
+		t.Fatalf("TopReactedMessages(2) = %v, want three and two early", top)
+	}
+	if _, err := store.TopReactedMessages(0); !errors.Is(err, ErrValidation) {
+		t.Fatalf("TopReactedMessages(0) error = %v, want ErrValidation", err)
+	}
+}
+
+func TestMemoryTopReactedMessages(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertTopReactedMessages(t, NewMemoryDAL())
+}
+
+func TestSQLiteTopReactedMessages(t *testing.T) {
+	assertTopReactedMessages(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresTopReactedMessages(t *testing.T) {
+	assertTopReactedMessages(t, newTestPostgresDAL(t))
+}
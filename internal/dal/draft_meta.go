@@ -0,0 +1,147 @@
+package dal
+
+import "github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+
+// The draft's title and description are stored in draft_settings.
+const (
+	draftMetaTitleKey       = "meta_title"
+	draftMetaDescriptionKey = "meta_description"
+)
+
+// Limits on DraftMeta, in characters.
+const (
+	maxDraftTitleLength       = 100
+	maxDraftDescriptionLength = 500
+)
+
+// normalizeDraftMeta cleans meta like chat text and rejects a title or
+// description that is too long. Both may be empty.
+func normalizeDraftMeta(meta models.DraftMeta) (models.DraftMeta, error) {
+	meta.Title = cleanText(meta.Title)
+	meta.Description = cleanText(meta.Description)
+	if graphemeCount(meta.Title) > maxDraftTitleLength {
+		return meta, fieldErrorf("title", "title must be at most %d characters", maxDraftTitleLength)
+	}
+	if graphemeCount(meta.Description) > maxDraftDescriptionLength {
+		return meta, fieldErrorf("description", "description must be at most %d characters", maxDraftDescriptionLength)
+	}
+	return meta, nil
+}
+
+// loadDraftMeta reads the meta from draft_settings. The query has no
+// placeholders, so it works on every SQL backend.
+func loadDraftMeta(q sqlQueryer) (models.DraftMeta, error) {
+	var meta models.DraftMeta
+
+	rows, err := q.Query(`SELECT key, value FROM draft_settings WHERE key IN ('` + draftMetaTitleKey + `', '` + draftMetaDescriptionKey + `')`)
+	if err != nil {
+		return meta, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return meta, err
+		}
+		if key == draftMetaTitleKey {
+			meta.Title = value
+		} else {
+			meta.Description = value
+		}
+	}
+	return meta, rows.Err()
+}
+
+func (m *MemoryDAL) GetMeta() (models.DraftMeta, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.meta, nil
+}
+
+func (m *MemoryDAL) SetMeta(meta models.DraftMeta) (*models.DraftMeta, error) {
+	meta, err := normalizeDraftMeta(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.meta = meta
+	m.version++
+	return &meta, nil
+}
+
+func (s *SQLiteDAL) GetMeta() (models.DraftMeta, error) {
+	return loadDraftMeta(s.db)
+}
+
+func (s *SQLiteDAL) SetMeta(meta models.DraftMeta) (*models.DraftMeta, error) {
+	meta, err := normalizeDraftMeta(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for key, value := range map[string]string{draftMetaTitleKey: meta.Title, draftMetaDescriptionKey: meta.Description} {
+		_, err := tx.Exec(`
+			INSERT INTO draft_settings (key, value)
+			VALUES (?, ?)
+			ON CONFLICT(key) DO UPDATE SET value = excluded.value
+		`, key, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+func (p *PostgresDAL) GetMeta() (models.DraftMeta, error) {
+	return loadDraftMeta(p.db)
+}
+
+func (p *PostgresDAL) SetMeta(meta models.DraftMeta) (*models.DraftMeta, error) {
+	meta, err := normalizeDraftMeta(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for key, value := range map[string]string{draftMetaTitleKey: meta.Title, draftMetaDescriptionKey: meta.Description} {
+		_, err := tx.Exec(`
+			INSERT INTO draft_settings (key, value, updated_at)
+			VALUES ($1, $2, CURRENT_TIMESTAMP)
+			ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_at = CURRENT_TIMESTAMP
+		`, key, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
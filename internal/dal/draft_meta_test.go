@@ -0,0 +1,62 @@
+package dal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// assertDraftMeta sets the title and description, reads them back, and
+// checks that a too-long title is rejected and Reset clears them.
+func assertDraftMeta(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	meta, err := store.GetMeta()
+	if err != nil {
+		t.Fatalf("GetMeta() failed: %v", err)
+	}
+	if meta != (models.DraftMeta{}) {
+		t.Fatalf("GetMeta() on a new store = %+v, want empty", meta)
+	}
+
+	set, err := store.SetMeta(models.DraftMeta{Title: "  Springfield\tPlush League 🧸 ", Description: "Season two"})
+	if err != nil {
+		t.Fatalf("SetMeta() failed: %v", err)
+	}
+	want := models.DraftMeta{Title: "Springfield Plush League 🧸", Description: "Season two"}
+	if *set != want {
+		t.Fatalf("SetMeta() = %+v, want %+v", *set, want)
+	}
+	if meta, err = store.GetMeta(); err != nil || meta != want {
+		t.Fatalf("GetMeta() = %+v, %v; want %+v", meta, err, want)
+	}
+
+	if _, err := store.SetMeta(models.DraftMeta{Title: strings.Repeat("a", maxDraftTitleLength+1)}); !errors.Is(err, ErrValidation) {
+		t.Fatalf("SetMeta(long title) error = %v, want ErrValidation", err)
+	}
+	if meta, _ = store.GetMeta(); meta != want {
+		t.Fatalf("rejected SetMeta() changed the meta to %+v", meta)
+	}
+
+	if err := store.Reset(); err != nil {
+		t.Fatalf("Reset() failed: %v", err)
+	}
+	if meta, _ = store.GetMeta(); meta != (models.DraftMeta{}) {
+		t.Fatalf("GetMeta() after Reset = %+v, want empty", meta)
+	}
+}
+
+func TestMemoryDraftMeta(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertDraftMeta(t, NewMemoryDAL())
+}
+
+func TestSQLiteDraftMeta(t *testing.T) {
+	assertDraftMeta(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresDraftMeta(t *testing.T) {
+	assertDraftMeta(t, newTestPostgresDAL(t))
+}
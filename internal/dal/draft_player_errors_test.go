@@ -0,0 +1,70 @@
+package dal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// newTestSQLiteDAL opens an isolated SQLite store without development seed teams.
+func newTestSQLiteDAL(t *testing.T) *SQLiteDAL {
+	t.Helper()
+	t.Setenv("ENVIRONMENT", "production")
+
+	store, err := NewSQLiteDAL(filepath.Join(t.TempDir(), "draft.sqlite"))
+	if err != nil {
+		t.Fatalf("NewSQLiteDAL() failed: %v", err)
+	}
+	return store
+}
+
+// newTestPostgresDAL connects to TEST_DATABASE_URL and resets it, skipping when unset.
+func newTestPostgresDAL(t *testing.T) *PostgresDAL {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set; skipping Postgres test")
+	}
+	t.Setenv("ENVIRONMENT", "production")
+
+	store, err := NewPostgresDAL(dsn)
+	if err != nil {
+		t.Fatalf("NewPostgresDAL() failed: %v", err)
+	}
+	if err := store.Reset(); err != nil {
+		t.Fatalf("Reset() failed: %v", err)
+	}
+	return store
+}
+
+func assertDraftPlayerLookupErrors(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	team, err := store.AddTeam("Lookup Team", "Lookup", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	player, err := store.AddPlayer(&models.Player{Name: "Lookup Pick", Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+
+	if err := store.DraftPlayer("missing-player", team.ID); !errors.Is(err, ErrPlayerNotFound) {
+		t.Fatalf("DraftPlayer(missing player) error = %v, want %v", err, ErrPlayerNotFound)
+	}
+	if err := store.DraftPlayer(player.ID, "missing-team"); !errors.Is(err, ErrTeamNotFound) {
+		t.Fatalf("DraftPlayer(missing team) error = %v, want %v", err, ErrTeamNotFound)
+	}
+}
+
+func TestSQLiteDraftPlayerReportsMissingRecords(t *testing.T) {
+	assertDraftPlayerLookupErrors(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresDraftPlayerReportsMissingRecords(t *testing.T) {
+	assertDraftPlayerLookupErrors(t, newTestPostgresDAL(t))
+}
@@ -1,9 +1,12 @@
 package dal
 
 import (
+	"database/sql"
 	"fmt"
 	"hash/fnv"
 	"sort"
+	"strconv"
+	"time"
 
 	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
 )
@@ -41,11 +44,12 @@ func CalculateCurrentPick(state *models.DraftState, players []models.Player) {
 	state.Settings = models.DraftSettingsForMode(state.Settings.Mode)
 
 	totalDrafted := countDraftedPlayers(players)
-	totalPlayers := len(players)
 	teamCount := len(state.Teams)
+	totalPlayers := draftCapacity(teamCount, len(players))
 	defer func() {
 		applyDraftIntel(state, totalDrafted)
 	}()
+	applyRosterLimit(state)
 
 	state.CurrentPick = totalDrafted + 1
 	state.CurrentRound = 0
@@ -468,22 +472,181 @@ func wheelScore(round, teamIndex int) uint64 {
 
 func validateTeamTurn(teams []models.Team, mode models.DraftMode, players []models.Player, teamID string) error {
 	if len(teams) == 0 {
-		return fmt.Errorf("no teams are available")
+		return validationErrorf("no teams are available")
 	}
 
 	totalDrafted := countDraftedPlayers(players)
-	if totalDrafted >= len(players) {
-		return fmt.Errorf("draft is complete")
+	if totalDrafted >= draftCapacity(len(teams), len(players)) {
+		return validationErrorf("draft is complete")
 	}
 
 	expectedTeam := expectedTeamForPick(teams, mode, totalDrafted)
 	if expectedTeam == nil {
-		return fmt.Errorf("could not determine current team")
+		return validationErrorf("could not determine current team")
 	}
 
 	if expectedTeam.ID != teamID {
-		return fmt.Errorf("it is %s's turn", expectedTeam.Name)
+		return validationErrorf("it is %s's turn", expectedTeam.Name)
 	}
 
 	return nil
 }
+
+// draftCapacity is how many picks the draft has: every player, or fewer when
+// MAX_ROSTER_SIZE fills every roster first.
+func draftCapacity(teamCount, totalPlayers int) int {
+	if maxRoster := MaxRosterSize(); maxRoster > 0 && teamCount*maxRoster < totalPlayers {
+		return teamCount * maxRoster
+	}
+	return totalPlayers
+}
+
+// checkRosterSize rejects a pick for a team already holding rosterSize players
+// when MAX_ROSTER_SIZE is set.
+func checkRosterSize(teamName string, rosterSize int) error {
+	maxRoster := MaxRosterSize()
+	if maxRoster > 0 && rosterSize >= maxRoster {
+		return fmt.Errorf("%w: %s already has the maximum of %d players", ErrRosterFull, teamName, maxRoster)
+	}
+	return nil
+}
+
+// applyRosterLimit reports the roster cap and each team's open slots.
+func applyRosterLimit(state *models.DraftState) {
+	state.MaxRosterSize = MaxRosterSize()
+	for i := range state.Teams {
+		state.Teams[i].RosterSlotsRemaining = nil
+		if state.MaxRosterSize > 0 {
+			remaining := max(state.MaxRosterSize-len(state.Teams[i].Players), 0)
+			state.Teams[i].RosterSlotsRemaining = &remaining
+		}
+	}
+}
+
+// validateTeamOrder checks that order is a permutation of teamIDs: every
+// existing team exactly once and nothing else.
+func validateTeamOrder(order, teamIDs []string) error {
+	existing := make(map[string]bool, len(teamIDs))
+	for _, id := range teamIDs {
+		existing[id] = true
+	}
+
+	seen := make(map[string]bool, len(order))
+	for _, id := range order {
+		if !existing[id] {
+			return fieldErrorf("order", "unknown team %q in order", id)
+		}
+		if seen[id] {
+			return fieldErrorf("order", "team %q appears more than once in order", id)
+		}
+		seen[id] = true
+	}
+
+	for _, id := range teamIDs {
+		if !seen[id] {
+			return fieldErrorf("order", "team order is missing team %q", id)
+		}
+	}
+	return nil
+}
+
+// queryTeamIDs collects the id column from a team query.
+func queryTeamIDs(rows *sql.Rows, err error) ([]string, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teamIDs := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		teamIDs = append(teamIDs, id)
+	}
+	return teamIDs, rows.Err()
+}
+
+// Draft window bounds are stored in draft_settings as Unix milliseconds; an
+// empty value leaves that side of the window open.
+const (
+	draftWindowOpensKey  = "window_opens_at"
+	draftWindowClosesKey = "window_closes_at"
+)
+
+// sqlQueryer is satisfied by *sql.DB and *sql.Tx.
+type sqlQueryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// sqlExecer is likewise satisfied by *sql.DB and *sql.Tx.
+type sqlExecer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// loadDraftWindow reads the window from draft_settings. The query has no
+// placeholders, so it works on every SQL backend.
+func loadDraftWindow(q sqlQueryer) (models.DraftWindow, error) {
+	var window models.DraftWindow
+
+	rows, err := q.Query(`SELECT key, value FROM draft_settings WHERE key IN ('` + draftWindowOpensKey + `', '` + draftWindowClosesKey + `')`)
+	if err != nil {
+		return window, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return window, err
+		}
+		bound := decodeWindowBound(value)
+		if key == draftWindowOpensKey {
+			window.OpensAt = bound
+		} else {
+			window.ClosesAt = bound
+		}
+	}
+	return window, rows.Err()
+}
+
+func encodeWindowBound(bound time.Time) string {
+	if bound.IsZero() {
+		return ""
+	}
+	return strconv.FormatInt(bound.UnixMilli(), 10)
+}
+
+func decodeWindowBound(value string) time.Time {
+	millis, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || value == "" {
+		return time.Time{}
+	}
+	return time.UnixMilli(millis).UTC()
+}
+
+// normalizeDraftWindow validates window and truncates it to the stored precision.
+func normalizeDraftWindow(window models.DraftWindow) (models.DraftWindow, error) {
+	if !window.OpensAt.IsZero() {
+		window.OpensAt = window.OpensAt.Truncate(time.Millisecond).UTC()
+	}
+	if !window.ClosesAt.IsZero() {
+		window.ClosesAt = window.ClosesAt.Truncate(time.Millisecond).UTC()
+	}
+	if !window.OpensAt.IsZero() && !window.ClosesAt.IsZero() && !window.ClosesAt.After(window.OpensAt) {
+		return window, fieldErrorf("closesAt", "the draft window must close after it opens")
+	}
+	return window, nil
+}
+
+// checkDraftWindow rejects picks outside window.
+func checkDraftWindow(window models.DraftWindow, now time.Time) error {
+	switch window.State(now) {
+	case models.DraftWindowScheduled:
+		return ErrDraftNotOpen
+	case models.DraftWindowClosed:
+		return ErrDraftClosed
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package dal
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+func assertDraftWindowEnforced(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	team, err := store.AddTeam("Window Team", "Window", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	player, err := store.AddPlayer(&models.Player{Name: "Window Pick", Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+
+	if _, err := store.SetDraftWindow(models.DraftWindow{OpensAt: time.Now().Add(time.Hour), ClosesAt: time.Now()}); !errors.Is(err, ErrValidation) {
+		t.Fatalf("SetDraftWindow(closes before opens) error = %v, want %v", err, ErrValidation)
+	}
+
+	opensAt := time.Now().Add(time.Hour)
+	if _, err := store.SetDraftWindow(models.DraftWindow{OpensAt: opensAt}); err != nil {
+		t.Fatalf("SetDraftWindow(future) failed: %v", err)
+	}
+	window, err := store.GetDraftWindow()
+	if err != nil {
+		t.Fatalf("GetDraftWindow() failed: %v", err)
+	}
+	if !window.OpensAt.Equal(opensAt.Truncate(time.Millisecond)) || !window.ClosesAt.IsZero() {
+		t.Fatalf("GetDraftWindow() = %+v, want stored opening time only", window)
+	}
+	if err := store.DraftPlayer(player.ID, team.ID); !errors.Is(err, ErrDraftNotOpen) {
+		t.Fatalf("DraftPlayer(before open) error = %v, want %v", err, ErrDraftNotOpen)
+	}
+
+	if _, err := store.SetDraftWindow(models.DraftWindow{ClosesAt: time.Now().Add(-time.Minute)}); err != nil {
+		t.Fatalf("SetDraftWindow(past) failed: %v", err)
+	}
+	if err := store.DraftPlayer(player.ID, team.ID); !errors.Is(err, ErrDraftClosed) {
+		t.Fatalf("DraftPlayer(after close) error = %v, want %v", err, ErrDraftClosed)
+	}
+
+	if _, err := store.SetDraftWindow(models.DraftWindow{OpensAt: time.Now().Add(-time.Minute), ClosesAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("SetDraftWindow(open) failed: %v", err)
+	}
+	if err := store.DraftPlayer(player.ID, team.ID); err != nil {
+		t.Fatalf("DraftPlayer(within window) failed: %v", err)
+	}
+}
+
+func TestMemoryDraftWindow(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertDraftWindowEnforced(t, NewMemoryDAL())
+}
+
+func TestSQLiteDraftWindow(t *testing.T) {
+	assertDraftWindowEnforced(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresDraftWindow(t *testing.T) {
+	assertDraftWindowEnforced(t, newTestPostgresDAL(t))
+}
+
+func TestDraftWindowStatusCountdowns(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	window := models.DraftWindow{OpensAt: now.Add(90 * time.Second), ClosesAt: now.Add(time.Hour)}
+
+	status := window.Status(now)
+	if status.State != models.DraftWindowScheduled || status.OpensIn != 90 || status.ClosesIn != 3600 {
+		t.Fatalf("Status(before) = %+v, want scheduled with both countdowns", status)
+	}
+	if status := window.Status(now.Add(2 * time.Minute)); status.State != models.DraftWindowOpen || status.OpensIn != 0 {
+		t.Fatalf("Status(during) = %+v, want open", status)
+	}
+	if status := window.Status(now.Add(time.Hour)); status.State != models.DraftWindowClosed || status.ClosesIn != 0 {
+		t.Fatalf("Status(at close) = %+v, want closed", status)
+	}
+}
@@ -0,0 +1,110 @@
+package dal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// defaultEmotes are the reactions allowed when REACTION_EMOTES is unset.
+var defaultEmotes = []string{"👍", "❤️", "😂", "😮", "🎉", "🔥"}
+
+// AllowedEmotes returns the reactions AddReaction accepts: the
+// comma-separated REACTION_EMOTES, or a small default set when it is unset.
+func AllowedEmotes() []string {
+	emotes := strings.Split(os.Getenv("REACTION_EMOTES"), ",")
+	allowed := make([]string, 0, len(emotes))
+	for _, emote := range emotes {
+		if emote = strings.TrimSpace(emote); emote != "" {
+			allowed = append(allowed, emote)
+		}
+	}
+	if len(allowed) == 0 {
+		return defaultEmotes
+	}
+	return allowed
+}
+
+// normalizeEmote returns the allowed spelling of emote, or false when it is
+// not allowed. Variation selectors are ignored, so "❤" and "❤️" match.
+func normalizeEmote(emote string) (string, bool) {
+	key := emoteKey(emote)
+	if key == "" {
+		return "", false
+	}
+	for _, allowed := range AllowedEmotes() {
+		if emoteKey(allowed) == key {
+			return allowed, true
+		}
+	}
+	return "", false
+}
+
+func emoteKey(emote string) string {
+	return strings.NewReplacer("\uFE0E", "", "\uFE0F", "").Replace(strings.TrimSpace(emote))
+}
+
+// validateEmote returns the allowed spelling of emote, or an error matching
+// ErrInvalidEmote.
+func validateEmote(emote string) (string, error) {
+	normalized, ok := normalizeEmote(emote)
+	if !ok {
+		return "", fmt.Errorf("%w: %q (allowed: %s)", ErrInvalidEmote, emote, strings.Join(AllowedEmotes(), " "))
+	}
+	return normalized, nil
+}
+
+// cleanEmotes returns emotes with keys that are not allowed dropped and the
+// rest under their allowed spelling, and whether anything changed.
+func cleanEmotes(emotes map[string]int) (map[string]int, bool) {
+	cleaned := make(map[string]int, len(emotes))
+	changed := false
+	for emote, count := range emotes {
+		normalized, ok := normalizeEmote(emote)
+		if !ok || count <= 0 {
+			changed = true
+			continue
+		}
+		changed = changed || normalized != emote
+		cleaned[normalized] += count
+	}
+	return cleaned, changed
+}
+
+// cleanStoredEmotes rewrites the chat rows holding reactions that are no
+// longer allowed, such as free text sent before the emote whitelist.
+func cleanStoredEmotes(db *sql.DB, update func(id string, emotes []byte) error) error {
+	rows, err := db.Query(`SELECT id, emotes FROM chat`)
+	if err != nil {
+		return err
+	}
+	updates := map[string][]byte{}
+	for rows.Next() {
+		var id string
+		var emotesJSON []byte
+		if err := rows.Scan(&id, &emotesJSON); err != nil {
+			rows.Close()
+			return err
+		}
+		var emotes map[string]int
+		if json.Unmarshal(emotesJSON, &emotes) != nil {
+			emotes = map[string]int{}
+		}
+		if cleaned, changed := cleanEmotes(emotes); changed {
+			updates[id], _ = json.Marshal(cleaned)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, emotesJSON := range updates {
+		if err := update(id, emotesJSON); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package dal
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAllowedEmotes(t *testing.T) {
+	t.Setenv("REACTION_EMOTES", "")
+	if got := AllowedEmotes(); !reflect.DeepEqual(got, defaultEmotes) {
+		t.Fatalf("AllowedEmotes() = %v, want the defaults", got)
+	}
+
+	t.Setenv("REACTION_EMOTES", " 🧸, 🐰 ,,")
+	if got := AllowedEmotes(); !reflect.DeepEqual(got, []string{"🧸", "🐰"}) {
+		t.Fatalf("AllowedEmotes() = %v, want [🧸 🐰]", got)
+	}
+	if _, err := validateEmote("👍"); !errors.Is(err, ErrInvalidEmote) {
+		t.Fatalf("validateEmote(👍) = %v, want ErrInvalidEmote once it is not configured", err)
+	}
+}
+
+func TestValidateEmote(t *testing.T) {
+	t.Setenv("REACTION_EMOTES", "")
+	for emote, want := range map[string]string{
+		"🎉":   "🎉",
+		" 👍 ": "👍",
+		"❤":   "❤️", // the bare heart gets its emoji presentation
+		"❤️":  "❤️",
+	} {
+		if got, err := validateEmote(emote); err != nil || got != want {
+			t.Errorf("validateEmote(%q) = %q, %v; want %q", emote, got, err, want)
+		}
+	}
+	for _, emote := range []string{"", "lol", "heart", "🎉🎉", strings.Repeat("x", 200)} {
+		if _, err := validateEmote(emote); !errors.Is(err, ErrInvalidEmote) || !errors.Is(err, ErrValidation) {
+			t.Errorf("validateEmote(%q) = %v, want ErrInvalidEmote", emote, err)
+		}
+	}
+}
+
+func assertReactionEmotes(t *testing.T, store DraftDAL) {
+	t.Helper()
+	t.Setenv("REACTION_EMOTES", "")
+
+	msg, err := store.AddChatMessage("Great pick", "user")
+	if err != nil {
+		t.Fatalf("AddChatMessage() failed: %v", err)
+	}
+	if _, err := store.AddReaction(msg.ID, "lol", "user-1"); !errors.Is(err, ErrInvalidEmote) {
+		t.Fatalf("AddReaction(lol) error = %v, want ErrInvalidEmote", err)
+	}
+	if _, err := store.AddReaction(msg.ID, "❤", "user-1"); err != nil {
+		t.Fatalf("AddReaction(❤) failed: %v", err)
+	}
+	reacted, err := store.AddReaction(msg.ID, "❤️", "user-2")
+	if err != nil {
+		t.Fatalf("AddReaction(❤️) failed: %v", err)
+	}
+	if want := map[string]int{"❤️": 2}; !reflect.DeepEqual(reacted.Emotes, want) {
+		t.Fatalf("AddReaction() emotes = %v, want %v", reacted.Emotes, want)
+	}
+}
+
+func TestMemoryReactionEmotes(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertReactionEmotes(t, NewMemoryDAL())
+}
+
+func TestSQLiteReactionEmotes(t *testing.T) {
+	assertReactionEmotes(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresReactionEmotes(t *testing.T) {
+	assertReactionEmotes(t, newTestPostgresDAL(t))
+}
+
+func TestSQLiteMigrationCleansReactionEmotes(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("REACTION_EMOTES", "")
+	path := filepath.Join(t.TempDir(), "draft.sqlite")
+
+	store, err := NewSQLiteDAL(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteDAL() failed: %v", err)
+	}
+	msg, err := store.AddChatMessage("Great pick", "user")
+	if err != nil {
+		t.Fatalf("AddChatMessage() failed: %v", err)
+	}
+	store.db.Close()
+
+	// Reactions stored before the whitelist
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("sql.Open() failed: %v", err)
+	}
+	_, err = db.Exec(`UPDATE chat SET emotes = ? WHERE id = ?`, `{"lol":3,"🎉":2,"❤":1,"❤️":1,"`+strings.Repeat("x", 200)+`":1}`, msg.ID)
+	db.Close()
+	if err != nil {
+		t.Fatalf("store old reactions: %v", err)
+	}
+
+	store, err = NewSQLiteDAL(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteDAL() failed: %v", err)
+	}
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	if want := map[string]int{"🎉": 2, "❤️": 2}; len(state.Chat) != 1 || !reflect.DeepEqual(state.Chat[0].Emotes, want) {
+		t.Fatalf("GetState() chat = %+v, want emotes %v", state.Chat, want)
+	}
+}
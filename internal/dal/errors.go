@@ -0,0 +1,120 @@
+package dal
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Generic error kinds. Every DAL error that callers can act on matches one of
+// these under errors.Is, so transports can map them to status codes without
+// knowing each specific sentinel. ErrAlreadyDrafted is a kind of ErrConflict.
+var (
+	ErrNotFound       = errors.New("not found")
+	ErrConflict       = errors.New("conflict")
+	ErrAlreadyDrafted = newKindError(ErrConflict, "already drafted")
+	ErrValidation     = errors.New("validation failed")
+)
+
+// Sentinel errors returned by every DraftDAL backend. Callers should use
+// errors.Is rather than comparing messages.
+var (
+	ErrPlayerNotFound        = &NotFoundError{Entity: "player"}
+	ErrTeamNotFound          = &NotFoundError{Entity: "team"}
+	ErrMessageNotFound       = &NotFoundError{Entity: "message"}
+	ErrPickNotFound          = &NotFoundError{Entity: "pick"}
+	ErrPlayerAlreadyDrafted  = newKindError(ErrAlreadyDrafted, "player already drafted")
+	ErrPlayerReserved        = newKindError(ErrAlreadyDrafted, "player is reserved by another team")
+	ErrReservationUsed       = newKindError(ErrConflict, "team has already reserved a player for this pick")
+	ErrDraftedPlayerDelete   = newKindError(ErrAlreadyDrafted, "cannot delete a drafted player")
+	ErrTeamHasDraftedPlayers = newKindError(ErrAlreadyDrafted, "cannot delete a team that has drafted players")
+	ErrTeamAlreadyClaimed    = newKindError(ErrConflict, "team is already claimed by another user")
+	ErrOwnerHasTeam          = newKindError(ErrConflict, "owner already has a team")
+	ErrTokenNotFound         = &NotFoundError{Entity: "access token"}
+	ErrSessionNotFound       = &NotFoundError{Entity: "session"}
+	ErrDraftNotOpen          = newKindError(ErrValidation, "the draft has not opened yet")
+	ErrDraftClosed           = newKindError(ErrValidation, "the draft is closed")
+	ErrPlayerNotDrafted      = newKindError(ErrValidation, "player has not been drafted")
+	ErrNoPicksToUndo         = newKindError(ErrNotFound, "no picks to undo")
+	ErrNoPlayersLeft         = newKindError(ErrNotFound, "no undrafted players left")
+	ErrNothingToRestore      = newKindError(ErrNotFound, "no cleared chat messages to restore")
+	ErrRosterFull            = newKindError(ErrValidation, "team roster is full")
+	ErrAuctionDisabled       = newKindError(ErrValidation, "auction drafts are not enabled (DRAFT_MODE=auction)")
+	ErrAuctionPick           = newKindError(ErrValidation, "players are won by auction in this draft")
+	ErrChatDisabled          = newKindError(ErrNotFound, "chat is disabled (ENABLE_CHAT=false)")
+	ErrInvalidEmote          = newKindError(ErrValidation, "emote is not an allowed reaction")
+)
+
+// NotFoundError reports a missing entity, such as "player" or "team". It
+// matches ErrNotFound; use errors.As to read which entity was missing.
+type NotFoundError struct {
+	Entity string
+}
+
+func (e *NotFoundError) Error() string { return e.Entity + " not found" }
+
+func (e *NotFoundError) Unwrap() error { return ErrNotFound }
+
+// ValidationError reports a rejected input. It matches ErrValidation; Field
+// names the offending request field when there is one.
+type ValidationError struct {
+	Field string
+	msg   string
+}
+
+func (e *ValidationError) Error() string { return e.msg }
+
+func (e *ValidationError) Unwrap() error { return ErrValidation }
+
+// kindError keeps its own message while matching a generic kind.
+type kindError struct {
+	kind error
+	msg  string
+}
+
+func newKindError(kind error, msg string) error {
+	return &kindError{kind: kind, msg: msg}
+}
+
+func (e *kindError) Error() string { return e.msg }
+
+func (e *kindError) Unwrap() error { return e.kind }
+
+// validationErrorf formats a ValidationError that is not about one field.
+func validationErrorf(format string, args ...interface{}) error {
+	return fieldErrorf("", format, args...)
+}
+
+// fieldErrorf formats a ValidationError about field, named as clients send
+// it (for example "name" or "points").
+func fieldErrorf(field, format string, args ...interface{}) error {
+	return &ValidationError{Field: field, msg: fmt.Sprintf(format, args...)}
+}
+
+// noRows returns notFound in place of sql.ErrNoRows, so a missing row never
+// reaches callers as a raw database error.
+func noRows(err, notFound error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFound
+	}
+	return err
+}
+
+// wrappedKindError marks err as a kind while keeping err itself matchable.
+type wrappedKindError struct {
+	kind error
+	err  error
+}
+
+func (e *wrappedKindError) Error() string { return e.err.Error() }
+
+func (e *wrappedKindError) Unwrap() []error { return []error{e.kind, e.err} }
+
+// auctionError reports an internal/draft auction rule violation as
+// ErrValidation.
+func auctionError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &wrappedKindError{kind: ErrValidation, err: err}
+}
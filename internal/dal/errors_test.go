@@ -0,0 +1,65 @@
+package dal
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+func TestErrorKinds(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		kind error
+	}{
+		{ErrPlayerAlreadyDrafted, ErrAlreadyDrafted},
+		{ErrPlayerAlreadyDrafted, ErrConflict},
+		{ErrTeamAlreadyClaimed, ErrConflict},
+		{ErrTeamNotFound, ErrNotFound},
+		{ErrNoPicksToUndo, ErrNotFound},
+		{fieldErrorf("points", "bad"), ErrValidation},
+	} {
+		if !errors.Is(tc.err, tc.kind) {
+			t.Errorf("errors.Is(%v, %v) = false, want true", tc.err, tc.kind)
+		}
+	}
+	if errors.Is(ErrTeamAlreadyClaimed, ErrAlreadyDrafted) {
+		t.Errorf("ErrTeamAlreadyClaimed matches ErrAlreadyDrafted")
+	}
+
+	var notFound *NotFoundError
+	if !errors.As(ErrMessageNotFound, &notFound) || notFound.Entity != "message" {
+		t.Errorf("errors.As(ErrMessageNotFound) entity = %+v, want message", notFound)
+	}
+	var invalid *ValidationError
+	err := ValidateNewPlayer(&models.Player{Name: "Bunny", Tier: "Z"})
+	if !errors.As(err, &invalid) || invalid.Field != "tier" {
+		t.Errorf("ValidateNewPlayer(bad tier) = %v, want a tier ValidationError", err)
+	}
+}
+
+func assertReactionToMissingMessage(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	if _, err := store.AddReaction("missing", "👍", "user-1"); !errors.Is(err, ErrMessageNotFound) {
+		t.Fatalf("AddReaction(missing) error = %v, want ErrMessageNotFound", err)
+	}
+	// The failed reaction must not count as the user's, or a retry would
+	// skip the update.
+	if _, err := store.AddReaction("missing", "👍", "user-1"); !errors.Is(err, ErrMessageNotFound) {
+		t.Fatalf("AddReaction(missing) retry error = %v, want ErrMessageNotFound", err)
+	}
+}
+
+func TestMemoryReactionToMissingMessage(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertReactionToMissingMessage(t, NewMemoryDAL())
+}
+
+func TestSQLiteReactionToMissingMessage(t *testing.T) {
+	assertReactionToMissingMessage(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresReactionToMissingMessage(t *testing.T) {
+	assertReactionToMissingMessage(t, newTestPostgresDAL(t))
+}
@@ -7,6 +7,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
 )
 
 // LoadImagesIntoDatabase loads image files from the static/images directory into the database
@@ -63,7 +65,7 @@ func LoadImagesIntoDatabase(db *sql.DB, imagesDir string) error {
 
 		rowsAffected, _ := result.RowsAffected()
 		if rowsAffected > 0 {
-			fmt.Printf("Loaded image data for %s (%d bytes, %d players updated)\n", fileName, len(imageData), rowsAffected)
+			logger.Info("Loaded image data", "file", fileName, "bytes", len(imageData), "players", rowsAffected)
 		}
 	}
 
@@ -123,11 +125,11 @@ func (p *PostgresDAL) GetImageByPath(imagePath string) ([]byte, string, error) {
 // SaveImage stores or replaces an image asset at a public /images/... path.
 func (p *PostgresDAL) SaveImage(path, contentType string, data []byte) error {
 	if !strings.HasPrefix(path, "/images/") {
-		return fmt.Errorf("image path must start with /images/")
+		return validationErrorf("image path must start with /images/")
 	}
 	filename := strings.TrimPrefix(path, "/images/")
 	if filename == "" {
-		return fmt.Errorf("image filename is required")
+		return validationErrorf("image filename is required")
 	}
 	if contentType == "" {
 		contentType = contentTypeForFilename(filename)
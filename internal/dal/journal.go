@@ -0,0 +1,187 @@
+package dal
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"sort"
+	"sync"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// ChangeJournal numbers changes to players, teams and chat messages so
+// clients can fetch only what changed since their last look. It works over
+// any DraftDAL by fingerprinting entities in GetState snapshots: every
+// snapshot that finds an entity new, changed or gone stamps it with the next
+// sequence number. Player analytics are derived from the whole board, so they
+// do not count as changes. Sequences are per process, so a client that presents one
+// the journal never issued gets a full reset instead.
+type ChangeJournal struct {
+	mu      sync.Mutex
+	seq     int64
+	players journalEntries
+	teams   journalEntries
+	chat    journalEntries
+}
+
+// journalEntries tracks one entity kind by ID.
+type journalEntries map[string]*journalEntry
+
+type journalEntry struct {
+	fingerprint uint64
+	seq         int64
+	removed     bool
+}
+
+// NewChangeJournal creates an empty journal.
+func NewChangeJournal() *ChangeJournal {
+	return &ChangeJournal{
+		players: journalEntries{},
+		teams:   journalEntries{},
+		chat:    journalEntries{},
+	}
+}
+
+// Diff snapshots store and returns the changes since seq. seq 0 returns the
+// whole board as a reset.
+func (j *ChangeJournal) Diff(store DraftDAL, since int64) (*models.DraftDiff, error) {
+	state, err := store.GetState()
+	if err != nil {
+		return nil, err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.observe(state)
+
+	diff := &models.DraftDiff{
+		Seq:            j.seq,
+		Players:        []models.Player{},
+		RemovedPlayers: []string{},
+		Teams:          []models.Team{},
+		RemovedTeams:   []string{},
+		Chat:           []models.ChatMessage{},
+		RemovedChat:    []string{},
+		TeamOrder:      make([]string, len(state.Teams)),
+		Settings:       state.Settings,
+		CurrentPick:    state.CurrentPick,
+		CurrentRound:   state.CurrentRound,
+		PickInRound:    state.PickInRound,
+		CurrentTeamID:  state.CurrentTeamID,
+		DraftOrder:     state.DraftOrder,
+		SuggestedPick:  state.SuggestedPick,
+	}
+	if since <= 0 || since > j.seq {
+		diff.Reset = true
+		since = 0
+	}
+
+	for _, player := range state.Players {
+		if j.players[player.ID].seq > since {
+			diff.Players = append(diff.Players, player)
+		}
+	}
+	for i, team := range state.Teams {
+		diff.TeamOrder[i] = team.ID
+		if j.teams[team.ID].seq > since {
+			diff.Teams = append(diff.Teams, team)
+		}
+	}
+	for _, message := range state.Chat {
+		if j.chat[message.ID].seq > since {
+			diff.Chat = append(diff.Chat, message)
+		}
+	}
+	if !diff.Reset {
+		diff.RemovedPlayers = j.players.removedSince(since)
+		diff.RemovedTeams = j.teams.removedSince(since)
+		diff.RemovedChat = j.chat.removedSince(since)
+	}
+	return diff, nil
+}
+
+// Snapshot returns store's state with the sequence number it is current as
+// of, so a client can start from the state and ask Diff for what changed
+// since.
+func (j *ChangeJournal) Snapshot(store DraftDAL) (*models.DraftState, int64, error) {
+	state, err := store.GetState()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.observe(state)
+	return state, j.seq, nil
+}
+
+// observe stamps what changed in state with the next sequence number. The
+// caller holds j.mu.
+func (j *ChangeJournal) observe(state *models.DraftState) {
+	next := j.seq + 1
+	changed := j.players.record(next, len(state.Players), func(i int) (string, interface{}) {
+		return state.Players[i].ID, withoutAnalytics(state.Players[i])
+	})
+	changed = j.teams.record(next, len(state.Teams), func(i int) (string, interface{}) {
+		team := state.Teams[i]
+		team.Players = make([]models.Player, len(state.Teams[i].Players))
+		for k, player := range state.Teams[i].Players {
+			team.Players[k] = withoutAnalytics(player)
+		}
+		return team.ID, team
+	}) || changed
+	changed = j.chat.record(next, len(state.Chat), func(i int) (string, interface{}) { return state.Chat[i].ID, state.Chat[i] }) || changed
+	if changed {
+		j.seq = next
+	}
+}
+
+// record stamps entities that are new or changed, and those missing from the
+// snapshot, with seq. It reports whether anything was stamped.
+func (e journalEntries) record(seq int64, count int, entity func(int) (string, interface{})) bool {
+	changed := false
+	seen := make(map[string]bool, count)
+	for i := 0; i < count; i++ {
+		id, value := entity(i)
+		seen[id] = true
+		fingerprint := fingerprintOf(value)
+		if entry, ok := e[id]; ok && !entry.removed && entry.fingerprint == fingerprint {
+			continue
+		}
+		e[id] = &journalEntry{fingerprint: fingerprint, seq: seq}
+		changed = true
+	}
+	for id, entry := range e {
+		if !seen[id] && !entry.removed {
+			entry.removed = true
+			entry.seq = seq
+			changed = true
+		}
+	}
+	return changed
+}
+
+func (e journalEntries) removedSince(since int64) []string {
+	ids := []string{}
+	for id, entry := range e {
+		if entry.removed && entry.seq > since {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// withoutAnalytics drops the analytics GetState recomputes for every player on
+// each pick, which would otherwise mark the whole board changed.
+func withoutAnalytics(player models.Player) models.Player {
+	player.Analytics = models.PlayerAnalytics{}
+	return player
+}
+
+func fingerprintOf(value interface{}) uint64 {
+	data, _ := json.Marshal(value)
+	hash := fnv.New64a()
+	hash.Write(data)
+	return hash.Sum64()
+}
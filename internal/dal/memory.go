@@ -1,13 +1,14 @@
 package dal
 
 import (
-	"crypto/rand"
-	"encoding/hex"
-	"fmt"
+	"context"
+	"maps"
 	"os"
 	"sync"
 	"time"
 
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/draft"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/ids"
 	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
 )
 
@@ -23,8 +24,17 @@ type MemoryDAL struct {
 	players       []models.Player
 	teams         []models.Team
 	chat          []models.ChatMessage
+	clearedChat   []clearedMessage // restorable until the grace period passes
 	settings      models.DraftSettings
+	window        models.DraftWindow
+	meta          models.DraftMeta
+	picks         []memoryPick                          // in draft order
 	reactionUsers map[string]map[string]map[string]bool // messageID -> emote -> userID -> bool
+	tokens        []models.AccessToken
+	sessions      map[string]storedSession
+	authEvents    []models.AuthEvent
+	auction       *draft.Auction // nil until the first nomination
+	version       int64
 }
 
 // NewMemoryDAL creates a new in-memory data access layer
@@ -47,17 +57,55 @@ func NewMemoryDAL() *MemoryDAL {
 		chat:          []models.ChatMessage{},
 		settings:      models.DefaultDraftSettings(),
 		reactionUsers: make(map[string]map[string]map[string]bool),
+		sessions:      make(map[string]storedSession),
 	}
 
 	if SeedDefaultCatalogEnabled() {
-		dal.AddChatMessage("Welcome to the Jellycat Draft!", "system")
-		dal.AddChatMessage("Tip: pair your phone with the TV room code before picking.", "system")
-		dal.AddChatMessage("Who will snag Bashful Bunny first?", "system")
+		for _, key := range welcomeChatKeys {
+			dal.addSystemChatUnsafe(newSystemChatMessage(key, nil))
+		}
 	}
 
 	return dal
 }
 
+// NewMemoryDALFromState creates an in-memory DAL holding a copy of state's
+// players, teams and settings, for practice drafts that must not touch the
+// real store. Chat and the draft window are not carried over. Existing picks
+// are replayed team by team, so undo works but may not follow the original
+// pick order.
+func NewMemoryDALFromState(state *models.DraftState) *MemoryDAL {
+	players := make([]models.Player, len(state.Players))
+	copy(players, state.Players)
+
+	teams := make([]models.Team, len(state.Teams))
+	var picks []memoryPick
+	for i, team := range state.Teams {
+		team.Players = append([]models.Player{}, team.Players...)
+		team.RosterSlotsRemaining = nil
+		team.Budget = nil
+		teams[i] = team
+		for _, player := range team.Players {
+			picks = append(picks, memoryPick{playerID: player.ID, teamID: team.ID, points: player.Points, cuddlePoints: player.CuddlePoints})
+		}
+	}
+
+	return &MemoryDAL{
+		players:       players,
+		teams:         teams,
+		chat:          []models.ChatMessage{},
+		settings:      state.Settings,
+		picks:         picks,
+		reactionUsers: make(map[string]map[string]map[string]bool),
+		sessions:      make(map[string]storedSession),
+	}
+}
+
+// Ping always succeeds unless ctx is done; there is no connection to lose.
+func (m *MemoryDAL) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
 func (m *MemoryDAL) GetState() (*models.DraftState, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -68,18 +116,64 @@ func (m *MemoryDAL) GetState() (*models.DraftState, error) {
 		Teams:    make([]models.Team, len(m.teams)),
 		Chat:     make([]models.ChatMessage, len(m.chat)),
 		Settings: m.settings,
+		Version:  m.version,
 	}
 
 	copy(state.Players, m.players)
-	copy(state.Teams, m.teams)
 	copy(state.Chat, m.chat)
+	// Rosters and reaction maps would be shared by a plain copy. Callers
+	// read them after the lock is released, while picks and reactions
+	// change them, and the pick analytics below write into the rosters
+	for i := range state.Teams {
+		state.Teams[i] = cloneTeam(m.teams[i])
+	}
+	for i := range state.Chat {
+		state.Chat[i] = cloneChatMessage(m.chat[i])
+	}
+	localizeChat(state.Chat)
+	if !ChatEnabled() {
+		state.Chat = state.Chat[:0]
+	}
 
 	// Calculate current pick number and whose turn it is
 	CalculateCurrentPick(state, state.Players)
+	if draft.AuctionEnabled() {
+		m.currentAuction().Apply(state)
+	}
 
 	return state, nil
 }
 
+// cloneTeam copies team with its own roster.
+func cloneTeam(team models.Team) models.Team {
+	team.Players = append([]models.Player{}, team.Players...)
+	return team
+}
+
+// cloneTeams copies teams, each with its own roster.
+func cloneTeams(teams []models.Team) []models.Team {
+	out := make([]models.Team, len(teams))
+	for i := range teams {
+		out[i] = cloneTeam(teams[i])
+	}
+	return out
+}
+
+// cloneChatMessage copies msg with its own Emotes and Params maps.
+func cloneChatMessage(msg models.ChatMessage) models.ChatMessage {
+	msg.Emotes = maps.Clone(msg.Emotes)
+	msg.Params = maps.Clone(msg.Params)
+	return msg
+}
+
+// localizedCopy returns a clone of msg rendered in LANG, for handing a
+// stored message to callers that read it after the lock is released.
+func localizedCopy(msg *models.ChatMessage) *models.ChatMessage {
+	out := cloneChatMessage(*msg)
+	localizeChatMessage(&out)
+	return &out
+}
+
 func (m *MemoryDAL) Reset() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -97,8 +191,14 @@ func (m *MemoryDAL) Reset() error {
 	}
 	m.teams = teams
 	m.chat = []models.ChatMessage{}
+	m.clearedChat = nil
 	m.settings = models.DefaultDraftSettings()
+	m.window = models.DraftWindow{}
+	m.meta = models.DraftMeta{}
+	m.picks = nil
+	m.auction = nil
 	m.reactionUsers = make(map[string]map[string]map[string]bool)
+	m.version++
 
 	return nil
 }
@@ -109,29 +209,64 @@ func (m *MemoryDAL) SetDraftMode(mode models.DraftMode) (*models.DraftSettings,
 
 	settings := models.DraftSettingsForMode(mode)
 	m.settings = settings
-	m.addChatMessageUnsafe(fmt.Sprintf("Draft mode set to %s", settings.Name), "system")
+	m.addSystemChatUnsafe(draftModeSetChatMessage(settings))
+	m.version++
 
 	return &settings, nil
 }
 
+func (m *MemoryDAL) GetDraftWindow() (models.DraftWindow, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.window, nil
+}
+
+func (m *MemoryDAL) SetDraftWindow(window models.DraftWindow) (*models.DraftWindow, error) {
+	window, err := normalizeDraftWindow(window)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.window = window
+	m.version++
+	return &window, nil
+}
+
 func (m *MemoryDAL) AddPlayer(player *models.Player) (*models.Player, error) {
+	name, err := sanitizeName("player", player.Name)
+	if err != nil {
+		return nil, err
+	}
+	player.Name = name
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	if player.ID == "" {
-		player.ID = genID("player")
+		player.ID = ids.New("player")
 	}
 
 	// Assign random cuddle points if not already set
 	if player.CuddlePoints == 0 {
 		player.CuddlePoints = randomCuddlePoints()
 	}
+	player.CreatedAt = timestampNow()
+	player.UpdatedAt = player.CreatedAt
 
 	m.players = append(m.players, *player)
+	m.version++
 	return player, nil
 }
 
 func (m *MemoryDAL) UpdatePlayer(player *models.Player) (*models.Player, error) {
+	name, err := sanitizeName("player", player.Name)
+	if err != nil {
+		return nil, err
+	}
+	player.Name = name
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -155,6 +290,7 @@ func (m *MemoryDAL) UpdatePlayer(player *models.Player) (*models.Player, error)
 			m.players[i].CuddlePoints = player.CuddlePoints
 			m.players[i].Tier = player.Tier
 			m.players[i].Image = player.Image
+			m.players[i].UpdatedAt = timestampNow()
 
 			// Update in team rosters too
 			for j := range m.teams {
@@ -164,12 +300,14 @@ func (m *MemoryDAL) UpdatePlayer(player *models.Player) (*models.Player, error)
 					}
 				}
 			}
+			m.version++
 
-			return &m.players[i], nil
+			updated := m.players[i]
+			return &updated, nil
 		}
 	}
 
-	return nil, fmt.Errorf("player not found")
+	return nil, ErrPlayerNotFound
 }
 
 func (m *MemoryDAL) DeletePlayer(id string) error {
@@ -182,7 +320,7 @@ func (m *MemoryDAL) DeletePlayer(id string) error {
 		if m.players[i].ID == id {
 			// Cannot delete a drafted player
 			if m.players[i].Drafted {
-				return fmt.Errorf("cannot delete a drafted player")
+				return ErrDraftedPlayerDelete
 			}
 			m.players = append(m.players[:i], m.players[i+1:]...)
 			found = true
@@ -191,8 +329,9 @@ func (m *MemoryDAL) DeletePlayer(id string) error {
 	}
 
 	if !found {
-		return fmt.Errorf("player not found")
+		return ErrPlayerNotFound
 	}
+	m.version++
 
 	return nil
 }
@@ -204,55 +343,69 @@ func (m *MemoryDAL) SetPlayerPoints(id string, points int) (*models.Player, erro
 	for i := range m.players {
 		if m.players[i].ID == id {
 			m.players[i].Points = points
+			m.players[i].UpdatedAt = timestampNow()
 
 			// Update in team rosters too
 			for j := range m.teams {
 				for k := range m.teams[j].Players {
 					if m.teams[j].Players[k].ID == id {
 						m.teams[j].Players[k].Points = points
+						m.teams[j].Players[k].UpdatedAt = m.players[i].UpdatedAt
 					}
 				}
 			}
+			m.version++
 
-			return &m.players[i], nil
+			updated := m.players[i]
+			return &updated, nil
 		}
 	}
 
-	return nil, fmt.Errorf("player not found")
+	return nil, ErrPlayerNotFound
 }
 
 func (m *MemoryDAL) ReorderTeams(order []string) ([]models.Team, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	idToTeam := make(map[string]models.Team)
-	for _, team := range m.teams {
+	teamIDs := make([]string, len(m.teams))
+	idToTeam := make(map[string]models.Team, len(m.teams))
+	for i, team := range m.teams {
+		teamIDs[i] = team.ID
 		idToTeam[team.ID] = team
 	}
-
-	reordered := []models.Team{}
-	for _, id := range order {
-		if team, ok := idToTeam[id]; ok {
-			reordered = append(reordered, team)
-			delete(idToTeam, id)
-		}
+	if err := validateTeamOrder(order, teamIDs); err != nil {
+		return nil, err
 	}
 
-	// Append any missing teams
-	for _, team := range m.teams {
-		if _, ok := idToTeam[team.ID]; ok {
-			reordered = append(reordered, team)
-		}
+	reordered := make([]models.Team, 0, len(order))
+	for _, id := range order {
+		reordered = append(reordered, idToTeam[id])
 	}
 
 	m.teams = reordered
-	return m.teams, nil
+	m.version++
+	return cloneTeams(m.teams), nil
 }
 
 func (m *MemoryDAL) DraftPlayer(playerID, teamID string) error {
+	if draft.AuctionEnabled() {
+		return ErrAuctionPick
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	return m.draftPlayerUnsafe(playerID, teamID, true)
+}
+
+// draftPlayerUnsafe adds playerID to teamID's roster. Auction awards skip the
+// turn check, since the high bidder wins whoever's turn it is.
+func (m *MemoryDAL) draftPlayerUnsafe(playerID, teamID string, checkTurn bool) error {
+	if err := checkDraftWindow(m.window, time.Now()); err != nil {
+		return err
+	}
+
 	var player *models.Player
 	var team *models.Team
 
@@ -271,18 +424,25 @@ func (m *MemoryDAL) DraftPlayer(playerID, teamID string) error {
 	}
 
 	if player == nil {
-		return fmt.Errorf("player not found")
+		return ErrPlayerNotFound
 	}
 	if team == nil {
-		return fmt.Errorf("team not found")
+		return ErrTeamNotFound
 	}
 	if player.Drafted {
-		return fmt.Errorf("player already drafted")
+		return ErrPlayerAlreadyDrafted
+	}
+	if checkTurn {
+		if err := validateTeamTurn(m.teams, m.settings.Mode, m.players, teamID); err != nil {
+			return err
+		}
 	}
-	if err := validateTeamTurn(m.teams, m.settings.Mode, m.players, teamID); err != nil {
+	if err := checkRosterSize(team.Name, len(team.Players)); err != nil {
 		return err
 	}
 
+	m.picks = append(m.picks, memoryPick{playerID: player.ID, teamID: team.ID, points: player.Points, cuddlePoints: player.CuddlePoints})
+
 	personalizedPlayer := personalizePlayerForTeam(*player, *team)
 	player.Points = personalizedPlayer.Points
 	player.CuddlePoints = personalizedPlayer.CuddlePoints
@@ -294,53 +454,69 @@ func (m *MemoryDAL) DraftPlayer(playerID, teamID string) error {
 	}
 	draftPickNumber += 1
 
-	// Calculate cuddle points adjustment based on draft position
-	cuddlePointsAdjustment := 0
-	if draftPickNumber <= 6 {
-		cuddlePointsAdjustment = 20 - (draftPickNumber * 2)
-	} else if draftPickNumber >= 13 {
-		cuddlePointsAdjustment = 8 - draftPickNumber
-	}
-
-	newCuddlePoints := player.CuddlePoints + cuddlePointsAdjustment
-	if newCuddlePoints < 10 {
-		newCuddlePoints = 10
-	}
-	if newCuddlePoints > 100 {
-		newCuddlePoints = 100
-	}
+	// Early picks gain cuddle points, late picks lose them
+	newCuddlePoints := draftedCuddlePoints(player.CuddlePoints, draftPickNumber)
 
 	player.Drafted = true
 	player.DraftedBy = team.Name
 	player.CuddlePoints = newCuddlePoints
+	player.UpdatedAt = timestampNow()
 	team.Players = append(team.Players, *player)
 
 	// Add system message
-	msg := fmt.Sprintf("%s %s drafted %s (%s • %s)", team.Mascot, team.Name, player.Name, player.Team, player.Position)
-	m.addChatMessageUnsafe(msg, "system")
+	m.addSystemChatUnsafe(pickChatMessage(team.Mascot, team.Name, *player))
+	m.version++
 
 	return nil
 }
 
 func (m *MemoryDAL) AddChatMessage(text, msgType string) (*models.ChatMessage, error) {
+	if !ChatEnabled() {
+		return nil, ErrChatDisabled
+	}
+	text, err := sanitizeChatText(text)
+	if err != nil {
+		return nil, err
+	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	return m.addChatMessageUnsafe(text, msgType), nil
+	msg := m.addChatMessageUnsafe(text, msgType)
+	m.version++
+	return msg, nil
 }
 
 func (m *MemoryDAL) addChatMessageUnsafe(text, msgType string) *models.ChatMessage {
+	if !ChatEnabled() {
+		return nil
+	}
 	msg := &models.ChatMessage{
-		ID:     genID("msg"),
+		ID:     ids.NewSortable("msg"),
 		TS:     time.Now().UnixMilli(),
 		Type:   msgType,
 		Text:   text,
+		Sender: chatSender(msgType),
 		Emotes: make(map[string]int),
 	}
+	stampChatMessage(msg, 0)
 	m.chat = append(m.chat, *msg)
 	return msg
 }
 
+// addSystemChatUnsafe appends a system message built from a message key.
+func (m *MemoryDAL) addSystemChatUnsafe(msg *models.ChatMessage) {
+	if ChatEnabled() {
+		m.chat = append(m.chat, *msg)
+	}
+}
+
 func (m *MemoryDAL) AddReaction(messageID, emote, userID string) (*models.ChatMessage, error) {
+	if !ChatEnabled() {
+		return nil, ErrChatDisabled
+	}
+	emote, err := validateEmote(emote)
+	if err != nil {
+		return nil, err
+	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -353,7 +529,7 @@ func (m *MemoryDAL) AddReaction(messageID, emote, userID string) (*models.ChatMe
 	}
 
 	if msg == nil {
-		return nil, fmt.Errorf("message not found")
+		return nil, ErrMessageNotFound
 	}
 
 	uid := userID
@@ -370,67 +546,71 @@ func (m *MemoryDAL) AddReaction(messageID, emote, userID string) (*models.ChatMe
 	}
 
 	if m.reactionUsers[messageID][emote][uid] {
-		return msg, nil // Already reacted
+		return localizedCopy(msg), nil // Already reacted
 	}
 
 	m.reactionUsers[messageID][emote][uid] = true
 	msg.Emotes[emote]++
+	msg.UpdatedAt = timestampNow()
+	m.version++
 
-	return msg, nil
+	return localizedCopy(msg), nil
 }
 
 func (m *MemoryDAL) AddTeam(name, owner, mascot, color string) (*models.Team, error) {
+	name, err := sanitizeName("team", name)
+	if err != nil {
+		return nil, err
+	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	mascots := []string{"🦊", "🐻", "🐰", "🐱", "🐑", "🦒", "🐨", "🦁", "🐼", "🦄", "🐯", "🐶"}
-	colors := []string{
-		"bg-orange-100 border-orange-300",
-		"bg-amber-100 border-amber-300",
-		"bg-pink-100 border-pink-300",
-		"bg-purple-100 border-purple-300",
-		"bg-blue-100 border-blue-300",
-		"bg-yellow-100 border-yellow-300",
-		"bg-green-100 border-green-300",
+	if err := checkUniqueOwner(m.teams, "", "", owner); err != nil {
+		return nil, err
 	}
 
-	if mascot == "" {
-		mascot = mascots[len(m.teams)%len(mascots)]
-	}
-	if color == "" {
-		color = colors[len(m.teams)%len(colors)]
-	}
+	mascot, color = teamDefaults(len(m.teams), mascot, color)
 
+	now := timestampNow()
 	team := &models.Team{
-		ID:      genID("team"),
-		Name:    name,
-		Owner:   owner,
-		Mascot:  mascot,
-		Color:   color,
-		Players: []models.Player{},
+		ID:        ids.New("team"),
+		Name:      name,
+		Owner:     owner,
+		Mascot:    mascot,
+		Color:     color,
+		Players:   []models.Player{},
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 
 	m.teams = append(m.teams, *team)
 
-	ownerLabel := team.Owner
-	if ownerLabel == "" {
-		ownerLabel = "Unassigned"
-	}
-	msg := fmt.Sprintf("New team joined the draft: %s %s (Owner: %s)", team.Mascot, team.Name, ownerLabel)
-	m.addChatMessageUnsafe(msg, "system")
+	m.addSystemChatUnsafe(teamJoinedChatMessage(team))
+	m.version++
 
 	return team, nil
 }
 
 func (m *MemoryDAL) UpdateTeam(id, name, owner, mascot, color string) (*models.Team, error) {
+	name, err := sanitizeName("team", name)
+	if err != nil {
+		return nil, err
+	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	for i := range m.teams {
 		if m.teams[i].ID == id {
+			if err := checkUniqueOwner(m.teams, id, "", owner); err != nil {
+				return nil, err
+			}
 			if name != "" {
 				m.teams[i].Name = name
 			}
+			if m.teams[i].Owner != owner {
+				// A changed owner string no longer identifies the claimed user.
+				m.teams[i].OwnerUserID = ""
+			}
 			m.teams[i].Owner = owner
 			if mascot != "" {
 				m.teams[i].Mascot = mascot
@@ -438,11 +618,61 @@ func (m *MemoryDAL) UpdateTeam(id, name, owner, mascot, color string) (*models.T
 			if color != "" {
 				m.teams[i].Color = color
 			}
-			return &m.teams[i], nil
+			m.teams[i].UpdatedAt = timestampNow()
+			m.version++
+			updated := cloneTeam(m.teams[i])
+			return &updated, nil
+		}
+	}
+
+	return nil, ErrTeamNotFound
+}
+
+func (m *MemoryDAL) ClaimTeam(teamID, userID, owner string) (*models.Team, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.teams {
+		if m.teams[i].ID != teamID {
+			continue
+		}
+		team := &m.teams[i]
+		if team.OwnerUserID != userID && (team.OwnerUserID != "" || (team.Owner != "" && team.Owner != owner)) {
+			return nil, ErrTeamAlreadyClaimed
+		}
+		if err := checkUniqueOwner(m.teams, teamID, userID, owner); err != nil {
+			return nil, err
+		}
+		team.OwnerUserID = userID
+		team.Owner = owner
+		team.UpdatedAt = timestampNow()
+		m.version++
+		claimed := cloneTeam(*team)
+		return &claimed, nil
+	}
+
+	return nil, ErrTeamNotFound
+}
+
+func (m *MemoryDAL) AssignTeamOwner(teamID, userID, owner string) (*models.Team, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.teams {
+		if m.teams[i].ID == teamID {
+			if err := checkUniqueOwner(m.teams, teamID, userID, owner); err != nil {
+				return nil, err
+			}
+			m.teams[i].OwnerUserID = userID
+			m.teams[i].Owner = owner
+			m.teams[i].UpdatedAt = timestampNow()
+			m.version++
+			assigned := cloneTeam(m.teams[i])
+			return &assigned, nil
 		}
 	}
 
-	return nil, fmt.Errorf("team not found")
+	return nil, ErrTeamNotFound
 }
 
 func (m *MemoryDAL) DeleteTeam(id string) error {
@@ -453,52 +683,48 @@ func (m *MemoryDAL) DeleteTeam(id string) error {
 		if m.teams[i].ID == id {
 			// Cannot delete a team that has drafted players
 			if len(m.teams[i].Players) > 0 {
-				return fmt.Errorf("cannot delete a team that has drafted players")
+				return ErrTeamHasDraftedPlayers
 			}
 			m.teams = append(m.teams[:i], m.teams[i+1:]...)
+			m.version++
 			return nil
 		}
 	}
 
-	return fmt.Errorf("team not found")
-}
-
-func genID(prefix string) string {
-	b := make([]byte, 4)
-	rand.Read(b)
-	return fmt.Sprintf("%s_%s", prefix, hex.EncodeToString(b))
+	return ErrTeamNotFound
 }
 
 func getDefaultPlayers() []models.Player {
-	return []models.Player{
-		{ID: "1", Name: "Bashful Bunny", Position: "CC", Team: "Woodland", Points: 324, CuddlePoints: 50, Tier: models.TierS, Drafted: false, Image: "/images/bashful-bunny.png"},
-		{ID: "2", Name: "Fuddlewuddle Lion", Position: "SS", Team: "Safari", Points: 298, CuddlePoints: 50, Tier: models.TierS, Drafted: false, Image: "/images/fuddlewuddle-lion.png"},
-		{ID: "3", Name: "Cordy Roy Elephant", Position: "HH", Team: "Safari", Points: 287, CuddlePoints: 50, Tier: models.TierS, Drafted: false, Image: "/images/cordy-roy-elephant.png"},
-		{ID: "4", Name: "Blossom Tulip Bunny", Position: "CH", Team: "Garden", Points: 251, CuddlePoints: 50, Tier: models.TierA, Drafted: false, Image: "/images/blossom-tulip-bunny.png"},
-		{ID: "5", Name: "Amuseable Avocado", Position: "CC", Team: "Kitchen", Points: 312, CuddlePoints: 50, Tier: models.TierS, Drafted: false, Image: "/images/amuseable-avocado.png"},
-		{ID: "6", Name: "Octopus Ollie", Position: "SS", Team: "Ocean", Points: 276, CuddlePoints: 50, Tier: models.TierA, Drafted: false, Image: "/images/octopus-ollie.png"},
-		{ID: "7", Name: "Jellycat Dragon", Position: "HH", Team: "Fantasy", Points: 268, CuddlePoints: 50, Tier: models.TierA, Drafted: false, Image: "/images/jellycat-dragon.png"},
-		{ID: "8", Name: "Bashful Lamb", Position: "CH", Team: "Farm", Points: 245, CuddlePoints: 50, Tier: models.TierA, Drafted: false, Image: "/images/bashful-lamb.png"},
-		{ID: "9", Name: "Amuseable Pineapple", Position: "CC", Team: "Tropical", Points: 289, CuddlePoints: 50, Tier: models.TierS, Drafted: false, Image: "/images/amuseable-pineapple.png"},
-		{ID: "10", Name: "Cordy Roy Fox", Position: "SS", Team: "Woodland", Points: 234, CuddlePoints: 50, Tier: models.TierA, Drafted: false, Image: "/images/cordy-roy-fox.png"},
-		{ID: "11", Name: "Blossom Peach Bunny", Position: "HH", Team: "Garden", Points: 256, CuddlePoints: 50, Tier: models.TierA, Drafted: false, Image: "/images/blossom-peach-bunny.png"},
-		{ID: "12", Name: "Amuseable Taco", Position: "CH", Team: "Kitchen", Points: 267, CuddlePoints: 50, Tier: models.TierA, Drafted: false, Image: "/images/amuseable-taco.png"},
-		{ID: "13", Name: "Bashful Unicorn", Position: "CC", Team: "Fantasy", Points: 278, CuddlePoints: 50, Tier: models.TierA, Drafted: false, Image: "/images/bashful-unicorn.png"},
-		{ID: "14", Name: "Jellycat Penguin", Position: "SS", Team: "Arctic", Points: 243, CuddlePoints: 50, Tier: models.TierB, Drafted: false, Image: "/images/jellycat-penguin.png"},
-		{ID: "15", Name: "Amuseable Moon", Position: "HH", Team: "Space", Points: 229, CuddlePoints: 50, Tier: models.TierB, Drafted: false, Image: "/images/amuseable-moon.png"},
-		{ID: "16", Name: "Cordy Roy Pig", Position: "CH", Team: "Farm", Points: 241, CuddlePoints: 50, Tier: models.TierB, Drafted: false, Image: "/images/cordy-roy-pig.png"},
-		{ID: "17", Name: "Bashful Tiger", Position: "SS", Team: "Safari", Points: 235, CuddlePoints: 50, Tier: models.TierB, Drafted: false, Image: "/images/bashful-tiger.png"},
-		{ID: "18", Name: "Amuseable Donut", Position: "CC", Team: "Kitchen", Points: 228, CuddlePoints: 50, Tier: models.TierB, Drafted: false, Image: "/images/amuseable-donut.png"},
-	}
+	cuddle := DefaultCuddlePoints()
+	return stampPlayers([]models.Player{
+		{ID: "1", Name: "Bashful Bunny", Position: "CC", Team: "Woodland", Points: 324, CuddlePoints: cuddle, Tier: models.TierS, Drafted: false, Image: "/images/bashful-bunny.png"},
+		{ID: "2", Name: "Fuddlewuddle Lion", Position: "SS", Team: "Safari", Points: 298, CuddlePoints: cuddle, Tier: models.TierS, Drafted: false, Image: "/images/fuddlewuddle-lion.png"},
+		{ID: "3", Name: "Cordy Roy Elephant", Position: "HH", Team: "Safari", Points: 287, CuddlePoints: cuddle, Tier: models.TierS, Drafted: false, Image: "/images/cordy-roy-elephant.png"},
+		{ID: "4", Name: "Blossom Tulip Bunny", Position: "CH", Team: "Garden", Points: 251, CuddlePoints: cuddle, Tier: models.TierA, Drafted: false, Image: "/images/blossom-tulip-bunny.png"},
+		{ID: "5", Name: "Amuseable Avocado", Position: "CC", Team: "Kitchen", Points: 312, CuddlePoints: cuddle, Tier: models.TierS, Drafted: false, Image: "/images/amuseable-avocado.png"},
+		{ID: "6", Name: "Octopus Ollie", Position: "SS", Team: "Ocean", Points: 276, CuddlePoints: cuddle, Tier: models.TierA, Drafted: false, Image: "/images/octopus-ollie.png"},
+		{ID: "7", Name: "Jellycat Dragon", Position: "HH", Team: "Fantasy", Points: 268, CuddlePoints: cuddle, Tier: models.TierA, Drafted: false, Image: "/images/jellycat-dragon.png"},
+		{ID: "8", Name: "Bashful Lamb", Position: "CH", Team: "Farm", Points: 245, CuddlePoints: cuddle, Tier: models.TierA, Drafted: false, Image: "/images/bashful-lamb.png"},
+		{ID: "9", Name: "Amuseable Pineapple", Position: "CC", Team: "Tropical", Points: 289, CuddlePoints: cuddle, Tier: models.TierS, Drafted: false, Image: "/images/amuseable-pineapple.png"},
+		{ID: "10", Name: "Cordy Roy Fox", Position: "SS", Team: "Woodland", Points: 234, CuddlePoints: cuddle, Tier: models.TierA, Drafted: false, Image: "/images/cordy-roy-fox.png"},
+		{ID: "11", Name: "Blossom Peach Bunny", Position: "HH", Team: "Garden", Points: 256, CuddlePoints: cuddle, Tier: models.TierA, Drafted: false, Image: "/images/blossom-peach-bunny.png"},
+		{ID: "12", Name: "Amuseable Taco", Position: "CH", Team: "Kitchen", Points: 267, CuddlePoints: cuddle, Tier: models.TierA, Drafted: false, Image: "/images/amuseable-taco.png"},
+		{ID: "13", Name: "Bashful Unicorn", Position: "CC", Team: "Fantasy", Points: 278, CuddlePoints: cuddle, Tier: models.TierA, Drafted: false, Image: "/images/bashful-unicorn.png"},
+		{ID: "14", Name: "Jellycat Penguin", Position: "SS", Team: "Arctic", Points: 243, CuddlePoints: cuddle, Tier: models.TierB, Drafted: false, Image: "/images/jellycat-penguin.png"},
+		{ID: "15", Name: "Amuseable Moon", Position: "HH", Team: "Space", Points: 229, CuddlePoints: cuddle, Tier: models.TierB, Drafted: false, Image: "/images/amuseable-moon.png"},
+		{ID: "16", Name: "Cordy Roy Pig", Position: "CH", Team: "Farm", Points: 241, CuddlePoints: cuddle, Tier: models.TierB, Drafted: false, Image: "/images/cordy-roy-pig.png"},
+		{ID: "17", Name: "Bashful Tiger", Position: "SS", Team: "Safari", Points: 235, CuddlePoints: cuddle, Tier: models.TierB, Drafted: false, Image: "/images/bashful-tiger.png"},
+		{ID: "18", Name: "Amuseable Donut", Position: "CC", Team: "Kitchen", Points: 228, CuddlePoints: cuddle, Tier: models.TierB, Drafted: false, Image: "/images/amuseable-donut.png"},
+	})
 }
 
 func getDefaultTeams() []models.Team {
-	return []models.Team{
+	return stampTeams([]models.Team{
 		{ID: "1", Name: "Fluffy Foxes", Owner: "Sarah", Mascot: "🦊", Color: "bg-orange-100 border-orange-300", Players: []models.Player{}},
 		{ID: "2", Name: "Cuddly Bears", Owner: "Mike", Mascot: "🐻", Color: "bg-amber-100 border-amber-300", Players: []models.Player{}},
 		{ID: "3", Name: "Snuggly Bunnies", Owner: "Emma", Mascot: "🐰", Color: "bg-pink-100 border-pink-300", Players: []models.Player{}},
 		{ID: "4", Name: "Cozy Cats", Owner: "Alex", Mascot: "🐱", Color: "bg-purple-100 border-purple-300", Players: []models.Player{}},
 		{ID: "5", Name: "Soft Sheep", Owner: "Jordan", Mascot: "🐑", Color: "bg-blue-100 border-blue-300", Players: []models.Player{}},
 		{ID: "6", Name: "Gentle Giraffes", Owner: "Taylor", Mascot: "🦒", Color: "bg-yellow-100 border-yellow-300", Players: []models.Player{}},
-	}
+	})
 }
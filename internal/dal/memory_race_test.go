@@ -0,0 +1,181 @@
+package dal
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// TestMemoryGetStateDuringPicks reads the state while picks and reactions
+// land. Run it with -race: GetState must hand back rosters and reaction maps
+// the store no longer touches.
+func TestMemoryGetStateDuringPicks(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("JELLYCAT_SEED_DEFAULT_CATALOG", "true")
+	store := NewMemoryDAL()
+	for _, name := range []string{"Foxes", "Bears", "Bunnies"} {
+		if _, err := store.AddTeam(name, "", "", ""); err != nil {
+			t.Fatalf("AddTeam() failed: %v", err)
+		}
+	}
+	msg, err := store.AddChatMessage("Draft day!", "user")
+	if err != nil {
+		t.Fatalf("AddChatMessage() failed: %v", err)
+	}
+
+	// Both sides yield after each call, so they interleave even on one CPU
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 30 {
+				state, err := store.GetState()
+				if err != nil {
+					t.Errorf("GetState() failed: %v", err)
+					return
+				}
+				// Read everything a handler would, as it encodes the state
+				if _, err := json.Marshal(state); err != nil {
+					t.Errorf("marshal state: %v", err)
+					return
+				}
+				runtime.Gosched()
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range 9 {
+			state, err := store.GetState()
+			if err != nil {
+				t.Errorf("GetState() failed: %v", err)
+				return
+			}
+			var playerID string
+			for _, player := range state.Players {
+				if !player.Drafted {
+					playerID = player.ID
+					break
+				}
+			}
+			if err := store.DraftPlayer(playerID, state.CurrentTeamID); err != nil {
+				t.Errorf("DraftPlayer() failed: %v", err)
+				return
+			}
+			if _, err := store.AddReaction(msg.ID, "👍", fmt.Sprintf("user-%d", i)); err != nil {
+				t.Errorf("AddReaction() failed: %v", err)
+				return
+			}
+			runtime.Gosched()
+		}
+	}()
+	wg.Wait()
+
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	for _, team := range state.Teams {
+		if len(team.Players) != 3 {
+			t.Fatalf("%s has %d players, want 3", team.Name, len(team.Players))
+		}
+	}
+}
+
+// TestMemoryWriteResultsDuringRosterUpdates encodes what the team and player
+// writes return while points change on the rosters. Run it with -race: the
+// returned teams and players must not share the store's rosters.
+func TestMemoryWriteResultsDuringRosterUpdates(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("JELLYCAT_SEED_DEFAULT_CATALOG", "true")
+	store := NewMemoryDAL()
+	for _, name := range []string{"Foxes", "Bears"} {
+		if _, err := store.AddTeam(name, "", "", ""); err != nil {
+			t.Fatalf("AddTeam() failed: %v", err)
+		}
+	}
+	for range 4 {
+		state, err := store.GetState()
+		if err != nil {
+			t.Fatalf("GetState() failed: %v", err)
+		}
+		var playerID string
+		for _, player := range state.Players {
+			if !player.Drafted {
+				playerID = player.ID
+				break
+			}
+		}
+		if err := store.DraftPlayer(playerID, state.CurrentTeamID); err != nil {
+			t.Fatalf("DraftPlayer() failed: %v", err)
+		}
+	}
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	team := state.Teams[0]
+	drafted := team.Players[0]
+
+	encode := func(name string, value any, err error) bool {
+		if err != nil {
+			t.Errorf("%s() failed: %v", name, err)
+			return false
+		}
+		if _, err := json.Marshal(value); err != nil {
+			t.Errorf("marshal %s() result: %v", name, err)
+			return false
+		}
+		runtime.Gosched()
+		return true
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for range 30 {
+			updated, err := store.UpdateTeam(team.ID, team.Name, "Fox Owner", "", "")
+			if !encode("UpdateTeam", updated, err) {
+				return
+			}
+			claimed, err := store.ClaimTeam(team.ID, "user-1", "Fox Owner")
+			if !encode("ClaimTeam", claimed, err) {
+				return
+			}
+			assigned, err := store.AssignTeamOwner(team.ID, "user-1", "Fox Owner")
+			if !encode("AssignTeamOwner", assigned, err) {
+				return
+			}
+			reordered, err := store.ReorderTeams([]string{state.Teams[1].ID, state.Teams[0].ID})
+			if !encode("ReorderTeams", reordered, err) {
+				return
+			}
+			randomized, err := store.RandomizeTeamOrder()
+			if !encode("RandomizeTeamOrder", randomized, err) {
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := range 30 {
+			player, err := store.SetPlayerPoints(drafted.ID, 100+i)
+			if !encode("SetPlayerPoints", player, err) {
+				return
+			}
+			update := drafted
+			update.CuddlePoints = i
+			player, err = store.UpdatePlayer(&update)
+			if !encode("UpdatePlayer", player, err) {
+				return
+			}
+		}
+	}()
+	wg.Wait()
+}
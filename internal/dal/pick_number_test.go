@@ -0,0 +1,87 @@
+package dal
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// assertConcurrentPickNumbers drafts players from several goroutines at once
+// and checks every pick got its own number.
+func assertConcurrentPickNumbers(t *testing.T, store DraftDAL, db *sql.DB) {
+	t.Helper()
+
+	const teams = 4
+	// Add everything before racing, so only the picks contend for the store
+	racers := map[string]string{}
+	for i := 0; i < teams; i++ {
+		team, err := store.AddTeam("Racer", "", "", "")
+		if err != nil {
+			t.Fatalf("AddTeam() failed: %v", err)
+		}
+		player, err := store.AddPlayer(&models.Player{Name: "Racing Bun", Position: "CC", Team: "Test", Points: 100, CuddlePoints: 50, Tier: models.TierB})
+		if err != nil {
+			t.Fatalf("AddPlayer() failed: %v", err)
+		}
+		racers[player.ID] = team.ID
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, teams)
+	for playerID, teamID := range racers {
+		wg.Add(1)
+		go func(playerID, teamID string) {
+			defer wg.Done()
+			// Only the team on the clock may pick, so keep trying until it is this team's turn.
+			for {
+				err := store.DraftPlayer(playerID, teamID)
+				if !errors.Is(err, ErrValidation) {
+					errs <- err
+					return
+				}
+			}
+		}(playerID, teamID)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("DraftPlayer() failed: %v", err)
+		}
+	}
+
+	rows, err := db.Query(`SELECT draft_pick_number FROM team_players ORDER BY draft_pick_number`)
+	if err != nil {
+		t.Fatalf("query pick numbers failed: %v", err)
+	}
+	defer rows.Close()
+	var picks []int
+	for rows.Next() {
+		var pick int
+		if err := rows.Scan(&pick); err != nil {
+			t.Fatalf("scan pick number failed: %v", err)
+		}
+		picks = append(picks, pick)
+	}
+	for i, pick := range picks {
+		if pick != i+1 {
+			t.Fatalf("pick numbers = %v, want 1..%d with no duplicates", picks, teams)
+		}
+	}
+	if len(picks) != teams {
+		t.Fatalf("got %d picks, want %d", len(picks), teams)
+	}
+}
+
+func TestSQLiteConcurrentPickNumbers(t *testing.T) {
+	store := newTestSQLiteDAL(t)
+	assertConcurrentPickNumbers(t, store, store.db)
+}
+
+func TestPostgresConcurrentPickNumbers(t *testing.T) {
+	store := newTestPostgresDAL(t)
+	assertConcurrentPickNumbers(t, store, store.db)
+}
@@ -4,19 +4,38 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	_ "github.com/lib/pq"
 
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/draft"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/ids"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
 	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
 )
 
 // PostgresDAL implements DraftDAL using PostgreSQL
 type PostgresDAL struct {
-	db            *sql.DB
-	reactionUsers map[string]map[string]map[string]bool
+	db *sql.DB
+}
+
+// checkServerEncoding rejects a database that can't store emoji. lib/pq
+// always talks UTF-8, but a LATIN1 (or similar) database would fail every
+// insert of a mascot, an emoji team name or a reaction. SQL_ASCII stores the
+// bytes as sent, so it's fine.
+func checkServerEncoding(db *sql.DB) error {
+	var encoding string
+	if err := db.QueryRow(`SHOW server_encoding`).Scan(&encoding); err != nil {
+		return fmt.Errorf("failed to read server encoding: %w", err)
+	}
+	switch strings.ToUpper(encoding) {
+	case "UTF8", "SQL_ASCII":
+		return nil
+	}
+	return fmt.Errorf("postgres database encoding is %s; create it with ENCODING 'UTF8' to store emoji", encoding)
 }
 
 // NewPostgresDAL creates a new PostgreSQL data access layer optimized for CloudNativePG
@@ -60,9 +79,12 @@ func NewPostgresDAL(connString string) (*PostgresDAL, error) {
 		return nil, fmt.Errorf("failed to ping postgres after %d retries: %w", maxRetries, lastErr)
 	}
 
+	if err := checkServerEncoding(db); err != nil {
+		return nil, err
+	}
+
 	dal := &PostgresDAL{
-		db:            db,
-		reactionUsers: make(map[string]map[string]map[string]bool),
+		db: db,
 	}
 
 	if err := dal.initSchema(); err != nil {
@@ -94,10 +116,12 @@ func (p *PostgresDAL) initSchema() error {
 		id TEXT PRIMARY KEY,
 		name TEXT NOT NULL,
 		owner TEXT NOT NULL,
+		owner_user_id TEXT NOT NULL DEFAULT '',
 		mascot TEXT NOT NULL,
 		color TEXT NOT NULL,
 		display_order INTEGER,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
 
 	CREATE TABLE IF NOT EXISTS team_players (
@@ -105,6 +129,8 @@ func (p *PostgresDAL) initSchema() error {
 		player_id TEXT NOT NULL REFERENCES players(id) ON DELETE CASCADE,
 		player_data JSONB NOT NULL,
 		draft_pick_number INTEGER,
+		pre_draft_points INTEGER,
+		pre_draft_cuddle_points INTEGER,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 		PRIMARY KEY (team_id, player_id)
 	);
@@ -114,16 +140,42 @@ func (p *PostgresDAL) initSchema() error {
 		ts BIGINT NOT NULL,
 		type TEXT NOT NULL,
 		text TEXT NOT NULL,
+		sender TEXT NOT NULL DEFAULT '',
 		emotes JSONB NOT NULL DEFAULT '{}'::jsonb,
+		deleted_at BIGINT,
+		updated_at BIGINT NOT NULL DEFAULT 0,
+		message JSONB,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
 
+	CREATE TABLE IF NOT EXISTS chat_reactions (
+		message_id TEXT NOT NULL REFERENCES chat(id) ON DELETE CASCADE,
+		emote TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		created_at BIGINT NOT NULL,
+		PRIMARY KEY (message_id, emote, user_id)
+	);
+
 	CREATE TABLE IF NOT EXISTS draft_settings (
 		key TEXT PRIMARY KEY,
 		value TEXT NOT NULL,
 		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
 
+	-- A single row whose lock serializes picks and undos; last_pick is the last pick number handed out.
+	CREATE TABLE IF NOT EXISTS draft_pick_counter (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		last_pick INTEGER NOT NULL DEFAULT 0
+	);
+	INSERT INTO draft_pick_counter (id, last_pick) VALUES (1, 0) ON CONFLICT (id) DO NOTHING;
+
+	-- A single row counting changes to the draft; Reset leaves it alone.
+	CREATE TABLE IF NOT EXISTS state_version (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		version BIGINT NOT NULL DEFAULT 0
+	);
+	INSERT INTO state_version (id, version) VALUES (1, 0) ON CONFLICT (id) DO NOTHING;
+
 	CREATE TABLE IF NOT EXISTS images (
 		path TEXT PRIMARY KEY,
 		filename TEXT NOT NULL,
@@ -133,13 +185,50 @@ func (p *PostgresDAL) initSchema() error {
 		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
 
+	CREATE TABLE IF NOT EXISTS access_tokens (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		owner_id TEXT NOT NULL,
+		owner_username TEXT NOT NULL,
+		owner_name TEXT NOT NULL,
+		owner_email TEXT NOT NULL,
+		owner_groups JSONB NOT NULL DEFAULT '[]'::jsonb,
+		scopes JSONB NOT NULL DEFAULT '[]'::jsonb,
+		token_hash TEXT NOT NULL UNIQUE,
+		created_at BIGINT NOT NULL,
+		last_used_at BIGINT NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS sessions (
+		id TEXT PRIMARY KEY,
+		data BYTEA NOT NULL,
+		expires_at BIGINT NOT NULL,
+		idp_subject TEXT NOT NULL DEFAULT '',
+		idp_session_id TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE TABLE IF NOT EXISTS auth_events (
+		id TEXT PRIMARY KEY,
+		type TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		username TEXT NOT NULL,
+		ip TEXT NOT NULL,
+		user_agent TEXT NOT NULL,
+		detail TEXT NOT NULL,
+		impersonator_id TEXT NOT NULL DEFAULT '',
+		created_at BIGINT NOT NULL
+	);
+
 	-- CloudNativePG optimization: Add indexes for common query patterns
 	CREATE INDEX IF NOT EXISTS idx_players_drafted ON players(drafted);
 	CREATE INDEX IF NOT EXISTS idx_players_points ON players(points DESC);
-	CREATE INDEX IF NOT EXISTS idx_chat_ts ON chat(ts);
+	CREATE INDEX IF NOT EXISTS idx_chat_ts_id ON chat(ts, id);
 	CREATE INDEX IF NOT EXISTS idx_team_players_team_id ON team_players(team_id);
 	CREATE INDEX IF NOT EXISTS idx_teams_created_at ON teams(created_at);
 	CREATE INDEX IF NOT EXISTS idx_images_filename ON images(filename);
+	CREATE INDEX IF NOT EXISTS idx_access_tokens_owner_id ON access_tokens(owner_id);
+	CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at);
+	CREATE INDEX IF NOT EXISTS idx_auth_events_created_at ON auth_events(created_at);
 	`
 
 	if _, err := p.db.Exec(schema); err != nil {
@@ -164,6 +253,16 @@ func (p *PostgresDAL) initSchema() error {
 		return fmt.Errorf("failed to add draft_pick_number column: %w", err)
 	}
 
+	// Pre-draft points let a pick be undone; older picks keep their drafted values.
+	_, err = p.db.Exec(`
+		ALTER TABLE team_players
+		ADD COLUMN IF NOT EXISTS pre_draft_points INTEGER,
+		ADD COLUMN IF NOT EXISTS pre_draft_cuddle_points INTEGER
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add pre-draft points columns: %w", err)
+	}
+
 	_, err = p.db.Exec(`
 		ALTER TABLE teams
 		ADD COLUMN IF NOT EXISTS display_order INTEGER
@@ -172,6 +271,86 @@ func (p *PostgresDAL) initSchema() error {
 		return fmt.Errorf("failed to add teams display_order column: %w", err)
 	}
 
+	_, err = p.db.Exec(`
+		ALTER TABLE teams
+		ADD COLUMN IF NOT EXISTS owner_user_id TEXT NOT NULL DEFAULT ''
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add teams owner_user_id column: %w", err)
+	}
+
+	_, err = p.db.Exec(`
+		ALTER TABLE auth_events
+		ADD COLUMN IF NOT EXISTS impersonator_id TEXT NOT NULL DEFAULT ''
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add auth_events impersonator_id column: %w", err)
+	}
+
+	// Cleared chat stays restorable until deleted_at is older than the grace period
+	_, err = p.db.Exec(`
+		ALTER TABLE chat
+		ADD COLUMN IF NOT EXISTS deleted_at BIGINT
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add chat deleted_at column: %w", err)
+	}
+
+	// Teams predate updated_at; existing rows get the time of the migration
+	_, err = p.db.Exec(`
+		ALTER TABLE teams
+		ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add teams updated_at column: %w", err)
+	}
+
+	// Unix milliseconds like ts; 0 until a reaction changes the message
+	_, err = p.db.Exec(`
+		ALTER TABLE chat
+		ADD COLUMN IF NOT EXISTS updated_at BIGINT NOT NULL DEFAULT 0
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add chat updated_at column: %w", err)
+	}
+
+	// Back-channel logout finds sessions by the identity provider's sub and sid
+	_, err = p.db.Exec(`
+		ALTER TABLE sessions
+		ADD COLUMN IF NOT EXISTS idp_subject TEXT NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS idp_session_id TEXT NOT NULL DEFAULT ''
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add sessions idp columns: %w", err)
+	}
+	if _, err = p.db.Exec(`CREATE INDEX IF NOT EXISTS idx_sessions_idp ON sessions(idp_subject, idp_session_id)`); err != nil {
+		return fmt.Errorf("failed to create sessions idp index: %w", err)
+	}
+
+	// System messages carry the bot's name; older ones get the current one
+	_, err = p.db.Exec(`ALTER TABLE chat ADD COLUMN IF NOT EXISTS sender TEXT NOT NULL DEFAULT ''`)
+	if err != nil {
+		return fmt.Errorf("failed to add chat sender column: %w", err)
+	}
+	if _, err = p.db.Exec(`UPDATE chat SET sender = $1 WHERE type = 'system' AND sender = ''`, SystemSender()); err != nil {
+		return fmt.Errorf("failed to backfill chat sender: %w", err)
+	}
+
+	// System messages are stored as a message key and params; older rows keep raw text
+	_, err = p.db.Exec(`ALTER TABLE chat ADD COLUMN IF NOT EXISTS message JSONB`)
+	if err != nil {
+		return fmt.Errorf("failed to add chat message column: %w", err)
+	}
+
+	// Reactions were free text before the emote whitelist
+	err = cleanStoredEmotes(p.db, func(id string, emotes []byte) error {
+		_, err := p.db.Exec(`UPDATE chat SET emotes = $1 WHERE id = $2`, emotes, id)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clean chat reactions: %w", err)
+	}
+
 	// Seed default data if empty and demo catalog seeding is enabled.
 	var count int
 	if err := p.db.QueryRow("SELECT COUNT(*) FROM players").Scan(&count); err != nil {
@@ -179,16 +358,29 @@ func (p *PostgresDAL) initSchema() error {
 	}
 
 	if count == 0 && SeedDefaultCatalogEnabled() {
-		if err := p.seedData(); err != nil {
+		// CloudNativePG optimization: Use a transaction for batch inserts
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		tx, err := p.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		if err := p.seedData(ctx, tx); err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
 			return err
 		}
+		p.migrateSeedImages()
 	}
 
-	return p.ensureDefaultDraftSettings()
+	return p.ensureDefaultDraftSettings(p.db)
 }
 
-func (p *PostgresDAL) ensureDefaultDraftSettings() error {
-	_, err := p.db.Exec(`
+func (p *PostgresDAL) ensureDefaultDraftSettings(e sqlExecer) error {
+	_, err := e.Exec(`
 		INSERT INTO draft_settings (key, value)
 		VALUES ('mode', $1)
 		ON CONFLICT (key) DO NOTHING
@@ -201,7 +393,7 @@ func (p *PostgresDAL) getDraftSettings() (models.DraftSettings, error) {
 	var mode string
 	err := p.db.QueryRow(`SELECT value FROM draft_settings WHERE key = 'mode'`).Scan(&mode)
 	if err == sql.ErrNoRows {
-		return models.DefaultDraftSettings(), p.ensureDefaultDraftSettings()
+		return models.DefaultDraftSettings(), p.ensureDefaultDraftSettings(p.db)
 	}
 	if err != nil {
 		return models.DraftSettings{}, err
@@ -223,27 +415,19 @@ func (p *PostgresDAL) getDraftModeTx(ctx context.Context, tx *sql.Tx) (models.Dr
 	return models.NormalizeDraftMode(models.DraftMode(mode)), nil
 }
 
-func (p *PostgresDAL) seedData() error {
+// seedData writes the default catalog inside tx. Call migrateSeedImages
+// once tx has committed.
+func (p *PostgresDAL) seedData(ctx context.Context, tx *sql.Tx) error {
 	if !SeedDefaultCatalogEnabled() {
-		return p.ensureDefaultDraftSettings()
-	}
-
-	// CloudNativePG optimization: Use a transaction for batch inserts
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	tx, err := p.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
+		return p.ensureDefaultDraftSettings(tx)
 	}
-	defer tx.Rollback()
 
 	players := getDefaultPlayers()
 
 	// CloudNativePG optimization: Batch insert players to reduce round trips
 	playerStmt, err := tx.PrepareContext(ctx, `
-		INSERT INTO players (id, name, position, team, points, cuddle_points, tier, drafted, drafted_by, image)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO players (id, name, position, team, points, cuddle_points, tier, drafted, drafted_by, image, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`)
 	if err != nil {
 		return err
@@ -251,7 +435,7 @@ func (p *PostgresDAL) seedData() error {
 	defer playerStmt.Close()
 
 	for _, player := range players {
-		_, err := playerStmt.ExecContext(ctx, player.ID, player.Name, player.Position, player.Team, player.Points, player.CuddlePoints, player.Tier, player.Drafted, "", player.Image)
+		_, err := playerStmt.ExecContext(ctx, player.ID, player.Name, player.Position, player.Team, player.Points, player.CuddlePoints, player.Tier, player.Drafted, "", player.Image, player.CreatedAt, player.UpdatedAt)
 		if err != nil {
 			return err
 		}
@@ -263,8 +447,8 @@ func (p *PostgresDAL) seedData() error {
 
 		// CloudNativePG optimization: Batch insert teams
 		teamStmt, err := tx.PrepareContext(ctx, `
-			INSERT INTO teams (id, name, owner, mascot, color, display_order)
-			VALUES ($1, $2, $3, $4, $5, $6)
+			INSERT INTO teams (id, name, owner, mascot, color, display_order, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		`)
 		if err != nil {
 			return err
@@ -272,33 +456,33 @@ func (p *PostgresDAL) seedData() error {
 		defer teamStmt.Close()
 
 		for index, team := range teams {
-			_, err := teamStmt.ExecContext(ctx, team.ID, team.Name, team.Owner, team.Mascot, team.Color, index)
+			_, err := teamStmt.ExecContext(ctx, team.ID, team.Name, team.Owner, team.Mascot, team.Color, index, team.CreatedAt, team.UpdatedAt)
 			if err != nil {
 				return err
 			}
 		}
 	}
 
-	// Commit the transaction
-	if err := tx.Commit(); err != nil {
-		return err
+	for _, key := range welcomeChatKeys {
+		if err := insertChatTx(tx, newSystemChatMessage(key, nil), "$1, $2, $3, $4, $5, $6, $7"); err != nil {
+			return err
+		}
 	}
 
-	// Migrate images from static/images directory to database
+	return p.ensureDefaultDraftSettings(tx)
+}
+
+// migrateSeedImages copies static/images into the database after seeding.
+func (p *PostgresDAL) migrateSeedImages() {
 	if err := p.MigrateImagesToDatabase(); err != nil {
 		// Log warning but don't fail - images are optional
-		fmt.Printf("Warning: Failed to migrate images to database: %v\n", err)
-	}
-
-	p.AddChatMessage("Welcome to the Jellycat Draft!", "system")
-	p.AddChatMessage("Tip: pair your phone with the TV room code before picking.", "system")
-	p.AddChatMessage("Who will snag Bashful Bunny first?", "system")
-
-	if err := p.ensureDefaultDraftSettings(); err != nil {
-		return err
+		logger.Warn("Failed to migrate images to database", "error", err)
 	}
+}
 
-	return nil
+// Ping checks the connection to the server, giving up when ctx is done.
+func (p *PostgresDAL) Ping(ctx context.Context) error {
+	return p.db.PingContext(ctx)
 }
 
 func (p *PostgresDAL) GetState() (*models.DraftState, error) {
@@ -309,6 +493,13 @@ func (p *PostgresDAL) GetState() (*models.DraftState, error) {
 		Settings: models.DefaultDraftSettings(),
 	}
 
+	// Read the version first, so the board is at least as new as it says.
+	version, err := loadVersion(p.db)
+	if err != nil {
+		return nil, err
+	}
+	state.Version = version
+
 	settings, err := p.getDraftSettings()
 	if err != nil {
 		return nil, err
@@ -317,9 +508,9 @@ func (p *PostgresDAL) GetState() (*models.DraftState, error) {
 
 	// Get players
 	rows, err := p.db.Query(`
-		SELECT id, name, position, team, points, cuddle_points, tier, drafted, COALESCE(drafted_by, ''), image
+		SELECT id, name, position, team, points, cuddle_points, tier, drafted, COALESCE(drafted_by, ''), image, created_at, updated_at
 		FROM players
-		ORDER BY points DESC
+		ORDER BY created_at, id
 	`)
 	if err != nil {
 		return nil, err
@@ -328,10 +519,12 @@ func (p *PostgresDAL) GetState() (*models.DraftState, error) {
 
 	for rows.Next() {
 		var player models.Player
-		err := rows.Scan(&player.ID, &player.Name, &player.Position, &player.Team, &player.Points, &player.CuddlePoints, &player.Tier, &player.Drafted, &player.DraftedBy, &player.Image)
+		var createdAt, updatedAt sql.NullTime
+		err := rows.Scan(&player.ID, &player.Name, &player.Position, &player.Team, &player.Points, &player.CuddlePoints, &player.Tier, &player.Drafted, &player.DraftedBy, &player.Image, &createdAt, &updatedAt)
 		if err != nil {
 			return nil, err
 		}
+		player.CreatedAt, player.UpdatedAt = nullTime(createdAt), nullTime(updatedAt)
 		state.Players = append(state.Players, player)
 	}
 
@@ -339,7 +532,7 @@ func (p *PostgresDAL) GetState() (*models.DraftState, error) {
 	// This eliminates N+1 query problem and improves performance with read replicas
 	teamRows, err := p.db.Query(`
 		SELECT
-			t.id, t.name, t.owner, t.mascot, t.color,
+			t.id, t.name, t.owner, t.owner_user_id, t.mascot, t.color, t.created_at, t.updated_at,
 			tp.player_data
 		FROM teams t
 		LEFT JOIN team_players tp ON t.id = tp.team_id
@@ -355,10 +548,11 @@ func (p *PostgresDAL) GetState() (*models.DraftState, error) {
 	teamOrder := []string{} // Track order of teams
 
 	for teamRows.Next() {
-		var teamID, teamName, teamOwner, teamMascot, teamColor string
+		var teamID, teamName, teamOwner, teamOwnerUserID, teamMascot, teamColor string
+		var teamCreatedAt, teamUpdatedAt sql.NullTime
 		var playerJSON sql.NullString
 
-		err := teamRows.Scan(&teamID, &teamName, &teamOwner, &teamMascot, &teamColor, &playerJSON)
+		err := teamRows.Scan(&teamID, &teamName, &teamOwner, &teamOwnerUserID, &teamMascot, &teamColor, &teamCreatedAt, &teamUpdatedAt, &playerJSON)
 		if err != nil {
 			return nil, err
 		}
@@ -366,12 +560,15 @@ func (p *PostgresDAL) GetState() (*models.DraftState, error) {
 		// Create team if not exists
 		if _, exists := teamsMap[teamID]; !exists {
 			teamsMap[teamID] = &models.Team{
-				ID:      teamID,
-				Name:    teamName,
-				Owner:   teamOwner,
-				Mascot:  teamMascot,
-				Color:   teamColor,
-				Players: []models.Player{},
+				ID:          teamID,
+				Name:        teamName,
+				Owner:       teamOwner,
+				OwnerUserID: teamOwnerUserID,
+				Mascot:      teamMascot,
+				Color:       teamColor,
+				Players:     []models.Player{},
+				CreatedAt:   nullTime(teamCreatedAt),
+				UpdatedAt:   nullTime(teamUpdatedAt),
 			}
 			teamOrder = append(teamOrder, teamID)
 		}
@@ -392,47 +589,70 @@ func (p *PostgresDAL) GetState() (*models.DraftState, error) {
 	}
 
 	// Get chat
-	chatRows, err := p.db.Query(`SELECT id, ts, type, text, emotes FROM chat ORDER BY ts ASC`)
-	if err != nil {
-		return nil, err
-	}
-	defer chatRows.Close()
-
-	for chatRows.Next() {
-		var msg models.ChatMessage
-		var emotesJSON []byte
-		err := chatRows.Scan(&msg.ID, &msg.TS, &msg.Type, &msg.Text, &emotesJSON)
+	if ChatEnabled() {
+		chatRows, err := p.db.Query(`SELECT id, ts, type, text, sender, emotes, updated_at, message FROM chat WHERE deleted_at IS NULL ORDER BY ts ASC, id ASC`)
 		if err != nil {
 			return nil, err
 		}
-		msg.Emotes = make(map[string]int)
-		json.Unmarshal(emotesJSON, &msg.Emotes)
-		state.Chat = append(state.Chat, msg)
+		if state.Chat, err = scanChatRows(chatRows); err != nil {
+			return nil, err
+		}
 	}
 
 	// Calculate current pick number and whose turn it is
 	CalculateCurrentPick(state, state.Players)
+	if draft.AuctionEnabled() {
+		auction, err := loadAuction(p.db)
+		if err != nil {
+			return nil, err
+		}
+		auction.Apply(state)
+	}
 
 	return state, nil
 }
 
 func (p *PostgresDAL) Reset() error {
-	// Clear all tables
-	_, err := p.db.Exec("TRUNCATE team_players, chat, draft_settings, teams, players CASCADE")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := p.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	p.reactionUsers = make(map[string]map[string]map[string]bool)
+	// Clear all tables
+	if _, err := tx.ExecContext(ctx, "TRUNCATE team_players, chat, draft_settings, teams, players CASCADE"); err != nil {
+		return err
+	}
 
 	// Re-seed
-	return p.seedData()
+	if err := p.seedData(ctx, tx); err != nil {
+		return err
+	}
+	if err := bumpVersion(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if SeedDefaultCatalogEnabled() {
+		p.migrateSeedImages()
+	}
+	return nil
 }
 
 func (p *PostgresDAL) SetDraftMode(mode models.DraftMode) (*models.DraftSettings, error) {
 	settings := models.DraftSettingsForMode(mode)
 
-	_, err := p.db.Exec(`
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
 		INSERT INTO draft_settings (key, value, updated_at)
 		VALUES ('mode', $1, CURRENT_TIMESTAMP)
 		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_at = CURRENT_TIMESTAMP
@@ -441,16 +661,63 @@ func (p *PostgresDAL) SetDraftMode(mode models.DraftMode) (*models.DraftSettings
 		return nil, err
 	}
 
-	if _, err := p.AddChatMessage(fmt.Sprintf("Draft mode set to %s", settings.Name), "system"); err != nil {
+	if err := insertChatTx(tx, draftModeSetChatMessage(settings), "$1, $2, $3, $4, $5, $6, $7"); err != nil {
+		return nil, err
+	}
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
 
 	return &settings, nil
 }
 
+func (p *PostgresDAL) GetDraftWindow() (models.DraftWindow, error) {
+	return loadDraftWindow(p.db)
+}
+
+func (p *PostgresDAL) SetDraftWindow(window models.DraftWindow) (*models.DraftWindow, error) {
+	window, err := normalizeDraftWindow(window)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for key, bound := range map[string]time.Time{draftWindowOpensKey: window.OpensAt, draftWindowClosesKey: window.ClosesAt} {
+		_, err := tx.Exec(`
+			INSERT INTO draft_settings (key, value, updated_at)
+			VALUES ($1, $2, CURRENT_TIMESTAMP)
+			ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_at = CURRENT_TIMESTAMP
+		`, key, encodeWindowBound(bound))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &window, nil
+}
+
 func (p *PostgresDAL) AddPlayer(player *models.Player) (*models.Player, error) {
+	name, err := sanitizeName("player", player.Name)
+	if err != nil {
+		return nil, err
+	}
+	player.Name = name
 	if player.ID == "" {
-		player.ID = genID("player")
+		player.ID = ids.New("player")
 	}
 
 	// Assign random cuddle points if not already set
@@ -458,15 +725,38 @@ func (p *PostgresDAL) AddPlayer(player *models.Player) (*models.Player, error) {
 		player.CuddlePoints = randomCuddlePoints()
 	}
 
-	_, err := p.db.Exec(`
-		INSERT INTO players (id, name, position, team, points, cuddle_points, tier, drafted, drafted_by, image)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-	`, player.ID, player.Name, player.Position, player.Team, player.Points, player.CuddlePoints, player.Tier, player.Drafted, player.DraftedBy, player.Image)
+	player.CreatedAt = timestampNow()
+	player.UpdatedAt = player.CreatedAt
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return player, err
+	}
+	defer tx.Rollback()
 
-	return player, err
+	_, err = tx.Exec(`
+		INSERT INTO players (id, name, position, team, points, cuddle_points, tier, drafted, drafted_by, image, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`, player.ID, player.Name, player.Position, player.Team, player.Points, player.CuddlePoints, player.Tier, player.Drafted, player.DraftedBy, player.Image, player.CreatedAt, player.UpdatedAt)
+	if err != nil {
+		return player, err
+	}
+	if err := bumpVersion(tx); err != nil {
+		return player, err
+	}
+	if err := tx.Commit(); err != nil {
+		return player, err
+	}
+
+	return player, nil
 }
 
 func (p *PostgresDAL) UpdatePlayer(player *models.Player) (*models.Player, error) {
+	name, err := sanitizeName("player", player.Name)
+	if err != nil {
+		return nil, err
+	}
+	player.Name = name
 	// Cap cuddle points at 100
 	if player.CuddlePoints > 100 {
 		player.CuddlePoints = 100
@@ -475,13 +765,19 @@ func (p *PostgresDAL) UpdatePlayer(player *models.Player) (*models.Player, error
 		player.CuddlePoints = 0
 	}
 
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
 	// Check if player exists and get current data
 	var drafted bool
 	var currentPoints int
-	err := p.db.QueryRow(`SELECT drafted, points FROM players WHERE id = $1`, player.ID).Scan(&drafted, &currentPoints)
+	err = tx.QueryRow(`SELECT drafted, points FROM players WHERE id = $1`, player.ID).Scan(&drafted, &currentPoints)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("player not found")
+			return nil, ErrPlayerNotFound
 		}
 		return nil, err
 	}
@@ -492,76 +788,123 @@ func (p *PostgresDAL) UpdatePlayer(player *models.Player) (*models.Player, error
 		pointsToUpdate = currentPoints
 	}
 
-	_, err = p.db.Exec(`
+	_, err = tx.Exec(`
 		UPDATE players 
-		SET name = $1, position = $2, team = $3, points = $4, cuddle_points = $5, tier = $6, image = $7, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $8
-	`, player.Name, player.Position, player.Team, pointsToUpdate, player.CuddlePoints, player.Tier, player.Image, player.ID)
+		SET name = $1, position = $2, team = $3, points = $4, cuddle_points = $5, tier = $6, image = $7, updated_at = $8
+		WHERE id = $9
+	`, player.Name, player.Position, player.Team, pointsToUpdate, player.CuddlePoints, player.Tier, player.Image, timestampNow(), player.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get updated player
+	var updatedPlayer models.Player
+	var createdAt, updatedAt sql.NullTime
+	err = tx.QueryRow(`
+		SELECT id, name, position, team, points, cuddle_points, tier, drafted, COALESCE(drafted_by, ''), image, created_at, updated_at
+		FROM players WHERE id = $1
+	`, player.ID).Scan(&updatedPlayer.ID, &updatedPlayer.Name, &updatedPlayer.Position, &updatedPlayer.Team, &updatedPlayer.Points, &updatedPlayer.CuddlePoints, &updatedPlayer.Tier, &updatedPlayer.Drafted, &updatedPlayer.DraftedBy, &updatedPlayer.Image, &createdAt, &updatedAt)
 	if err != nil {
 		return nil, err
 	}
+	updatedPlayer.CreatedAt, updatedPlayer.UpdatedAt = nullTime(createdAt), nullTime(updatedAt)
 
 	// Also update in team_players if player is drafted
 	if drafted {
 		player.Points = pointsToUpdate
+		player.CreatedAt, player.UpdatedAt = updatedPlayer.CreatedAt, updatedPlayer.UpdatedAt
 		playerJSON, _ := json.Marshal(player)
-		_, err = p.db.Exec(`
+		_, err = tx.Exec(`
 			UPDATE team_players 
 			SET player_data = $1
 			WHERE player_id = $2
 		`, playerJSON, player.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
 	}
 
-	// Get updated player
-	var updatedPlayer models.Player
-	err = p.db.QueryRow(`
-		SELECT id, name, position, team, points, cuddle_points, tier, drafted, COALESCE(drafted_by, ''), image
-		FROM players WHERE id = $1
-	`, player.ID).Scan(&updatedPlayer.ID, &updatedPlayer.Name, &updatedPlayer.Position, &updatedPlayer.Team, &updatedPlayer.Points, &updatedPlayer.CuddlePoints, &updatedPlayer.Tier, &updatedPlayer.Drafted, &updatedPlayer.DraftedBy, &updatedPlayer.Image)
-
-	return &updatedPlayer, err
+	return &updatedPlayer, nil
 }
 
 func (p *PostgresDAL) DeletePlayer(id string) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	// Check if player exists and is not drafted
 	var drafted bool
-	err := p.db.QueryRow(`SELECT drafted FROM players WHERE id = $1`, id).Scan(&drafted)
+	err = tx.QueryRow(`SELECT drafted FROM players WHERE id = $1`, id).Scan(&drafted)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return fmt.Errorf("player not found")
+			return ErrPlayerNotFound
 		}
 		return err
 	}
 
 	if drafted {
-		return fmt.Errorf("cannot delete a drafted player")
+		return ErrDraftedPlayerDelete
 	}
 
-	_, err = p.db.Exec(`DELETE FROM players WHERE id = $1`, id)
-	return err
+	if _, err := tx.Exec(`DELETE FROM players WHERE id = $1`, id); err != nil {
+		return err
+	}
+	if err := bumpVersion(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (p *PostgresDAL) SetPlayerPoints(id string, points int) (*models.Player, error) {
-	_, err := p.db.Exec(`UPDATE players SET points = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, points, id)
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := timestampNow()
+	_, err = tx.Exec(`UPDATE players SET points = $1, updated_at = $2 WHERE id = $3`, points, now, id)
 	if err != nil {
 		return nil, err
 	}
 
 	// Also update in team_players
-	_, err = p.db.Exec(`
+	_, err = tx.Exec(`
 		UPDATE team_players
-		SET player_data = jsonb_set(player_data, '{points}', $1::text::jsonb)
-		WHERE (player_data->>'id') = $2
-	`, points, id)
+		SET player_data = jsonb_set(jsonb_set(player_data, '{points}', $1::text::jsonb), '{updatedAt}', to_jsonb($2::text))
+		WHERE (player_data->>'id') = $3
+	`, points, now.Format(time.RFC3339Nano), id)
+	if err != nil {
+		return nil, err
+	}
 
 	// Get updated player
 	var player models.Player
-	err = p.db.QueryRow(`
-		SELECT id, name, position, team, points, cuddle_points, tier, drafted, COALESCE(drafted_by, ''), image
+	var createdAt, updatedAt sql.NullTime
+	err = tx.QueryRow(`
+		SELECT id, name, position, team, points, cuddle_points, tier, drafted, COALESCE(drafted_by, ''), image, created_at, updated_at
 		FROM players WHERE id = $1
-	`, id).Scan(&player.ID, &player.Name, &player.Position, &player.Team, &player.Points, &player.CuddlePoints, &player.Tier, &player.Drafted, &player.DraftedBy, &player.Image)
+	`, id).Scan(&player.ID, &player.Name, &player.Position, &player.Team, &player.Points, &player.CuddlePoints, &player.Tier, &player.Drafted, &player.DraftedBy, &player.Image, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, noRows(err, ErrPlayerNotFound)
+	}
+	player.CreatedAt, player.UpdatedAt = nullTime(createdAt), nullTime(updatedAt)
 
-	return &player, err
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &player, nil
 }
 
 func (p *PostgresDAL) ReorderTeams(order []string) ([]models.Team, error) {
@@ -574,12 +917,24 @@ func (p *PostgresDAL) ReorderTeams(order []string) ([]models.Team, error) {
 	}
 	defer func() { _ = tx.Rollback() }()
 
+	// Lock the team rows so a concurrent add or delete can't slip past validation.
+	teamIDs, err := queryTeamIDs(tx.QueryContext(ctx, `SELECT id FROM teams FOR UPDATE`))
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTeamOrder(order, teamIDs); err != nil {
+		return nil, err
+	}
+
 	for index, id := range order {
 		if _, err := tx.ExecContext(ctx, `UPDATE teams SET display_order = $1 WHERE id = $2`, index, id); err != nil {
 			return nil, err
 		}
 	}
 
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
 	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
@@ -592,7 +947,36 @@ func (p *PostgresDAL) ReorderTeams(order []string) ([]models.Team, error) {
 	return state.Teams, nil
 }
 
+// lockPickCounter holds the pick counter row until tx ends, so concurrent
+// picks and undos queue up instead of numbering from the same snapshot.
+func lockPickCounter(ctx context.Context, tx *sql.Tx) error {
+	var lastPick int
+	return tx.QueryRowContext(ctx, `SELECT last_pick FROM draft_pick_counter WHERE id = 1 FOR UPDATE`).Scan(&lastPick)
+}
+
+// nextDraftPickNumber locks the pick counter and claims the pick after the
+// highest one taken. Read committed gives each statement after the lock a
+// fresh snapshot, so the previous holder's pick is always counted.
+func nextDraftPickNumber(ctx context.Context, tx *sql.Tx) (int, error) {
+	if err := lockPickCounter(ctx, tx); err != nil {
+		return 0, err
+	}
+
+	var next int
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(draft_pick_number), 0) + 1 FROM team_players`).Scan(&next); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE draft_pick_counter SET last_pick = $1 WHERE id = 1`, next); err != nil {
+		return 0, err
+	}
+	return next, nil
+}
+
 func (p *PostgresDAL) DraftPlayer(playerID, teamID string) error {
+	if draft.AuctionEnabled() {
+		return ErrAuctionPick
+	}
+
 	// CloudNativePG optimization: Use context with timeout for better failover handling
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -603,78 +987,98 @@ func (p *PostgresDAL) DraftPlayer(playerID, teamID string) error {
 	}
 	defer tx.Rollback()
 
-	// Get the current draft pick number (count of already drafted players + 1)
-	var draftPickNumber int
-	err = tx.QueryRow(`SELECT COUNT(*) + 1 FROM team_players`).Scan(&draftPickNumber)
+	if err := p.draftPlayerTx(ctx, tx, playerID, teamID, true); err != nil {
+		return err
+	}
+	if err := bumpVersion(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// draftPlayerTx adds playerID to teamID's roster. Auction awards skip the
+// turn check, since the high bidder wins whoever's turn it is.
+func (p *PostgresDAL) draftPlayerTx(ctx context.Context, tx *sql.Tx, playerID, teamID string, checkTurn bool) error {
+	// Taken first, so every read below sees the picks committed before ours
+	draftPickNumber, err := nextDraftPickNumber(ctx, tx)
 	if err != nil {
 		return err
 	}
 
+	window, err := loadDraftWindow(tx)
+	if err != nil {
+		return err
+	}
+	if err := checkDraftWindow(window, time.Now()); err != nil {
+		return err
+	}
+
 	// Get player including cuddle_points
 	var player models.Player
+	var createdAt sql.NullTime
 	err = tx.QueryRow(`
-		SELECT id, name, position, team, points, cuddle_points, tier, drafted, image
+		SELECT id, name, position, team, points, cuddle_points, tier, drafted, image, created_at
 		FROM players WHERE id = $1 FOR UPDATE
-	`, playerID).Scan(&player.ID, &player.Name, &player.Position, &player.Team, &player.Points, &player.CuddlePoints, &player.Tier, &player.Drafted, &player.Image)
+	`, playerID).Scan(&player.ID, &player.Name, &player.Position, &player.Team, &player.Points, &player.CuddlePoints, &player.Tier, &player.Drafted, &player.Image, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPlayerNotFound
+	}
 	if err != nil {
 		return err
 	}
 
 	if player.Drafted {
-		return fmt.Errorf("player already drafted")
+		return ErrPlayerAlreadyDrafted
 	}
 
 	// Get team
 	var teamName, teamMascot string
 	err = tx.QueryRow(`SELECT name, mascot FROM teams WHERE id = $1`, teamID).Scan(&teamName, &teamMascot)
-	if err != nil {
-		return err
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTeamNotFound
 	}
-
-	mode, err := p.getDraftModeTx(ctx, tx)
 	if err != nil {
 		return err
 	}
-	teams, err := postgresTeamsForTurn(ctx, tx)
-	if err != nil {
-		return err
+
+	if checkTurn {
+		mode, err := p.getDraftModeTx(ctx, tx)
+		if err != nil {
+			return err
+		}
+		teams, err := postgresTeamsForTurn(ctx, tx)
+		if err != nil {
+			return err
+		}
+		players, err := postgresPlayersForTurn(ctx, tx)
+		if err != nil {
+			return err
+		}
+		if err := validateTeamTurn(teams, mode, players, teamID); err != nil {
+			return err
+		}
 	}
-	players, err := postgresPlayersForTurn(ctx, tx)
-	if err != nil {
+	var rosterSize int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM team_players WHERE team_id = $1`, teamID).Scan(&rosterSize); err != nil {
 		return err
 	}
-	if err := validateTeamTurn(teams, mode, players, teamID); err != nil {
+	if err := checkRosterSize(teamName, rosterSize); err != nil {
 		return err
 	}
 
+	preDraftPoints, preDraftCuddlePoints := player.Points, player.CuddlePoints
 	player = personalizePlayerForTeam(player, models.Team{ID: teamID, Name: teamName})
 
-	// Calculate cuddle points adjustment based on draft position
-	// Early picks (1-6) gain points, late picks (13-18) lose points
-	cuddlePointsAdjustment := 0
-	if draftPickNumber <= 6 {
-		// Early picks gain 8-18 points (pick 1 gets +18, pick 6 gets +8)
-		cuddlePointsAdjustment = 20 - (draftPickNumber * 2)
-	} else if draftPickNumber >= 13 {
-		// Late picks lose 5-10 points (pick 13 loses -5, pick 18 loses -10)
-		cuddlePointsAdjustment = 8 - draftPickNumber
-	}
-
-	newCuddlePoints := player.CuddlePoints + cuddlePointsAdjustment
-	// Ensure cuddle points stay within reasonable bounds (min 10, max 100)
-	if newCuddlePoints < 10 {
-		newCuddlePoints = 10
-	}
-	if newCuddlePoints > 100 {
-		newCuddlePoints = 100
-	}
+	// Early picks gain cuddle points, late picks lose them
+	newCuddlePoints := draftedCuddlePoints(player.CuddlePoints, draftPickNumber)
 
 	// Update player as drafted with adjusted cuddle points
+	player.CreatedAt, player.UpdatedAt = nullTime(createdAt), timestampNow()
 	_, err = tx.Exec(`
 		UPDATE players
-		SET drafted = true, drafted_by = $1, points = $2, cuddle_points = $3, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $4
-	`, teamName, player.Points, newCuddlePoints, playerID)
+		SET drafted = true, drafted_by = $1, points = $2, cuddle_points = $3, updated_at = $4
+		WHERE id = $5
+	`, teamName, player.Points, newCuddlePoints, player.UpdatedAt, playerID)
 	if err != nil {
 		return err
 	}
@@ -690,30 +1094,28 @@ func (p *PostgresDAL) DraftPlayer(playerID, teamID string) error {
 
 	// Add player to team with draft pick number
 	_, err = tx.Exec(`
-		INSERT INTO team_players (team_id, player_id, player_data, draft_pick_number)
-		VALUES ($1, $2, $3, $4)
-	`, teamID, playerID, playerJSON, draftPickNumber)
+		INSERT INTO team_players (team_id, player_id, player_data, draft_pick_number, pre_draft_points, pre_draft_cuddle_points)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, teamID, playerID, playerJSON, draftPickNumber, preDraftPoints, preDraftCuddlePoints)
 	if err != nil {
 		return err
 	}
 
 	// Add chat message
-	msg := fmt.Sprintf("%s %s drafted %s (%s • %s)", teamMascot, teamName, player.Name, player.Team, player.Position)
-	emotesJSON, _ := json.Marshal(map[string]int{})
-	_, err = tx.ExecContext(ctx, `
-		INSERT INTO chat (id, ts, type, text, emotes)
-		VALUES ($1, $2, $3, $4, $5)
-	`, genID("msg"), time.Now().UnixMilli(), "system", msg, emotesJSON)
-	if err != nil {
-		return err
+	if !ChatEnabled() {
+		return nil
 	}
-
-	return tx.Commit()
+	msg := pickChatMessage(teamMascot, teamName, player)
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO chat (id, ts, type, text, sender, emotes, message)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, msg.ID, msg.TS, msg.Type, msg.Text, msg.Sender, []byte("{}"), chatMessageColumn(msg))
+	return err
 }
 
 func postgresTeamsForTurn(ctx context.Context, tx *sql.Tx) ([]models.Team, error) {
 	rows, err := tx.QueryContext(ctx, `
-		SELECT id, name, owner, mascot, color
+		SELECT id, name, owner, owner_user_id, mascot, color
 		FROM teams
 		ORDER BY COALESCE(display_order, 2147483647), created_at
 	`)
@@ -725,7 +1127,7 @@ func postgresTeamsForTurn(ctx context.Context, tx *sql.Tx) ([]models.Team, error
 	teams := []models.Team{}
 	for rows.Next() {
 		var team models.Team
-		if err := rows.Scan(&team.ID, &team.Name, &team.Owner, &team.Mascot, &team.Color); err != nil {
+		if err := rows.Scan(&team.ID, &team.Name, &team.Owner, &team.OwnerUserID, &team.Mascot, &team.Color); err != nil {
 			return nil, err
 		}
 		team.Players = []models.Player{}
@@ -758,128 +1160,190 @@ func postgresPlayersForTurn(ctx context.Context, tx *sql.Tx) ([]models.Player, e
 }
 
 func (p *PostgresDAL) AddChatMessage(text, msgType string) (*models.ChatMessage, error) {
-	msg := &models.ChatMessage{
-		ID:     genID("msg"),
-		TS:     time.Now().UnixMilli(),
-		Type:   msgType,
-		Text:   text,
-		Emotes: make(map[string]int),
+	if !ChatEnabled() {
+		return nil, ErrChatDisabled
+	}
+	msg, err := newChatMessage(text, msgType)
+	if err != nil {
+		return nil, err
 	}
 
-	emotesJSON, _ := json.Marshal(msg.Emotes)
-	_, err := p.db.Exec(`
-		INSERT INTO chat (id, ts, type, text, emotes)
-		VALUES ($1, $2, $3, $4, $5)
-	`, msg.ID, msg.TS, msg.Type, msg.Text, emotesJSON)
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 
-	return msg, err
+	if err := insertChatTx(tx, msg, "$1, $2, $3, $4, $5, $6, $7"); err != nil {
+		return nil, err
+	}
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return msg, nil
 }
 
 func (p *PostgresDAL) AddReaction(messageID, emote, userID string) (*models.ChatMessage, error) {
+	if !ChatEnabled() {
+		return nil, ErrChatDisabled
+	}
+	emote, err := validateEmote(emote)
+	if err != nil {
+		return nil, err
+	}
 	uid := userID
 	if uid == "" {
 		uid = "anon"
 	}
 
-	// Check if user already reacted
-	if p.reactionUsers[messageID] == nil {
-		p.reactionUsers[messageID] = make(map[string]map[string]bool)
-	}
-	if p.reactionUsers[messageID][emote] == nil {
-		p.reactionUsers[messageID][emote] = make(map[string]bool)
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, err
 	}
+	defer tx.Rollback()
 
-	if p.reactionUsers[messageID][emote][uid] {
-		// Already reacted, return current message
-		var msg models.ChatMessage
-		var emotesJSON []byte
-		err := p.db.QueryRow(`SELECT id, ts, type, text, emotes FROM chat WHERE id = $1`, messageID).Scan(&msg.ID, &msg.TS, &msg.Type, &msg.Text, &emotesJSON)
+	// Record who reacted; nothing is inserted for a missing message or a
+	// repeat by the same user
+	result, err := tx.Exec(`
+		INSERT INTO chat_reactions (message_id, emote, user_id, created_at)
+		SELECT id, $2::text, $3::text, $4::bigint FROM chat WHERE id = $1
+		ON CONFLICT DO NOTHING
+	`, messageID, emote, uid, timeMs(timestampNow()))
+	if err != nil {
+		return nil, err
+	}
+	affected, _ := result.RowsAffected()
+	if affected > 0 {
+		// Update emotes using jsonb operations
+		_, err = tx.Exec(`
+			UPDATE chat
+			SET emotes = jsonb_set(
+				COALESCE(emotes, '{}'::jsonb),
+				ARRAY[$2],
+				(COALESCE((emotes->>$2)::int, 0) + 1)::text::jsonb
+			),
+			updated_at = $3
+			WHERE id = $1
+		`, messageID, emote, timeMs(timestampNow()))
 		if err != nil {
 			return nil, err
 		}
-		json.Unmarshal(emotesJSON, &msg.Emotes)
-		return &msg, nil
-	}
-
-	p.reactionUsers[messageID][emote][uid] = true
-
-	// Update emotes using jsonb operations
-	_, err := p.db.Exec(`
-		UPDATE chat
-		SET emotes = jsonb_set(
-			COALESCE(emotes, '{}'::jsonb),
-			ARRAY[$2],
-			(COALESCE((emotes->>$2)::int, 0) + 1)::text::jsonb
-		)
-		WHERE id = $1
-	`, messageID, emote)
-	if err != nil {
-		return nil, err
 	}
 
-	// Return updated message
+	// Return the message as it now stands
 	var msg models.ChatMessage
-	var emotesJSON []byte
-	err = p.db.QueryRow(`SELECT id, ts, type, text, emotes FROM chat WHERE id = $1`, messageID).Scan(&msg.ID, &msg.TS, &msg.Type, &msg.Text, &emotesJSON)
+	var emotesJSON, message []byte
+	var updatedAt int64
+	err = tx.QueryRow(`SELECT id, ts, type, text, sender, emotes, updated_at, message FROM chat WHERE id = $1`, messageID).Scan(&msg.ID, &msg.TS, &msg.Type, &msg.Text, &msg.Sender, &emotesJSON, &updatedAt, &message)
 	if err != nil {
-		return nil, err
+		return nil, noRows(err, ErrMessageNotFound)
 	}
 	json.Unmarshal(emotesJSON, &msg.Emotes)
+	stampChatMessage(&msg, updatedAt)
+	applyChatMessageColumn(&msg, message)
 
+	if affected > 0 {
+		if err := bumpVersion(tx); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 	return &msg, nil
 }
 
+// checkUniqueOwnerLocked locks the teams table against other writers before
+// checkUniqueOwnerSQL, so that under READ COMMITTED a concurrent transaction
+// can't set the same owner between the check and tx's write. The lock is
+// only taken with UNIQUE_OWNERS on.
+func checkUniqueOwnerLocked(tx *sql.Tx, teamID, userID, owner string) error {
+	if !UniqueOwners() {
+		return nil
+	}
+	if _, err := tx.Exec(`LOCK TABLE teams IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return err
+	}
+	return checkUniqueOwnerSQL(tx, teamID, userID, owner)
+}
+
 func (p *PostgresDAL) AddTeam(name, owner, mascot, color string) (*models.Team, error) {
-	mascots := []string{"🦊", "🐻", "🐰", "🐱", "🐑", "🦒", "🐨", "🦁", "🐼", "🦄", "🐯", "🐶"}
-	colors := []string{
-		"bg-orange-100 border-orange-300",
-		"bg-amber-100 border-amber-300",
-		"bg-pink-100 border-pink-300",
-		"bg-purple-100 border-purple-300",
-		"bg-blue-100 border-blue-300",
-		"bg-yellow-100 border-yellow-300",
-		"bg-green-100 border-green-300",
+	name, err := sanitizeName("team", name)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := checkUniqueOwnerLocked(tx, "", "", owner); err != nil {
+		return nil, err
 	}
 
 	// Count existing teams for default mascot/color
 	var count int
-	p.db.QueryRow("SELECT COUNT(*) FROM teams").Scan(&count)
+	tx.QueryRow("SELECT COUNT(*) FROM teams").Scan(&count)
 
-	if mascot == "" {
-		mascot = mascots[count%len(mascots)]
-	}
-	if color == "" {
-		color = colors[count%len(colors)]
+	// New teams go after the current last team, matching MemoryDAL's append.
+	var nextOrder int
+	if err := tx.QueryRow("SELECT COALESCE(MAX(display_order) + 1, 0) FROM teams").Scan(&nextOrder); err != nil {
+		return nil, err
 	}
 
+	mascot, color = teamDefaults(count, mascot, color)
+
+	now := timestampNow()
 	team := &models.Team{
-		ID:      genID("team"),
-		Name:    name,
-		Owner:   owner,
-		Mascot:  mascot,
-		Color:   color,
-		Players: []models.Player{},
+		ID:        ids.New("team"),
+		Name:      name,
+		Owner:     owner,
+		Mascot:    mascot,
+		Color:     color,
+		Players:   []models.Player{},
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 
-	_, err := p.db.Exec(`
-		INSERT INTO teams (id, name, owner, mascot, color, display_order)
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`, team.ID, team.Name, team.Owner, team.Mascot, team.Color, count)
+	_, err = tx.Exec(`
+		INSERT INTO teams (id, name, owner, mascot, color, display_order, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, team.ID, team.Name, team.Owner, team.Mascot, team.Color, nextOrder, now, now)
 	if err != nil {
 		return nil, err
 	}
 
-	ownerLabel := team.Owner
-	if ownerLabel == "" {
-		ownerLabel = "Unassigned"
+	if err := insertChatTx(tx, teamJoinedChatMessage(team), "$1, $2, $3, $4, $5, $6, $7"); err != nil {
+		return nil, err
+	}
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
 	}
-	msg := fmt.Sprintf("New team joined the draft: %s %s (Owner: %s)", team.Mascot, team.Name, ownerLabel)
-	p.AddChatMessage(msg, "system")
 
 	return team, nil
 }
 
 func (p *PostgresDAL) UpdateTeam(id, name, owner, mascot, color string) (*models.Team, error) {
+	name, err := sanitizeName("team", name)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := checkUniqueOwnerLocked(tx, id, "", owner); err != nil {
+		return nil, err
+	}
 	// Build the UPDATE query dynamically based on non-empty fields
 	query := "UPDATE teams SET "
 	args := []interface{}{}
@@ -891,7 +1355,10 @@ func (p *PostgresDAL) UpdateTeam(id, name, owner, mascot, color string) (*models
 		args = append(args, name)
 		paramIdx++
 	}
-	updates = append(updates, fmt.Sprintf("owner = $%d", paramIdx))
+	// A changed owner string no longer identifies the claimed user.
+	updates = append(updates,
+		fmt.Sprintf("owner_user_id = CASE WHEN owner = $%d THEN owner_user_id ELSE '' END", paramIdx),
+		fmt.Sprintf("owner = $%d", paramIdx))
 	args = append(args, owner)
 	paramIdx++
 	if mascot != "" {
@@ -906,59 +1373,143 @@ func (p *PostgresDAL) UpdateTeam(id, name, owner, mascot, color string) (*models
 	}
 
 	if len(updates) == 0 {
-		return nil, fmt.Errorf("no fields to update")
+		return nil, validationErrorf("no fields to update")
 	}
+	updates = append(updates, fmt.Sprintf("updated_at = $%d", paramIdx))
+	args = append(args, timestampNow())
+	paramIdx++
 
 	query += strings.Join(updates, ", ")
 	query += fmt.Sprintf(" WHERE id = $%d", paramIdx)
 	args = append(args, id)
 
-	result, err := p.db.Exec(query, args...)
+	result, err := tx.Exec(query, args...)
 	if err != nil {
 		return nil, err
 	}
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return nil, fmt.Errorf("team not found")
+		return nil, ErrTeamNotFound
+	}
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
 	}
 
 	// Fetch and return the updated team
+	return p.getTeam(id)
+}
+
+func (p *PostgresDAL) getTeam(id string) (*models.Team, error) {
 	var team models.Team
-	err = p.db.QueryRow(`
-		SELECT id, name, owner, mascot, color
+	var createdAt, updatedAt sql.NullTime
+	err := p.db.QueryRow(`
+		SELECT id, name, owner, owner_user_id, mascot, color, created_at, updated_at
 		FROM teams WHERE id = $1
-	`, id).Scan(&team.ID, &team.Name, &team.Owner, &team.Mascot, &team.Color)
+	`, id).Scan(&team.ID, &team.Name, &team.Owner, &team.OwnerUserID, &team.Mascot, &team.Color, &createdAt, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTeamNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 	team.Players = []models.Player{}
+	team.CreatedAt, team.UpdatedAt = nullTime(createdAt), nullTime(updatedAt)
 
 	return &team, nil
 }
 
+func (p *PostgresDAL) ClaimTeam(teamID, userID, owner string) (*models.Team, error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := checkUniqueOwnerLocked(tx, teamID, userID, owner); err != nil {
+		return nil, err
+	}
+	result, err := tx.Exec(`
+		UPDATE teams SET owner_user_id = $1, owner = $2, updated_at = $4
+		WHERE id = $3 AND (owner_user_id = $1 OR (owner_user_id = '' AND (owner = '' OR owner = $2)))
+	`, userID, owner, teamID, timestampNow())
+	if err != nil {
+		return nil, err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		if _, err := p.getTeam(teamID); err != nil {
+			return nil, err
+		}
+		return nil, ErrTeamAlreadyClaimed
+	}
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return p.getTeam(teamID)
+}
+
+func (p *PostgresDAL) AssignTeamOwner(teamID, userID, owner string) (*models.Team, error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := checkUniqueOwnerLocked(tx, teamID, userID, owner); err != nil {
+		return nil, err
+	}
+	result, err := tx.Exec(`UPDATE teams SET owner_user_id = $1, owner = $2, updated_at = $4 WHERE id = $3`, userID, owner, teamID, timestampNow())
+	if err != nil {
+		return nil, err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, ErrTeamNotFound
+	}
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return p.getTeam(teamID)
+}
+
 func (p *PostgresDAL) DeleteTeam(id string) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	// Check if team has drafted players
 	var count int
-	err := p.db.QueryRow(`
+	err = tx.QueryRow(`
 		SELECT COUNT(*) FROM players WHERE drafted_by = $1
 	`, id).Scan(&count)
 	if err != nil {
 		return err
 	}
 	if count > 0 {
-		return fmt.Errorf("cannot delete a team that has drafted players")
+		return ErrTeamHasDraftedPlayers
 	}
 
-	result, err := p.db.Exec("DELETE FROM teams WHERE id = $1", id)
+	result, err := tx.Exec("DELETE FROM teams WHERE id = $1", id)
 	if err != nil {
 		return err
 	}
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("team not found")
+		return ErrTeamNotFound
 	}
-
-	return nil
+	if err := bumpVersion(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
@@ -0,0 +1,131 @@
+package dal
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"math/big"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// shuffleTeams puts teams in a uniformly random order (Fisher-Yates), drawing
+// from crypto/rand so no one can predict or steer the draft order.
+func shuffleTeams(teams []models.Team) error {
+	for i := len(teams) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return err
+		}
+		teams[i], teams[j.Int64()] = teams[j.Int64()], teams[i]
+	}
+	return nil
+}
+
+func (m *MemoryDAL) RandomizeTeamOrder() ([]models.Team, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	teams := append([]models.Team{}, m.teams...)
+	if err := shuffleTeams(teams); err != nil {
+		return nil, err
+	}
+
+	m.teams = teams
+	m.addSystemChatUnsafe(orderRandomizedChatMessage(teams))
+	m.version++
+	return cloneTeams(teams), nil
+}
+
+func (s *SQLiteDAL) RandomizeTeamOrder() ([]models.Team, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	teams, err := queryOrderTeams(tx.Query(`SELECT id, name, mascot FROM teams`))
+	if err != nil {
+		return nil, err
+	}
+	if err := shuffleTeams(teams); err != nil {
+		return nil, err
+	}
+	for index, team := range teams {
+		if _, err := tx.Exec(`UPDATE teams SET display_order = ? WHERE id = ?`, index, team.ID); err != nil {
+			return nil, err
+		}
+	}
+	if err := insertChatTx(tx, orderRandomizedChatMessage(teams), "?, ?, ?, ?, ?, ?, ?"); err != nil {
+		return nil, err
+	}
+
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	state, err := s.GetState()
+	if err != nil {
+		return nil, err
+	}
+	return state.Teams, nil
+}
+
+func (p *PostgresDAL) RandomizeTeamOrder() ([]models.Team, error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Lock the team rows, as ReorderTeams does, so an added team isn't left out
+	teams, err := queryOrderTeams(tx.Query(`SELECT id, name, mascot FROM teams FOR UPDATE`))
+	if err != nil {
+		return nil, err
+	}
+	if err := shuffleTeams(teams); err != nil {
+		return nil, err
+	}
+	for index, team := range teams {
+		if _, err := tx.Exec(`UPDATE teams SET display_order = $1 WHERE id = $2`, index, team.ID); err != nil {
+			return nil, err
+		}
+	}
+	if err := insertChatTx(tx, orderRandomizedChatMessage(teams), "$1, $2, $3, $4, $5, $6, $7"); err != nil {
+		return nil, err
+	}
+
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	state, err := p.GetState()
+	if err != nil {
+		return nil, err
+	}
+	return state.Teams, nil
+}
+
+// queryOrderTeams collects the id, name and mascot columns of a team query,
+// all a new order and its chat message need.
+func queryOrderTeams(rows *sql.Rows, err error) ([]models.Team, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var teams []models.Team
+	for rows.Next() {
+		var team models.Team
+		if err := rows.Scan(&team.ID, &team.Name, &team.Mascot); err != nil {
+			return nil, err
+		}
+		teams = append(teams, team)
+	}
+	return teams, rows.Err()
+}
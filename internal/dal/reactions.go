@@ -0,0 +1,92 @@
+package dal
+
+import (
+	"database/sql"
+	"sort"
+)
+
+func (m *MemoryDAL) GetReactionUsers(messageID string) (map[string][]string, error) {
+	if !ChatEnabled() {
+		return nil, ErrChatDisabled
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	found := false
+	for _, msg := range m.chat {
+		if msg.ID == messageID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, ErrMessageNotFound
+	}
+
+	users := make(map[string][]string)
+	for emote, byUser := range m.reactionUsers[messageID] {
+		for uid, reacted := range byUser {
+			if reacted {
+				users[emote] = append(users[emote], uid)
+			}
+		}
+		sort.Strings(users[emote])
+	}
+	return users, nil
+}
+
+func (s *SQLiteDAL) GetReactionUsers(messageID string) (map[string][]string, error) {
+	if !ChatEnabled() {
+		return nil, ErrChatDisabled
+	}
+	var exists int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM chat WHERE id = ? AND deleted_at IS NULL`, messageID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if exists == 0 {
+		return nil, ErrMessageNotFound
+	}
+	rows, err := s.db.Query(`
+		SELECT emote, user_id FROM chat_reactions WHERE message_id = ? ORDER BY emote, user_id
+	`, messageID)
+	if err != nil {
+		return nil, err
+	}
+	return scanReactionUsers(rows)
+}
+
+func (p *PostgresDAL) GetReactionUsers(messageID string) (map[string][]string, error) {
+	if !ChatEnabled() {
+		return nil, ErrChatDisabled
+	}
+	var exists bool
+	if err := p.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM chat WHERE id = $1 AND deleted_at IS NULL)`, messageID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrMessageNotFound
+	}
+	rows, err := p.db.Query(`
+		SELECT emote, user_id FROM chat_reactions WHERE message_id = $1 ORDER BY emote, user_id
+	`, messageID)
+	if err != nil {
+		return nil, err
+	}
+	return scanReactionUsers(rows)
+}
+
+// scanReactionUsers reads emote, user_id rows, grouped by emote, and closes
+// them.
+func scanReactionUsers(rows *sql.Rows) (map[string][]string, error) {
+	defer rows.Close()
+
+	users := make(map[string][]string)
+	for rows.Next() {
+		var emote, uid string
+		if err := rows.Scan(&emote, &uid); err != nil {
+			return nil, err
+		}
+		users[emote] = append(users[emote], uid)
+	}
+	return users, rows.Err()
+}
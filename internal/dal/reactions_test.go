@@ -0,0 +1,104 @@
+package dal
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func assertReactionUsers(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	msg, err := store.AddChatMessage("Great pick", "user")
+	if err != nil {
+		t.Fatalf("AddChatMessage() failed: %v", err)
+	}
+	for _, reaction := range []struct{ emote, user string }{
+		{"👍", "user-2"},
+		{"👍", "user-1"},
+		{"👍", "user-1"}, // a repeat is not a second author
+		{"🔥", "user-2"},
+	} {
+		if _, err := store.AddReaction(msg.ID, reaction.emote, reaction.user); err != nil {
+			t.Fatalf("AddReaction(%s, %s) failed: %v", reaction.emote, reaction.user, err)
+		}
+	}
+
+	users, err := store.GetReactionUsers(msg.ID)
+	if err != nil {
+		t.Fatalf("GetReactionUsers() failed: %v", err)
+	}
+	want := map[string][]string{"👍": {"user-1", "user-2"}, "🔥": {"user-2"}}
+	if !reflect.DeepEqual(users, want) {
+		t.Fatalf("GetReactionUsers() = %v, want %v", users, want)
+	}
+
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	if got := state.Chat[len(state.Chat)-1].Emotes["👍"]; got != 2 {
+		t.Fatalf("👍 count = %d, want 2", got)
+	}
+
+	quiet, err := store.AddChatMessage("No reactions", "user")
+	if err != nil {
+		t.Fatalf("AddChatMessage() failed: %v", err)
+	}
+	if users, err := store.GetReactionUsers(quiet.ID); err != nil || users == nil || len(users) != 0 {
+		t.Fatalf("GetReactionUsers(no reactions) = %#v, %v; want an empty map", users, err)
+	}
+	if _, err := store.GetReactionUsers("missing"); !errors.Is(err, ErrMessageNotFound) {
+		t.Fatalf("GetReactionUsers(missing) error = %v, want ErrMessageNotFound", err)
+	}
+}
+
+func TestMemoryReactionUsers(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertReactionUsers(t, NewMemoryDAL())
+}
+
+func TestSQLiteReactionUsers(t *testing.T) {
+	assertReactionUsers(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresReactionUsers(t *testing.T) {
+	assertReactionUsers(t, newTestPostgresDAL(t))
+}
+
+func TestSQLiteReactionUsersSurviveRestart(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	path := filepath.Join(t.TempDir(), "draft.sqlite")
+	store, err := NewSQLiteDAL(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteDAL() failed: %v", err)
+	}
+	msg, err := store.AddChatMessage("Great pick", "user")
+	if err != nil {
+		t.Fatalf("AddChatMessage() failed: %v", err)
+	}
+	if _, err := store.AddReaction(msg.ID, "🎉", "user-1"); err != nil {
+		t.Fatalf("AddReaction() failed: %v", err)
+	}
+
+	reopened, err := NewSQLiteDAL(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteDAL(reopen) failed: %v", err)
+	}
+	// The same user reacting again after a restart still counts once.
+	reacted, err := reopened.AddReaction(msg.ID, "🎉", "user-1")
+	if err != nil {
+		t.Fatalf("AddReaction() after reopen failed: %v", err)
+	}
+	if reacted.Emotes["🎉"] != 1 {
+		t.Fatalf("🎉 count after repeat = %d, want 1", reacted.Emotes["🎉"])
+	}
+	users, err := reopened.GetReactionUsers(msg.ID)
+	if err != nil {
+		t.Fatalf("GetReactionUsers() failed: %v", err)
+	}
+	if want := map[string][]string{"🎉": {"user-1"}}; !reflect.DeepEqual(users, want) {
+		t.Fatalf("GetReactionUsers() after reopen = %v, want %v", users, want)
+	}
+}
@@ -0,0 +1,157 @@
+package dal
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/draft"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+const defaultPickReservationTTL = 5 * time.Second
+
+// PickReservationTTL is how long a pending pick holds its player, read from
+// PICK_RESERVATION_TTL (a Go duration such as "3s").
+func PickReservationTTL() time.Duration {
+	ttl, err := time.ParseDuration(strings.TrimSpace(os.Getenv("PICK_RESERVATION_TTL")))
+	if err != nil || ttl <= 0 {
+		return defaultPickReservationTTL
+	}
+	return ttl
+}
+
+// PickReservations holds players for the team about to draft them, so a UI
+// can show a pick optimistically without another team taking the player in
+// the meantime. Like ChangeJournal it works over any DraftDAL and lives in
+// the process, so replicas don't share reservations. Every transport should
+// pick through Draft so reservations hold however a pick arrives.
+type PickReservations struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	pending map[string]models.PickReservation // by player ID
+	used    map[string]int                    // pick number each team last reserved at
+}
+
+// NewPickReservations creates an empty set of reservations.
+func NewPickReservations() *PickReservations {
+	return &PickReservations{now: time.Now, pending: make(map[string]models.PickReservation), used: make(map[string]int)}
+}
+
+// Reserve holds an undrafted playerID for teamID for PickReservationTTL.
+// Only the team on the clock may reserve, and only once per pick: asking
+// again for the same player returns the live hold unchanged, and once it
+// expires the team has to pick without one. While another team holds the
+// player it returns ErrPlayerReserved. The state is read under the lock, as
+// in Draft, so a pick through Draft can't land between the check and the
+// hold.
+func (p *PickReservations) Reserve(store DraftDAL, playerID, teamID string) (models.PickReservation, error) {
+	if draft.AuctionEnabled() {
+		return models.PickReservation{}, ErrAuctionPick
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	state, err := store.GetState()
+	if err != nil {
+		return models.PickReservation{}, err
+	}
+	if err := checkReservable(state, playerID, teamID); err != nil {
+		return models.PickReservation{}, err
+	}
+
+	if held, ok := p.heldUnsafe(playerID); ok {
+		if held.TeamID != teamID {
+			return models.PickReservation{}, ErrPlayerReserved
+		}
+		return held, nil
+	}
+	if p.used[teamID] == state.CurrentPick {
+		return models.PickReservation{}, ErrReservationUsed
+	}
+	// A hold left over from an earlier pick, say one that was undone, goes,
+	// so a team holds at most one player at a time
+	for id, held := range p.pending {
+		if held.TeamID == teamID {
+			delete(p.pending, id)
+		}
+	}
+
+	reservation := models.PickReservation{
+		PlayerID:  playerID,
+		TeamID:    teamID,
+		ExpiresAt: p.now().Add(PickReservationTTL()).UnixMilli(),
+	}
+	p.pending[playerID] = reservation
+	p.used[teamID] = state.CurrentPick
+	return reservation, nil
+}
+
+// checkReservable rejects a reservation of a missing or drafted player, or
+// by a team that isn't on the clock.
+func checkReservable(state *models.DraftState, playerID, teamID string) error {
+	if !slices.ContainsFunc(state.Teams, func(team models.Team) bool { return team.ID == teamID }) {
+		return ErrTeamNotFound
+	}
+	index := slices.IndexFunc(state.Players, func(player models.Player) bool { return player.ID == playerID })
+	if index < 0 {
+		return ErrPlayerNotFound
+	}
+	if state.Players[index].Drafted {
+		return ErrPlayerAlreadyDrafted
+	}
+	if state.CurrentTeamID == "" {
+		return validationErrorf("draft is complete")
+	}
+	if state.CurrentTeamID != teamID {
+		return validationErrorf("it is %s's turn", state.CurrentTeamName)
+	}
+	return nil
+}
+
+// Check returns ErrPlayerReserved if a team other than teamID holds
+// playerID.
+func (p *PickReservations) Check(playerID, teamID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if held, ok := p.heldUnsafe(playerID); ok && held.TeamID != teamID {
+		return ErrPlayerReserved
+	}
+	return nil
+}
+
+// Draft drafts playerID to teamID unless another team holds the player, then
+// releases any hold on it. It is the pick path both HTTP and gRPC use. The
+// lock is held across the pick, so no hold can be taken between the check
+// and the draft, and the draft and the release.
+func (p *PickReservations) Draft(store DraftDAL, playerID, teamID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if held, ok := p.heldUnsafe(playerID); ok && held.TeamID != teamID {
+		return ErrPlayerReserved
+	}
+	if err := store.DraftPlayer(playerID, teamID); err != nil {
+		return err
+	}
+	delete(p.pending, playerID)
+	return nil
+}
+
+// heldUnsafe returns the live reservation on playerID, forgetting it if it
+// has expired.
+func (p *PickReservations) heldUnsafe(playerID string) (models.PickReservation, bool) {
+	held, ok := p.pending[playerID]
+	if !ok {
+		return held, false
+	}
+	if p.now().UnixMilli() >= held.ExpiresAt {
+		delete(p.pending, playerID)
+		return held, false
+	}
+	return held, true
+}
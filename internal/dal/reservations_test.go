@@ -0,0 +1,163 @@
+package dal
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+func newTestPickReservations(t *testing.T) (*PickReservations, *time.Time) {
+	t.Helper()
+	t.Setenv("PICK_RESERVATION_TTL", "5s")
+
+	now := time.Unix(1700000000, 0)
+	reservations := NewPickReservations()
+	reservations.now = func() time.Time { return now }
+	return reservations, &now
+}
+
+// newReservationDraft returns a store with two teams, the first on the
+// clock, and two undrafted players.
+func newReservationDraft(t *testing.T) (store *MemoryDAL, first, second *models.Team, players []*models.Player) {
+	t.Helper()
+	t.Setenv("ENVIRONMENT", "production")
+
+	store = NewMemoryDAL()
+	first, _ = store.AddTeam("First", "", "", "")
+	second, _ = store.AddTeam("Second", "", "", "")
+	for _, name := range []string{"Held Bun", "Other Bun"} {
+		player, err := store.AddPlayer(&models.Player{Name: name, Position: "CC", Team: "Test", Points: 10, Tier: models.TierB})
+		if err != nil {
+			t.Fatalf("AddPlayer() failed: %v", err)
+		}
+		players = append(players, player)
+	}
+	return store, first, second, players
+}
+
+func TestPickReservationIsConfirmedByItsTeam(t *testing.T) {
+	reservations, _ := newTestPickReservations(t)
+	store, first, second, players := newReservationDraft(t)
+	held := players[0].ID
+
+	reservation, err := reservations.Reserve(store, held, first.ID)
+	if err != nil {
+		t.Fatalf("Reserve() failed: %v", err)
+	}
+	if want := time.Unix(1700000005, 0).UnixMilli(); reservation.ExpiresAt != want {
+		t.Fatalf("ExpiresAt = %d, want %d", reservation.ExpiresAt, want)
+	}
+
+	if err := reservations.Check(held, second.ID); !errors.Is(err, ErrPlayerReserved) || !errors.Is(err, ErrAlreadyDrafted) {
+		t.Fatalf("Check() by another team = %v, want ErrPlayerReserved", err)
+	}
+	if err := reservations.Draft(store, held, second.ID); !errors.Is(err, ErrPlayerReserved) {
+		t.Fatalf("Draft() by another team = %v, want ErrPlayerReserved", err)
+	}
+
+	// Confirming the pick releases the player.
+	if err := reservations.Draft(store, held, first.ID); err != nil {
+		t.Fatalf("Draft() by the reserving team = %v", err)
+	}
+	if err := reservations.Check(held, second.ID); err != nil {
+		t.Fatalf("Check() after the pick = %v", err)
+	}
+}
+
+func TestPickReservationNeedsTheTeamOnTheClock(t *testing.T) {
+	reservations, _ := newTestPickReservations(t)
+	store, first, second, players := newReservationDraft(t)
+
+	if _, err := reservations.Reserve(store, players[0].ID, second.ID); !errors.Is(err, ErrValidation) {
+		t.Fatalf("Reserve() out of turn = %v, want ErrValidation", err)
+	}
+	if _, err := reservations.Reserve(store, "missing", first.ID); !errors.Is(err, ErrPlayerNotFound) {
+		t.Fatalf("Reserve(missing player) = %v, want ErrPlayerNotFound", err)
+	}
+	if _, err := reservations.Reserve(store, players[0].ID, "missing"); !errors.Is(err, ErrTeamNotFound) {
+		t.Fatalf("Reserve(missing team) = %v, want ErrTeamNotFound", err)
+	}
+
+	if err := store.DraftPlayer(players[0].ID, first.ID); err != nil {
+		t.Fatalf("DraftPlayer() failed: %v", err)
+	}
+	if _, err := reservations.Reserve(store, players[0].ID, second.ID); !errors.Is(err, ErrPlayerAlreadyDrafted) {
+		t.Fatalf("Reserve(drafted player) = %v, want ErrPlayerAlreadyDrafted", err)
+	}
+}
+
+func TestPickReservationIsOncePerPick(t *testing.T) {
+	reservations, now := newTestPickReservations(t)
+	store, first, _, players := newReservationDraft(t)
+
+	reservation, err := reservations.Reserve(store, players[0].ID, first.ID)
+	if err != nil {
+		t.Fatalf("Reserve() failed: %v", err)
+	}
+
+	// Asking again doesn't extend the hold
+	*now = now.Add(4 * time.Second)
+	again, err := reservations.Reserve(store, players[0].ID, first.ID)
+	if err != nil || again != reservation {
+		t.Fatalf("repeated Reserve() = %+v, %v; want the original %+v", again, err, reservation)
+	}
+	if _, err := reservations.Reserve(store, players[1].ID, first.ID); !errors.Is(err, ErrReservationUsed) {
+		t.Fatalf("Reserve() of a second player = %v, want ErrReservationUsed", err)
+	}
+
+	// An expired hold can't be taken out again for the same pick
+	*now = now.Add(time.Second)
+	if err := reservations.Check(players[0].ID, "any-team"); err != nil {
+		t.Fatalf("Check() after expiry = %v", err)
+	}
+	if _, err := reservations.Reserve(store, players[0].ID, first.ID); !errors.Is(err, ErrReservationUsed) {
+		t.Fatalf("Reserve() after expiry = %v, want ErrReservationUsed", err)
+	}
+}
+
+// pickAfterRead runs pick once, right after the first GetState reads, which
+// is when Reserve has checked the player but not yet held it. It waits for
+// the pick unless the pick blocks.
+type pickAfterRead struct {
+	*MemoryDAL
+	pick   func()
+	picked chan struct{}
+}
+
+func (s *pickAfterRead) GetState() (*models.DraftState, error) {
+	state, err := s.MemoryDAL.GetState()
+	if s.pick != nil {
+		pick := s.pick
+		s.pick = nil
+		go func() {
+			pick()
+			close(s.picked)
+		}()
+		select {
+		case <-s.picked:
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	return state, err
+}
+
+func TestPickReservationNeverHoldsADraftedPlayer(t *testing.T) {
+	reservations, _ := newTestPickReservations(t)
+	memory, first, second, players := newReservationDraft(t)
+	held := players[0].ID
+
+	store := &pickAfterRead{MemoryDAL: memory, picked: make(chan struct{})}
+	store.pick = func() {
+		if err := reservations.Draft(memory, held, first.ID); err != nil {
+			t.Errorf("Draft() failed: %v", err)
+		}
+	}
+	reservations.Reserve(store, held, first.ID)
+	<-store.picked
+
+	if err := reservations.Check(held, second.ID); err != nil {
+		t.Fatalf("Check() after the pick = %v, want the drafted player unheld", err)
+	}
+}
@@ -0,0 +1,124 @@
+package dal
+
+import (
+	"sort"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// newResetPreview summarizes a reset of a draft holding playerIDs and
+// teamNames: every team goes, and so does every player the default catalog
+// won't reseed. The development environment re-seeds the default teams, so
+// those are reported as coming back.
+func newResetPreview(picks, chatMessages int, playerIDs, teamNames []string) *models.ResetPreview {
+	catalog := map[string]bool{}
+	if SeedDefaultCatalogEnabled() {
+		for _, player := range getDefaultPlayers() {
+			catalog[player.ID] = true
+		}
+	}
+	custom := 0
+	for _, id := range playerIDs {
+		if !catalog[id] {
+			custom++
+		}
+	}
+	teams := append([]string{}, teamNames...)
+	sort.Strings(teams)
+	reseeded := []string{}
+	if IsDevEnvironment() {
+		for _, team := range getDefaultTeams() {
+			reseeded = append(reseeded, team.Name)
+		}
+		sort.Strings(reseeded)
+	}
+	return &models.ResetPreview{
+		PicksRemoved:         picks,
+		CustomPlayersDeleted: custom,
+		ChatMessagesPurged:   chatMessages,
+		TeamsAffected:        teams,
+		TeamsReseeded:        reseeded,
+	}
+}
+
+func (m *MemoryDAL) PreviewReset() (*models.ResetPreview, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	picks := 0
+	teamNames := make([]string, len(m.teams))
+	for i, team := range m.teams {
+		picks += len(team.Players)
+		teamNames[i] = team.Name
+	}
+	playerIDs := make([]string, len(m.players))
+	for i, player := range m.players {
+		playerIDs[i] = player.ID
+	}
+	return newResetPreview(picks, len(m.chat)+len(m.clearedChat), playerIDs, teamNames), nil
+}
+
+func (s *SQLiteDAL) PreviewReset() (*models.ResetPreview, error) {
+	return previewResetSQL(s.db)
+}
+
+func (p *PostgresDAL) PreviewReset() (*models.ResetPreview, error) {
+	return previewResetSQL(p.db)
+}
+
+// previewResetSQL only reads, with queries that have no placeholders, so it
+// works on every SQL backend.
+func previewResetSQL(q sqlQueryer) (*models.ResetPreview, error) {
+	picks, err := queryCount(q, `SELECT COUNT(*) FROM team_players`)
+	if err != nil {
+		return nil, err
+	}
+	// Reset deletes cleared messages too, not just the visible ones
+	chatMessages, err := queryCount(q, `SELECT COUNT(*) FROM chat`)
+	if err != nil {
+		return nil, err
+	}
+	playerIDs, err := queryStrings(q, `SELECT id FROM players`)
+	if err != nil {
+		return nil, err
+	}
+	teamNames, err := queryStrings(q, `SELECT name FROM teams`)
+	if err != nil {
+		return nil, err
+	}
+	return newResetPreview(picks, chatMessages, playerIDs, teamNames), nil
+}
+
+func queryCount(q sqlQueryer, query string) (int, error) {
+	rows, err := q.Query(query)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var count int
+	if rows.Next() {
+		if err := rows.Scan(&count); err != nil {
+			return 0, err
+		}
+	}
+	return count, rows.Err()
+}
+
+func queryStrings(q sqlQueryer, query string) ([]string, error) {
+	rows, err := q.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []string{}
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
@@ -0,0 +1,174 @@
+package dal
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// assertResetPreview checks the preview against a draft holding the default
+// catalog plus one custom player, one pick and a chat message, and that
+// taking it changes nothing.
+func assertResetPreview(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	team, err := store.AddTeam("Preview Foxes", "", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	if _, err := store.AddPlayer(&models.Player{Name: "Custom Bun", Position: "CC", Team: "Test", Points: 10, Tier: models.TierB}); err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+	if err := store.DraftPlayer("1", team.ID); err != nil {
+		t.Fatalf("DraftPlayer() failed: %v", err)
+	}
+	if _, err := store.AddChatMessage("Before the reset", "user"); err != nil {
+		t.Fatalf("AddChatMessage() failed: %v", err)
+	}
+
+	before, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	preview, err := store.PreviewReset()
+	if err != nil {
+		t.Fatalf("PreviewReset() failed: %v", err)
+	}
+	if preview.PicksRemoved != 1 || preview.CustomPlayersDeleted != 1 || preview.ChatMessagesPurged != len(before.Chat) ||
+		len(preview.TeamsAffected) != 1 || preview.TeamsAffected[0] != "Preview Foxes" {
+		t.Fatalf("PreviewReset() = %+v, want 1 pick, 1 custom player, %d messages and Preview Foxes", preview, len(before.Chat))
+	}
+
+	after, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() after preview failed: %v", err)
+	}
+	beforeJSON, _ := json.Marshal(before)
+	afterJSON, _ := json.Marshal(after)
+	if !bytes.Equal(beforeJSON, afterJSON) {
+		t.Fatalf("state changed after PreviewReset():\nbefore %s\nafter  %s", beforeJSON, afterJSON)
+	}
+
+	// The preview matches what Reset then does
+	if err := store.Reset(); err != nil {
+		t.Fatalf("Reset() failed: %v", err)
+	}
+	reset, err := store.PreviewReset()
+	if err != nil {
+		t.Fatalf("PreviewReset() after Reset failed: %v", err)
+	}
+	if reset.PicksRemoved != 0 || reset.CustomPlayersDeleted != 0 || len(reset.TeamsAffected) != 0 || len(reset.TeamsReseeded) != 0 {
+		t.Fatalf("PreviewReset() after Reset = %+v, want nothing left to remove", reset)
+	}
+}
+
+// assertResetPreviewReportsReseededTeams checks that in development the
+// preview names the default teams Reset brings back, and that they're the
+// teams left once it has.
+func assertResetPreviewReportsReseededTeams(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	if _, err := store.AddTeam("Preview Foxes", "", "", ""); err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	preview, err := store.PreviewReset()
+	if err != nil {
+		t.Fatalf("PreviewReset() failed: %v", err)
+	}
+	want := []string{}
+	for _, team := range getDefaultTeams() {
+		want = append(want, team.Name)
+	}
+	sort.Strings(want)
+	if !slices.Equal(preview.TeamsReseeded, want) {
+		t.Fatalf("TeamsReseeded = %v, want %v", preview.TeamsReseeded, want)
+	}
+	if !slices.Contains(preview.TeamsAffected, "Preview Foxes") {
+		t.Fatalf("TeamsAffected = %v, want it to include Preview Foxes", preview.TeamsAffected)
+	}
+
+	if err := store.Reset(); err != nil {
+		t.Fatalf("Reset() failed: %v", err)
+	}
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	got := []string{}
+	for _, team := range state.Teams {
+		got = append(got, team.Name)
+	}
+	sort.Strings(got)
+	if !slices.Equal(got, preview.TeamsReseeded) {
+		t.Fatalf("teams after Reset() = %v, want the previewed %v", got, preview.TeamsReseeded)
+	}
+}
+
+func TestMemoryResetPreview(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("JELLYCAT_SEED_DEFAULT_CATALOG", "true")
+	assertResetPreview(t, NewMemoryDAL())
+}
+
+func TestSQLiteResetPreview(t *testing.T) {
+	t.Setenv("JELLYCAT_SEED_DEFAULT_CATALOG", "true")
+	assertResetPreview(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresResetPreview(t *testing.T) {
+	t.Setenv("JELLYCAT_SEED_DEFAULT_CATALOG", "true")
+	assertResetPreview(t, newTestPostgresDAL(t))
+}
+
+func TestMemoryResetPreviewReportsReseededTeams(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "development")
+	assertResetPreviewReportsReseededTeams(t, NewMemoryDAL())
+}
+
+func TestSQLiteResetPreviewReportsReseededTeams(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "development")
+
+	store, err := NewSQLiteDAL(filepath.Join(t.TempDir(), "draft.sqlite"))
+	if err != nil {
+		t.Fatalf("NewSQLiteDAL() failed: %v", err)
+	}
+	assertResetPreviewReportsReseededTeams(t, store)
+}
+
+func TestSQLiteResetPreviewLeavesTheFileUntouched(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+
+	path := filepath.Join(t.TempDir(), "draft.sqlite")
+	store, err := NewSQLiteDAL(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteDAL() failed: %v", err)
+	}
+	if _, err := store.AddTeam("Untouched", "", "", ""); err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	if _, err := store.AddChatMessage("Still here", "user"); err != nil {
+		t.Fatalf("AddChatMessage() failed: %v", err)
+	}
+
+	read := func() []byte {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read database file: %v", err)
+		}
+		return data
+	}
+	before := read()
+	if _, err := store.PreviewReset(); err != nil {
+		t.Fatalf("PreviewReset() failed: %v", err)
+	}
+	if !bytes.Equal(before, read()) {
+		t.Fatal("database file changed after PreviewReset()")
+	}
+}
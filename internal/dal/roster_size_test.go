@@ -0,0 +1,94 @@
+package dal
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// assertRosterSizeCap drafts two teams up to a one-player cap and checks the
+// draft ends with players still on the board.
+func assertRosterSizeCap(t *testing.T, store DraftDAL) {
+	t.Helper()
+	t.Setenv("MAX_ROSTER_SIZE", "1")
+
+	for _, name := range []string{"Capped A", "Capped B"} {
+		if _, err := store.AddTeam(name, "", "", ""); err != nil {
+			t.Fatalf("AddTeam() failed: %v", err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := store.AddPlayer(&models.Player{Name: fmt.Sprintf("Capped Bun %d", i), Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB}); err != nil {
+			t.Fatalf("AddPlayer() failed: %v", err)
+		}
+	}
+
+	for pick := 0; pick < 2; pick++ {
+		state, err := store.GetState()
+		if err != nil {
+			t.Fatalf("GetState() failed: %v", err)
+		}
+		if err := store.DraftPlayer(firstUndrafted(state).ID, state.CurrentTeamID); err != nil {
+			t.Fatalf("DraftPlayer() pick %d failed: %v", pick+1, err)
+		}
+	}
+
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	if state.MaxRosterSize != 1 {
+		t.Fatalf("MaxRosterSize = %d, want 1", state.MaxRosterSize)
+	}
+	for _, team := range state.Teams {
+		if team.RosterSlotsRemaining == nil || *team.RosterSlotsRemaining != 0 {
+			t.Fatalf("%s RosterSlotsRemaining = %v, want 0", team.Name, team.RosterSlotsRemaining)
+		}
+	}
+	if err := store.DraftPlayer(firstUndrafted(state).ID, state.Teams[0].ID); !errors.Is(err, ErrValidation) {
+		t.Fatalf("DraftPlayer() past the cap error = %v, want %v", err, ErrValidation)
+	}
+}
+
+func firstUndrafted(state *models.DraftState) *models.Player {
+	for i := range state.Players {
+		if !state.Players[i].Drafted {
+			return &state.Players[i]
+		}
+	}
+	return nil
+}
+
+func TestMemoryRosterSizeCap(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertRosterSizeCap(t, NewMemoryDAL())
+}
+
+func TestSQLiteRosterSizeCap(t *testing.T) {
+	assertRosterSizeCap(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresRosterSizeCap(t *testing.T) {
+	assertRosterSizeCap(t, newTestPostgresDAL(t))
+}
+
+func TestCheckRosterSize(t *testing.T) {
+	if err := checkRosterSize("Unlimited", 50); err != nil {
+		t.Fatalf("checkRosterSize() without a cap = %v, want nil", err)
+	}
+
+	t.Setenv("MAX_ROSTER_SIZE", "2")
+	if err := checkRosterSize("Full", 2); !errors.Is(err, ErrRosterFull) || !errors.Is(err, ErrValidation) {
+		t.Fatalf("checkRosterSize() at the cap = %v, want %v", err, ErrRosterFull)
+	}
+	if err := checkRosterSize("Open", 1); err != nil {
+		t.Fatalf("checkRosterSize() below the cap = %v, want nil", err)
+	}
+
+	t.Setenv("MAX_ROSTER_SIZE", "lots")
+	if MaxRosterSize() != 0 {
+		t.Fatalf("MaxRosterSize() with an invalid value = %d, want 0", MaxRosterSize())
+	}
+}
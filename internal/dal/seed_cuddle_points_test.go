@@ -0,0 +1,57 @@
+package dal
+
+import "testing"
+
+// assertSeedCuddlePoints checks every seeded player starts at the
+// DEFAULT_CUDDLE_POINTS baseline set before store was created.
+func assertSeedCuddlePoints(t *testing.T, store DraftDAL, want int) {
+	t.Helper()
+
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	if len(state.Players) == 0 {
+		t.Fatal("no players were seeded")
+	}
+	for _, player := range state.Players {
+		if player.CuddlePoints != want {
+			t.Fatalf("player %s cuddle points = %d, want %d", player.ID, player.CuddlePoints, want)
+		}
+	}
+}
+
+func seedWithCuddlePoints(t *testing.T, points string) {
+	t.Helper()
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("JELLYCAT_SEED_DEFAULT_CATALOG", "true")
+	t.Setenv("DEFAULT_CUDDLE_POINTS", points)
+}
+
+func TestMemorySeedCuddlePoints(t *testing.T) {
+	seedWithCuddlePoints(t, "73")
+	assertSeedCuddlePoints(t, NewMemoryDAL(), 73)
+}
+
+func TestSQLiteSeedCuddlePoints(t *testing.T) {
+	seedWithCuddlePoints(t, "73")
+	assertSeedCuddlePoints(t, newTestSQLiteDAL(t), 73)
+}
+
+func TestPostgresSeedCuddlePoints(t *testing.T) {
+	seedWithCuddlePoints(t, "73")
+	assertSeedCuddlePoints(t, newTestPostgresDAL(t), 73)
+}
+
+func TestDefaultCuddlePointsFallsBackOutsideRange(t *testing.T) {
+	for _, value := range []string{"", "-1", "101", "lots"} {
+		t.Setenv("DEFAULT_CUDDLE_POINTS", value)
+		if got := DefaultCuddlePoints(); got != 50 {
+			t.Fatalf("DefaultCuddlePoints() with %q = %d, want 50", value, got)
+		}
+	}
+	t.Setenv("DEFAULT_CUDDLE_POINTS", "0")
+	if got := DefaultCuddlePoints(); got != 0 {
+		t.Fatalf("DefaultCuddlePoints() with 0 = %d, want 0", got)
+	}
+}
@@ -0,0 +1,152 @@
+package dal
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type storedSession struct {
+	data      []byte
+	expiresAt int64
+	idp       IdPSession
+}
+
+func nowMillis() int64 {
+	return time.Now().UnixMilli()
+}
+
+// Memory
+
+func (m *MemoryDAL) SaveSession(id string, data []byte, expiresAt int64, idp IdPSession) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := nowMillis()
+	for sessionID, session := range m.sessions {
+		if session.expiresAt <= now {
+			delete(m.sessions, sessionID)
+		}
+	}
+	m.sessions[id] = storedSession{data: append([]byte(nil), data...), expiresAt: expiresAt, idp: idp}
+	return nil
+}
+
+func (m *MemoryDAL) GetSession(id string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	session, ok := m.sessions[id]
+	if !ok || session.expiresAt <= nowMillis() {
+		return nil, ErrSessionNotFound
+	}
+	return append([]byte(nil), session.data...), nil
+}
+
+func (m *MemoryDAL) DeleteSession(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.sessions, id)
+	return nil
+}
+
+func (m *MemoryDAL) DeleteIdPSessions(idp IdPSession) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := 0
+	for id, session := range m.sessions {
+		if idp.Matches(session.idp) {
+			delete(m.sessions, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// SQLite
+
+func (s *SQLiteDAL) SaveSession(id string, data []byte, expiresAt int64, idp IdPSession) error {
+	// Expired rows are purged on write so the table stays bounded without a janitor.
+	if _, err := s.db.Exec(`DELETE FROM sessions WHERE expires_at <= ?`, nowMillis()); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO sessions (id, data, expires_at, idp_subject, idp_session_id) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET data = excluded.data, expires_at = excluded.expires_at,
+			idp_subject = excluded.idp_subject, idp_session_id = excluded.idp_session_id
+	`, id, data, expiresAt, idp.Subject, idp.SessionID)
+	return err
+}
+
+func (s *SQLiteDAL) GetSession(id string) ([]byte, error) {
+	return scanSession(s.db.QueryRow(`SELECT data FROM sessions WHERE id = ? AND expires_at > ?`, id, nowMillis()))
+}
+
+func (s *SQLiteDAL) DeleteSession(id string) error {
+	_, err := s.db.Exec(`DELETE FROM sessions WHERE id = ?`, id)
+	return err
+}
+
+func (s *SQLiteDAL) DeleteIdPSessions(idp IdPSession) (int, error) {
+	if idp == (IdPSession{}) {
+		return 0, nil
+	}
+	result, err := s.db.Exec(`
+		DELETE FROM sessions
+		WHERE (? = '' OR idp_subject = ?) AND (? = '' OR idp_session_id = ?)
+	`, idp.Subject, idp.Subject, idp.SessionID, idp.SessionID)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := result.RowsAffected()
+	return int(deleted), err
+}
+
+// Postgres
+
+func (p *PostgresDAL) SaveSession(id string, data []byte, expiresAt int64, idp IdPSession) error {
+	if _, err := p.db.Exec(`DELETE FROM sessions WHERE expires_at <= $1`, nowMillis()); err != nil {
+		return err
+	}
+	_, err := p.db.Exec(`
+		INSERT INTO sessions (id, data, expires_at, idp_subject, idp_session_id) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO UPDATE SET data = EXCLUDED.data, expires_at = EXCLUDED.expires_at,
+			idp_subject = EXCLUDED.idp_subject, idp_session_id = EXCLUDED.idp_session_id
+	`, id, data, expiresAt, idp.Subject, idp.SessionID)
+	return err
+}
+
+func (p *PostgresDAL) GetSession(id string) ([]byte, error) {
+	return scanSession(p.db.QueryRow(`SELECT data FROM sessions WHERE id = $1 AND expires_at > $2`, id, nowMillis()))
+}
+
+func (p *PostgresDAL) DeleteSession(id string) error {
+	_, err := p.db.Exec(`DELETE FROM sessions WHERE id = $1`, id)
+	return err
+}
+
+func (p *PostgresDAL) DeleteIdPSessions(idp IdPSession) (int, error) {
+	if idp == (IdPSession{}) {
+		return 0, nil
+	}
+	result, err := p.db.Exec(`
+		DELETE FROM sessions
+		WHERE ($1 = '' OR idp_subject = $1) AND ($2 = '' OR idp_session_id = $2)
+	`, idp.Subject, idp.SessionID)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := result.RowsAffected()
+	return int(deleted), err
+}
+
+func scanSession(row *sql.Row) ([]byte, error) {
+	var data []byte
+	err := row.Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSessionNotFound
+	}
+	return data, err
+}
@@ -0,0 +1,98 @@
+package dal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func assertSessionStoreRoundTrip(t *testing.T, store SessionStore) {
+	t.Helper()
+
+	expiresAt := time.Now().Add(time.Hour).UnixMilli()
+	if err := store.SaveSession("sess_1", []byte(`{"user":"u1"}`), expiresAt, IdPSession{}); err != nil {
+		t.Fatalf("SaveSession() failed: %v", err)
+	}
+	data, err := store.GetSession("sess_1")
+	if err != nil || string(data) != `{"user":"u1"}` {
+		t.Fatalf("GetSession() = %q, %v; want stored data", data, err)
+	}
+
+	if err := store.SaveSession("sess_1", []byte(`{"user":"u2"}`), expiresAt, IdPSession{}); err != nil {
+		t.Fatalf("SaveSession() replace failed: %v", err)
+	}
+	if data, _ := store.GetSession("sess_1"); string(data) != `{"user":"u2"}` {
+		t.Fatalf("GetSession() after replace = %q, want replaced data", data)
+	}
+
+	if err := store.SaveSession("sess_expired", []byte(`{}`), time.Now().Add(-time.Second).UnixMilli(), IdPSession{}); err != nil {
+		t.Fatalf("SaveSession() expired failed: %v", err)
+	}
+	if _, err := store.GetSession("sess_expired"); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("GetSession(expired) error = %v, want %v", err, ErrSessionNotFound)
+	}
+
+	if err := store.DeleteSession("sess_1"); err != nil {
+		t.Fatalf("DeleteSession() failed: %v", err)
+	}
+	if _, err := store.GetSession("sess_1"); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("GetSession() after delete error = %v, want %v", err, ErrSessionNotFound)
+	}
+}
+
+func assertSessionStoreDeletesIdPSessions(t *testing.T, store SessionStore) {
+	t.Helper()
+
+	expiresAt := time.Now().Add(time.Hour).UnixMilli()
+	for id, idp := range map[string]IdPSession{
+		"sess_laptop": {Subject: "sub-1", SessionID: "sid-laptop"},
+		"sess_phone":  {Subject: "sub-1", SessionID: "sid-phone"},
+		"sess_other":  {Subject: "sub-2", SessionID: "sid-other"},
+		"sess_local":  {},
+	} {
+		if err := store.SaveSession(id, []byte(`{}`), expiresAt, idp); err != nil {
+			t.Fatalf("SaveSession(%s) failed: %v", id, err)
+		}
+	}
+	remaining := func(want ...string) {
+		t.Helper()
+		for _, id := range []string{"sess_laptop", "sess_phone", "sess_other", "sess_local"} {
+			_, err := store.GetSession(id)
+			kept := false
+			for _, wanted := range want {
+				kept = kept || wanted == id
+			}
+			if kept != (err == nil) {
+				t.Fatalf("GetSession(%s) error = %v, want kept = %v", id, err, kept)
+			}
+		}
+	}
+
+	if deleted, err := store.DeleteIdPSessions(IdPSession{}); err != nil || deleted != 0 {
+		t.Fatalf("DeleteIdPSessions(empty) = %d, %v; want nothing deleted", deleted, err)
+	}
+	if deleted, err := store.DeleteIdPSessions(IdPSession{Subject: "sub-1", SessionID: "sid-laptop"}); err != nil || deleted != 1 {
+		t.Fatalf("DeleteIdPSessions(sid) = %d, %v; want 1", deleted, err)
+	}
+	remaining("sess_phone", "sess_other", "sess_local")
+	if deleted, err := store.DeleteIdPSessions(IdPSession{Subject: "sub-2"}); err != nil || deleted != 1 {
+		t.Fatalf("DeleteIdPSessions(sub) = %d, %v; want 1", deleted, err)
+	}
+	remaining("sess_phone", "sess_local")
+}
+
+func TestMemorySessionStore(t *testing.T) {
+	assertSessionStoreRoundTrip(t, NewMemoryDAL())
+	assertSessionStoreDeletesIdPSessions(t, NewMemoryDAL())
+}
+
+func TestSQLiteSessionStore(t *testing.T) {
+	assertSessionStoreRoundTrip(t, newTestSQLiteDAL(t))
+	assertSessionStoreDeletesIdPSessions(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresSessionStore(t *testing.T) {
+	store := newTestPostgresDAL(t)
+	assertSessionStoreRoundTrip(t, store)
+	assertSessionStoreDeletesIdPSessions(t, store)
+}
@@ -1,21 +1,31 @@
 package dal
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/draft"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/ids"
 	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
 )
 
 // SQLiteDAL implements DraftDAL using SQLite
 type SQLiteDAL struct {
-	db            *sql.DB
-	reactionUsers map[string]map[string]map[string]bool
+	db *sql.DB
+	// pickMu serializes picks and undos: SQLite transactions don't lock on
+	// read, so two picks could otherwise number from the same count.
+	pickMu sync.Mutex
+	// ownerMu serializes changes to team owners, so the UNIQUE_OWNERS check
+	// and the write it allows can't interleave with another owner change.
+	ownerMu sync.Mutex
 }
 
 // NewSQLiteDAL creates a new SQLite data access layer
@@ -26,8 +36,7 @@ func NewSQLiteDAL(dbPath string) (*SQLiteDAL, error) {
 	}
 
 	dal := &SQLiteDAL{
-		db:            db,
-		reactionUsers: make(map[string]map[string]map[string]bool),
+		db: db,
 	}
 
 	if err := dal.initSchema(); err != nil {
@@ -49,16 +58,21 @@ func (s *SQLiteDAL) initSchema() error {
 		tier TEXT NOT NULL,
 		drafted INTEGER NOT NULL DEFAULT 0,
 		drafted_by TEXT,
-		image TEXT
+		image TEXT,
+		created_at INTEGER NOT NULL DEFAULT 0,
+		updated_at INTEGER NOT NULL DEFAULT 0
 	);
 
 	CREATE TABLE IF NOT EXISTS teams (
 		id TEXT PRIMARY KEY,
 		name TEXT NOT NULL,
 		owner TEXT NOT NULL,
+		owner_user_id TEXT NOT NULL DEFAULT '',
 		mascot TEXT NOT NULL,
 		color TEXT NOT NULL,
-		display_order INTEGER
+		display_order INTEGER,
+		created_at INTEGER NOT NULL DEFAULT 0,
+		updated_at INTEGER NOT NULL DEFAULT 0
 	);
 
 	CREATE TABLE IF NOT EXISTS team_players (
@@ -66,6 +80,8 @@ func (s *SQLiteDAL) initSchema() error {
 		player_id TEXT NOT NULL,
 		player_data TEXT NOT NULL,
 		draft_pick_number INTEGER,
+		pre_draft_points INTEGER,
+		pre_draft_cuddle_points INTEGER,
 		FOREIGN KEY (team_id) REFERENCES teams(id),
 		FOREIGN KEY (player_id) REFERENCES players(id)
 	);
@@ -75,13 +91,67 @@ func (s *SQLiteDAL) initSchema() error {
 		ts INTEGER NOT NULL,
 		type TEXT NOT NULL,
 		text TEXT NOT NULL,
-		emotes TEXT NOT NULL
+		sender TEXT NOT NULL DEFAULT '',
+		emotes TEXT NOT NULL,
+		deleted_at INTEGER,
+		updated_at INTEGER NOT NULL DEFAULT 0,
+		message TEXT
+	);
+
+	CREATE TABLE IF NOT EXISTS chat_reactions (
+		message_id TEXT NOT NULL,
+		emote TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		PRIMARY KEY (message_id, emote, user_id)
 	);
 
 	CREATE TABLE IF NOT EXISTS draft_settings (
 		key TEXT PRIMARY KEY,
 		value TEXT NOT NULL
 	);
+
+	-- A single row counting changes to the draft; Reset leaves it alone.
+	CREATE TABLE IF NOT EXISTS state_version (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		version INTEGER NOT NULL DEFAULT 0
+	);
+	INSERT OR IGNORE INTO state_version (id, version) VALUES (1, 0);
+
+	CREATE TABLE IF NOT EXISTS access_tokens (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		owner_id TEXT NOT NULL,
+		owner_username TEXT NOT NULL,
+		owner_name TEXT NOT NULL,
+		owner_email TEXT NOT NULL,
+		owner_groups TEXT NOT NULL,
+		scopes TEXT NOT NULL,
+		token_hash TEXT NOT NULL UNIQUE,
+		created_at INTEGER NOT NULL,
+		last_used_at INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS sessions (
+		id TEXT PRIMARY KEY,
+		data BLOB NOT NULL,
+		expires_at INTEGER NOT NULL,
+		idp_subject TEXT NOT NULL DEFAULT '',
+		idp_session_id TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE TABLE IF NOT EXISTS auth_events (
+		id TEXT PRIMARY KEY,
+		type TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		username TEXT NOT NULL,
+		ip TEXT NOT NULL,
+		user_agent TEXT NOT NULL,
+		detail TEXT NOT NULL,
+		impersonator_id TEXT NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_auth_events_created_at ON auth_events(created_at);
 	`
 
 	if _, err := s.db.Exec(schema); err != nil {
@@ -125,6 +195,28 @@ func (s *SQLiteDAL) initSchema() error {
 		}
 	}
 
+	// Pre-draft points let a pick be undone; older picks keep their drafted values.
+	var preDraftPointsExists int
+	err = s.db.QueryRow(`
+		SELECT COUNT(*)
+		FROM pragma_table_info('team_players')
+		WHERE name='pre_draft_points'
+	`).Scan(&preDraftPointsExists)
+	if err != nil {
+		return fmt.Errorf("failed to check pre_draft_points column existence: %w", err)
+	}
+
+	if preDraftPointsExists == 0 {
+		_, err = s.db.Exec(`ALTER TABLE team_players ADD COLUMN pre_draft_points INTEGER`)
+		if err != nil {
+			return fmt.Errorf("failed to add pre_draft_points column: %w", err)
+		}
+		_, err = s.db.Exec(`ALTER TABLE team_players ADD COLUMN pre_draft_cuddle_points INTEGER`)
+		if err != nil {
+			return fmt.Errorf("failed to add pre_draft_cuddle_points column: %w", err)
+		}
+	}
+
 	var teamDisplayOrderExists int
 	err = s.db.QueryRow(`
 		SELECT COUNT(*)
@@ -142,6 +234,138 @@ func (s *SQLiteDAL) initSchema() error {
 		}
 	}
 
+	var teamOwnerUserIDExists int
+	err = s.db.QueryRow(`
+		SELECT COUNT(*)
+		FROM pragma_table_info('teams')
+		WHERE name='owner_user_id'
+	`).Scan(&teamOwnerUserIDExists)
+	if err != nil {
+		return fmt.Errorf("failed to check teams owner_user_id column existence: %w", err)
+	}
+
+	if teamOwnerUserIDExists == 0 {
+		_, err = s.db.Exec(`ALTER TABLE teams ADD COLUMN owner_user_id TEXT NOT NULL DEFAULT ''`)
+		if err != nil {
+			return fmt.Errorf("failed to add teams owner_user_id column: %w", err)
+		}
+	}
+
+	var authEventImpersonatorExists int
+	err = s.db.QueryRow(`
+		SELECT COUNT(*)
+		FROM pragma_table_info('auth_events')
+		WHERE name='impersonator_id'
+	`).Scan(&authEventImpersonatorExists)
+	if err != nil {
+		return fmt.Errorf("failed to check auth_events impersonator_id column existence: %w", err)
+	}
+
+	if authEventImpersonatorExists == 0 {
+		_, err = s.db.Exec(`ALTER TABLE auth_events ADD COLUMN impersonator_id TEXT NOT NULL DEFAULT ''`)
+		if err != nil {
+			return fmt.Errorf("failed to add auth_events impersonator_id column: %w", err)
+		}
+	}
+
+	// Cleared chat stays restorable until deleted_at is older than the grace period
+	var chatDeletedAtExists int
+	err = s.db.QueryRow(`
+		SELECT COUNT(*)
+		FROM pragma_table_info('chat')
+		WHERE name='deleted_at'
+	`).Scan(&chatDeletedAtExists)
+	if err != nil {
+		return fmt.Errorf("failed to check chat deleted_at column existence: %w", err)
+	}
+
+	if chatDeletedAtExists == 0 {
+		_, err = s.db.Exec(`ALTER TABLE chat ADD COLUMN deleted_at INTEGER`)
+		if err != nil {
+			return fmt.Errorf("failed to add chat deleted_at column: %w", err)
+		}
+	}
+
+	// Back-channel logout finds sessions by the identity provider's sub and sid
+	for _, column := range []string{"idp_subject", "idp_session_id"} {
+		var exists int
+		err = s.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('sessions') WHERE name = ?`, column).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to check sessions %s column existence: %w", column, err)
+		}
+		if exists == 0 {
+			if _, err = s.db.Exec(`ALTER TABLE sessions ADD COLUMN ` + column + ` TEXT NOT NULL DEFAULT ''`); err != nil {
+				return fmt.Errorf("failed to add sessions %s column: %w", column, err)
+			}
+		}
+	}
+	if _, err = s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_sessions_idp ON sessions(idp_subject, idp_session_id)`); err != nil {
+		return fmt.Errorf("failed to create sessions idp index: %w", err)
+	}
+
+	// Unix millisecond timestamps. Existing players and teams are backfilled
+	// with the time of the migration; chat's updated_at stays 0 until a
+	// reaction, meaning "same as ts".
+	backfill := timeMs(timestampNow())
+	for _, column := range []struct{ table, name string }{
+		{"players", "created_at"}, {"players", "updated_at"},
+		{"teams", "created_at"}, {"teams", "updated_at"},
+		{"chat", "updated_at"},
+	} {
+		var exists int
+		err = s.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, column.table, column.name).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to check %s %s column existence: %w", column.table, column.name, err)
+		}
+		if exists != 0 {
+			continue
+		}
+		if _, err = s.db.Exec(`ALTER TABLE ` + column.table + ` ADD COLUMN ` + column.name + ` INTEGER NOT NULL DEFAULT 0`); err != nil {
+			return fmt.Errorf("failed to add %s %s column: %w", column.table, column.name, err)
+		}
+		if column.table != "chat" {
+			if _, err = s.db.Exec(`UPDATE `+column.table+` SET `+column.name+` = ?`, backfill); err != nil {
+				return fmt.Errorf("failed to backfill %s %s: %w", column.table, column.name, err)
+			}
+		}
+	}
+
+	// System messages carry the bot's name; older ones get the current one
+	var chatSenderExists int
+	err = s.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('chat') WHERE name = 'sender'`).Scan(&chatSenderExists)
+	if err != nil {
+		return fmt.Errorf("failed to check chat sender column existence: %w", err)
+	}
+	if chatSenderExists == 0 {
+		if _, err = s.db.Exec(`ALTER TABLE chat ADD COLUMN sender TEXT NOT NULL DEFAULT ''`); err != nil {
+			return fmt.Errorf("failed to add chat sender column: %w", err)
+		}
+		if _, err = s.db.Exec(`UPDATE chat SET sender = ? WHERE type = 'system'`, SystemSender()); err != nil {
+			return fmt.Errorf("failed to backfill chat sender: %w", err)
+		}
+	}
+
+	// System messages are stored as a message key and params; older rows keep raw text
+	var chatMessageExists int
+	err = s.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('chat') WHERE name = 'message'`).Scan(&chatMessageExists)
+	if err != nil {
+		return fmt.Errorf("failed to check chat message column existence: %w", err)
+	}
+	if chatMessageExists == 0 {
+		if _, err = s.db.Exec(`ALTER TABLE chat ADD COLUMN message TEXT`); err != nil {
+			return fmt.Errorf("failed to add chat message column: %w", err)
+		}
+	}
+
+	// Reactions were free text before the emote whitelist
+	err = cleanStoredEmotes(s.db, func(id string, emotes []byte) error {
+		_, err := s.db.Exec(`UPDATE chat SET emotes = ? WHERE id = ?`, string(emotes), id)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clean chat reactions: %w", err)
+	}
+
 	// Seed default data if empty and demo catalog seeding is enabled.
 	var count int
 	if err := s.db.QueryRow("SELECT COUNT(*) FROM players").Scan(&count); err != nil {
@@ -149,16 +373,24 @@ func (s *SQLiteDAL) initSchema() error {
 	}
 
 	if count == 0 && SeedDefaultCatalogEnabled() {
-		if err := s.seedData(); err != nil {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		if err := s.seedData(tx); err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
 			return err
 		}
 	}
 
-	return s.ensureDefaultDraftSettings()
+	return s.ensureDefaultDraftSettings(s.db)
 }
 
-func (s *SQLiteDAL) ensureDefaultDraftSettings() error {
-	_, err := s.db.Exec(`
+func (s *SQLiteDAL) ensureDefaultDraftSettings(e sqlExecer) error {
+	_, err := e.Exec(`
 		INSERT OR IGNORE INTO draft_settings (key, value)
 		VALUES ('mode', ?)
 	`, string(models.DefaultDraftSettings().Mode))
@@ -170,7 +402,7 @@ func (s *SQLiteDAL) getDraftSettings() (models.DraftSettings, error) {
 	var mode string
 	err := s.db.QueryRow(`SELECT value FROM draft_settings WHERE key = 'mode'`).Scan(&mode)
 	if err == sql.ErrNoRows {
-		return models.DefaultDraftSettings(), s.ensureDefaultDraftSettings()
+		return models.DefaultDraftSettings(), s.ensureDefaultDraftSettings(s.db)
 	}
 	if err != nil {
 		return models.DraftSettings{}, err
@@ -192,19 +424,20 @@ func (s *SQLiteDAL) getDraftModeTx(tx *sql.Tx) (models.DraftMode, error) {
 	return models.NormalizeDraftMode(models.DraftMode(mode)), nil
 }
 
-func (s *SQLiteDAL) seedData() error {
+// seedData writes the default catalog inside tx.
+func (s *SQLiteDAL) seedData(tx *sql.Tx) error {
 	if !SeedDefaultCatalogEnabled() {
-		return s.ensureDefaultDraftSettings()
+		return s.ensureDefaultDraftSettings(tx)
 	}
 
 	players := getDefaultPlayers()
 
 	// Insert players
 	for _, p := range players {
-		_, err := s.db.Exec(`
-			INSERT INTO players (id, name, position, team, points, cuddle_points, tier, drafted, drafted_by, image)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		`, p.ID, p.Name, p.Position, p.Team, p.Points, p.CuddlePoints, p.Tier, 0, "", p.Image)
+		_, err := tx.Exec(`
+			INSERT INTO players (id, name, position, team, points, cuddle_points, tier, drafted, drafted_by, image, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, p.ID, p.Name, p.Position, p.Team, p.Points, p.CuddlePoints, p.Tier, 0, "", p.Image, timeMs(p.CreatedAt), timeMs(p.UpdatedAt))
 		if err != nil {
 			return err
 		}
@@ -214,25 +447,28 @@ func (s *SQLiteDAL) seedData() error {
 	if IsDevEnvironment() {
 		teams := getDefaultTeams()
 		for i, t := range teams {
-			_, err := s.db.Exec(`
-				INSERT INTO teams (id, name, owner, mascot, color, display_order)
-				VALUES (?, ?, ?, ?, ?, ?)
-			`, t.ID, t.Name, t.Owner, t.Mascot, t.Color, i)
+			_, err := tx.Exec(`
+				INSERT INTO teams (id, name, owner, mascot, color, display_order, created_at, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			`, t.ID, t.Name, t.Owner, t.Mascot, t.Color, i, timeMs(t.CreatedAt), timeMs(t.UpdatedAt))
 			if err != nil {
 				return err
 			}
 		}
 	}
 
-	s.AddChatMessage("Welcome to the Jellycat Draft!", "system")
-	s.AddChatMessage("Tip: pair your phone with the TV room code before picking.", "system")
-	s.AddChatMessage("Who will snag Bashful Bunny first?", "system")
-
-	if err := s.ensureDefaultDraftSettings(); err != nil {
-		return err
+	for _, key := range welcomeChatKeys {
+		if err := insertChatTx(tx, newSystemChatMessage(key, nil), "?, ?, ?, ?, ?, ?, ?"); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	return s.ensureDefaultDraftSettings(tx)
+}
+
+// Ping checks the database file is still reachable, giving up when ctx is done.
+func (s *SQLiteDAL) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
 }
 
 func (s *SQLiteDAL) GetState() (*models.DraftState, error) {
@@ -243,6 +479,13 @@ func (s *SQLiteDAL) GetState() (*models.DraftState, error) {
 		Settings: models.DefaultDraftSettings(),
 	}
 
+	// Read the version first, so the board is at least as new as it says.
+	version, err := loadVersion(s.db)
+	if err != nil {
+		return nil, err
+	}
+	state.Version = version
+
 	settings, err := s.getDraftSettings()
 	if err != nil {
 		return nil, err
@@ -251,8 +494,8 @@ func (s *SQLiteDAL) GetState() (*models.DraftState, error) {
 
 	// Get players
 	rows, err := s.db.Query(`
-		SELECT id, name, position, team, points, cuddle_points, tier, drafted, drafted_by, image
-		FROM players
+		SELECT id, name, position, team, points, cuddle_points, tier, drafted, drafted_by, image, created_at, updated_at
+		FROM players ORDER BY rowid
 	`)
 	if err != nil {
 		return nil, err
@@ -263,11 +506,13 @@ func (s *SQLiteDAL) GetState() (*models.DraftState, error) {
 		var p models.Player
 		var drafted int
 		var draftedBy sql.NullString
-		err := rows.Scan(&p.ID, &p.Name, &p.Position, &p.Team, &p.Points, &p.CuddlePoints, &p.Tier, &drafted, &draftedBy, &p.Image)
+		var createdAt, updatedAt int64
+		err := rows.Scan(&p.ID, &p.Name, &p.Position, &p.Team, &p.Points, &p.CuddlePoints, &p.Tier, &drafted, &draftedBy, &p.Image, &createdAt, &updatedAt)
 		if err != nil {
 			return nil, err
 		}
 		p.Drafted = drafted == 1
+		p.CreatedAt, p.UpdatedAt = msTime(createdAt), msTime(updatedAt)
 		if draftedBy.Valid {
 			p.DraftedBy = draftedBy.String
 		}
@@ -276,7 +521,7 @@ func (s *SQLiteDAL) GetState() (*models.DraftState, error) {
 
 	// Get teams with their players
 	teamRows, err := s.db.Query(`
-		SELECT id, name, owner, mascot, color
+		SELECT id, name, owner, owner_user_id, mascot, color, created_at, updated_at
 		FROM teams ORDER BY COALESCE(display_order, rowid), rowid
 	`)
 	if err != nil {
@@ -286,10 +531,12 @@ func (s *SQLiteDAL) GetState() (*models.DraftState, error) {
 
 	for teamRows.Next() {
 		var t models.Team
-		err := teamRows.Scan(&t.ID, &t.Name, &t.Owner, &t.Mascot, &t.Color)
+		var createdAt, updatedAt int64
+		err := teamRows.Scan(&t.ID, &t.Name, &t.Owner, &t.OwnerUserID, &t.Mascot, &t.Color, &createdAt, &updatedAt)
 		if err != nil {
 			return nil, err
 		}
+		t.CreatedAt, t.UpdatedAt = msTime(createdAt), msTime(updatedAt)
 		t.Players = []models.Player{}
 
 		// Get team players
@@ -319,66 +566,66 @@ func (s *SQLiteDAL) GetState() (*models.DraftState, error) {
 	}
 
 	// Get chat
-	chatRows, err := s.db.Query(`
-		SELECT id, ts, type, text, emotes
-		FROM chat ORDER BY ts ASC
-	`)
-	if err != nil {
-		return nil, err
-	}
-	defer chatRows.Close()
-
-	for chatRows.Next() {
-		var msg models.ChatMessage
-		var emotesJSON string
-		err := chatRows.Scan(&msg.ID, &msg.TS, &msg.Type, &msg.Text, &emotesJSON)
+	if ChatEnabled() {
+		chatRows, err := s.db.Query(`
+			SELECT id, ts, type, text, sender, emotes, updated_at, message
+			FROM chat WHERE deleted_at IS NULL ORDER BY ts ASC, id ASC
+		`)
 		if err != nil {
 			return nil, err
 		}
-		msg.Emotes = make(map[string]int)
-		json.Unmarshal([]byte(emotesJSON), &msg.Emotes)
-		state.Chat = append(state.Chat, msg)
+		if state.Chat, err = scanChatRows(chatRows); err != nil {
+			return nil, err
+		}
 	}
 
 	// Calculate current pick number and whose turn it is
 	CalculateCurrentPick(state, state.Players)
+	if draft.AuctionEnabled() {
+		auction, err := loadAuction(s.db)
+		if err != nil {
+			return nil, err
+		}
+		auction.Apply(state)
+	}
 
 	return state, nil
 }
 
 func (s *SQLiteDAL) Reset() error {
-	// Clear all tables
-	_, err := s.db.Exec("DELETE FROM team_players")
-	if err != nil {
-		return err
-	}
-	_, err = s.db.Exec("DELETE FROM chat")
+	tx, err := s.db.Begin()
 	if err != nil {
 		return err
 	}
-	_, err = s.db.Exec("DELETE FROM draft_settings")
-	if err != nil {
-		return err
+	defer tx.Rollback()
+
+	// Clear all tables
+	for _, table := range []string{"team_players", "chat_reactions", "chat", "draft_settings", "teams", "players"} {
+		if _, err := tx.Exec("DELETE FROM " + table); err != nil {
+			return err
+		}
 	}
-	_, err = s.db.Exec("DELETE FROM teams")
-	if err != nil {
+
+	// Re-seed
+	if err := s.seedData(tx); err != nil {
 		return err
 	}
-	_, err = s.db.Exec("DELETE FROM players")
-	if err != nil {
+	if err := bumpVersion(tx); err != nil {
 		return err
 	}
-
-	s.reactionUsers = make(map[string]map[string]map[string]bool)
-
-	// Re-seed
-	return s.seedData()
+	return tx.Commit()
 }
 
 func (s *SQLiteDAL) SetDraftMode(mode models.DraftMode) (*models.DraftSettings, error) {
 	settings := models.DraftSettingsForMode(mode)
 
-	_, err := s.db.Exec(`
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
 		INSERT INTO draft_settings (key, value)
 		VALUES ('mode', ?)
 		ON CONFLICT(key) DO UPDATE SET value = excluded.value
@@ -387,16 +634,63 @@ func (s *SQLiteDAL) SetDraftMode(mode models.DraftMode) (*models.DraftSettings,
 		return nil, err
 	}
 
-	if _, err := s.AddChatMessage(fmt.Sprintf("Draft mode set to %s", settings.Name), "system"); err != nil {
+	if err := insertChatTx(tx, draftModeSetChatMessage(settings), "?, ?, ?, ?, ?, ?, ?"); err != nil {
+		return nil, err
+	}
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
 
 	return &settings, nil
 }
 
+func (s *SQLiteDAL) GetDraftWindow() (models.DraftWindow, error) {
+	return loadDraftWindow(s.db)
+}
+
+func (s *SQLiteDAL) SetDraftWindow(window models.DraftWindow) (*models.DraftWindow, error) {
+	window, err := normalizeDraftWindow(window)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for key, bound := range map[string]time.Time{draftWindowOpensKey: window.OpensAt, draftWindowClosesKey: window.ClosesAt} {
+		_, err := tx.Exec(`
+			INSERT INTO draft_settings (key, value)
+			VALUES (?, ?)
+			ON CONFLICT(key) DO UPDATE SET value = excluded.value
+		`, key, encodeWindowBound(bound))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &window, nil
+}
+
 func (s *SQLiteDAL) AddPlayer(player *models.Player) (*models.Player, error) {
+	name, err := sanitizeName("player", player.Name)
+	if err != nil {
+		return nil, err
+	}
+	player.Name = name
 	if player.ID == "" {
-		player.ID = genID("player")
+		player.ID = ids.New("player")
 	}
 
 	// Assign random cuddle points if not already set
@@ -408,16 +702,38 @@ func (s *SQLiteDAL) AddPlayer(player *models.Player) (*models.Player, error) {
 	if player.Drafted {
 		drafted = 1
 	}
+	player.CreatedAt = timestampNow()
+	player.UpdatedAt = player.CreatedAt
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return player, err
+	}
+	defer tx.Rollback()
 
-	_, err := s.db.Exec(`
-		INSERT INTO players (id, name, position, team, points, cuddle_points, tier, drafted, drafted_by, image)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, player.ID, player.Name, player.Position, player.Team, player.Points, player.CuddlePoints, player.Tier, drafted, player.DraftedBy, player.Image)
+	_, err = tx.Exec(`
+		INSERT INTO players (id, name, position, team, points, cuddle_points, tier, drafted, drafted_by, image, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, player.ID, player.Name, player.Position, player.Team, player.Points, player.CuddlePoints, player.Tier, drafted, player.DraftedBy, player.Image, timeMs(player.CreatedAt), timeMs(player.UpdatedAt))
+	if err != nil {
+		return player, err
+	}
+	if err := bumpVersion(tx); err != nil {
+		return player, err
+	}
+	if err := tx.Commit(); err != nil {
+		return player, err
+	}
 
-	return player, err
+	return player, nil
 }
 
 func (s *SQLiteDAL) UpdatePlayer(player *models.Player) (*models.Player, error) {
+	name, err := sanitizeName("player", player.Name)
+	if err != nil {
+		return nil, err
+	}
+	player.Name = name
 	// Cap cuddle points at 100
 	if player.CuddlePoints > 100 {
 		player.CuddlePoints = 100
@@ -426,13 +742,19 @@ func (s *SQLiteDAL) UpdatePlayer(player *models.Player) (*models.Player, error)
 		player.CuddlePoints = 0
 	}
 
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
 	// Check if player exists and get current data
 	var drafted int
 	var currentPoints int
-	err := s.db.QueryRow(`SELECT drafted, points FROM players WHERE id = ?`, player.ID).Scan(&drafted, &currentPoints)
+	err = tx.QueryRow(`SELECT drafted, points FROM players WHERE id = ?`, player.ID).Scan(&drafted, &currentPoints)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("player not found")
+			return nil, ErrPlayerNotFound
 		}
 		return nil, err
 	}
@@ -443,33 +765,23 @@ func (s *SQLiteDAL) UpdatePlayer(player *models.Player) (*models.Player, error)
 		pointsToUpdate = currentPoints
 	}
 
-	_, err = s.db.Exec(`
+	_, err = tx.Exec(`
 		UPDATE players 
-		SET name = ?, position = ?, team = ?, points = ?, cuddle_points = ?, tier = ?, image = ?
+		SET name = ?, position = ?, team = ?, points = ?, cuddle_points = ?, tier = ?, image = ?, updated_at = ?
 		WHERE id = ?
-	`, player.Name, player.Position, player.Team, pointsToUpdate, player.CuddlePoints, player.Tier, player.Image, player.ID)
+	`, player.Name, player.Position, player.Team, pointsToUpdate, player.CuddlePoints, player.Tier, player.Image, timeMs(timestampNow()), player.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Also update in team_players if player is drafted
-	if drafted == 1 {
-		player.Points = pointsToUpdate
-		playerJSON, _ := json.Marshal(player)
-		_, err = s.db.Exec(`
-			UPDATE team_players 
-			SET player_data = ?
-			WHERE player_id = ?
-		`, string(playerJSON), player.ID)
-	}
-
 	// Get updated player
 	var p models.Player
 	var draftedBy sql.NullString
-	err = s.db.QueryRow(`
-		SELECT id, name, position, team, points, cuddle_points, tier, drafted, drafted_by, image
+	var createdAt, updatedAt int64
+	err = tx.QueryRow(`
+		SELECT id, name, position, team, points, cuddle_points, tier, drafted, drafted_by, image, created_at, updated_at
 		FROM players WHERE id = ?
-	`, player.ID).Scan(&p.ID, &p.Name, &p.Position, &p.Team, &p.Points, &p.CuddlePoints, &p.Tier, &drafted, &draftedBy, &p.Image)
+	`, player.ID).Scan(&p.ID, &p.Name, &p.Position, &p.Team, &p.Points, &p.CuddlePoints, &p.Tier, &drafted, &draftedBy, &p.Image, &createdAt, &updatedAt)
 
 	if err != nil {
 		return nil, err
@@ -479,59 +791,110 @@ func (s *SQLiteDAL) UpdatePlayer(player *models.Player) (*models.Player, error)
 	if draftedBy.Valid {
 		p.DraftedBy = draftedBy.String
 	}
+	p.CreatedAt, p.UpdatedAt = msTime(createdAt), msTime(updatedAt)
+
+	// Also update in team_players if player is drafted
+	if drafted == 1 {
+		player.Points = pointsToUpdate
+		player.CreatedAt, player.UpdatedAt = p.CreatedAt, p.UpdatedAt
+		playerJSON, _ := json.Marshal(player)
+		_, err = tx.Exec(`
+			UPDATE team_players 
+			SET player_data = ?
+			WHERE player_id = ?
+		`, string(playerJSON), player.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 
 	return &p, nil
 }
 
 func (s *SQLiteDAL) DeletePlayer(id string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	// Check if player exists and is not drafted
 	var drafted int
-	err := s.db.QueryRow(`SELECT drafted FROM players WHERE id = ?`, id).Scan(&drafted)
+	err = tx.QueryRow(`SELECT drafted FROM players WHERE id = ?`, id).Scan(&drafted)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return fmt.Errorf("player not found")
+			return ErrPlayerNotFound
 		}
 		return err
 	}
 
 	if drafted == 1 {
-		return fmt.Errorf("cannot delete a drafted player")
+		return ErrDraftedPlayerDelete
 	}
 
-	_, err = s.db.Exec(`DELETE FROM players WHERE id = ?`, id)
-	return err
+	if _, err := tx.Exec(`DELETE FROM players WHERE id = ?`, id); err != nil {
+		return err
+	}
+	if err := bumpVersion(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (s *SQLiteDAL) SetPlayerPoints(id string, points int) (*models.Player, error) {
-	_, err := s.db.Exec(`UPDATE players SET points = ? WHERE id = ?`, points, id)
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := timestampNow()
+	_, err = tx.Exec(`UPDATE players SET points = ?, updated_at = ? WHERE id = ?`, points, timeMs(now), id)
 	if err != nil {
 		return nil, err
 	}
 
 	// Also update in team_players
-	_, err = s.db.Exec(`
+	_, err = tx.Exec(`
 		UPDATE team_players 
-		SET player_data = json_set(player_data, '$.points', ?) 
+		SET player_data = json_set(player_data, '$.points', ?, '$.updatedAt', ?) 
 		WHERE json_extract(player_data, '$.id') = ?
-	`, points, id)
+	`, points, now.Format(time.RFC3339Nano), id)
+	if err != nil {
+		return nil, err
+	}
 
 	// Get updated player
 	var p models.Player
 	var drafted int
 	var draftedBy sql.NullString
-	err = s.db.QueryRow(`
-		SELECT id, name, position, team, points, cuddle_points, tier, drafted, drafted_by, image
+	var createdAt, updatedAt int64
+	err = tx.QueryRow(`
+		SELECT id, name, position, team, points, cuddle_points, tier, drafted, drafted_by, image, created_at, updated_at
 		FROM players WHERE id = ?
-	`, id).Scan(&p.ID, &p.Name, &p.Position, &p.Team, &p.Points, &p.CuddlePoints, &p.Tier, &drafted, &draftedBy, &p.Image)
+	`, id).Scan(&p.ID, &p.Name, &p.Position, &p.Team, &p.Points, &p.CuddlePoints, &p.Tier, &drafted, &draftedBy, &p.Image, &createdAt, &updatedAt)
 
 	if err != nil {
-		return nil, err
+		return nil, noRows(err, ErrPlayerNotFound)
 	}
 
 	p.Drafted = drafted == 1
+	p.CreatedAt, p.UpdatedAt = msTime(createdAt), msTime(updatedAt)
 	if draftedBy.Valid {
 		p.DraftedBy = draftedBy.String
 	}
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 
 	return &p, nil
 }
@@ -543,12 +906,23 @@ func (s *SQLiteDAL) ReorderTeams(order []string) ([]models.Team, error) {
 	}
 	defer func() { _ = tx.Rollback() }()
 
+	teamIDs, err := queryTeamIDs(tx.Query(`SELECT id FROM teams`))
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTeamOrder(order, teamIDs); err != nil {
+		return nil, err
+	}
+
 	for index, id := range order {
 		if _, err := tx.Exec(`UPDATE teams SET display_order = ? WHERE id = ?`, index, id); err != nil {
 			return nil, err
 		}
 	}
 
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
 	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
@@ -562,15 +936,42 @@ func (s *SQLiteDAL) ReorderTeams(order []string) ([]models.Team, error) {
 }
 
 func (s *SQLiteDAL) DraftPlayer(playerID, teamID string) error {
+	if draft.AuctionEnabled() {
+		return ErrAuctionPick
+	}
+
+	s.pickMu.Lock()
+	defer s.pickMu.Unlock()
+
 	tx, err := s.db.Begin()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	// Get the current draft pick number (count of already drafted players + 1)
+	if err := s.draftPlayerTx(tx, playerID, teamID, true); err != nil {
+		return err
+	}
+	if err := bumpVersion(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// draftPlayerTx adds playerID to teamID's roster. Auction awards skip the
+// turn check, since the high bidder wins whoever's turn it is.
+func (s *SQLiteDAL) draftPlayerTx(tx *sql.Tx, playerID, teamID string, checkTurn bool) error {
+	window, err := loadDraftWindow(tx)
+	if err != nil {
+		return err
+	}
+	if err := checkDraftWindow(window, time.Now()); err != nil {
+		return err
+	}
+
+	// The pick after the highest one taken
 	var draftPickNumber int
-	err = tx.QueryRow(`SELECT COUNT(*) + 1 FROM team_players`).Scan(&draftPickNumber)
+	err = tx.QueryRow(`SELECT COALESCE(MAX(draft_pick_number), 0) + 1 FROM team_players`).Scan(&draftPickNumber)
 	if err != nil {
 		return err
 	}
@@ -578,17 +979,21 @@ func (s *SQLiteDAL) DraftPlayer(playerID, teamID string) error {
 	// Get player including cuddle_points
 	var p models.Player
 	var drafted int
+	var createdAt int64
 	err = tx.QueryRow(`
-		SELECT id, name, position, team, points, cuddle_points, tier, drafted, image
+		SELECT id, name, position, team, points, cuddle_points, tier, drafted, image, created_at
 		FROM players WHERE id = ?
-	`, playerID).Scan(&p.ID, &p.Name, &p.Position, &p.Team, &p.Points, &p.CuddlePoints, &p.Tier, &drafted, &p.Image)
+	`, playerID).Scan(&p.ID, &p.Name, &p.Position, &p.Team, &p.Points, &p.CuddlePoints, &p.Tier, &drafted, &p.Image, &createdAt)
 
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPlayerNotFound
+	}
 	if err != nil {
 		return err
 	}
 
 	if drafted == 1 {
-		return fmt.Errorf("player already drafted")
+		return ErrPlayerAlreadyDrafted
 	}
 
 	// Get team
@@ -597,52 +1002,49 @@ func (s *SQLiteDAL) DraftPlayer(playerID, teamID string) error {
 		SELECT name, mascot FROM teams WHERE id = ?
 	`, teamID).Scan(&teamName, &teamMascot)
 
-	if err != nil {
-		return err
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTeamNotFound
 	}
-
-	mode, err := s.getDraftModeTx(tx)
 	if err != nil {
 		return err
 	}
-	teams, err := sqliteTeamsForTurn(tx)
-	if err != nil {
-		return err
+
+	if checkTurn {
+		mode, err := s.getDraftModeTx(tx)
+		if err != nil {
+			return err
+		}
+		teams, err := sqliteTeamsForTurn(tx)
+		if err != nil {
+			return err
+		}
+		players, err := sqlitePlayersForTurn(tx)
+		if err != nil {
+			return err
+		}
+		if err := validateTeamTurn(teams, mode, players, teamID); err != nil {
+			return err
+		}
 	}
-	players, err := sqlitePlayersForTurn(tx)
-	if err != nil {
+	var rosterSize int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM team_players WHERE team_id = ?`, teamID).Scan(&rosterSize); err != nil {
 		return err
 	}
-	if err := validateTeamTurn(teams, mode, players, teamID); err != nil {
+	if err := checkRosterSize(teamName, rosterSize); err != nil {
 		return err
 	}
 
+	preDraftPoints, preDraftCuddlePoints := p.Points, p.CuddlePoints
 	p = personalizePlayerForTeam(p, models.Team{ID: teamID, Name: teamName})
 
-	// Calculate cuddle points adjustment based on draft position
-	// Early picks (1-6) gain points, late picks (13-18) lose points
-	cuddlePointsAdjustment := 0
-	if draftPickNumber <= 6 {
-		// Early picks gain 8-18 points (pick 1 gets +18, pick 6 gets +8)
-		cuddlePointsAdjustment = 20 - (draftPickNumber * 2)
-	} else if draftPickNumber >= 13 {
-		// Late picks lose 5-10 points (pick 13 loses -5, pick 18 loses -10)
-		cuddlePointsAdjustment = 8 - draftPickNumber
-	}
-
-	newCuddlePoints := p.CuddlePoints + cuddlePointsAdjustment
-	// Ensure cuddle points stay within reasonable bounds (min 10, max 100)
-	if newCuddlePoints < 10 {
-		newCuddlePoints = 10
-	}
-	if newCuddlePoints > 100 {
-		newCuddlePoints = 100
-	}
+	// Early picks gain cuddle points, late picks lose them
+	newCuddlePoints := draftedCuddlePoints(p.CuddlePoints, draftPickNumber)
 
 	// Update player as drafted with adjusted cuddle points
+	p.CreatedAt, p.UpdatedAt = msTime(createdAt), timestampNow()
 	_, err = tx.Exec(`
-		UPDATE players SET drafted = 1, drafted_by = ?, points = ?, cuddle_points = ? WHERE id = ?
-	`, teamName, p.Points, newCuddlePoints, playerID)
+		UPDATE players SET drafted = 1, drafted_by = ?, points = ?, cuddle_points = ?, updated_at = ? WHERE id = ?
+	`, teamName, p.Points, newCuddlePoints, timeMs(p.UpdatedAt), playerID)
 	if err != nil {
 		return err
 	}
@@ -658,30 +1060,28 @@ func (s *SQLiteDAL) DraftPlayer(playerID, teamID string) error {
 
 	// Add player to team with draft pick number
 	_, err = tx.Exec(`
-		INSERT INTO team_players (team_id, player_id, player_data, draft_pick_number)
-		VALUES (?, ?, ?, ?)
-	`, teamID, playerID, string(playerJSON), draftPickNumber)
+		INSERT INTO team_players (team_id, player_id, player_data, draft_pick_number, pre_draft_points, pre_draft_cuddle_points)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, teamID, playerID, string(playerJSON), draftPickNumber, preDraftPoints, preDraftCuddlePoints)
 	if err != nil {
 		return err
 	}
 
 	// Add chat message
-	msg := fmt.Sprintf("%s %s drafted %s (%s • %s)", teamMascot, teamName, p.Name, p.Team, p.Position)
-	emotesJSON, _ := json.Marshal(map[string]int{})
-	_, err = tx.Exec(`
-		INSERT INTO chat (id, ts, type, text, emotes)
-		VALUES (?, ?, ?, ?, ?)
-	`, genID("msg"), time.Now().UnixMilli(), "system", msg, string(emotesJSON))
-	if err != nil {
-		return err
+	if !ChatEnabled() {
+		return nil
 	}
-
-	return tx.Commit()
+	msg := pickChatMessage(teamMascot, teamName, p)
+	_, err = tx.Exec(`
+		INSERT INTO chat (id, ts, type, text, sender, emotes, message)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, msg.ID, msg.TS, msg.Type, msg.Text, msg.Sender, "{}", chatMessageColumn(msg))
+	return err
 }
 
 func sqliteTeamsForTurn(tx *sql.Tx) ([]models.Team, error) {
 	rows, err := tx.Query(`
-		SELECT id, name, owner, mascot, color
+		SELECT id, name, owner, owner_user_id, mascot, color
 		FROM teams
 		ORDER BY COALESCE(display_order, rowid), rowid
 	`)
@@ -693,7 +1093,7 @@ func sqliteTeamsForTurn(tx *sql.Tx) ([]models.Team, error) {
 	teams := []models.Team{}
 	for rows.Next() {
 		var team models.Team
-		if err := rows.Scan(&team.ID, &team.Name, &team.Owner, &team.Mascot, &team.Color); err != nil {
+		if err := rows.Scan(&team.ID, &team.Name, &team.Owner, &team.OwnerUserID, &team.Mascot, &team.Color); err != nil {
 			return nil, err
 		}
 		team.Players = []models.Player{}
@@ -728,135 +1128,179 @@ func sqlitePlayersForTurn(tx *sql.Tx) ([]models.Player, error) {
 }
 
 func (s *SQLiteDAL) AddChatMessage(text, msgType string) (*models.ChatMessage, error) {
-	msg := &models.ChatMessage{
-		ID:     genID("msg"),
-		TS:     time.Now().UnixMilli(),
-		Type:   msgType,
-		Text:   text,
-		Emotes: make(map[string]int),
+	if !ChatEnabled() {
+		return nil, ErrChatDisabled
+	}
+	msg, err := newChatMessage(text, msgType)
+	if err != nil {
+		return nil, err
 	}
 
-	emotesJSON, _ := json.Marshal(msg.Emotes)
-	_, err := s.db.Exec(`
-		INSERT INTO chat (id, ts, type, text, emotes)
-		VALUES (?, ?, ?, ?, ?)
-	`, msg.ID, msg.TS, msg.Type, msg.Text, string(emotesJSON))
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 
-	return msg, err
+	if err := insertChatTx(tx, msg, "?, ?, ?, ?, ?, ?, ?"); err != nil {
+		return nil, err
+	}
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return msg, nil
 }
 
 func (s *SQLiteDAL) AddReaction(messageID, emote, userID string) (*models.ChatMessage, error) {
+	if !ChatEnabled() {
+		return nil, ErrChatDisabled
+	}
+	emote, err := validateEmote(emote)
+	if err != nil {
+		return nil, err
+	}
 	uid := userID
 	if uid == "" {
 		uid = "anon"
 	}
 
-	// Check if user already reacted
-	if s.reactionUsers[messageID] == nil {
-		s.reactionUsers[messageID] = make(map[string]map[string]bool)
-	}
-	if s.reactionUsers[messageID][emote] == nil {
-		s.reactionUsers[messageID][emote] = make(map[string]bool)
-	}
-
-	if s.reactionUsers[messageID][emote][uid] {
-		// Already reacted, just return current message
-		var msg models.ChatMessage
-		var emotesJSON string
-		err := s.db.QueryRow(`
-			SELECT id, ts, type, text, emotes FROM chat WHERE id = ?
-		`, messageID).Scan(&msg.ID, &msg.TS, &msg.Type, &msg.Text, &emotesJSON)
-		if err != nil {
-			return nil, err
-		}
-		json.Unmarshal([]byte(emotesJSON), &msg.Emotes)
-		return &msg, nil
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
 	}
-
-	s.reactionUsers[messageID][emote][uid] = true
+	defer tx.Rollback()
 
 	// Get current emotes
 	var emotesJSON string
-	err := s.db.QueryRow(`SELECT emotes FROM chat WHERE id = ?`, messageID).Scan(&emotesJSON)
+	err = tx.QueryRow(`SELECT emotes FROM chat WHERE id = ?`, messageID).Scan(&emotesJSON)
 	if err != nil {
-		return nil, err
+		return nil, noRows(err, ErrMessageNotFound)
 	}
 
-	emotes := make(map[string]int)
-	json.Unmarshal([]byte(emotesJSON), &emotes)
-	emotes[emote]++
-
-	newEmotesJSON, _ := json.Marshal(emotes)
-	_, err = s.db.Exec(`UPDATE chat SET emotes = ? WHERE id = ?`, string(newEmotesJSON), messageID)
+	// Record who reacted; a repeat by the same user changes nothing
+	result, err := tx.Exec(`
+		INSERT OR IGNORE INTO chat_reactions (message_id, emote, user_id, created_at) VALUES (?, ?, ?, ?)
+	`, messageID, emote, uid, timeMs(timestampNow()))
 	if err != nil {
 		return nil, err
 	}
+	if affected, _ := result.RowsAffected(); affected > 0 {
+		emotes := make(map[string]int)
+		json.Unmarshal([]byte(emotesJSON), &emotes)
+		emotes[emote]++
+
+		newEmotesJSON, _ := json.Marshal(emotes)
+		_, err = tx.Exec(`UPDATE chat SET emotes = ?, updated_at = ? WHERE id = ?`, string(newEmotesJSON), timeMs(timestampNow()), messageID)
+		if err != nil {
+			return nil, err
+		}
+		if err := bumpVersion(tx); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 
-	// Return updated message
+	// Return the message as it now stands
 	var msg models.ChatMessage
+	var message []byte
+	var updatedAt int64
 	err = s.db.QueryRow(`
-		SELECT id, ts, type, text, emotes FROM chat WHERE id = ?
-	`, messageID).Scan(&msg.ID, &msg.TS, &msg.Type, &msg.Text, &emotesJSON)
+		SELECT id, ts, type, text, sender, emotes, updated_at, message FROM chat WHERE id = ?
+	`, messageID).Scan(&msg.ID, &msg.TS, &msg.Type, &msg.Text, &msg.Sender, &emotesJSON, &updatedAt, &message)
 	if err != nil {
-		return nil, err
+		return nil, noRows(err, ErrMessageNotFound)
 	}
 	json.Unmarshal([]byte(emotesJSON), &msg.Emotes)
+	stampChatMessage(&msg, updatedAt)
+	applyChatMessageColumn(&msg, message)
 
 	return &msg, nil
 }
 
 func (s *SQLiteDAL) AddTeam(name, owner, mascot, color string) (*models.Team, error) {
-	mascots := []string{"🦊", "🐻", "🐰", "🐱", "🐑", "🦒", "🐨", "🦁", "🐼", "🦄", "🐯", "🐶"}
-	colors := []string{
-		"bg-orange-100 border-orange-300",
-		"bg-amber-100 border-amber-300",
-		"bg-pink-100 border-pink-300",
-		"bg-purple-100 border-purple-300",
-		"bg-blue-100 border-blue-300",
-		"bg-yellow-100 border-yellow-300",
-		"bg-green-100 border-green-300",
+	name, err := sanitizeName("team", name)
+	if err != nil {
+		return nil, err
+	}
+	s.ownerMu.Lock()
+	defer s.ownerMu.Unlock()
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := checkUniqueOwnerSQL(tx, "", "", owner); err != nil {
+		return nil, err
 	}
 
 	// Count existing teams for default mascot/color
 	var count int
-	s.db.QueryRow("SELECT COUNT(*) FROM teams").Scan(&count)
+	tx.QueryRow("SELECT COUNT(*) FROM teams").Scan(&count)
 
-	if mascot == "" {
-		mascot = mascots[count%len(mascots)]
-	}
-	if color == "" {
-		color = colors[count%len(colors)]
+	// New teams go after the current last team, matching MemoryDAL's append.
+	var nextOrder int
+	if err := tx.QueryRow("SELECT COALESCE(MAX(display_order) + 1, 0) FROM teams").Scan(&nextOrder); err != nil {
+		return nil, err
 	}
 
+	mascot, color = teamDefaults(count, mascot, color)
+
+	now := timestampNow()
 	team := &models.Team{
-		ID:      genID("team"),
-		Name:    name,
-		Owner:   owner,
-		Mascot:  mascot,
-		Color:   color,
-		Players: []models.Player{},
+		ID:        ids.New("team"),
+		Name:      name,
+		Owner:     owner,
+		Mascot:    mascot,
+		Color:     color,
+		Players:   []models.Player{},
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 
-	_, err := s.db.Exec(`
-		INSERT INTO teams (id, name, owner, mascot, color, display_order)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`, team.ID, team.Name, team.Owner, team.Mascot, team.Color, count)
+	_, err = tx.Exec(`
+		INSERT INTO teams (id, name, owner, mascot, color, display_order, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, team.ID, team.Name, team.Owner, team.Mascot, team.Color, nextOrder, timeMs(now), timeMs(now))
 
 	if err != nil {
 		return nil, err
 	}
 
-	ownerLabel := team.Owner
-	if ownerLabel == "" {
-		ownerLabel = "Unassigned"
+	if err := insertChatTx(tx, teamJoinedChatMessage(team), "?, ?, ?, ?, ?, ?, ?"); err != nil {
+		return nil, err
+	}
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
 	}
-	msg := fmt.Sprintf("New team joined the draft: %s %s (Owner: %s)", team.Mascot, team.Name, ownerLabel)
-	s.AddChatMessage(msg, "system")
 
 	return team, nil
 }
 
 func (s *SQLiteDAL) UpdateTeam(id, name, owner, mascot, color string) (*models.Team, error) {
+	name, err := sanitizeName("team", name)
+	if err != nil {
+		return nil, err
+	}
+	s.ownerMu.Lock()
+	defer s.ownerMu.Unlock()
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := checkUniqueOwnerSQL(tx, id, "", owner); err != nil {
+		return nil, err
+	}
 	// Build the UPDATE query dynamically based on non-empty fields
 	query := "UPDATE teams SET "
 	args := []interface{}{}
@@ -866,8 +1310,9 @@ func (s *SQLiteDAL) UpdateTeam(id, name, owner, mascot, color string) (*models.T
 		updates = append(updates, "name = ?")
 		args = append(args, name)
 	}
-	updates = append(updates, "owner = ?")
-	args = append(args, owner)
+	// A changed owner string no longer identifies the claimed user.
+	updates = append(updates, "owner_user_id = CASE WHEN owner = ? THEN owner_user_id ELSE '' END", "owner = ?")
+	args = append(args, owner, owner)
 	if mascot != "" {
 		updates = append(updates, "mascot = ?")
 		args = append(args, mascot)
@@ -878,59 +1323,146 @@ func (s *SQLiteDAL) UpdateTeam(id, name, owner, mascot, color string) (*models.T
 	}
 
 	if len(updates) == 0 {
-		return nil, fmt.Errorf("no fields to update")
+		return nil, validationErrorf("no fields to update")
 	}
+	updates = append(updates, "updated_at = ?")
+	args = append(args, timeMs(timestampNow()))
 
 	query += strings.Join(updates, ", ")
 	query += " WHERE id = ?"
 	args = append(args, id)
 
-	result, err := s.db.Exec(query, args...)
+	result, err := tx.Exec(query, args...)
 	if err != nil {
 		return nil, err
 	}
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return nil, fmt.Errorf("team not found")
+		return nil, ErrTeamNotFound
+	}
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
 	}
 
 	// Fetch and return the updated team
+	return s.getTeam(id)
+}
+
+func (s *SQLiteDAL) getTeam(id string) (*models.Team, error) {
 	var team models.Team
-	err = s.db.QueryRow(`
-		SELECT id, name, owner, mascot, color
+	var createdAt, updatedAt int64
+	err := s.db.QueryRow(`
+		SELECT id, name, owner, owner_user_id, mascot, color, created_at, updated_at
 		FROM teams WHERE id = ?
-	`, id).Scan(&team.ID, &team.Name, &team.Owner, &team.Mascot, &team.Color)
+	`, id).Scan(&team.ID, &team.Name, &team.Owner, &team.OwnerUserID, &team.Mascot, &team.Color, &createdAt, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTeamNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 	team.Players = []models.Player{}
+	team.CreatedAt, team.UpdatedAt = msTime(createdAt), msTime(updatedAt)
 
 	return &team, nil
 }
 
+func (s *SQLiteDAL) ClaimTeam(teamID, userID, owner string) (*models.Team, error) {
+	s.ownerMu.Lock()
+	defer s.ownerMu.Unlock()
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := checkUniqueOwnerSQL(tx, teamID, userID, owner); err != nil {
+		return nil, err
+	}
+	result, err := tx.Exec(`
+		UPDATE teams SET owner_user_id = ?, owner = ?, updated_at = ?
+		WHERE id = ? AND (owner_user_id = ? OR (owner_user_id = '' AND (owner = '' OR owner = ?)))
+	`, userID, owner, timeMs(timestampNow()), teamID, userID, owner)
+	if err != nil {
+		return nil, err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		if _, err := s.getTeam(teamID); err != nil {
+			return nil, err
+		}
+		return nil, ErrTeamAlreadyClaimed
+	}
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.getTeam(teamID)
+}
+
+func (s *SQLiteDAL) AssignTeamOwner(teamID, userID, owner string) (*models.Team, error) {
+	s.ownerMu.Lock()
+	defer s.ownerMu.Unlock()
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := checkUniqueOwnerSQL(tx, teamID, userID, owner); err != nil {
+		return nil, err
+	}
+	result, err := tx.Exec(`UPDATE teams SET owner_user_id = ?, owner = ?, updated_at = ? WHERE id = ?`, userID, owner, timeMs(timestampNow()), teamID)
+	if err != nil {
+		return nil, err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, ErrTeamNotFound
+	}
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.getTeam(teamID)
+}
+
 func (s *SQLiteDAL) DeleteTeam(id string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	// Check if team has drafted players
 	var count int
-	err := s.db.QueryRow(`
+	err = tx.QueryRow(`
 		SELECT COUNT(*) FROM players WHERE drafted_by = ?
 	`, id).Scan(&count)
 	if err != nil {
 		return err
 	}
 	if count > 0 {
-		return fmt.Errorf("cannot delete a team that has drafted players")
+		return ErrTeamHasDraftedPlayers
 	}
 
-	result, err := s.db.Exec("DELETE FROM teams WHERE id = ?", id)
+	result, err := tx.Exec("DELETE FROM teams WHERE id = ?", id)
 	if err != nil {
 		return err
 	}
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("team not found")
+		return ErrTeamNotFound
 	}
-
-	return nil
+	if err := bumpVersion(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
@@ -0,0 +1,51 @@
+package dal
+
+import "github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+
+func (m *MemoryDAL) Stats() (models.DALStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := models.DALStats{Players: len(m.players), Teams: len(m.teams), Chat: len(m.chat)}
+	for _, player := range m.players {
+		if player.Drafted {
+			stats.Drafted++
+		}
+	}
+	for _, msg := range m.chat {
+		for _, count := range msg.Emotes {
+			stats.Reactions += count
+		}
+	}
+	return stats, nil
+}
+
+func (s *SQLiteDAL) Stats() (models.DALStats, error) {
+	return statsSQL(s.db)
+}
+
+func (p *PostgresDAL) Stats() (models.DALStats, error) {
+	return statsSQL(p.db)
+}
+
+func statsSQL(q sqlQueryer) (models.DALStats, error) {
+	var stats models.DALStats
+	counts := []struct {
+		into  *int
+		query string
+	}{
+		{&stats.Players, `SELECT COUNT(*) FROM players`},
+		{&stats.Drafted, `SELECT COUNT(*) FROM players WHERE drafted`},
+		{&stats.Teams, `SELECT COUNT(*) FROM teams`},
+		{&stats.Chat, `SELECT COUNT(*) FROM chat WHERE deleted_at IS NULL`},
+		{&stats.Reactions, `SELECT COUNT(*) FROM chat_reactions r JOIN chat c ON c.id = r.message_id WHERE c.deleted_at IS NULL`},
+	}
+	for _, count := range counts {
+		n, err := queryCount(q, count.query)
+		if err != nil {
+			return models.DALStats{}, err
+		}
+		*count.into = n
+	}
+	return stats, nil
+}
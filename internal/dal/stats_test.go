@@ -0,0 +1,60 @@
+package dal
+
+import (
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// assertStats checks Stats against a store seeded with the default catalog,
+// after a pick, a chat message and a reaction.
+func assertStats(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	team, err := store.AddTeam("Stats Foxes", "", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	if err := store.DraftPlayer("1", team.ID); err != nil {
+		t.Fatalf("DraftPlayer() failed: %v", err)
+	}
+	msg, err := store.AddChatMessage("Nice pick", "user")
+	if err != nil {
+		t.Fatalf("AddChatMessage() failed: %v", err)
+	}
+	if _, err := store.AddReaction(msg.ID, "👍", "u1"); err != nil {
+		t.Fatalf("AddReaction() failed: %v", err)
+	}
+	if _, err := store.AddReaction(msg.ID, "👍", "u2"); err != nil {
+		t.Fatalf("AddReaction() failed: %v", err)
+	}
+
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	stats, err := store.Stats()
+	if err != nil {
+		t.Fatalf("Stats() failed: %v", err)
+	}
+	want := models.DALStats{Players: len(getDefaultPlayers()), Drafted: 1, Teams: 1, Chat: len(state.Chat), Reactions: 2}
+	if stats != want {
+		t.Fatalf("Stats() = %+v, want %+v", stats, want)
+	}
+}
+
+func TestMemoryStats(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("JELLYCAT_SEED_DEFAULT_CATALOG", "true")
+	assertStats(t, NewMemoryDAL())
+}
+
+func TestSQLiteStats(t *testing.T) {
+	t.Setenv("JELLYCAT_SEED_DEFAULT_CATALOG", "true")
+	assertStats(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresStats(t *testing.T) {
+	t.Setenv("JELLYCAT_SEED_DEFAULT_CATALOG", "true")
+	assertStats(t, newTestPostgresDAL(t))
+}
@@ -0,0 +1,85 @@
+package dal
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// suggestPick picks the best undrafted player for teamID: the one with the
+// most points at a position the team hasn't filled to its PositionLimits
+// target, or the best overall once every position is filled. Ties go to
+// cuddle points, then to the player added first.
+func suggestPick(state *models.DraftState, teamID string) (*models.Player, error) {
+	var team *models.Team
+	for i := range state.Teams {
+		if state.Teams[i].ID == teamID {
+			team = &state.Teams[i]
+			break
+		}
+	}
+	if team == nil {
+		return nil, ErrTeamNotFound
+	}
+	if err := checkRosterSize(team.Name, len(team.Players)); err != nil {
+		return nil, err
+	}
+
+	filled := map[string]int{}
+	for _, player := range team.Players {
+		filled[player.Position]++
+	}
+	limits := PositionLimits()
+	needed := func(position string) bool {
+		limit, ok := limits[position]
+		if len(limits) == 0 {
+			limit, ok = 1, true
+		}
+		return ok && filled[position] < limit
+	}
+
+	available := make([]models.Player, 0, len(state.Players))
+	for _, player := range state.Players {
+		if !player.Drafted {
+			available = append(available, player)
+		}
+	}
+	if len(available) == 0 {
+		return nil, ErrNoPlayersLeft
+	}
+	slices.SortStableFunc(available, func(a, b models.Player) int {
+		return cmp.Or(cmp.Compare(b.Points, a.Points), cmp.Compare(b.CuddlePoints, a.CuddlePoints))
+	})
+
+	for i := range available {
+		if needed(available[i].Position) {
+			return &available[i], nil
+		}
+	}
+	return &available[0], nil
+}
+
+func (m *MemoryDAL) SuggestPick(teamID string) (*models.Player, error) {
+	state, err := m.GetState()
+	if err != nil {
+		return nil, err
+	}
+	return suggestPick(state, teamID)
+}
+
+func (s *SQLiteDAL) SuggestPick(teamID string) (*models.Player, error) {
+	state, err := s.GetState()
+	if err != nil {
+		return nil, err
+	}
+	return suggestPick(state, teamID)
+}
+
+func (p *PostgresDAL) SuggestPick(teamID string) (*models.Player, error) {
+	state, err := p.GetState()
+	if err != nil {
+		return nil, err
+	}
+	return suggestPick(state, teamID)
+}
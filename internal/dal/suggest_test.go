@@ -0,0 +1,83 @@
+package dal
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// assertSuggestPick drafts the best CC for a lone team and checks that the
+// suggestion moves on to the SS it still needs, follows POSITION_LIMITS, and
+// falls back to the best player overall once every position is filled.
+func assertSuggestPick(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	team, err := store.AddTeam("Suggest Foxes", "", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	add := func(name, position string, points int) *models.Player {
+		t.Helper()
+		player, err := store.AddPlayer(&models.Player{Name: name, Position: position, Team: "Test", Points: points, Tier: models.TierB})
+		if err != nil {
+			t.Fatalf("AddPlayer(%s) failed: %v", name, err)
+		}
+		return player
+	}
+	topCC := add("Top Bunny", "CC", 300)
+	nextCC := add("Next Bunny", "CC", 250)
+	bestSS := add("Best Lion", "SS", 200)
+
+	suggest := func(want *models.Player) {
+		t.Helper()
+		got, err := store.SuggestPick(team.ID)
+		if err != nil {
+			t.Fatalf("SuggestPick() failed: %v", err)
+		}
+		if got.ID != want.ID {
+			t.Fatalf("SuggestPick() = %s, want %s", got.Name, want.Name)
+		}
+	}
+
+	suggest(topCC)
+	if err := store.DraftPlayer(topCC.ID, team.ID); err != nil {
+		t.Fatalf("DraftPlayer() failed: %v", err)
+	}
+
+	// One CC is filled, so the SS beats the higher-scoring CC
+	suggest(bestSS)
+
+	t.Setenv("POSITION_LIMITS", "CC:2,SS:1")
+	suggest(nextCC)
+
+	// Every limited position is filled: best overall
+	t.Setenv("POSITION_LIMITS", "CC:1")
+	suggest(nextCC)
+
+	if _, err := store.SuggestPick("no-such-team"); !errors.Is(err, ErrTeamNotFound) {
+		t.Fatalf("SuggestPick(unknown team) error = %v, want ErrTeamNotFound", err)
+	}
+
+	for _, player := range []*models.Player{nextCC, bestSS} {
+		if err := store.DraftPlayer(player.ID, team.ID); err != nil {
+			t.Fatalf("DraftPlayer() failed: %v", err)
+		}
+	}
+	if _, err := store.SuggestPick(team.ID); !errors.Is(err, ErrNoPlayersLeft) {
+		t.Fatalf("SuggestPick() with everyone drafted error = %v, want ErrNoPlayersLeft", err)
+	}
+}
+
+func TestMemorySuggestPick(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertSuggestPick(t, NewMemoryDAL())
+}
+
+func TestSQLiteSuggestPick(t *testing.T) {
+	assertSuggestPick(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresSuggestPick(t *testing.T) {
+	assertSuggestPick(t, newTestPostgresDAL(t))
+}
@@ -0,0 +1,270 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/draft"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// draftedPick is one side of a swap: the pick's number, the team that made
+// it and the player, with the points the player had before the pick.
+type draftedPick struct {
+	number               int
+	team                 models.Team
+	player               models.Player
+	preDraftPoints       int
+	preDraftCuddlePoints int
+}
+
+// redraft returns from's player as if it had been drafted at to's pick by
+// to's team: its points are personalized for the team and its cuddle points
+// adjusted for the pick number, both starting from its pre-draft values.
+func redraft(from, to draftedPick) models.Player {
+	player := from.player
+	player.Points, player.CuddlePoints = from.preDraftPoints, from.preDraftCuddlePoints
+	player = personalizePlayerForTeam(player, to.team)
+	player.CuddlePoints = draftedCuddlePoints(player.CuddlePoints, to.number)
+	player.Drafted = true
+	player.DraftedBy = to.team.Name
+	player.UpdatedAt = timestampNow()
+	return player
+}
+
+func checkSwap(pickA, pickB int) error {
+	if draft.AuctionEnabled() {
+		return ErrAuctionPick
+	}
+	if pickA == pickB {
+		return validationErrorf("cannot swap pick %d with itself", pickA)
+	}
+	return nil
+}
+
+// errNoPreDraftPoints rejects swapping a pick recorded before the pre-draft
+// points were stored, since its points can't be recomputed.
+func errNoPreDraftPoints(number int) error {
+	return validationErrorf("pick %d predates recorded pre-draft points and can't be swapped", number)
+}
+
+// Memory
+
+func (m *MemoryDAL) SwapPicks(pickA, pickB int) error {
+	if err := checkSwap(pickA, pickB); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a, err := m.draftedPickUnsafe(pickA)
+	if err != nil {
+		return err
+	}
+	b, err := m.draftedPickUnsafe(pickB)
+	if err != nil {
+		return err
+	}
+
+	*m.findPlayerUnsafe(a.player.ID) = redraft(a, b)
+	*m.findPlayerUnsafe(b.player.ID) = redraft(b, a)
+	first, second := m.picks[pickA-1], m.picks[pickB-1]
+	m.picks[pickA-1] = memoryPick{playerID: second.playerID, teamID: first.teamID, points: second.points, cuddlePoints: second.cuddlePoints}
+	m.picks[pickB-1] = memoryPick{playerID: first.playerID, teamID: second.teamID, points: first.points, cuddlePoints: first.cuddlePoints}
+
+	// Rebuild both rosters in pick order
+	for _, teamID := range []string{first.teamID, second.teamID} {
+		team := m.findTeamUnsafe(teamID)
+		roster := []models.Player{}
+		for _, pick := range m.picks {
+			if pick.teamID == teamID {
+				roster = append(roster, *m.findPlayerUnsafe(pick.playerID))
+			}
+		}
+		team.Players = roster
+	}
+
+	m.addSystemChatUnsafe(picksSwappedChatMessage(a, b))
+	m.version++
+	return nil
+}
+
+func (m *MemoryDAL) draftedPickUnsafe(number int) (draftedPick, error) {
+	if number < 1 || number > len(m.picks) {
+		return draftedPick{}, ErrPickNotFound
+	}
+	pick := m.picks[number-1]
+	player, team := m.findPlayerUnsafe(pick.playerID), m.findTeamUnsafe(pick.teamID)
+	if player == nil || team == nil {
+		return draftedPick{}, ErrPickNotFound
+	}
+	return draftedPick{
+		number:               number,
+		team:                 *team,
+		player:               *player,
+		preDraftPoints:       pick.points,
+		preDraftCuddlePoints: pick.cuddlePoints,
+	}, nil
+}
+
+// SQLite
+
+func (s *SQLiteDAL) SwapPicks(pickA, pickB int) error {
+	if err := checkSwap(pickA, pickB); err != nil {
+		return err
+	}
+
+	s.pickMu.Lock()
+	defer s.pickMu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	a, err := sqliteDraftedPick(tx, pickA)
+	if err != nil {
+		return err
+	}
+	b, err := sqliteDraftedPick(tx, pickB)
+	if err != nil {
+		return err
+	}
+	for _, move := range []struct{ from, to draftedPick }{{a, b}, {b, a}} {
+		player := redraft(move.from, move.to)
+		playerJSON, err := json.Marshal(player)
+		if err != nil {
+			return fmt.Errorf("failed to marshal player data: %w", err)
+		}
+		if _, err := tx.Exec(`
+			UPDATE team_players SET team_id = ?, draft_pick_number = ?, player_data = ? WHERE player_id = ?
+		`, move.to.team.ID, move.to.number, string(playerJSON), player.ID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`
+			UPDATE players SET drafted_by = ?, points = ?, cuddle_points = ?, updated_at = ? WHERE id = ?
+		`, player.DraftedBy, player.Points, player.CuddlePoints, timeMs(player.UpdatedAt), player.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := insertChatTx(tx, picksSwappedChatMessage(a, b), "?, ?, ?, ?, ?, ?, ?"); err != nil {
+		return err
+	}
+
+	if err := bumpVersion(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func sqliteDraftedPick(tx *sql.Tx, number int) (draftedPick, error) {
+	pick := draftedPick{number: number}
+	var playerJSON string
+	var preDraftPoints, preDraftCuddlePoints sql.NullInt64
+	err := tx.QueryRow(`
+		SELECT t.id, t.name, tp.player_data, tp.pre_draft_points, tp.pre_draft_cuddle_points
+		FROM team_players tp JOIN teams t ON t.id = tp.team_id
+		WHERE tp.draft_pick_number = ?
+	`, number).Scan(&pick.team.ID, &pick.team.Name, &playerJSON, &preDraftPoints, &preDraftCuddlePoints)
+	if err != nil {
+		return pick, noRows(err, ErrPickNotFound)
+	}
+	if !preDraftPoints.Valid || !preDraftCuddlePoints.Valid {
+		return pick, errNoPreDraftPoints(number)
+	}
+	if err := json.Unmarshal([]byte(playerJSON), &pick.player); err != nil {
+		return pick, err
+	}
+	pick.preDraftPoints, pick.preDraftCuddlePoints = int(preDraftPoints.Int64), int(preDraftCuddlePoints.Int64)
+	return pick, nil
+}
+
+// Postgres
+
+func (p *PostgresDAL) SwapPicks(pickA, pickB int) error {
+	if err := checkSwap(pickA, pickB); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockPickCounter(ctx, tx); err != nil {
+		return err
+	}
+	a, err := postgresDraftedPick(ctx, tx, pickA)
+	if err != nil {
+		return err
+	}
+	b, err := postgresDraftedPick(ctx, tx, pickB)
+	if err != nil {
+		return err
+	}
+	for _, move := range []struct{ from, to draftedPick }{{a, b}, {b, a}} {
+		player := redraft(move.from, move.to)
+		playerJSON, err := json.Marshal(player)
+		if err != nil {
+			return fmt.Errorf("failed to marshal player data: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE team_players SET team_id = $1, draft_pick_number = $2, player_data = $3 WHERE player_id = $4
+		`, move.to.team.ID, move.to.number, playerJSON, player.ID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE players SET drafted_by = $1, points = $2, cuddle_points = $3, updated_at = $4 WHERE id = $5
+		`, player.DraftedBy, player.Points, player.CuddlePoints, player.UpdatedAt, player.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := insertChatTx(tx, picksSwappedChatMessage(a, b), "$1, $2, $3, $4, $5, $6, $7"); err != nil {
+		return err
+	}
+
+	if err := bumpVersion(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func postgresDraftedPick(ctx context.Context, tx *sql.Tx, number int) (draftedPick, error) {
+	pick := draftedPick{number: number}
+	var playerJSON []byte
+	var preDraftPoints, preDraftCuddlePoints sql.NullInt64
+	err := tx.QueryRowContext(ctx, `
+		SELECT t.id, t.name, tp.player_data, tp.pre_draft_points, tp.pre_draft_cuddle_points
+		FROM team_players tp JOIN teams t ON t.id = tp.team_id
+		WHERE tp.draft_pick_number = $1
+		FOR UPDATE OF tp
+	`, number).Scan(&pick.team.ID, &pick.team.Name, &playerJSON, &preDraftPoints, &preDraftCuddlePoints)
+	if err != nil {
+		return pick, noRows(err, ErrPickNotFound)
+	}
+	if !preDraftPoints.Valid || !preDraftCuddlePoints.Valid {
+		return pick, errNoPreDraftPoints(number)
+	}
+	if err := json.Unmarshal(playerJSON, &pick.player); err != nil {
+		return pick, err
+	}
+	pick.preDraftPoints, pick.preDraftCuddlePoints = int(preDraftPoints.Int64), int(preDraftCuddlePoints.Int64)
+	return pick, nil
+}
@@ -0,0 +1,133 @@
+package dal
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+func assertSwapPicks(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	var teams []*models.Team
+	for _, name := range []string{"Swap Alpha", "Swap Bravo", "Swap Charlie"} {
+		team, err := store.AddTeam(name, name, "", "")
+		if err != nil {
+			t.Fatalf("AddTeam(%s) failed: %v", name, err)
+		}
+		teams = append(teams, team)
+	}
+	var players []*models.Player
+	for i := 0; i < 18; i++ {
+		player, err := store.AddPlayer(&models.Player{Name: fmt.Sprintf("Swap %02d", i+1), Position: "CC", Team: "Test", Points: 100 + i, CuddlePoints: 50, Tier: models.TierB})
+		if err != nil {
+			t.Fatalf("AddPlayer() failed: %v", err)
+		}
+		players = append(players, player)
+	}
+	teamIDs := map[int]string{}
+	for i, player := range players {
+		state, err := store.GetState()
+		if err != nil {
+			t.Fatalf("GetState() failed: %v", err)
+		}
+		if err := store.DraftPlayer(player.ID, state.CurrentTeamID); err != nil {
+			t.Fatalf("DraftPlayer(%s) failed: %v", player.Name, err)
+		}
+		teamIDs[i+1] = state.CurrentTeamID
+	}
+	teamsByID := map[string]*models.Team{}
+	for _, team := range teams {
+		teamsByID[team.ID] = team
+	}
+
+	if err := store.SwapPicks(1, 1); !errors.Is(err, ErrValidation) {
+		t.Fatalf("SwapPicks(1, 1) error = %v, want %v", err, ErrValidation)
+	}
+	if err := store.SwapPicks(1, 19); !errors.Is(err, ErrPickNotFound) {
+		t.Fatalf("SwapPicks(1, 19) error = %v, want %v", err, ErrPickNotFound)
+	}
+
+	cuddlePoints := func(id string) int {
+		t.Helper()
+		state, err := store.GetState()
+		if err != nil {
+			t.Fatalf("GetState() failed: %v", err)
+		}
+		for _, player := range state.Players {
+			if player.ID == id {
+				return player.CuddlePoints
+			}
+		}
+		t.Fatalf("player %s missing from state", id)
+		return 0
+	}
+	// adjustment is how far player's cuddle points sit from their personalized
+	// pre-draft value for the team holding pick
+	adjustment := func(player *models.Player, pick int) int {
+		team := teamsByID[teamIDs[pick]]
+		return cuddlePoints(player.ID) - personalizePlayerForTeam(*player, models.Team{ID: team.ID, Name: team.Name}).CuddlePoints
+	}
+	firstAdjustment, lastAdjustment := adjustment(players[0], 1), adjustment(players[17], 18)
+	if firstAdjustment != draftedCuddlePoints(50, 1)-50 || lastAdjustment != draftedCuddlePoints(50, 18)-50 {
+		t.Fatalf("adjustments before swap = %+d and %+d, want %+d and %+d", firstAdjustment, lastAdjustment, draftedCuddlePoints(50, 1)-50, draftedCuddlePoints(50, 18)-50)
+	}
+
+	if err := store.SwapPicks(1, 18); err != nil {
+		t.Fatalf("SwapPicks(1, 18) failed: %v", err)
+	}
+
+	if got := adjustment(players[17], 1); got != firstAdjustment {
+		t.Fatalf("%s adjustment at pick 1 = %+d, want %+d", players[17].Name, got, firstAdjustment)
+	}
+	if got := adjustment(players[0], 18); got != lastAdjustment {
+		t.Fatalf("%s adjustment at pick 18 = %+d, want %+d", players[0].Name, got, lastAdjustment)
+	}
+
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	for _, player := range state.Players {
+		if player.ID == players[0].ID && player.DraftedBy != teamsByID[teamIDs[18]].Name {
+			t.Fatalf("%s drafted by %q, want the team that made pick 18", player.Name, player.DraftedBy)
+		}
+		if player.ID == players[17].ID && player.DraftedBy != teamsByID[teamIDs[1]].Name {
+			t.Fatalf("%s drafted by %q, want the team that made pick 1", player.Name, player.DraftedBy)
+		}
+	}
+	for _, team := range state.Teams {
+		for _, player := range team.Players {
+			if player.ID == players[0].ID && team.ID != teamIDs[18] {
+				t.Fatalf("%s is on %s, want the team that made pick 18", player.Name, team.Name)
+			}
+			if player.ID == players[17].ID && team.ID != teamIDs[1] {
+				t.Fatalf("%s is on %s, want the team that made pick 1", player.Name, team.Name)
+			}
+		}
+	}
+
+	// Undo still walks back the draft order: pick 18 is now the first player
+	restored, err := store.UndoLastPick()
+	if err != nil {
+		t.Fatalf("UndoLastPick() failed: %v", err)
+	}
+	if restored.ID != players[0].ID || restored.Points != players[0].Points || restored.CuddlePoints != players[0].CuddlePoints {
+		t.Fatalf("UndoLastPick() after swap = %+v, want %s restored to %d/%d", restored, players[0].Name, players[0].Points, players[0].CuddlePoints)
+	}
+}
+
+func TestMemorySwapPicks(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertSwapPicks(t, NewMemoryDAL())
+}
+
+func TestSQLiteSwapPicks(t *testing.T) {
+	assertSwapPicks(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresSwapPicks(t *testing.T) {
+	assertSwapPicks(t, newTestPostgresDAL(t))
+}
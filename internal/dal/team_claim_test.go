@@ -0,0 +1,211 @@
+package dal
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func assertTeamClaiming(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	team, err := store.AddTeam("Legacy Team", "Sarah", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+
+	if _, err := store.ClaimTeam(team.ID, "user-mike", "Mike"); !errors.Is(err, ErrTeamAlreadyClaimed) {
+		t.Fatalf("ClaimTeam(other owner string) error = %v, want %v", err, ErrTeamAlreadyClaimed)
+	}
+	claimed, err := store.ClaimTeam(team.ID, "user-sarah", "Sarah")
+	if err != nil {
+		t.Fatalf("ClaimTeam(matching owner string) failed: %v", err)
+	}
+	if claimed.OwnerUserID != "user-sarah" {
+		t.Fatalf("OwnerUserID = %q, want %q", claimed.OwnerUserID, "user-sarah")
+	}
+	if _, err := store.ClaimTeam(team.ID, "user-other", "Sarah"); !errors.Is(err, ErrTeamAlreadyClaimed) {
+		t.Fatalf("ClaimTeam(bound team) error = %v, want %v", err, ErrTeamAlreadyClaimed)
+	}
+	if _, err := store.ClaimTeam("missing-team", "user-sarah", "Sarah"); !errors.Is(err, ErrTeamNotFound) {
+		t.Fatalf("ClaimTeam(missing team) error = %v, want %v", err, ErrTeamNotFound)
+	}
+
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	if len(state.Teams) != 1 || state.Teams[0].OwnerUserID != "user-sarah" {
+		t.Fatalf("GetState() teams = %+v, want claimed team", state.Teams)
+	}
+
+	renamed, err := store.UpdateTeam(team.ID, "", "Someone Else", "", "")
+	if err != nil {
+		t.Fatalf("UpdateTeam() failed: %v", err)
+	}
+	if renamed.OwnerUserID != "" {
+		t.Fatalf("OwnerUserID after owner change = %q, want empty", renamed.OwnerUserID)
+	}
+
+	assigned, err := store.AssignTeamOwner(team.ID, "user-casey", "Casey")
+	if err != nil {
+		t.Fatalf("AssignTeamOwner() failed: %v", err)
+	}
+	if assigned.OwnerUserID != "user-casey" || assigned.Owner != "Casey" {
+		t.Fatalf("AssignTeamOwner() = %q/%q, want user-casey/Casey", assigned.OwnerUserID, assigned.Owner)
+	}
+	unassigned, err := store.AssignTeamOwner(team.ID, "", "")
+	if err != nil {
+		t.Fatalf("AssignTeamOwner(unassign) failed: %v", err)
+	}
+	if unassigned.OwnerUserID != "" || unassigned.Owner != "" {
+		t.Fatalf("AssignTeamOwner(unassign) = %q/%q, want empty", unassigned.OwnerUserID, unassigned.Owner)
+	}
+}
+
+func TestMemoryTeamClaiming(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertTeamClaiming(t, NewMemoryDAL())
+}
+
+func TestSQLiteTeamClaiming(t *testing.T) {
+	assertTeamClaiming(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresTeamClaiming(t *testing.T) {
+	assertTeamClaiming(t, newTestPostgresDAL(t))
+}
+
+func assertUniqueOwners(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	t.Setenv("UNIQUE_OWNERS", "")
+	if _, err := store.AddTeam("First", "sam", "", ""); err != nil {
+		t.Fatalf("AddTeam(first) failed: %v", err)
+	}
+	if _, err := store.AddTeam("Second", "sam", "", ""); err != nil {
+		t.Fatalf("AddTeam(second) without UNIQUE_OWNERS failed: %v", err)
+	}
+
+	t.Setenv("UNIQUE_OWNERS", "true")
+	if _, err := store.AddTeam("Third", "sam", "", ""); !errors.Is(err, ErrOwnerHasTeam) || !errors.Is(err, ErrConflict) {
+		t.Fatalf("AddTeam(duplicate owner) error = %v, want ErrOwnerHasTeam", err)
+	}
+	kims, err := store.AddTeam("Kim's", "kim", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam(new owner) failed: %v", err)
+	}
+	ownerless, err := store.AddTeam("Ownerless", "", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam(no owner) failed: %v", err)
+	}
+
+	// Every other path that sets an owner checks too
+	if _, err := store.UpdateTeam(ownerless.ID, "", "kim", "", ""); !errors.Is(err, ErrOwnerHasTeam) {
+		t.Fatalf("UpdateTeam(duplicate owner) error = %v, want ErrOwnerHasTeam", err)
+	}
+	if _, err := store.UpdateTeam(kims.ID, "Kim's Bunnies", "kim", "", ""); err != nil {
+		t.Fatalf("UpdateTeam(own team) failed: %v", err)
+	}
+	if _, err := store.ClaimTeam(ownerless.ID, "user-kim", "kim"); !errors.Is(err, ErrOwnerHasTeam) {
+		t.Fatalf("ClaimTeam(duplicate owner) error = %v, want ErrOwnerHasTeam", err)
+	}
+	if _, err := store.ClaimTeam(kims.ID, "user-kim", "kim"); err != nil {
+		t.Fatalf("ClaimTeam(own team) failed: %v", err)
+	}
+	if _, err := store.ClaimTeam(ownerless.ID, "user-kim", "Kimberly"); !errors.Is(err, ErrOwnerHasTeam) {
+		t.Fatalf("ClaimTeam(user with a team) error = %v, want ErrOwnerHasTeam", err)
+	}
+	if _, err := store.AssignTeamOwner(ownerless.ID, "user-kim", "kim"); !errors.Is(err, ErrOwnerHasTeam) {
+		t.Fatalf("AssignTeamOwner(duplicate owner) error = %v, want ErrOwnerHasTeam", err)
+	}
+	if _, err := store.AssignTeamOwner(ownerless.ID, "user-lee", "lee"); err != nil {
+		t.Fatalf("AssignTeamOwner(new owner) failed: %v", err)
+	}
+	if _, err := store.AssignTeamOwner(ownerless.ID, "", ""); err != nil {
+		t.Fatalf("AssignTeamOwner(unassign) failed: %v", err)
+	}
+}
+
+func TestMemoryUniqueOwners(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertUniqueOwners(t, NewMemoryDAL())
+}
+
+func TestSQLiteUniqueOwners(t *testing.T) {
+	assertUniqueOwners(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresUniqueOwners(t *testing.T) {
+	assertUniqueOwners(t, newTestPostgresDAL(t))
+}
+
+// assertConcurrentClaimsKeepOwnersUnique has one user claim several teams at
+// once with UNIQUE_OWNERS on and checks exactly one claim wins.
+func assertConcurrentClaimsKeepOwnersUnique(t *testing.T, store DraftDAL) {
+	t.Helper()
+	t.Setenv("UNIQUE_OWNERS", "true")
+
+	const teams = 8
+	var teamIDs []string
+	for i := 0; i < teams; i++ {
+		team, err := store.AddTeam("Open", "", "", "")
+		if err != nil {
+			t.Fatalf("AddTeam() failed: %v", err)
+		}
+		teamIDs = append(teamIDs, team.ID)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, teams)
+	for _, teamID := range teamIDs {
+		wg.Add(1)
+		go func(teamID string) {
+			defer wg.Done()
+			_, err := store.ClaimTeam(teamID, "user-racer", "racer")
+			errs <- err
+		}(teamID)
+	}
+	wg.Wait()
+	close(errs)
+
+	won := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			won++
+		case !errors.Is(err, ErrOwnerHasTeam):
+			t.Fatalf("ClaimTeam() error = %v, want ErrOwnerHasTeam for the losing claims", err)
+		}
+	}
+	if won != 1 {
+		t.Fatalf("%d concurrent claims succeeded, want 1", won)
+	}
+
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	owned := 0
+	for _, team := range state.Teams {
+		if team.OwnerUserID == "user-racer" || team.Owner == "racer" {
+			owned++
+		}
+	}
+	if owned != 1 {
+		t.Fatalf("user owns %d teams after concurrent claims, want 1", owned)
+	}
+}
+
+func TestMemoryConcurrentClaimsKeepOwnersUnique(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertConcurrentClaimsKeepOwnersUnique(t, NewMemoryDAL())
+}
+
+func TestSQLiteConcurrentClaimsKeepOwnersUnique(t *testing.T) {
+	assertConcurrentClaimsKeepOwnersUnique(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresConcurrentClaimsKeepOwnersUnique(t *testing.T) {
+	assertConcurrentClaimsKeepOwnersUnique(t, newTestPostgresDAL(t))
+}
@@ -0,0 +1,47 @@
+package dal
+
+import (
+	"os"
+	"strings"
+)
+
+var defaultTeamMascots = []string{"🦊", "🐻", "🐰", "🐱", "🐑", "🦒", "🐨", "🦁", "🐼", "🦄", "🐯", "🐶"}
+
+var defaultTeamColors = []string{
+	"bg-orange-100 border-orange-300",
+	"bg-amber-100 border-amber-300",
+	"bg-pink-100 border-pink-300",
+	"bg-purple-100 border-purple-300",
+	"bg-blue-100 border-blue-300",
+	"bg-yellow-100 border-yellow-300",
+	"bg-green-100 border-green-300",
+}
+
+// TeamMascots returns the mascots handed out to new teams that don't pick
+// one: the comma-separated TEAM_MASCOTS list, or the default animals when it
+// is unset or empty.
+func TeamMascots() []string {
+	var mascots []string
+	for _, mascot := range strings.Split(os.Getenv("TEAM_MASCOTS"), ",") {
+		if mascot = strings.TrimSpace(mascot); mascot != "" {
+			mascots = append(mascots, mascot)
+		}
+	}
+	if len(mascots) == 0 {
+		return defaultTeamMascots
+	}
+	return mascots
+}
+
+// teamDefaults fills in a blank mascot or color for the team joining at
+// position index, cycling through the mascot and color lists.
+func teamDefaults(index int, mascot, color string) (string, string) {
+	if mascot == "" {
+		mascots := TeamMascots()
+		mascot = mascots[index%len(mascots)]
+	}
+	if color == "" {
+		color = defaultTeamColors[index%len(defaultTeamColors)]
+	}
+	return mascot, color
+}
@@ -0,0 +1,55 @@
+package dal
+
+import "testing"
+
+// assertCustomMascots adds teams without a mascot and checks they cycle
+// through TEAM_MASCOTS, while an explicit mascot is kept.
+func assertCustomMascots(t *testing.T, store DraftDAL) {
+	t.Helper()
+	t.Setenv("TEAM_MASCOTS", "🐉, 🦖 ,,🐢")
+
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	existing := len(state.Teams)
+	custom := []string{"🐉", "🦖", "🐢"}
+
+	for i := 0; i < 4; i++ {
+		team, err := store.AddTeam("Themed", "", "", "")
+		if err != nil {
+			t.Fatalf("AddTeam() failed: %v", err)
+		}
+		if want := custom[(existing+i)%len(custom)]; team.Mascot != want {
+			t.Fatalf("team %d mascot = %q, want %q", existing+i, team.Mascot, want)
+		}
+	}
+
+	team, err := store.AddTeam("Chosen", "", "🐙", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	if team.Mascot != "🐙" {
+		t.Fatalf("explicit mascot = %q, want 🐙", team.Mascot)
+	}
+}
+
+func TestMemoryCustomMascots(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertCustomMascots(t, NewMemoryDAL())
+}
+
+func TestSQLiteCustomMascots(t *testing.T) {
+	assertCustomMascots(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresCustomMascots(t *testing.T) {
+	assertCustomMascots(t, newTestPostgresDAL(t))
+}
+
+func TestTeamMascotsFallsBackToDefaults(t *testing.T) {
+	t.Setenv("TEAM_MASCOTS", " , ")
+	if got := TeamMascots(); len(got) != len(defaultTeamMascots) || got[0] != "🦊" {
+		t.Fatalf("TeamMascots() = %v, want the defaults", got)
+	}
+}
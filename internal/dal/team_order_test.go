@@ -1,9 +1,13 @@
 package dal
 
 import (
+	"errors"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/i18n"
 	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
 )
 
@@ -51,3 +55,234 @@ func TestSQLiteDraftPlayerUsesPersistedTeamOrder(t *testing.T) {
 		t.Fatalf("DraftPlayer() should allow reordered first team: %v", err)
 	}
 }
+
+func assertReorderTeamsValidation(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	var teamIDs []string
+	for _, name := range []string{"First", "Second", "Third"} {
+		team, err := store.AddTeam(name, name, "", "")
+		if err != nil {
+			t.Fatalf("AddTeam(%s) failed: %v", name, err)
+		}
+		teamIDs = append(teamIDs, team.ID)
+	}
+
+	invalid := map[string][]string{
+		"partial":   {teamIDs[2], teamIDs[0]},
+		"duplicate": {teamIDs[2], teamIDs[2], teamIDs[0], teamIDs[1]},
+		"unknown":   {teamIDs[2], teamIDs[1], teamIDs[0], "missing-team"},
+		"empty":     {},
+	}
+	for name, order := range invalid {
+		if _, err := store.ReorderTeams(order); !errors.Is(err, ErrValidation) {
+			t.Fatalf("ReorderTeams(%s) error = %v, want %v", name, err, ErrValidation)
+		}
+	}
+
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	for i, team := range state.Teams {
+		if team.ID != teamIDs[i] {
+			t.Fatalf("rejected reorder changed team %d to %q, want %q", i, team.ID, teamIDs[i])
+		}
+	}
+
+	permutation := []string{teamIDs[2], teamIDs[0], teamIDs[1]}
+	teams, err := store.ReorderTeams(permutation)
+	if err != nil {
+		t.Fatalf("ReorderTeams(permutation) failed: %v", err)
+	}
+	if len(teams) != len(permutation) {
+		t.Fatalf("ReorderTeams() returned %d teams, want %d", len(teams), len(permutation))
+	}
+	for i, team := range teams {
+		if team.ID != permutation[i] {
+			t.Fatalf("team %d = %q, want %q", i, team.ID, permutation[i])
+		}
+	}
+}
+
+func TestMemoryReorderTeamsValidation(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertReorderTeamsValidation(t, NewMemoryDAL())
+}
+
+func TestSQLiteReorderTeamsValidation(t *testing.T) {
+	assertReorderTeamsValidation(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresReorderTeamsValidation(t *testing.T) {
+	assertReorderTeamsValidation(t, newTestPostgresDAL(t))
+}
+
+// reorderTeamNames applies the same reorder to store and returns team names
+// as ReorderTeams and a later GetState report them.
+func reorderTeamNames(t *testing.T, store DraftDAL) (reordered, persisted []string) {
+	t.Helper()
+
+	ids := map[string]string{}
+	for _, name := range []string{"Alpha", "Bravo", "Charlie"} {
+		team, err := store.AddTeam(name, name, "", "")
+		if err != nil {
+			t.Fatalf("AddTeam(%s) failed: %v", name, err)
+		}
+		ids[name] = team.ID
+	}
+
+	teams, err := store.ReorderTeams([]string{ids["Charlie"], ids["Alpha"], ids["Bravo"]})
+	if err != nil {
+		t.Fatalf("ReorderTeams() failed: %v", err)
+	}
+	for _, team := range teams {
+		reordered = append(reordered, team.Name)
+	}
+
+	// Teams added after a reorder go to the end, even once earlier teams are deleted.
+	for _, name := range []string{"Charlie", "Alpha"} {
+		if err := store.DeleteTeam(ids[name]); err != nil {
+			t.Fatalf("DeleteTeam(%s) failed: %v", name, err)
+		}
+	}
+	if _, err := store.AddTeam("Delta", "Delta", "", ""); err != nil {
+		t.Fatalf("AddTeam(Delta) failed: %v", err)
+	}
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	for _, team := range state.Teams {
+		persisted = append(persisted, team.Name)
+	}
+	return reordered, persisted
+}
+
+func TestReorderTeamsMatchesAcrossBackends(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+
+	backends := map[string]func(*testing.T) DraftDAL{
+		"memory":   func(*testing.T) DraftDAL { return NewMemoryDAL() },
+		"sqlite":   func(t *testing.T) DraftDAL { return newTestSQLiteDAL(t) },
+		"postgres": func(t *testing.T) DraftDAL { return newTestPostgresDAL(t) },
+	}
+	wantReordered := []string{"Charlie", "Alpha", "Bravo"}
+	wantPersisted := []string{"Bravo", "Delta"}
+
+	for name, newStore := range backends {
+		t.Run(name, func(t *testing.T) {
+			reordered, persisted := reorderTeamNames(t, newStore(t))
+			if !slices.Equal(reordered, wantReordered) {
+				t.Fatalf("ReorderTeams() = %v, want %v", reordered, wantReordered)
+			}
+			if !slices.Equal(persisted, wantPersisted) {
+				t.Fatalf("GetState() teams = %v, want %v", persisted, wantPersisted)
+			}
+		})
+	}
+}
+
+func TestSQLiteReorderTeamsSurvivesReopen(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+
+	path := filepath.Join(t.TempDir(), "draft.sqlite")
+	store, err := NewSQLiteDAL(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteDAL() failed: %v", err)
+	}
+	_, want := reorderTeamNames(t, store)
+	store.db.Close()
+
+	reopened, err := NewSQLiteDAL(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteDAL(reopen) failed: %v", err)
+	}
+	state, err := reopened.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	var got []string
+	for _, team := range state.Teams {
+		got = append(got, team.Name)
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("teams after reopen = %v, want %v", got, want)
+	}
+}
+
+// assertRandomizeTeamOrder checks that a randomized order is a permutation
+// of every team, persisted and announced in chat.
+func assertRandomizeTeamOrder(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	var want []string
+	for _, name := range []string{"Foxes", "Bears", "Bunnies", "Cats", "Sheep", "Giraffes"} {
+		team, err := store.AddTeam(name, "", "", "")
+		if err != nil {
+			t.Fatalf("AddTeam() failed: %v", err)
+		}
+		want = append(want, team.ID)
+	}
+	slices.Sort(want)
+
+	for range 3 {
+		teams, err := store.RandomizeTeamOrder()
+		if err != nil {
+			t.Fatalf("RandomizeTeamOrder() failed: %v", err)
+		}
+		got := make([]string, len(teams))
+		for i, team := range teams {
+			got[i] = team.ID
+		}
+
+		state, err := store.GetState()
+		if err != nil {
+			t.Fatalf("GetState() failed: %v", err)
+		}
+		stored := make([]string, len(state.Teams))
+		for i, team := range state.Teams {
+			stored[i] = team.ID
+		}
+		if !slices.Equal(stored, got) {
+			t.Fatalf("stored order %v, want the returned %v", stored, got)
+		}
+
+		slices.Sort(got)
+		if !slices.Equal(got, want) {
+			t.Fatalf("RandomizeTeamOrder() teams %v are not a permutation of %v", got, want)
+		}
+
+		last := state.Chat[len(state.Chat)-1]
+		if last.Key != i18n.KeyOrderRandomized || !strings.HasPrefix(last.Params["order"], "1. "+teams[0].Mascot+" "+teams[0].Name+", 2. ") {
+			t.Fatalf("last chat message = %q (key %q), want the new order", last.Text, last.Key)
+		}
+	}
+}
+
+func TestMemoryRandomizeTeamOrder(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertRandomizeTeamOrder(t, NewMemoryDAL())
+}
+
+func TestSQLiteRandomizeTeamOrder(t *testing.T) {
+	assertRandomizeTeamOrder(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresRandomizeTeamOrder(t *testing.T) {
+	assertRandomizeTeamOrder(t, newTestPostgresDAL(t))
+}
+
+func TestShuffleTeamsReachesEveryOrder(t *testing.T) {
+	seen := map[string]bool{}
+	for range 200 {
+		teams := []models.Team{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+		if err := shuffleTeams(teams); err != nil {
+			t.Fatalf("shuffleTeams() failed: %v", err)
+		}
+		seen[teams[0].ID+teams[1].ID+teams[2].ID] = true
+	}
+	if len(seen) != 6 {
+		t.Fatalf("200 shuffles of 3 teams gave %d of the 6 orders: %v", len(seen), seen)
+	}
+}
@@ -0,0 +1,95 @@
+package dal
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+func (m *MemoryDAL) GetTeamPlayers(teamID string) ([]models.Player, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var team *models.Team
+	for i := range m.teams {
+		if m.teams[i].ID == teamID {
+			team = &m.teams[i]
+			break
+		}
+	}
+	if team == nil {
+		return nil, ErrTeamNotFound
+	}
+
+	pickNumbers := make(map[string]int, len(m.picks))
+	for i, pick := range m.picks {
+		pickNumbers[pick.playerID] = i + 1
+	}
+	players := make([]models.Player, len(team.Players))
+	for i, player := range team.Players {
+		player.PickNumber = pickNumbers[player.ID]
+		players[i] = player
+	}
+	return players, nil
+}
+
+func (s *SQLiteDAL) GetTeamPlayers(teamID string) ([]models.Player, error) {
+	var exists int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM teams WHERE id = ?`, teamID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if exists == 0 {
+		return nil, ErrTeamNotFound
+	}
+
+	rows, err := s.db.Query(`
+		SELECT player_data, COALESCE(draft_pick_number, 0)
+		FROM team_players WHERE team_id = ? ORDER BY draft_pick_number
+	`, teamID)
+	if err != nil {
+		return nil, err
+	}
+	return scanTeamPlayerRows(rows)
+}
+
+func (p *PostgresDAL) GetTeamPlayers(teamID string) ([]models.Player, error) {
+	var exists bool
+	if err := p.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM teams WHERE id = $1)`, teamID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrTeamNotFound
+	}
+
+	rows, err := p.db.Query(`
+		SELECT player_data, COALESCE(draft_pick_number, 0)
+		FROM team_players WHERE team_id = $1 ORDER BY draft_pick_number, created_at
+	`, teamID)
+	if err != nil {
+		return nil, err
+	}
+	return scanTeamPlayerRows(rows)
+}
+
+// scanTeamPlayerRows reads player_data, draft_pick_number rows and closes
+// them.
+func scanTeamPlayerRows(rows *sql.Rows) ([]models.Player, error) {
+	defer rows.Close()
+
+	players := []models.Player{}
+	for rows.Next() {
+		var playerJSON []byte
+		var pickNumber int
+		if err := rows.Scan(&playerJSON, &pickNumber); err != nil {
+			return nil, err
+		}
+		var player models.Player
+		if err := json.Unmarshal(playerJSON, &player); err != nil {
+			return nil, err
+		}
+		player.PickNumber = pickNumber
+		players = append(players, player)
+	}
+	return players, rows.Err()
+}
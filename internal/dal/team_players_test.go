@@ -0,0 +1,69 @@
+package dal
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// assertGetTeamPlayers drafts a player to each of two teams and checks each
+// team's players carry their overall pick numbers, and that a team without
+// picks gets an empty list.
+func assertGetTeamPlayers(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	first, err := store.AddTeam("First", "", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam(First) failed: %v", err)
+	}
+	second, err := store.AddTeam("Second", "", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam(Second) failed: %v", err)
+	}
+
+	players, err := store.GetTeamPlayers(first.ID)
+	if err != nil {
+		t.Fatalf("GetTeamPlayers() before any picks failed: %v", err)
+	}
+	if players == nil || len(players) != 0 {
+		t.Fatalf("GetTeamPlayers() before any picks = %#v, want an empty list", players)
+	}
+
+	for _, team := range []*models.Team{first, second} {
+		player, err := store.AddPlayer(&models.Player{Name: "Pick for " + team.Name, Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB})
+		if err != nil {
+			t.Fatalf("AddPlayer() failed: %v", err)
+		}
+		if err := store.DraftPlayer(player.ID, team.ID); err != nil {
+			t.Fatalf("DraftPlayer(%s) failed: %v", team.Name, err)
+		}
+	}
+
+	for pick, team := range []*models.Team{first, second} {
+		players, err := store.GetTeamPlayers(team.ID)
+		if err != nil {
+			t.Fatalf("GetTeamPlayers(%s) failed: %v", team.Name, err)
+		}
+		if len(players) != 1 || players[0].Name != "Pick for "+team.Name || players[0].PickNumber != pick+1 {
+			t.Fatalf("GetTeamPlayers(%s) = %+v, want its one pick numbered %d", team.Name, players, pick+1)
+		}
+	}
+
+	if _, err := store.GetTeamPlayers("missing-team"); !errors.Is(err, ErrTeamNotFound) {
+		t.Fatalf("GetTeamPlayers(missing) error = %v, want ErrTeamNotFound", err)
+	}
+}
+
+func TestMemoryGetTeamPlayers(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertGetTeamPlayers(t, NewMemoryDAL())
+}
+
+func TestSQLiteGetTeamPlayers(t *testing.T) {
+	assertGetTeamPlayers(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresGetTeamPlayers(t *testing.T) {
+	assertGetTeamPlayers(t, newTestPostgresDAL(t))
+}
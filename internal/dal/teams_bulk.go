@@ -0,0 +1,193 @@
+package dal
+
+import (
+	"fmt"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/ids"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// TeamInput is one team for AddTeams. A blank mascot or color gets the
+// default for the team's position, as with AddTeam.
+type TeamInput struct {
+	Name   string `json:"name"`
+	Owner  string `json:"owner"`
+	Mascot string `json:"mascot"`
+	Color  string `json:"color"`
+}
+
+// checkTeamInputs cleans every name and rejects the batch if any name is
+// missing or too long, or, with UNIQUE_OWNERS, if an owner appears twice.
+func checkTeamInputs(inputs []TeamInput) ([]TeamInput, error) {
+	if len(inputs) == 0 {
+		return nil, validationErrorf("at least one team is required")
+	}
+	checked := make([]TeamInput, len(inputs))
+	owners := map[string]bool{}
+	for i, input := range inputs {
+		field := fmt.Sprintf("teams[%d].name", i)
+		name, err := sanitizeName("team", input.Name)
+		if err != nil {
+			return nil, fieldErrorf(field, "team %d: %v", i+1, err)
+		}
+		if name == "" {
+			return nil, fieldErrorf(field, "team %d: team name is required", i+1)
+		}
+		if input.Owner != "" && UniqueOwners() {
+			if owners[input.Owner] {
+				return nil, ErrOwnerHasTeam
+			}
+			owners[input.Owner] = true
+		}
+		input.Name = name
+		checked[i] = input
+	}
+	return checked, nil
+}
+
+// teamOwners lists the owner of each input.
+func teamOwners(inputs []TeamInput) []string {
+	owners := make([]string, len(inputs))
+	for i, input := range inputs {
+		owners[i] = input.Owner
+	}
+	return owners
+}
+
+// newBulkTeams builds the teams for inputs, the first joining at position
+// existing so default mascots and colors carry on from the current teams.
+func newBulkTeams(inputs []TeamInput, existing int) []models.Team {
+	now := timestampNow()
+	teams := make([]models.Team, len(inputs))
+	for i, input := range inputs {
+		mascot, color := teamDefaults(existing+i, input.Mascot, input.Color)
+		teams[i] = models.Team{
+			ID:        ids.New("team"),
+			Name:      input.Name,
+			Owner:     input.Owner,
+			Mascot:    mascot,
+			Color:     color,
+			Players:   []models.Player{},
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+	}
+	return teams
+}
+
+func (m *MemoryDAL) AddTeams(inputs []TeamInput) ([]models.Team, error) {
+	inputs, err := checkTeamInputs(inputs)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, input := range inputs {
+		if err := checkUniqueOwner(m.teams, "", "", input.Owner); err != nil {
+			return nil, err
+		}
+	}
+
+	teams := newBulkTeams(inputs, len(m.teams))
+	for i := range teams {
+		m.teams = append(m.teams, teams[i])
+		m.addSystemChatUnsafe(teamJoinedChatMessage(&teams[i]))
+	}
+	m.version++
+
+	return teams, nil
+}
+
+func (s *SQLiteDAL) AddTeams(inputs []TeamInput) ([]models.Team, error) {
+	inputs, err := checkTeamInputs(inputs)
+	if err != nil {
+		return nil, err
+	}
+	s.ownerMu.Lock()
+	defer s.ownerMu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := checkUniqueOwnersSQL(tx, teamOwners(inputs)); err != nil {
+		return nil, err
+	}
+
+	var count, nextOrder int
+	if err := tx.QueryRow("SELECT COUNT(*), COALESCE(MAX(display_order) + 1, 0) FROM teams").Scan(&count, &nextOrder); err != nil {
+		return nil, err
+	}
+
+	teams := newBulkTeams(inputs, count)
+	for i, team := range teams {
+		if _, err := tx.Exec(`
+			INSERT INTO teams (id, name, owner, mascot, color, display_order, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, team.ID, team.Name, team.Owner, team.Mascot, team.Color, nextOrder+i, timeMs(team.CreatedAt), timeMs(team.UpdatedAt)); err != nil {
+			return nil, err
+		}
+		if err := insertChatTx(tx, teamJoinedChatMessage(&team), "?, ?, ?, ?, ?, ?, ?"); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+func (p *PostgresDAL) AddTeams(inputs []TeamInput) ([]models.Team, error) {
+	inputs, err := checkTeamInputs(inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Concurrent batches would otherwise read the same count and order
+	if _, err := tx.Exec(`LOCK TABLE teams IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return nil, err
+	}
+
+	if err := checkUniqueOwnersSQL(tx, teamOwners(inputs)); err != nil {
+		return nil, err
+	}
+
+	var count, nextOrder int
+	if err := tx.QueryRow("SELECT COUNT(*), COALESCE(MAX(display_order) + 1, 0) FROM teams").Scan(&count, &nextOrder); err != nil {
+		return nil, err
+	}
+
+	teams := newBulkTeams(inputs, count)
+	for i, team := range teams {
+		if _, err := tx.Exec(`
+			INSERT INTO teams (id, name, owner, mascot, color, display_order, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, team.ID, team.Name, team.Owner, team.Mascot, team.Color, nextOrder+i, team.CreatedAt, team.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := insertChatTx(tx, teamJoinedChatMessage(&team), "$1, $2, $3, $4, $5, $6, $7"); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
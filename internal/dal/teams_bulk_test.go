@@ -0,0 +1,83 @@
+package dal
+
+import (
+	"errors"
+	"testing"
+)
+
+// assertAddTeams adds six teams in one call after an existing one and
+// checks default mascots carry on from it, then that a bad batch adds none.
+func assertAddTeams(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	if _, err := store.AddTeam("First", "", "", ""); err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	names := []string{"Foxes", "Bears", "Bunnies", "Cats", "Sheep", "Giraffes"}
+	inputs := make([]TeamInput, len(names))
+	for i, name := range names {
+		inputs[i] = TeamInput{Name: name}
+	}
+	inputs[2].Mascot = "🐙"
+
+	teams, err := store.AddTeams(inputs)
+	if err != nil {
+		t.Fatalf("AddTeams() failed: %v", err)
+	}
+	if len(teams) != len(names) {
+		t.Fatalf("AddTeams() returned %d teams, want %d", len(teams), len(names))
+	}
+	mascots := map[string]bool{}
+	for i, team := range teams {
+		want := defaultTeamMascots[i+1]
+		if i == 2 {
+			want = "🐙"
+		}
+		if team.Name != names[i] || team.Mascot != want {
+			t.Fatalf("team %d = %s %s, want %s %s", i, team.Mascot, team.Name, want, names[i])
+		}
+		if mascots[team.Mascot] {
+			t.Fatalf("mascot %s handed out twice", team.Mascot)
+		}
+		mascots[team.Mascot] = true
+	}
+
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	if len(state.Teams) != 7 || state.Teams[6].ID != teams[5].ID {
+		t.Fatalf("GetState() has %d teams, want 7 with the batch last in order", len(state.Teams))
+	}
+
+	if _, err := store.AddTeams([]TeamInput{{Name: "Lions"}, {Name: " "}}); !errors.Is(err, ErrValidation) {
+		t.Fatalf("AddTeams(blank name) error = %v, want ErrValidation", err)
+	}
+	if _, err := store.AddTeams(nil); !errors.Is(err, ErrValidation) {
+		t.Fatalf("AddTeams(nil) error = %v, want ErrValidation", err)
+	}
+	t.Setenv("UNIQUE_OWNERS", "true")
+	if _, err := store.AddTeams([]TeamInput{{Name: "Pandas", Owner: "sam"}, {Name: "Tigers", Owner: "sam"}}); !errors.Is(err, ErrOwnerHasTeam) {
+		t.Fatalf("AddTeams(same owner twice) error = %v, want ErrOwnerHasTeam", err)
+	}
+	state, err = store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	if len(state.Teams) != 7 {
+		t.Fatalf("rejected batches left %d teams, want 7", len(state.Teams))
+	}
+}
+
+func TestMemoryAddTeams(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertAddTeams(t, NewMemoryDAL())
+}
+
+func TestSQLiteAddTeams(t *testing.T) {
+	assertAddTeams(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresAddTeams(t *testing.T) {
+	assertAddTeams(t, newTestPostgresDAL(t))
+}
@@ -0,0 +1,157 @@
+package dal
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxClusterRunes caps the runes kept in one user-perceived character. The
+// longest emoji sequences (families, flags of subdivisions, skin-toned
+// couples) need about ten; the rest is stacked combining marks.
+const maxClusterRunes = 16
+
+// MaxChatLength returns the CHAT_MAX_LENGTH limit on a chat message, in
+// characters, or 500 when it is unset or not a positive number. Longer
+// messages are cut to fit.
+func MaxChatLength() int {
+	return positiveEnvInt("CHAT_MAX_LENGTH", 500)
+}
+
+// MaxNameLength returns the NAME_MAX_LENGTH limit on team and player names,
+// in characters, or 50 when it is unset or not a positive number. Longer
+// names are rejected.
+func MaxNameLength() int {
+	return positiveEnvInt("NAME_MAX_LENGTH", 50)
+}
+
+func positiveEnvInt(name string, fallback int) int {
+	value, err := strconv.Atoi(strings.TrimSpace(os.Getenv(name)))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// sanitizeChatText cleans a chat message and cuts it to MaxChatLength
+// characters. A message with nothing left is rejected.
+func sanitizeChatText(text string) (string, error) {
+	text = cleanText(text)
+	if text == "" {
+		return "", fieldErrorf("text", "chat message is empty")
+	}
+	return truncateGraphemes(text, MaxChatLength()), nil
+}
+
+// sanitizeName cleans a team or player name and rejects it when it is
+// longer than MaxNameLength characters. kind names the field in the error.
+func sanitizeName(kind, name string) (string, error) {
+	name = cleanText(name)
+	if limit := MaxNameLength(); graphemeCount(name) > limit {
+		return "", fieldErrorf("name", "%s name must be at most %d characters", kind, limit)
+	}
+	return name, nil
+}
+
+// cleanText drops control and invisible formatting characters, collapses
+// each run of whitespace to one space and trims the ends. Joiners, tags
+// and variation selectors stay so emoji sequences survive intact.
+func cleanText(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	space := false
+	for _, cluster := range graphemes(text) {
+		r, _ := utf8.DecodeRuneInString(cluster)
+		if unicode.IsSpace(r) {
+			space = b.Len() > 0
+			continue
+		}
+		kept := 0
+		for _, r := range cluster {
+			if r == utf8.RuneError || unicode.IsControl(r) || (unicode.Is(unicode.Cf, r) && !keepFormat(r)) || unicode.IsSpace(r) {
+				continue
+			}
+			if kept == maxClusterRunes {
+				break
+			}
+			if space {
+				b.WriteByte(' ')
+				space = false
+			}
+			b.WriteRune(r)
+			kept++
+		}
+	}
+	return b.String()
+}
+
+// keepFormat reports whether a format (Cf) character is part of emoji
+// sequences rather than a way to hide or reorder text.
+func keepFormat(r rune) bool {
+	return r == zeroWidthJoiner || r == 0x200C || (r >= 0xE0020 && r <= 0xE007F)
+}
+
+// graphemeCount returns how many user-perceived characters text holds.
+func graphemeCount(text string) int {
+	return len(graphemes(text))
+}
+
+// truncateGraphemes cuts text to at most limit user-perceived characters
+// without splitting an emoji or a letter from its accents.
+func truncateGraphemes(text string, limit int) string {
+	clusters := graphemes(text)
+	if len(clusters) <= limit {
+		return text
+	}
+	return strings.TrimRightFunc(strings.Join(clusters[:limit], ""), unicode.IsSpace)
+}
+
+const zeroWidthJoiner = 0x200D
+
+// graphemes splits text into user-perceived characters. It covers what
+// chat and names use: combining marks, variation selectors, emoji
+// modifiers and tags, zero-width-joiner sequences and regional indicator
+// flags. It is not a full UAX #29 segmenter.
+func graphemes(text string) []string {
+	var clusters []string
+	start := 0
+	joined := false // the previous rune was a zero width joiner
+	flagHalf := false
+	for i, r := range text {
+		extends := i > 0 && ((joined && !unicode.IsSpace(r) && !unicode.IsControl(r)) || extendsCluster(r) || (flagHalf && isRegionalIndicator(r)))
+		if !extends && i > 0 {
+			clusters = append(clusters, text[start:i])
+			start = i
+		}
+		joined = r == zeroWidthJoiner
+		if isRegionalIndicator(r) {
+			flagHalf = !(extends && flagHalf)
+		} else {
+			flagHalf = false
+		}
+	}
+	if start < len(text) {
+		clusters = append(clusters, text[start:])
+	}
+	return clusters
+}
+
+func extendsCluster(r rune) bool {
+	switch {
+	case r == zeroWidthJoiner:
+		return true
+	case r >= 0xFE00 && r <= 0xFE0F, r >= 0xE0100 && r <= 0xE01EF: // variation selectors
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF: // skin tone modifiers
+		return true
+	case r >= 0xE0020 && r <= 0xE007F: // tags
+		return true
+	}
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc)
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
@@ -0,0 +1,242 @@
+package dal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+const (
+	family   = "👨\u200d👩\u200d👧\u200d👦" // four people joined by ZWJs
+	flag     = "🇬🇧"                     // two regional indicators
+	thumbsUp = "👍🏽"                     // skin tone modifier
+	accented = "e\u0301"                // e + combining acute
+	scotland = "🏴\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F"
+)
+
+func TestGraphemeCount(t *testing.T) {
+	for text, want := range map[string]int{
+		"":                     0,
+		"bunny":                5,
+		family:                 1,
+		flag + flag:            2,
+		"🇬🇧🇫":                  2, // a lone indicator is its own character
+		thumbsUp + "❤️":        2,
+		accented + "clair":     6,
+		scotland:               1,
+		"a\u200d b":            3, // a joiner does not swallow a space
+		strings.Repeat("界", 3): 3,
+	} {
+		if got := graphemeCount(text); got != want {
+			t.Errorf("graphemeCount(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
+
+func TestTruncateGraphemesKeepsClustersWhole(t *testing.T) {
+	for _, tc := range []struct {
+		text  string
+		limit int
+		want  string
+	}{
+		{"bunny", 10, "bunny"},
+		{"hi " + family + family, 4, "hi " + family},
+		{flag + flag + flag, 2, flag + flag},
+		{"ok " + thumbsUp, 3, "ok"},
+		{accented + accented, 1, accented},
+		{"café" + scotland, 5, "café" + scotland},
+	} {
+		if got := truncateGraphemes(tc.text, tc.limit); got != tc.want {
+			t.Errorf("truncateGraphemes(%q, %d) = %q, want %q", tc.text, tc.limit, got, tc.want)
+		}
+	}
+}
+
+func TestCleanText(t *testing.T) {
+	for text, want := range map[string]string{
+		"  hello   world \n":               "hello world",
+		"line\r\none\ttwo":                 "line one two",
+		"null\x00byte\x1b[31m":             "nullbyte[31m",
+		"bidi\u202eevil\u202c":             "bidievil",
+		"zero\u200bwidth\ufeff":            "zerowidth",
+		family + " " + flag:                family + " " + flag,
+		"invalid \xff utf8":                "invalid utf8",
+		"z" + strings.Repeat("\u0336", 40): "z" + strings.Repeat("\u0336", maxClusterRunes-1),
+	} {
+		if got := cleanText(text); got != want {
+			t.Errorf("cleanText(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func assertTextLimits(t *testing.T, store DraftDAL) {
+	t.Helper()
+	t.Setenv("CHAT_MAX_LENGTH", "10")
+	t.Setenv("NAME_MAX_LENGTH", "5")
+
+	msg, err := store.AddChatMessage("  go \x07bunnies "+strings.Repeat(family, 20), "user")
+	if err != nil {
+		t.Fatalf("AddChatMessage() failed: %v", err)
+	}
+	if want := "go bunnies"; msg.Text != want {
+		t.Fatalf("AddChatMessage() text = %q, want %q", msg.Text, want)
+	}
+	msg, err = store.AddChatMessage("hi "+strings.Repeat(flag, 20), "user")
+	if err != nil {
+		t.Fatalf("AddChatMessage() failed: %v", err)
+	}
+	if want := "hi " + strings.Repeat(flag, 7); msg.Text != want {
+		t.Fatalf("AddChatMessage() text = %q, want %q", msg.Text, want)
+	}
+	if _, err := store.AddChatMessage(" \u200b\n ", "user"); !errors.Is(err, ErrValidation) {
+		t.Fatalf("AddChatMessage(blank) error = %v, want ErrValidation", err)
+	}
+
+	team, err := store.AddTeam(" Fox\t\n"+family+" ", "Sarah", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	if want := "Fox " + family; team.Name != want {
+		t.Fatalf("AddTeam() name = %q, want %q", team.Name, want)
+	}
+	if _, err := store.AddTeam("Foxes!", "Sarah", "", ""); !errors.Is(err, ErrValidation) {
+		t.Fatalf("AddTeam(long name) error = %v, want ErrValidation", err)
+	}
+	if _, err := store.UpdateTeam(team.ID, strings.Repeat(thumbsUp, 6), "Sarah", "", ""); !errors.Is(err, ErrValidation) {
+		t.Fatalf("UpdateTeam(long name) error = %v, want ErrValidation", err)
+	}
+
+	player, err := store.AddPlayer(&models.Player{Name: "Bun\x00ny", Position: "CC", Team: "Test", Points: 10, Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+	if player.Name != "Bunny" {
+		t.Fatalf("AddPlayer() name = %q, want %q", player.Name, "Bunny")
+	}
+	if _, err := store.UpdatePlayer(&models.Player{ID: player.ID, Name: "Bunnies", Position: "CC", Team: "Test", Tier: models.TierB}); !errors.Is(err, ErrValidation) {
+		t.Fatalf("UpdatePlayer(long name) error = %v, want ErrValidation", err)
+	}
+
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	if state.Teams[0].Name != team.Name || state.Players[0].Name != "Bunny" {
+		t.Fatalf("GetState() names = %q/%q, want the stored names unchanged", state.Teams[0].Name, state.Players[0].Name)
+	}
+}
+
+func TestMemoryTextLimits(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertTextLimits(t, NewMemoryDAL())
+}
+
+func TestSQLiteTextLimits(t *testing.T) {
+	assertTextLimits(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresTextLimits(t *testing.T) {
+	assertTextLimits(t, newTestPostgresDAL(t))
+}
+
+func TestValidateNewTeamRejectsLongNames(t *testing.T) {
+	t.Setenv("NAME_MAX_LENGTH", "3")
+	if err := ValidateNewTeam(flag + flag + flag); err != nil {
+		t.Fatalf("ValidateNewTeam(3 flags) = %v, want nil", err)
+	}
+	if err := ValidateNewTeam("Foxes"); !errors.Is(err, ErrValidation) {
+		t.Fatalf("ValidateNewTeam(long) = %v, want ErrValidation", err)
+	}
+	if err := ValidateNewTeam("\u200b\x00"); !errors.Is(err, ErrValidation) || !strings.Contains(err.Error(), "required") {
+		t.Fatalf("ValidateNewTeam(invisible) = %v, want a required error", err)
+	}
+}
+
+// assertEmojiRoundTrip checks that 4-byte UTF-8 survives a trip through
+// storage: team names, mascots, chat text and reaction emotes come back
+// byte for byte.
+func assertEmojiRoundTrip(t *testing.T, store DraftDAL) {
+	t.Helper()
+	name := "🦊 Füchse " + flag
+	text := "Go " + family + " " + thumbsUp + " — ça va? 𝄞 " + scotland
+
+	team, err := store.AddTeam(name, "Zoë", "🐰", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	msg, err := store.AddChatMessage(text, "user")
+	if err != nil {
+		t.Fatalf("AddChatMessage() failed: %v", err)
+	}
+	if _, err := store.AddReaction(msg.ID, "🔥", "u1"); err != nil {
+		t.Fatalf("AddReaction() failed: %v", err)
+	}
+
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	var stored *models.Team
+	for i := range state.Teams {
+		if state.Teams[i].ID == team.ID {
+			stored = &state.Teams[i]
+		}
+	}
+	if stored == nil || stored.Name != name || stored.Owner != "Zoë" || stored.Mascot != "🐰" {
+		t.Fatalf("stored team = %+v, want name %q, owner Zoë and mascot 🐰", stored, name)
+	}
+
+	since, err := store.GetChatSince(0)
+	if err != nil {
+		t.Fatalf("GetChatSince() failed: %v", err)
+	}
+	for _, messages := range [][]models.ChatMessage{state.Chat, since} {
+		found := false
+		for _, got := range messages {
+			if got.ID != msg.ID {
+				continue
+			}
+			found = true
+			if got.Text != text || got.Emotes["🔥"] != 1 {
+				t.Fatalf("stored message = %q %v, want %q with one 🔥", got.Text, got.Emotes, text)
+			}
+		}
+		if !found {
+			t.Fatal("chat message missing after a round trip")
+		}
+	}
+}
+
+func TestMemoryEmojiRoundTrip(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertEmojiRoundTrip(t, NewMemoryDAL())
+}
+
+func TestSQLiteEmojiRoundTrip(t *testing.T) {
+	store := newTestSQLiteDAL(t)
+	assertEmojiRoundTrip(t, store)
+
+	// SQLite counts characters, so a mangled encoding shows up as a
+	// mismatch between these and Go's byte and rune counts
+	var encoding string
+	if err := store.db.QueryRow(`PRAGMA encoding`).Scan(&encoding); err != nil {
+		t.Fatalf("PRAGMA encoding failed: %v", err)
+	}
+	if encoding != "UTF-8" {
+		t.Fatalf("SQLite encoding = %q, want UTF-8", encoding)
+	}
+	var name string
+	var bytesLen, charLen int
+	if err := store.db.QueryRow(`SELECT name, length(CAST(name AS BLOB)), length(name) FROM teams`).Scan(&name, &bytesLen, &charLen); err != nil {
+		t.Fatalf("read team name failed: %v", err)
+	}
+	if bytesLen != len(name) || charLen != len([]rune(name)) {
+		t.Fatalf("stored name %q is %d bytes and %d characters, want %d and %d", name, bytesLen, charLen, len(name), len([]rune(name)))
+	}
+}
+
+func TestPostgresEmojiRoundTrip(t *testing.T) {
+	assertEmojiRoundTrip(t, newTestPostgresDAL(t))
+}
@@ -0,0 +1,67 @@
+package dal
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// timestampNow is the time written to CreatedAt and UpdatedAt. It is UTC and
+// truncated to milliseconds, which is what SQLite keeps, so every backend
+// hands back the value it was given.
+func timestampNow() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+// msTime converts a unix millisecond column to a time, with 0 as the zero
+// time (rows from before the column existed).
+func msTime(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms).UTC()
+}
+
+// nullTime reads a nullable Postgres TIMESTAMP column as UTC, with NULL as
+// the zero time.
+func nullTime(t sql.NullTime) time.Time {
+	if !t.Valid {
+		return time.Time{}
+	}
+	return t.Time.UTC()
+}
+
+// timeMs is the inverse of msTime.
+func timeMs(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+// stampChatMessage fills in a message's CreatedAt from TS and its UpdatedAt
+// from updatedMs, falling back to CreatedAt when it has never changed.
+func stampChatMessage(msg *models.ChatMessage, updatedMs int64) {
+	msg.CreatedAt = msTime(msg.TS)
+	msg.UpdatedAt = msTime(updatedMs)
+	if msg.UpdatedAt.IsZero() {
+		msg.UpdatedAt = msg.CreatedAt
+	}
+}
+
+func stampPlayers(players []models.Player) []models.Player {
+	now := timestampNow()
+	for i := range players {
+		players[i].CreatedAt, players[i].UpdatedAt = now, now
+	}
+	return players
+}
+
+func stampTeams(teams []models.Team) []models.Team {
+	now := timestampNow()
+	for i := range teams {
+		teams[i].CreatedAt, teams[i].UpdatedAt = now, now
+	}
+	return teams
+}
@@ -0,0 +1,152 @@
+package dal
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+func assertTimestamps(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	// Backends keep millisecond timestamps; step past one between writes.
+	tick := func() { time.Sleep(2 * time.Millisecond) }
+
+	player, err := store.AddPlayer(&models.Player{Name: "Stamped", Position: "CC", Team: "Test", Points: 10, Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+	if player.CreatedAt.IsZero() || !player.UpdatedAt.Equal(player.CreatedAt) {
+		t.Fatalf("AddPlayer() times = %v/%v, want equal and set", player.CreatedAt, player.UpdatedAt)
+	}
+	created := player.CreatedAt
+
+	tick()
+	updated, err := store.UpdatePlayer(&models.Player{ID: player.ID, Name: "Restamped", Position: "CC", Team: "Test", Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("UpdatePlayer() failed: %v", err)
+	}
+	if !updated.CreatedAt.Equal(created) {
+		t.Fatalf("UpdatePlayer() CreatedAt = %v, want %v", updated.CreatedAt, created)
+	}
+	if !updated.UpdatedAt.After(created) {
+		t.Fatalf("UpdatePlayer() UpdatedAt = %v, want after %v", updated.UpdatedAt, created)
+	}
+	lastUpdate := updated.UpdatedAt
+
+	team, err := store.AddTeam("Stamped Team", "Sarah", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	if team.CreatedAt.IsZero() || !team.UpdatedAt.Equal(team.CreatedAt) {
+		t.Fatalf("AddTeam() times = %v/%v, want equal and set", team.CreatedAt, team.UpdatedAt)
+	}
+	tick()
+	renamed, err := store.UpdateTeam(team.ID, "Restamped Team", "Sarah", "", "")
+	if err != nil {
+		t.Fatalf("UpdateTeam() failed: %v", err)
+	}
+	if !renamed.CreatedAt.Equal(team.CreatedAt) || !renamed.UpdatedAt.After(team.CreatedAt) {
+		t.Fatalf("UpdateTeam() times = %v/%v, want created %v and updated later", renamed.CreatedAt, renamed.UpdatedAt, team.CreatedAt)
+	}
+
+	tick()
+	if err := store.DraftPlayer(player.ID, team.ID); err != nil {
+		t.Fatalf("DraftPlayer() failed: %v", err)
+	}
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	if len(state.Players) != 1 || len(state.Teams) != 1 || len(state.Teams[0].Players) != 1 {
+		t.Fatalf("GetState() = %d players, %d teams, want the drafted player", len(state.Players), len(state.Teams))
+	}
+	drafted := state.Players[0]
+	if !drafted.CreatedAt.Equal(created) || !drafted.UpdatedAt.After(lastUpdate) {
+		t.Fatalf("drafted player times = %v/%v, want created %v and updated after %v", drafted.CreatedAt, drafted.UpdatedAt, created, lastUpdate)
+	}
+	if rostered := state.Teams[0].Players[0]; !rostered.CreatedAt.Equal(created) || !rostered.UpdatedAt.Equal(drafted.UpdatedAt) {
+		t.Fatalf("roster player times = %v/%v, want %v/%v", rostered.CreatedAt, rostered.UpdatedAt, created, drafted.UpdatedAt)
+	}
+	if got := state.Teams[0]; !got.CreatedAt.Equal(renamed.CreatedAt) || !got.UpdatedAt.Equal(renamed.UpdatedAt) {
+		t.Fatalf("GetState() team times = %v/%v, want %v/%v", got.CreatedAt, got.UpdatedAt, renamed.CreatedAt, renamed.UpdatedAt)
+	}
+
+	msg, err := store.AddChatMessage("hello", "user")
+	if err != nil {
+		t.Fatalf("AddChatMessage() failed: %v", err)
+	}
+	if !msg.CreatedAt.Equal(time.UnixMilli(msg.TS)) || !msg.UpdatedAt.Equal(msg.CreatedAt) {
+		t.Fatalf("AddChatMessage() times = %v/%v, want ts %d", msg.CreatedAt, msg.UpdatedAt, msg.TS)
+	}
+	tick()
+	reacted, err := store.AddReaction(msg.ID, "❤️", "user-1")
+	if err != nil {
+		t.Fatalf("AddReaction() failed: %v", err)
+	}
+	if !reacted.CreatedAt.Equal(msg.CreatedAt) || !reacted.UpdatedAt.After(msg.CreatedAt) {
+		t.Fatalf("AddReaction() times = %v/%v, want created %v and updated later", reacted.CreatedAt, reacted.UpdatedAt, msg.CreatedAt)
+	}
+}
+
+func TestMemoryTimestamps(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertTimestamps(t, NewMemoryDAL())
+}
+
+func TestSQLiteTimestamps(t *testing.T) {
+	assertTimestamps(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresTimestamps(t *testing.T) {
+	assertTimestamps(t, newTestPostgresDAL(t))
+}
+
+func TestSQLiteBackfillsTimestamps(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	path := filepath.Join(t.TempDir(), "draft.sqlite")
+
+	// A database from before the timestamp columns
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("sql.Open() failed: %v", err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE players (
+			id TEXT PRIMARY KEY, name TEXT NOT NULL, position TEXT NOT NULL, team TEXT NOT NULL,
+			points INTEGER NOT NULL, cuddle_points INTEGER NOT NULL DEFAULT 50, tier TEXT NOT NULL,
+			drafted INTEGER NOT NULL DEFAULT 0, drafted_by TEXT, image TEXT
+		);
+		CREATE TABLE teams (
+			id TEXT PRIMARY KEY, name TEXT NOT NULL, owner TEXT NOT NULL, mascot TEXT NOT NULL, color TEXT NOT NULL
+		);
+		INSERT INTO players (id, name, position, team, points, tier, image) VALUES ('p1', 'Old Player', 'CC', 'Test', 10, 'B', '');
+		INSERT INTO teams (id, name, owner, mascot, color) VALUES ('t1', 'Old Team', 'Sarah', '🦊', 'bg-orange-100');
+	`)
+	db.Close()
+	if err != nil {
+		t.Fatalf("create old schema: %v", err)
+	}
+
+	before := time.Now().Add(-time.Second)
+	store, err := NewSQLiteDAL(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteDAL() failed: %v", err)
+	}
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	if len(state.Players) != 1 || len(state.Teams) != 1 {
+		t.Fatalf("GetState() = %d players, %d teams, want 1 and 1", len(state.Players), len(state.Teams))
+	}
+	if player := state.Players[0]; player.CreatedAt.Before(before) || !player.UpdatedAt.Equal(player.CreatedAt) {
+		t.Fatalf("backfilled player times = %v/%v, want the migration time", player.CreatedAt, player.UpdatedAt)
+	}
+	if team := state.Teams[0]; team.CreatedAt.Before(before) || !team.UpdatedAt.Equal(team.CreatedAt) {
+		t.Fatalf("backfilled team times = %v/%v, want the migration time", team.CreatedAt, team.UpdatedAt)
+	}
+}
@@ -0,0 +1,205 @@
+package dal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+const accessTokenColumns = `id, name, owner_id, owner_username, owner_name, owner_email, owner_groups, scopes, token_hash, created_at, last_used_at`
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAccessToken(row rowScanner) (*models.AccessToken, error) {
+	var token models.AccessToken
+	var groupsJSON, scopesJSON []byte
+	err := row.Scan(&token.ID, &token.Name, &token.OwnerID, &token.OwnerUsername, &token.OwnerName, &token.OwnerEmail,
+		&groupsJSON, &scopesJSON, &token.TokenHash, &token.CreatedAt, &token.LastUsedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	json.Unmarshal(groupsJSON, &token.OwnerGroups)
+	json.Unmarshal(scopesJSON, &token.Scopes)
+	return &token, nil
+}
+
+func scanAccessTokens(rows *sql.Rows) ([]models.AccessToken, error) {
+	defer rows.Close()
+
+	tokens := []models.AccessToken{}
+	for rows.Next() {
+		token, err := scanAccessToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *token)
+	}
+	return tokens, rows.Err()
+}
+
+func copyAccessToken(token models.AccessToken) models.AccessToken {
+	token.OwnerGroups = append([]string(nil), token.OwnerGroups...)
+	token.Scopes = append([]string(nil), token.Scopes...)
+	return token
+}
+
+// Memory
+
+func (m *MemoryDAL) CreateAccessToken(token *models.AccessToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.tokens = append(m.tokens, copyAccessToken(*token))
+	return nil
+}
+
+func (m *MemoryDAL) ListAccessTokens(ownerID string) ([]models.AccessToken, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tokens := []models.AccessToken{}
+	for _, token := range m.tokens {
+		if ownerID == "" || token.OwnerID == ownerID {
+			tokens = append(tokens, copyAccessToken(token))
+		}
+	}
+	return tokens, nil
+}
+
+func (m *MemoryDAL) GetAccessTokenByHash(hash string) (*models.AccessToken, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, token := range m.tokens {
+		if token.TokenHash == hash {
+			found := copyAccessToken(token)
+			return &found, nil
+		}
+	}
+	return nil, ErrTokenNotFound
+}
+
+func (m *MemoryDAL) DeleteAccessToken(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, token := range m.tokens {
+		if token.ID == id {
+			m.tokens = append(m.tokens[:i], m.tokens[i+1:]...)
+			return nil
+		}
+	}
+	return ErrTokenNotFound
+}
+
+func (m *MemoryDAL) TouchAccessToken(id string, usedAt int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.tokens {
+		if m.tokens[i].ID == id {
+			m.tokens[i].LastUsedAt = usedAt
+			return nil
+		}
+	}
+	return ErrTokenNotFound
+}
+
+// SQLite
+
+func (s *SQLiteDAL) CreateAccessToken(token *models.AccessToken) error {
+	groupsJSON, _ := json.Marshal(token.OwnerGroups)
+	scopesJSON, _ := json.Marshal(token.Scopes)
+	_, err := s.db.Exec(`
+		INSERT INTO access_tokens (`+accessTokenColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, token.ID, token.Name, token.OwnerID, token.OwnerUsername, token.OwnerName, token.OwnerEmail,
+		string(groupsJSON), string(scopesJSON), token.TokenHash, token.CreatedAt, token.LastUsedAt)
+	return err
+}
+
+func (s *SQLiteDAL) ListAccessTokens(ownerID string) ([]models.AccessToken, error) {
+	rows, err := s.db.Query(`
+		SELECT `+accessTokenColumns+` FROM access_tokens
+		WHERE ? = '' OR owner_id = ?
+		ORDER BY created_at, id
+	`, ownerID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return scanAccessTokens(rows)
+}
+
+func (s *SQLiteDAL) GetAccessTokenByHash(hash string) (*models.AccessToken, error) {
+	return scanAccessToken(s.db.QueryRow(`SELECT `+accessTokenColumns+` FROM access_tokens WHERE token_hash = ?`, hash))
+}
+
+func (s *SQLiteDAL) DeleteAccessToken(id string) error {
+	result, err := s.db.Exec(`DELETE FROM access_tokens WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrTokenNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteDAL) TouchAccessToken(id string, usedAt int64) error {
+	_, err := s.db.Exec(`UPDATE access_tokens SET last_used_at = ? WHERE id = ?`, usedAt, id)
+	return err
+}
+
+// Postgres
+
+func (p *PostgresDAL) CreateAccessToken(token *models.AccessToken) error {
+	groupsJSON, _ := json.Marshal(token.OwnerGroups)
+	scopesJSON, _ := json.Marshal(token.Scopes)
+	_, err := p.db.Exec(`
+		INSERT INTO access_tokens (`+accessTokenColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, token.ID, token.Name, token.OwnerID, token.OwnerUsername, token.OwnerName, token.OwnerEmail,
+		groupsJSON, scopesJSON, token.TokenHash, token.CreatedAt, token.LastUsedAt)
+	return err
+}
+
+func (p *PostgresDAL) ListAccessTokens(ownerID string) ([]models.AccessToken, error) {
+	rows, err := p.db.Query(`
+		SELECT `+accessTokenColumns+` FROM access_tokens
+		WHERE $1 = '' OR owner_id = $1
+		ORDER BY created_at, id
+	`, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return scanAccessTokens(rows)
+}
+
+func (p *PostgresDAL) GetAccessTokenByHash(hash string) (*models.AccessToken, error) {
+	return scanAccessToken(p.db.QueryRow(`SELECT `+accessTokenColumns+` FROM access_tokens WHERE token_hash = $1`, hash))
+}
+
+func (p *PostgresDAL) DeleteAccessToken(id string) error {
+	result, err := p.db.Exec(`DELETE FROM access_tokens WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrTokenNotFound
+	}
+	return nil
+}
+
+func (p *PostgresDAL) TouchAccessToken(id string, usedAt int64) error {
+	_, err := p.db.Exec(`UPDATE access_tokens SET last_used_at = $1 WHERE id = $2`, usedAt, id)
+	return err
+}
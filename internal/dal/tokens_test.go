@@ -0,0 +1,66 @@
+package dal
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+func assertTokenStoreRoundTrip(t *testing.T, store TokenStore) {
+	t.Helper()
+
+	token := &models.AccessToken{
+		ID:            "tok_1",
+		Name:          "cron",
+		OwnerID:       "u1",
+		OwnerUsername: "commish",
+		OwnerGroups:   []string{"admins"},
+		Scopes:        []string{"read", "chat"},
+		TokenHash:     "hash-1",
+		CreatedAt:     1700000000000,
+	}
+	if err := store.CreateAccessToken(token); err != nil {
+		t.Fatalf("CreateAccessToken() failed: %v", err)
+	}
+
+	found, err := store.GetAccessTokenByHash("hash-1")
+	if err != nil {
+		t.Fatalf("GetAccessTokenByHash() failed: %v", err)
+	}
+	if found.ID != "tok_1" || len(found.Scopes) != 2 || found.OwnerGroups[0] != "admins" {
+		t.Fatalf("token = %+v, want stored scopes and groups", found)
+	}
+
+	if err := store.TouchAccessToken("tok_1", 1700000005000); err != nil {
+		t.Fatalf("TouchAccessToken() failed: %v", err)
+	}
+	owned, err := store.ListAccessTokens("u1")
+	if err != nil || len(owned) != 1 || owned[0].LastUsedAt != 1700000005000 {
+		t.Fatalf("ListAccessTokens(u1) = %+v, %v; want one touched token", owned, err)
+	}
+	if others, _ := store.ListAccessTokens("u2"); len(others) != 0 {
+		t.Fatalf("ListAccessTokens(u2) = %+v, want none", others)
+	}
+
+	if err := store.DeleteAccessToken("tok_1"); err != nil {
+		t.Fatalf("DeleteAccessToken() failed: %v", err)
+	}
+	if _, err := store.GetAccessTokenByHash("hash-1"); !errors.Is(err, ErrTokenNotFound) {
+		t.Fatalf("GetAccessTokenByHash() after delete error = %v, want %v", err, ErrTokenNotFound)
+	}
+}
+
+func TestMemoryTokenStore(t *testing.T) {
+	assertTokenStoreRoundTrip(t, NewMemoryDAL())
+}
+
+func TestSQLiteTokenStore(t *testing.T) {
+	assertTokenStoreRoundTrip(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresTokenStore(t *testing.T) {
+	store := newTestPostgresDAL(t)
+	store.db.Exec(`DELETE FROM access_tokens WHERE id = 'tok_1'`)
+	assertTokenStoreRoundTrip(t, store)
+}
@@ -1,23 +1,104 @@
 package dal
 
-import "github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+import (
+	"context"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
 
 // DraftDAL defines the interface for data access layer
 type DraftDAL interface {
+	// Ping checks the store can serve requests, giving up once ctx is done.
+	Ping(ctx context.Context) error
 	GetState() (*models.DraftState, error)
+	// Version returns the state version, which every successful mutation
+	// bumps by one. GetState reports it too, as DraftState.Version.
+	Version() (int64, error)
 	Reset() error
+	// PreviewReset reports what Reset would remove without changing anything.
+	PreviewReset() (*models.ResetPreview, error)
+	// Stats counts players, picks, teams, chat and reactions without
+	// loading the whole state.
+	Stats() (models.DALStats, error)
 	SetDraftMode(mode models.DraftMode) (*models.DraftSettings, error)
 	AddPlayer(player *models.Player) (*models.Player, error)
 	UpdatePlayer(player *models.Player) (*models.Player, error)
 	DeletePlayer(id string) error
 	SetPlayerPoints(id string, points int) (*models.Player, error)
 	ReorderTeams(order []string) ([]models.Team, error)
+	// RandomizeTeamOrder shuffles the team order using crypto/rand and posts
+	// the new order to chat.
+	RandomizeTeamOrder() ([]models.Team, error)
 	DraftPlayer(playerID, teamID string) error
+	// SuggestPick returns the best available player for teamID at a position
+	// it still needs under POSITION_LIMITS, or the best overall when none is
+	// needed. It returns ErrNoPlayersLeft once every player is drafted.
+	SuggestPick(teamID string) (*models.Player, error)
+	// UndraftPlayer returns a drafted player to the pool with the points it
+	// had before the pick, and renumbers later picks to close the gap.
+	UndraftPlayer(playerID string) (*models.Player, error)
+	// UndoLastPick undrafts the most recent pick. It returns ErrNoPicksToUndo
+	// when nothing has been drafted.
+	UndoLastPick() (*models.Player, error)
+	// SwapPicks exchanges the players drafted at two pick numbers: each
+	// takes the other's pick number and team, with its points and cuddle
+	// points recomputed as if drafted there. It returns ErrPickNotFound for a
+	// pick number nobody has been drafted at.
+	SwapPicks(pickA, pickB int) error
 	AddChatMessage(text, msgType string) (*models.ChatMessage, error)
 	AddReaction(messageID, emote, userID string) (*models.ChatMessage, error)
+	// GetReactionUsers returns the IDs of the users behind each of a
+	// message's reactions, keyed by emote and sorted. Reactions recorded
+	// before authors were stored have counts but no users.
+	GetReactionUsers(messageID string) (map[string][]string, error)
+	// GetChatSince returns the chat messages with a ts after since, oldest
+	// first.
+	GetChatSince(since int64) ([]models.ChatMessage, error)
+	// TopReactedMessages returns up to limit messages with at least one
+	// reaction, most reactions first; ties go to the older message.
+	TopReactedMessages(limit int) ([]models.ChatMessage, error)
+	// ClearChat hides every chat message from GetState and returns how many
+	// went. Cleared messages can be restored for ChatClearGrace; after that
+	// they are deleted for good.
+	ClearChat() (int, error)
+	// RestoreChat brings back the messages cleared within the grace period.
+	// It returns ErrNothingToRestore when there are none.
+	RestoreChat() (int, error)
 	AddTeam(name, owner, mascot, color string) (*models.Team, error)
+	// AddTeams adds several teams in one go: all of them or, on any error,
+	// none. Default mascots and colors carry on from the existing teams.
+	AddTeams(inputs []TeamInput) ([]models.Team, error)
 	UpdateTeam(id, name, owner, mascot, color string) (*models.Team, error)
+	// ClaimTeam binds an unclaimed team to userID, recording owner as its
+	// display name. A team whose owner string already equals owner may be
+	// claimed; one bound to a different user returns ErrTeamAlreadyClaimed.
+	ClaimTeam(teamID, userID, owner string) (*models.Team, error)
+	// AssignTeamOwner unconditionally sets a team's owner. Empty values
+	// unassign it.
+	AssignTeamOwner(teamID, userID, owner string) (*models.Team, error)
 	DeleteTeam(id string) error
+	// GetTeamPlayers returns the players drafted by teamID in pick order,
+	// each with its PickNumber, or ErrTeamNotFound.
+	GetTeamPlayers(teamID string) ([]models.Player, error)
+	GetDraftWindow() (models.DraftWindow, error)
+	// SetDraftWindow schedules when DraftPlayer accepts picks. Outside the
+	// window DraftPlayer returns ErrDraftNotOpen or ErrDraftClosed.
+	SetDraftWindow(window models.DraftWindow) (*models.DraftWindow, error)
+	GetMeta() (models.DraftMeta, error)
+	// SetMeta stores the draft's title and description, cleaned like chat
+	// text. Reset clears them, as it does the draft window.
+	SetMeta(meta models.DraftMeta) (*models.DraftMeta, error)
+	// NominatePlayer puts an undrafted player up for bid in an auction draft,
+	// with teamID holding the opening bid. Only one player is nominated at a
+	// time. The auction methods return ErrAuctionDisabled unless
+	// DRAFT_MODE=auction, and DraftPlayer returns ErrAuctionPick when it is.
+	NominatePlayer(playerID, teamID string, openingBid int) (*models.AuctionNomination, error)
+	// PlaceBid raises the high bid on the nominated player. Bids over the
+	// team's remaining budget are rejected.
+	PlaceBid(playerID, teamID string, amount int) (*models.AuctionNomination, error)
+	// AwardPlayer drafts the nominated player to the high bidder, charges
+	// the bid to its budget and returns the closed nomination.
+	AwardPlayer() (*models.AuctionNomination, error)
 }
 
 // ImageStore stores user-managed image assets outside the application image.
@@ -26,3 +107,62 @@ type ImageStore interface {
 	SaveImage(path, contentType string, data []byte) error
 	ListImages() ([]string, error)
 }
+
+// TokenStore persists personal access tokens. Tokens survive draft resets.
+type TokenStore interface {
+	CreateAccessToken(token *models.AccessToken) error
+	// ListAccessTokens returns tokens owned by ownerID, or every token when ownerID is empty.
+	ListAccessTokens(ownerID string) ([]models.AccessToken, error)
+	GetAccessTokenByHash(hash string) (*models.AccessToken, error)
+	DeleteAccessToken(id string) error
+	TouchAccessToken(id string, usedAt int64) error
+}
+
+// SessionStore persists login sessions as opaque data so they survive restarts
+// and can be shared between replicas. Sessions survive draft resets.
+type SessionStore interface {
+	// SaveSession creates or replaces a session. expiresAt is in Unix milliseconds.
+	SaveSession(id string, data []byte, expiresAt int64, idp IdPSession) error
+	// GetSession returns ErrSessionNotFound for unknown and expired sessions.
+	GetSession(id string) ([]byte, error)
+	DeleteSession(id string) error
+	// DeleteIdPSessions deletes the sessions matching every non-empty field
+	// of idp and returns how many went. An empty idp deletes nothing.
+	DeleteIdPSessions(idp IdPSession) (int, error)
+}
+
+// IdPSession identifies the identity provider login behind a session: the
+// sub and sid claims of its OIDC ID token. Both are empty for other logins.
+type IdPSession struct {
+	Subject   string
+	SessionID string
+}
+
+// Matches reports whether other satisfies every non-empty field of idp.
+// An empty idp matches nothing.
+func (idp IdPSession) Matches(other IdPSession) bool {
+	if idp == (IdPSession{}) {
+		return false
+	}
+	return (idp.Subject == "" || idp.Subject == other.Subject) &&
+		(idp.SessionID == "" || idp.SessionID == other.SessionID)
+}
+
+// AuthEventFilter narrows ListAuthEvents. Zero values match everything.
+type AuthEventFilter struct {
+	Type string
+	// User matches either the user ID or the username.
+	User  string
+	IP    string
+	Since int64
+	Until int64
+	// Limit caps the result (newest first); 0 means no limit.
+	Limit int
+}
+
+// AuthEventStore keeps the authentication audit log. Events survive draft resets.
+type AuthEventStore interface {
+	RecordAuthEvent(event *models.AuthEvent) error
+	// ListAuthEvents returns matching events, newest first.
+	ListAuthEvents(filter AuthEventFilter) ([]models.AuthEvent, error)
+}
@@ -0,0 +1,287 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// memoryPick remembers a pick's team and the player's points before it.
+type memoryPick struct {
+	playerID     string
+	teamID       string
+	points       int
+	cuddlePoints int
+}
+
+// Memory
+
+func (m *MemoryDAL) UndraftPlayer(playerID string) (*models.Player, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.undraftPlayerUnsafe(playerID)
+}
+
+func (m *MemoryDAL) UndoLastPick() (*models.Player, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.picks) == 0 {
+		return nil, ErrNoPicksToUndo
+	}
+	return m.undraftPlayerUnsafe(m.picks[len(m.picks)-1].playerID)
+}
+
+func (m *MemoryDAL) undraftPlayerUnsafe(playerID string) (*models.Player, error) {
+	var player *models.Player
+	for i := range m.players {
+		if m.players[i].ID == playerID {
+			player = &m.players[i]
+			break
+		}
+	}
+	if player == nil {
+		return nil, ErrPlayerNotFound
+	}
+
+	pickIndex := -1
+	for i, pick := range m.picks {
+		if pick.playerID == playerID {
+			pickIndex = i
+			break
+		}
+	}
+	if !player.Drafted || pickIndex < 0 {
+		return nil, ErrPlayerNotDrafted
+	}
+	pick := m.picks[pickIndex]
+	m.picks = append(m.picks[:pickIndex], m.picks[pickIndex+1:]...)
+
+	teamName := player.DraftedBy
+	for i := range m.teams {
+		if m.teams[i].ID != pick.teamID {
+			continue
+		}
+		teamName = m.teams[i].Name
+		roster := m.teams[i].Players[:0]
+		for _, drafted := range m.teams[i].Players {
+			if drafted.ID != playerID {
+				roster = append(roster, drafted)
+			}
+		}
+		m.teams[i].Players = roster
+	}
+
+	player.Drafted = false
+	player.DraftedBy = ""
+	player.Points = pick.points
+	player.CuddlePoints = pick.cuddlePoints
+	player.UpdatedAt = timestampNow()
+	if m.auction != nil {
+		m.auction.Refund(playerID)
+	}
+	m.addSystemChatUnsafe(pickUndoneChatMessage(*player, teamName))
+	m.version++
+
+	restored := *player
+	return &restored, nil
+}
+
+// SQLite
+
+func (s *SQLiteDAL) UndraftPlayer(playerID string) (*models.Player, error) {
+	return s.undraft(func(tx *sql.Tx) (string, error) { return playerID, nil })
+}
+
+func (s *SQLiteDAL) UndoLastPick() (*models.Player, error) {
+	return s.undraft(func(tx *sql.Tx) (string, error) {
+		var playerID string
+		err := tx.QueryRow(`SELECT player_id FROM team_players ORDER BY draft_pick_number DESC LIMIT 1`).Scan(&playerID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrNoPicksToUndo
+		}
+		return playerID, err
+	})
+}
+
+// undraft undoes the pick of the player chosen inside the transaction.
+func (s *SQLiteDAL) undraft(choose func(tx *sql.Tx) (string, error)) (*models.Player, error) {
+	s.pickMu.Lock()
+	defer s.pickMu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	playerID, err := choose(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var teamName string
+	var pickNumber, preDraftPoints, preDraftCuddlePoints sql.NullInt64
+	err = tx.QueryRow(`
+		SELECT t.name, tp.draft_pick_number, tp.pre_draft_points, tp.pre_draft_cuddle_points
+		FROM team_players tp JOIN teams t ON t.id = tp.team_id
+		WHERE tp.player_id = ?
+	`, playerID).Scan(&teamName, &pickNumber, &preDraftPoints, &preDraftCuddlePoints)
+	if errors.Is(err, sql.ErrNoRows) {
+		var exists int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM players WHERE id = ?`, playerID).Scan(&exists); err != nil {
+			return nil, err
+		}
+		if exists == 0 {
+			return nil, ErrPlayerNotFound
+		}
+		return nil, ErrPlayerNotDrafted
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM team_players WHERE player_id = ?`, playerID); err != nil {
+		return nil, err
+	}
+	if err := refundAuction(tx, playerID, sqliteSaveAuction); err != nil {
+		return nil, err
+	}
+	if pickNumber.Valid {
+		if _, err := tx.Exec(`UPDATE team_players SET draft_pick_number = draft_pick_number - 1 WHERE draft_pick_number > ?`, pickNumber.Int64); err != nil {
+			return nil, err
+		}
+	}
+	_, err = tx.Exec(`
+		UPDATE players
+		SET drafted = 0, drafted_by = NULL, points = COALESCE(?, points), cuddle_points = COALESCE(?, cuddle_points), updated_at = ?
+		WHERE id = ?
+	`, preDraftPoints, preDraftCuddlePoints, timeMs(timestampNow()), playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var player models.Player
+	var createdAt, updatedAt int64
+	err = tx.QueryRow(`
+		SELECT id, name, position, team, points, cuddle_points, tier, image, created_at, updated_at
+		FROM players WHERE id = ?
+	`, playerID).Scan(&player.ID, &player.Name, &player.Position, &player.Team, &player.Points, &player.CuddlePoints, &player.Tier, &player.Image, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	player.CreatedAt, player.UpdatedAt = msTime(createdAt), msTime(updatedAt)
+
+	if err := insertChatTx(tx, pickUndoneChatMessage(player, teamName), "?, ?, ?, ?, ?, ?, ?"); err != nil {
+		return nil, err
+	}
+
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &player, nil
+}
+
+// Postgres
+
+func (p *PostgresDAL) UndraftPlayer(playerID string) (*models.Player, error) {
+	return p.undraft(func(ctx context.Context, tx *sql.Tx) (string, error) { return playerID, nil })
+}
+
+func (p *PostgresDAL) UndoLastPick() (*models.Player, error) {
+	return p.undraft(func(ctx context.Context, tx *sql.Tx) (string, error) {
+		var playerID string
+		err := tx.QueryRowContext(ctx, `SELECT player_id FROM team_players ORDER BY draft_pick_number DESC NULLS LAST LIMIT 1 FOR UPDATE`).Scan(&playerID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrNoPicksToUndo
+		}
+		return playerID, err
+	})
+}
+
+// undraft undoes the pick of the player chosen inside the transaction.
+func (p *PostgresDAL) undraft(choose func(ctx context.Context, tx *sql.Tx) (string, error)) (*models.Player, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockPickCounter(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	playerID, err := choose(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var teamName string
+	var pickNumber, preDraftPoints, preDraftCuddlePoints sql.NullInt64
+	err = tx.QueryRowContext(ctx, `
+		SELECT t.name, tp.draft_pick_number, tp.pre_draft_points, tp.pre_draft_cuddle_points
+		FROM team_players tp JOIN teams t ON t.id = tp.team_id
+		WHERE tp.player_id = $1
+		FOR UPDATE OF tp
+	`, playerID).Scan(&teamName, &pickNumber, &preDraftPoints, &preDraftCuddlePoints)
+	if errors.Is(err, sql.ErrNoRows) {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM players WHERE id = $1)`, playerID).Scan(&exists); err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrPlayerNotFound
+		}
+		return nil, ErrPlayerNotDrafted
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM team_players WHERE player_id = $1`, playerID); err != nil {
+		return nil, err
+	}
+	if err := refundAuction(tx, playerID, postgresSaveAuction(ctx)); err != nil {
+		return nil, err
+	}
+	if pickNumber.Valid {
+		if _, err := tx.ExecContext(ctx, `UPDATE team_players SET draft_pick_number = draft_pick_number - 1 WHERE draft_pick_number > $1`, pickNumber.Int64); err != nil {
+			return nil, err
+		}
+	}
+
+	var player models.Player
+	var createdAt, updatedAt sql.NullTime
+	err = tx.QueryRowContext(ctx, `
+		UPDATE players
+		SET drafted = false, drafted_by = NULL, points = COALESCE($1, points), cuddle_points = COALESCE($2, cuddle_points), updated_at = $4
+		WHERE id = $3
+		RETURNING id, name, position, team, points, cuddle_points, tier, image, created_at, updated_at
+	`, preDraftPoints, preDraftCuddlePoints, playerID, timestampNow()).Scan(&player.ID, &player.Name, &player.Position, &player.Team, &player.Points, &player.CuddlePoints, &player.Tier, &player.Image, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	player.CreatedAt, player.UpdatedAt = nullTime(createdAt), nullTime(updatedAt)
+
+	if err := insertChatTx(tx, pickUndoneChatMessage(player, teamName), "$1, $2, $3, $4, $5, $6, $7"); err != nil {
+		return nil, err
+	}
+
+	if err := bumpVersion(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &player, nil
+}
@@ -0,0 +1,98 @@
+package dal
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+func assertUndoPicks(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	if _, err := store.UndoLastPick(); !errors.Is(err, ErrNoPicksToUndo) || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("UndoLastPick(empty draft) error = %v, want %v", err, ErrNoPicksToUndo)
+	}
+
+	for _, name := range []string{"Undo Alpha", "Undo Bravo"} {
+		if _, err := store.AddTeam(name, name, "", ""); err != nil {
+			t.Fatalf("AddTeam(%s) failed: %v", name, err)
+		}
+	}
+	var players []*models.Player
+	for i, name := range []string{"Undo One", "Undo Two", "Undo Three"} {
+		player, err := store.AddPlayer(&models.Player{Name: name, Position: "CC", Team: "Test", Points: 200 + i, CuddlePoints: 40 + i, Tier: models.TierB})
+		if err != nil {
+			t.Fatalf("AddPlayer(%s) failed: %v", name, err)
+		}
+		players = append(players, player)
+	}
+
+	draftAll := func() {
+		t.Helper()
+		for _, player := range players {
+			state, err := store.GetState()
+			if err != nil {
+				t.Fatalf("GetState() failed: %v", err)
+			}
+			if err := store.DraftPlayer(player.ID, state.CurrentTeamID); err != nil {
+				t.Fatalf("DraftPlayer(%s) failed: %v", player.Name, err)
+			}
+		}
+	}
+	undoLast := func(want *models.Player) {
+		t.Helper()
+		restored, err := store.UndoLastPick()
+		if err != nil {
+			t.Fatalf("UndoLastPick() failed: %v", err)
+		}
+		if restored.ID != want.ID || restored.Drafted || restored.Points != want.Points || restored.CuddlePoints != want.CuddlePoints {
+			t.Fatalf("UndoLastPick() = %+v, want %s restored to %d/%d", restored, want.Name, want.Points, want.CuddlePoints)
+		}
+	}
+
+	draftAll()
+	if _, err := store.UndraftPlayer("missing"); !errors.Is(err, ErrPlayerNotFound) {
+		t.Fatalf("UndraftPlayer(missing) error = %v, want %v", err, ErrPlayerNotFound)
+	}
+	undoLast(players[2])
+	if _, err := store.UndraftPlayer(players[2].ID); !errors.Is(err, ErrPlayerNotDrafted) {
+		t.Fatalf("UndraftPlayer(undrafted) error = %v, want %v", err, ErrPlayerNotDrafted)
+	}
+	undoLast(players[1])
+	undoLast(players[0])
+	if _, err := store.UndoLastPick(); !errors.Is(err, ErrNoPicksToUndo) {
+		t.Fatalf("UndoLastPick(after unwinding) error = %v, want %v", err, ErrNoPicksToUndo)
+	}
+
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	for _, team := range state.Teams {
+		if len(team.Players) != 0 {
+			t.Fatalf("team %s still has %d players after unwinding", team.Name, len(team.Players))
+		}
+	}
+
+	// Undoing a middle pick closes the gap, so undo-last still follows draft order.
+	draftAll()
+	if _, err := store.UndraftPlayer(players[1].ID); err != nil {
+		t.Fatalf("UndraftPlayer(middle) failed: %v", err)
+	}
+	undoLast(players[2])
+	undoLast(players[0])
+}
+
+func TestMemoryUndoPicks(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertUndoPicks(t, NewMemoryDAL())
+}
+
+func TestSQLiteUndoPicks(t *testing.T) {
+	assertUndoPicks(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresUndoPicks(t *testing.T) {
+	assertUndoPicks(t, newTestPostgresDAL(t))
+}
@@ -2,9 +2,11 @@ package dal
 
 import (
 	"crypto/rand"
+	"database/sql"
 	"hash/fnv"
 	"math/big"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
@@ -15,6 +17,149 @@ func SeedDefaultCatalogEnabled() bool {
 	return IsDevEnvironment() || truthyEnv("JELLYCAT_SEED_DEFAULT_CATALOG")
 }
 
+// MaxRosterSize returns the MAX_ROSTER_SIZE cap on players per team, or 0
+// (unlimited) when it is unset or not a positive number.
+func MaxRosterSize() int {
+	size, err := strconv.Atoi(strings.TrimSpace(os.Getenv("MAX_ROSTER_SIZE")))
+	if err != nil || size < 0 {
+		return 0
+	}
+	return size
+}
+
+// PositionLimits returns the POSITION_LIMITS roster targets, e.g. "CC:2,SS:1"
+// for two CCs and one SS per team. Malformed or non-positive entries are
+// skipped; an empty map means one of each position.
+func PositionLimits() map[string]int {
+	limits := map[string]int{}
+	for _, entry := range strings.Split(os.Getenv("POSITION_LIMITS"), ",") {
+		position, count, ok := strings.Cut(entry, ":")
+		if !ok {
+			continue
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(count))
+		if position = strings.ToUpper(strings.TrimSpace(position)); position == "" || err != nil || limit < 1 {
+			continue
+		}
+		limits[position] = limit
+	}
+	return limits
+}
+
+// DefaultCuddlePoints returns the DEFAULT_CUDDLE_POINTS every seed player
+// starts with, or 50 when it is unset or outside 0–100.
+func DefaultCuddlePoints() int {
+	points, err := strconv.Atoi(strings.TrimSpace(os.Getenv("DEFAULT_CUDDLE_POINTS")))
+	if err != nil || points < 0 || points > 100 {
+		return 50
+	}
+	return points
+}
+
+// ChatEnabled reports whether chat is on. ENABLE_CHAT=false turns it off:
+// the chat methods return ErrChatDisabled, GetState returns no messages and
+// picks, undos and new teams no longer post system messages.
+func ChatEnabled() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("ENABLE_CHAT"))) {
+	case "0", "false", "no", "off":
+		return false
+	}
+	return true
+}
+
+// SystemSender returns the SYSTEM_SENDER name stored on system chat
+// messages, or "Draft Bot" when it is unset.
+func SystemSender() string {
+	if sender := strings.TrimSpace(os.Getenv("SYSTEM_SENDER")); sender != "" {
+		return sender
+	}
+	return "Draft Bot"
+}
+
+// chatSender is the sender stored on a new message of msgType.
+func chatSender(msgType string) string {
+	if msgType == "system" {
+		return SystemSender()
+	}
+	return ""
+}
+
+// UniqueOwners reports whether UNIQUE_OWNERS is on, making every path that
+// sets a team's owner reject one that already has a team with
+// ErrOwnerHasTeam. Otherwise an owner may have several teams.
+func UniqueOwners() bool {
+	return truthyEnv("UNIQUE_OWNERS")
+}
+
+// checkUniqueOwner returns ErrOwnerHasTeam when UNIQUE_OWNERS is on and a
+// team other than teamID already belongs to owner, or is claimed by userID.
+// teamID is empty for a new team.
+func checkUniqueOwner(teams []models.Team, teamID, userID, owner string) error {
+	if !UniqueOwners() {
+		return nil
+	}
+	for _, team := range teams {
+		if team.ID == teamID {
+			continue
+		}
+		if (owner != "" && team.Owner == owner) || (userID != "" && team.OwnerUserID == userID) {
+			return ErrOwnerHasTeam
+		}
+	}
+	return nil
+}
+
+// checkUniqueOwnerSQL is checkUniqueOwner over the teams table, reading only
+// the teams that would clash. The caller must keep other owner changes out
+// until tx commits: SQLiteDAL holds ownerMu and PostgresDAL locks the table
+// (see checkUniqueOwnerLocked), or two transactions could both pass the
+// check before either writes.
+func checkUniqueOwnerSQL(tx *sql.Tx, teamID, userID, owner string) error {
+	if !UniqueOwners() {
+		return nil
+	}
+	var clash bool
+	err := tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM teams WHERE id <> $1 AND (($2 <> '' AND owner = $2) OR ($3 <> '' AND owner_user_id = $3)))
+	`, teamID, owner, userID).Scan(&clash)
+	if err != nil {
+		return err
+	}
+	if clash {
+		return ErrOwnerHasTeam
+	}
+	return nil
+}
+
+// checkUniqueOwnersSQL is checkUniqueOwnerSQL for the owners of a batch of
+// new teams, in one query. Blank owners are skipped.
+func checkUniqueOwnersSQL(tx *sql.Tx, owners []string) error {
+	if !UniqueOwners() {
+		return nil
+	}
+	var placeholders []string
+	var args []interface{}
+	for _, owner := range owners {
+		if owner == "" {
+			continue
+		}
+		args = append(args, owner)
+		placeholders = append(placeholders, "$"+strconv.Itoa(len(args)))
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	var clash bool
+	err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM teams WHERE owner IN (`+strings.Join(placeholders, ", ")+`))`, args...).Scan(&clash)
+	if err != nil {
+		return err
+	}
+	if clash {
+		return ErrOwnerHasTeam
+	}
+	return nil
+}
+
 func truthyEnv(name string) bool {
 	value := strings.ToLower(strings.TrimSpace(os.Getenv(name)))
 	return value == "1" || value == "true" || value == "yes" || value == "on"
@@ -28,6 +173,19 @@ func personalizePlayerForTeam(player models.Player, team models.Team) models.Pla
 	return player
 }
 
+// draftedCuddlePoints applies the adjustment for the pickNumber-th pick to
+// cuddlePoints: picks 1-6 gain 18 down to 8, picks from 13 on lose 5 and up,
+// and the result stays within 10-100.
+func draftedCuddlePoints(cuddlePoints, pickNumber int) int {
+	adjustment := 0
+	if pickNumber <= 6 {
+		adjustment = 20 - pickNumber*2
+	} else if pickNumber >= 13 {
+		adjustment = 8 - pickNumber
+	}
+	return clampInt(cuddlePoints+adjustment, 10, 100)
+}
+
 func deterministicHash(value string) uint32 {
 	hasher := fnv.New32a()
 	_, _ = hasher.Write([]byte(value))
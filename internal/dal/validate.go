@@ -0,0 +1,40 @@
+package dal
+
+import (
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// ValidateNewTeam checks a team before AddTeam, so the HTTP and gRPC APIs
+// reject the same requests. Errors match ErrValidation.
+func ValidateNewTeam(name string) error {
+	name, err := sanitizeName("team", name)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return fieldErrorf("name", "team name is required")
+	}
+	return nil
+}
+
+// ValidateNewPlayer checks a player before AddPlayer, so the HTTP and gRPC
+// APIs reject the same requests. Errors match ErrValidation.
+func ValidateNewPlayer(player *models.Player) error {
+	name, err := sanitizeName("player", player.Name)
+	if err != nil {
+		return err
+	}
+	switch {
+	case name == "":
+		return fieldErrorf("name", "player name is required")
+	case player.Points < 0:
+		return fieldErrorf("points", "points must not be negative")
+	case player.CuddlePoints < 0:
+		return fieldErrorf("cuddlePoints", "cuddle points must not be negative")
+	}
+	switch player.Tier {
+	case models.TierS, models.TierA, models.TierB, models.TierC:
+		return nil
+	}
+	return fieldErrorf("tier", "tier must be one of S, A, B or C, got %q", player.Tier)
+}
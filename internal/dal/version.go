@@ -0,0 +1,50 @@
+package dal
+
+import "database/sql"
+
+// The state version counts changes to the draft: every successful mutation
+// through DraftDAL bumps it once, and Reset bumps rather than rewinds it, so
+// a client holding an older version knows its state is stale. The SQL
+// backends keep it in the single-row state_version table, so it survives
+// restarts and replicas sharing a database agree on it.
+
+// bumpVersion advances the state version inside tx, the transaction making
+// the change, so the change and its new version commit or roll back
+// together. A missing state_version row is an error too, since the change
+// would otherwise go unversioned.
+func bumpVersion(tx *sql.Tx) error {
+	var version int64
+	return tx.QueryRow(`UPDATE state_version SET version = version + 1 WHERE id = 1 RETURNING version`).Scan(&version)
+}
+
+// loadVersion reads the state version. The query has no placeholders, so it
+// works on every SQL backend.
+func loadVersion(q sqlQueryer) (int64, error) {
+	rows, err := q.Query(`SELECT version FROM state_version WHERE id = 1`)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var version int64
+	if rows.Next() {
+		if err := rows.Scan(&version); err != nil {
+			return 0, err
+		}
+	}
+	return version, rows.Err()
+}
+
+func (m *MemoryDAL) Version() (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.version, nil
+}
+
+func (s *SQLiteDAL) Version() (int64, error) {
+	return loadVersion(s.db)
+}
+
+func (p *PostgresDAL) Version() (int64, error) {
+	return loadVersion(p.db)
+}
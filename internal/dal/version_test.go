@@ -0,0 +1,202 @@
+package dal
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// assertVersion checks that store's version moves by exactly want since
+// before, and that GetState agrees.
+func assertVersion(t *testing.T, store DraftDAL, step string, before, want int64) int64 {
+	t.Helper()
+
+	version, err := store.Version()
+	if err != nil {
+		t.Fatalf("%s: Version() failed: %v", step, err)
+	}
+	if version != before+want {
+		t.Fatalf("%s: version = %d, want %d", step, version, before+want)
+	}
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("%s: GetState() failed: %v", step, err)
+	}
+	if state.Version != version {
+		t.Fatalf("%s: GetState().Version = %d, want %d", step, state.Version, version)
+	}
+	return version
+}
+
+func assertStateVersion(t *testing.T, store DraftDAL) {
+	t.Helper()
+
+	version, err := store.Version()
+	if err != nil {
+		t.Fatalf("Version() failed: %v", err)
+	}
+	version = assertVersion(t, store, "start", version, 0)
+
+	player, err := store.AddPlayer(&models.Player{Name: "Versioned Bunny", Position: "CC", Team: "Woodland", Points: 100, Tier: models.TierA})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+	version = assertVersion(t, store, "AddPlayer", version, 1)
+
+	// A change that also writes a system message still counts once
+	team, err := store.AddTeam("Versioned Team", "", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	version = assertVersion(t, store, "AddTeam", version, 1)
+
+	if _, err := store.SetDraftMode(models.DraftModeReverseSnake); err != nil {
+		t.Fatalf("SetDraftMode() failed: %v", err)
+	}
+	version = assertVersion(t, store, "SetDraftMode", version, 1)
+
+	if err := store.DraftPlayer(player.ID, team.ID); err != nil {
+		t.Fatalf("DraftPlayer() failed: %v", err)
+	}
+	version = assertVersion(t, store, "DraftPlayer", version, 1)
+
+	// Failed changes leave it alone
+	if err := store.DraftPlayer(player.ID, team.ID); err == nil {
+		t.Fatal("DraftPlayer(again) succeeded, want an error")
+	}
+	version = assertVersion(t, store, "failed DraftPlayer", version, 0)
+
+	if _, err := store.UndoLastPick(); err != nil {
+		t.Fatalf("UndoLastPick() failed: %v", err)
+	}
+	version = assertVersion(t, store, "UndoLastPick", version, 1)
+
+	// Reset carries on from the version it found
+	if err := store.Reset(); err != nil {
+		t.Fatalf("Reset() failed: %v", err)
+	}
+	version = assertVersion(t, store, "Reset", version, 1)
+
+	if _, err := store.AddTeam("After Reset", "", "", ""); err != nil {
+		t.Fatalf("AddTeam() after Reset failed: %v", err)
+	}
+	assertVersion(t, store, "AddTeam after Reset", version, 1)
+}
+
+func TestMemoryStateVersion(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	assertStateVersion(t, NewMemoryDAL())
+}
+
+func TestSQLiteStateVersion(t *testing.T) {
+	assertStateVersion(t, newTestSQLiteDAL(t))
+}
+
+func TestPostgresStateVersion(t *testing.T) {
+	assertStateVersion(t, newTestPostgresDAL(t))
+}
+
+func TestSQLiteStateVersionSurvivesReopen(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+
+	path := filepath.Join(t.TempDir(), "draft.sqlite")
+	store, err := NewSQLiteDAL(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteDAL() failed: %v", err)
+	}
+	if _, err := store.AddTeam("Persistent", "", "", ""); err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	want, err := store.Version()
+	if err != nil {
+		t.Fatalf("Version() failed: %v", err)
+	}
+	store.db.Close()
+
+	reopened, err := NewSQLiteDAL(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteDAL(reopen) failed: %v", err)
+	}
+	if got, err := reopened.Version(); err != nil || got != want {
+		t.Fatalf("Version() after reopen = %d, %v; want %d", got, err, want)
+	}
+}
+
+func TestPostgresStateVersionIsSharedBetweenInstances(t *testing.T) {
+	first := newTestPostgresDAL(t)
+	second, err := NewPostgresDAL(os.Getenv("TEST_DATABASE_URL"))
+	if err != nil {
+		t.Fatalf("NewPostgresDAL(second) failed: %v", err)
+	}
+
+	before, err := second.Version()
+	if err != nil {
+		t.Fatalf("Version() failed: %v", err)
+	}
+	if _, err := first.AddTeam("Shared", "", "", ""); err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	if err := second.Reset(); err != nil {
+		t.Fatalf("Reset() failed: %v", err)
+	}
+	assertVersion(t, first, "after both instances changed the draft", before, 2)
+	assertVersion(t, second, "read from the other instance", before, 2)
+}
+
+// assertFailedBumpRollsBack removes the state_version row so every bump
+// fails, and checks that the changes it was part of are rolled back.
+func assertFailedBumpRollsBack(t *testing.T, store DraftDAL, db *sql.DB) {
+	t.Helper()
+
+	before, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	if _, err := db.Exec(`DELETE FROM state_version`); err != nil {
+		t.Fatalf("deleting state_version failed: %v", err)
+	}
+
+	if _, err := store.AddTeam("Unversioned", "", "", ""); err == nil {
+		t.Fatal("AddTeam() succeeded without a version to bump")
+	}
+	if _, err := store.AddPlayer(&models.Player{Name: "Unversioned Bunny", Position: "CC", Team: "Woodland", Points: 100, Tier: models.TierA}); err == nil {
+		t.Fatal("AddPlayer() succeeded without a version to bump")
+	}
+	if _, err := store.SetDraftMode(models.DraftModeReverseSnake); err == nil {
+		t.Fatal("SetDraftMode() succeeded without a version to bump")
+	}
+	if _, err := store.AddChatMessage("Unversioned hello", "user"); err == nil {
+		t.Fatal("AddChatMessage() succeeded without a version to bump")
+	}
+	if err := store.Reset(); err == nil {
+		t.Fatal("Reset() succeeded without a version to bump")
+	}
+
+	after, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() after failed bumps failed: %v", err)
+	}
+	if len(after.Teams) != len(before.Teams) || len(after.Players) != len(before.Players) || len(after.Chat) != len(before.Chat) {
+		t.Fatalf("after failed bumps: %d teams, %d players, %d messages; want %d, %d, %d",
+			len(after.Teams), len(after.Players), len(after.Chat), len(before.Teams), len(before.Players), len(before.Chat))
+	}
+	if after.Settings.Mode != before.Settings.Mode {
+		t.Fatalf("draft mode after failed bump = %q, want %q", after.Settings.Mode, before.Settings.Mode)
+	}
+}
+
+func TestSQLiteFailedBumpRollsBack(t *testing.T) {
+	store := newTestSQLiteDAL(t)
+	assertFailedBumpRollsBack(t, store, store.db)
+}
+
+func TestPostgresFailedBumpRollsBack(t *testing.T) {
+	store := newTestPostgresDAL(t)
+	t.Cleanup(func() {
+		store.db.Exec(`INSERT INTO state_version (id, version) VALUES (1, 0) ON CONFLICT (id) DO NOTHING`)
+	})
+	assertFailedBumpRollsBack(t, store, store.db)
+}
@@ -0,0 +1,149 @@
+// Package draft holds draft rules that don't depend on how the draft is
+// stored.
+package draft
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+const defaultAuctionBudget = 200
+
+// Auction rule violations. Every DAL backend returns them wrapped as
+// validation errors, so match them with errors.Is.
+var (
+	ErrNominationOpen = errors.New("a player is already up for auction")
+	ErrNoNomination   = errors.New("no player is up for auction")
+	ErrNotNominated   = errors.New("player is not up for auction")
+	ErrBidTooLow      = errors.New("bid must be higher than the current high bid")
+	ErrOverBudget     = errors.New("bid is more than the team has left to spend")
+	ErrAlreadyLeading = errors.New("team already has the high bid")
+)
+
+// AuctionEnabled reports whether DRAFT_MODE=auction replaces turn-based picks
+// with nominations and bids.
+func AuctionEnabled() bool {
+	return strings.EqualFold(strings.TrimSpace(os.Getenv("DRAFT_MODE")), "auction")
+}
+
+// AuctionBudget is each team's starting budget, read from AUCTION_BUDGET.
+func AuctionBudget() int {
+	budget, err := strconv.Atoi(strings.TrimSpace(os.Getenv("AUCTION_BUDGET")))
+	if err != nil || budget <= 0 {
+		return defaultAuctionBudget
+	}
+	return budget
+}
+
+// Sale is a player won at auction.
+type Sale struct {
+	TeamID string `json:"teamId"`
+	Price  int    `json:"price"`
+}
+
+// Auction is the state of an auction draft: at most one player is nominated
+// at a time, teams outbid each other, and the high bid wins when the
+// nomination is awarded. It is stored as JSON, so every field is exported.
+type Auction struct {
+	Budget     int                       `json:"budget"`
+	Sales      map[string]Sale           `json:"sales"` // by player ID
+	Nomination *models.AuctionNomination `json:"nomination,omitempty"`
+}
+
+// NewAuction starts an auction where every team has budget to spend.
+func NewAuction(budget int) *Auction {
+	return &Auction{Budget: budget, Sales: make(map[string]Sale)}
+}
+
+// Remaining is what teamID has left after its winning bids.
+func (a *Auction) Remaining(teamID string) int {
+	remaining := a.Budget
+	for _, sale := range a.Sales {
+		if sale.TeamID == teamID {
+			remaining -= sale.Price
+		}
+	}
+	return remaining
+}
+
+// Nominate puts playerID up for bid with teamID holding the opening bid.
+func (a *Auction) Nominate(playerID, teamID string, openingBid int, now time.Time) error {
+	if a.Nomination != nil {
+		return ErrNominationOpen
+	}
+	if openingBid < 1 {
+		return fmt.Errorf("%w: the opening bid must be at least 1", ErrBidTooLow)
+	}
+	if openingBid > a.Remaining(teamID) {
+		return ErrOverBudget
+	}
+
+	a.Nomination = &models.AuctionNomination{
+		PlayerID:    playerID,
+		NominatedBy: teamID,
+		HighBid:     openingBid,
+		HighBidder:  teamID,
+		OpenedAt:    now.UnixMilli(),
+	}
+	return nil
+}
+
+// Bid raises the high bid on playerID, which must be the nominated player so
+// a bid sent just as one auction closed can't land on the next.
+func (a *Auction) Bid(playerID, teamID string, amount int) error {
+	switch {
+	case a.Nomination == nil:
+		return ErrNoNomination
+	case a.Nomination.PlayerID != playerID:
+		return ErrNotNominated
+	case a.Nomination.HighBidder == teamID:
+		return ErrAlreadyLeading
+	case amount <= a.Nomination.HighBid:
+		return ErrBidTooLow
+	case amount > a.Remaining(teamID):
+		return ErrOverBudget
+	}
+
+	a.Nomination.HighBid = amount
+	a.Nomination.HighBidder = teamID
+	return nil
+}
+
+// Award closes the nomination, selling the player to the high bidder, and
+// returns it.
+func (a *Auction) Award() (models.AuctionNomination, error) {
+	if a.Nomination == nil {
+		return models.AuctionNomination{}, ErrNoNomination
+	}
+
+	won := *a.Nomination
+	if a.Sales == nil {
+		a.Sales = make(map[string]Sale)
+	}
+	a.Sales[won.PlayerID] = Sale{TeamID: won.HighBidder, Price: won.HighBid}
+	a.Nomination = nil
+	return won, nil
+}
+
+// Refund returns what was paid for playerID, for when its pick is undone.
+func (a *Auction) Refund(playerID string) {
+	delete(a.Sales, playerID)
+}
+
+// Apply shows each team's remaining budget and the open nomination in state.
+func (a *Auction) Apply(state *models.DraftState) {
+	for i := range state.Teams {
+		remaining := a.Remaining(state.Teams[i].ID)
+		state.Teams[i].Budget = &remaining
+	}
+	if a.Nomination != nil {
+		nomination := *a.Nomination
+		state.Auction = &nomination
+	}
+}
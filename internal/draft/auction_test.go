@@ -0,0 +1,43 @@
+package draft
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAuctionBidRules(t *testing.T) {
+	auction := NewAuction(20)
+	if err := auction.Nominate("bun", "a", 0, time.Now()); !errors.Is(err, ErrBidTooLow) {
+		t.Fatalf("Nominate() opening at 0 = %v, want %v", err, ErrBidTooLow)
+	}
+	if err := auction.Nominate("bun", "a", 4, time.Now()); err != nil {
+		t.Fatalf("Nominate() failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		playerID, teamID string
+		amount           int
+		want             error
+	}{
+		{"lamb", "b", 5, ErrNotNominated},
+		{"bun", "a", 5, ErrAlreadyLeading},
+		{"bun", "b", 4, ErrBidTooLow},
+		{"bun", "b", 21, ErrOverBudget},
+		{"bun", "b", 20, nil},
+	} {
+		if err := auction.Bid(tc.playerID, tc.teamID, tc.amount); !errors.Is(err, tc.want) {
+			t.Fatalf("Bid(%s, %s, %d) = %v, want %v", tc.playerID, tc.teamID, tc.amount, err, tc.want)
+		}
+	}
+
+	if _, err := auction.Award(); err != nil {
+		t.Fatalf("Award() failed: %v", err)
+	}
+	if auction.Remaining("b") != 0 || auction.Remaining("a") != 20 {
+		t.Fatalf("Remaining() = a %d, b %d; want 20 and 0", auction.Remaining("a"), auction.Remaining("b"))
+	}
+	if err := auction.Nominate("lamb", "b", 1, time.Now()); !errors.Is(err, ErrOverBudget) {
+		t.Fatalf("Nominate() with no budget left = %v, want %v", err, ErrOverBudget)
+	}
+}
@@ -0,0 +1,91 @@
+// Package export converts draft results into formats other fantasy tools can import.
+package export
+
+import (
+	"sort"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// Exporter renders a draft in one import format. Export's result is encoded as JSON.
+type Exporter interface {
+	// Format is the name clients pass as ?format=.
+	Format() string
+	Export(state *models.DraftState) (interface{}, error)
+}
+
+// DefaultFormat is used when no format is requested.
+const DefaultFormat = "generic"
+
+var exporters = map[string]Exporter{}
+
+// Register makes e available under e.Format(), replacing any exporter of that name.
+func Register(e Exporter) {
+	exporters[e.Format()] = e
+}
+
+// Lookup returns the exporter for format.
+func Lookup(format string) (Exporter, bool) {
+	e, ok := exporters[format]
+	return e, ok
+}
+
+// Formats lists the registered format names in order.
+func Formats() []string {
+	names := make([]string, 0, len(exporters))
+	for name := range exporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	Register(genericExporter{})
+	Register(sleeperExporter{})
+}
+
+// pick is one completed selection, shared by the formats.
+type pick struct {
+	Number   int
+	Round    int
+	TeamSlot int
+	Team     *models.Team
+	Player   *models.Player
+}
+
+// picks replays the draft order to number every drafted player. Each team's
+// roster is in pick order, so the team's nth completed turn took its nth player.
+func picks(state *models.DraftState) []pick {
+	teamSlots := make(map[string]int, len(state.Teams))
+	for i := range state.Teams {
+		teamSlots[state.Teams[i].ID] = i
+	}
+
+	taken := make(map[string]int, len(state.Teams))
+	var out []pick
+	for _, entry := range state.DraftOrder {
+		if !entry.Completed {
+			continue
+		}
+		slot, ok := teamSlots[entry.TeamID]
+		if !ok {
+			continue
+		}
+		team := &state.Teams[slot]
+		n := taken[team.ID]
+		if n >= len(team.Players) {
+			continue
+		}
+		taken[team.ID] = n + 1
+
+		out = append(out, pick{
+			Number:   entry.Pick,
+			Round:    entry.Round,
+			TeamSlot: slot + 1,
+			Team:     team,
+			Player:   &team.Players[n],
+		})
+	}
+	return out
+}
@@ -0,0 +1,114 @@
+package export
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// GenericDraft is the normalized "generic" format: draft settings, then each
+// team with its picks in order, then every pick in overall order.
+type GenericDraft struct {
+	Name  string        `json:"name"`
+	Mode  string        `json:"mode"`
+	Teams []GenericTeam `json:"teams"`
+	Picks []GenericPick `json:"picks"`
+}
+
+// GenericTeam is a team and the picks it made.
+type GenericTeam struct {
+	ID    string        `json:"id"`
+	Name  string        `json:"name"`
+	Owner string        `json:"owner"`
+	Picks []GenericPick `json:"picks"`
+}
+
+// GenericPick is one selection. Points and CuddlePoints are the player's
+// current values.
+type GenericPick struct {
+	Pick         int    `json:"pick"`
+	Round        int    `json:"round"`
+	TeamID       string `json:"teamId"`
+	PlayerID     string `json:"playerId"`
+	PlayerName   string `json:"playerName"`
+	Position     string `json:"position"`
+	Tier         string `json:"tier"`
+	Points       int    `json:"points"`
+	CuddlePoints int    `json:"cuddlePoints"`
+}
+
+type genericExporter struct{}
+
+func (genericExporter) Format() string { return "generic" }
+
+func (genericExporter) Export(state *models.DraftState) (interface{}, error) {
+	draft := GenericDraft{
+		Name:  state.Settings.Name,
+		Mode:  string(state.Settings.Mode),
+		Teams: make([]GenericTeam, len(state.Teams)),
+		Picks: []GenericPick{},
+	}
+	for i, team := range state.Teams {
+		draft.Teams[i] = GenericTeam{ID: team.ID, Name: team.Name, Owner: team.Owner, Picks: []GenericPick{}}
+	}
+
+	for _, p := range picks(state) {
+		entry := GenericPick{
+			Pick:         p.Number,
+			Round:        p.Round,
+			TeamID:       p.Team.ID,
+			PlayerID:     p.Player.ID,
+			PlayerName:   p.Player.Name,
+			Position:     p.Player.Position,
+			Tier:         string(p.Player.Tier),
+			Points:       p.Player.Points,
+			CuddlePoints: p.Player.CuddlePoints,
+		}
+		draft.Picks = append(draft.Picks, entry)
+		team := &draft.Teams[p.TeamSlot-1]
+		team.Picks = append(team.Picks, entry)
+	}
+	return draft, nil
+}
+
+// SleeperPick follows the objects in Sleeper's draft picks API
+// (GET /v1/draft/<draft_id>/picks). draft_slot and roster_id are the team's
+// 1-based position in the team order; metadata values are strings, as Sleeper
+// sends them.
+type SleeperPick struct {
+	Round     int               `json:"round"`
+	PickNo    int               `json:"pick_no"`
+	DraftSlot int               `json:"draft_slot"`
+	RosterID  int               `json:"roster_id"`
+	PlayerID  string            `json:"player_id"`
+	PickedBy  string            `json:"picked_by"`
+	Metadata  map[string]string `json:"metadata"`
+}
+
+type sleeperExporter struct{}
+
+func (sleeperExporter) Format() string { return "sleeper" }
+
+func (sleeperExporter) Export(state *models.DraftState) (interface{}, error) {
+	out := []SleeperPick{}
+	for _, p := range picks(state) {
+		firstName, lastName, _ := strings.Cut(p.Player.Name, " ")
+		out = append(out, SleeperPick{
+			Round:     p.Round,
+			PickNo:    p.Number,
+			DraftSlot: p.TeamSlot,
+			RosterID:  p.TeamSlot,
+			PlayerID:  p.Player.ID,
+			PickedBy:  p.Team.OwnerUserID,
+			Metadata: map[string]string{
+				"first_name": firstName,
+				"last_name":  lastName,
+				"position":   p.Player.Position,
+				"team":       p.Player.Team,
+				"points":     strconv.Itoa(p.Player.Points),
+			},
+		})
+	}
+	return out, nil
+}
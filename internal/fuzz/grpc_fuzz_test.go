@@ -34,7 +34,10 @@ func FuzzGRPCDraftPlayer(f *testing.F) {
 		}
 
 		// Should not panic
-		_, _ = server.DraftPlayer(context.Background(), req)
+		_, err := server.DraftPlayer(context.Background(), req)
+		assertNoGRPCLeak(t, err)
+		_, err = grpcserver.NewServer(newFailingStore(), ps).DraftPlayer(context.Background(), req)
+		assertNoGRPCLeak(t, err)
 	})
 }
 
@@ -57,7 +60,10 @@ func FuzzGRPCAddTeam(f *testing.F) {
 			Color:  color,
 		}
 
-		_, _ = server.AddTeam(context.Background(), req)
+		_, err := server.AddTeam(context.Background(), req)
+		assertNoGRPCLeak(t, err)
+		_, err = grpcserver.NewServer(newFailingStore(), ps).AddTeam(context.Background(), req)
+		assertNoGRPCLeak(t, err)
 	})
 }
 
@@ -78,7 +84,10 @@ func FuzzGRPCSendChatMessage(f *testing.F) {
 			Type: msgType,
 		}
 
-		_, _ = server.SendChatMessage(context.Background(), req)
+		_, err := server.SendChatMessage(context.Background(), req)
+		assertNoGRPCLeak(t, err)
+		_, err = grpcserver.NewServer(newFailingStore(), ps).SendChatMessage(context.Background(), req)
+		assertNoGRPCLeak(t, err)
 	})
 }
 
@@ -99,7 +108,10 @@ func FuzzGRPCSetPlayerPoints(f *testing.F) {
 			Points: points,
 		}
 
-		_, _ = server.SetPlayerPoints(context.Background(), req)
+		_, err := server.SetPlayerPoints(context.Background(), req)
+		assertNoGRPCLeak(t, err)
+		_, err = grpcserver.NewServer(newFailingStore(), ps).SetPlayerPoints(context.Background(), req)
+		assertNoGRPCLeak(t, err)
 	})
 }
 
@@ -107,15 +119,20 @@ func FuzzGRPCSetPlayerPoints(f *testing.F) {
 func FuzzGRPCAddReaction(f *testing.F) {
 	// Seed corpus
 	f.Add("msg_1", "🎉", "user1")
+	f.Add("msg_1", "lol", "user1")
+	f.Add("msg_1", "🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉", "")
 	f.Add("", "", "")
 
 	f.Fuzz(func(t *testing.T, messageId, emote, user string) {
-		dal := dal.NewMemoryDAL()
+		store := dal.NewMemoryDAL()
 		ps := pubsub.New()
-		server := grpcserver.NewServer(dal, ps)
+		server := grpcserver.NewServer(store, ps)
 
-		// Add a test message first
-		dal.AddChatMessage("Test", "user")
+		// Add a test message first; msg_1 stands for its ID
+		msg, _ := store.AddChatMessage("Test", "user")
+		if messageId == "msg_1" {
+			messageId = msg.ID
+		}
 
 		req := &pb.AddReactionRequest{
 			MessageId: messageId,
@@ -123,7 +140,11 @@ func FuzzGRPCAddReaction(f *testing.F) {
 			User:      user,
 		}
 
-		_, _ = server.AddReaction(context.Background(), req)
+		_, err := server.AddReaction(context.Background(), req)
+		assertNoGRPCLeak(t, err)
+		_, err = grpcserver.NewServer(newFailingStore(), ps).AddReaction(context.Background(), req)
+		assertNoGRPCLeak(t, err)
+		assertAllowedEmotes(t, store)
 	})
 }
 
@@ -147,7 +168,10 @@ func FuzzGRPCAddPlayer(f *testing.F) {
 			Image:    "/test.png",
 		}
 
-		_, _ = server.AddPlayer(context.Background(), req)
+		_, err := server.AddPlayer(context.Background(), req)
+		assertNoGRPCLeak(t, err)
+		_, err = grpcserver.NewServer(newFailingStore(), ps).AddPlayer(context.Background(), req)
+		assertNoGRPCLeak(t, err)
 	})
 }
 
@@ -194,6 +218,9 @@ func FuzzGRPCReorderTeams(f *testing.F) {
 			Order: order,
 		}
 
-		_, _ = server.ReorderTeams(context.Background(), req)
+		_, err := server.ReorderTeams(context.Background(), req)
+		assertNoGRPCLeak(t, err)
+		_, err = grpcserver.NewServer(newFailingStore(), ps).ReorderTeams(context.Background(), req)
+		assertNoGRPCLeak(t, err)
 	})
 }
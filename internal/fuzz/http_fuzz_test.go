@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
@@ -38,9 +40,10 @@ func FuzzHTTPDraftPick(f *testing.F) {
 
 		// Execute
 		api.DraftPick(w, req)
+		assertNoHTTPLeak(t, w)
+		assertFailingStoreDoesNotLeak(t, (*handlers.APIHandlers).DraftPick, "/api/draft/pick", data)
 
-		// Should not panic - that's the main goal of fuzzing
-		// We don't care if it returns an error, just that it doesn't crash
+		// Errors are fine, but raw database errors must not reach the client
 	})
 }
 
@@ -61,6 +64,8 @@ func FuzzHTTPAddTeam(f *testing.F) {
 		w := httptest.NewRecorder()
 
 		api.AddTeam(w, req)
+		assertNoHTTPLeak(t, w)
+		assertFailingStoreDoesNotLeak(t, (*handlers.APIHandlers).AddTeam, "/api/teams/add", data)
 	})
 }
 
@@ -81,6 +86,8 @@ func FuzzHTTPSendChat(f *testing.F) {
 		w := httptest.NewRecorder()
 
 		api.SendChatMessage(w, req)
+		assertNoHTTPLeak(t, w)
+		assertFailingStoreDoesNotLeak(t, (*handlers.APIHandlers).SendChatMessage, "/api/chat/send", data)
 	})
 }
 
@@ -101,6 +108,8 @@ func FuzzHTTPSetPlayerPoints(f *testing.F) {
 		w := httptest.NewRecorder()
 
 		api.SetPlayerPoints(w, req)
+		assertNoHTTPLeak(t, w)
+		assertFailingStoreDoesNotLeak(t, (*handlers.APIHandlers).SetPlayerPoints, "/api/players/points", data)
 	})
 }
 
@@ -121,6 +130,8 @@ func FuzzHTTPReorderTeams(f *testing.F) {
 		w := httptest.NewRecorder()
 
 		api.ReorderTeams(w, req)
+		assertNoHTTPLeak(t, w)
+		assertFailingStoreDoesNotLeak(t, (*handlers.APIHandlers).ReorderTeams, "/api/teams/reorder", data)
 	})
 }
 
@@ -128,24 +139,51 @@ func FuzzHTTPReorderTeams(f *testing.F) {
 func FuzzHTTPAddReaction(f *testing.F) {
 	// Seed corpus
 	f.Add(`{"messageId":"msg_1","emote":"🎉","user":"user1"}`)
+	f.Add(`{"messageId":"msg_1","emote":"lol","user":"user1"}`)
+	f.Add(`{"messageId":"msg_1","emote":"` + strings.Repeat("x", 200) + `","user":""}`)
 	f.Add(`{"messageId":"","emote":"","user":""}`)
 
 	f.Fuzz(func(t *testing.T, data string) {
-		dal := dal.NewMemoryDAL()
+		store := dal.NewMemoryDAL()
 		ps := pubsub.New()
-		api := handlers.NewAPIHandlers(dal, ps)
+		api := handlers.NewAPIHandlers(store, ps)
 
-		// First add a message
-		dal.AddChatMessage("Test message", "user")
+		// First add a message; msg_1 stands for its ID
+		msg, _ := store.AddChatMessage("Test message", "user")
+		data = strings.ReplaceAll(data, "msg_1", msg.ID)
 
 		req := httptest.NewRequest(http.MethodPost, "/api/chat/react", bytes.NewBufferString(data))
 		req.Header.Set("Content-Type", "application/json")
 		w := httptest.NewRecorder()
 
 		api.AddReaction(w, req)
+		assertNoHTTPLeak(t, w)
+		assertFailingStoreDoesNotLeak(t, (*handlers.APIHandlers).AddReaction, "/api/chat/react", data)
+		if w.Code == http.StatusOK {
+			assertAllowedEmotes(t, store)
+		} else if w.Code != http.StatusBadRequest && w.Code != http.StatusNotFound && w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("status = %d, want 200, 400, 404 or 422", w.Code)
+		}
 	})
 }
 
+// assertAllowedEmotes fails when a reaction outside dal.AllowedEmotes got
+// stored.
+func assertAllowedEmotes(t *testing.T, store dal.DraftDAL) {
+	t.Helper()
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	for _, msg := range state.Chat {
+		for emote := range msg.Emotes {
+			if !slices.Contains(dal.AllowedEmotes(), emote) {
+				t.Fatalf("stored reaction %q is not an allowed emote", emote)
+			}
+		}
+	}
+}
+
 // FuzzJSONParsing fuzzes general JSON parsing
 func FuzzJSONParsing(f *testing.F) {
 	// Seed various JSON structures
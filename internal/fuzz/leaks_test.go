@@ -0,0 +1,100 @@
+package fuzz
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/handlers"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/pubsub"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errDatabase stands in for a driver error that must never reach clients.
+var errDatabase = errors.New(`pq: relation "players" does not exist (canary 7f3a)`)
+
+// failingStore fails every call the fuzzed endpoints make with errDatabase.
+type failingStore struct {
+	dal.DraftDAL
+}
+
+func newFailingStore() failingStore {
+	return failingStore{DraftDAL: dal.NewMemoryDAL()}
+}
+
+func (failingStore) GetState() (*models.DraftState, error) { return nil, errDatabase }
+
+func (failingStore) DraftPlayer(playerID, teamID string) error { return errDatabase }
+
+func (failingStore) AddPlayer(*models.Player) (*models.Player, error) { return nil, errDatabase }
+
+func (failingStore) SetPlayerPoints(string, int) (*models.Player, error) { return nil, errDatabase }
+
+func (failingStore) ReorderTeams([]string) ([]models.Team, error) { return nil, errDatabase }
+
+func (failingStore) AddTeam(string, string, string, string) (*models.Team, error) {
+	return nil, errDatabase
+}
+
+func (failingStore) AddChatMessage(string, string) (*models.ChatMessage, error) {
+	return nil, errDatabase
+}
+
+func (failingStore) AddReaction(string, string, string) (*models.ChatMessage, error) {
+	return nil, errDatabase
+}
+
+// assertNoHTTPLeak fails when a response carries errDatabase, or a 500
+// says more than the generic internal error.
+func assertNoHTTPLeak(t *testing.T, w *httptest.ResponseRecorder) {
+	t.Helper()
+	if strings.Contains(w.Body.String(), errDatabase.Error()) {
+		t.Fatalf("response leaked the database error: %s", w.Body.String())
+	}
+	if w.Code != http.StatusInternalServerError {
+		return
+	}
+	var body struct {
+		Error string `json:"error"`
+		Code  string `json:"code"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Code != "internal" || body.Error != "internal server error" {
+		t.Fatalf("500 body = %s, want the generic internal error", w.Body.String())
+	}
+}
+
+// assertFailingStoreDoesNotLeak sends data to handle backed by a
+// failingStore.
+func assertFailingStoreDoesNotLeak(t *testing.T, handle func(*handlers.APIHandlers, http.ResponseWriter, *http.Request), path, data string) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(data))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handle(handlers.NewAPIHandlers(newFailingStore(), pubsub.New()), w, req)
+	assertNoHTTPLeak(t, w)
+}
+
+// assertNoGRPCLeak fails when err carries errDatabase or is not a status.
+func assertNoGRPCLeak(t *testing.T, err error) {
+	t.Helper()
+	if err == nil {
+		return
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		t.Fatalf("error %v is not a gRPC status", err)
+	}
+	if strings.Contains(st.Message(), errDatabase.Error()) {
+		t.Fatalf("status leaked the database error: %v", err)
+	}
+	if st.Code() == codes.Internal && st.Message() != "internal server error" {
+		t.Fatalf("internal status = %q, want the generic message", st.Message())
+	}
+}
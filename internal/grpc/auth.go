@@ -0,0 +1,123 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/auth"
+	pb "github.com/Billy-Davies-2/jellycat-draft-ui/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// methodScopes maps each DraftService method to the token scope it requires.
+// Methods not listed require the admin scope.
+var methodScopes = map[string]string{
+	pb.DraftService_GetState_FullMethodName:         auth.ScopeRead,
+	pb.DraftService_ListTeams_FullMethodName:        auth.ScopeRead,
+	pb.DraftService_GetPlayerProfile_FullMethodName: auth.ScopeRead,
+	pb.DraftService_ListChat_FullMethodName:         auth.ScopeRead,
+	pb.DraftService_StreamEvents_FullMethodName:     auth.ScopeRead,
+	pb.DraftService_SendChatMessage_FullMethodName:  auth.ScopeChat,
+	pb.DraftService_AddReaction_FullMethodName:      auth.ScopeChat,
+	// Picks are authorized by role and team ownership rather than scope.
+	pb.DraftService_DraftPlayer_FullMethodName: auth.ScopeRead,
+}
+
+// methodRoles applies the HTTP API role matrix to DraftService. Methods not
+// listed require a commissioner; publicMethods may be called anonymously.
+var methodRoles = map[string]auth.Role{
+	pb.DraftService_SendChatMessage_FullMethodName: auth.RoleSpectator,
+	pb.DraftService_AddReaction_FullMethodName:     auth.RoleSpectator,
+	pb.DraftService_DraftPlayer_FullMethodName:     auth.RoleOwner,
+}
+
+var publicMethods = map[string]bool{
+	pb.DraftService_GetState_FullMethodName:         true,
+	pb.DraftService_ListTeams_FullMethodName:        true,
+	pb.DraftService_GetPlayerProfile_FullMethodName: true,
+	pb.DraftService_ListChat_FullMethodName:         true,
+	pb.DraftService_StreamEvents_FullMethodName:     true,
+}
+
+// AuthInterceptors authenticate personal access tokens sent as "authorization: Bearer <token>" metadata
+// and enforce the role each method requires. Only read methods accept calls without a token.
+func AuthInterceptors(tokens *auth.TokenAuth, roles *auth.Roles) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	unary := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticateContext(ctx, tokens, roles, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+
+	stream := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticateContext(ss.Context(), tokens, roles, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+
+	return unary, stream
+}
+
+func authenticateContext(ctx context.Context, tokens *auth.TokenAuth, roles *auth.Roles, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		if publicMethods[method] {
+			return ctx, nil
+		}
+		return nil, status.Error(codes.Unauthenticated, "a bearer token is required for this method")
+	}
+
+	secret := auth.BearerToken(values[0])
+	if secret == "" {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata must be a bearer token")
+	}
+
+	user, err := tokens.Authenticate(secret)
+	if errors.Is(err, auth.ErrInvalidToken) {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	scope, ok := methodScopes[method]
+	if !ok {
+		scope = auth.ScopeAdmin
+	}
+	if !user.HasScope(scope) {
+		return nil, status.Errorf(codes.PermissionDenied, "token lacks %s scope", scope)
+	}
+
+	if !publicMethods[method] {
+		required, ok := methodRoles[method]
+		if !ok {
+			required = auth.RoleCommissioner
+		}
+		role, err := roles.RoleFor(user)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if !role.Includes(required) {
+			return nil, status.Errorf(codes.PermissionDenied, "%s role required", required)
+		}
+	}
+
+	return auth.WithUser(ctx, user), nil
+}
+
+// authenticatedStream overrides the stream context with the authenticated one.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,133 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/auth"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	pb "github.com/Billy-Davies-2/jellycat-draft-ui/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthInterceptorEnforcesTokenScopes(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("AUTH_ADMIN_CLAIM", "")
+	t.Setenv("AUTH_ADMIN_VALUE", "")
+
+	tokens := auth.NewTokenAuth(dal.NewMemoryDAL())
+	_, readOnly, err := tokens.Create(&auth.User{ID: "u1", Username: "cron"}, "cron", []string{"read"})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	unary, _ := AuthInterceptors(tokens, auth.NewRoles(dal.NewMemoryDAL()))
+
+	call := func(method, authorization string) (*auth.User, error) {
+		ctx := context.Background()
+		if authorization != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", authorization))
+		}
+		var seen *auth.User
+		_, err := unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			seen, _ = ctx.Value("user").(*auth.User)
+			return nil, nil
+		})
+		return seen, err
+	}
+
+	if user, err := call(pb.DraftService_GetState_FullMethodName, "Bearer "+readOnly); err != nil || user == nil || user.Username != "cron" {
+		t.Fatalf("GetState with read token = %+v, %v; want token owner", user, err)
+	}
+	if _, err := call(pb.DraftService_ResetDraft_FullMethodName, "Bearer "+readOnly); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("ResetDraft with read token code = %v, want %v", status.Code(err), codes.PermissionDenied)
+	}
+	if _, err := call(pb.DraftService_GetState_FullMethodName, "Bearer jcd_bogus"); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("bogus token code = %v, want %v", status.Code(err), codes.Unauthenticated)
+	}
+	if user, err := call(pb.DraftService_GetState_FullMethodName, ""); err != nil || user != nil {
+		t.Fatalf("anonymous read = %+v, %v; want pass-through", user, err)
+	}
+	if _, err := call(pb.DraftService_ResetDraft_FullMethodName, ""); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("anonymous ResetDraft code = %v, want %v", status.Code(err), codes.Unauthenticated)
+	}
+}
+
+func TestAuthInterceptorEnforcesRoleMatrix(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("AUTH_ADMIN_CLAIM", "")
+	t.Setenv("AUTH_ADMIN_VALUE", "")
+
+	store := dal.NewMemoryDAL()
+	team, err := store.AddTeam("Owned", "owner", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	if _, err := store.ClaimTeam(team.ID, "user-owner", "owner"); err != nil {
+		t.Fatalf("ClaimTeam() failed: %v", err)
+	}
+
+	tokens := auth.NewTokenAuth(store)
+	secrets := map[auth.Role]string{}
+	for role, user := range map[auth.Role]*auth.User{
+		auth.RoleSpectator:    {ID: "user-spectator", Username: "spectator"},
+		auth.RoleOwner:        {ID: "user-owner", Username: "owner"},
+		auth.RoleCommissioner: {ID: "user-commish", Username: "commish", Groups: []string{"admins"}},
+	} {
+		scopes := []string{auth.ScopeRead, auth.ScopeChat}
+		if role == auth.RoleCommissioner {
+			scopes = append(scopes, auth.ScopeAdmin)
+		}
+		_, secret, err := tokens.Create(user, string(role), scopes)
+		if err != nil {
+			t.Fatalf("Create(%s) failed: %v", role, err)
+		}
+		secrets[role] = secret
+	}
+	unary, _ := AuthInterceptors(tokens, auth.NewRoles(store))
+
+	methods := []struct {
+		method  string
+		minimum auth.Role // "" allows anonymous callers
+	}{
+		{pb.DraftService_GetState_FullMethodName, ""},
+		{pb.DraftService_ListTeams_FullMethodName, ""},
+		{pb.DraftService_GetPlayerProfile_FullMethodName, ""},
+		{pb.DraftService_ListChat_FullMethodName, ""},
+		{pb.DraftService_SendChatMessage_FullMethodName, auth.RoleSpectator},
+		{pb.DraftService_AddReaction_FullMethodName, auth.RoleSpectator},
+		{pb.DraftService_DraftPlayer_FullMethodName, auth.RoleOwner},
+		{pb.DraftService_ResetDraft_FullMethodName, auth.RoleCommissioner},
+		{pb.DraftService_AddTeam_FullMethodName, auth.RoleCommissioner},
+		{pb.DraftService_ReorderTeams_FullMethodName, auth.RoleCommissioner},
+		{pb.DraftService_AddPlayer_FullMethodName, auth.RoleCommissioner},
+		{pb.DraftService_SetPlayerPoints_FullMethodName, auth.RoleCommissioner},
+	}
+	callers := []auth.Role{"", auth.RoleSpectator, auth.RoleOwner, auth.RoleCommissioner}
+
+	for _, m := range methods {
+		for _, caller := range callers {
+			ctx := context.Background()
+			if caller != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+secrets[caller]))
+			}
+			_, err := unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: m.method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, nil
+			})
+
+			want := codes.OK
+			switch {
+			case m.minimum == "":
+			case caller == "":
+				want = codes.Unauthenticated
+			case !caller.Includes(m.minimum):
+				want = codes.PermissionDenied
+			}
+			if status.Code(err) != want {
+				t.Errorf("%s as %q code = %v, want %v", m.method, caller, status.Code(err), want)
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package grpc
+
+import (
+	"errors"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorCodes maps DAL errors to gRPC codes. It is checked in order, so
+// specific errors come before the kinds they belong to.
+var errorCodes = []struct {
+	kind error
+	code codes.Code
+}{
+	{dal.ErrNotFound, codes.NotFound},
+	{dal.ErrPlayerAlreadyDrafted, codes.AlreadyExists},
+	{dal.ErrConflict, codes.FailedPrecondition},
+	{dal.ErrValidation, codes.InvalidArgument},
+}
+
+// grpcStatusForError maps DAL errors to gRPC status errors so clients get a
+// meaningful code instead of codes.Unknown. Errors that already carry a
+// status are returned unchanged. Unmapped errors are logged and reported as
+// a bare codes.Internal, so SQL errors never reach clients.
+func grpcStatusForError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	for _, mapping := range errorCodes {
+		if errors.Is(err, mapping.kind) {
+			return status.Error(mapping.code, err.Error())
+		}
+	}
+	logger.Error("gRPC: Request failed", "error", err)
+	return status.Error(codes.Internal, "internal server error")
+}
@@ -2,50 +2,101 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"math"
-
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/auth"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/clickhouse"
 	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
 	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
 	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/profile"
 	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/pubsub"
 	pb "github.com/Billy-Davies-2/jellycat-draft-ui/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// defaultStreamKeepalive matches the SSE endpoint's keepalive.
+const defaultStreamKeepalive = 30 * time.Second
+
+// streamKeepalive is how long StreamEvents may sit idle before it sends a
+// "keepalive" event, so load balancers don't cut quiet streams. It is read
+// from GRPC_STREAM_KEEPALIVE (a Go duration such as "15s").
+func streamKeepalive() time.Duration {
+	interval, err := time.ParseDuration(strings.TrimSpace(os.Getenv("GRPC_STREAM_KEEPALIVE")))
+	if err != nil || interval <= 0 {
+		return defaultStreamKeepalive
+	}
+	return interval
+}
+
 // Server implements the gRPC DraftService
 type Server struct {
 	pb.UnimplementedDraftServiceServer
-	dal    dal.DraftDAL
-	pubsub *pubsub.PubSub
+	dal          dal.DraftDAL
+	pubsub       *pubsub.PubSub
+	reservations *dal.PickReservations
+	profiles     *profile.Service
 }
 
 // NewServer creates a new gRPC server
-func NewServer(dal dal.DraftDAL, ps *pubsub.PubSub) *Server {
+func NewServer(store dal.DraftDAL, ps *pubsub.PubSub) *Server {
 	return &Server{
-		dal:    dal,
-		pubsub: ps,
+		dal:          store,
+		pubsub:       ps,
+		reservations: dal.NewPickReservations(),
+		profiles:     profile.NewService(store, nil),
 	}
 }
 
+// UsePickReservations shares reservations with the HTTP API, so a player
+// reserved there can't be taken here.
+func (s *Server) UsePickReservations(reservations *dal.PickReservations) {
+	s.reservations = reservations
+}
+
+// UsePlayerMetrics serves player profile metrics from source instead of
+// placeholders.
+func (s *Server) UsePlayerMetrics(source clickhouse.MetricsSource) {
+	s.profiles = profile.NewService(s.dal, source)
+}
+
 // GetState returns the current draft state
 func (s *Server) GetState(ctx context.Context, req *pb.Empty) (*pb.DraftState, error) {
 	logger.Debug("gRPC: Getting draft state")
 	state, err := s.dal.GetState()
 	if err != nil {
 		logger.Error("gRPC: Failed to get draft state", "error", err)
-		return nil, err
+		return nil, grpcStatusForError(err)
 	}
 
+	// Backends return players in insertion order; match the HTTP API's default.
+	sort.SliceStable(state.Players, func(i, j int) bool {
+		return state.Players[i].Points > state.Players[j].Points
+	})
 	return modelsToPbDraftState(state), nil
 }
 
 // DraftPlayer drafts a player to a team
 func (s *Server) DraftPlayer(ctx context.Context, req *pb.DraftPlayerRequest) (*pb.DraftPlayerResponse, error) {
 	logger.Info("gRPC: Drafting player", "player_id", req.PlayerId, "team_id", req.TeamId)
-	err := s.dal.DraftPlayer(req.PlayerId, req.TeamId)
+	if err := s.authorizePick(ctx, req.TeamId); err != nil {
+		s.publishPickFailure(ctx, req, err)
+		return &pb.DraftPlayerResponse{Success: false}, err
+	}
+
+	err := s.reservations.Draft(s.dal, req.PlayerId, req.TeamId)
 	if err != nil {
 		logger.Error("gRPC: Failed to draft player", "error", err, "player_id", req.PlayerId, "team_id", req.TeamId)
-		return &pb.DraftPlayerResponse{Success: false}, err
+		s.publishPickFailure(ctx, req, err)
+		return &pb.DraftPlayerResponse{Success: false}, grpcStatusForError(err)
 	}
 
 	s.pubsub.Publish(pubsub.Event{
@@ -55,17 +106,80 @@ func (s *Server) DraftPlayer(ctx context.Context, req *pb.DraftPlayerRequest) (*
 			"teamId":   req.TeamId,
 		},
 	})
+	s.publishOnClock()
 
 	return &pb.DraftPlayerResponse{Success: true}, nil
 }
 
-// ResetDraft resets the draft
+// publishOnClock announces the team now on the clock. The pick already
+// succeeded, so failures are only logged.
+func (s *Server) publishOnClock() {
+	state, err := s.dal.GetState()
+	if err != nil {
+		logger.Warn("gRPC: Failed to load draft state for on-the-clock event", "error", err)
+		return
+	}
+	window, err := s.dal.GetDraftWindow()
+	if err != nil {
+		logger.Warn("gRPC: Failed to load draft window for on-the-clock event", "error", err)
+		return
+	}
+	if event, ok := pubsub.NewOnClockEvent(state, window, time.Now()); ok {
+		s.pubsub.Publish(event)
+	}
+}
+
+// publishPickFailure sends the caller's streams an error event for a rejected
+// pick. Calls without a user have no streams to address.
+func (s *Server) publishPickFailure(ctx context.Context, req *pb.DraftPlayerRequest, err error) {
+	user := auth.UserFromContext(ctx)
+	if user == nil {
+		return
+	}
+	if st, ok := status.FromError(err); ok {
+		err = errors.New(st.Message())
+	}
+	event := pubsub.NewErrorEvent(user.ID, "draft:pick", err)
+	event.Payload["playerId"] = req.PlayerId
+	event.Payload["teamId"] = req.TeamId
+	s.pubsub.Publish(event)
+}
+
+// authorizePick restricts authenticated callers to the team they own unless
+// they are a commissioner. Calls without a user are left to the interceptor.
+func (s *Server) authorizePick(ctx context.Context, teamID string) error {
+	user := auth.UserFromContext(ctx)
+	if user == nil {
+		return nil
+	}
+
+	state, err := s.dal.GetState()
+	if err != nil {
+		return grpcStatusForError(err)
+	}
+	for _, team := range state.Teams {
+		if team.ID == teamID {
+			if !auth.CanPickFor(user, team) {
+				return status.Error(codes.PermissionDenied, "only the team owner or a commissioner may pick for this team")
+			}
+			return nil
+		}
+	}
+	return grpcStatusForError(dal.ErrTeamNotFound)
+}
+
+// ResetDraft resets the draft. Like the HTTP route it is for commissioners;
+// calls without a user are left to the interceptor.
 func (s *Server) ResetDraft(ctx context.Context, req *pb.Empty) (*pb.Empty, error) {
+	if user := auth.UserFromContext(ctx); user != nil && !auth.IsAdmin(user) {
+		return nil, status.Error(codes.PermissionDenied, "commissioner role required")
+	}
+
 	logger.Info("gRPC: Resetting draft")
 	err := s.dal.Reset()
 	if err != nil {
 		logger.Error("gRPC: Failed to reset draft", "error", err)
-		return nil, err
+		return nil, grpcStatusForError(err)
 	}
 
 	s.pubsub.Publish(pubsub.Event{Type: "draft:reset"})
@@ -74,9 +188,13 @@ func (s *Server) ResetDraft(ctx context.Context, req *pb.Empty) (*pb.Empty, erro
 
 // AddTeam adds a new team
 func (s *Server) AddTeam(ctx context.Context, req *pb.AddTeamRequest) (*pb.Team, error) {
+	if err := dal.ValidateNewTeam(req.Name); err != nil {
+		return nil, grpcStatusForError(err)
+	}
+
 	team, err := s.dal.AddTeam(req.Name, req.Owner, req.Mascot, req.Color)
 	if err != nil {
-		return nil, err
+		return nil, grpcStatusForError(err)
 	}
 
 	s.pubsub.Publish(pubsub.Event{
@@ -93,7 +211,7 @@ func (s *Server) AddTeam(ctx context.Context, req *pb.AddTeamRequest) (*pb.Team,
 func (s *Server) ListTeams(ctx context.Context, req *pb.Empty) (*pb.TeamsResponse, error) {
 	state, err := s.dal.GetState()
 	if err != nil {
-		return nil, err
+		return nil, grpcStatusForError(err)
 	}
 
 	teams := make([]*pb.Team, len(state.Teams))
@@ -108,7 +226,7 @@ func (s *Server) ListTeams(ctx context.Context, req *pb.Empty) (*pb.TeamsRespons
 func (s *Server) ReorderTeams(ctx context.Context, req *pb.ReorderTeamsRequest) (*pb.TeamsResponse, error) {
 	teams, err := s.dal.ReorderTeams(req.Order)
 	if err != nil {
-		return nil, err
+		return nil, grpcStatusForError(err)
 	}
 
 	s.pubsub.Publish(pubsub.Event{Type: "teams:reorder"})
@@ -124,9 +242,13 @@ func (s *Server) ReorderTeams(ctx context.Context, req *pb.ReorderTeamsRequest)
 // AddPlayer adds a new player
 func (s *Server) AddPlayer(ctx context.Context, req *pb.Player) (*pb.Player, error) {
 	player := pbToModelsPlayer(req)
+	if err := dal.ValidateNewPlayer(player); err != nil {
+		return nil, grpcStatusForError(err)
+	}
+
 	result, err := s.dal.AddPlayer(player)
 	if err != nil {
-		return nil, err
+		return nil, grpcStatusForError(err)
 	}
 
 	s.pubsub.Publish(pubsub.Event{
@@ -143,7 +265,7 @@ func (s *Server) AddPlayer(ctx context.Context, req *pb.Player) (*pb.Player, err
 func (s *Server) SetPlayerPoints(ctx context.Context, req *pb.SetPlayerPointsRequest) (*pb.Player, error) {
 	player, err := s.dal.SetPlayerPoints(req.Id, int(req.Points))
 	if err != nil {
-		return nil, err
+		return nil, grpcStatusForError(err)
 	}
 
 	s.pubsub.Publish(pubsub.Event{
@@ -159,34 +281,15 @@ func (s *Server) SetPlayerPoints(ctx context.Context, req *pb.SetPlayerPointsReq
 
 // GetPlayerProfile returns extended player information
 func (s *Server) GetPlayerProfile(ctx context.Context, req *pb.GetPlayerProfileRequest) (*pb.PlayerProfile, error) {
-	state, err := s.dal.GetState()
-	if err != nil {
-		return nil, err
-	}
-
-	var player *models.Player
-	for _, p := range state.Players {
-		if p.ID == req.Id {
-			player = &p
-			break
-		}
-	}
-
-	if player == nil {
-		return nil, nil
+	player, err := s.profiles.Get(ctx, req.Id)
+	if errors.Is(err, profile.ErrMetricsUnavailable) {
+		return nil, status.Error(codes.Unavailable, err.Error())
 	}
-
-	// Generate mock metrics
-	seed := player.Points
-	for _, c := range player.ID {
-		seed += int(c)
-	}
-
-	norm := func(x int) int32 {
-		return int32(math.Max(0, math.Min(100, float64(x))))
+	if err != nil {
+		return nil, grpcStatusForError(err)
 	}
 
-	profile := &pb.PlayerProfile{
+	return &pb.PlayerProfile{
 		Id:        player.ID,
 		Name:      player.Name,
 		Position:  player.Position,
@@ -197,21 +300,22 @@ func (s *Server) GetPlayerProfile(ctx context.Context, req *pb.GetPlayerProfileR
 		DraftedBy: player.DraftedBy,
 		Image:     player.Image,
 		Metrics: &pb.PlayerMetrics{
-			Consistency: norm((seed * 13) % 101),
-			Popularity:  norm((seed * 29) % 101),
-			Efficiency:  norm((seed * 47) % 101),
-			TrendDelta:  float64(((seed%15)-7)/7.0) * 100 / 100,
+			Consistency: int32(player.Metrics.Consistency),
+			Popularity:  int32(player.Metrics.Popularity),
+			Efficiency:  int32(player.Metrics.Efficiency),
+			TrendDelta:  player.Metrics.TrendDelta,
 		},
-	}
-
-	return profile, nil
+	}, nil
 }
 
 // ListChat returns all chat messages
 func (s *Server) ListChat(ctx context.Context, req *pb.Empty) (*pb.ChatResponse, error) {
+	if !dal.ChatEnabled() {
+		return nil, grpcStatusForError(dal.ErrChatDisabled)
+	}
 	state, err := s.dal.GetState()
 	if err != nil {
-		return nil, err
+		return nil, grpcStatusForError(err)
 	}
 
 	messages := make([]*pb.ChatMessage, len(state.Chat))
@@ -224,14 +328,15 @@ func (s *Server) ListChat(ctx context.Context, req *pb.Empty) (*pb.ChatResponse,
 
 // SendChatMessage sends a new chat message
 func (s *Server) SendChatMessage(ctx context.Context, req *pb.SendChatRequest) (*pb.ChatMessage, error) {
-	msgType := req.Type
-	if msgType == "" {
-		msgType = "user"
+	user := auth.UserFromContext(ctx)
+	msgType, err := auth.ChatMessageType(user, req.Type)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
 	}
 
 	msg, err := s.dal.AddChatMessage(req.Text, msgType)
 	if err != nil {
-		return nil, err
+		return nil, grpcStatusForError(err)
 	}
 
 	s.pubsub.Publish(pubsub.Event{
@@ -246,9 +351,9 @@ func (s *Server) SendChatMessage(ctx context.Context, req *pb.SendChatRequest) (
 
 // AddReaction adds a reaction to a chat message
 func (s *Server) AddReaction(ctx context.Context, req *pb.AddReactionRequest) (*pb.ChatMessage, error) {
-	msg, err := s.dal.AddReaction(req.MessageId, req.Emote, req.User)
+	msg, err := s.dal.AddReaction(req.MessageId, req.Emote, auth.ReactionUserID(auth.UserFromContext(ctx), req.User))
 	if err != nil {
-		return nil, err
+		return nil, grpcStatusForError(err)
 	}
 
 	s.pubsub.Publish(pubsub.Event{
@@ -265,9 +370,14 @@ func (s *Server) AddReaction(ctx context.Context, req *pb.AddReactionRequest) (*
 // StreamEvents streams events to clients
 func (s *Server) StreamEvents(req *pb.Empty, stream pb.DraftService_StreamEventsServer) error {
 	logger.Debug("gRPC: New client connected to event stream")
-	eventChan := s.pubsub.Subscribe()
+	var userID string
+	if user := auth.UserFromContext(stream.Context()); user != nil {
+		userID = user.ID
+	}
+	eventChan := s.pubsub.SubscribeFiltered(pubsub.Audience(userID))
 	defer s.pubsub.Unsubscribe(eventChan)
 
+	keepalive := streamKeepalive()
 	for {
 		select {
 		case event := <-eventChan:
@@ -275,6 +385,9 @@ func (s *Server) StreamEvents(req *pb.Empty, stream pb.DraftService_StreamEvents
 			for k, v := range event.Payload {
 				payload[k] = fmt.Sprint(v)
 			}
+			if event.Version > 0 {
+				payload["version"] = strconv.FormatInt(event.Version, 10)
+			}
 			pbEvent := &pb.Event{
 				Type:    event.Type,
 				Payload: payload,
@@ -286,6 +399,11 @@ func (s *Server) StreamEvents(req *pb.Empty, stream pb.DraftService_StreamEvents
 		case <-stream.Context().Done():
 			logger.Debug("gRPC: Client disconnected from event stream")
 			return nil
+		case <-time.After(keepalive):
+			if err := stream.Send(&pb.Event{Type: "keepalive"}); err != nil {
+				logger.Error("gRPC: Failed to send keepalive to stream", "error", err)
+				return err
+			}
 		}
 	}
 }
@@ -302,9 +420,19 @@ func modelsToPbPlayer(p *models.Player) *pb.Player {
 		Drafted:   p.Drafted,
 		DraftedBy: p.DraftedBy,
 		Image:     p.Image,
+		CreatedAt: pbTimestamp(p.CreatedAt),
+		UpdatedAt: pbTimestamp(p.UpdatedAt),
 	}
 }
 
+// pbTimestamp leaves unset times unset rather than sending the Unix epoch.
+func pbTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
 func pbToModelsPlayer(p *pb.Player) *models.Player {
 	return &models.Player{
 		ID:        p.Id,
@@ -326,12 +454,14 @@ func modelsToPbTeam(t *models.Team) *pb.Team {
 	}
 
 	return &pb.Team{
-		Id:      t.ID,
-		Name:    t.Name,
-		Owner:   t.Owner,
-		Mascot:  t.Mascot,
-		Color:   t.Color,
-		Players: players,
+		Id:        t.ID,
+		Name:      t.Name,
+		Owner:     t.Owner,
+		Mascot:    t.Mascot,
+		Color:     t.Color,
+		Players:   players,
+		CreatedAt: pbTimestamp(t.CreatedAt),
+		UpdatedAt: pbTimestamp(t.UpdatedAt),
 	}
 }
 
@@ -342,11 +472,14 @@ func modelsToPbChatMessage(m *models.ChatMessage) *pb.ChatMessage {
 	}
 
 	return &pb.ChatMessage{
-		Id:     m.ID,
-		Ts:     m.TS,
-		Type:   m.Type,
-		Text:   m.Text,
-		Emotes: emotes,
+		Id:        m.ID,
+		Ts:        m.TS,
+		Type:      m.Type,
+		Text:      m.Text,
+		Sender:    m.Sender,
+		Emotes:    emotes,
+		CreatedAt: pbTimestamp(m.CreatedAt),
+		UpdatedAt: pbTimestamp(m.UpdatedAt),
 	}
 }
 
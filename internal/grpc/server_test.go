@@ -0,0 +1,357 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/auth"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/handlers"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/mocks"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/pubsub"
+	pb "github.com/Billy-Davies-2/jellycat-draft-ui/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	t.Setenv("ENVIRONMENT", "production")
+	logger.Init()
+
+	return NewServer(dal.NewMemoryDAL(), pubsub.New())
+}
+
+func TestGetPlayerProfileReturnsNotFoundForMissingPlayer(t *testing.T) {
+	server := newTestServer(t)
+
+	profile, err := server.GetPlayerProfile(context.Background(), &pb.GetPlayerProfileRequest{Id: "missing-player"})
+	if profile != nil {
+		t.Fatalf("GetPlayerProfile() = %v, want nil profile", profile)
+	}
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("GetPlayerProfile() code = %v, want %v (err: %v)", status.Code(err), codes.NotFound, err)
+	}
+}
+
+func TestDraftPlayerReturnsStatusCodes(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	team, err := server.AddTeam(ctx, &pb.AddTeamRequest{Name: "First", Owner: "First"})
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	player, err := server.AddPlayer(ctx, &pb.Player{Name: "Repeat Pick", Position: "CC", Team: "Test", Points: 10, Tier: "B"})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+
+	_, err = server.DraftPlayer(ctx, &pb.DraftPlayerRequest{PlayerId: "missing-player", TeamId: team.Id})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("DraftPlayer(missing player) code = %v, want %v", status.Code(err), codes.NotFound)
+	}
+
+	if _, err := server.DraftPlayer(ctx, &pb.DraftPlayerRequest{PlayerId: player.Id, TeamId: team.Id}); err != nil {
+		t.Fatalf("DraftPlayer() failed: %v", err)
+	}
+	_, err = server.DraftPlayer(ctx, &pb.DraftPlayerRequest{PlayerId: player.Id, TeamId: team.Id})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("DraftPlayer(repeat) code = %v, want %v", status.Code(err), codes.AlreadyExists)
+	}
+}
+
+func TestDraftPlayerHonorsReservationsMadeOverHTTP(t *testing.T) {
+	server := newTestServer(t)
+	reservations := dal.NewPickReservations()
+	server.UsePickReservations(reservations)
+	ctx := context.Background()
+
+	first, _ := server.AddTeam(ctx, &pb.AddTeamRequest{Name: "First"})
+	second, _ := server.AddTeam(ctx, &pb.AddTeamRequest{Name: "Second"})
+	player, err := server.AddPlayer(ctx, &pb.Player{Name: "Held Pick", Position: "CC", Team: "Test", Points: 10, Tier: "B"})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+	if _, err := reservations.Reserve(server.dal, player.Id, first.Id); err != nil {
+		t.Fatalf("Reserve() failed: %v", err)
+	}
+
+	_, err = server.DraftPlayer(ctx, &pb.DraftPlayerRequest{PlayerId: player.Id, TeamId: second.Id})
+	if st := status.Convert(err); st.Code() != codes.FailedPrecondition || st.Message() != dal.ErrPlayerReserved.Error() {
+		t.Fatalf("DraftPlayer(reserved by another team) = %v, want %v %q", err, codes.FailedPrecondition, dal.ErrPlayerReserved)
+	}
+	if _, err := server.DraftPlayer(ctx, &pb.DraftPlayerRequest{PlayerId: player.Id, TeamId: first.Id}); err != nil {
+		t.Fatalf("DraftPlayer() by the reserving team failed: %v", err)
+	}
+}
+
+func TestGRPCStatusForErrorHidesInternalErrors(t *testing.T) {
+	for _, tc := range []struct {
+		err     error
+		code    codes.Code
+		message string
+	}{
+		{dal.ErrTeamAlreadyClaimed, codes.FailedPrecondition, "team is already claimed by another user"},
+		{dal.ErrMessageNotFound, codes.NotFound, "message not found"},
+		{errors.New("sql: database is closed"), codes.Internal, "internal server error"},
+	} {
+		st := status.Convert(grpcStatusForError(tc.err))
+		if st.Code() != tc.code || st.Message() != tc.message {
+			t.Errorf("grpcStatusForError(%v) = %v %q, want %v %q", tc.err, st.Code(), st.Message(), tc.code, tc.message)
+		}
+	}
+}
+
+func TestMessagesCarryTimestamps(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	team, err := server.AddTeam(ctx, &pb.AddTeamRequest{Name: "Stamped", Owner: "Sarah"})
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	if team.GetCreatedAt() == nil || !team.GetUpdatedAt().AsTime().Equal(team.GetCreatedAt().AsTime()) {
+		t.Fatalf("AddTeam() times = %v/%v, want equal and set", team.GetCreatedAt(), team.GetUpdatedAt())
+	}
+	if _, err := server.AddPlayer(ctx, &pb.Player{Name: "Stamped", Position: "CC", Team: "Test", Points: 10, Tier: "B"}); err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+
+	state, err := server.GetState(ctx, &pb.Empty{})
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	for _, player := range state.Players {
+		if player.GetCreatedAt() == nil || player.GetUpdatedAt() == nil {
+			t.Errorf("player %s times = %v/%v, want set", player.Id, player.GetCreatedAt(), player.GetUpdatedAt())
+		}
+	}
+	for _, msg := range state.Chat {
+		if msg.GetCreatedAt().AsTime().UnixMilli() != msg.Ts {
+			t.Errorf("chat %s created_at = %v, want ts %d", msg.Id, msg.GetCreatedAt(), msg.Ts)
+		}
+	}
+}
+
+func TestAddTeamAndPlayerRejectInvalidInputLikeHTTP(t *testing.T) {
+	server := newTestServer(t)
+	api := handlers.NewAPIHandlers(dal.NewMemoryDAL(), pubsub.New())
+	ctx := context.Background()
+
+	_, err := server.AddTeam(ctx, &pb.AddTeamRequest{Name: "  ", Owner: "Nobody"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("AddTeam() code = %v, want %v (err: %v)", status.Code(err), codes.InvalidArgument, err)
+	}
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/api/teams/add", strings.NewReader(`{"name":"  ","owner":"Nobody"}`))
+	request.Header.Set("Content-Type", "application/json")
+	api.AddTeam(recorder, request)
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("HTTP AddTeam status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+
+	_, err = server.AddPlayer(ctx, &pb.Player{Name: "Tierless", Position: "CC", Team: "Test", Points: 10, Tier: "Z"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("AddPlayer() code = %v, want %v (err: %v)", status.Code(err), codes.InvalidArgument, err)
+	}
+	recorder = httptest.NewRecorder()
+	api.AddPlayer(recorder, httptest.NewRequest(http.MethodPost, "/api/players/add", strings.NewReader(`{"name":"Tierless","position":"CC","team":"Test","points":10,"tier":"Z"}`)))
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("HTTP AddPlayer status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGetPlayerProfileMatchesHTTP(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	logger.Init()
+	store := dal.NewMemoryDAL()
+	events := pubsub.New()
+	server := NewServer(store, events)
+	api := handlers.NewAPIHandlers(store, events)
+	var ids []string
+	for _, name := range []string{"Parity Bun", "Parity Bear", "Parity Lamb"} {
+		player, err := store.AddPlayer(&models.Player{Name: name, Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB})
+		if err != nil {
+			t.Fatalf("AddPlayer() failed: %v", err)
+		}
+		ids = append(ids, player.ID)
+	}
+
+	compare := func(t *testing.T, id string) {
+		t.Helper()
+		got, err := server.GetPlayerProfile(context.Background(), &pb.GetPlayerProfileRequest{Id: id})
+		if err != nil {
+			t.Fatalf("GetPlayerProfile() failed: %v", err)
+		}
+		recorder := httptest.NewRecorder()
+		api.GetPlayerProfile(recorder, httptest.NewRequest(http.MethodGet, "/api/players/profile?id="+id, nil))
+		var want models.PlayerProfile
+		if err := json.Unmarshal(recorder.Body.Bytes(), &want); err != nil {
+			t.Fatalf("decode HTTP profile: %v (status %d)", err, recorder.Code)
+		}
+		if got.Id != want.ID || got.Name != want.Name || got.Points != int32(want.Points) ||
+			got.Metrics.Consistency != int32(want.Metrics.Consistency) ||
+			got.Metrics.Popularity != int32(want.Metrics.Popularity) ||
+			got.Metrics.Efficiency != int32(want.Metrics.Efficiency) ||
+			got.Metrics.TrendDelta != want.Metrics.TrendDelta {
+			t.Fatalf("gRPC profile %+v differs from HTTP profile %+v", got, want)
+		}
+	}
+
+	for _, id := range ids {
+		compare(t, id)
+	}
+
+	metrics := mocks.NewMockClickHouseClient()
+	server.UsePlayerMetrics(metrics)
+	api.UsePlayerMetrics(metrics)
+	for _, id := range ids {
+		compare(t, id)
+	}
+}
+
+func TestDraftPlayerRestrictsOwnersToTheirTeam(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	owned, err := server.AddTeam(ctx, &pb.AddTeamRequest{Name: "Owned"})
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	if _, err := server.dal.ClaimTeam(owned.Id, "user-owner", "owner"); err != nil {
+		t.Fatalf("ClaimTeam() failed: %v", err)
+	}
+	other, err := server.AddTeam(ctx, &pb.AddTeamRequest{Name: "Other"})
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	if _, err := server.dal.ClaimTeam(other.Id, "user-other", "other"); err != nil {
+		t.Fatalf("ClaimTeam() failed: %v", err)
+	}
+	player, err := server.AddPlayer(ctx, &pb.Player{Name: "Owned Pick", Position: "CC", Team: "Test", Points: 10, Tier: "B"})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+
+	asOther := auth.WithUser(ctx, &auth.User{ID: "user-other", Username: "other"})
+	_, err = server.DraftPlayer(asOther, &pb.DraftPlayerRequest{PlayerId: player.Id, TeamId: owned.Id})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("DraftPlayer(other owner) code = %v, want %v", status.Code(err), codes.PermissionDenied)
+	}
+
+	asOwner := auth.WithUser(ctx, &auth.User{ID: "user-owner", Username: "owner"})
+	if _, err := server.DraftPlayer(asOwner, &pb.DraftPlayerRequest{PlayerId: player.Id, TeamId: owned.Id}); err != nil {
+		t.Fatalf("DraftPlayer(owner) failed: %v", err)
+	}
+}
+
+func TestChatAndResetUseCallerIdentity(t *testing.T) {
+	t.Setenv("AUTH_ADMIN_CLAIM", "")
+	t.Setenv("AUTH_ADMIN_VALUE", "")
+	server := newTestServer(t)
+	asSpectator := auth.WithUser(context.Background(), &auth.User{ID: "user-spectator"})
+	asOther := auth.WithUser(context.Background(), &auth.User{ID: "user-other"})
+	asCommissioner := auth.WithUser(context.Background(), &auth.User{ID: "user-commish", Groups: []string{"admins"}})
+
+	if _, err := server.SendChatMessage(asSpectator, &pb.SendChatRequest{Text: "Pick is in", Type: "system"}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("spectator system message code = %v, want %v", status.Code(err), codes.PermissionDenied)
+	}
+	msg, err := server.SendChatMessage(asSpectator, &pb.SendChatRequest{Text: "Go bunnies"})
+	if err != nil || msg.Type != "user" {
+		t.Fatalf("SendChatMessage() = %v, %v; want a user message", msg, err)
+	}
+	if _, err := server.SendChatMessage(asCommissioner, &pb.SendChatRequest{Text: "Round two", Type: "system"}); err != nil {
+		t.Fatalf("commissioner system message failed: %v", err)
+	}
+
+	// Reactions count once per caller, whatever user the request claims.
+	for _, ctx := range []context.Context{asSpectator, asSpectator, asOther} {
+		if msg, err = server.AddReaction(ctx, &pb.AddReactionRequest{MessageId: msg.Id, Emote: "🎉", User: "spoofed"}); err != nil {
+			t.Fatalf("AddReaction() failed: %v", err)
+		}
+	}
+	if msg.Emotes["🎉"] != 2 {
+		t.Fatalf("reaction count = %d, want one per caller (2)", msg.Emotes["🎉"])
+	}
+	if _, err := server.AddReaction(asSpectator, &pb.AddReactionRequest{MessageId: msg.Id, Emote: "lol"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("unknown emote code = %v, want %v", status.Code(err), codes.InvalidArgument)
+	}
+
+	if _, err := server.ResetDraft(asSpectator, &pb.Empty{}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("spectator ResetDraft code = %v, want %v", status.Code(err), codes.PermissionDenied)
+	}
+	if _, err := server.ResetDraft(asCommissioner, &pb.Empty{}); err != nil {
+		t.Fatalf("commissioner ResetDraft failed: %v", err)
+	}
+}
+
+func TestGetPlayerProfileNotFoundOverTheWire(t *testing.T) {
+	server := newTestServer(t)
+
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	pb.RegisterDraftServiceServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+	defer conn.Close()
+
+	profile, err := pb.NewDraftServiceClient(conn).GetPlayerProfile(context.Background(), &pb.GetPlayerProfileRequest{Id: "missing-player"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("GetPlayerProfile() code = %v, want %v (profile %v)", status.Code(err), codes.NotFound, profile)
+	}
+}
+
+func TestStreamEventsSendsKeepalive(t *testing.T) {
+	t.Setenv("GRPC_STREAM_KEEPALIVE", "50ms")
+	server := newTestServer(t)
+
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	pb.RegisterDraftServiceServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	stream, err := pb.NewDraftServiceClient(conn).StreamEvents(ctx, &pb.Empty{})
+	if err != nil {
+		t.Fatalf("StreamEvents() failed: %v", err)
+	}
+
+	started := time.Now()
+	event, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv() failed: %v", err)
+	}
+	if event.Type != "keepalive" || time.Since(started) > 500*time.Millisecond {
+		t.Fatalf("got %q after %s, want a keepalive within the 50ms interval", event.Type, time.Since(started))
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/pubsub"
+)
+
+// NominatePlayer puts a player up for bid in an auction draft
+// (DRAFT_MODE=auction), with the nominating team holding the opening bid.
+func (h *APIHandlers) NominatePlayer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		PlayerID   string `json:"playerId"`
+		TeamID     string `json:"teamId"`
+		OpeningBid int    `json:"openingBid"`
+		Code       string `json:"code"`
+		RoomCode   string `json:"roomCode"`
+	}
+	if err := DecodeJSON(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.authorizePick(r, req.TeamID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	nomination, err := h.dal.NominatePlayer(req.PlayerID, req.TeamID, req.OpeningBid)
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("Failed to nominate player", "error", err, "player_id", req.PlayerID, "team_id", req.TeamID)
+		writeError(w, r, err)
+		return
+	}
+
+	h.publishAuction("auction:nominate", nomination)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(nomination)
+}
+
+// PlaceBid raises the high bid on the nominated player for a team.
+func (h *APIHandlers) PlaceBid(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		PlayerID string `json:"playerId"`
+		TeamID   string `json:"teamId"`
+		Amount   int    `json:"amount"`
+		Code     string `json:"code"`
+		RoomCode string `json:"roomCode"`
+	}
+	if err := DecodeJSON(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.authorizePick(r, req.TeamID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	nomination, err := h.dal.PlaceBid(req.PlayerID, req.TeamID, req.Amount)
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("Rejected bid", "error", err, "player_id", req.PlayerID, "team_id", req.TeamID, "amount", req.Amount)
+		writeError(w, r, err)
+		return
+	}
+
+	h.publishAuction("auction:bid", nomination)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(nomination)
+}
+
+// AwardPlayer closes bidding and drafts the nominated player to the high
+// bidder.
+func (h *APIHandlers) AwardPlayer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	won, err := h.dal.AwardPlayer()
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to award player", "error", err)
+		writeError(w, r, err)
+		return
+	}
+
+	h.publishAuction("auction:award", won)
+	h.pubsub.Publish(pubsub.Event{
+		Type: "draft:pick",
+		Payload: map[string]interface{}{
+			"playerId": won.PlayerID,
+			"teamId":   won.HighBidder,
+		},
+	})
+	h.publishSystemChat()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(won)
+}
+
+func (h *APIHandlers) publishAuction(eventType string, nomination *models.AuctionNomination) {
+	h.pubsub.Publish(pubsub.Event{
+		Type: eventType,
+		Payload: map[string]interface{}{
+			"playerId":    nomination.PlayerID,
+			"nominatedBy": nomination.NominatedBy,
+			"highBid":     nomination.HighBid,
+			"highBidder":  nomination.HighBidder,
+		},
+	})
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+)
+
+const (
+	defaultAuthEventLimit = 100
+	maxAuthEventLimit     = 1000
+)
+
+// AuthEventHandlers serves the authentication audit log
+type AuthEventHandlers struct {
+	store dal.AuthEventStore
+}
+
+// NewAuthEventHandlers creates a new audit log handlers instance
+func NewAuthEventHandlers(store dal.AuthEventStore) *AuthEventHandlers {
+	return &AuthEventHandlers{store: store}
+}
+
+// ListAuthEvents returns audit events, newest first. Optional filters:
+// type, user (ID or username), ip, since and until (Unix milliseconds) and
+// limit (default 100, max 1000).
+func (h *AuthEventHandlers) ListAuthEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := dal.AuthEventFilter{
+		Type:  query.Get("type"),
+		User:  query.Get("user"),
+		IP:    query.Get("ip"),
+		Limit: defaultAuthEventLimit,
+	}
+
+	var err error
+	if filter.Since, err = parseMillis(query.Get("since")); err != nil {
+		http.Error(w, "since must be a Unix millisecond timestamp", http.StatusBadRequest)
+		return
+	}
+	if filter.Until, err = parseMillis(query.Get("until")); err != nil {
+		http.Error(w, "until must be a Unix millisecond timestamp", http.StatusBadRequest)
+		return
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = min(limit, maxAuthEventLimit)
+	}
+
+	events, err := h.store.ListAuthEvents(filter)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to list auth events", "error", err)
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
+
+func parseMillis(raw string) (int64, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(raw, 10, 64)
+}
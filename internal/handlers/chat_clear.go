@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/pubsub"
+)
+
+// ClearChat hides every chat message. The clear can be undone with
+// RestoreChat until the grace period (CHAT_CLEAR_GRACE) passes.
+func (h *APIHandlers) ClearChat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cleared, err := h.dal.ClearChat()
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to clear chat", "error", err)
+		writeError(w, r, err)
+		return
+	}
+	logger.FromContext(r.Context()).Info("Cleared chat", "messages", cleared)
+
+	grace := dal.ChatClearGrace()
+	h.pubsub.Publish(pubsub.Event{
+		Type:    "chat:clear",
+		Payload: map[string]interface{}{"cleared": cleared},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"cleared":           cleared,
+		"restorableSeconds": int64(grace.Seconds()),
+	})
+}
+
+// RestoreChat brings back the messages of recent clears.
+func (h *APIHandlers) RestoreChat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	restored, err := h.dal.RestoreChat()
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("Failed to restore chat", "error", err)
+		writeError(w, r, err)
+		return
+	}
+	logger.FromContext(r.Context()).Info("Restored chat", "messages", restored)
+
+	h.pubsub.Publish(pubsub.Event{
+		Type:    "chat:restore",
+		Payload: map[string]interface{}{"restored": restored},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"restored": restored})
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/clickhouse"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+)
+
+const (
+	defaultHistoryDays = 30
+	historyCacheTTL    = 5 * time.Minute
+)
+
+// CuddleHistoryHandlers serves cuddle points history for the player profile.
+// Each ClickHouse series is cached for a few minutes since it only changes
+// as interactions arrive.
+type CuddleHistoryHandlers struct {
+	dal    dal.DraftDAL
+	source clickhouse.CuddleHistorySource
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedHistory
+}
+
+type cachedHistory struct {
+	series  []clickhouse.DailyCuddlePoints
+	expires time.Time
+}
+
+// NewCuddleHistoryHandlers creates a new cuddle history handlers instance
+func NewCuddleHistoryHandlers(store dal.DraftDAL, source clickhouse.CuddleHistorySource) *CuddleHistoryHandlers {
+	return &CuddleHistoryHandlers{
+		dal:    store,
+		source: source,
+		now:    time.Now,
+		cache:  make(map[string]cachedHistory),
+	}
+}
+
+// GetCuddleHistory returns a player's daily cuddle points, oldest first, for
+// the last ?days= days (default 30, at most 90).
+func (h *CuddleHistoryHandlers) GetCuddleHistory(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "Missing id parameter", http.StatusBadRequest)
+		return
+	}
+	days := defaultHistoryDays
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > clickhouse.MaxHistoryDays {
+			http.Error(w, fmt.Sprintf("days must be a number from 1 to %d", clickhouse.MaxHistoryDays), http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	state, err := h.dal.GetState()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	found := false
+	for _, p := range state.Players {
+		if p.ID == id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		http.Error(w, "Player not found", http.StatusNotFound)
+		return
+	}
+
+	series, err := h.history(r, id, days)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to load cuddle points history", "error", err, "player_id", id)
+		http.Error(w, "Cuddle points history is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":      id,
+		"days":    days,
+		"history": series,
+	})
+}
+
+func (h *CuddleHistoryHandlers) history(r *http.Request, id string, days int) ([]clickhouse.DailyCuddlePoints, error) {
+	key := id + "|" + strconv.Itoa(days)
+	now := h.now()
+
+	h.mu.Lock()
+	cached, ok := h.cache[key]
+	h.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.series, nil
+	}
+
+	series, err := h.source.GetCuddlePointsHistory(r.Context(), id, days)
+	if err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	for k, entry := range h.cache {
+		if !now.Before(entry.expires) {
+			delete(h.cache, k)
+		}
+	}
+	h.cache[key] = cachedHistory{series: series, expires: now.Add(historyCacheTTL)}
+	h.mu.Unlock()
+	return series, nil
+}
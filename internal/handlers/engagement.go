@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/clickhouse"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+const engagementCacheTTL = 5 * time.Minute
+
+// EngagementHandlers ranks teams by how much their rosters are cuddled.
+// ClickHouse totals are cached for a few minutes per window and roster
+// line-up; without ClickHouse the ranking falls back to the draft's own
+// cuddle points.
+type EngagementHandlers struct {
+	dal    dal.DraftDAL
+	source clickhouse.EngagementSource // nil without ClickHouse
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedEngagement
+}
+
+type cachedEngagement struct {
+	totals  map[string]clickhouse.TeamEngagement
+	expires time.Time
+}
+
+// teamEngagement is one team's line in the engagement ranking.
+type teamEngagement struct {
+	TeamID              string  `json:"teamId"`
+	TeamName            string  `json:"teamName"`
+	Players             int     `json:"players"`
+	Interactions        int     `json:"interactions"`
+	UniqueUsers         int     `json:"uniqueUsers"`
+	AverageCuddlePoints float64 `json:"averageCuddlePoints"`
+}
+
+// NewEngagementHandlers creates engagement handlers. A nil source ranks teams
+// by the cuddle points stored with their players.
+func NewEngagementHandlers(store dal.DraftDAL, source clickhouse.EngagementSource) *EngagementHandlers {
+	return &EngagementHandlers{
+		dal:    store,
+		source: source,
+		now:    time.Now,
+		cache:  make(map[string]cachedEngagement),
+	}
+}
+
+// GetTeamEngagement ranks teams, most cuddled first, by interactions with
+// their rosters over the last ?days= days (default 30, at most 90).
+func (h *EngagementHandlers) GetTeamEngagement(w http.ResponseWriter, r *http.Request) {
+	days := defaultHistoryDays
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > clickhouse.MaxHistoryDays {
+			http.Error(w, fmt.Sprintf("days must be a number from 1 to %d", clickhouse.MaxHistoryDays), http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	state, err := h.dal.GetState()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	source := "draft"
+	teams := make([]teamEngagement, len(state.Teams))
+	for i, team := range state.Teams {
+		teams[i] = teamEngagement{TeamID: team.ID, TeamName: team.Name, Players: len(team.Players)}
+	}
+	if h.source == nil {
+		for i, team := range state.Teams {
+			total := 0
+			for _, player := range team.Players {
+				total += player.CuddlePoints
+			}
+			teams[i].AverageCuddlePoints = average(total, len(team.Players))
+		}
+	} else {
+		totals, err := h.totals(r, state.Teams, days)
+		if err != nil {
+			logger.FromContext(r.Context()).Error("Failed to load team engagement", "error", err)
+			http.Error(w, "Team engagement is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		source = "clickhouse"
+		for i := range teams {
+			t := totals[teams[i].TeamID]
+			teams[i].Interactions = t.Interactions
+			teams[i].UniqueUsers = t.UniqueUsers
+			teams[i].AverageCuddlePoints = average(t.CuddlePoints, teams[i].Players)
+		}
+	}
+
+	sort.SliceStable(teams, func(i, j int) bool {
+		if teams[i].Interactions != teams[j].Interactions {
+			return teams[i].Interactions > teams[j].Interactions
+		}
+		return teams[i].AverageCuddlePoints > teams[j].AverageCuddlePoints
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"days":   days,
+		"source": source,
+		"teams":  teams,
+	})
+}
+
+func (h *EngagementHandlers) totals(r *http.Request, teams []models.Team, days int) (map[string]clickhouse.TeamEngagement, error) {
+	rosters := make(map[string][]string, len(teams))
+	var key strings.Builder
+	key.WriteString(strconv.Itoa(days))
+	for _, team := range teams {
+		key.WriteString("|" + team.ID + ":")
+		for _, player := range team.Players {
+			rosters[team.ID] = append(rosters[team.ID], player.ID)
+			key.WriteString(player.ID + ",")
+		}
+	}
+	now := h.now()
+
+	h.mu.Lock()
+	cached, ok := h.cache[key.String()]
+	h.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.totals, nil
+	}
+
+	totals, err := h.source.GetTeamEngagement(r.Context(), rosters, days)
+	if err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	for k, entry := range h.cache {
+		if !now.Before(entry.expires) {
+			delete(h.cache, k)
+		}
+	}
+	h.cache[key.String()] = cachedEngagement{totals: totals, expires: now.Add(engagementCacheTTL)}
+	h.mu.Unlock()
+	return totals, nil
+}
+
+// average is total/count rounded to one decimal place, or 0 for no count.
+func average(total, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return math.Round(float64(total)/float64(count)*10) / 10
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+)
+
+// Errors the handlers raise themselves, mapped alongside the DAL kinds.
+var (
+	errLoginRequired = errors.New("Unauthorized: login required to pick for this team")
+	errNotTeamOwner  = errors.New("Forbidden: team is claimed by another user")
+)
+
+// errorMapping ties an error kind to the HTTP status and the machine-readable
+// code clients receive for it.
+type errorMapping struct {
+	kind   error
+	status int
+	code   string
+}
+
+// errorMappings is checked in order, so specific errors come before the
+// kinds they belong to. Anything unmatched is an internal error.
+var errorMappings = []errorMapping{
+	{errLoginRequired, http.StatusUnauthorized, "unauthorized"},
+	{errNotTeamOwner, http.StatusForbidden, "forbidden"},
+	{dal.ErrInvalidEmote, http.StatusUnprocessableEntity, "invalid_emote"},
+	{dal.ErrNotFound, http.StatusNotFound, "not_found"},
+	{dal.ErrAlreadyDrafted, http.StatusConflict, "already_drafted"},
+	{dal.ErrConflict, http.StatusConflict, "conflict"},
+	{dal.ErrValidation, http.StatusBadRequest, "validation_failed"},
+}
+
+// internalErrorMessage replaces the message of unmapped errors, which may
+// carry database or driver details.
+const internalErrorMessage = "internal server error"
+
+func mappingForError(err error) (errorMapping, bool) {
+	for _, mapping := range errorMappings {
+		if errors.Is(err, mapping.kind) {
+			return mapping, true
+		}
+	}
+	return errorMapping{status: http.StatusInternalServerError, code: "internal"}, false
+}
+
+// clientMessage is err's message, or a generic one when err is unmapped.
+func clientMessage(err error) string {
+	if _, ok := mappingForError(err); !ok {
+		return internalErrorMessage
+	}
+	return err.Error()
+}
+
+// errorResponse is the JSON body writeError sends.
+type errorResponse struct {
+	Error  string `json:"error"`
+	Code   string `json:"code"`
+	Entity string `json:"entity,omitempty"`
+	Field  string `json:"field,omitempty"`
+}
+
+// writeError sends err with the status and code from errorMappings. Unmapped
+// errors are logged with r's logger and reported with a generic message, so
+// SQL errors never reach clients.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	mapping, ok := mappingForError(err)
+	if !ok {
+		logger.FromContext(r.Context()).Error("Request failed", "error", err)
+	}
+	body := errorResponse{Error: clientMessage(err), Code: mapping.code}
+	var notFound *dal.NotFoundError
+	if errors.As(err, &notFound) {
+		body.Entity = notFound.Entity
+	}
+	var invalid *dal.ValidationError
+	if errors.As(err, &invalid) {
+		body.Field = invalid.Field
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(mapping.status)
+	json.NewEncoder(w).Encode(body)
+}
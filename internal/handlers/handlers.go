@@ -2,44 +2,83 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"math"
 	"mime"
 	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/auth"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/clickhouse"
 	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/export"
 	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
 	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/profile"
 	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/pubsub"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // APIHandlers contains all API handler methods
 type APIHandlers struct {
-	dal    dal.DraftDAL
-	pubsub *pubsub.PubSub
+	dal          dal.DraftDAL
+	pubsub       *pubsub.PubSub
+	journal      *dal.ChangeJournal
+	reservations *dal.PickReservations
+	profiles     *profile.Service
+	// rooms lists the rooms a request's user belongs to; nil means none
+	rooms func(*http.Request) []string
 }
 
 // NewAPIHandlers creates a new API handlers instance
-func NewAPIHandlers(dal dal.DraftDAL, ps *pubsub.PubSub) *APIHandlers {
+func NewAPIHandlers(store dal.DraftDAL, ps *pubsub.PubSub) *APIHandlers {
 	return &APIHandlers{
-		dal:    dal,
-		pubsub: ps,
+		dal:          store,
+		pubsub:       ps,
+		journal:      dal.NewChangeJournal(),
+		reservations: dal.NewPickReservations(),
+		profiles:     profile.NewService(store, nil),
 	}
 }
 
-// GetDraftState returns the current draft state
+// UsePickReservations shares reservations with the other transports, so a
+// player held over one can't be taken over another.
+func (h *APIHandlers) UsePickReservations(reservations *dal.PickReservations) {
+	h.reservations = reservations
+}
+
+// UsePlayerMetrics serves player profile metrics from source instead of
+// placeholders.
+func (h *APIHandlers) UsePlayerMetrics(source clickhouse.MetricsSource) {
+	h.profiles = profile.NewService(h.dal, source)
+}
+
+// UseRooms has event streams deliver room events to the rooms rooms lists
+// for the subscribing request. Without it streams only get events for no
+// room.
+func (h *APIHandlers) UseRooms(rooms func(*http.Request) []string) {
+	h.rooms = rooms
+}
+
+// GetDraftState returns the current draft state, with players sorted by
+// ?sort= and ?dir= (points, highest first, by default).
 func (h *APIHandlers) GetDraftState(w http.ResponseWriter, r *http.Request) {
-	logger.Debug("Getting draft state")
+	logger.FromContext(r.Context()).Debug("Getting draft state")
 	state, err := h.dal.GetState()
 	if err != nil {
-		logger.Error("Failed to get draft state", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("Failed to get draft state", "error", err)
+		writeError(w, r, err)
+		return
+	}
+	if err := sortPlayersFromQuery(r, state.Players); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -47,6 +86,70 @@ func (h *APIHandlers) GetDraftState(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(state)
 }
 
+// GetStats returns the store's counts, a cheap alternative to the full state
+// for diagnostics.
+func (h *APIHandlers) GetStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	stats, err := h.dal.Stats()
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to get stats", "error", err)
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// Me describes the caller: their user record, role and claimed teams, with
+// teamId the one they act as (see auth.ResolveTeam).
+// Anonymous callers get the spectator role.
+func (h *APIHandlers) Me(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user := auth.GetUser(r)
+	response := map[string]interface{}{
+		"authenticated": user != nil,
+		"user":          user,
+		"role":          auth.RoleSpectator,
+		"teamId":        "",
+		"teamIds":       []string{},
+		"impersonating": false,
+	}
+
+	if user != nil {
+		if user.Impersonator != nil {
+			response["impersonating"] = true
+			response["impersonator"] = user.Impersonator
+		}
+
+		state, err := h.dal.GetState()
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		response["role"] = auth.RoleForTeams(user, state.Teams)
+		owned := auth.OwnedTeams(user, state.Teams)
+		teamIDs := make([]string, len(owned))
+		for i, team := range owned {
+			teamIDs[i] = team.ID
+		}
+		response["teamIds"] = teamIDs
+		if team := auth.ResolveTeam(owned, state.CurrentTeamID); team != nil {
+			response["teamId"] = team.ID
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
 // DraftPick handles player draft selection
 func (h *APIHandlers) DraftPick(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -57,18 +160,29 @@ func (h *APIHandlers) DraftPick(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		PlayerID string `json:"playerId"`
 		TeamID   string `json:"teamId"`
+		// The room code may ride along in the body; the router checks it.
+		Code     string `json:"code"`
+		RoomCode string `json:"roomCode"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		logger.Warn("Failed to decode draft pick request", "error", err)
+	if err := DecodeJSON(r, &req); err != nil {
+		logger.FromContext(r.Context()).Warn("Failed to decode draft pick request", "error", err)
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	logger.Info("Drafting player", "player_id", req.PlayerID, "team_id", req.TeamID)
-	if err := h.dal.DraftPlayer(req.PlayerID, req.TeamID); err != nil {
-		logger.Error("Failed to draft player", "error", err, "player_id", req.PlayerID, "team_id", req.TeamID)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := h.authorizePick(r, req.TeamID); err != nil {
+		logger.FromContext(r.Context()).Warn("Rejected draft pick", "error", err, "team_id", req.TeamID)
+		h.publishPickFailure(r, req.PlayerID, req.TeamID, err)
+		writeError(w, r, err)
+		return
+	}
+
+	logger.FromContext(r.Context()).Info("Drafting player", "player_id", req.PlayerID, "team_id", req.TeamID)
+	if err := h.reservations.Draft(h.dal, req.PlayerID, req.TeamID); err != nil {
+		logger.FromContext(r.Context()).Error("Failed to draft player", "error", err, "player_id", req.PlayerID, "team_id", req.TeamID)
+		h.publishPickFailure(r, req.PlayerID, req.TeamID, err)
+		writeError(w, r, err)
 		return
 	}
 
@@ -81,29 +195,125 @@ func (h *APIHandlers) DraftPick(w http.ResponseWriter, r *http.Request) {
 		},
 	})
 
-	// Publish chat event for the system message that was created
+	h.publishSystemChat()
+	h.publishOnClock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+}
+
+// publishSystemChat tells clients about the system chat message the DAL
+// wrote for a pick or settings change. Without chat there is none.
+func (h *APIHandlers) publishSystemChat() {
+	if !dal.ChatEnabled() {
+		return
+	}
 	h.pubsub.Publish(pubsub.Event{
 		Type: "chat:add",
 		Payload: map[string]interface{}{
 			"type": "system",
 		},
 	})
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+// publishOnClock announces the team now on the clock. The pick already
+// succeeded, so failures are only logged.
+func (h *APIHandlers) publishOnClock() {
+	state, err := h.dal.GetState()
+	if err != nil {
+		logger.Warn("Failed to load draft state for on-the-clock event", "error", err)
+		return
+	}
+	window, err := h.dal.GetDraftWindow()
+	if err != nil {
+		logger.Warn("Failed to load draft window for on-the-clock event", "error", err)
+		return
+	}
+	if event, ok := pubsub.NewOnClockEvent(state, window, time.Now()); ok {
+		h.pubsub.Publish(event)
+	}
+}
+
+// authorizePick checks that the caller may pick for teamID. Teams claimed by
+// a user may only pick as that owner or a commissioner; unclaimed teams fall
+// back to the room code check applied by the router.
+func (h *APIHandlers) authorizePick(r *http.Request, teamID string) error {
+	team, err := h.findTeam(teamID)
+	if err != nil {
+		return err
+	}
+	if team.OwnerUserID == "" {
+		return nil
+	}
+
+	user := auth.GetUser(r)
+	if user == nil {
+		return errLoginRequired
+	}
+	if !auth.CanPickFor(user, *team) {
+		return errNotTeamOwner
+	}
+	return nil
+}
+
+// publishPickFailure sends the caller's streams an error event for a rejected
+// pick, so any tab that showed the pick as pending can undo it. Anonymous
+// callers only learn from the response.
+func (h *APIHandlers) publishPickFailure(r *http.Request, playerID, teamID string, err error) {
+	user := auth.GetUser(r)
+	if user == nil {
+		return
+	}
+	event := pubsub.NewErrorEvent(user.ID, "draft:pick", errors.New(clientMessage(err)))
+	event.Payload["playerId"] = playerID
+	event.Payload["teamId"] = teamID
+	h.pubsub.Publish(event)
+}
+
+func (h *APIHandlers) findTeam(teamID string) (*models.Team, error) {
+	state, err := h.dal.GetState()
+	if err != nil {
+		return nil, err
+	}
+	for _, team := range state.Teams {
+		if team.ID == teamID {
+			return &team, nil
+		}
+	}
+	return nil, dal.ErrTeamNotFound
 }
 
-// ResetDraft resets the draft to initial state
+// ResetDraft resets the draft to initial state. With ?dryRun=true it only
+// reports what a reset would remove.
 func (h *APIHandlers) ResetDraft(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	logger.Info("Resetting draft")
+	if raw := r.URL.Query().Get("dryRun"); raw != "" {
+		dryRun, err := strconv.ParseBool(raw)
+		if err != nil {
+			http.Error(w, "dryRun must be true or false", http.StatusBadRequest)
+			return
+		}
+		if dryRun {
+			preview, err := h.dal.PreviewReset()
+			if err != nil {
+				logger.FromContext(r.Context()).Error("Failed to preview draft reset", "error", err)
+				writeError(w, r, err)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(preview)
+			return
+		}
+	}
+
+	logger.FromContext(r.Context()).Info("Resetting draft")
 	if err := h.dal.Reset(); err != nil {
-		logger.Error("Failed to reset draft", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("Failed to reset draft", "error", err)
+		writeError(w, r, err)
 		return
 	}
 
@@ -113,6 +323,94 @@ func (h *APIHandlers) ResetDraft(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]bool{"ok": true})
 }
 
+// UndraftPlayer returns a specific drafted player to the pool.
+func (h *APIHandlers) UndraftPlayer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		PlayerID string `json:"playerId"`
+	}
+	if err := DecodeJSON(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logger.FromContext(r.Context()).Info("Undoing pick", "player_id", req.PlayerID)
+	player, err := h.dal.UndraftPlayer(req.PlayerID)
+	h.writeUndo(w, r, player, err)
+}
+
+// UndoLastPick undoes the most recent pick, whichever player it was.
+func (h *APIHandlers) UndoLastPick(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	logger.FromContext(r.Context()).Info("Undoing last pick")
+	player, err := h.dal.UndoLastPick()
+	h.writeUndo(w, r, player, err)
+}
+
+func (h *APIHandlers) writeUndo(w http.ResponseWriter, r *http.Request, player *models.Player, err error) {
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to undo pick", "error", err)
+		writeError(w, r, err)
+		return
+	}
+
+	h.pubsub.Publish(pubsub.Event{
+		Type: "draft:undo",
+		Payload: map[string]interface{}{
+			"playerId": player.ID,
+		},
+	})
+	h.publishSystemChat()
+	h.publishOnClock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(player)
+}
+
+// SwapPicks exchanges the players drafted at two pick numbers.
+func (h *APIHandlers) SwapPicks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		PickA int `json:"pickA"`
+		PickB int `json:"pickB"`
+	}
+	if err := DecodeJSON(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logger.FromContext(r.Context()).Info("Swapping picks", "pick_a", req.PickA, "pick_b", req.PickB)
+	if err := h.dal.SwapPicks(req.PickA, req.PickB); err != nil {
+		logger.FromContext(r.Context()).Error("Failed to swap picks", "error", err)
+		writeError(w, r, err)
+		return
+	}
+
+	h.pubsub.Publish(pubsub.Event{
+		Type: "draft:swap",
+		Payload: map[string]interface{}{
+			"pickA": req.PickA,
+			"pickB": req.PickB,
+		},
+	})
+	h.publishSystemChat()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+}
+
 // UpdateDraftSettings changes the active draft mode.
 func (h *APIHandlers) UpdateDraftSettings(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost && r.Method != http.MethodPut {
@@ -126,7 +424,7 @@ func (h *APIHandlers) UpdateDraftSettings(w http.ResponseWriter, r *http.Request
 		var req struct {
 			Mode string `json:"mode"`
 		}
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if err := DecodeJSON(r, &req); err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
@@ -141,8 +439,8 @@ func (h *APIHandlers) UpdateDraftSettings(w http.ResponseWriter, r *http.Request
 
 	settings, err := h.dal.SetDraftMode(models.DraftMode(mode))
 	if err != nil {
-		logger.Error("Failed to update draft settings", "error", err, "mode", mode)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.FromContext(r.Context()).Error("Failed to update draft settings", "error", err, "mode", mode)
+		writeError(w, r, err)
 		return
 	}
 
@@ -152,22 +450,196 @@ func (h *APIHandlers) UpdateDraftSettings(w http.ResponseWriter, r *http.Request
 			"mode": settings.Mode,
 		},
 	})
+	h.publishSystemChat()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+// GetDraftWindow returns when picks are accepted, with countdowns from the server's clock
+func (h *APIHandlers) GetDraftWindow(w http.ResponseWriter, r *http.Request) {
+	window, err := h.dal.GetDraftWindow()
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to get draft window", "error", err)
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(window.Status(time.Now()))
+}
+
+// SetDraftWindow schedules the draft: {"opensAt": RFC 3339, "closesAt": RFC 3339}.
+// Omitted bounds leave that side of the window open.
+func (h *APIHandlers) SetDraftWindow(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.DraftWindow
+	if err := DecodeJSON(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	window, err := h.dal.SetDraftWindow(req)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to set draft window", "error", err)
+		writeError(w, r, err)
+		return
+	}
+
+	status := window.Status(time.Now())
 	h.pubsub.Publish(pubsub.Event{
-		Type: "chat:add",
+		Type: "draft:window",
 		Payload: map[string]interface{}{
-			"type": "system",
+			"opensAt":  status.OpensAt,
+			"closesAt": status.ClosesAt,
+			"state":    status.State,
 		},
 	})
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(settings)
+	json.NewEncoder(w).Encode(status)
+}
+
+// SuggestPick returns the player ?teamId= should draft next, filling the
+// positions it still needs first.
+func (h *APIHandlers) SuggestPick(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	teamID := r.URL.Query().Get("teamId")
+	if teamID == "" {
+		http.Error(w, "teamId is required", http.StatusBadRequest)
+		return
+	}
+
+	player, err := h.dal.SuggestPick(teamID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(player)
+}
+
+// GetDraftMeta returns the draft's title and description.
+func (h *APIHandlers) GetDraftMeta(w http.ResponseWriter, r *http.Request) {
+	meta, err := h.dal.GetMeta()
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to get draft meta", "error", err)
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(meta)
+}
+
+// SetDraftMeta sets the draft's title and description:
+// {"title": "...", "description": "..."}. An omitted field is cleared.
+func (h *APIHandlers) SetDraftMeta(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.DraftMeta
+	if err := DecodeJSON(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	meta, err := h.dal.SetMeta(req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	h.pubsub.Publish(pubsub.Event{
+		Type: "draft:meta",
+		Payload: map[string]interface{}{
+			"title":       meta.Title,
+			"description": meta.Description,
+		},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(meta)
+}
+
+// GetDraftDiff returns the board changes since ?since=, the seq of the
+// client's previous diff. Omit since (or pass 0) for the whole board.
+func (h *APIHandlers) GetDraftDiff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var since int64
+	if value := r.URL.Query().Get("since"); value != "" {
+		var err error
+		if since, err = strconv.ParseInt(value, 10, 64); err != nil {
+			http.Error(w, "since must be a sequence number from a previous diff", http.StatusBadRequest)
+			return
+		}
+	}
+
+	diff, err := h.journal.Diff(h.dal, since)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to diff draft state", "error", err)
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(diff)
+}
+
+// ExportDraft returns the draft's picks in an import format for other fantasy
+// tools (?format=, default generic; see package export).
+func (h *APIHandlers) ExportDraft(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = export.DefaultFormat
+	}
+	exporter, ok := export.Lookup(format)
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown format %q (valid: %s)", format, strings.Join(export.Formats(), ", ")), http.StatusBadRequest)
+		return
+	}
+
+	state, err := h.dal.GetState()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	body, err := exporter.Export(state)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to export draft", "format", format, "error", err)
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="draft-%s.json"`, format))
+	json.NewEncoder(w).Encode(body)
 }
 
 // ListTeams returns all teams
 func (h *APIHandlers) ListTeams(w http.ResponseWriter, r *http.Request) {
 	state, err := h.dal.GetState()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -175,6 +647,25 @@ func (h *APIHandlers) ListTeams(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(state.Teams)
 }
 
+// GetTeamPlayers returns the players drafted by the teamId query parameter,
+// in pick order.
+func (h *APIHandlers) GetTeamPlayers(w http.ResponseWriter, r *http.Request) {
+	teamID := r.URL.Query().Get("teamId")
+	if teamID == "" {
+		http.Error(w, "teamId is required", http.StatusBadRequest)
+		return
+	}
+
+	players, err := h.dal.GetTeamPlayers(teamID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(players)
+}
+
 // AddTeam creates a new team
 func (h *APIHandlers) AddTeam(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -193,7 +684,7 @@ func (h *APIHandlers) AddTeam(w http.ResponseWriter, r *http.Request) {
 			Mascot string `json:"mascot"`
 			Color  string `json:"color"`
 		}
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if err := DecodeJSON(r, &req); err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
@@ -210,14 +701,14 @@ func (h *APIHandlers) AddTeam(w http.ResponseWriter, r *http.Request) {
 		color = r.FormValue("color")
 	}
 
-	if name == "" {
-		http.Error(w, "Team name is required", http.StatusBadRequest)
+	if err := dal.ValidateNewTeam(name); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
 	team, err := h.dal.AddTeam(name, owner, mascot, color)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -232,6 +723,39 @@ func (h *APIHandlers) AddTeam(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(team)
 }
 
+// AddTeams adds a JSON array of teams at once, for setting up a league. The
+// batch is all or nothing.
+func (h *APIHandlers) AddTeams(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var inputs []dal.TeamInput
+	if err := DecodeJSON(r, &inputs); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	teams, err := h.dal.AddTeams(inputs)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	for _, team := range teams {
+		h.pubsub.Publish(pubsub.Event{
+			Type: "teams:add",
+			Payload: map[string]interface{}{
+				"id": team.ID,
+			},
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(teams)
+}
+
 // ReorderTeams reorders the team draft order
 func (h *APIHandlers) ReorderTeams(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -243,18 +767,39 @@ func (h *APIHandlers) ReorderTeams(w http.ResponseWriter, r *http.Request) {
 		Order []string `json:"order"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := DecodeJSON(r, &req); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	teams, err := h.dal.ReorderTeams(req.Order)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
+		return
+	}
+
+	h.pubsub.Publish(pubsub.Event{Type: "teams:reorder"})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(teams)
+}
+
+// RandomizeTeamOrder shuffles the draft order, for commissioners who want a
+// fair random start.
+func (h *APIHandlers) RandomizeTeamOrder(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	teams, err := h.dal.RandomizeTeamOrder()
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
 	h.pubsub.Publish(pubsub.Event{Type: "teams:reorder"})
+	h.publishSystemChat()
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(teams)
@@ -280,7 +825,7 @@ func (h *APIHandlers) UpdateTeam(w http.ResponseWriter, r *http.Request) {
 			Mascot string  `json:"mascot"`
 			Color  string  `json:"color"`
 		}
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if err := DecodeJSON(r, &req); err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
@@ -311,7 +856,7 @@ func (h *APIHandlers) UpdateTeam(w http.ResponseWriter, r *http.Request) {
 	if !ownerProvided {
 		state, err := h.dal.GetState()
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, r, err)
 			return
 		}
 		for _, team := range state.Teams {
@@ -324,10 +869,72 @@ func (h *APIHandlers) UpdateTeam(w http.ResponseWriter, r *http.Request) {
 
 	team, err := h.dal.UpdateTeam(id, name, owner, mascot, color)
 	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	h.pubsub.Publish(pubsub.Event{
+		Type: "teams:update",
+		Payload: map[string]interface{}{
+			"id": team.ID,
+		},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(team)
+}
+
+// ClaimTeam binds a team to the authenticated user. Admins may instead pass
+// userId (and optionally owner) to assign the team to someone else, or an
+// empty userId to unassign it.
+func (h *APIHandlers) ClaimTeam(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user := auth.GetUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized: login required", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		TeamID string  `json:"teamId"`
+		UserID *string `json:"userId"`
+		Owner  string  `json:"owner"`
+	}
+	if err := DecodeJSON(r, &req); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	if req.TeamID == "" {
+		http.Error(w, "Team ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var team *models.Team
+	var err error
+	if req.UserID != nil {
+		if !auth.IsAdmin(user) {
+			http.Error(w, "Forbidden: Admin access required", http.StatusForbidden)
+			return
+		}
+		owner := strings.TrimSpace(req.Owner)
+		if owner == "" {
+			owner = *req.UserID
+		}
+		team, err = h.dal.AssignTeamOwner(req.TeamID, *req.UserID, owner)
+	} else {
+		team, err = h.claimTeam(req.TeamID, user)
+	}
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
 	h.pubsub.Publish(pubsub.Event{
 		Type: "teams:update",
 		Payload: map[string]interface{}{
@@ -339,6 +946,25 @@ func (h *APIHandlers) UpdateTeam(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(team)
 }
 
+// claimTeam claims teamID for user, keeping the team's existing owner label
+// when it already names the user.
+func (h *APIHandlers) claimTeam(teamID string, user *auth.User) (*models.Team, error) {
+	existing, err := h.findTeam(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	owner := user.Username
+	if owner == "" {
+		owner = user.Name
+	}
+	if existing.Owner != "" && (existing.Owner == user.Username || existing.Owner == user.Name) {
+		owner = existing.Owner
+	}
+
+	return h.dal.ClaimTeam(teamID, user.ID, owner)
+}
+
 // DeleteTeam deletes a team
 func (h *APIHandlers) DeleteTeam(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
@@ -350,7 +976,7 @@ func (h *APIHandlers) DeleteTeam(w http.ResponseWriter, r *http.Request) {
 		ID string `json:"id"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := DecodeJSON(r, &req); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -361,7 +987,7 @@ func (h *APIHandlers) DeleteTeam(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.dal.DeleteTeam(req.ID); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
@@ -384,14 +1010,19 @@ func (h *APIHandlers) AddPlayer(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var player models.Player
-	if err := json.NewDecoder(r.Body).Decode(&player); err != nil {
+	if err := DecodeJSON(r, &player); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	if err := dal.ValidateNewPlayer(&player); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
 	result, err := h.dal.AddPlayer(&player)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -414,7 +1045,7 @@ func (h *APIHandlers) UpdatePlayer(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var player models.Player
-	if err := json.NewDecoder(r.Body).Decode(&player); err != nil {
+	if err := DecodeJSON(r, &player); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -426,8 +1057,8 @@ func (h *APIHandlers) UpdatePlayer(w http.ResponseWriter, r *http.Request) {
 
 	result, err := h.dal.UpdatePlayer(&player)
 	if err != nil {
-		logger.Error("Failed to update player", "error", err, "player_id", player.ID)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("Failed to update player", "error", err, "player_id", player.ID)
+		writeError(w, r, err)
 		return
 	}
 
@@ -453,7 +1084,7 @@ func (h *APIHandlers) DeletePlayer(w http.ResponseWriter, r *http.Request) {
 		ID string `json:"id"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := DecodeJSON(r, &req); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -465,8 +1096,8 @@ func (h *APIHandlers) DeletePlayer(w http.ResponseWriter, r *http.Request) {
 
 	err := h.dal.DeletePlayer(req.ID)
 	if err != nil {
-		logger.Error("Failed to delete player", "error", err, "player_id", req.ID)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("Failed to delete player", "error", err, "player_id", req.ID)
+		writeError(w, r, err)
 		return
 	}
 
@@ -493,14 +1124,14 @@ func (h *APIHandlers) SetPlayerPoints(w http.ResponseWriter, r *http.Request) {
 		Points int    `json:"points"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := DecodeJSON(r, &req); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	player, err := h.dal.SetPlayerPoints(req.ID, req.Points)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -524,55 +1155,83 @@ func (h *APIHandlers) GetPlayerProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	state, err := h.dal.GetState()
+	playerProfile, err := h.profiles.Get(r.Context(), id)
+	if errors.Is(err, profile.ErrMetricsUnavailable) {
+		logger.FromContext(r.Context()).Warn("Failed to load player metrics", "error", err, "player_id", id)
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	var player *models.Player
-	for _, p := range state.Players {
-		if p.ID == id {
-			player = &p
-			break
-		}
-	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(playerProfile)
+}
 
-	if player == nil {
-		http.Error(w, "Player not found", http.StatusNotFound)
+// ListChat returns all chat messages
+func (h *APIHandlers) ListChat(w http.ResponseWriter, r *http.Request) {
+	if !dal.ChatEnabled() {
+		writeError(w, r, dal.ErrChatDisabled)
 		return
 	}
-
-	// Generate mock metrics
-	profile := models.PlayerProfile{Player: *player}
-	seed := player.Points
-	for _, c := range player.ID {
-		seed += int(c)
+	state, err := h.dal.GetState()
+	if err != nil {
+		writeError(w, r, err)
+		return
 	}
 
-	norm := func(x int) int {
-		return int(math.Max(0, math.Min(100, float64(x))))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state.Chat)
+}
+
+// GetChatSince returns the chat messages sent after ?ts= (Unix
+// milliseconds), for clients catching up after a reconnect.
+func (h *APIHandlers) GetChatSince(w http.ResponseWriter, r *http.Request) {
+	since, err := strconv.ParseInt(r.URL.Query().Get("ts"), 10, 64)
+	if err != nil || since < 0 {
+		http.Error(w, "ts must be a Unix timestamp in milliseconds", http.StatusBadRequest)
+		return
 	}
 
-	profile.Metrics.Consistency = norm((seed * 13) % 101)
-	profile.Metrics.Popularity = norm((seed * 29) % 101)
-	profile.Metrics.Efficiency = norm((seed * 47) % 101)
-	profile.Metrics.TrendDelta = float64(((seed%15)-7)/7.0) * 100 / 100
+	messages, err := h.dal.GetChatSince(since)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(profile)
+	json.NewEncoder(w).Encode(messages)
 }
 
-// ListChat returns all chat messages
-func (h *APIHandlers) ListChat(w http.ResponseWriter, r *http.Request) {
-	state, err := h.dal.GetState()
+// Limits for GetTopReactions' ?limit=.
+const (
+	defaultTopReactionsLimit = 5
+	maxTopReactionsLimit     = 50
+)
+
+// GetTopReactions returns the most-reacted chat messages, most reactions
+// first. ?limit= defaults to 5 and is capped at 50.
+func (h *APIHandlers) GetTopReactions(w http.ResponseWriter, r *http.Request) {
+	limit := defaultTopReactionsLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(parsed, maxTopReactionsLimit)
+	}
+
+	messages, err := h.dal.TopReactedMessages(limit)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(state.Chat)
+	json.NewEncoder(w).Encode(messages)
 }
 
 // SendChatMessage sends a new chat message
@@ -587,18 +1246,21 @@ func (h *APIHandlers) SendChatMessage(w http.ResponseWriter, r *http.Request) {
 		Type string `json:"type"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := DecodeJSON(r, &req); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	if req.Type == "" {
-		req.Type = "user"
+	user := auth.GetUser(r)
+	msgType, err := auth.ChatMessageType(user, req.Type)
+	if err != nil {
+		http.Error(w, "Forbidden: "+err.Error(), http.StatusForbidden)
+		return
 	}
 
-	msg, err := h.dal.AddChatMessage(req.Text, req.Type)
+	msg, err := h.dal.AddChatMessage(req.Text, msgType)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -626,14 +1288,14 @@ func (h *APIHandlers) AddReaction(w http.ResponseWriter, r *http.Request) {
 		User      string `json:"user"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := DecodeJSON(r, &req); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	msg, err := h.dal.AddReaction(req.MessageID, req.Emote, req.User)
+	msg, err := h.dal.AddReaction(req.MessageID, req.Emote, auth.ReactionUserID(auth.GetUser(r), req.User))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -649,9 +1311,63 @@ func (h *APIHandlers) AddReaction(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(msg)
 }
 
-// EventsSSE provides Server-Sent Events for realtime updates
+// GetReactionUsers returns who reacted to ?messageId=, as lists of user IDs
+// keyed by emote.
+func (h *APIHandlers) GetReactionUsers(w http.ResponseWriter, r *http.Request) {
+	messageID := r.URL.Query().Get("messageId")
+	if messageID == "" {
+		http.Error(w, "messageId is required", http.StatusBadRequest)
+		return
+	}
+
+	users, err := h.dal.GetReactionUsers(messageID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
+
+// sessionExpiryWarning is how long before expiry an SSE stream gets session:expiring.
+var sessionExpiryWarning = 5 * time.Minute
+
+var (
+	sseClients      atomic.Int64
+	sseClientsGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "jellycat_sse_clients_active",
+		Help: "Clients connected to the /api/events stream.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(sseClientsGauge)
+}
+
+// ActiveSSEClients returns how many clients are connected to EventsSSE.
+func ActiveSSEClients() int64 {
+	return sseClients.Load()
+}
+
+// EventSnapshot is the first SSE message of a ?snapshot=true stream.
+const EventSnapshot = "snapshot"
+
+// EventsSSE provides Server-Sent Events for realtime updates. With
+// ?snapshot=true the first message is a snapshot event carrying the current
+// state (sorted like GetDraftState) and its diff seq instead of connected.
+// The stream subscribes before the snapshot is taken, so events that follow
+// may repeat changes it already includes but never miss one.
 func (h *APIHandlers) EventsSSE(w http.ResponseWriter, r *http.Request) {
-	logger.Info("SSE client connected", "remoteAddr", r.RemoteAddr)
+	var snapshot bool
+	if value := r.URL.Query().Get("snapshot"); value != "" {
+		var err error
+		if snapshot, err = strconv.ParseBool(value); err != nil {
+			http.Error(w, "snapshot must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+	logger.FromContext(r.Context()).Info("SSE client connected", "remoteAddr", r.RemoteAddr, "snapshot", snapshot)
 
 	// Set headers for SSE
 	w.Header().Set("Content-Type", "text/event-stream")
@@ -659,29 +1375,85 @@ func (h *APIHandlers) EventsSSE(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	// Subscribe to events
-	logger.Debug("SSE: Subscribing to pubsub")
-	eventChan := h.pubsub.Subscribe()
+	// Subscribe to the events this user may see, such as errors from their
+	// own requests and events for the rooms they belong to
+	var userID string
+	if user := auth.GetUser(r); user != nil {
+		userID = user.ID
+	}
+	var rooms []string
+	if h.rooms != nil {
+		rooms = h.rooms(r)
+	}
+	logger.FromContext(r.Context()).Debug("SSE: Subscribing to pubsub", "rooms", rooms)
+	eventChan := h.pubsub.SubscribeFiltered(pubsub.Audience(userID, rooms...))
 	defer h.pubsub.Unsubscribe(eventChan)
-	logger.Debug("SSE: Subscribed successfully")
+	logger.FromContext(r.Context()).Debug("SSE: Subscribed successfully")
+	sseClientsGauge.Set(float64(sseClients.Add(1)))
+	defer func() { sseClientsGauge.Set(float64(sseClients.Add(-1))) }()
 
-	// Send initial connection message
-	fmt.Fprintf(w, "data: {\"type\":\"connected\"}\n\n")
+	if snapshot {
+		state, seq, err := h.journal.Snapshot(h.dal)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if err := sortPlayersFromQuery(r, state.Players); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := json.Marshal(map[string]interface{}{
+			"type":    EventSnapshot,
+			"payload": map[string]interface{}{"seq": seq, "state": state},
+		})
+		fmt.Fprintf(w, "id: %d\ndata: %s\n\n", state.Version, data)
+	} else {
+		// Send initial connection message
+		fmt.Fprintf(w, "data: {\"type\":\"connected\"}\n\n")
+	}
 	if f, ok := w.(http.Flusher); ok {
 		f.Flush()
 	}
 
+	// Warn this connection shortly before its session expires so the page can offer a refresh
+	var expiring <-chan time.Time
+	if session, ok := auth.CurrentSession(r); ok {
+		expiring = time.After(time.Until(session.ExpiresAt) - sessionExpiryWarning)
+	}
+
 	// Listen for events
 	for {
 		select {
+		case <-expiring:
+			expiring = nil
+			session, ok := auth.CurrentSession(r)
+			if !ok {
+				continue
+			}
+			if remaining := time.Until(session.ExpiresAt); remaining > sessionExpiryWarning {
+				// Refreshed since the timer was set
+				expiring = time.After(remaining - sessionExpiryWarning)
+				continue
+			}
+			data, _ := json.Marshal(map[string]interface{}{
+				"type":    "session:expiring",
+				"payload": map[string]interface{}{"expiresAt": session.ExpiresAt, "expiresIn": session.ExpiresIn, "refreshable": session.Refreshable},
+			})
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
 		case event := <-eventChan:
 			data, _ := json.Marshal(event)
+			if event.Version > 0 {
+				fmt.Fprintf(w, "id: %d\n", event.Version)
+			}
 			fmt.Fprintf(w, "data: %s\n\n", data)
 			if f, ok := w.(http.Flusher); ok {
 				f.Flush()
 			}
 		case <-r.Context().Done():
-			logger.Debug("SSE client disconnected")
+			logger.FromContext(r.Context()).Debug("SSE client disconnected")
 			return
 		case <-time.After(30 * time.Second):
 			// Send keepalive ping
@@ -702,7 +1474,7 @@ func (h *APIHandlers) UploadImage(w http.ResponseWriter, r *http.Request) {
 
 	// Parse multipart form with max 10MB file size
 	if err := r.ParseMultipartForm(10 << 20); err != nil {
-		logger.Error("Failed to parse multipart form", "error", err)
+		logger.FromContext(r.Context()).Error("Failed to parse multipart form", "error", err)
 		http.Error(w, "Failed to parse form: "+err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -710,7 +1482,7 @@ func (h *APIHandlers) UploadImage(w http.ResponseWriter, r *http.Request) {
 	// Get the uploaded file
 	file, header, err := r.FormFile("image")
 	if err != nil {
-		logger.Error("Failed to get file from form", "error", err)
+		logger.FromContext(r.Context()).Error("Failed to get file from form", "error", err)
 		http.Error(w, "Failed to get file: "+err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -734,18 +1506,18 @@ func (h *APIHandlers) UploadImage(w http.ResponseWriter, r *http.Request) {
 	if imageStore, ok := h.dal.(dal.ImageStore); ok {
 		imageData, err := io.ReadAll(file)
 		if err != nil {
-			logger.Error("Failed to read upload contents", "error", err)
+			logger.FromContext(r.Context()).Error("Failed to read upload contents", "error", err)
 			http.Error(w, "Failed to read file", http.StatusInternalServerError)
 			return
 		}
 
 		if err := imageStore.SaveImage(imageURL, contentType, imageData); err != nil {
-			logger.Error("Failed to store image in database", "error", err)
+			logger.FromContext(r.Context()).Error("Failed to store image in database", "error", err)
 			http.Error(w, "Failed to save file", http.StatusInternalServerError)
 			return
 		}
 
-		logger.Info("Image uploaded to database", "filename", safeFilename, "size", len(imageData))
+		logger.FromContext(r.Context()).Info("Image uploaded to database", "filename", safeFilename, "size", len(imageData))
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]string{
 			"url":      imageURL,
@@ -756,7 +1528,7 @@ func (h *APIHandlers) UploadImage(w http.ResponseWriter, r *http.Request) {
 
 	imagesDir := "static/images"
 	if err := os.MkdirAll(imagesDir, 0755); err != nil {
-		logger.Error("Failed to create images directory", "error", err)
+		logger.FromContext(r.Context()).Error("Failed to create images directory", "error", err)
 		http.Error(w, "Failed to create directory", http.StatusInternalServerError)
 		return
 	}
@@ -766,7 +1538,7 @@ func (h *APIHandlers) UploadImage(w http.ResponseWriter, r *http.Request) {
 	// Create destination file
 	destFile, err := os.Create(destPath)
 	if err != nil {
-		logger.Error("Failed to create destination file", "error", err)
+		logger.FromContext(r.Context()).Error("Failed to create destination file", "error", err)
 		http.Error(w, "Failed to save file", http.StatusInternalServerError)
 		return
 	}
@@ -774,12 +1546,12 @@ func (h *APIHandlers) UploadImage(w http.ResponseWriter, r *http.Request) {
 
 	// Copy file contents
 	if _, err := io.Copy(destFile, file); err != nil {
-		logger.Error("Failed to copy file contents", "error", err)
+		logger.FromContext(r.Context()).Error("Failed to copy file contents", "error", err)
 		http.Error(w, "Failed to save file", http.StatusInternalServerError)
 		return
 	}
 
-	logger.Info("Image uploaded successfully", "filename", safeFilename, "size", header.Size)
+	logger.FromContext(r.Context()).Info("Image uploaded successfully", "filename", safeFilename, "size", header.Size)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
@@ -793,8 +1565,8 @@ func (h *APIHandlers) ListImages(w http.ResponseWriter, r *http.Request) {
 	if imageStore, ok := h.dal.(dal.ImageStore); ok {
 		images, err := imageStore.ListImages()
 		if err != nil {
-			logger.Error("Failed to list database images", "error", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			logger.FromContext(r.Context()).Error("Failed to list database images", "error", err)
+			writeError(w, r, err)
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
@@ -812,7 +1584,7 @@ func (h *APIHandlers) ListImages(w http.ResponseWriter, r *http.Request) {
 			json.NewEncoder(w).Encode([]string{})
 			return
 		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -0,0 +1,1500 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/auth"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/clickhouse"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/pubsub"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newTestHandlers(t *testing.T) (*APIHandlers, *dal.MemoryDAL) {
+	t.Helper()
+	t.Setenv("ENVIRONMENT", "production")
+	logger.Init()
+
+	store := dal.NewMemoryDAL()
+	return NewAPIHandlers(store, pubsub.New()), store
+}
+
+func postJSON(handler http.HandlerFunc, path, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	handler(recorder, request)
+	return recorder
+}
+
+func postJSONAs(handler http.HandlerFunc, path, body string, user *auth.User) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	if user != nil {
+		request = request.WithContext(context.WithValue(request.Context(), "user", user))
+	}
+	recorder := httptest.NewRecorder()
+	handler(recorder, request)
+	return recorder
+}
+
+func TestReservedPlayerCanOnlyBePickedByItsTeam(t *testing.T) {
+	h, store := newTestHandlers(t)
+	events := h.pubsub.Subscribe()
+
+	first, _ := store.AddTeam("First", "", "", "")
+	second, _ := store.AddTeam("Second", "", "", "")
+	player, err := store.AddPlayer(&models.Player{Name: "Held Pick", Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+
+	recorder := postJSON(h.ReservePick, "/api/draft/reserve", `{"playerId":"`+player.ID+`","teamId":"`+first.ID+`"}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("reserve status = %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if event := <-events; event.Type != "draft:reserve" || event.Payload["teamId"] != first.ID {
+		t.Fatalf("event = %+v, want draft:reserve for the first team", event)
+	}
+
+	recorder = postJSON(h.DraftPick, "/api/draft/pick", `{"playerId":"`+player.ID+`","teamId":"`+second.ID+`"}`)
+	if recorder.Code != http.StatusConflict {
+		t.Fatalf("other team's pick status = %d, want %d", recorder.Code, http.StatusConflict)
+	}
+	recorder = postJSON(h.DraftPick, "/api/draft/pick", `{"playerId":"`+player.ID+`","teamId":"`+first.ID+`"}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("reserving team's pick status = %d: %s", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestDraftPickReturnsConflictForDraftedPlayer(t *testing.T) {
+	h, store := newTestHandlers(t)
+
+	team, err := store.AddTeam("First", "First", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	player, err := store.AddPlayer(&models.Player{Name: "Repeat Pick", Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+
+	body := `{"playerId":"` + player.ID + `","teamId":"` + team.ID + `"}`
+	if recorder := postJSON(h.DraftPick, "/api/draft/pick", body); recorder.Code != http.StatusOK {
+		t.Fatalf("first pick status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body.String())
+	}
+
+	recorder := postJSON(h.DraftPick, "/api/draft/pick", body)
+	if recorder.Code != http.StatusConflict {
+		t.Fatalf("repeat pick status = %d, want %d: %s", recorder.Code, http.StatusConflict, recorder.Body.String())
+	}
+}
+
+func TestClaimTeamBindsAuthenticatedUser(t *testing.T) {
+	h, store := newTestHandlers(t)
+	alice := &auth.User{ID: "user-alice", Username: "alice"}
+	bob := &auth.User{ID: "user-bob", Username: "bob"}
+
+	team, err := store.AddTeam("Open Team", "", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	body := `{"teamId":"` + team.ID + `"}`
+
+	if recorder := postJSONAs(h.ClaimTeam, "/api/teams/claim", body, nil); recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous claim status = %d, want %d", recorder.Code, http.StatusUnauthorized)
+	}
+	if recorder := postJSONAs(h.ClaimTeam, "/api/teams/claim", body, alice); recorder.Code != http.StatusOK {
+		t.Fatalf("claim status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body.String())
+	}
+	if recorder := postJSONAs(h.ClaimTeam, "/api/teams/claim", body, alice); recorder.Code != http.StatusOK {
+		t.Fatalf("repeat claim status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body.String())
+	}
+	if recorder := postJSONAs(h.ClaimTeam, "/api/teams/claim", body, bob); recorder.Code != http.StatusConflict {
+		t.Fatalf("competing claim status = %d, want %d", recorder.Code, http.StatusConflict)
+	}
+
+	state, _ := store.GetState()
+	for _, claimed := range state.Teams {
+		if claimed.ID == team.ID && (claimed.OwnerUserID != alice.ID || claimed.Owner != "alice") {
+			t.Fatalf("claimed team owner = %q/%q, want %q/%q", claimed.OwnerUserID, claimed.Owner, alice.ID, "alice")
+		}
+	}
+}
+
+func TestClaimTeamAssignmentRequiresAdmin(t *testing.T) {
+	h, store := newTestHandlers(t)
+	user := &auth.User{ID: "user-1", Username: "player"}
+	admin := &auth.User{ID: "admin-1", Username: "commish", Groups: []string{"admins"}}
+
+	team, err := store.AddTeam("Assigned", "", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+
+	assign := `{"teamId":"` + team.ID + `","userId":"user-2","owner":"Casey"}`
+	if recorder := postJSONAs(h.ClaimTeam, "/api/teams/claim", assign, user); recorder.Code != http.StatusForbidden {
+		t.Fatalf("non-admin assign status = %d, want %d", recorder.Code, http.StatusForbidden)
+	}
+	if recorder := postJSONAs(h.ClaimTeam, "/api/teams/claim", assign, admin); recorder.Code != http.StatusOK {
+		t.Fatalf("admin assign status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body.String())
+	}
+
+	unassign := `{"teamId":"` + team.ID + `","userId":""}`
+	if recorder := postJSONAs(h.ClaimTeam, "/api/teams/claim", unassign, admin); recorder.Code != http.StatusOK {
+		t.Fatalf("admin unassign status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body.String())
+	}
+	if recorder := postJSONAs(h.ClaimTeam, "/api/teams/claim", `{"teamId":"`+team.ID+`"}`, user); recorder.Code != http.StatusOK {
+		t.Fatalf("claim after unassign status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body.String())
+	}
+}
+
+func TestDraftPickRequiresClaimingUser(t *testing.T) {
+	h, store := newTestHandlers(t)
+	owner := &auth.User{ID: "user-owner", Username: "owner"}
+	other := &auth.User{ID: "user-other", Username: "other"}
+
+	team, err := store.AddTeam("Claimed", "", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	if _, err := store.ClaimTeam(team.ID, owner.ID, owner.Username); err != nil {
+		t.Fatalf("ClaimTeam() failed: %v", err)
+	}
+	player, err := store.AddPlayer(&models.Player{Name: "Owned Pick", Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+
+	body := `{"playerId":"` + player.ID + `","teamId":"` + team.ID + `"}`
+	if recorder := postJSONAs(h.DraftPick, "/api/draft/pick", body, nil); recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous pick status = %d, want %d", recorder.Code, http.StatusUnauthorized)
+	}
+	if recorder := postJSONAs(h.DraftPick, "/api/draft/pick", body, other); recorder.Code != http.StatusForbidden {
+		t.Fatalf("other user pick status = %d, want %d", recorder.Code, http.StatusForbidden)
+	}
+	if recorder := postJSONAs(h.DraftPick, "/api/draft/pick", body, owner); recorder.Code != http.StatusOK {
+		t.Fatalf("owner pick status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body.String())
+	}
+}
+
+func TestDeletePlayerReturnsNotFoundForMissingPlayer(t *testing.T) {
+	h, _ := newTestHandlers(t)
+
+	recorder := postJSON(h.DeletePlayer, "/api/players/delete", `{"id":"missing-player"}`)
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("delete status = %d, want %d: %s", recorder.Code, http.StatusNotFound, recorder.Body.String())
+	}
+}
+
+func TestDraftPickReturnsBadRequestOutOfTurn(t *testing.T) {
+	h, store := newTestHandlers(t)
+
+	if _, err := store.AddTeam("First", "First", "", ""); err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	second, err := store.AddTeam("Second", "Second", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	player, err := store.AddPlayer(&models.Player{Name: "Early Pick", Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+
+	body := `{"playerId":"` + player.ID + `","teamId":"` + second.ID + `"}`
+	recorder := postJSON(h.DraftPick, "/api/draft/pick", body)
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("out-of-turn pick status = %d, want %d: %s", recorder.Code, http.StatusBadRequest, recorder.Body.String())
+	}
+}
+
+func TestDraftPickPublishesScopedErrorOutOfTurn(t *testing.T) {
+	h, store := newTestHandlers(t)
+	events := h.pubsub.Subscribe()
+	defer h.pubsub.Unsubscribe(events)
+
+	if _, err := store.AddTeam("First", "First", "", ""); err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	second, err := store.AddTeam("Second", "Second", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	player, err := store.AddPlayer(&models.Player{Name: "Early Pick", Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+
+	body := `{"playerId":"` + player.ID + `","teamId":"` + second.ID + `"}`
+	if recorder := postJSONAs(h.DraftPick, "/api/draft/pick", body, &auth.User{ID: "user-eager"}); recorder.Code != http.StatusBadRequest {
+		t.Fatalf("out-of-turn pick status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+
+	select {
+	case event := <-events:
+		if event.Type != pubsub.EventError || event.Scope != "user-eager" {
+			t.Fatalf("event = %+v, want an error scoped to user-eager", event)
+		}
+		if event.Payload["action"] != "draft:pick" || event.Payload["playerId"] != player.ID || !strings.Contains(event.Payload["reason"].(string), "turn") {
+			t.Fatalf("payload = %v, want the failed pick and its reason", event.Payload)
+		}
+		if event.VisibleTo("user-other") || event.VisibleTo("") {
+			t.Fatal("error event is visible to other streams")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no error event published for the rejected pick")
+	}
+}
+
+func TestDraftPickPublishesOnClockForNextOwner(t *testing.T) {
+	h, store := newTestHandlers(t)
+	events := h.pubsub.Subscribe()
+	defer h.pubsub.Unsubscribe(events)
+
+	first, err := store.AddTeam("First", "First", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	second, err := store.AddTeam("Second", "", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	if _, err := store.ClaimTeam(second.ID, "user-second", "Second Owner"); err != nil {
+		t.Fatalf("ClaimTeam() failed: %v", err)
+	}
+	if _, err := store.SetDraftWindow(models.DraftWindow{ClosesAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("SetDraftWindow() failed: %v", err)
+	}
+	player, err := store.AddPlayer(&models.Player{Name: "Opening Pick", Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+	if _, err := store.AddPlayer(&models.Player{Name: "Next Pick", Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB}); err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+
+	body := `{"playerId":"` + player.ID + `","teamId":"` + first.ID + `"}`
+	if recorder := postJSON(h.DraftPick, "/api/draft/pick", body); recorder.Code != http.StatusOK {
+		t.Fatalf("pick status = %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Type != pubsub.EventOnClock {
+				continue
+			}
+			if event.Payload["teamId"] != second.ID || event.Payload["ownerUserId"] != "user-second" || event.Payload["owner"] != "Second Owner" {
+				t.Fatalf("payload = %v, want Second's owner on the clock", event.Payload)
+			}
+			if closesIn, _ := event.Payload["closesIn"].(int64); closesIn <= 0 {
+				t.Fatalf("closesIn = %v, want the window's remaining seconds", event.Payload["closesIn"])
+			}
+			return
+		case <-timeout:
+			t.Fatal("no draft:onclock event after the pick")
+		}
+	}
+}
+
+func TestJSONBodiesGetFriendlyDecodeErrors(t *testing.T) {
+	h, _ := newTestHandlers(t)
+
+	for name, tc := range map[string]struct{ body, want string }{
+		"malformed":     {`{"id": "1", "points": `, "invalid JSON body"},
+		"unknown field": {`{"id": "1", "pionts": 5}`, `unknown field "pionts"`},
+		"wrong type":    {`{"id": "1", "points": "five"}`, "field points must be a number"},
+		"empty":         {``, "request body is empty"},
+		"two objects":   {`{"id": "1"} {"id": "2"}`, "request body must contain a single JSON object"},
+	} {
+		recorder := postJSON(h.SetPlayerPoints, "/api/players/points", tc.body)
+		if got := strings.TrimSpace(recorder.Body.String()); recorder.Code != http.StatusBadRequest || got != tc.want {
+			t.Errorf("%s: %d %q, want 400 %q", name, recorder.Code, got, tc.want)
+		}
+	}
+}
+
+func TestMeReportsRoleAndTeam(t *testing.T) {
+	t.Setenv("AUTH_ADMIN_CLAIM", "")
+	t.Setenv("AUTH_ADMIN_VALUE", "")
+	h, store := newTestHandlers(t)
+
+	team, err := store.AddTeam("Mine", "", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	if _, err := store.ClaimTeam(team.ID, "user-owner", "owner"); err != nil {
+		t.Fatalf("ClaimTeam() failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		user     *auth.User
+		wantRole string
+		wantTeam string
+	}{
+		{"anonymous", nil, "spectator", ""},
+		{"spectator", &auth.User{ID: "user-other"}, "spectator", ""},
+		{"owner", &auth.User{ID: "user-owner"}, "owner", team.ID},
+		{"commissioner", &auth.User{ID: "user-commish", Groups: []string{"admins"}}, "commissioner", ""},
+		{"impersonated owner", &auth.User{ID: "user-owner", Groups: []string{"admins"}, Impersonator: &auth.User{ID: "user-commish"}}, "owner", team.ID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/api/me", nil)
+			if tt.user != nil {
+				request = request.WithContext(context.WithValue(request.Context(), "user", tt.user))
+			}
+			recorder := httptest.NewRecorder()
+			h.Me(recorder, request)
+
+			var response struct {
+				Authenticated bool   `json:"authenticated"`
+				Role          string `json:"role"`
+				TeamID        string `json:"teamId"`
+				Impersonating bool   `json:"impersonating"`
+			}
+			if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			wantImpersonating := tt.user != nil && tt.user.Impersonator != nil
+			if response.Role != tt.wantRole || response.TeamID != tt.wantTeam || response.Authenticated != (tt.user != nil) || response.Impersonating != wantImpersonating {
+				t.Fatalf("Me() = %+v, want role %q team %q", response, tt.wantRole, tt.wantTeam)
+			}
+		})
+	}
+}
+
+func TestMeListsEveryOwnedTeam(t *testing.T) {
+	h, store := newTestHandlers(t)
+
+	var ids []string
+	for _, name := range []string{"First", "Other", "Second"} {
+		team, err := store.AddTeam(name, "", "", "")
+		if err != nil {
+			t.Fatalf("AddTeam() failed: %v", err)
+		}
+		ids = append(ids, team.ID)
+	}
+	for _, id := range []string{ids[0], ids[2]} {
+		if _, err := store.ClaimTeam(id, "user-owner", "owner"); err != nil {
+			t.Fatalf("ClaimTeam() failed: %v", err)
+		}
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/api/me", nil)
+	request = request.WithContext(context.WithValue(request.Context(), "user", &auth.User{ID: "user-owner"}))
+	recorder := httptest.NewRecorder()
+	h.Me(recorder, request)
+
+	var response struct {
+		TeamID  string   `json:"teamId"`
+		TeamIDs []string `json:"teamIds"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if response.TeamID != ids[0] || strings.Join(response.TeamIDs, ",") != ids[0]+","+ids[2] {
+		t.Fatalf("Me() = %+v, want teamId %s and teamIds %v", response, ids[0], []string{ids[0], ids[2]})
+	}
+}
+
+func TestListAuthEventsAppliesFilters(t *testing.T) {
+	_, store := newTestHandlers(t)
+	for _, event := range []models.AuthEvent{
+		{Type: "login_failure", Username: "taylor", IP: "10.0.0.1", CreatedAt: 1000},
+		{Type: "login_success", UserID: "local:taylor", Username: "taylor", IP: "10.0.0.1", CreatedAt: 2000},
+		{Type: "login_failure", Username: "sam", IP: "10.0.0.2", CreatedAt: 3000},
+	} {
+		store.RecordAuthEvent(&event)
+	}
+	handler := NewAuthEventHandlers(store).ListAuthEvents
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, "/api/admin/auth-events?type=login_failure&user=taylor", nil))
+	var events []models.AuthEvent
+	if err := json.NewDecoder(recorder.Body).Decode(&events); err != nil {
+		t.Fatalf("decode events: %v", err)
+	}
+	if recorder.Code != http.StatusOK || len(events) != 1 || events[0].CreatedAt != 1000 {
+		t.Fatalf("ListAuthEvents() = %d %+v, want taylor's failure", recorder.Code, events)
+	}
+
+	for _, query := range []string{"since=yesterday", "until=1.5", "limit=0"} {
+		recorder := httptest.NewRecorder()
+		handler(recorder, httptest.NewRequest(http.MethodGet, "/api/admin/auth-events?"+query, nil))
+		if recorder.Code != http.StatusBadRequest {
+			t.Fatalf("ListAuthEvents(%s) status = %d, want %d", query, recorder.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+// impersonationProvider records the target passed to Impersonate.
+type impersonationProvider struct {
+	auth.AuthProvider
+	target *auth.User
+}
+
+func (p *impersonationProvider) Impersonate(w http.ResponseWriter, r *http.Request, target *auth.User) error {
+	p.target = target
+	return nil
+}
+
+func TestImpersonateResolvesTeamOwner(t *testing.T) {
+	_, store := newTestHandlers(t)
+	team, _ := store.AddTeam("Mine", "", "", "")
+	if _, err := store.ClaimTeam(team.ID, "user-owner", "Owner"); err != nil {
+		t.Fatalf("ClaimTeam() failed: %v", err)
+	}
+	provider := &impersonationProvider{}
+	handler := NewImpersonationHandlers(provider, store).Impersonate
+	admin := &auth.User{ID: "user-commish", Groups: []string{"admins"}}
+
+	if code := postJSONAs(handler, "/auth/impersonate", `{"username":"owner"}`, admin).Code; code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", code, http.StatusOK)
+	}
+	if provider.target == nil || provider.target.ID != "user-owner" {
+		t.Fatalf("target = %+v, want the team owner", provider.target)
+	}
+
+	if code := postJSONAs(handler, "/auth/impersonate", `{"username":"nobody"}`, admin).Code; code != http.StatusNotFound {
+		t.Fatalf("unknown user status = %d, want %d", code, http.StatusNotFound)
+	}
+	impersonating := &auth.User{ID: "user-owner", Impersonator: admin}
+	if code := postJSONAs(handler, "/auth/impersonate", `{"userId":"user-owner"}`, impersonating).Code; code != http.StatusForbidden {
+		t.Fatalf("nested impersonation status = %d, want %d", code, http.StatusForbidden)
+	}
+}
+
+func TestUndoLastPickRestoresMostRecentPick(t *testing.T) {
+	h, store := newTestHandlers(t)
+	team, _ := store.AddTeam("Undo", "", "", "")
+	player, _ := store.AddPlayer(&models.Player{Name: "Undo Pick", Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB})
+
+	if code := postJSON(h.UndoLastPick, "/api/draft/undo-last", "").Code; code != http.StatusNotFound {
+		t.Fatalf("undo-last on an empty draft status = %d, want %d", code, http.StatusNotFound)
+	}
+
+	if err := store.DraftPlayer(player.ID, team.ID); err != nil {
+		t.Fatalf("DraftPlayer() failed: %v", err)
+	}
+	recorder := postJSON(h.UndoLastPick, "/api/draft/undo-last", "")
+	var restored models.Player
+	if err := json.NewDecoder(recorder.Body).Decode(&restored); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if recorder.Code != http.StatusOK || restored.ID != player.ID || restored.Drafted {
+		t.Fatalf("undo-last = %d %+v, want %s back in the pool", recorder.Code, restored, player.Name)
+	}
+}
+
+func TestExportDraftFormats(t *testing.T) {
+	h, store := newTestHandlers(t)
+	otters, _ := store.AddTeam("Otters", "Olive", "", "")
+	bunnies, _ := store.AddTeam("Bunnies", "Bea", "", "")
+	var players []*models.Player
+	for _, name := range []string{"Bashful Bunny", "Amuseable Avocado", "Fuddlewuddle Lion", "Bartholomew Bear"} {
+		player, _ := store.AddPlayer(&models.Player{Name: name, Position: "CC", Team: "Jellycat", Points: 10, CuddlePoints: 50, Tier: models.TierB})
+		players = append(players, player)
+	}
+	// Snake order: Otters, Bunnies, Bunnies, Otters.
+	for i, teamID := range []string{otters.ID, bunnies.ID, bunnies.ID, otters.ID} {
+		if err := store.DraftPlayer(players[i].ID, teamID); err != nil {
+			t.Fatalf("DraftPlayer() pick %d failed: %v", i+1, err)
+		}
+	}
+
+	get := func(format string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		h.ExportDraft(recorder, httptest.NewRequest(http.MethodGet, "/api/draft/export?format="+format, nil))
+		return recorder
+	}
+
+	var generic struct {
+		Teams []struct {
+			Name  string
+			Picks []struct{ Pick, Round int }
+		}
+		Picks []struct {
+			Pick       int
+			TeamID     string
+			PlayerName string
+		}
+	}
+	recorder := get("")
+	if err := json.NewDecoder(recorder.Body).Decode(&generic); err != nil {
+		t.Fatalf("decode generic export: %v", err)
+	}
+	if len(generic.Picks) != 4 || generic.Picks[2].Pick != 3 || generic.Picks[2].TeamID != bunnies.ID || generic.Picks[2].PlayerName != "Fuddlewuddle Lion" {
+		t.Fatalf("generic picks = %+v, want four picks in snake order", generic.Picks)
+	}
+	if otterPicks := generic.Teams[0].Picks; len(otterPicks) != 2 || otterPicks[1].Pick != 4 || otterPicks[1].Round != 2 {
+		t.Fatalf("Otters picks = %+v, want picks 1 and 4", otterPicks)
+	}
+
+	var sleeper []struct {
+		Round     int               `json:"round"`
+		PickNo    int               `json:"pick_no"`
+		DraftSlot int               `json:"draft_slot"`
+		PlayerID  string            `json:"player_id"`
+		Metadata  map[string]string `json:"metadata"`
+	}
+	recorder = get("sleeper")
+	if err := json.NewDecoder(recorder.Body).Decode(&sleeper); err != nil {
+		t.Fatalf("decode sleeper export: %v", err)
+	}
+	last := sleeper[len(sleeper)-1]
+	if len(sleeper) != 4 || last.PickNo != 4 || last.Round != 2 || last.DraftSlot != 1 || last.PlayerID != players[3].ID || last.Metadata["last_name"] != "Bear" {
+		t.Fatalf("sleeper picks = %+v, want Bartholomew Bear as the Otters' pick 4", sleeper)
+	}
+
+	if code := get("espn").Code; code != http.StatusBadRequest {
+		t.Fatalf("unknown format status = %d, want %d", code, http.StatusBadRequest)
+	}
+}
+
+func TestGetDraftDiffReturnsOnlyChanges(t *testing.T) {
+	h, store := newTestHandlers(t)
+	picker, _ := store.AddTeam("Picker", "", "", "")
+	store.AddTeam("Bystander", "", "", "")
+	picked, _ := store.AddPlayer(&models.Player{Name: "Diff Pick", Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB})
+	benched, _ := store.AddPlayer(&models.Player{Name: "Diff Bench", Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB})
+
+	diff := func(since int64) models.DraftDiff {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		h.GetDraftDiff(recorder, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/draft/diff?since=%d", since), nil))
+		var body models.DraftDiff
+		if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+			t.Fatalf("decode diff: %v", err)
+		}
+		return body
+	}
+
+	full := diff(0)
+	if !full.Reset || len(full.Players) != 2 || len(full.Teams) != 2 {
+		t.Fatalf("initial diff = %+v, want a reset with the whole board", full)
+	}
+
+	if err := store.DraftPlayer(picked.ID, picker.ID); err != nil {
+		t.Fatalf("DraftPlayer() failed: %v", err)
+	}
+	afterPick := diff(full.Seq)
+	if afterPick.Reset || afterPick.Seq <= full.Seq {
+		t.Fatalf("diff after pick seq = %d reset = %v, want a newer incremental diff", afterPick.Seq, afterPick.Reset)
+	}
+	if len(afterPick.Players) != 1 || afterPick.Players[0].ID != picked.ID || !afterPick.Players[0].Drafted {
+		t.Fatalf("changed players = %+v, want only %s", afterPick.Players, picked.Name)
+	}
+	if len(afterPick.Teams) != 1 || afterPick.Teams[0].ID != picker.ID {
+		t.Fatalf("changed teams = %+v, want only %s", afterPick.Teams, picker.Name)
+	}
+	if len(afterPick.Chat) != 1 || !strings.Contains(afterPick.Chat[0].Text, picked.Name) {
+		t.Fatalf("new chat = %+v, want the pick announcement", afterPick.Chat)
+	}
+
+	if unchanged := diff(afterPick.Seq); unchanged.Seq != afterPick.Seq || len(unchanged.Players)+len(unchanged.Teams)+len(unchanged.Chat) != 0 {
+		t.Fatalf("diff without changes = %+v, want nothing new", unchanged)
+	}
+
+	store.DeletePlayer(benched.ID)
+	if removed := diff(afterPick.Seq); len(removed.RemovedPlayers) != 1 || removed.RemovedPlayers[0] != benched.ID {
+		t.Fatalf("removed players = %v, want [%s]", removed.RemovedPlayers, benched.ID)
+	}
+	if unknown := diff(afterPick.Seq + 100); !unknown.Reset {
+		t.Fatal("diff since an unissued seq did not reset")
+	}
+}
+
+func TestSendChatMessageGatesSystemMessages(t *testing.T) {
+	t.Setenv("AUTH_ADMIN_CLAIM", "")
+	t.Setenv("AUTH_ADMIN_VALUE", "")
+	h, _ := newTestHandlers(t)
+	spectator := &auth.User{ID: "user-spectator"}
+	admin := &auth.User{ID: "user-commish", Groups: []string{"admins"}}
+
+	if code := postJSONAs(h.SendChatMessage, "/api/chat/send", `{"text":"Pick is in","type":"system"}`, spectator).Code; code != http.StatusForbidden {
+		t.Fatalf("spectator system message status = %d, want %d", code, http.StatusForbidden)
+	}
+	if code := postJSONAs(h.SendChatMessage, "/api/chat/send", `{"text":"Round two","type":"system"}`, admin).Code; code != http.StatusOK {
+		t.Fatalf("commissioner system message status = %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestEventsSSEWarnsBeforeSessionExpires(t *testing.T) {
+	api, _ := newTestHandlers(t)
+	previous := sessionExpiryWarning
+	sessionExpiryWarning = 48 * time.Hour // longer than a mock session, so the warning is due at once
+	defer func() { sessionExpiryWarning = previous }()
+
+	provider, _ := auth.NewMockAuth()
+	login := httptest.NewRecorder()
+	provider.LoginHandler(login, httptest.NewRequest(http.MethodGet, "/auth/login", nil))
+	server := httptest.NewServer(provider.OptionalMiddleware(api.EventsSSE))
+	defer server.Close()
+
+	request, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	for _, cookie := range login.Result().Cookies() {
+		request.AddCookie(cookie)
+	}
+	response, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatalf("GET /api/events failed: %v", err)
+	}
+	defer response.Body.Close()
+
+	scanner := bufio.NewScanner(response.Body)
+	for scanner.Scan() {
+		if strings.Contains(scanner.Text(), `"type":"session:expiring"`) {
+			return
+		}
+	}
+	t.Fatal("stream ended without a session:expiring event")
+}
+
+// readSSEData reads the next message on an SSE stream, through the blank
+// line that ends it, and returns its data.
+func readSSEData(t *testing.T, reader *bufio.Reader) string {
+	t.Helper()
+	var data string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read SSE message: %v", err)
+		}
+		line = strings.TrimSpace(line)
+		if line == "" && data != "" {
+			return data
+		}
+		if value, ok := strings.CutPrefix(line, "data: "); ok {
+			data = value
+		}
+	}
+}
+
+func TestEventsSSEUsesTheStateVersionAsTheEventID(t *testing.T) {
+	api, store := newTestHandlers(t)
+	api.pubsub.StampVersions(store.Version)
+	server := httptest.NewServer(http.HandlerFunc(api.EventsSSE))
+	defer server.Close()
+
+	response, err := server.Client().Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET /api/events failed: %v", err)
+	}
+	defer response.Body.Close()
+	reader := bufio.NewReader(response.Body)
+	readSSEData(t, reader) // connected
+
+	team, err := store.AddTeam("Versioned", "", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	version, err := store.Version()
+	if err != nil {
+		t.Fatalf("Version() failed: %v", err)
+	}
+	api.pubsub.Publish(pubsub.Event{Type: "teams:add", Payload: map[string]interface{}{"id": team.ID}})
+
+	idLine, err := reader.ReadString('\n')
+	if err != nil {
+		t.Fatalf("read event: %v", err)
+	}
+	if want := fmt.Sprintf("id: %d", version); strings.TrimSpace(idLine) != want {
+		t.Fatalf("event id line = %q, want %q", strings.TrimSpace(idLine), want)
+	}
+	var event pubsub.Event
+	if err := json.Unmarshal([]byte(readSSEData(t, reader)), &event); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	if event.Type != "teams:add" || event.Version != version {
+		t.Fatalf("event = %+v, want teams:add at version %d", event, version)
+	}
+}
+
+func TestEventsSSEClientsGaugeTracksConnections(t *testing.T) {
+	api, _ := newTestHandlers(t)
+	server := httptest.NewServer(http.HandlerFunc(api.EventsSSE))
+	defer server.Close()
+
+	before := testutil.ToFloat64(sseClientsGauge)
+	connect := func() *http.Response {
+		t.Helper()
+		response, err := server.Client().Get(server.URL)
+		if err != nil {
+			t.Fatalf("GET /api/events failed: %v", err)
+		}
+		readSSEData(t, bufio.NewReader(response.Body)) // connected
+		return response
+	}
+	first := connect()
+	second := connect()
+	defer second.Body.Close()
+
+	if got := testutil.ToFloat64(sseClientsGauge); got != before+2 {
+		t.Fatalf("jellycat_sse_clients_active = %v with two clients, want %v", got, before+2)
+	}
+	if got := ActiveSSEClients(); float64(got) != before+2 {
+		t.Fatalf("ActiveSSEClients() = %d, want %v", got, before+2)
+	}
+
+	// The handler sees the disconnect asynchronously
+	first.Body.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for testutil.ToFloat64(sseClientsGauge) != before+1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("jellycat_sse_clients_active = %v after a disconnect, want %v", testutil.ToFloat64(sseClientsGauge), before+1)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestEventsSSESnapshotIsTheFirstMessage(t *testing.T) {
+	api, store := newTestHandlers(t)
+	if _, err := store.AddPlayer(&models.Player{Name: "Snapshot Bun", Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB}); err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(api.EventsSSE))
+	defer server.Close()
+
+	firstMessage := func(query string) string {
+		t.Helper()
+		response, err := server.Client().Get(server.URL + query)
+		if err != nil {
+			t.Fatalf("GET /api/events%s failed: %v", query, err)
+		}
+		defer response.Body.Close()
+		return readSSEData(t, bufio.NewReader(response.Body))
+	}
+
+	var snapshot struct {
+		Type    string `json:"type"`
+		Payload struct {
+			Seq   int64             `json:"seq"`
+			State models.DraftState `json:"state"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal([]byte(firstMessage("?snapshot=true")), &snapshot); err != nil {
+		t.Fatalf("decode snapshot: %v", err)
+	}
+	if snapshot.Type != EventSnapshot || snapshot.Payload.Seq == 0 || len(snapshot.Payload.State.Players) != 1 {
+		t.Fatalf("first message = %+v, want a snapshot of the one player with a seq", snapshot)
+	}
+
+	// The seq picks up where the snapshot left off
+	recorder := httptest.NewRecorder()
+	api.GetDraftDiff(recorder, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/draft/diff?since=%d", snapshot.Payload.Seq), nil))
+	var diff models.DraftDiff
+	if err := json.Unmarshal(recorder.Body.Bytes(), &diff); err != nil {
+		t.Fatalf("decode diff: %v", err)
+	}
+	if diff.Reset || len(diff.Players) != 0 || len(diff.Teams) != 0 {
+		t.Fatalf("diff since the snapshot = %+v, want no changes", diff)
+	}
+
+	if got := firstMessage(""); got != `{"type":"connected"}` {
+		t.Fatalf("first message without snapshot = %s, want connected", got)
+	}
+	response, err := server.Client().Get(server.URL + "?snapshot=maybe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("snapshot=maybe status = %d, want %d", response.StatusCode, http.StatusBadRequest)
+	}
+}
+
+// countingHistory serves a flat series and counts ClickHouse queries.
+type countingHistory struct{ calls int }
+
+func (c *countingHistory) GetCuddlePointsHistory(ctx context.Context, jellycatID string, days int) ([]clickhouse.DailyCuddlePoints, error) {
+	c.calls++
+	return clickhouse.HistorySeries(time.Now(), days, func(time.Time) int { return 7 }), nil
+}
+
+func TestGetCuddleHistoryCachesSeries(t *testing.T) {
+	_, store := newTestHandlers(t)
+	player, err := store.AddPlayer(&models.Player{Name: "History Bun", Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+	source := &countingHistory{}
+	h := NewCuddleHistoryHandlers(store, source)
+	now := time.Now()
+	h.now = func() time.Time { return now }
+
+	get := func(query string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		h.GetCuddleHistory(recorder, httptest.NewRequest(http.MethodGet, "/api/players/cuddle-history?"+query, nil))
+		return recorder
+	}
+
+	recorder := get("id=" + player.ID + "&days=7")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body.String())
+	}
+	var body struct {
+		Days    int                            `json:"days"`
+		History []clickhouse.DailyCuddlePoints `json:"history"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Days != 7 || len(body.History) != 7 || body.History[6].Points != 7 {
+		t.Fatalf("response = %+v, want 7 days of 7 points", body)
+	}
+
+	get("id=" + player.ID + "&days=7")
+	if source.calls != 1 {
+		t.Fatalf("ClickHouse queried %d times, want the second request cached", source.calls)
+	}
+	now = now.Add(historyCacheTTL)
+	get("id=" + player.ID + "&days=7")
+	if source.calls != 2 {
+		t.Fatalf("ClickHouse queried %d times, want a refresh once the cache expired", source.calls)
+	}
+
+	for query, want := range map[string]int{
+		"":                            http.StatusBadRequest,
+		"id=" + player.ID + "&days=0": http.StatusBadRequest,
+		"id=" + player.ID + "&days=x": http.StatusBadRequest,
+		"id=missing":                  http.StatusNotFound,
+	} {
+		if code := get(query).Code; code != want {
+			t.Errorf("?%s status = %d, want %d", query, code, want)
+		}
+	}
+}
+
+// countingEngagement gives every roster one interaction per Jellycat and
+// counts ClickHouse queries.
+type countingEngagement struct{ calls int }
+
+func (c *countingEngagement) GetTeamEngagement(ctx context.Context, rosters map[string][]string, days int) (map[string]clickhouse.TeamEngagement, error) {
+	c.calls++
+	engagement := make(map[string]clickhouse.TeamEngagement)
+	for teamID, roster := range rosters {
+		engagement[teamID] = clickhouse.TeamEngagement{Interactions: len(roster), UniqueUsers: 1, CuddlePoints: 30 * len(roster)}
+	}
+	return engagement, nil
+}
+
+func TestGetTeamEngagementRanksTeams(t *testing.T) {
+	_, store := newTestHandlers(t)
+	quiet, err := store.AddTeam("Quiet", "", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	busy, err := store.AddTeam("Busy", "", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	for i, teamID := range []string{quiet.ID, busy.ID, busy.ID} {
+		player, err := store.AddPlayer(&models.Player{Name: fmt.Sprintf("Engaged Bun %d", i), Position: "CC", Team: "Test", Points: 10, CuddlePoints: 40 + 20*i, Tier: models.TierB})
+		if err != nil {
+			t.Fatalf("AddPlayer() failed: %v", err)
+		}
+		if err := store.DraftPlayer(player.ID, teamID); err != nil {
+			t.Fatalf("DraftPlayer() failed: %v", err)
+		}
+	}
+
+	get := func(h *EngagementHandlers, query string) (int, string, []teamEngagement) {
+		recorder := httptest.NewRecorder()
+		h.GetTeamEngagement(recorder, httptest.NewRequest(http.MethodGet, "/api/teams/engagement?"+query, nil))
+		var body struct {
+			Source string           `json:"source"`
+			Teams  []teamEngagement `json:"teams"`
+		}
+		json.Unmarshal(recorder.Body.Bytes(), &body)
+		return recorder.Code, body.Source, body.Teams
+	}
+	find := func(teams []teamEngagement, id string) teamEngagement {
+		for _, team := range teams {
+			if team.TeamID == id {
+				return team
+			}
+		}
+		t.Fatalf("team %s missing from %+v", id, teams)
+		return teamEngagement{}
+	}
+
+	code, source, teams := get(NewEngagementHandlers(store, nil), "")
+	if code != http.StatusOK || source != "draft" {
+		t.Fatalf("fallback status = %d, source = %q", code, source)
+	}
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	for _, team := range state.Teams {
+		if team.ID != busy.ID {
+			continue
+		}
+		// Drafting personalizes cuddle points, so average what the team holds.
+		want := float64(team.Players[0].CuddlePoints+team.Players[1].CuddlePoints) / 2
+		if got := find(teams, busy.ID).AverageCuddlePoints; got != want {
+			t.Errorf("Busy average = %v, want %v from the draft's cuddle points", got, want)
+		}
+	}
+
+	counting := &countingEngagement{}
+	h := NewEngagementHandlers(store, counting)
+	now := time.Now()
+	h.now = func() time.Time { return now }
+	code, source, teams = get(h, "days=7")
+	if code != http.StatusOK || source != "clickhouse" {
+		t.Fatalf("status = %d, source = %q", code, source)
+	}
+	if teams[0].TeamID != busy.ID || teams[0].Interactions != 2 || teams[0].AverageCuddlePoints != 30 {
+		t.Fatalf("first team = %+v, want Busy with 2 interactions averaging 30", teams[0])
+	}
+
+	get(h, "days=7")
+	if counting.calls != 1 {
+		t.Fatalf("ClickHouse queried %d times, want the second request cached", counting.calls)
+	}
+	now = now.Add(engagementCacheTTL)
+	get(h, "days=7")
+	if counting.calls != 2 {
+		t.Fatalf("ClickHouse queried %d times, want a refresh once the cache expired", counting.calls)
+	}
+
+	for _, query := range []string{"days=0", "days=91", "days=x"} {
+		if code, _, _ := get(h, query); code != http.StatusBadRequest {
+			t.Errorf("?%s status = %d, want 400", query, code)
+		}
+	}
+}
+
+func TestAuctionEndpointsRunACycle(t *testing.T) {
+	h, store := newTestHandlers(t)
+	t.Setenv("DRAFT_MODE", "auction")
+	events := h.pubsub.Subscribe()
+	defer h.pubsub.Unsubscribe(events)
+
+	first, err := store.AddTeam("First", "", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	second, err := store.AddTeam("Second", "", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	player, err := store.AddPlayer(&models.Player{Name: "Gavel Bun", Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+
+	steps := []struct {
+		handler http.HandlerFunc
+		body    string
+		status  int
+	}{
+		{h.NominatePlayer, `{"playerId":"` + player.ID + `","teamId":"` + first.ID + `","openingBid":3}`, http.StatusOK},
+		{h.PlaceBid, `{"playerId":"` + player.ID + `","teamId":"` + second.ID + `","amount":3}`, http.StatusBadRequest},
+		{h.PlaceBid, `{"playerId":"` + player.ID + `","teamId":"` + second.ID + `","amount":9}`, http.StatusOK},
+		{h.AwardPlayer, ``, http.StatusOK},
+	}
+	for i, step := range steps {
+		if recorder := postJSON(step.handler, "/api/auction", step.body); recorder.Code != step.status {
+			t.Fatalf("step %d status = %d, want %d: %s", i, recorder.Code, step.status, recorder.Body.String())
+		}
+	}
+
+	var seen []string
+	for len(events) > 0 {
+		event := <-events
+		seen = append(seen, event.Type)
+		if event.Type == "auction:award" && (event.Payload["highBidder"] != second.ID || event.Payload["highBid"] != 9) {
+			t.Fatalf("award payload = %v, want Second winning at 9", event.Payload)
+		}
+	}
+	if got := strings.Join(seen, ","); !strings.HasPrefix(got, "auction:nominate,auction:bid,auction:award,draft:pick") {
+		t.Fatalf("events = %s, want nominate, bid, award then the pick", got)
+	}
+}
+
+func TestMockDraftPicksStayOutOfRealState(t *testing.T) {
+	_, store := newTestHandlers(t)
+	mock := NewMockDraftHandlers(store)
+
+	team, err := store.AddTeam("Practice", "Practice", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() error = %v", err)
+	}
+	player, err := store.AddPlayer(&models.Player{Name: "Practice Pick", Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("AddPlayer() error = %v", err)
+	}
+	before, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() error = %v", err)
+	}
+	alice := &auth.User{ID: "alice"}
+	bob := &auth.User{ID: "bob"}
+
+	if rec := postJSONAs(mock.MockDraftPick, "/api/draft/mock/pick", `{}`, alice); rec.Code != http.StatusNotFound {
+		t.Fatalf("pick before start status = %d, want 404", rec.Code)
+	}
+	if rec := postJSONAs(mock.StartMockDraft, "/api/draft/mock/start", `{}`, alice); rec.Code != http.StatusOK {
+		t.Fatalf("start status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	body := fmt.Sprintf(`{"playerId":%q,"teamId":%q}`, player.ID, team.ID)
+	rec := postJSONAs(mock.MockDraftPick, "/api/draft/mock/pick", body, alice)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("mock pick status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var mockState models.DraftState
+	if err := json.Unmarshal(rec.Body.Bytes(), &mockState); err != nil {
+		t.Fatalf("decode mock state: %v", err)
+	}
+	if !mockState.Players[0].Drafted || len(mockState.Teams[0].Players) != 1 {
+		t.Fatalf("mock state = %+v, want the player drafted", mockState)
+	}
+
+	real, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() error = %v", err)
+	}
+	if real.Players[0].Drafted || len(real.Teams[0].Players) != 0 || len(real.Chat) != len(before.Chat) {
+		t.Fatalf("real state = %+v, want the mock pick kept out", real)
+	}
+
+	// Another user has no mock draft of their own.
+	if rec := postJSONAs(mock.MockDraftPick, "/api/draft/mock/pick", body, bob); rec.Code != http.StatusNotFound {
+		t.Fatalf("other user's pick status = %d, want 404", rec.Code)
+	}
+
+	if rec := postJSONAs(mock.ResetMockDraft, "/api/draft/mock/reset", `{}`, alice); rec.Code != http.StatusOK {
+		t.Fatalf("reset status = %d", rec.Code)
+	}
+	if rec := postJSONAs(mock.MockDraftPick, "/api/draft/mock/pick", body, alice); rec.Code != http.StatusNotFound {
+		t.Fatalf("pick after reset status = %d, want 404", rec.Code)
+	}
+}
+
+func TestGetChatSinceReturnsLaterMessages(t *testing.T) {
+	h, store := newTestHandlers(t)
+
+	first, err := store.AddChatMessage("first", "chat")
+	if err != nil {
+		t.Fatalf("AddChatMessage() error = %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	h.GetChatSince(recorder, httptest.NewRequest(http.MethodGet, "/api/chat/since?ts=nope", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("bad ts status = %d, want 400", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	h.GetChatSince(recorder, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/chat/since?ts=%d", first.TS-1), nil))
+	var messages []models.ChatMessage
+	if err := json.NewDecoder(recorder.Body).Decode(&messages); err != nil {
+		t.Fatalf("decode messages: %v", err)
+	}
+	if len(messages) != 1 || messages[0].ID != first.ID {
+		t.Fatalf("messages = %v, want just the first", messages)
+	}
+
+	recorder = httptest.NewRecorder()
+	h.GetChatSince(recorder, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/chat/since?ts=%d", first.TS), nil))
+	if body := strings.TrimSpace(recorder.Body.String()); body != "[]" {
+		t.Fatalf("body = %s, want []", body)
+	}
+}
+
+func TestGetTopReactionsRanksMessages(t *testing.T) {
+	h, store := newTestHandlers(t)
+
+	quiet, _ := store.AddChatMessage("quiet", "chat")
+	liked, _ := store.AddChatMessage("liked", "chat")
+	loved, _ := store.AddChatMessage("loved", "chat")
+	for _, user := range []string{"u1", "u2"} {
+		if _, err := store.AddReaction(loved.ID, "❤️", user); err != nil {
+			t.Fatalf("AddReaction() error = %v", err)
+		}
+	}
+	if _, err := store.AddReaction(liked.ID, "👍", "u1"); err != nil {
+		t.Fatalf("AddReaction() error = %v", err)
+	}
+
+	for _, query := range []string{"limit=0", "limit=many"} {
+		recorder := httptest.NewRecorder()
+		h.GetTopReactions(recorder, httptest.NewRequest(http.MethodGet, "/api/chat/top-reactions?"+query, nil))
+		if recorder.Code != http.StatusBadRequest {
+			t.Fatalf("%s status = %d, want 400", query, recorder.Code)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	h.GetTopReactions(recorder, httptest.NewRequest(http.MethodGet, "/api/chat/top-reactions", nil))
+	var messages []models.ChatMessage
+	if err := json.NewDecoder(recorder.Body).Decode(&messages); err != nil {
+		t.Fatalf("decode messages: %v", err)
+	}
+	if len(messages) != 2 || messages[0].ID != loved.ID || messages[1].ID != liked.ID {
+		t.Fatalf("messages = %v, want loved then liked and not %s", messages, quiet.ID)
+	}
+
+	recorder = httptest.NewRecorder()
+	h.GetTopReactions(recorder, httptest.NewRequest(http.MethodGet, "/api/chat/top-reactions?limit=1", nil))
+	messages = nil
+	if err := json.NewDecoder(recorder.Body).Decode(&messages); err != nil {
+		t.Fatalf("decode messages: %v", err)
+	}
+	if len(messages) != 1 || messages[0].ID != loved.ID {
+		t.Fatalf("limit=1 messages = %v, want just loved", messages)
+	}
+}
+
+func TestWriteErrorUsesTheMappingTable(t *testing.T) {
+	logger.Init()
+	for _, tc := range []struct {
+		err    error
+		status int
+		want   errorResponse
+	}{
+		{dal.ErrTeamNotFound, http.StatusNotFound, errorResponse{Error: "team not found", Code: "not_found", Entity: "team"}},
+		{dal.ErrPlayerAlreadyDrafted, http.StatusConflict, errorResponse{Error: "player already drafted", Code: "already_drafted"}},
+		{dal.ErrTeamAlreadyClaimed, http.StatusConflict, errorResponse{Error: "team is already claimed by another user", Code: "conflict"}},
+		{dal.ValidateNewTeam(""), http.StatusBadRequest, errorResponse{Error: "team name is required", Code: "validation_failed", Field: "name"}},
+		{errNotTeamOwner, http.StatusForbidden, errorResponse{Error: errNotTeamOwner.Error(), Code: "forbidden"}},
+		{fmt.Errorf("sql: database is closed"), http.StatusInternalServerError, errorResponse{Error: "internal server error", Code: "internal"}},
+	} {
+		recorder := httptest.NewRecorder()
+		writeError(recorder, httptest.NewRequest(http.MethodGet, "/api/state", nil), tc.err)
+		var got errorResponse
+		if err := json.NewDecoder(recorder.Body).Decode(&got); err != nil {
+			t.Fatalf("decode %v: %v", tc.err, err)
+		}
+		if recorder.Code != tc.status || got != tc.want {
+			t.Errorf("writeError(%v) = %d %+v, want %d %+v", tc.err, recorder.Code, got, tc.status, tc.want)
+		}
+	}
+}
+
+func TestGetReactionUsersListsEachReactor(t *testing.T) {
+	h, store := newTestHandlers(t)
+
+	msg, err := store.AddChatMessage("Great pick", "chat")
+	if err != nil {
+		t.Fatalf("AddChatMessage() error = %v", err)
+	}
+	for _, user := range []string{"user-2", "user-1"} {
+		if _, err := store.AddReaction(msg.ID, "👍", user); err != nil {
+			t.Fatalf("AddReaction() error = %v", err)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	h.GetReactionUsers(recorder, httptest.NewRequest(http.MethodGet, "/api/chat/reactions?messageId="+msg.ID, nil))
+	var users map[string][]string
+	if err := json.NewDecoder(recorder.Body).Decode(&users); err != nil {
+		t.Fatalf("decode users: %v", err)
+	}
+	if got := users["👍"]; len(got) != 2 || got[0] != "user-1" || got[1] != "user-2" {
+		t.Fatalf("users = %v, want user-1 and user-2 under 👍", users)
+	}
+
+	for query, status := range map[string]int{"": http.StatusBadRequest, "?messageId=missing": http.StatusNotFound} {
+		recorder = httptest.NewRecorder()
+		h.GetReactionUsers(recorder, httptest.NewRequest(http.MethodGet, "/api/chat/reactions"+query, nil))
+		if recorder.Code != status {
+			t.Fatalf("%q status = %d, want %d", query, recorder.Code, status)
+		}
+	}
+}
+
+func TestGetDraftStateSortsPlayersOnEveryBackend(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	logger.Init()
+
+	sqliteStore, err := dal.NewSQLiteDAL(t.TempDir() + "/draft.sqlite")
+	if err != nil {
+		t.Fatalf("NewSQLiteDAL() error = %v", err)
+	}
+	for name, store := range map[string]dal.DraftDAL{"memory": dal.NewMemoryDAL(), "sqlite": sqliteStore} {
+		t.Run(name, func(t *testing.T) {
+			h := NewAPIHandlers(store, pubsub.New())
+			var bunnyID string
+			for _, player := range []models.Player{
+				{Name: "Bunny", Points: 20, CuddlePoints: 40},
+				{Name: "avocado", Points: 50, CuddlePoints: 10},
+				{Name: "Cactus", Points: 20, CuddlePoints: 90},
+			} {
+				player.Position, player.Team, player.Tier = "CC", "Test", models.TierB
+				added, err := store.AddPlayer(&player)
+				if err != nil {
+					t.Fatalf("AddPlayer() error = %v", err)
+				}
+				if added.Name == "Bunny" {
+					bunnyID = added.ID
+				}
+				time.Sleep(2 * time.Millisecond)
+			}
+
+			names := func(query string) []string {
+				t.Helper()
+				recorder := httptest.NewRecorder()
+				h.GetDraftState(recorder, httptest.NewRequest(http.MethodGet, "/api/draft/state"+query, nil))
+				if recorder.Code != http.StatusOK {
+					t.Fatalf("GetDraftState(%q) status = %d", query, recorder.Code)
+				}
+				var state models.DraftState
+				if err := json.NewDecoder(recorder.Body).Decode(&state); err != nil {
+					t.Fatalf("decode state: %v", err)
+				}
+				var names []string
+				for _, player := range state.Players {
+					names = append(names, player.Name)
+				}
+				return names
+			}
+
+			// Points, highest first; the tie keeps insertion order.
+			if got := fmt.Sprint(names("")); got != "[avocado Bunny Cactus]" {
+				t.Errorf("default order = %s", got)
+			}
+			if got := fmt.Sprint(names("?sort=name&dir=asc")); got != "[avocado Bunny Cactus]" {
+				t.Errorf("name asc order = %s", got)
+			}
+			if got := fmt.Sprint(names("?sort=cuddlePoints")); got != "[Cactus Bunny avocado]" {
+				t.Errorf("cuddlePoints desc order = %s", got)
+			}
+
+			// The most recently changed player comes first.
+			if _, err := store.SetPlayerPoints(bunnyID, 20); err != nil {
+				t.Fatalf("SetPlayerPoints() error = %v", err)
+			}
+			if got := fmt.Sprint(names("?sort=updatedAt")); got != "[Bunny Cactus avocado]" {
+				t.Errorf("updatedAt desc order = %s", got)
+			}
+			if got := fmt.Sprint(names("?sort=createdAt&dir=asc")); got != "[Bunny avocado Cactus]" {
+				t.Errorf("createdAt asc order = %s", got)
+			}
+
+			recorder := httptest.NewRecorder()
+			h.GetDraftState(recorder, httptest.NewRequest(http.MethodGet, "/api/draft/state?sort=height", nil))
+			if recorder.Code != http.StatusBadRequest {
+				t.Errorf("unknown sort status = %d, want 400", recorder.Code)
+			}
+		})
+	}
+}
+
+func TestChatDisabledHidesChatAndKeepsPicksWorking(t *testing.T) {
+	h, store := newTestHandlers(t)
+	t.Setenv("ENABLE_CHAT", "false")
+	events := h.pubsub.Subscribe()
+
+	recorder := httptest.NewRecorder()
+	h.ListChat(recorder, httptest.NewRequest(http.MethodGet, "/api/chat/list", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("list status = %d, want %d", recorder.Code, http.StatusNotFound)
+	}
+	recorder = httptest.NewRecorder()
+	h.GetChatSince(recorder, httptest.NewRequest(http.MethodGet, "/api/chat/since?ts=0", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("since status = %d, want %d", recorder.Code, http.StatusNotFound)
+	}
+	if code := postJSON(h.SendChatMessage, "/api/chat/send", `{"text":"hello"}`).Code; code != http.StatusNotFound {
+		t.Fatalf("send status = %d, want %d", code, http.StatusNotFound)
+	}
+
+	team, _ := store.AddTeam("Foxes", "", "", "")
+	player, err := store.AddPlayer(&models.Player{Name: "Quiet Pick", Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+	recorder = postJSON(h.DraftPick, "/api/draft/pick", `{"playerId":"`+player.ID+`","teamId":"`+team.ID+`"}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("pick status = %d: %s", recorder.Code, recorder.Body.String())
+	}
+	sawPick := false
+	for len(events) > 0 {
+		event := <-events
+		if strings.HasPrefix(event.Type, "chat:") {
+			t.Fatalf("published %s with chat disabled", event.Type)
+		}
+		sawPick = sawPick || event.Type == "draft:pick"
+	}
+	if !sawPick {
+		t.Fatal("pick published no draft:pick event")
+	}
+
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+	if len(state.Chat) != 0 || !state.Players[0].Drafted {
+		t.Fatalf("GetState() = %d messages, drafted %v; want the pick without chat", len(state.Chat), state.Players[0].Drafted)
+	}
+}
+
+func TestAddReactionRejectsUnknownEmotes(t *testing.T) {
+	h, store := newTestHandlers(t)
+	t.Setenv("REACTION_EMOTES", "")
+	msg, err := store.AddChatMessage("Great pick", "user")
+	if err != nil {
+		t.Fatalf("AddChatMessage() failed: %v", err)
+	}
+
+	recorder := postJSON(h.AddReaction, "/api/chat/react", `{"messageId":"`+msg.ID+`","emote":"lol"}`)
+	if recorder.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("unknown emote status = %d, want %d", recorder.Code, http.StatusUnprocessableEntity)
+	}
+	recorder = postJSON(h.AddReaction, "/api/chat/react", `{"messageId":"`+msg.ID+`","emote":"🔥"}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("allowed emote status = %d: %s", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestResetDraftDryRunChangesNothing(t *testing.T) {
+	h, store := newTestHandlers(t)
+	team, err := store.AddTeam("Foxes", "", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	player, err := store.AddPlayer(&models.Player{Name: "Bashful Bunny", Position: "CC", Team: "Test", Points: 10, Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+	if err := store.DraftPlayer(player.ID, team.ID); err != nil {
+		t.Fatalf("DraftPlayer() failed: %v", err)
+	}
+	version, err := store.Version()
+	if err != nil {
+		t.Fatalf("Version() failed: %v", err)
+	}
+
+	recorder := postJSON(h.ResetDraft, "/api/draft/reset?dryRun=true", "")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("dry run status = %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var preview models.ResetPreview
+	if err := json.Unmarshal(recorder.Body.Bytes(), &preview); err != nil {
+		t.Fatalf("decode preview: %v", err)
+	}
+	if preview.PicksRemoved != 1 || preview.CustomPlayersDeleted != 1 || len(preview.TeamsAffected) != 1 {
+		t.Fatalf("preview = %+v, want 1 pick, 1 custom player and 1 team", preview)
+	}
+	if after, _ := store.Version(); after != version {
+		t.Fatalf("version = %d after dry run, want %d", after, version)
+	}
+
+	recorder = postJSON(h.ResetDraft, "/api/draft/reset?dryRun=maybe", "")
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("bad dryRun status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+	if after, _ := store.Version(); after != version {
+		t.Fatal("rejected dry run reset the draft")
+	}
+}
+
+func TestDraftMetaRoundTripsAndPublishes(t *testing.T) {
+	h, _ := newTestHandlers(t)
+	events := h.pubsub.Subscribe()
+	defer h.pubsub.Unsubscribe(events)
+
+	recorder := postJSON(h.SetDraftMeta, "/api/draft/meta", `{"title":" Springfield Plush League ","description":"Season two"}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("set status = %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if event := <-events; event.Type != "draft:meta" {
+		t.Fatalf("published %s, want draft:meta", event.Type)
+	}
+
+	recorder = httptest.NewRecorder()
+	h.GetDraftMeta(recorder, httptest.NewRequest(http.MethodGet, "/api/draft/meta", nil))
+	var meta models.DraftMeta
+	if err := json.Unmarshal(recorder.Body.Bytes(), &meta); err != nil {
+		t.Fatalf("decode meta: %v", err)
+	}
+	if meta.Title != "Springfield Plush League" || meta.Description != "Season two" {
+		t.Fatalf("GetDraftMeta() = %+v, want the cleaned title and description", meta)
+	}
+
+	recorder = postJSON(h.SetDraftMeta, "/api/draft/meta", `{"title":"`+strings.Repeat("a", 101)+`"}`)
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("long title status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestChatJSONIncludesAnISOTimestamp(t *testing.T) {
+	h, store := newTestHandlers(t)
+	msg, err := store.AddChatMessage("Great pick", "user")
+	if err != nil {
+		t.Fatalf("AddChatMessage() failed: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	h.ListChat(recorder, httptest.NewRequest(http.MethodGet, "/api/chat/list", nil))
+	var messages []map[string]interface{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &messages); err != nil {
+		t.Fatalf("decode chat: %v", err)
+	}
+	if len(messages) != 1 {
+		t.Fatalf("ListChat() returned %d messages, want 1", len(messages))
+	}
+	if ts, ok := messages[0]["ts"].(float64); !ok || int64(ts) != msg.TS {
+		t.Fatalf("ts = %v, want %d", messages[0]["ts"], msg.TS)
+	}
+	iso, _ := messages[0]["tsIso"].(string)
+	parsed, err := time.Parse(time.RFC3339, iso)
+	if err != nil {
+		t.Fatalf("tsIso %q is not RFC 3339: %v", iso, err)
+	}
+	if parsed.UnixMilli() != msg.TS || !strings.HasSuffix(iso, "Z") {
+		t.Fatalf("tsIso = %q, want %d in UTC", iso, msg.TS)
+	}
+}
+
+func TestSuggestPickNeedsATeam(t *testing.T) {
+	h, store := newTestHandlers(t)
+	team, err := store.AddTeam("Suggest Foxes", "", "", "")
+	if err != nil {
+		t.Fatalf("AddTeam() failed: %v", err)
+	}
+	player, err := store.AddPlayer(&models.Player{Name: "Top Bunny", Position: "CC", Team: "Test", Points: 300, Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	h.SuggestPick(recorder, httptest.NewRequest(http.MethodGet, "/api/draft/suggest", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("SuggestPick() without teamId status = %d, want 400", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	h.SuggestPick(recorder, httptest.NewRequest(http.MethodGet, "/api/draft/suggest?teamId="+team.ID, nil))
+	var suggested models.Player
+	if err := json.Unmarshal(recorder.Body.Bytes(), &suggested); err != nil {
+		t.Fatalf("decode suggestion: %v", err)
+	}
+	if recorder.Code != http.StatusOK || suggested.ID != player.ID {
+		t.Fatalf("SuggestPick() = %d %+v, want 200 %s", recorder.Code, suggested, player.Name)
+	}
+
+	recorder = httptest.NewRecorder()
+	h.SuggestPick(recorder, httptest.NewRequest(http.MethodGet, "/api/draft/suggest?teamId=missing", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("SuggestPick(unknown team) status = %d, want 404", recorder.Code)
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/auth"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+)
+
+// ImpersonationHandlers lets commissioners see the app as a team owner
+type ImpersonationHandlers struct {
+	provider auth.AuthProvider
+	dal      dal.DraftDAL
+}
+
+// NewImpersonationHandlers creates a new impersonation handlers instance
+func NewImpersonationHandlers(provider auth.AuthProvider, store dal.DraftDAL) *ImpersonationHandlers {
+	return &ImpersonationHandlers{provider: provider, dal: store}
+}
+
+// Impersonate switches the caller's session to the owner of a claimed team,
+// found by {"username"} or {"userId"}. Route it behind the commissioner role.
+func (h *ImpersonationHandlers) Impersonate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if user := auth.GetUser(r); user == nil || user.Impersonator != nil {
+		http.Error(w, "Forbidden: cannot impersonate from an impersonated session", http.StatusForbidden)
+		return
+	}
+
+	var req struct {
+		Username string `json:"username"`
+		UserID   string `json:"userId"`
+	}
+	if err := DecodeJSON(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Username = strings.TrimSpace(req.Username)
+	if req.Username == "" && req.UserID == "" {
+		http.Error(w, "username or userId is required", http.StatusBadRequest)
+		return
+	}
+
+	state, err := h.dal.GetState()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var target *auth.User
+	for _, team := range state.Teams {
+		if team.OwnerUserID == "" {
+			continue
+		}
+		if team.OwnerUserID == req.UserID || (req.Username != "" && strings.EqualFold(team.Owner, req.Username)) {
+			target = &auth.User{ID: team.OwnerUserID, Name: team.Owner, Username: team.Owner}
+			break
+		}
+	}
+	if target == nil {
+		http.Error(w, "No team owner matches that user", http.StatusNotFound)
+		return
+	}
+
+	if err := h.provider.Impersonate(w, r, target); err != nil {
+		writeImpersonationError(w, err)
+		return
+	}
+
+	logger.FromContext(r.Context()).Info("Impersonation started", "admin", auth.GetUser(r).ID, "user", target.ID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "user": target})
+}
+
+// Unimpersonate returns an impersonated session to the admin who started it.
+func (h *ImpersonationHandlers) Unimpersonate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := h.provider.Unimpersonate(w, r); err != nil {
+		writeImpersonationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+}
+
+func writeImpersonationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, auth.ErrNoSession), errors.Is(err, auth.ErrNotImpersonating):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, auth.ErrAlreadyImpersonating):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		logger.Error("Failed to update session", "error", err)
+		http.Error(w, "Session store unavailable, please try again", http.StatusServiceUnavailable)
+	}
+}
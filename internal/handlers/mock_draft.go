@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/auth"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// mockDraftIdleTTL is how long an untouched mock draft is kept.
+const mockDraftIdleTTL = 2 * time.Hour
+
+// MockDraftHandlers runs practice drafts. Each caller gets a private in-memory
+// copy of the real players and teams; mock picks never reach the shared store
+// and publish no events.
+type MockDraftHandlers struct {
+	dal dal.DraftDAL
+	now func() time.Time
+
+	mu     sync.Mutex
+	drafts map[string]*mockDraft
+}
+
+type mockDraft struct {
+	store    *dal.MemoryDAL
+	lastUsed time.Time
+}
+
+// NewMockDraftHandlers creates a new mock draft handlers instance
+func NewMockDraftHandlers(store dal.DraftDAL) *MockDraftHandlers {
+	return &MockDraftHandlers{
+		dal:    store,
+		now:    time.Now,
+		drafts: make(map[string]*mockDraft),
+	}
+}
+
+// mockDraftKey scopes mock drafts to the login session, falling back to the
+// user for access-token callers.
+func mockDraftKey(r *http.Request) (string, bool) {
+	user := auth.GetUser(r)
+	if user == nil {
+		return "", false
+	}
+	if cookie, err := r.Cookie("session_id"); err == nil && cookie.Value != "" {
+		return "session:" + cookie.Value, true
+	}
+	return "user:" + user.ID, true
+}
+
+// lookup returns the caller's mock draft and marks it used, dropping any that
+// have sat idle too long.
+func (h *MockDraftHandlers) lookup(key string) *dal.MemoryDAL {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	for k, d := range h.drafts {
+		if now.Sub(d.lastUsed) > mockDraftIdleTTL {
+			delete(h.drafts, k)
+		}
+	}
+	d, ok := h.drafts[key]
+	if !ok {
+		return nil
+	}
+	d.lastUsed = now
+	return d.store
+}
+
+// StartMockDraft copies the current players and teams into a fresh mock
+// draft for the caller, replacing any they already had.
+func (h *MockDraftHandlers) StartMockDraft(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	key, ok := mockDraftKey(r)
+	if !ok {
+		http.Error(w, "Unauthorized: login required", http.StatusUnauthorized)
+		return
+	}
+
+	state, err := h.dal.GetState()
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to load draft state for mock draft", "error", err)
+		writeError(w, r, err)
+		return
+	}
+	store := dal.NewMemoryDALFromState(state)
+
+	h.mu.Lock()
+	h.drafts[key] = &mockDraft{store: store, lastUsed: h.now()}
+	h.mu.Unlock()
+
+	h.writeState(w, r, store)
+}
+
+// GetMockDraftState returns the caller's mock draft.
+func (h *MockDraftHandlers) GetMockDraftState(w http.ResponseWriter, r *http.Request) {
+	store, ok := h.callerDraft(w, r)
+	if !ok {
+		return
+	}
+	h.writeState(w, r, store)
+}
+
+// MockDraftPick drafts a player in the caller's mock draft and returns the
+// updated mock state.
+func (h *MockDraftHandlers) MockDraftPick(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		PlayerID string `json:"playerId"`
+		TeamID   string `json:"teamId"`
+	}
+	if err := DecodeJSON(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	store, ok := h.callerDraft(w, r)
+	if !ok {
+		return
+	}
+
+	if err := store.DraftPlayer(req.PlayerID, req.TeamID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	h.writeState(w, r, store)
+}
+
+// ResetMockDraft discards the caller's mock draft.
+func (h *MockDraftHandlers) ResetMockDraft(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	key, ok := mockDraftKey(r)
+	if !ok {
+		http.Error(w, "Unauthorized: login required", http.StatusUnauthorized)
+		return
+	}
+
+	h.mu.Lock()
+	delete(h.drafts, key)
+	h.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+}
+
+// callerDraft finds the caller's mock draft, writing the error response when
+// there is none.
+func (h *MockDraftHandlers) callerDraft(w http.ResponseWriter, r *http.Request) (*dal.MemoryDAL, bool) {
+	key, ok := mockDraftKey(r)
+	if !ok {
+		http.Error(w, "Unauthorized: login required", http.StatusUnauthorized)
+		return nil, false
+	}
+	store := h.lookup(key)
+	if store == nil {
+		http.Error(w, "No mock draft running; start one with POST /api/draft/mock/start", http.StatusNotFound)
+		return nil, false
+	}
+	return store, true
+}
+
+func (h *MockDraftHandlers) writeState(w http.ResponseWriter, r *http.Request, store *dal.MemoryDAL) {
+	state, err := store.GetState()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		*models.DraftState
+		Mock bool `json:"mock"`
+	}{state, true})
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/pubsub"
+)
+
+// ReservePick holds an undrafted player for the team on the clock for a few
+// seconds (PICK_RESERVATION_TTL) so the UI can show the pick before it is
+// confirmed with DraftPick. Until it expires, other teams' picks of the
+// player fail with 409. A team gets one reservation per pick.
+func (h *APIHandlers) ReservePick(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		PlayerID string `json:"playerId"`
+		TeamID   string `json:"teamId"`
+		// The room code may ride along in the body; the router checks it.
+		Code     string `json:"code"`
+		RoomCode string `json:"roomCode"`
+	}
+	if err := DecodeJSON(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.authorizePick(r, req.TeamID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	reservation, err := h.reservations.Reserve(h.dal, req.PlayerID, req.TeamID)
+	if err != nil {
+		logger.FromContext(r.Context()).Info("Rejected pick reservation", "error", err, "player_id", req.PlayerID, "team_id", req.TeamID)
+		writeError(w, r, err)
+		return
+	}
+
+	h.pubsub.Publish(pubsub.Event{
+		Type: "draft:reserve",
+		Payload: map[string]interface{}{
+			"playerId":  reservation.PlayerID,
+			"teamId":    reservation.TeamID,
+			"expiresAt": reservation.ExpiresAt,
+		},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reservation)
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// Backends return players in the order they were added; the API sorts them.
+const (
+	defaultPlayerSort = "points"
+	defaultSortDir    = "desc"
+)
+
+// playerSortKeys compares two players on one field.
+var playerSortKeys = map[string]func(a, b models.Player) int{
+	"points":       func(a, b models.Player) int { return a.Points - b.Points },
+	"cuddlePoints": func(a, b models.Player) int { return a.CuddlePoints - b.CuddlePoints },
+	"name":         func(a, b models.Player) int { return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)) },
+	"tier":         func(a, b models.Player) int { return strings.Compare(string(a.Tier), string(b.Tier)) },
+	"createdAt":    func(a, b models.Player) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"updatedAt":    func(a, b models.Player) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+}
+
+// sortPlayersFromQuery orders players by ?sort= (points, cuddlePoints, name,
+// tier, createdAt or updatedAt; default points) and ?dir= (asc or desc; default desc). Ties keep
+// the backend's order, so the result is stable.
+func sortPlayersFromQuery(r *http.Request, players []models.Player) error {
+	field := r.URL.Query().Get("sort")
+	if field == "" {
+		field = defaultPlayerSort
+	}
+	compare, ok := playerSortKeys[field]
+	if !ok {
+		return errors.New("sort must be one of points, cuddlePoints, name, tier, createdAt or updatedAt")
+	}
+
+	dir := r.URL.Query().Get("dir")
+	if dir == "" {
+		dir = defaultSortDir
+	}
+	if dir != "asc" && dir != "desc" {
+		return errors.New("dir must be asc or desc")
+	}
+
+	sort.SliceStable(players, func(i, j int) bool {
+		if dir == "desc" {
+			return compare(players[i], players[j]) > 0
+		}
+		return compare(players[i], players[j]) < 0
+	})
+	return nil
+}
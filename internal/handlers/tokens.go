@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/auth"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+)
+
+// TokenHandlers serves the personal access token API
+type TokenHandlers struct {
+	tokens *auth.TokenAuth
+}
+
+// NewTokenHandlers creates a new token API handlers instance
+func NewTokenHandlers(tokens *auth.TokenAuth) *TokenHandlers {
+	return &TokenHandlers{tokens: tokens}
+}
+
+// Tokens lists (GET), creates (POST) and revokes (DELETE ?id=) the caller's tokens.
+// Admins may list every token with ?all=true.
+func (h *TokenHandlers) Tokens(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized: login required", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.listTokens(w, r, user)
+	case http.MethodPost:
+		h.createToken(w, r, user)
+	case http.MethodDelete:
+		h.revokeToken(w, r, user)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *TokenHandlers) listTokens(w http.ResponseWriter, r *http.Request, user *auth.User) {
+	ownerID := user.ID
+	if r.URL.Query().Get("all") == "true" {
+		if !auth.IsAdmin(user) {
+			http.Error(w, "Forbidden: Admin access required", http.StatusForbidden)
+			return
+		}
+		ownerID = ""
+	}
+
+	tokens, err := h.tokens.Store().ListAccessTokens(ownerID)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to list access tokens", "error", err)
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+func (h *TokenHandlers) createToken(w http.ResponseWriter, r *http.Request, user *auth.User) {
+	// Tokens can only be minted from an interactive session, never from another token.
+	if user.Scopes != nil {
+		http.Error(w, "Forbidden: tokens cannot create tokens", http.StatusForbidden)
+		return
+	}
+
+	var req struct {
+		Name   string   `json:"name"`
+		Scopes []string `json:"scopes"`
+	}
+
+	if err := DecodeJSON(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	token, secret, err := h.tokens.Create(user, req.Name, req.Scopes)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logger.FromContext(r.Context()).Info("Created access token", "token_id", token.ID, "owner", user.Username, "scopes", token.Scopes)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"token":  token,
+		"secret": secret,
+	})
+}
+
+func (h *TokenHandlers) revokeToken(w http.ResponseWriter, r *http.Request, user *auth.User) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "id is required", http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := h.tokens.Store().ListAccessTokens("")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	found := false
+	for _, token := range tokens {
+		if token.ID == id {
+			found = token.OwnerID == user.ID || auth.IsAdmin(user)
+			break
+		}
+	}
+	if !found {
+		writeError(w, r, dal.ErrTokenNotFound)
+		return
+	}
+
+	if err := h.tokens.Store().DeleteAccessToken(id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	logger.FromContext(r.Context()).Info("Revoked access token", "token_id", id, "by", user.Username)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+}
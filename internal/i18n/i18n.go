@@ -0,0 +1,89 @@
+// Package i18n renders system chat messages, stored as a message key and
+// params, in the language named by LANG.
+package i18n
+
+import (
+	"os"
+	"strings"
+)
+
+// Keys of the system chat messages.
+const (
+	KeyWelcome           = "chat.welcome"
+	KeyTipRoomCode       = "chat.tip_room_code"
+	KeyFirstPickTease    = "chat.first_pick_tease"
+	KeyPick              = "chat.pick"
+	KeyTeamJoined        = "chat.team_joined"
+	KeyTeamJoinedNoOwner = "chat.team_joined_unassigned"
+	KeyOrderRandomized   = "chat.order_randomized"
+	KeyDraftModeSet      = "chat.draft_mode_set"
+	KeyPickUndone        = "chat.pick_undone"
+	KeyPicksSwapped      = "chat.picks_swapped"
+)
+
+// DefaultLang is the language used when LANG names none in the catalog.
+const DefaultLang = "en"
+
+// catalog maps a language to its message texts. A text names its params in
+// braces, e.g. "{team}".
+var catalog = map[string]map[string]string{
+	"en": {
+		KeyWelcome:           "Welcome to the Jellycat Draft!",
+		KeyTipRoomCode:       "Tip: pair your phone with the TV room code before picking.",
+		KeyFirstPickTease:    "Who will snag Bashful Bunny first?",
+		KeyPick:              "{mascot} {team} drafted {player} ({playerTeam} • {position})",
+		KeyTeamJoined:        "New team joined the draft: {mascot} {team} (Owner: {owner})",
+		KeyTeamJoinedNoOwner: "New team joined the draft: {mascot} {team} (Owner: Unassigned)",
+		KeyOrderRandomized:   "🎲 The draft order has been randomized: {order}",
+		KeyDraftModeSet:      "Draft mode set to {mode}",
+		KeyPickUndone:        "↩️ Pick undone: {player} returned to the pool from {team}",
+		KeyPicksSwapped:      "🔀 Picks {pickA} and {pickB} swapped: {playerA} now goes to {teamB} at pick {pickB}, {playerB} to {teamA} at pick {pickA}",
+	},
+	"fr": {
+		KeyWelcome:           "Bienvenue à la draft Jellycat !",
+		KeyTipRoomCode:       "Astuce : associez votre téléphone au code de la salle TV avant de choisir.",
+		KeyFirstPickTease:    "Qui attrapera Bashful Bunny en premier ?",
+		KeyPick:              "{mascot} {team} a choisi {player} ({playerTeam} • {position})",
+		KeyTeamJoined:        "Nouvelle équipe dans la draft : {mascot} {team} (Propriétaire : {owner})",
+		KeyTeamJoinedNoOwner: "Nouvelle équipe dans la draft : {mascot} {team} (sans propriétaire)",
+		KeyOrderRandomized:   "🎲 L'ordre de la draft a été tiré au sort : {order}",
+		KeyDraftModeSet:      "Mode de draft réglé sur {mode}",
+		KeyPickUndone:        "↩️ Choix annulé : {player} retourne dans le vivier, retiré de {team}",
+		KeyPicksSwapped:      "🔀 Choix {pickA} et {pickB} échangés : {playerA} passe à {teamB} au choix {pickB}, {playerB} à {teamA} au choix {pickA}",
+	},
+}
+
+// Lang returns the catalog language LANG names, e.g. "fr" for
+// "fr_FR.UTF-8", or DefaultLang when it is unset or has no catalog.
+func Lang() string {
+	lang := strings.ToLower(strings.TrimSpace(os.Getenv("LANG")))
+	if i := strings.IndexAny(lang, "_.@-"); i >= 0 {
+		lang = lang[:i]
+	}
+	if _, ok := catalog[lang]; ok {
+		return lang
+	}
+	return DefaultLang
+}
+
+// Render returns the text for key in the LANG language.
+func Render(key string, params map[string]string) string {
+	return RenderIn(Lang(), key, params)
+}
+
+// RenderIn returns the text for key in lang with params filled in, falling
+// back to DefaultLang for a key lang lacks and to the key itself for one
+// no catalog has.
+func RenderIn(lang, key string, params map[string]string) string {
+	text, ok := catalog[lang][key]
+	if !ok {
+		if text, ok = catalog[DefaultLang][key]; !ok {
+			return key
+		}
+	}
+	pairs := make([]string, 0, len(params)*2)
+	for name, value := range params {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
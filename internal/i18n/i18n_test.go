@@ -0,0 +1,71 @@
+package i18n
+
+import "testing"
+
+func TestLangReadsTheLanguageFromLANG(t *testing.T) {
+	for value, want := range map[string]string{
+		"":            "en",
+		"C":           "en",
+		"fr_FR.UTF-8": "fr",
+		"FR":          "fr",
+		"de_DE.UTF-8": "en",
+	} {
+		t.Setenv("LANG", value)
+		if got := Lang(); got != want {
+			t.Errorf("Lang() with LANG=%q = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestRenderFillsParamsInTheChosenLanguage(t *testing.T) {
+	params := map[string]string{"mascot": "🦊", "team": "Foxes", "player": "Bashful Bunny", "playerTeam": "Jellycat", "position": "CC"}
+
+	if got, want := RenderIn("en", KeyPick, params), "🦊 Foxes drafted Bashful Bunny (Jellycat • CC)"; got != want {
+		t.Errorf("RenderIn(en) = %q, want %q", got, want)
+	}
+	t.Setenv("LANG", "fr_FR.UTF-8")
+	if got, want := Render(KeyPick, params), "🦊 Foxes a choisi Bashful Bunny (Jellycat • CC)"; got != want {
+		t.Errorf("Render() with LANG=fr = %q, want %q", got, want)
+	}
+	// Params are filled in once, so one containing braces is left alone
+	if got, want := RenderIn("en", KeyPick, map[string]string{"team": "{player}"}), "{mascot} {player} drafted {player} ({playerTeam} • {position})"; got != want {
+		t.Errorf("RenderIn() with a braced param = %q, want %q", got, want)
+	}
+}
+
+func TestEveryKeyHasAFrenchText(t *testing.T) {
+	for key := range catalog[DefaultLang] {
+		if _, ok := catalog["fr"][key]; !ok {
+			t.Errorf("fr catalog lacks %s", key)
+		}
+	}
+}
+
+func TestRenderFillsChangeMessageParams(t *testing.T) {
+	swap := map[string]string{"pickA": "1", "pickB": "2", "playerA": "Bashful Bunny", "playerB": "Amuseable Avocado", "teamA": "Foxes", "teamB": "Owls"}
+	for _, tc := range []struct {
+		lang, key string
+		params    map[string]string
+		want      string
+	}{
+		{"en", KeyDraftModeSet, map[string]string{"mode": "Reverse Snake"}, "Draft mode set to Reverse Snake"},
+		{"fr", KeyDraftModeSet, map[string]string{"mode": "Reverse Snake"}, "Mode de draft réglé sur Reverse Snake"},
+		{"en", KeyPickUndone, map[string]string{"player": "Bashful Bunny", "team": "Foxes"}, "↩️ Pick undone: Bashful Bunny returned to the pool from Foxes"},
+		{"fr", KeyPickUndone, map[string]string{"player": "Bashful Bunny", "team": "Foxes"}, "↩️ Choix annulé : Bashful Bunny retourne dans le vivier, retiré de Foxes"},
+		{"en", KeyPicksSwapped, swap, "🔀 Picks 1 and 2 swapped: Bashful Bunny now goes to Owls at pick 2, Amuseable Avocado to Foxes at pick 1"},
+		{"fr", KeyPicksSwapped, swap, "🔀 Choix 1 et 2 échangés : Bashful Bunny passe à Owls au choix 2, Amuseable Avocado à Foxes au choix 1"},
+	} {
+		if got := RenderIn(tc.lang, tc.key, tc.params); got != tc.want {
+			t.Errorf("RenderIn(%s, %s) = %q, want %q", tc.lang, tc.key, got, tc.want)
+		}
+	}
+}
+
+func TestRenderFallsBackForUnknownKeys(t *testing.T) {
+	if got := RenderIn("fr", "chat.unknown", nil); got != "chat.unknown" {
+		t.Errorf("RenderIn(unknown key) = %q, want the key", got)
+	}
+	if got := RenderIn("xx", KeyWelcome, nil); got != "Welcome to the Jellycat Draft!" {
+		t.Errorf("RenderIn(unknown lang) = %q, want the English text", got)
+	}
+}
@@ -0,0 +1,34 @@
+// Package ids generates identifiers shared by the data layer and auth.
+package ids
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// idBytes is the amount of randomness in record IDs. 128 bits keeps the
+// birthday bound far beyond anything a draft will ever generate.
+const idBytes = 16
+
+// tokenBytes is the amount of randomness in session IDs and OAuth state.
+const tokenBytes = 32
+
+// New returns a random record ID of the form "<prefix>_<32 hex chars>".
+func New(prefix string) string {
+	return prefix + "_" + hex.EncodeToString(randomBytes(idBytes))
+}
+
+// Token returns a URL-safe random token suitable for session IDs and CSRF state.
+func Token() string {
+	return base64.URLEncoding.EncodeToString(randomBytes(tokenBytes))
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand only fails if the OS entropy source is broken.
+		panic("ids: failed to read random bytes: " + err.Error())
+	}
+	return b
+}
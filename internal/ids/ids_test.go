@@ -0,0 +1,74 @@
+package ids
+
+import (
+	"encoding/base64"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestNewFormat(t *testing.T) {
+	pattern := regexp.MustCompile(`^player_[0-9a-f]{32}$`)
+
+	id := New("player")
+	if !pattern.MatchString(id) {
+		t.Fatalf("New() = %q, want match for %s", id, pattern)
+	}
+}
+
+func TestTokenFormat(t *testing.T) {
+	token := Token()
+
+	decoded, err := base64.URLEncoding.DecodeString(token)
+	if err != nil {
+		t.Fatalf("Token() = %q is not URL-safe base64: %v", token, err)
+	}
+	if len(decoded) != tokenBytes {
+		t.Fatalf("decoded token length = %d, want %d", len(decoded), tokenBytes)
+	}
+}
+
+func TestNewHasNoCollisions(t *testing.T) {
+	const count = 100000
+
+	seen := make(map[string]struct{}, count)
+	for i := 0; i < count; i++ {
+		id := New("msg")
+		if _, ok := seen[id]; ok {
+			t.Fatalf("duplicate ID after %d generations: %s", i, id)
+		}
+		seen[id] = struct{}{}
+	}
+}
+
+func TestNewSortableFormat(t *testing.T) {
+	pattern := regexp.MustCompile(`^msg_[0-9A-HJKMNP-TV-Z]{26}$`)
+
+	id := NewSortable("msg")
+	if !pattern.MatchString(id) {
+		t.Fatalf("NewSortable() = %q, want match for %s", id, pattern)
+	}
+}
+
+func TestNewSortableIsMonotonicWithinMillisecond(t *testing.T) {
+	var generator ulidGenerator
+	now := time.UnixMilli(1700000000000)
+
+	previous := generator.next(now)
+	for i := 0; i < 1000; i++ {
+		next := generator.next(now)
+		if next <= previous {
+			t.Fatalf("ULID %q did not sort after %q", next, previous)
+		}
+		previous = next
+	}
+}
+
+func TestNewSortableOrdersByTime(t *testing.T) {
+	var generator ulidGenerator
+	first := generator.next(time.UnixMilli(1700000000000))
+	second := generator.next(time.UnixMilli(1700000000001))
+	if second <= first {
+		t.Fatalf("later ULID %q did not sort after %q", second, first)
+	}
+}
@@ -0,0 +1,71 @@
+package ids
+
+import (
+	"sync"
+	"time"
+)
+
+// crockford is the ULID alphabet; its characters sort in the same order as their values.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidGenerator keeps the last timestamp and entropy so IDs stay monotonic.
+type ulidGenerator struct {
+	mu      sync.Mutex
+	lastMS  uint64
+	entropy [10]byte
+}
+
+var defaultULIDs ulidGenerator
+
+// NewSortable returns "<prefix>_<ULID>". IDs sort lexically by creation time,
+// and IDs generated within the same millisecond are strictly increasing.
+func NewSortable(prefix string) string {
+	return prefix + "_" + defaultULIDs.next(time.Now())
+}
+
+func (g *ulidGenerator) next(now time.Time) string {
+	ms := uint64(now.UnixMilli())
+
+	g.mu.Lock()
+	if ms <= g.lastMS {
+		// Same (or earlier) millisecond: bump the previous entropy so ordering stays monotonic.
+		ms = g.lastMS
+		for i := len(g.entropy) - 1; i >= 0; i-- {
+			g.entropy[i]++
+			if g.entropy[i] != 0 {
+				break
+			}
+		}
+	} else {
+		copy(g.entropy[:], randomBytes(len(g.entropy)))
+	}
+	g.lastMS = ms
+
+	var raw [16]byte
+	for i := 0; i < 6; i++ {
+		raw[i] = byte(ms >> (40 - 8*i))
+	}
+	copy(raw[6:], g.entropy[:])
+	g.mu.Unlock()
+
+	return encodeCrockford(raw)
+}
+
+// encodeCrockford encodes 128 bits as 26 base32 characters, most significant first.
+func encodeCrockford(raw [16]byte) string {
+	var out [26]byte
+	// 26 characters carry 130 bits; the leading two bits are always zero.
+	var bitBuf uint32
+	bits := 2
+	pos := 0
+	for _, b := range raw {
+		bitBuf = bitBuf<<8 | uint32(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out[pos] = crockford[(bitBuf>>uint(bits))&0x1f]
+			pos++
+		}
+	}
+	return string(out[:])
+}
@@ -1,18 +1,29 @@
 package logger
 
 import (
+	"context"
+	"io"
 	"log/slog"
 	"os"
+	"runtime"
 	"strings"
+	"sync"
+	"time"
 )
 
 var (
 	// Logger is the global slog logger instance
 	Logger *slog.Logger
+
+	// output is the current LOG_OUTPUT file, nil when logging to a stream.
+	output *reopenableFile
 )
 
-// Init initializes the global logger with the configured level from LOG_LEVEL environment variable
-// Default level is INFO for production, DEBUG for development
+// Init initializes the global logger from the environment:
+//   - LOG_LEVEL: debug, info (default), warn or error
+//   - LOG_FORMAT: json (default) or text for human-readable local logs
+//   - LOG_OUTPUT: stdout (default), stderr or a file path to append to; see
+//     Reopen for rotating the file
 func Init() {
 	// Get log level from environment variable
 	logLevelStr := os.Getenv("LOG_LEVEL")
@@ -36,37 +47,179 @@ func Init() {
 		level = slog.LevelInfo
 	}
 
-	// Create handler options with the configured level
-	opts := &slog.HandlerOptions{
-		Level: level,
-	}
-
-	// Create JSON handler for structured logging
-	handler := slog.NewJSONHandler(os.Stdout, opts)
+	w, openErr := openOutput(os.Getenv("LOG_OUTPUT"))
+	format := strings.ToLower(strings.TrimSpace(os.Getenv("LOG_FORMAT")))
 
 	// Set the global logger
-	Logger = slog.New(handler)
+	Logger = slog.New(newHandler(w, format, level))
 	slog.SetDefault(Logger)
 
-	Logger.Info("Logger initialized", "level", logLevelStr)
+	if openErr != nil {
+		Logger.Warn("Failed to open LOG_OUTPUT, logging to stdout", "path", os.Getenv("LOG_OUTPUT"), "error", openErr)
+	}
+	Logger.Info("Logger initialized", "level", logLevelStr, "format", formatName(format))
+}
+
+// newHandler builds a JSON handler, or a text one for LOG_FORMAT=text, that
+// records where Error logs came from.
+func newHandler(w io.Writer, format string, level slog.Level) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level}
+	if format == "text" {
+		return errorSourceHandler{slog.NewTextHandler(w, opts)}
+	}
+	return errorSourceHandler{slog.NewJSONHandler(w, opts)}
+}
+
+func formatName(format string) string {
+	if format == "text" {
+		return "text"
+	}
+	return "json"
+}
+
+// openOutput returns the writer for LOG_OUTPUT, falling back to stdout when
+// the file can't be opened.
+func openOutput(path string) (io.Writer, error) {
+	if output != nil {
+		output.Close()
+		output = nil
+	}
+	switch strings.ToLower(strings.TrimSpace(path)) {
+	case "", "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	}
+	file, err := openFile(path)
+	if err != nil {
+		return os.Stdout, err
+	}
+	output = file
+	return file, nil
+}
+
+// Reopen closes and reopens the LOG_OUTPUT file, so logs move to a new file
+// after logrotate (or similar) renames the old one. It is a no-op when
+// logging to stdout or stderr.
+func Reopen() error {
+	if output == nil {
+		return nil
+	}
+	return output.Reopen()
+}
+
+// reopenableFile is an append-only log file that can be swapped for a fresh
+// one at the same path while other goroutines write.
+type reopenableFile struct {
+	path string
+	mu   sync.Mutex
+	file *os.File
+}
+
+func openFile(path string) (*reopenableFile, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &reopenableFile{path: path, file: file}, nil
+}
+
+func (f *reopenableFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Write(p)
+}
+
+func (f *reopenableFile) Reopen() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.file.Close()
+	f.file = file
+	return nil
+}
+
+func (f *reopenableFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// errorSourceHandler adds the caller's file and line to Error records only,
+// keeping the other levels short.
+type errorSourceHandler struct {
+	slog.Handler
+}
+
+func (h errorSourceHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelError && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		r.AddAttrs(slog.Any(slog.SourceKey, &slog.Source{Function: frame.Function, File: frame.File, Line: frame.Line}))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h errorSourceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return errorSourceHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h errorSourceHandler) WithGroup(name string) slog.Handler {
+	return errorSourceHandler{h.Handler.WithGroup(name)}
+}
+
+type contextKey struct{}
+
+// WithContext returns ctx carrying l, for FromContext.
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger WithContext stored in ctx, such as one
+// carrying a request's request_id and user, or the global Logger (slog's
+// default before Init).
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return l
+	}
+	if Logger == nil {
+		return slog.Default()
+	}
+	return Logger
+}
+
+// log writes a record attributed to the caller of Debug, Info, Warn or
+// Error rather than to this package.
+func log(level slog.Level, msg string, args ...any) {
+	ctx := context.Background()
+	if !Logger.Enabled(ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // skip Callers, log and the exported wrapper
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.Add(args...)
+	_ = Logger.Handler().Handle(ctx, r)
 }
 
 // Debug logs a debug message
 func Debug(msg string, args ...any) {
-	Logger.Debug(msg, args...)
+	log(slog.LevelDebug, msg, args...)
 }
 
 // Info logs an info message
 func Info(msg string, args ...any) {
-	Logger.Info(msg, args...)
+	log(slog.LevelInfo, msg, args...)
 }
 
 // Warn logs a warning message
 func Warn(msg string, args ...any) {
-	Logger.Warn(msg, args...)
+	log(slog.LevelWarn, msg, args...)
 }
 
 // Error logs an error message
 func Error(msg string, args ...any) {
-	Logger.Error(msg, args...)
+	log(slog.LevelError, msg, args...)
 }
@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useLogger points the package at a JSON logger writing to buf for the test.
+func useLogger(t *testing.T, buf *bytes.Buffer) {
+	t.Helper()
+	previous := Logger
+	Logger = slog.New(newHandler(buf, "json", slog.LevelDebug))
+	t.Cleanup(func() { Logger = previous })
+}
+
+func TestErrorLogsCarrySource(t *testing.T) {
+	var buf bytes.Buffer
+	useLogger(t, &buf)
+
+	Info("fine")
+	Error("broken")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d log lines, want 2: %s", len(lines), buf.String())
+	}
+	var info, failure struct {
+		Source *slog.Source `json:"source"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &info); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &failure); err != nil {
+		t.Fatal(err)
+	}
+	if info.Source != nil {
+		t.Errorf("info line has source %+v, want none", info.Source)
+	}
+	if failure.Source == nil || filepath.Base(failure.Source.File) != "logger_test.go" {
+		t.Errorf("error source = %+v, want this test file rather than logger.go", failure.Source)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	useLogger(t, &buf)
+
+	if FromContext(context.Background()) != Logger {
+		t.Fatal("FromContext without a stored logger should return the global Logger")
+	}
+	ctx := WithContext(context.Background(), Logger.With("request_id", "req-1"))
+	FromContext(ctx).Info("handled")
+	if !strings.Contains(buf.String(), `"request_id":"req-1"`) {
+		t.Fatalf("log line %q is missing the request_id", buf.String())
+	}
+}
+
+func TestInitTextFormatToFileAndReopen(t *testing.T) {
+	previous, previousDefault := Logger, slog.Default()
+	t.Cleanup(func() {
+		openOutput("")
+		Logger = previous
+		slog.SetDefault(previousDefault)
+	})
+	path := filepath.Join(t.TempDir(), "app.log")
+	t.Setenv("LOG_FORMAT", "text")
+	t.Setenv("LOG_OUTPUT", path)
+	Init()
+	Info("before rotation")
+
+	rotated := path + ".1"
+	if err := os.Rename(path, rotated); err != nil {
+		t.Fatal(err)
+	}
+	if err := Reopen(); err != nil {
+		t.Fatalf("Reopen: %v", err)
+	}
+	Info("after rotation")
+
+	old, err := os.ReadFile(rotated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(old), `msg="before rotation"`) || strings.Contains(string(old), "after rotation") {
+		t.Errorf("rotated file = %q, want only the text-format line from before rotation", old)
+	}
+	current, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(current), `msg="after rotation"`) {
+		t.Errorf("reopened file = %q, want the line from after rotation", current)
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	inFlight atomic.Int64
+	// inFlightGauge reads inFlight when scraped, so it can't fall out of
+	// step with the counter however requests interleave
+	inFlightGauge = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "jellycat_http_requests_in_flight",
+		Help: "HTTP requests being served, including open SSE streams.",
+	}, func() float64 { return float64(inFlight.Load()) })
+)
+
+func init() {
+	prometheus.MustRegister(inFlightGauge)
+}
+
+// CountInFlight counts the requests next is serving, for InFlight and the
+// jellycat_http_requests_in_flight gauge.
+func CountInFlight(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlight.Add(1)
+		defer inFlight.Add(-1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// InFlight returns how many requests CountInFlight handlers are serving.
+func InFlight() int64 {
+	return inFlight.Load()
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCountInFlightTracksRequestsBeingServed(t *testing.T) {
+	before := InFlight()
+	var during int64
+	var gauge float64
+	handler := CountInFlight(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		during = InFlight()
+		gauge = testutil.ToFloat64(inFlightGauge)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/state", nil))
+
+	if during != before+1 || gauge != float64(before+1) {
+		t.Fatalf("during the request InFlight() = %d and the gauge = %v, want %d", during, gauge, before+1)
+	}
+	if after := InFlight(); after != before {
+		t.Fatalf("InFlight() after the request = %d, want %d", after, before)
+	}
+}
+
+func TestInFlightGaugeSettlesAfterConcurrentRequests(t *testing.T) {
+	before := InFlight()
+	handler := CountInFlight(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/state", nil))
+		}()
+	}
+	wg.Wait()
+
+	if gauge := testutil.ToFloat64(inFlightGauge); gauge != float64(before) {
+		t.Fatalf("gauge after the requests = %v, want %d", gauge, before)
+	}
+}
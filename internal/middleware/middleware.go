@@ -0,0 +1,45 @@
+// Package middleware composes HTTP middlewares written in the
+// func(http.Handler) http.Handler convention.
+package middleware
+
+import "net/http"
+
+// Middleware wraps a handler with behavior that runs around it.
+type Middleware func(http.Handler) http.Handler
+
+// Chain is an ordered list of middlewares; the first one listed sees the
+// request first.
+type Chain []Middleware
+
+// New returns a chain of middlewares.
+func New(middlewares ...Middleware) Chain {
+	return append(Chain(nil), middlewares...)
+}
+
+// Append returns a new chain with middlewares added after c's. c is unchanged,
+// so route groups can extend a shared base chain.
+func (c Chain) Append(middlewares ...Middleware) Chain {
+	chain := make(Chain, 0, len(c)+len(middlewares))
+	return append(append(chain, c...), middlewares...)
+}
+
+// Then wraps h in every middleware of the chain.
+func (c Chain) Then(h http.Handler) http.Handler {
+	for i := len(c) - 1; i >= 0; i-- {
+		h = c[i](h)
+	}
+	return h
+}
+
+// ThenFunc is Then for a handler function.
+func (c Chain) ThenFunc(fn http.HandlerFunc) http.Handler {
+	return c.Then(fn)
+}
+
+// Func adapts a middleware over http.HandlerFunc, such as
+// auth.AuthProvider.Middleware, to the Middleware convention.
+func Func(wrap func(http.HandlerFunc) http.HandlerFunc) Middleware {
+	return func(next http.Handler) http.Handler {
+		return wrap(next.ServeHTTP)
+	}
+}
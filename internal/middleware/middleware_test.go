@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// tag records its name on the way in, so the response body shows the order.
+func tag(name string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(name + ">"))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func serve(h http.Handler) string {
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	return recorder.Body.String()
+}
+
+func TestChainRunsInOrder(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("handler")) }
+
+	base := New(tag("a"), tag("b"))
+	extended := base.Append(Func(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("c>"))
+			next(w, r)
+		}
+	}))
+	// A second Append must not overwrite the first's middlewares.
+	other := base.Append(tag("d"))
+
+	if got := serve(extended.ThenFunc(handler)); got != "a>b>c>handler" {
+		t.Fatalf("extended chain = %q, want a>b>c>handler", got)
+	}
+	if got := serve(other.ThenFunc(handler)); got != "a>b>d>handler" {
+		t.Fatalf("other chain = %q, want a>b>d>handler", got)
+	}
+	if got := serve(base.ThenFunc(handler)); !strings.HasPrefix(got, "a>b>handler") {
+		t.Fatalf("base chain = %q, want it unchanged by Append", got)
+	}
+	if got := serve(New().ThenFunc(handler)); got != "handler" {
+		t.Fatalf("empty chain = %q, want the bare handler", got)
+	}
+}
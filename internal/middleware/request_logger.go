@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/ids"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+)
+
+// RequestIDHeader carries a request's ID in both directions.
+const RequestIDHeader = "X-Request-Id"
+
+// RequestLogger gives each request a logger carrying request_id, for
+// logger.FromContext. A well-formed incoming X-Request-Id is kept so IDs
+// match across proxies; otherwise one is generated. The ID is echoed in the
+// response.
+func RequestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = ids.New("req")
+		}
+		w.Header().Set(RequestIDHeader, id)
+		ctx := logger.WithContext(r.Context(), logger.FromContext(r.Context()).With("request_id", id))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// validRequestID accepts up to 64 letters, digits, '.', '_' and '-', so a
+// client can't inject anything odd into the logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '.', c == '_', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+)
+
+func TestRequestLoggerTagsLogsWithRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	previous := logger.Logger
+	logger.Logger = slog.New(slog.NewJSONHandler(&buf, nil))
+	t.Cleanup(func() { logger.Logger = previous })
+
+	handler := RequestLogger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.FromContext(r.Context()).Info("handled")
+	}))
+
+	for _, tc := range []struct {
+		name, header string
+		keep         bool
+	}{
+		{"kept", "edge-1234.abc_DEF", true},
+		{"missing", "", false},
+		{"injected", "bad id\n{\"user\":\"admin\"}", false},
+	} {
+		buf.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/api/state", nil)
+		if tc.header != "" {
+			req.Header.Set(RequestIDHeader, tc.header)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		id := recorder.Header().Get(RequestIDHeader)
+		if tc.keep && id != tc.header {
+			t.Errorf("%s: response ID = %q, want %q", tc.name, id, tc.header)
+		}
+		if !tc.keep && (id == tc.header || !validRequestID(id)) {
+			t.Errorf("%s: response ID = %q, want a fresh generated one", tc.name, id)
+		}
+		var line struct {
+			RequestID string `json:"request_id"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+			t.Fatalf("%s: decode log line %q: %v", tc.name, buf.String(), err)
+		}
+		if line.RequestID != id {
+			t.Errorf("%s: logged request_id = %q, want %q", tc.name, line.RequestID, id)
+		}
+	}
+}
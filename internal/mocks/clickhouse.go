@@ -1,21 +1,42 @@
 package mocks
 
 import (
+	"context"
+	"hash/fnv"
 	"math/rand"
+	"sync"
+	"time"
 
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/clickhouse"
 	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
 )
 
-// MockClickHouseClient provides a mock ClickHouse client for local development
+var (
+	_ clickhouse.CuddlePointsClient  = (*MockClickHouseClient)(nil)
+	_ clickhouse.InteractionSink     = (*MockClickHouseClient)(nil)
+	_ clickhouse.CuddleHistorySource = (*MockClickHouseClient)(nil)
+	_ clickhouse.FormulaSource       = (*MockClickHouseClient)(nil)
+	_ clickhouse.EngagementSource    = (*MockClickHouseClient)(nil)
+)
+
+// MockClickHouseClient provides a mock ClickHouse client for local development.
+// Interactions written to it raise cuddle points the way the real query would,
+// and its made-up activity is scored with the same formula.
 type MockClickHouseClient struct {
 	basePoints map[string]int
+	formula    clickhouse.Formula
+
+	mu           sync.Mutex
+	interactions []clickhouse.Interaction
 }
 
-// NewMockClickHouseClient creates a mock ClickHouse client
+// NewMockClickHouseClient creates a mock ClickHouse client scoring with
+// clickhouse.FormulaFromEnv.
 func NewMockClickHouseClient() *MockClickHouseClient {
 	logger.Info("Using MOCK ClickHouse client for local development")
 
 	return &MockClickHouseClient{
+		formula: clickhouse.FormulaFromEnv(),
 		basePoints: map[string]int{
 			"1":  324, // Bashful Bunny
 			"2":  298, // Fuddlewuddle Lion
@@ -40,42 +61,241 @@ func NewMockClickHouseClient() *MockClickHouseClient {
 }
 
 // GetCuddlePoints returns mock cuddle points with slight variation
-func (m *MockClickHouseClient) GetCuddlePoints(jellycatID string) (int, error) {
-	base, ok := m.basePoints[jellycatID]
-	if !ok {
-		base = 200 // Default for unknown jellycats
+func (m *MockClickHouseClient) GetCuddlePoints(ctx context.Context, jellycatID string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
 	}
 
-	// Add some randomness for realism (±10%)
-	variance := rand.Intn(int(float64(base)*0.2)) - int(float64(base)*0.1)
-	return base + variance, nil
+	return jitter(m.syntheticPoints(jellycatID)) + m.interactionPoints()[jellycatID], nil
 }
 
-// GetAllCuddlePoints returns all mock cuddle points
-func (m *MockClickHouseClient) GetAllCuddlePoints() (map[string]int, error) {
-	result := make(map[string]int)
-	for id, base := range m.basePoints {
-		variance := rand.Intn(int(float64(base)*0.2)) - int(float64(base)*0.1)
-		result[id] = base + variance
+// GetAllCuddlePoints returns all mock cuddle points unless ctx is already done
+func (m *MockClickHouseClient) GetAllCuddlePoints(ctx context.Context) (map[string]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := m.interactionPoints()
+	for id := range m.basePoints {
+		result[id] += jitter(m.syntheticPoints(id))
 	}
 	return result, nil
 }
 
 // SyncCuddlePoints updates player cuddle points (mock implementation)
-func (m *MockClickHouseClient) SyncCuddlePoints(updateFunc func(playerID string, points int) error) error {
-	allPoints, err := m.GetAllCuddlePoints()
+func (m *MockClickHouseClient) SyncCuddlePoints(ctx context.Context, updateFunc func(playerID string, points int) error) (clickhouse.SyncResult, error) {
+	allPoints, err := m.GetAllCuddlePoints(ctx)
 	if err != nil {
+		return clickhouse.SyncResult{}, err
+	}
+
+	result := clickhouse.ApplyCuddlePoints(allPoints, updateFunc)
+	logger.Info("Mock ClickHouse: Synced cuddle points", "updated", result.Updated, "skipped", result.Skipped, "errors", len(result.Errors))
+	return result, nil
+}
+
+// InsertInteractions keeps interactions in memory
+func (m *MockClickHouseClient) InsertInteractions(ctx context.Context, interactions []clickhouse.Interaction) error {
+	if err := ctx.Err(); err != nil {
 		return err
 	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.interactions = append(m.interactions, interactions...)
+	return nil
+}
+
+// Interactions returns the interactions written so far
+func (m *MockClickHouseClient) Interactions() []clickhouse.Interaction {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]clickhouse.Interaction(nil), m.interactions...)
+}
 
-	for playerID, points := range allPoints {
-		if err := updateFunc(playerID, points); err != nil {
-			logger.Error("Mock ClickHouse: Failed to update points", "player_id", playerID, "error", err)
+// syntheticPoints scores made-up activity for jellycatID. Under the default
+// formula a Jellycat scores its base points: half from unique users, 30% from
+// interactions and 20% from minutes played.
+func (m *MockClickHouseClient) syntheticPoints(jellycatID string) int {
+	base, ok := m.basePoints[jellycatID]
+	if !ok {
+		base = 200 // Default for unknown jellycats
+	}
+	return m.formula.Score(base/20, base*3, time.Duration(base/5)*time.Minute)
+}
+
+// jitter adds up to ±10% to points for realism.
+func jitter(points int) int {
+	spread := points / 5
+	if spread == 0 {
+		return points
+	}
+	return points + rand.Intn(spread) - spread/2
+}
+
+// interactionPoints scores the interactions in the formula's window per
+// Jellycat, as the ClickHouse query would.
+func (m *MockClickHouseClient) interactionPoints() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	type totals struct {
+		users    map[string]bool
+		count    int
+		duration time.Duration
+	}
+	since := time.Now().AddDate(0, 0, -m.formula.WindowDays)
+	byJellycat := make(map[string]*totals)
+	for _, i := range m.interactions {
+		if i.Timestamp.Before(since) {
+			continue
 		}
+		t, ok := byJellycat[i.JellycatID]
+		if !ok {
+			t = &totals{users: make(map[string]bool)}
+			byJellycat[i.JellycatID] = t
+		}
+		t.users[i.UserID] = true
+		t.count++
+		t.duration += i.Duration
 	}
 
-	logger.Info("Mock ClickHouse: Synced cuddle points for all Jellycats")
-	return nil
+	points := make(map[string]int, len(byJellycat))
+	for id, t := range byJellycat {
+		points[id] = m.formula.Score(len(t.users), t.count, t.duration)
+	}
+	return points
+}
+
+// GetCuddlePointsHistory returns a stable made-up series: a daily share of the
+// Jellycat's base points that drifts from day to day, plus whatever
+// interactions were written for that day.
+func (m *MockClickHouseClient) GetCuddlePointsHistory(ctx context.Context, jellycatID string, days int) ([]clickhouse.DailyCuddlePoints, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	base := m.syntheticPoints(jellycatID)
+	daily := m.dailyInteractionPoints(jellycatID)
+
+	return clickhouse.HistorySeries(time.Now(), days, func(day time.Time) int {
+		hasher := fnv.New32a()
+		hasher.Write([]byte(jellycatID + day.Format(time.DateOnly)))
+		// Between 70% and 130% of an even 30-day share of base.
+		share := base / 30
+		drift := int(hasher.Sum32()%61) - 30
+		return share + share*drift/100 + daily[day.Format(time.DateOnly)]
+	}), nil
+}
+
+// dailyInteractionPoints scores jellycatID's interactions per UTC day.
+func (m *MockClickHouseClient) dailyInteractionPoints(jellycatID string) map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	type totals struct {
+		users    map[string]bool
+		count    int
+		duration time.Duration
+	}
+	byDay := make(map[string]*totals)
+	for _, i := range m.interactions {
+		if i.JellycatID != jellycatID {
+			continue
+		}
+		day := i.Timestamp.UTC().Format(time.DateOnly)
+		t, ok := byDay[day]
+		if !ok {
+			t = &totals{users: make(map[string]bool)}
+			byDay[day] = t
+		}
+		t.users[i.UserID] = true
+		t.count++
+		t.duration += i.Duration
+	}
+
+	points := make(map[string]int, len(byDay))
+	for day, t := range byDay {
+		points[day] = m.formula.Score(len(t.users), t.count, t.duration)
+	}
+	return points
+}
+
+// GetPlayerMetrics rates the mock's made-up activity the way the real
+// client rates ClickHouse's, using the history series as daily activity.
+func (m *MockClickHouseClient) GetPlayerMetrics(ctx context.Context, jellycatID string) (clickhouse.PlayerMetrics, error) {
+	if err := ctx.Err(); err != nil {
+		return clickhouse.PlayerMetrics{}, err
+	}
+
+	totals := make(map[string]clickhouse.ActivityTotals, len(m.basePoints))
+	for id, base := range m.basePoints {
+		totals[id] = clickhouse.ActivityTotals{UniqueUsers: base / 20, Interactions: base * 3, Points: m.syntheticPoints(id)}
+	}
+	for id, points := range m.interactionPoints() {
+		t := totals[id]
+		t.Points += points
+		totals[id] = t
+	}
+
+	history, err := m.GetCuddlePointsHistory(ctx, jellycatID, m.formula.WindowDays)
+	if err != nil {
+		return clickhouse.PlayerMetrics{}, err
+	}
+	daily := make([]int, len(history))
+	for i, day := range history {
+		daily[i] = day.Points
+	}
+	return clickhouse.ComputePlayerMetrics(jellycatID, totals, daily), nil
+}
+
+// Health reports a healthy, instant ping unless ctx is already done
+func (m *MockClickHouseClient) Health(ctx context.Context) (time.Duration, error) {
+	return 0, ctx.Err()
+}
+
+// GetTeamEngagement totals each roster's made-up activity, scaled to days
+// out of the formula's window, plus the interactions written in that time.
+// Made-up users are counted per Jellycat, as if no one cuddled two.
+func (m *MockClickHouseClient) GetTeamEngagement(ctx context.Context, rosters map[string][]string, days int) (map[string]clickhouse.TeamEngagement, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	window := max(m.formula.WindowDays, 1)
+	since := time.Now().AddDate(0, 0, -days)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	engagement := make(map[string]clickhouse.TeamEngagement)
+	for teamID, roster := range rosters {
+		var totals clickhouse.TeamEngagement
+		users := make(map[string]bool)
+		for _, jellycatID := range roster {
+			base, ok := m.basePoints[jellycatID]
+			if !ok {
+				continue
+			}
+			totals.Interactions += base * 3 * days / window
+			totals.UniqueUsers += base / 20 * days / window
+			totals.CuddlePoints += m.syntheticPoints(jellycatID) * days / window
+			for _, i := range m.interactions {
+				if i.JellycatID == jellycatID && !i.Timestamp.Before(since) {
+					totals.Interactions++
+					users[i.UserID] = true
+				}
+			}
+		}
+		totals.UniqueUsers += len(users)
+		if totals.Interactions > 0 {
+			engagement[teamID] = totals
+		}
+	}
+	return engagement, nil
+}
+
+// Formula returns the formula the mock scores cuddle points with
+func (m *MockClickHouseClient) Formula() clickhouse.Formula {
+	return m.formula
 }
 
 // Close is a no-op for mock client
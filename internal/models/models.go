@@ -1,5 +1,10 @@
 package models
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // Tier represents the player tier rating
 type Tier string
 
@@ -143,6 +148,78 @@ func NormalizeDraftMode(mode DraftMode) DraftMode {
 	}
 }
 
+// Draft window states reported by DraftWindow.Status.
+const (
+	DraftWindowScheduled = "scheduled"
+	DraftWindowOpen      = "open"
+	DraftWindowClosed    = "closed"
+)
+
+// DraftWindow is when picks are accepted. A zero bound leaves that side open.
+type DraftWindow struct {
+	OpensAt  time.Time `json:"opensAt,omitzero"`
+	ClosesAt time.Time `json:"closesAt,omitzero"`
+}
+
+// DraftMeta is the title and description a deployment shows for its draft,
+// e.g. its league's name.
+type DraftMeta struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// PickReservation holds a player for a team about to draft it. ExpiresAt is
+// in Unix milliseconds.
+type PickReservation struct {
+	PlayerID  string `json:"playerId"`
+	TeamID    string `json:"teamId"`
+	ExpiresAt int64  `json:"expiresAt"`
+}
+
+// AuctionNomination is the player up for bid in an auction draft. HighBidder
+// is a team ID; the nominating team opens with the first bid.
+type AuctionNomination struct {
+	PlayerID    string `json:"playerId"`
+	NominatedBy string `json:"nominatedBy"`
+	HighBid     int    `json:"highBid"`
+	HighBidder  string `json:"highBidder"`
+	OpenedAt    int64  `json:"openedAt"`
+}
+
+// DraftWindowStatus is a DraftWindow with countdowns relative to ServerTime,
+// so clients can count down without trusting their own clocks.
+type DraftWindowStatus struct {
+	DraftWindow
+	State      string    `json:"state"`
+	ServerTime time.Time `json:"serverTime"`
+	// OpensIn and ClosesIn are whole seconds until the next bound; zero once it has passed.
+	OpensIn  int64 `json:"opensIn"`
+	ClosesIn int64 `json:"closesIn"`
+}
+
+// State reports whether picks are accepted at now.
+func (w DraftWindow) State(now time.Time) string {
+	if !w.OpensAt.IsZero() && now.Before(w.OpensAt) {
+		return DraftWindowScheduled
+	}
+	if !w.ClosesAt.IsZero() && !now.Before(w.ClosesAt) {
+		return DraftWindowClosed
+	}
+	return DraftWindowOpen
+}
+
+// Status returns the window's state and countdowns at now.
+func (w DraftWindow) Status(now time.Time) DraftWindowStatus {
+	status := DraftWindowStatus{DraftWindow: w, State: w.State(now), ServerTime: now.UTC()}
+	if !w.OpensAt.IsZero() && now.Before(w.OpensAt) {
+		status.OpensIn = int64(w.OpensAt.Sub(now).Seconds())
+	}
+	if !w.ClosesAt.IsZero() && now.Before(w.ClosesAt) {
+		status.ClosesIn = int64(w.ClosesAt.Sub(now).Seconds())
+	}
+	return status
+}
+
 // Player represents a Jellycat player
 type Player struct {
 	ID           string          `json:"id"`
@@ -156,44 +233,175 @@ type Player struct {
 	DraftedBy    string          `json:"draftedBy,omitempty"`
 	Image        string          `json:"image"`
 	Analytics    PlayerAnalytics `json:"analytics"`
+	// PickNumber is the player's place in the overall draft order. It is
+	// only set on players returned by GetTeamPlayers.
+	PickNumber int       `json:"pickNumber,omitempty"`
+	CreatedAt  time.Time `json:"createdAt,omitzero"`
+	UpdatedAt  time.Time `json:"updatedAt,omitzero"`
 }
 
 // Team represents a draft team
 type Team struct {
-	ID      string   `json:"id"`
-	Name    string   `json:"name"`
-	Owner   string   `json:"owner"`
-	Mascot  string   `json:"mascot"`
-	Color   string   `json:"color"`
-	Players []Player `json:"players"`
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Owner string `json:"owner"`
+	// OwnerUserID is the authenticated user who claimed the team. Owner
+	// remains the display name.
+	OwnerUserID string   `json:"ownerUserId,omitempty"`
+	Mascot      string   `json:"mascot"`
+	Color       string   `json:"color"`
+	Players     []Player `json:"players"`
+	// RosterSlotsRemaining is set in draft state when MAX_ROSTER_SIZE caps rosters.
+	RosterSlotsRemaining *int `json:"rosterSlotsRemaining,omitempty"`
+	// Budget is what the team has left to bid in an auction draft.
+	Budget    *int      `json:"budget,omitempty"`
+	CreatedAt time.Time `json:"createdAt,omitzero"`
+	UpdatedAt time.Time `json:"updatedAt,omitzero"`
 }
 
 // ChatMessage represents a chat message
 type ChatMessage struct {
-	ID     string         `json:"id"`
-	TS     int64          `json:"ts"`
-	Type   string         `json:"type"` // "system" or "user"
-	Text   string         `json:"text"`
+	ID   string `json:"id"`
+	TS   int64  `json:"ts"`
+	Type string `json:"type"` // "system" or "user"
+	Text string `json:"text"`
+	// Sender names the bot behind system messages (SYSTEM_SENDER); it is
+	// empty for user messages.
+	Sender string         `json:"sender,omitempty"`
 	Emotes map[string]int `json:"emotes"`
+	// Key and Params are set on system messages stored as a catalog key;
+	// Text is then their rendering in the server's LANG.
+	Key    string            `json:"key,omitempty"`
+	Params map[string]string `json:"params,omitempty"`
+	// CreatedAt is TS as a time; UpdatedAt moves when reactions change.
+	CreatedAt time.Time `json:"createdAt,omitzero"`
+	UpdatedAt time.Time `json:"updatedAt,omitzero"`
+}
+
+// ISOTimestampLayout is RFC 3339 in UTC at the millisecond precision TS is
+// stored with.
+const ISOTimestampLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// MarshalJSON adds tsIso, TS formatted with ISOTimestampLayout, so clients
+// needn't convert the millis themselves. It is left out when TS is zero.
+func (m ChatMessage) MarshalJSON() ([]byte, error) {
+	type plain ChatMessage
+	out := struct {
+		plain
+		TSISO string `json:"tsIso,omitempty"`
+	}{plain: plain(m)}
+	if m.TS != 0 {
+		out.TSISO = time.UnixMilli(m.TS).UTC().Format(ISOTimestampLayout)
+	}
+	return json.Marshal(out)
+}
+
+// AccessToken is a personal access token for scripts and gRPC clients.
+// The owner's identity is captured at creation; only a hash of the secret is stored.
+type AccessToken struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	OwnerID       string   `json:"ownerId"`
+	OwnerUsername string   `json:"ownerUsername"`
+	OwnerName     string   `json:"ownerName"`
+	OwnerEmail    string   `json:"ownerEmail,omitempty"`
+	OwnerGroups   []string `json:"ownerGroups,omitempty"`
+	Scopes        []string `json:"scopes"`
+	TokenHash     string   `json:"-"`
+	CreatedAt     int64    `json:"createdAt"`
+	LastUsedAt    int64    `json:"lastUsedAt,omitempty"`
+}
+
+// AuthEvent is an audit record of a sign-in, sign-out or session change.
+type AuthEvent struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	UserID    string `json:"userId,omitempty"`
+	Username  string `json:"username,omitempty"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"userAgent,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+	// ImpersonatorID is the admin acting as UserID, if any.
+	ImpersonatorID string `json:"impersonatorId,omitempty"`
+	CreatedAt      int64  `json:"createdAt"`
+}
+
+// ResetPreview is what resetting the draft would remove, worked out without
+// changing anything.
+type ResetPreview struct {
+	PicksRemoved int `json:"picksRemoved"`
+	// CustomPlayersDeleted counts players outside the default catalog,
+	// which a reset doesn't bring back.
+	CustomPlayersDeleted int `json:"customPlayersDeleted"`
+	// ChatMessagesPurged includes cleared messages still awaiting restore.
+	ChatMessagesPurged int `json:"chatMessagesPurged"`
+	// TeamsAffected names the teams a reset removes, sorted.
+	TeamsAffected []string `json:"teamsAffected"`
+	// TeamsReseeded names the default teams a reset re-creates in
+	// development, sorted; it's empty elsewhere.
+	TeamsReseeded []string `json:"teamsReseeded"`
+}
+
+// DALStats counts what a store holds, for diagnostics. Cleared chat and
+// its reactions aren't counted.
+type DALStats struct {
+	Players   int `json:"players"`
+	Drafted   int `json:"drafted"`
+	Teams     int `json:"teams"`
+	Chat      int `json:"chat"`
+	Reactions int `json:"reactions"`
 }
 
 // DraftState represents the complete state of the draft
 type DraftState struct {
-	Players             []Player             `json:"players"`
-	Teams               []Team               `json:"teams"`
-	Chat                []ChatMessage        `json:"chat"`
-	Settings            DraftSettings        `json:"settings"`
-	CurrentPick         int                  `json:"currentPick"`
-	CurrentRound        int                  `json:"currentRound"`
-	PickInRound         int                  `json:"pickInRound"`
-	CurrentTeamID       string               `json:"currentTeamId"`
-	CurrentTeamName     string               `json:"currentTeamName"`
-	DraftOrder          []DraftOrderEntry    `json:"draftOrder"`
-	BingoBoard          []BingoSquare        `json:"bingoBoard,omitempty"`
-	CurrentBingoPrompt  string               `json:"currentBingoPrompt,omitempty"`
-	WheelSlots          []WheelSlot          `json:"wheelSlots,omitempty"`
-	SuggestedPick       *DraftRecommendation `json:"suggestedPick,omitempty"`
-	AnalyticsConfigured bool                 `json:"analyticsConfigured"`
+	Players            []Player             `json:"players"`
+	Teams              []Team               `json:"teams"`
+	Chat               []ChatMessage        `json:"chat"`
+	Settings           DraftSettings        `json:"settings"`
+	CurrentPick        int                  `json:"currentPick"`
+	CurrentRound       int                  `json:"currentRound"`
+	PickInRound        int                  `json:"pickInRound"`
+	CurrentTeamID      string               `json:"currentTeamId"`
+	CurrentTeamName    string               `json:"currentTeamName"`
+	DraftOrder         []DraftOrderEntry    `json:"draftOrder"`
+	BingoBoard         []BingoSquare        `json:"bingoBoard,omitempty"`
+	CurrentBingoPrompt string               `json:"currentBingoPrompt,omitempty"`
+	WheelSlots         []WheelSlot          `json:"wheelSlots,omitempty"`
+	SuggestedPick      *DraftRecommendation `json:"suggestedPick,omitempty"`
+	MaxRosterSize      int                  `json:"maxRosterSize,omitempty"`
+	// Auction is the open nomination in an auction draft, if any.
+	Auction             *AuctionNomination `json:"auction,omitempty"`
+	AnalyticsConfigured bool               `json:"analyticsConfigured"`
+	// Version is the state version the board is current as of; events carry
+	// the version after their change, so a client seeing one skip ahead of
+	// its own has missed an update.
+	Version int64 `json:"version"`
+}
+
+// DraftDiff is what changed on the board since a sequence number returned by
+// an earlier diff. Players, Teams and Chat hold the added or updated entities;
+// the Removed lists hold IDs. The pick fields are always current. Player
+// analytics are only refreshed when a player changes.
+type DraftDiff struct {
+	Seq int64 `json:"seq"`
+	// Reset means the requested sequence was unknown (e.g. the server
+	// restarted), so every entity is included and clients should replace
+	// rather than merge.
+	Reset          bool                 `json:"reset"`
+	Players        []Player             `json:"players"`
+	RemovedPlayers []string             `json:"removedPlayers"`
+	Teams          []Team               `json:"teams"`
+	RemovedTeams   []string             `json:"removedTeams"`
+	Chat           []ChatMessage        `json:"chat"`
+	RemovedChat    []string             `json:"removedChat"`
+	TeamOrder      []string             `json:"teamOrder"`
+	Settings       DraftSettings        `json:"settings"`
+	CurrentPick    int                  `json:"currentPick"`
+	CurrentRound   int                  `json:"currentRound"`
+	PickInRound    int                  `json:"pickInRound"`
+	CurrentTeamID  string               `json:"currentTeamId"`
+	DraftOrder     []DraftOrderEntry    `json:"draftOrder"`
+	SuggestedPick  *DraftRecommendation `json:"suggestedPick,omitempty"`
 }
 
 // PlayerProfile represents extended player information
@@ -0,0 +1,104 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/clickhouse"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/dal"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+type fixedMetrics struct {
+	metrics clickhouse.PlayerMetrics
+	err     error
+}
+
+func (f fixedMetrics) GetPlayerMetrics(ctx context.Context, jellycatID string) (clickhouse.PlayerMetrics, error) {
+	return f.metrics, f.err
+}
+
+func newTestStore(t *testing.T) (*dal.MemoryDAL, *models.Player) {
+	t.Helper()
+	t.Setenv("ENVIRONMENT", "production")
+	store := dal.NewMemoryDAL()
+	player, err := store.AddPlayer(&models.Player{Name: "Profiled", Position: "CC", Team: "Test", Points: 10, CuddlePoints: 50, Tier: models.TierB})
+	if err != nil {
+		t.Fatalf("AddPlayer() failed: %v", err)
+	}
+	return store, player
+}
+
+func TestGetUsesMetricsSourceWhenConfigured(t *testing.T) {
+	store, player := newTestStore(t)
+	want := clickhouse.PlayerMetrics{Popularity: 80, Consistency: 60, Efficiency: 40, TrendDelta: -0.25}
+
+	got, err := NewService(store, fixedMetrics{metrics: want}).Get(context.Background(), player.ID)
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if got.ID != player.ID || got.Metrics.Popularity != 80 || got.Metrics.Consistency != 60 ||
+		got.Metrics.Efficiency != 40 || got.Metrics.TrendDelta != -0.25 {
+		t.Fatalf("profile = %+v, want the source's metrics", got)
+	}
+}
+
+func TestGetFallsBackToPlaceholdersWithoutClickHouse(t *testing.T) {
+	store, player := newTestStore(t)
+	service := NewService(store, nil)
+
+	first, err := service.Get(context.Background(), player.ID)
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	second, err := service.Get(context.Background(), player.ID)
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if first.Metrics != second.Metrics {
+		t.Fatalf("placeholder metrics changed between calls: %+v, %+v", first.Metrics, second.Metrics)
+	}
+}
+
+func TestPlaceholderTrendDeltaVariesWithinRange(t *testing.T) {
+	store, _ := newTestStore(t)
+	service := NewService(store, nil)
+	for i := 0; i < 12; i++ {
+		if _, err := store.AddPlayer(&models.Player{Name: fmt.Sprintf("Trending %d", i), Position: "CC", Team: "Test", Points: 10 + i, CuddlePoints: 50, Tier: models.TierB}); err != nil {
+			t.Fatalf("AddPlayer() failed: %v", err)
+		}
+	}
+	state, err := store.GetState()
+	if err != nil {
+		t.Fatalf("GetState() failed: %v", err)
+	}
+
+	seen := make(map[float64]bool)
+	for _, player := range state.Players {
+		got, err := service.Get(context.Background(), player.ID)
+		if err != nil {
+			t.Fatalf("Get(%s) failed: %v", player.ID, err)
+		}
+		if delta := got.Metrics.TrendDelta; delta < -1 || delta > 1 {
+			t.Fatalf("TrendDelta for %s = %v, want it in [-1, 1]", player.ID, delta)
+		}
+		seen[got.Metrics.TrendDelta] = true
+	}
+	if len(seen) < 3 {
+		t.Fatalf("TrendDelta took %d distinct values across %d players, want it to vary", len(seen), len(state.Players))
+	}
+}
+
+func TestGetReportsMissingPlayersAndMetricsFailures(t *testing.T) {
+	store, player := newTestStore(t)
+
+	if _, err := NewService(store, nil).Get(context.Background(), "missing"); !errors.Is(err, dal.ErrPlayerNotFound) {
+		t.Fatalf("Get(missing) error = %v, want ErrPlayerNotFound", err)
+	}
+	failing := NewService(store, fixedMetrics{err: errors.New("clickhouse down")})
+	if _, err := failing.Get(context.Background(), player.ID); !errors.Is(err, ErrMetricsUnavailable) {
+		t.Fatalf("Get() error = %v, want ErrMetricsUnavailable", err)
+	}
+}
@@ -248,29 +248,30 @@ func (p *EmbeddedNATSPubSub) GetSubscriberCount() int {
 	return len(p.subscribers)
 }
 
-// natsLogger adapts our logger to the NATS server logger interface
+// natsLogger adapts our logger to the NATS server logger interface, tagging
+// each line with component=nats
 type natsLogger struct{}
 
 func (l *natsLogger) Noticef(format string, v ...interface{}) {
-	logger.Info(fmt.Sprintf("[NATS] "+format, v...))
+	logger.Info(fmt.Sprintf(format, v...), "component", "nats")
 }
 
 func (l *natsLogger) Warnf(format string, v ...interface{}) {
-	logger.Warn(fmt.Sprintf("[NATS] "+format, v...))
+	logger.Warn(fmt.Sprintf(format, v...), "component", "nats")
 }
 
 func (l *natsLogger) Fatalf(format string, v ...interface{}) {
-	logger.Error(fmt.Sprintf("[NATS] "+format, v...))
+	logger.Error(fmt.Sprintf(format, v...), "component", "nats")
 }
 
 func (l *natsLogger) Errorf(format string, v ...interface{}) {
-	logger.Error(fmt.Sprintf("[NATS] "+format, v...))
+	logger.Error(fmt.Sprintf(format, v...), "component", "nats")
 }
 
 func (l *natsLogger) Debugf(format string, v ...interface{}) {
-	logger.Debug(fmt.Sprintf("[NATS] "+format, v...))
+	logger.Debug(fmt.Sprintf(format, v...), "component", "nats")
 }
 
 func (l *natsLogger) Tracef(format string, v ...interface{}) {
-	logger.Debug(fmt.Sprintf("[NATS TRACE] "+format, v...))
+	logger.Debug(fmt.Sprintf(format, v...), "component", "nats", "trace", true)
 }
@@ -0,0 +1,81 @@
+package pubsub
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+)
+
+const defaultMaxPayloadBytes = 64 << 10
+
+// MaxPayloadBytes is the largest event payload, as JSON, that Publish sends
+// whole, read from EVENT_MAX_PAYLOAD_BYTES. It keeps events inside NATS
+// message and gRPC frame limits.
+func MaxPayloadBytes() int {
+	limit, err := strconv.Atoi(strings.TrimSpace(os.Getenv("EVENT_MAX_PAYLOAD_BYTES")))
+	if err != nil || limit <= 0 {
+		return defaultMaxPayloadBytes
+	}
+	return limit
+}
+
+// limitPayload returns event with its largest payload fields dropped until
+// the payload fits in limit bytes. A trimmed payload is flagged with
+// "truncated": true and lists what went in "droppedFields".
+func limitPayload(event Event, limit int) Event {
+	sizes := make(map[string]int, len(event.Payload))
+	total := 2 // {}
+	for key, value := range event.Payload {
+		sizes[key] = len(key) + 4 + encodedSize(value) // "key": value,
+		total += sizes[key]
+	}
+	if total <= limit {
+		return event
+	}
+
+	keys := make([]string, 0, len(sizes))
+	for key := range sizes {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if sizes[keys[i]] != sizes[keys[j]] {
+			return sizes[keys[i]] > sizes[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	payload := make(map[string]interface{}, len(event.Payload))
+	for key, value := range event.Payload {
+		payload[key] = value
+	}
+	dropped := []string{}
+	for _, key := range keys {
+		if total <= limit {
+			break
+		}
+		delete(payload, key)
+		total -= sizes[key]
+		dropped = append(dropped, key)
+	}
+	payload["truncated"] = true
+	payload["droppedFields"] = dropped
+
+	logger.Warn("PubSub: Event payload too large, dropping fields", "type", event.Type, "limit", limit, "dropped", dropped)
+	event.Payload = payload
+	return event
+}
+
+// encodedSize is a value's size as JSON, or as fmt.Sprint prints it when it
+// has no JSON form.
+func encodedSize(value interface{}) int {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return len(fmt.Sprint(value))
+	}
+	return len(encoded)
+}
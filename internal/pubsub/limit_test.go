@@ -0,0 +1,46 @@
+package pubsub
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+)
+
+func TestPublishDropsOversizedPayloadFields(t *testing.T) {
+	logger.Init()
+	t.Setenv("EVENT_MAX_PAYLOAD_BYTES", "256")
+	ps := New()
+	ch := ps.Subscribe()
+
+	payload := map[string]interface{}{
+		"id":      "player-1",
+		"history": strings.Repeat("x", 1000),
+	}
+	ps.Publish(Event{Type: "players:update", Payload: payload})
+
+	event := <-ch
+	if _, ok := event.Payload["history"]; ok {
+		t.Fatal("oversized field was published whole")
+	}
+	if event.Payload["id"] != "player-1" || event.Payload["truncated"] != true {
+		t.Fatalf("payload = %v, want id kept and the payload flagged truncated", event.Payload)
+	}
+	if dropped, _ := event.Payload["droppedFields"].([]string); len(dropped) != 1 || dropped[0] != "history" {
+		t.Fatalf("droppedFields = %v, want [history]", event.Payload["droppedFields"])
+	}
+	if _, ok := payload["truncated"]; ok {
+		t.Fatal("Publish modified the caller's payload")
+	}
+}
+
+func TestPublishLeavesPayloadsUnderTheLimitAlone(t *testing.T) {
+	logger.Init()
+	ps := New()
+	ch := ps.Subscribe()
+
+	ps.Publish(Event{Type: "chat:add", Payload: map[string]interface{}{"text": "hello"}})
+	if event := <-ch; len(event.Payload) != 1 || event.Payload["text"] != "hello" {
+		t.Fatalf("payload = %v, want it unchanged", event.Payload)
+	}
+}
@@ -2,14 +2,104 @@ package pubsub
 
 import (
 	"sync"
+	"time"
 
 	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/logger"
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
 )
 
 // Event represents a pubsub event
 type Event struct {
 	Type    string                 `json:"type"`
 	Payload map[string]interface{} `json:"payload,omitempty"`
+	// Scope is the ID of the only user whose streams receive the event.
+	// Events without a scope go to everyone.
+	Scope string `json:"scope,omitempty"`
+	// Room is the draft room whose members receive the event. Events
+	// without a room go to every room.
+	Room string `json:"room,omitempty"`
+	// Version is the store's state version when the event was published
+	// (see StampVersions). A client whose last-known version is more than
+	// one behind has missed a change and should refetch the state.
+	Version int64 `json:"version,omitempty"`
+}
+
+// EventError reports a failed operation to the user who requested it, so
+// their clients can roll back anything rendered optimistically.
+const EventError = "error"
+
+// NewErrorEvent builds an error event for userID saying action failed with err.
+func NewErrorEvent(userID, action string, err error) Event {
+	return Event{
+		Type:  EventError,
+		Scope: userID,
+		Payload: map[string]interface{}{
+			"action": action,
+			"reason": err.Error(),
+		},
+	}
+}
+
+// EventOnClock announces whose turn it is after the turn moves, so the
+// team's owner can be alerted.
+const EventOnClock = "draft:onclock"
+
+// NewOnClockEvent builds the on-the-clock event for state's current team.
+// ownerUserId is empty for unclaimed teams. closesIn (seconds) is included
+// when the draft window has a closing time. It returns false once the draft
+// is complete.
+func NewOnClockEvent(state *models.DraftState, window models.DraftWindow, now time.Time) (Event, bool) {
+	if state.CurrentTeamID == "" {
+		return Event{}, false
+	}
+
+	payload := map[string]interface{}{
+		"teamId":      state.CurrentTeamID,
+		"teamName":    state.CurrentTeamName,
+		"pick":        state.CurrentPick,
+		"round":       state.CurrentRound,
+		"owner":       "",
+		"ownerUserId": "",
+	}
+	for _, team := range state.Teams {
+		if team.ID == state.CurrentTeamID {
+			payload["owner"] = team.Owner
+			payload["ownerUserId"] = team.OwnerUserID
+			break
+		}
+	}
+	if !window.ClosesAt.IsZero() {
+		payload["closesIn"] = window.Status(now).ClosesIn
+	}
+	return Event{Type: EventOnClock, Payload: payload}, true
+}
+
+// VisibleTo reports whether a stream for userID should receive the event.
+// Anonymous streams pass an empty userID and only see unscoped events.
+func (e Event) VisibleTo(userID string) bool {
+	return e.Scope == "" || e.Scope == userID
+}
+
+// Filter decides which events a subscription receives.
+type Filter func(Event) bool
+
+// Audience is the Filter for a stream opened by userID, a member of rooms:
+// it passes events VisibleTo the user that are for no room or one of rooms.
+func Audience(userID string, rooms ...string) Filter {
+	return func(e Event) bool {
+		if !e.VisibleTo(userID) {
+			return false
+		}
+		if e.Room == "" {
+			return true
+		}
+		for _, room := range rooms {
+			if room == e.Room {
+				return true
+			}
+		}
+		return false
+	}
 }
 
 // Upstream is an interface for upstream publishers (e.g., NATS)
@@ -23,9 +113,16 @@ type Upstream interface {
 type PubSub struct {
 	mu          sync.RWMutex
 	subscribers []chan Event
-	upstream    Upstream // Optional upstream publisher (e.g., NATS)
+	filters     map[chan Event]Filter // SubscribeFiltered predicates
+	origin      []chan Event          // SubscribeOrigin subscribers
+	upstream    Upstream              // Optional upstream publisher (e.g., NATS)
+	version     func() (int64, error) // StampVersions source
 }
 
+// originBuffer is larger than a stream's buffer since origin subscribers do
+// work per event, such as writing analytics, rather than just forwarding it.
+const originBuffer = 256
+
 // New creates a new PubSub instance
 func New() *PubSub {
 	return &PubSub{
@@ -42,9 +139,11 @@ func NewWithUpstream(upstream Upstream) *PubSub {
 		upstream:    upstream,
 	}
 
-	// Subscribe to upstream and forward events to local subscribers
+	// Subscribe to upstream and forward events to local subscribers. The
+	// subscription is taken before returning, so closing the upstream
+	// always ends the forwarding goroutine
+	ch := upstream.Subscribe()
 	go func() {
-		ch := upstream.Subscribe()
 		logger.Debug("PubSub: Subscribed to upstream, waiting for events")
 		for event := range ch {
 			logger.Debug("PubSub: Received event from upstream, forwarding to local", "type", event.Type)
@@ -58,15 +157,41 @@ func NewWithUpstream(upstream Upstream) *PubSub {
 
 // Subscribe adds a new subscriber and returns a channel for receiving events
 func (ps *PubSub) Subscribe() chan Event {
+	return ps.SubscribeFiltered(nil)
+}
+
+// SubscribeFiltered is like Subscribe but only delivers events allow
+// accepts, checked during fan-out so other users' events never reach the
+// channel. A nil allow accepts every event.
+func (ps *PubSub) SubscribeFiltered(allow Filter) chan Event {
 	ps.mu.Lock()
 	defer ps.mu.Unlock()
 
 	ch := make(chan Event, 10)
 	ps.subscribers = append(ps.subscribers, ch)
+	if allow != nil {
+		if ps.filters == nil {
+			ps.filters = map[chan Event]Filter{}
+		}
+		ps.filters[ch] = allow
+	}
 	logger.Debug("PubSub: New subscriber added", "totalSubscribers", len(ps.subscribers))
 	return ch
 }
 
+// SubscribeOrigin is like Subscribe but only receives events published
+// through this PubSub, not those arriving from the upstream. With an upstream
+// every instance sees every event, so side effects that must happen once per
+// event belong on an origin subscription.
+func (ps *PubSub) SubscribeOrigin() chan Event {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ch := make(chan Event, originBuffer)
+	ps.origin = append(ps.origin, ch)
+	return ch
+}
+
 // Unsubscribe removes a subscriber
 func (ps *PubSub) Unsubscribe(ch chan Event) {
 	ps.mu.Lock()
@@ -76,16 +201,53 @@ func (ps *PubSub) Unsubscribe(ch chan Event) {
 		if sub == ch {
 			close(ch)
 			ps.subscribers = append(ps.subscribers[:i], ps.subscribers[i+1:]...)
-			break
+			delete(ps.filters, ch)
+			return
+		}
+	}
+	for i, sub := range ps.origin {
+		if sub == ch {
+			close(ch)
+			ps.origin = append(ps.origin[:i], ps.origin[i+1:]...)
+			return
 		}
 	}
 }
 
+// StampVersions makes Publish set each event's Version from version, the
+// store's state version. Events are published after the change they report,
+// so they carry the version that change produced. Events from the upstream
+// keep the version their publisher stamped.
+func (ps *PubSub) StampVersions(version func() (int64, error)) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.version = version
+}
+
 // Publish sends an event to all subscribers
 // If an upstream is configured, the event is published to the upstream,
 // which will broadcast it back to all instances (including this one)
+// Payloads over MaxPayloadBytes lose their largest fields first.
 func (ps *PubSub) Publish(event Event) {
+	event = limitPayload(event, MaxPayloadBytes())
 	logger.Debug("PubSub: Publish called", "type", event.Type, "hasUpstream", ps.upstream != nil)
+	ps.mu.RLock()
+	if ps.version != nil && event.Version == 0 {
+		version, err := ps.version()
+		if err != nil {
+			logger.Warn("PubSub: Failed to read state version", "type", event.Type, "error", err)
+		}
+		event.Version = version
+	}
+	for _, ch := range ps.origin {
+		select {
+		case ch <- event:
+		default:
+			logger.Warn("PubSub: Origin subscriber is full, dropping event", "type", event.Type)
+		}
+	}
+	ps.mu.RUnlock()
+
 	if ps.upstream != nil {
 		// Send to upstream; it will broadcast back to us via the subscription
 		logger.Debug("PubSub: Forwarding to upstream", "type", event.Type)
@@ -100,8 +262,12 @@ func (ps *PubSub) Publish(event Event) {
 // publishLocal sends an event to local subscribers only
 func (ps *PubSub) publishLocal(event Event) {
 	ps.mu.RLock()
-	subs := make([]chan Event, len(ps.subscribers))
-	copy(subs, ps.subscribers)
+	subs := make([]chan Event, 0, len(ps.subscribers))
+	for _, ch := range ps.subscribers {
+		if allow := ps.filters[ch]; allow == nil || allow(event) {
+			subs = append(subs, ch)
+		}
+	}
 	ps.mu.RUnlock()
 
 	logger.Debug("PubSub: publishLocal", "type", event.Type, "subscriberCount", len(subs))
@@ -470,3 +470,111 @@ func TestUnsubscribeNonexistent(t *testing.T) {
 		// This is also ok if buffer is full
 	}
 }
+
+func TestSubscribeOriginSkipsUpstreamEvents(t *testing.T) {
+	upstream := NewMockUpstream()
+	ps := NewWithUpstream(upstream)
+
+	// Give the goroutine time to start
+	time.Sleep(10 * time.Millisecond)
+
+	origin := ps.SubscribeOrigin()
+	all := ps.Subscribe()
+
+	// Another instance's event reaches Subscribe but not SubscribeOrigin
+	upstream.Publish(Event{Type: "external:event"})
+	ps.Publish(Event{Type: "local:event"})
+
+	select {
+	case received := <-origin:
+		if received.Type != "local:event" {
+			t.Errorf("expected type local:event, got %s", received.Type)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timeout waiting for origin event")
+	}
+	select {
+	case received := <-origin:
+		t.Errorf("origin subscriber got extra event %s", received.Type)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	for _, want := range []string{"external:event", "local:event"} {
+		select {
+		case received := <-all:
+			if received.Type != want {
+				t.Errorf("expected type %s, got %s", want, received.Type)
+			}
+		case <-time.After(100 * time.Millisecond):
+			t.Fatalf("timeout waiting for %s", want)
+		}
+	}
+
+	ps.Unsubscribe(origin)
+	if _, ok := <-origin; ok {
+		t.Error("origin channel should be closed after Unsubscribe")
+	}
+}
+
+func TestSubscribeFilteredOnlyDeliversTheAudiencesEvents(t *testing.T) {
+	ps := New()
+	member := ps.SubscribeFiltered(Audience("user-a", "room-1"))
+	outsider := ps.SubscribeFiltered(Audience("user-b", "room-2"))
+
+	ps.Publish(Event{Type: "room:event", Room: "room-1"})
+	ps.Publish(Event{Type: "private:event", Scope: "user-a"})
+	ps.Publish(Event{Type: "global:event"})
+
+	for _, want := range []string{"room:event", "private:event", "global:event"} {
+		select {
+		case received := <-member:
+			if received.Type != want {
+				t.Errorf("member got %s, want %s", received.Type, want)
+			}
+		case <-time.After(100 * time.Millisecond):
+			t.Fatalf("timeout waiting for member's %s", want)
+		}
+	}
+
+	select {
+	case received := <-outsider:
+		if received.Type != "global:event" {
+			t.Errorf("outsider got %s, want only global:event", received.Type)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timeout waiting for outsider's global:event")
+	}
+	select {
+	case received := <-outsider:
+		t.Errorf("outsider got another room's or user's event %s", received.Type)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	ps.Unsubscribe(outsider)
+	if _, ok := ps.filters[outsider]; ok {
+		t.Error("Unsubscribe should drop the subscription's filter")
+	}
+}
+
+func TestStampVersionsSetsEachEventsVersion(t *testing.T) {
+	ps := New()
+	version := int64(6)
+	ps.StampVersions(func() (int64, error) { return version, nil })
+	ch := ps.Subscribe()
+	defer ps.Unsubscribe(ch)
+
+	ps.Publish(Event{Type: "teams:add"})
+	version = 7
+	ps.Publish(Event{Type: "relayed", Version: 3})
+
+	for _, want := range []Event{{Type: "teams:add", Version: 6}, {Type: "relayed", Version: 3}} {
+		select {
+		case received := <-ch:
+			if received.Type != want.Type || received.Version != want.Version {
+				t.Errorf("got %s at version %d, want %s at %d", received.Type, received.Version, want.Type, want.Version)
+			}
+		case <-time.After(100 * time.Millisecond):
+			t.Fatalf("timeout waiting for %s", want.Type)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package render
+
+import (
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// Funcs returns the helper functions available to every template:
+//   - chatTime formats a chat message's Unix-millis TS, e.g. "15:04 UTC"
+//   - tierBadge renders a player tier as a colored pill
+//   - plural writes a count with the singular or plural noun
+//   - dict builds a map from key/value pairs, for passing several values
+//     into a partial
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"chatTime":  chatTime,
+		"tierBadge": tierBadge,
+		"plural":    plural,
+		"dict":      dict,
+	}
+}
+
+func chatTime(ms int64) string {
+	if ms <= 0 {
+		return ""
+	}
+	return time.UnixMilli(ms).UTC().Format("15:04 UTC")
+}
+
+// tierBadgeClasses colors each tier's pill; unknown tiers are gray.
+var tierBadgeClasses = map[models.Tier]string{
+	models.TierS: "bg-yellow-200 text-yellow-900",
+	models.TierA: "bg-green-200 text-green-900",
+	models.TierB: "bg-blue-200 text-blue-900",
+}
+
+func tierBadge(tier models.Tier) template.HTML {
+	classes, ok := tierBadgeClasses[tier]
+	if !ok {
+		classes = "bg-gray-200 text-gray-800"
+	}
+	return template.HTML(fmt.Sprintf(`<span class="px-3 py-1.5 rounded-full text-xs font-bold %s">Tier %s</span>`,
+		classes, template.HTMLEscapeString(string(tier))))
+}
+
+func plural(count int, singular, pluralForm string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, singular)
+	}
+	return fmt.Sprintf("%d %s", count, pluralForm)
+}
+
+func dict(pairs ...any) (map[string]any, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict: odd number of arguments")
+	}
+	values := make(map[string]any, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: key %v is not a string", pairs[i])
+		}
+		values[key] = pairs[i+1]
+	}
+	return values, nil
+}
@@ -0,0 +1,124 @@
+// Package render executes the page templates with the shared helper
+// functions, caching the parsed sets in production and reparsing them on
+// every render in development so template edits show up on refresh.
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BaseTemplate is the layout every page extends; pages define "content".
+const BaseTemplate = "base.html"
+
+// Renderer renders the pages in a template directory. Each page is parsed
+// together with BaseTemplate and any partials/*.html files.
+type Renderer struct {
+	dir    string
+	reload bool
+	funcs  template.FuncMap
+
+	mu    sync.RWMutex
+	pages map[string]*template.Template
+}
+
+// New parses every page in dir, so template and helper errors fail startup
+// instead of a request. With reload set, pages are reparsed on each render.
+func New(dir string, reload bool) (*Renderer, error) {
+	r := &Renderer{dir: dir, reload: reload, funcs: Funcs()}
+	if err := r.parseAll(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Pages lists the page names Render accepts, sorted.
+func (r *Renderer) Pages() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.pages))
+	for name := range r.pages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render writes page, such as "draft" for templates/draft.html, inside the
+// base layout.
+func (r *Renderer) Render(w http.ResponseWriter, page string, data any) error {
+	return r.RenderFragment(w, page, BaseTemplate, data)
+}
+
+// RenderFragment writes the template called name from page's set, such as a
+// partial for an htmx swap. Output is buffered, so a failed render sends no
+// partial page and the caller can still write an error.
+func (r *Renderer) RenderFragment(w http.ResponseWriter, page, name string, data any) error {
+	tmpl, err := r.lookup(page)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return fmt.Errorf("render %s: %w", page, err)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+func (r *Renderer) lookup(page string) (*template.Template, error) {
+	if r.reload {
+		if err := r.parseAll(); err != nil {
+			return nil, err
+		}
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tmpl, ok := r.pages[page]
+	if !ok {
+		return nil, fmt.Errorf("render: no page %q in %s", page, r.dir)
+	}
+	return tmpl, nil
+}
+
+// parseAll parses each page in dir with the base layout and partials.
+func (r *Renderer) parseAll() error {
+	files, err := filepath.Glob(filepath.Join(r.dir, "*.html"))
+	if err != nil {
+		return err
+	}
+	partials, err := filepath.Glob(filepath.Join(r.dir, "partials", "*.html"))
+	if err != nil {
+		return err
+	}
+	base := filepath.Join(r.dir, BaseTemplate)
+
+	pages := make(map[string]*template.Template, len(files))
+	for _, file := range files {
+		if file == base {
+			continue
+		}
+		name := strings.TrimSuffix(filepath.Base(file), ".html")
+		set := append([]string{base, file}, partials...)
+		tmpl, err := template.New(BaseTemplate).Funcs(r.funcs).ParseFiles(set...)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", name, err)
+		}
+		pages[name] = tmpl
+	}
+	if len(pages) == 0 {
+		return fmt.Errorf("render: no pages in %s", r.dir)
+	}
+
+	r.mu.Lock()
+	r.pages = pages
+	r.mu.Unlock()
+	return nil
+}
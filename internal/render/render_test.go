@@ -0,0 +1,99 @@
+package render
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Billy-Davies-2/jellycat-draft-ui/internal/models"
+)
+
+// writeTemplates creates a base layout, a page and a partial in a temp dir.
+func writeTemplates(t *testing.T, page string) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.html":           `<main>{{ template "content" . }}</main>`,
+		"home.html":           page,
+		"partials/badge.html": `{{ define "badge" }}[{{ .label }} {{ .count }}]{{ end }}`,
+	}
+	for name, body := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func renderHome(t *testing.T, r *Renderer, data any) string {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	if err := r.Render(recorder, "home", data); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	return recorder.Body.String()
+}
+
+func TestRenderUsesPartialsAndHelpers(t *testing.T) {
+	dir := writeTemplates(t, `{{ define "content" }}{{ template "badge" (dict "label" "picks" "count" (plural .Picks "pick" "picks")) }} {{ chatTime .TS }} {{ tierBadge .Tier }}{{ end }}`)
+	r, err := New(dir, false)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ts := time.Date(2026, 3, 1, 18, 5, 0, 0, time.UTC).UnixMilli()
+	got := renderHome(t, r, map[string]any{"Picks": 1, "TS": ts, "Tier": models.TierS})
+	for _, want := range []string{"<main>", "[picks 1 pick]", "18:05 UTC", `bg-yellow-200 text-yellow-900">Tier S</span>`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered %q, missing %q", got, want)
+		}
+	}
+	if got := renderHome(t, r, map[string]any{"Picks": 3, "TS": int64(0), "Tier": models.Tier("<b>")}); !strings.Contains(got, "3 picks") || strings.Contains(got, "<b>") {
+		t.Errorf("rendered %q, want a plural count and an escaped unknown tier", got)
+	}
+}
+
+func TestNewRejectsBrokenTemplates(t *testing.T) {
+	if _, err := New(writeTemplates(t, `{{ define "content" }}{{ missingHelper }}{{ end }}`), false); err == nil {
+		t.Fatal("New should fail on a template calling an unknown function")
+	}
+	r, err := New(writeTemplates(t, `{{ define "content" }}{{ dict "odd" }}{{ end }}`), false)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	recorder := httptest.NewRecorder()
+	if err := r.Render(recorder, "home", nil); err == nil || recorder.Body.Len() != 0 {
+		t.Fatalf("Render = %v with body %q, want an error and no partial output", err, recorder.Body.String())
+	}
+	if err := r.Render(recorder, "missing", nil); err == nil {
+		t.Fatal("Render of an unknown page should fail")
+	}
+}
+
+func TestReloadReparsesEditedTemplates(t *testing.T) {
+	for _, reload := range []bool{false, true} {
+		dir := writeTemplates(t, `{{ define "content" }}v1{{ end }}`)
+		r, err := New(dir, reload)
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "home.html"), []byte(`{{ define "content" }}v2{{ end }}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		want := "<main>v1</main>"
+		if reload {
+			want = "<main>v2</main>"
+		}
+		if got := renderHome(t, r, nil); got != want {
+			t.Errorf("reload=%v: rendered %q, want %q", reload, got, want)
+		}
+	}
+}
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.36.10
+// 	protoc-gen-go v1.36.11
 // 	protoc        v4.25.1
 // source: proto/draft.proto
 
@@ -9,6 +9,7 @@ package draft
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -70,6 +71,8 @@ type Player struct {
 	Drafted       bool                   `protobuf:"varint,7,opt,name=drafted,proto3" json:"drafted,omitempty"`
 	DraftedBy     string                 `protobuf:"bytes,8,opt,name=drafted_by,json=draftedBy,proto3" json:"drafted_by,omitempty"`
 	Image         string                 `protobuf:"bytes,9,opt,name=image,proto3" json:"image,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -167,6 +170,20 @@ func (x *Player) GetImage() string {
 	return ""
 }
 
+func (x *Player) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Player) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
 // Team message
 type Team struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -176,6 +193,8 @@ type Team struct {
 	Mascot        string                 `protobuf:"bytes,4,opt,name=mascot,proto3" json:"mascot,omitempty"`
 	Color         string                 `protobuf:"bytes,5,opt,name=color,proto3" json:"color,omitempty"`
 	Players       []*Player              `protobuf:"bytes,6,rep,name=players,proto3" json:"players,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -252,14 +271,32 @@ func (x *Team) GetPlayers() []*Player {
 	return nil
 }
 
+func (x *Team) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Team) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
 // ChatMessage message
 type ChatMessage struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Ts            int64                  `protobuf:"varint,2,opt,name=ts,proto3" json:"ts,omitempty"`
-	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
-	Text          string                 `protobuf:"bytes,4,opt,name=text,proto3" json:"text,omitempty"`
-	Emotes        map[string]int32       `protobuf:"bytes,5,rep,name=emotes,proto3" json:"emotes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Ts        int64                  `protobuf:"varint,2,opt,name=ts,proto3" json:"ts,omitempty"`
+	Type      string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Text      string                 `protobuf:"bytes,4,opt,name=text,proto3" json:"text,omitempty"`
+	Emotes    map[string]int32       `protobuf:"bytes,5,rep,name=emotes,proto3" json:"emotes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// Display name of the bot that posts system messages; empty for users
+	Sender        string `protobuf:"bytes,8,opt,name=sender,proto3" json:"sender,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -329,6 +366,27 @@ func (x *ChatMessage) GetEmotes() map[string]int32 {
 	return nil
 }
 
+func (x *ChatMessage) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *ChatMessage) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *ChatMessage) GetSender() string {
+	if x != nil {
+		return x.Sender
+	}
+	return ""
+}
+
 // DraftState message
 type DraftState struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -1147,8 +1205,8 @@ var File_proto_draft_proto protoreflect.FileDescriptor
 
 const file_proto_draft_proto_rawDesc = "" +
 	"\n" +
-	"\x11proto/draft.proto\x12\x05draft\"\a\n" +
-	"\x05Empty\"\xd7\x01\n" +
+	"\x11proto/draft.proto\x12\x05draft\x1a\x1fgoogle/protobuf/timestamp.proto\"\a\n" +
+	"\x05Empty\"\xcd\x02\n" +
 	"\x06Player\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
@@ -1159,20 +1217,34 @@ const file_proto_draft_proto_rawDesc = "" +
 	"\adrafted\x18\a \x01(\bR\adrafted\x12\x1d\n" +
 	"\n" +
 	"drafted_by\x18\b \x01(\tR\tdraftedBy\x12\x14\n" +
-	"\x05image\x18\t \x01(\tR\x05image\"\x97\x01\n" +
+	"\x05image\x18\t \x01(\tR\x05image\x129\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\x8d\x02\n" +
 	"\x04Team\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
 	"\x05owner\x18\x03 \x01(\tR\x05owner\x12\x16\n" +
 	"\x06mascot\x18\x04 \x01(\tR\x06mascot\x12\x14\n" +
 	"\x05color\x18\x05 \x01(\tR\x05color\x12'\n" +
-	"\aplayers\x18\x06 \x03(\v2\r.draft.PlayerR\aplayers\"\xc8\x01\n" +
+	"\aplayers\x18\x06 \x03(\v2\r.draft.PlayerR\aplayers\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xd6\x02\n" +
 	"\vChatMessage\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x0e\n" +
 	"\x02ts\x18\x02 \x01(\x03R\x02ts\x12\x12\n" +
 	"\x04type\x18\x03 \x01(\tR\x04type\x12\x12\n" +
 	"\x04text\x18\x04 \x01(\tR\x04text\x126\n" +
-	"\x06emotes\x18\x05 \x03(\v2\x1e.draft.ChatMessage.EmotesEntryR\x06emotes\x1a9\n" +
+	"\x06emotes\x18\x05 \x03(\v2\x1e.draft.ChatMessage.EmotesEntryR\x06emotes\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x16\n" +
+	"\x06sender\x18\b \x01(\tR\x06sender\x1a9\n" +
 	"\vEmotesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"\x80\x01\n" +
@@ -1289,48 +1361,55 @@ var file_proto_draft_proto_goTypes = []any{
 	(*Event)(nil),                   // 17: draft.Event
 	nil,                             // 18: draft.ChatMessage.EmotesEntry
 	nil,                             // 19: draft.Event.PayloadEntry
+	(*timestamppb.Timestamp)(nil),   // 20: google.protobuf.Timestamp
 }
 var file_proto_draft_proto_depIdxs = []int32{
-	1,  // 0: draft.Team.players:type_name -> draft.Player
-	18, // 1: draft.ChatMessage.emotes:type_name -> draft.ChatMessage.EmotesEntry
-	1,  // 2: draft.DraftState.players:type_name -> draft.Player
-	2,  // 3: draft.DraftState.teams:type_name -> draft.Team
-	3,  // 4: draft.DraftState.chat:type_name -> draft.ChatMessage
-	2,  // 5: draft.TeamsResponse.teams:type_name -> draft.Team
-	13, // 6: draft.PlayerProfile.metrics:type_name -> draft.PlayerMetrics
-	3,  // 7: draft.ChatResponse.messages:type_name -> draft.ChatMessage
-	19, // 8: draft.Event.payload:type_name -> draft.Event.PayloadEntry
-	0,  // 9: draft.DraftService.GetState:input_type -> draft.Empty
-	5,  // 10: draft.DraftService.DraftPlayer:input_type -> draft.DraftPlayerRequest
-	0,  // 11: draft.DraftService.ResetDraft:input_type -> draft.Empty
-	7,  // 12: draft.DraftService.AddTeam:input_type -> draft.AddTeamRequest
-	0,  // 13: draft.DraftService.ListTeams:input_type -> draft.Empty
-	9,  // 14: draft.DraftService.ReorderTeams:input_type -> draft.ReorderTeamsRequest
-	1,  // 15: draft.DraftService.AddPlayer:input_type -> draft.Player
-	10, // 16: draft.DraftService.SetPlayerPoints:input_type -> draft.SetPlayerPointsRequest
-	11, // 17: draft.DraftService.GetPlayerProfile:input_type -> draft.GetPlayerProfileRequest
-	0,  // 18: draft.DraftService.ListChat:input_type -> draft.Empty
-	15, // 19: draft.DraftService.SendChatMessage:input_type -> draft.SendChatRequest
-	16, // 20: draft.DraftService.AddReaction:input_type -> draft.AddReactionRequest
-	0,  // 21: draft.DraftService.StreamEvents:input_type -> draft.Empty
-	4,  // 22: draft.DraftService.GetState:output_type -> draft.DraftState
-	6,  // 23: draft.DraftService.DraftPlayer:output_type -> draft.DraftPlayerResponse
-	0,  // 24: draft.DraftService.ResetDraft:output_type -> draft.Empty
-	2,  // 25: draft.DraftService.AddTeam:output_type -> draft.Team
-	8,  // 26: draft.DraftService.ListTeams:output_type -> draft.TeamsResponse
-	8,  // 27: draft.DraftService.ReorderTeams:output_type -> draft.TeamsResponse
-	1,  // 28: draft.DraftService.AddPlayer:output_type -> draft.Player
-	1,  // 29: draft.DraftService.SetPlayerPoints:output_type -> draft.Player
-	12, // 30: draft.DraftService.GetPlayerProfile:output_type -> draft.PlayerProfile
-	14, // 31: draft.DraftService.ListChat:output_type -> draft.ChatResponse
-	3,  // 32: draft.DraftService.SendChatMessage:output_type -> draft.ChatMessage
-	3,  // 33: draft.DraftService.AddReaction:output_type -> draft.ChatMessage
-	17, // 34: draft.DraftService.StreamEvents:output_type -> draft.Event
-	22, // [22:35] is the sub-list for method output_type
-	9,  // [9:22] is the sub-list for method input_type
-	9,  // [9:9] is the sub-list for extension type_name
-	9,  // [9:9] is the sub-list for extension extendee
-	0,  // [0:9] is the sub-list for field type_name
+	20, // 0: draft.Player.created_at:type_name -> google.protobuf.Timestamp
+	20, // 1: draft.Player.updated_at:type_name -> google.protobuf.Timestamp
+	1,  // 2: draft.Team.players:type_name -> draft.Player
+	20, // 3: draft.Team.created_at:type_name -> google.protobuf.Timestamp
+	20, // 4: draft.Team.updated_at:type_name -> google.protobuf.Timestamp
+	18, // 5: draft.ChatMessage.emotes:type_name -> draft.ChatMessage.EmotesEntry
+	20, // 6: draft.ChatMessage.created_at:type_name -> google.protobuf.Timestamp
+	20, // 7: draft.ChatMessage.updated_at:type_name -> google.protobuf.Timestamp
+	1,  // 8: draft.DraftState.players:type_name -> draft.Player
+	2,  // 9: draft.DraftState.teams:type_name -> draft.Team
+	3,  // 10: draft.DraftState.chat:type_name -> draft.ChatMessage
+	2,  // 11: draft.TeamsResponse.teams:type_name -> draft.Team
+	13, // 12: draft.PlayerProfile.metrics:type_name -> draft.PlayerMetrics
+	3,  // 13: draft.ChatResponse.messages:type_name -> draft.ChatMessage
+	19, // 14: draft.Event.payload:type_name -> draft.Event.PayloadEntry
+	0,  // 15: draft.DraftService.GetState:input_type -> draft.Empty
+	5,  // 16: draft.DraftService.DraftPlayer:input_type -> draft.DraftPlayerRequest
+	0,  // 17: draft.DraftService.ResetDraft:input_type -> draft.Empty
+	7,  // 18: draft.DraftService.AddTeam:input_type -> draft.AddTeamRequest
+	0,  // 19: draft.DraftService.ListTeams:input_type -> draft.Empty
+	9,  // 20: draft.DraftService.ReorderTeams:input_type -> draft.ReorderTeamsRequest
+	1,  // 21: draft.DraftService.AddPlayer:input_type -> draft.Player
+	10, // 22: draft.DraftService.SetPlayerPoints:input_type -> draft.SetPlayerPointsRequest
+	11, // 23: draft.DraftService.GetPlayerProfile:input_type -> draft.GetPlayerProfileRequest
+	0,  // 24: draft.DraftService.ListChat:input_type -> draft.Empty
+	15, // 25: draft.DraftService.SendChatMessage:input_type -> draft.SendChatRequest
+	16, // 26: draft.DraftService.AddReaction:input_type -> draft.AddReactionRequest
+	0,  // 27: draft.DraftService.StreamEvents:input_type -> draft.Empty
+	4,  // 28: draft.DraftService.GetState:output_type -> draft.DraftState
+	6,  // 29: draft.DraftService.DraftPlayer:output_type -> draft.DraftPlayerResponse
+	0,  // 30: draft.DraftService.ResetDraft:output_type -> draft.Empty
+	2,  // 31: draft.DraftService.AddTeam:output_type -> draft.Team
+	8,  // 32: draft.DraftService.ListTeams:output_type -> draft.TeamsResponse
+	8,  // 33: draft.DraftService.ReorderTeams:output_type -> draft.TeamsResponse
+	1,  // 34: draft.DraftService.AddPlayer:output_type -> draft.Player
+	1,  // 35: draft.DraftService.SetPlayerPoints:output_type -> draft.Player
+	12, // 36: draft.DraftService.GetPlayerProfile:output_type -> draft.PlayerProfile
+	14, // 37: draft.DraftService.ListChat:output_type -> draft.ChatResponse
+	3,  // 38: draft.DraftService.SendChatMessage:output_type -> draft.ChatMessage
+	3,  // 39: draft.DraftService.AddReaction:output_type -> draft.ChatMessage
+	17, // 40: draft.DraftService.StreamEvents:output_type -> draft.Event
+	28, // [28:41] is the sub-list for method output_type
+	15, // [15:28] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
 }
 
 func init() { file_proto_draft_proto_init() }